@@ -45,6 +45,7 @@ export interface FeatureToggles {
   featureHighlights?: boolean;
   dashboardComments?: boolean;
   annotationComments?: boolean;
+  dbFileStorage?: boolean;
   migrationLocking?: boolean;
   azureMonitorResourcePickerForMetrics?: boolean;
 }
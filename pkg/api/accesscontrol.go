@@ -7,6 +7,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -375,12 +376,43 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{"Admin"},
 	}
 
+	liveChannelRulesReaderRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Version:     1,
+			Name:        "fixed:live.channelrules:reader",
+			DisplayName: "Live channel rules reader",
+			Description: "Read the channel rules of the Live pipeline.",
+			Group:       "Live",
+			Permissions: []ac.Permission{
+				{Action: live.ActionChannelRulesRead},
+			},
+		},
+		Grants: []string{string(models.ROLE_ADMIN)},
+	}
+
+	liveChannelRulesWriterRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Version:     1,
+			Name:        "fixed:live.channelrules:writer",
+			DisplayName: "Live channel rules writer",
+			Description: "Create, update, delete or read the channel rules of the Live pipeline.",
+			Group:       "Live",
+			Permissions: ac.ConcatPermissions(liveChannelRulesReaderRole.Role.Permissions, []ac.Permission{
+				{Action: live.ActionChannelRulesCreate},
+				{Action: live.ActionChannelRulesWrite},
+				{Action: live.ActionChannelRulesDelete},
+			}),
+		},
+		Grants: []string{string(models.ROLE_ADMIN)},
+	}
+
 	return hs.AccessControl.DeclareFixedRoles(
 		provisioningWriterRole, datasourcesReaderRole, datasourcesWriterRole, datasourcesIdReaderRole,
 		datasourcesCompatibilityReaderRole, orgReaderRole, orgWriterRole,
 		orgMaintainerRole, teamsCreatorRole, teamsWriterRole, datasourcesExplorerRole, annotationsReaderRole,
 		dashboardsCreatorRole, dashboardsReaderRole, dashboardsWriterRole,
 		foldersCreatorRole, foldersReaderRole, foldersWriterRole, apikeyWriterRole,
+		liveChannelRulesReaderRole, liveChannelRulesWriterRole,
 	)
 }
 
@@ -0,0 +1,111 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards/database"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// ImportPrometheusRules imports the rule groups of a Prometheus, Cortex or Mimir rule file as Grafana managed
+// rules, and prints the rules that could not be converted.
+func ImportPrometheusRules(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("the path of the rule file is required")
+	}
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return fmt.Errorf("the --datasource-uid flag is required")
+	}
+	orgID := int64(c.Int("org-id"))
+	if orgID == 0 {
+		orgID = 1
+	}
+
+	// nolint:gosec
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errutil.Wrapf(err, "failed to read the rule file")
+	}
+	var file apimodels.PrometheusRuleFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return errutil.Wrapf(err, "failed to parse the rule file")
+	}
+
+	folderTitle := c.String("folder")
+	if folderTitle == "" {
+		folderTitle = file.Namespace
+	}
+	if folderTitle == "" {
+		return fmt.Errorf("the rule file has no namespace, use the --folder flag")
+	}
+
+	ctx := context.Background()
+	folder, err := database.ProvideDashboardStore(sqlStore).GetFolderByTitle(ctx, orgID, folderTitle)
+	if err != nil {
+		return errutil.Wrapf(err, "failed to get the folder %q", folderTitle)
+	}
+
+	dsQuery := models.GetDataSourceQuery{Uid: datasourceUID, OrgId: orgID}
+	if err := sqlStore.GetDataSource(ctx, &dsQuery); err != nil {
+		return errutil.Wrapf(err, "failed to get the data source %q", datasourceUID)
+	}
+	if dsQuery.Result.Type != prom.DatasourceType {
+		return fmt.Errorf("data source %s is of type %s, not %s", datasourceUID, dsQuery.Result.Type, prom.DatasourceType)
+	}
+
+	cfg := sqlStore.Cfg.UnifiedAlerting
+	ruleStore := store.DBstore{
+		BaseInterval:    cfg.BaseInterval,
+		DefaultInterval: cfg.DefaultRuleEvaluationInterval,
+		SQLStore:        sqlStore,
+		Logger:          log.New("ngalert.store"),
+	}
+	cmd := prom.ImportCommand{
+		OrgID:           orgID,
+		NamespaceUID:    folder.Uid,
+		DatasourceUID:   datasourceUID,
+		Groups:          file.Groups,
+		BaseInterval:    cfg.BaseInterval,
+		DefaultInterval: cfg.DefaultRuleEvaluationInterval,
+		DryRun:          c.Bool("dry-run"),
+	}
+	var report apimodels.PrometheusImportReport
+	err = ruleStore.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		report, err = prom.Import(ctx, ruleStore, cmd)
+		return err
+	})
+	if err != nil {
+		return errutil.Wrapf(err, "failed to import the rule file")
+	}
+
+	logger.Info("\n")
+	for _, status := range report.Failed {
+		if status.Rule == "" {
+			logger.Infof("%s Rule group %s: %s\n", color.RedString("✘"), status.Group, status.Error)
+			continue
+		}
+		logger.Infof("%s Rule %s of group %s: %s\n", color.RedString("✘"), status.Rule, status.Group, status.Error)
+	}
+	verb := "Imported"
+	if cmd.DryRun {
+		verb = "Would import"
+	}
+	logger.Infof("%s %s %d rules into the folder %s, %d rules or groups could not be converted\n", color.GreenString("✔"), verb, len(report.Imported), folder.Title, len(report.Failed))
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/alerting"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
@@ -196,6 +197,37 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "alerting",
+		Usage: "Runs a script that imports or converts alert rules",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "import-prometheus-rules",
+				Usage:  "import-prometheus-rules <rule file>. Imports the rules of a Prometheus, Cortex or Mimir rule file as Grafana managed rules, and reports the rules that could not be converted.",
+				Action: runDbCommand(alerting.ImportPrometheusRules),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "datasource-uid",
+						Usage: "UID of the Prometheus data source the rules query",
+					},
+					&cli.StringFlag{
+						Name:  "folder",
+						Usage: "Title of the folder the rules are imported into. Defaults to the namespace of the rule file",
+					},
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "ID of the organization the rules are imported into",
+						Value: 1,
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Convert the rules and report the result without saving them",
+						Value: false,
+					},
+				},
+			},
+		},
+	},
 }
 
 var cueCommands = []*cli.Command{
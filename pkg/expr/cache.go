@@ -0,0 +1,317 @@
+package expr
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	expressionsQueryCacheTotal *prometheus.CounterVec
+)
+
+const (
+	cacheResultHit    = "hit"
+	cacheResultMiss   = "miss"
+	cacheResultShared = "shared"
+)
+
+// sharedQueryTimeout is the maximum duration of a query that is shared with identical queries
+// in flight. It is not canceled with the context of the caller that started it.
+const sharedQueryTimeout = time.Minute
+
+func init() {
+	expressionsQueryCacheTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "expressions_query_cache_requests_total",
+			Help: "The number of data source queries of expressions by cache result: hit, miss, or shared with an identical query in flight",
+		},
+		[]string{"result"},
+	)
+
+	prometheus.MustRegister(expressionsQueryCacheTotal)
+}
+
+// queryCache caches the responses of data source queries for a time to live, and
+// makes sure that identical queries that run at the same time are only sent to the
+// data source once. Queries are identical when they are for the same data source,
+// have the same query model, and the same time range after it is aligned.
+// The cache holds at most maxEntries responses, the least recently used ones are
+// evicted first.
+type queryCache struct {
+	ttl        time.Duration
+	alignment  time.Duration
+	dedup      bool
+	maxEntries int
+
+	group singleflight.Group
+
+	mtx sync.Mutex
+	// entries maps the keys to the elements of lru, which holds *cacheEntry values
+	// from the most to the least recently used.
+	entries   map[string]*list.Element
+	lru       *list.List
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	key string
+	// frames holds the arrow encoded frames of each response, so every
+	// hit gets its own copy of the frames that it may modify.
+	frames map[string][][]byte
+	// errors holds the errors of each response. They are only shared with the
+	// identical queries in flight, responses with errors are never cached.
+	errors  map[string]error
+	expires time.Time
+}
+
+// newQueryCache returns a queryCache, or nil when both caching and deduplication are disabled.
+func newQueryCache(ttl, alignment time.Duration, maxEntries int, dedup bool) *queryCache {
+	if (ttl <= 0 || maxEntries <= 0) && !dedup {
+		return nil
+	}
+	if maxEntries <= 0 {
+		ttl = 0
+	}
+	return &queryCache{
+		ttl:        ttl,
+		alignment:  alignment,
+		dedup:      dedup,
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+// cacheKey identifies a data source query.
+type cacheKey struct {
+	OrgID             int64
+	DatasourceUID     string
+	DatasourceVersion int
+	Query             json.RawMessage
+	QueryType         string
+	From              int64
+	To                int64
+	IntervalMS        int64
+	MaxDP             int64
+	Headers           map[string]string
+}
+
+func (c *queryCache) key(dn *DSNode) (string, error) {
+	from, to := dn.timeRange.From, dn.timeRange.To
+	if c.alignment > 0 {
+		from, to = from.Truncate(c.alignment), to.Truncate(c.alignment)
+	}
+	b, err := json.Marshal(cacheKey{
+		OrgID:             dn.orgID,
+		DatasourceUID:     dn.datasource.Uid,
+		DatasourceVersion: dn.datasource.Version,
+		Query:             dn.query,
+		QueryType:         dn.queryType,
+		From:              from.UnixNano(),
+		To:                to.UnixNano(),
+		IntervalMS:        dn.intervalMS,
+		MaxDP:             dn.maxDP,
+		Headers:           dn.request.Headers,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// queryData returns the cached response for the query of the node if there is one,
+// otherwise it runs queryFn, sharing the response with identical queries in flight.
+func (c *queryCache) queryData(ctx context.Context, dn *DSNode, queryFn func(context.Context) (*backend.QueryDataResponse, error)) (*backend.QueryDataResponse, error) {
+	key, err := c.key(dn)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp, ok := c.get(key); ok {
+		expressionsQueryCacheTotal.WithLabelValues(cacheResultHit).Inc()
+		return resp, nil
+	}
+
+	if !c.dedup {
+		expressionsQueryCacheTotal.WithLabelValues(cacheResultMiss).Inc()
+		resp, err := queryFn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return resp, c.set(key, resp)
+	}
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(detachedContext{parent: ctx}, sharedQueryTimeout)
+		defer cancel()
+		resp, err := queryFn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := c.encode(key, resp)
+		if err != nil {
+			return nil, err
+		}
+		// errors are never cached, but are still shared with the queries in flight
+		if !hasError(resp) {
+			c.store(entry)
+		}
+		return entry, nil
+	})
+
+	// every caller stops waiting when its own context is done, the shared query keeps running
+	// for the other callers
+	var res singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-ch:
+	}
+	if res.Shared {
+		expressionsQueryCacheTotal.WithLabelValues(cacheResultShared).Inc()
+	} else {
+		expressionsQueryCacheTotal.WithLabelValues(cacheResultMiss).Inc()
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	// every caller decodes its own copy, as the frames are modified when they are converted
+	return decodeResponse(res.Val.(*cacheEntry))
+}
+
+// detachedContext keeps the values of its parent, such as the tracing span, but is not
+// canceled when the parent is.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (c detachedContext) Done() <-chan struct{} { return nil }
+
+func (c detachedContext) Err() error { return nil }
+
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+func (c *queryCache) get(key string) (*backend.QueryDataResponse, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mtx.Lock()
+	elem, ok := c.entries[key]
+	var entry *cacheEntry
+	if ok {
+		entry = elem.Value.(*cacheEntry)
+		c.lru.MoveToFront(elem)
+	}
+	c.mtx.Unlock()
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	resp, err := decodeResponse(entry)
+	if err != nil {
+		logger.Warn("failed to decode cached query response", "error", err)
+		return nil, false
+	}
+	return resp, true
+}
+
+func (c *queryCache) set(key string, resp *backend.QueryDataResponse) error {
+	if c.ttl <= 0 || hasError(resp) {
+		return nil
+	}
+	entry, err := c.encode(key, resp)
+	if err != nil {
+		return err
+	}
+	c.store(entry)
+	return nil
+}
+
+func (c *queryCache) encode(key string, resp *backend.QueryDataResponse) (*cacheEntry, error) {
+	entry := &cacheEntry{
+		key:     key,
+		frames:  make(map[string][][]byte, len(resp.Responses)),
+		errors:  map[string]error{},
+		expires: c.now().Add(c.ttl),
+	}
+	for refID, r := range resp.Responses {
+		b, err := data.Frames(r.Frames).MarshalArrow()
+		if err != nil {
+			return nil, err
+		}
+		entry.frames[refID] = b
+		if r.Error != nil {
+			entry.errors[refID] = r.Error
+		}
+	}
+	return entry, nil
+}
+
+// store adds the entry to the cache and evicts the least recently used entries above
+// maxEntries. Expired entries are removed at most once per time to live.
+func (c *queryCache) store(entry *cacheEntry) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	now := c.now()
+	if now.Sub(c.lastSweep) > c.ttl {
+		for _, elem := range c.entries {
+			if !now.Before(elem.Value.(*cacheEntry).expires) {
+				c.remove(elem)
+			}
+		}
+		c.lastSweep = now
+	}
+	if elem, ok := c.entries[entry.key]; ok {
+		c.remove(elem)
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove deletes the element of the cache, c.mtx must be held.
+func (c *queryCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
+
+func hasError(resp *backend.QueryDataResponse) bool {
+	for _, r := range resp.Responses {
+		if r.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeResponse(entry *cacheEntry) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for refID, b := range entry.frames {
+		frames, err := data.UnmarshalArrowFrames(b)
+		if err != nil {
+			return nil, err
+		}
+		resp.Responses[refID] = backend.DataResponse{Frames: frames, Error: entry.errors[refID]}
+	}
+	return resp, nil
+}
@@ -0,0 +1,242 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheTestNode(query string, from time.Time) *DSNode {
+	return &DSNode{
+		baseNode:   baseNode{refID: "A"},
+		orgID:      1,
+		query:      json.RawMessage(query),
+		datasource: &models.DataSource{Uid: "ds", Type: "test"},
+		timeRange: TimeRange{
+			From: from,
+			To:   from.Add(time.Hour),
+		},
+	}
+}
+
+func cacheTestResponse() *backend.QueryDataResponse {
+	resp := backend.NewQueryDataResponse()
+	resp.Responses["A"] = backend.DataResponse{
+		Frames: data.Frames{data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+			data.NewField("value", data.Labels{"host": "a"}, []float64{2}),
+		)},
+	}
+	return resp
+}
+
+func TestQueryCache(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("identical queries hit the cache until the ttl expires", func(t *testing.T) {
+		c := newQueryCache(time.Minute, 10*time.Second, 100, false)
+		now := start
+		c.now = func() time.Time { return now }
+
+		var calls int
+		queryFn := func(ctx context.Context) (*backend.QueryDataResponse, error) {
+			calls++
+			return cacheTestResponse(), nil
+		}
+
+		resp, err := c.queryData(context.Background(), cacheTestNode(`{"expr":"up"}`, start), queryFn)
+		require.NoError(t, err)
+		require.Equal(t, cacheTestResponse(), resp)
+
+		// the time range is within the same alignment interval
+		resp, err = c.queryData(context.Background(), cacheTestNode(`{"expr":"up"}`, start.Add(5*time.Second)), queryFn)
+		require.NoError(t, err)
+		require.Equal(t, cacheTestResponse(), resp)
+		require.Equal(t, 1, calls)
+
+		// cached frames are copies, so modifying them does not change the cache
+		resp.Responses["A"].Frames[0].Name = "changed"
+		resp, err = c.queryData(context.Background(), cacheTestNode(`{"expr":"up"}`, start), queryFn)
+		require.NoError(t, err)
+		require.Equal(t, "", resp.Responses["A"].Frames[0].Name)
+		require.Equal(t, 1, calls)
+
+		_, err = c.queryData(context.Background(), cacheTestNode(`{"expr":"down"}`, start), queryFn)
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+
+		_, err = c.queryData(context.Background(), cacheTestNode(`{"expr":"up"}`, start.Add(time.Minute)), queryFn)
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+
+		now = start.Add(time.Minute)
+		_, err = c.queryData(context.Background(), cacheTestNode(`{"expr":"up"}`, start), queryFn)
+		require.NoError(t, err)
+		require.Equal(t, 4, calls)
+	})
+
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		c := newQueryCache(time.Minute, 0, 100, false)
+		var calls int
+		queryFn := func(ctx context.Context) (*backend.QueryDataResponse, error) {
+			calls++
+			resp := backend.NewQueryDataResponse()
+			resp.Responses["A"] = backend.DataResponse{Error: errors.New("boom")}
+			return resp, nil
+		}
+
+		for i := 0; i < 2; i++ {
+			resp, err := c.queryData(context.Background(), cacheTestNode(`{}`, start), queryFn)
+			require.NoError(t, err)
+			require.EqualError(t, resp.Responses["A"].Error, "boom")
+		}
+		require.Equal(t, 2, calls)
+		require.Empty(t, c.entries)
+	})
+
+	t.Run("identical queries in flight are sent once", func(t *testing.T) {
+		c := newQueryCache(0, 0, 100, true)
+		var calls int32
+		release := make(chan struct{})
+		queryFn := func(ctx context.Context) (*backend.QueryDataResponse, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return cacheTestResponse(), nil
+		}
+
+		const n = 5
+		var wg sync.WaitGroup
+		results := make([]*backend.QueryDataResponse, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				resp, err := c.queryData(context.Background(), cacheTestNode(`{}`, start), queryFn)
+				require.NoError(t, err)
+				results[i] = resp
+			}(i)
+		}
+		// give the goroutines time to join the query in flight
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		for _, resp := range results {
+			require.Equal(t, cacheTestResponse(), resp)
+		}
+		require.Empty(t, c.entries)
+	})
+
+	t.Run("queries in flight are not canceled with the caller that started them", func(t *testing.T) {
+		c := newQueryCache(0, 0, 100, true)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		queryFn := func(ctx context.Context) (*backend.QueryDataResponse, error) {
+			close(started)
+			<-release
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return cacheTestResponse(), nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		firstErr := make(chan error)
+		go func() {
+			_, err := c.queryData(ctx, cacheTestNode(`{}`, start), queryFn)
+			firstErr <- err
+		}()
+		<-started
+
+		second := make(chan *backend.QueryDataResponse)
+		go func() {
+			resp, err := c.queryData(context.Background(), cacheTestNode(`{}`, start), queryFn)
+			require.NoError(t, err)
+			second <- resp
+		}()
+		// give the second goroutine time to join the query in flight
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-firstErr, context.Canceled)
+
+		close(release)
+		require.Equal(t, cacheTestResponse(), <-second)
+	})
+
+	t.Run("evicts the least recently used responses", func(t *testing.T) {
+		c := newQueryCache(time.Minute, 0, 2, false)
+		var calls int
+		queryFn := func(ctx context.Context) (*backend.QueryDataResponse, error) {
+			calls++
+			return cacheTestResponse(), nil
+		}
+		query := func(expr string) {
+			_, err := c.queryData(context.Background(), cacheTestNode(`{"expr":"`+expr+`"}`, start), queryFn)
+			require.NoError(t, err)
+		}
+
+		query("a")
+		query("b")
+		query("a")
+		query("c")
+		require.Equal(t, 3, calls)
+		require.Len(t, c.entries, 2)
+		require.Equal(t, 2, c.lru.Len())
+
+		// b was evicted, a is still cached
+		query("a")
+		require.Equal(t, 3, calls)
+		query("b")
+		require.Equal(t, 4, calls)
+	})
+
+	t.Run("identical queries in flight get their own copy of errors", func(t *testing.T) {
+		c := newQueryCache(time.Minute, 0, 100, true)
+		release := make(chan struct{})
+		queryFn := func(ctx context.Context) (*backend.QueryDataResponse, error) {
+			<-release
+			resp := cacheTestResponse()
+			resp.Responses["B"] = backend.DataResponse{Error: errors.New("boom")}
+			return resp, nil
+		}
+
+		const n = 2
+		var wg sync.WaitGroup
+		results := make([]*backend.QueryDataResponse, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				resp, err := c.queryData(context.Background(), cacheTestNode(`{}`, start), queryFn)
+				require.NoError(t, err)
+				results[i] = resp
+			}(i)
+		}
+		// give the goroutines time to join the query in flight
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		require.EqualError(t, results[0].Responses["B"].Error, "boom")
+		require.EqualError(t, results[1].Responses["B"].Error, "boom")
+		results[0].Responses["A"].Frames[0].Name = "changed"
+		require.Equal(t, "", results[1].Responses["A"].Frames[0].Name)
+		require.Empty(t, c.entries)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		require.Nil(t, newQueryCache(0, 10*time.Second, 100, false))
+		require.Nil(t, newQueryCache(time.Minute, 10*time.Second, 0, false))
+	})
+}
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
 )
 
 // Command is an interface for all expression commands.
@@ -93,15 +95,10 @@ func NewReduceCommand(refID, reducer, varToReduce string, mapper mathexp.ReduceM
 
 // UnmarshalReduceCommand creates a MathCMD from Grafana's frontend query.
 func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
-	rawVar, ok := rn.Query["expression"]
-	if !ok {
-		return nil, fmt.Errorf("no variable specified to reduce for refId %v", rn.RefID)
-	}
-	varToReduce, ok := rawVar.(string)
-	if !ok {
-		return nil, fmt.Errorf("expected reduce variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	varToReduce, err := unmarshalVar(rn, "reduce")
+	if err != nil {
+		return nil, err
 	}
-	varToReduce = strings.TrimPrefix(varToReduce, "$")
 
 	rawReducer, ok := rn.Query["reducer"]
 	if !ok {
@@ -197,16 +194,10 @@ func NewResampleCommand(refID, rawWindow, varToResample string, downsampler stri
 
 // UnmarshalResampleCommand creates a ResampleCMD from Grafana's frontend query.
 func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
-	rawVar, ok := rn.Query["expression"]
-	if !ok {
-		return nil, fmt.Errorf("no variable to resample for refId %v", rn.RefID)
-	}
-	varToReduce, ok := rawVar.(string)
-	if !ok {
-		return nil, fmt.Errorf("expected resample input variable to be type string, but got type %T for refId %v", rawVar, rn.RefID)
+	varToResample, err := unmarshalVar(rn, "resample")
+	if err != nil {
+		return nil, err
 	}
-	varToReduce = strings.TrimPrefix(varToReduce, "$")
-	varToResample := varToReduce
 
 	rawWindow, ok := rn.Query["window"]
 	if !ok {
@@ -262,6 +253,402 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 	return newRes, nil
 }
 
+// AggregateCommand is an expression command that groups the series or numbers of a variable
+// by a set of labels and combines each group, such as a sum of all series that share a job label.
+type AggregateCommand struct {
+	Function       string
+	VarToAggregate string
+	By             []string
+	K              int
+	refID          string
+}
+
+// NewAggregateCommand creates a new AggregateCommand.
+func NewAggregateCommand(refID, function, varToAggregate string, by []string, k int) (*AggregateCommand, error) {
+	if err := mathexp.ValidateAggregation(function, k); err != nil {
+		return nil, err
+	}
+	return &AggregateCommand{
+		Function:       function,
+		VarToAggregate: varToAggregate,
+		By:             by,
+		K:              k,
+		refID:          refID,
+	}, nil
+}
+
+// UnmarshalAggregateCommand creates an AggregateCommand from Grafana's frontend query.
+func UnmarshalAggregateCommand(rn *rawNode) (*AggregateCommand, error) {
+	varToAggregate, err := unmarshalVar(rn, "aggregate")
+	if err != nil {
+		return nil, err
+	}
+
+	rawFunction, ok := rn.Query["function"]
+	if !ok {
+		return nil, fmt.Errorf("no aggregation function specified for refId %v", rn.RefID)
+	}
+	function, ok := rawFunction.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected aggregation function to be a string, got %T for refId %v", rawFunction, rn.RefID)
+	}
+
+	var by []string
+	if rawBy, ok := rn.Query["by"]; ok && rawBy != nil {
+		labels, ok := rawBy.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected aggregate by to be a list of label names, got %T for refId %v", rawBy, rn.RefID)
+		}
+		for _, l := range labels {
+			name, ok := l.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected aggregate by label name to be a string, got %T for refId %v", l, rn.RefID)
+			}
+			by = append(by, name)
+		}
+	}
+
+	k := 0
+	if rawK, ok := rn.Query["k"]; ok {
+		floatK, ok := rawK.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected aggregate k to be a number, got %T for refId %v", rawK, rn.RefID)
+		}
+		k = int(floatK)
+	}
+
+	return NewAggregateCommand(rn.RefID, function, varToAggregate, by, k)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ga *AggregateCommand) NeedsVars() []string {
+	return []string{ga.VarToAggregate}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ga *AggregateCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	vals, err := mathexp.Aggregate(ga.refID, vars[ga.VarToAggregate].Values, ga.Function, ga.By, ga.K)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	return mathexp.Results{Values: vals}, nil
+}
+
+// SQLCommand is an expression command that runs a SQL query over the results
+// of other queries and expressions. Each variable is a table named after its refId.
+type SQLCommand struct {
+	RawSQL string
+	Tables []string
+	refID  string
+}
+
+// NewSQLCommand creates a new SQLCommand. It will return an error if the query
+// is not a single SELECT statement.
+func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
+	tables, err := sql.TableNames(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLCommand{
+		RawSQL: rawSQL,
+		Tables: tables,
+		refID:  refID,
+	}, nil
+}
+
+// UnmarshalSQLCommand creates a SQLCommand from Grafana's frontend query.
+func UnmarshalSQLCommand(rn *rawNode) (*SQLCommand, error) {
+	rawExpr, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("sql command for refId %v is missing an expression", rn.RefID)
+	}
+	expr, ok := rawExpr.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected sql command for refId %v expression to be a string, got %T", rn.RefID, rawExpr)
+	}
+
+	cmd, err := NewSQLCommand(rn.RefID, expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sql command in '%v': %w", rn.RefID, err)
+	}
+	return cmd, nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gs *SQLCommand) NeedsVars() []string {
+	return gs.Tables
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gs *SQLCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	tables := make(map[string]*data.Frame, len(gs.Tables))
+	for _, name := range gs.Tables {
+		table, err := sql.TableFromValues(name, vars[name].Values)
+		if err != nil {
+			return mathexp.Results{}, fmt.Errorf("failed to convert %v to a table: %w", name, err)
+		}
+		tables[name] = table
+	}
+
+	frame, err := sql.Query(ctx, gs.RawSQL, tables)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to execute sql command for refId %v: %w", gs.refID, err)
+	}
+
+	vals, err := valuesFromTable(frame)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	return mathexp.Results{Values: vals}, nil
+}
+
+// AnomalyCommand is an expression command that compares each point of a time series to
+// a baseline of the same series, such as the z-score against a trailing window or the
+// percentage deviation from the same time last week.
+type AnomalyCommand struct {
+	Function  string
+	Window    time.Duration
+	VarToEval string
+	refID     string
+}
+
+const (
+	// AnomalyZScore is the anomaly function for the z-score against a trailing window.
+	AnomalyZScore = "zscore"
+	// AnomalyDeviation is the anomaly function for the percentage deviation from an earlier time.
+	AnomalyDeviation = "deviation"
+)
+
+// NewAnomalyCommand creates a new AnomalyCommand. The window is the trailing window for
+// the z-score or how far back the baseline is for the deviation.
+func NewAnomalyCommand(refID, function, rawWindow, varToEval string) (*AnomalyCommand, error) {
+	if function != AnomalyZScore && function != AnomalyDeviation {
+		return nil, fmt.Errorf("anomaly function %v is not supported, supported: [%v,%v]", function, AnomalyZScore, AnomalyDeviation)
+	}
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse anomaly "window" duration field %q: %w`, rawWindow, err)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("anomaly window must be greater than zero, got %v", rawWindow)
+	}
+	return &AnomalyCommand{
+		Function:  function,
+		Window:    window,
+		VarToEval: varToEval,
+		refID:     refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	varToEval, err := unmarshalVar(rn, "anomaly")
+	if err != nil {
+		return nil, err
+	}
+
+	rawFunction, ok := rn.Query["function"]
+	if !ok {
+		return nil, fmt.Errorf("no anomaly function specified for refId %v", rn.RefID)
+	}
+	function, ok := rawFunction.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected anomaly function to be a string, got %T for refId %v", rawFunction, rn.RefID)
+	}
+
+	window := "1w"
+	if function == AnomalyZScore {
+		window = "1h"
+	}
+	if rawWindow, ok := rn.Query["window"]; ok {
+		window, ok = rawWindow.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected anomaly window to be a string, got %T for refId %v", rawWindow, rn.RefID)
+		}
+	}
+
+	return NewAnomalyCommand(rn.RefID, function, window, varToEval)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ga *AnomalyCommand) NeedsVars() []string {
+	return []string{ga.VarToEval}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ga *AnomalyCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[ga.VarToEval].Values {
+		series, ok := val.(mathexp.Series)
+		if !ok {
+			return newRes, fmt.Errorf("can only detect anomalies on type series, got type %v", val.Type())
+		}
+		switch ga.Function {
+		case AnomalyZScore:
+			newRes.Values = append(newRes.Values, series.ZScore(ga.refID, ga.Window))
+		case AnomalyDeviation:
+			newRes.Values = append(newRes.Values, series.Deviation(ga.refID, ga.Window))
+		}
+	}
+	return newRes, nil
+}
+
+// ForecastCommand is an expression command that predicts the value of each time series
+// at a time after the end of the query time range.
+type ForecastCommand struct {
+	Function    string
+	Horizon     time.Duration
+	HoltWinters mathexp.HoltWintersParams
+	VarToEval   string
+	TimeRange   TimeRange
+	refID       string
+}
+
+const (
+	// ForecastLinear is the forecast function for a linear regression prediction.
+	ForecastLinear = "linear"
+	// ForecastHoltWinters is the forecast function for a Holt-Winters exponential smoothing prediction.
+	ForecastHoltWinters = "holt_winters"
+)
+
+// NewForecastCommand creates a new ForecastCommand. The horizon is how long after the end of
+// the time range the value is predicted for.
+func NewForecastCommand(refID, function, rawHorizon, varToEval string, hw mathexp.HoltWintersParams, tr TimeRange) (*ForecastCommand, error) {
+	switch function {
+	case ForecastLinear:
+	case ForecastHoltWinters:
+		if err := hw.Validate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("forecast function %v is not supported, supported: [%v,%v]", function, ForecastLinear, ForecastHoltWinters)
+	}
+	horizon, err := gtime.ParseDuration(rawHorizon)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, rawHorizon, err)
+	}
+	if horizon < 0 {
+		return nil, fmt.Errorf("forecast horizon must not be negative, got %v", rawHorizon)
+	}
+	return &ForecastCommand{
+		Function:    function,
+		Horizon:     horizon,
+		HoltWinters: hw,
+		VarToEval:   varToEval,
+		TimeRange:   tr,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	varToEval, err := unmarshalVar(rn, "forecast")
+	if err != nil {
+		return nil, err
+	}
+
+	rawFunction, ok := rn.Query["function"]
+	if !ok {
+		return nil, fmt.Errorf("no forecast function specified for refId %v", rn.RefID)
+	}
+	function, ok := rawFunction.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected forecast function to be a string, got %T for refId %v", rawFunction, rn.RefID)
+	}
+
+	horizon := "0s"
+	if rawHorizon, ok := rn.Query["horizon"]; ok {
+		horizon, ok = rawHorizon.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected forecast horizon to be a string, got %T for refId %v", rawHorizon, rn.RefID)
+		}
+	}
+
+	hw := mathexp.HoltWintersParams{Level: 0.5, Trend: 0.5, Seasonal: 0.5}
+	if settings, ok := rn.Query["settings"]; ok {
+		s, ok := settings.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected settings to be an object, got %T for refId %v", settings, rn.RefID)
+		}
+		for name, factor := range map[string]*float64{"level": &hw.Level, "trend": &hw.Trend, "seasonal": &hw.Seasonal} {
+			rawFactor, ok := s[name]
+			if !ok {
+				continue
+			}
+			f, ok := rawFactor.(float64)
+			if !ok {
+				return nil, fmt.Errorf("expected settings.%v to be a number, got %T for refId %v", name, rawFactor, rn.RefID)
+			}
+			*factor = f
+		}
+		if rawSeason, ok := s["season"]; ok {
+			season, ok := rawSeason.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected settings.season to be a string, got %T for refId %v", rawSeason, rn.RefID)
+			}
+			if season != "" {
+				hw.Season, err = gtime.ParseDuration(season)
+				if err != nil {
+					return nil, fmt.Errorf(`failed to parse forecast settings "season" duration field %q: %w`, season, err)
+				}
+			}
+		}
+	}
+
+	return NewForecastCommand(rn.RefID, function, horizon, varToEval, hw, rn.TimeRange)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gf *ForecastCommand) NeedsVars() []string {
+	return []string{gf.VarToEval}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gf *ForecastCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	at := gf.TimeRange.To.Add(gf.Horizon)
+	for _, val := range vars[gf.VarToEval].Values {
+		series, ok := val.(mathexp.Series)
+		if !ok {
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+		var num mathexp.Number
+		switch gf.Function {
+		case ForecastLinear:
+			num = series.PredictLinear(gf.refID, at)
+		case ForecastHoltWinters:
+			var err error
+			num, err = series.HoltWinters(gf.refID, at, gf.HoltWinters)
+			if err != nil {
+				return newRes, err
+			}
+		}
+		newRes.Values = append(newRes.Values, num)
+	}
+	return newRes, nil
+}
+
+// unmarshalVar returns the variable in the expression property of a query without the $ prefix.
+func unmarshalVar(rn *rawNode, cmdName string) (string, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return "", fmt.Errorf("no variable specified for %v command for refId %v", cmdName, rn.RefID)
+	}
+	v, ok := rawVar.(string)
+	if !ok {
+		return "", fmt.Errorf("expected %v variable to be a string, got %T for refId %v", cmdName, rawVar, rn.RefID)
+	}
+	return strings.TrimPrefix(v, "$"), nil
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -276,6 +663,14 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeAggregate is the CMDType for a label based aggregation expression.
+	TypeAggregate
+	// TypeSQL is the CMDType for a SQL query over the results of other queries.
+	TypeSQL
+	// TypeAnomaly is the CMDType for comparing time series to a baseline.
+	TypeAnomaly
+	// TypeForecast is the CMDType for predicting future values of time series.
+	TypeForecast
 )
 
 func (gt CommandType) String() string {
@@ -288,6 +683,14 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeAggregate:
+		return "aggregate"
+	case TypeSQL:
+		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
 	default:
 		return "unknown"
 	}
@@ -304,6 +707,14 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "aggregate":
+		return TypeAggregate, nil
+	case "sql":
+		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -1,13 +1,17 @@
 package expr
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
 
 func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
@@ -89,3 +93,250 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 		})
 	}
 }
+
+func Test_UnmarshalAggregateCommand(t *testing.T) {
+	var tests = []struct {
+		name       string
+		query      string
+		isError    bool
+		expectedBy []string
+		expectedK  int
+	}{
+		{
+			name:       "sum by labels",
+			query:      `{ "expression" : "$A", "function": "sum", "by": ["job", "env"] }`,
+			expectedBy: []string{"job", "env"},
+		},
+		{
+			name:  "sum without labels",
+			query: `{ "expression" : "$A", "function": "sum" }`,
+		},
+		{
+			name:       "topk with k",
+			query:      `{ "expression" : "$A", "function": "topk", "by": ["job"], "k": 3 }`,
+			expectedBy: []string{"job"},
+			expectedK:  3,
+		},
+		{
+			name:    "error when topk has no k",
+			query:   `{ "expression" : "$A", "function": "topk" }`,
+			isError: true,
+		},
+		{
+			name:    "error when function is unknown",
+			query:   `{ "expression" : "$A", "function": "median" }`,
+			isError: true,
+		},
+		{
+			name:    "error when by is not a list",
+			query:   `{ "expression" : "$A", "function": "sum", "by": "job" }`,
+			isError: true,
+		},
+		{
+			name:    "error when function is missing",
+			query:   `{ "expression" : "$A" }`,
+			isError: true,
+		},
+		{
+			name:    "error when expression is missing",
+			query:   `{ "function": "sum" }`,
+			isError: true,
+		},
+		{
+			name:    "error when expression is not a string",
+			query:   `{ "expression" : 1, "function": "sum" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalAggregateCommand(&rawNode{
+				RefID: "B",
+				Query: qmap,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "A", cmd.VarToAggregate)
+			require.Equal(t, test.expectedBy, cmd.By)
+			require.Equal(t, test.expectedK, cmd.K)
+		})
+	}
+}
+
+func TestSQLCommand(t *testing.T) {
+	inventory := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("cores", nil, []int64{4, 8}),
+	)
+	usageA := mathexp.NewSeries("B", data.Labels{"host": "a"}, 2)
+	usageA.SetPoint(0, time.Unix(10, 0), float64Pointer(1))
+	usageA.SetPoint(1, time.Unix(20, 0), float64Pointer(2))
+	usageB := mathexp.NewSeries("B", data.Labels{"host": "b"}, 2)
+	usageB.SetPoint(0, time.Unix(10, 0), float64Pointer(4))
+	usageB.SetPoint(1, time.Unix(20, 0), float64Pointer(6))
+
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: inventory}}},
+		"B": mathexp.Results{Values: mathexp.Values{usageA, usageB}},
+	}
+
+	t.Run("string and numeric columns become numbers", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT A.host, MAX(B.value) / A.cores AS utilization FROM A JOIN B ON A.host = B.host GROUP BY A.host")
+		require.NoError(t, err)
+		require.Equal(t, []string{"A", "B"}, cmd.NeedsVars())
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		for i, expected := range []float64{0.5, 0.75} {
+			n, ok := res.Values[i].(mathexp.Number)
+			require.True(t, ok)
+			require.Equal(t, expected, *n.GetFloat64Value())
+		}
+		require.Equal(t, data.Labels{"host": "b"}, res.Values[1].GetLabels())
+	})
+
+	t.Run("time, string and numeric columns become series", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT B.time, B.host, B.value / A.cores AS utilization FROM B JOIN A USING (host) ORDER BY B.time")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		for _, v := range res.Values {
+			require.Equal(t, parse.TypeSeriesSet, v.Type())
+		}
+	})
+
+	t.Run("other results are tables", func(t *testing.T) {
+		cmd, err := NewSQLCommand("C", "SELECT host, cores, cores * 2 AS threads FROM A")
+		require.NoError(t, err)
+
+		res, err := cmd.Execute(context.Background(), vars)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeTableData, res.Values[0].Type())
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := NewSQLCommand("C", "DROP TABLE A")
+		require.Error(t, err)
+	})
+}
+
+func float64Pointer(f float64) *float64 {
+	return &f
+}
+
+func Test_UnmarshalAnomalyCommand(t *testing.T) {
+	var tests = []struct {
+		name           string
+		query          string
+		isError        bool
+		expectedWindow time.Duration
+	}{
+		{
+			name:           "zscore with default window",
+			query:          `{ "expression" : "$A", "function": "zscore" }`,
+			expectedWindow: time.Hour,
+		},
+		{
+			name:           "deviation with default window",
+			query:          `{ "expression" : "$A", "function": "deviation" }`,
+			expectedWindow: 7 * 24 * time.Hour,
+		},
+		{
+			name:           "zscore with window",
+			query:          `{ "expression" : "$A", "function": "zscore", "window": "30m" }`,
+			expectedWindow: 30 * time.Minute,
+		},
+		{
+			name:    "error when function is unknown",
+			query:   `{ "expression" : "$A", "function": "mad" }`,
+			isError: true,
+		},
+		{
+			name:    "error when window is not a duration",
+			query:   `{ "expression" : "$A", "function": "zscore", "window": "soon" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{RefID: "B", Query: qmap})
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+			require.Equal(t, test.expectedWindow, cmd.Window)
+		})
+	}
+}
+
+func Test_UnmarshalForecastCommand(t *testing.T) {
+	var tests = []struct {
+		name            string
+		query           string
+		isError         bool
+		expectedHorizon time.Duration
+		expectedParams  mathexp.HoltWintersParams
+	}{
+		{
+			name:            "linear with horizon",
+			query:           `{ "expression" : "$A", "function": "linear", "horizon": "4h" }`,
+			expectedHorizon: 4 * time.Hour,
+			expectedParams:  mathexp.HoltWintersParams{Level: 0.5, Trend: 0.5, Seasonal: 0.5},
+		},
+		{
+			name:           "holt winters with settings",
+			query:          `{ "expression" : "$A", "function": "holt_winters", "settings": { "level": 0.3, "trend": 0.1, "seasonal": 0.2, "season": "1d" } }`,
+			expectedParams: mathexp.HoltWintersParams{Level: 0.3, Trend: 0.1, Seasonal: 0.2, Season: 24 * time.Hour},
+		},
+		{
+			name:    "error when smoothing factor is out of range",
+			query:   `{ "expression" : "$A", "function": "holt_winters", "settings": { "level": 2 } }`,
+			isError: true,
+		},
+		{
+			name:    "error when horizon is negative",
+			query:   `{ "expression" : "$A", "function": "linear", "horizon": "-1h" }`,
+			isError: true,
+		},
+		{
+			name:    "error when function is unknown",
+			query:   `{ "expression" : "$A", "function": "arima" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalForecastCommand(&rawNode{RefID: "B", Query: qmap})
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedHorizon, cmd.Horizon)
+			require.Equal(t, test.expectedParams, cmd.HoltWinters)
+		})
+	}
+}
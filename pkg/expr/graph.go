@@ -204,6 +204,10 @@ func buildGraphEdges(dp *simple.DirectedGraph, registry map[string]Node) error {
 				}
 			}
 
+			if cmdNode.CMDType == TypeSQL && neededNode.NodeType() == TypeDatasourceNode {
+				neededNode.(*DSNode).sqlInput = true
+			}
+
 			edge := dp.NewEdge(neededNode, cmdNode)
 
 			dp.SetEdge(edge)
@@ -248,3 +248,51 @@ func getRefIDOrder(nodes []Node) []string {
 	}
 	return ids
 }
+
+func TestServicebuildPipeLine_SQLInput(t *testing.T) {
+	req := &Request{
+		Queries: []Query{
+			{
+				RefID:      "A",
+				DataSource: DataSourceModel(),
+				JSON: json.RawMessage(`{
+					"expression": "SELECT * FROM B",
+					"type": "sql"
+				}`),
+			},
+			{
+				RefID:      "C",
+				DataSource: DataSourceModel(),
+				JSON: json.RawMessage(`{
+					"expression": "D",
+					"reducer": "mean",
+					"type": "reduce"
+				}`),
+			},
+			{
+				RefID: "B",
+				DataSource: &models.DataSource{
+					Uid: "Fake",
+				},
+			},
+			{
+				RefID: "D",
+				DataSource: &models.DataSource{
+					Uid: "Fake",
+				},
+			},
+		},
+	}
+
+	s := Service{}
+	nodes, err := s.buildPipeline(req)
+	require.NoError(t, err)
+
+	sqlInputs := map[string]bool{}
+	for _, n := range nodes {
+		if dn, ok := n.(*DSNode); ok {
+			sqlInputs[n.RefID()] = dn.sqlInput
+		}
+	}
+	require.Equal(t, map[string]bool{"B": true, "D": false}, sqlInputs)
+}
@@ -0,0 +1,296 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// AggregateFunc combines the values of all the members of a group into a single value.
+// Only non-null values are passed to the function, and it is never called with an empty slice.
+type AggregateFunc = func(vals []float64) float64
+
+// GetAggregateFunc returns the AggregateFunc with the given name. The topk
+// aggregation is not a reduction and is handled separately by Aggregate.
+func GetAggregateFunc(name string) (AggregateFunc, error) {
+	switch strings.ToLower(name) {
+	case "sum":
+		return aggSum, nil
+	case "avg", "mean":
+		return func(vals []float64) float64 {
+			return aggSum(vals) / float64(len(vals))
+		}, nil
+	case "min":
+		return func(vals []float64) float64 {
+			f := vals[0]
+			for _, v := range vals[1:] {
+				f = math.Min(f, v)
+			}
+			return f
+		}, nil
+	case "max":
+		return func(vals []float64) float64 {
+			f := vals[0]
+			for _, v := range vals[1:] {
+				f = math.Max(f, v)
+			}
+			return f
+		}, nil
+	case "count":
+		return func(vals []float64) float64 {
+			return float64(len(vals))
+		}, nil
+	default:
+		return nil, fmt.Errorf("aggregation %v not implemented", name)
+	}
+}
+
+func aggSum(vals []float64) float64 {
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	return sum
+}
+
+// ValidateAggregation returns an error if the aggregation function is unknown
+// or if the parameters are not valid for it.
+func ValidateAggregation(name string, k int) error {
+	if strings.ToLower(name) == "topk" {
+		if k <= 0 {
+			return fmt.Errorf("topk aggregation requires k to be greater than zero, got %v", k)
+		}
+		return nil
+	}
+	_, err := GetAggregateFunc(name)
+	return err
+}
+
+// aggregationGroup holds the members of a group of values that share the same
+// values for the labels that are grouped by.
+type aggregationGroup struct {
+	labels  data.Labels
+	members []Value
+}
+
+// groupByLabels splits values into groups based on the values of the given label names.
+// A value that does not have one of the labels is grouped with the values that do not have it either.
+// When by is empty all values are in the same group. Groups are sorted by their labels.
+func groupByLabels(vals Values, by []string) []*aggregationGroup {
+	groups := map[string]*aggregationGroup{}
+	for _, v := range vals {
+		var labels data.Labels
+		if len(by) > 0 {
+			labels = data.Labels{}
+			for _, name := range by {
+				if lv, ok := v.GetLabels()[name]; ok {
+					labels[name] = lv
+				}
+			}
+		}
+		key := labels.String()
+		g, ok := groups[key]
+		if !ok {
+			g = &aggregationGroup{labels: labels}
+			groups[key] = g
+		}
+		g.members = append(g.members, v)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sorted := make([]*aggregationGroup, 0, len(keys))
+	for _, k := range keys {
+		sorted = append(sorted, groups[k])
+	}
+	return sorted
+}
+
+// Aggregate groups the values by the given label names and combines each group into one
+// value using the named aggregation function (sum, avg, min, max or count). The result of
+// each group has only the grouped labels. The topk aggregation instead keeps the k
+// largest members of each group with their original labels.
+// All the values must either be Numbers or Series.
+func Aggregate(refID string, vals Values, fn string, by []string, k int) (Values, error) {
+	if err := ValidateAggregation(fn, k); err != nil {
+		return nil, err
+	}
+	if len(vals) == 0 {
+		return Values{}, nil
+	}
+	valType := vals[0].Type()
+	for _, v := range vals {
+		if v.Type() != valType {
+			return nil, fmt.Errorf("can only aggregate values of the same type, got %v and %v", valType, v.Type())
+		}
+		if v.Type() != parse.TypeNumberSet && v.Type() != parse.TypeSeriesSet {
+			return nil, fmt.Errorf("can only aggregate type number or series, got type %v", v.Type())
+		}
+	}
+
+	topK := strings.ToLower(fn) == "topk"
+	var aggFunc AggregateFunc
+	if !topK {
+		aggFunc, _ = GetAggregateFunc(fn)
+	}
+
+	newVals := Values{}
+	for _, g := range groupByLabels(vals, by) {
+		switch valType {
+		case parse.TypeNumberSet:
+			if topK {
+				newVals = append(newVals, topKNumbers(refID, g.members, k)...)
+				continue
+			}
+			newVals = append(newVals, aggregateNumbers(refID, g, aggFunc))
+		case parse.TypeSeriesSet:
+			if topK {
+				newVals = append(newVals, topKSeries(refID, g.members, k)...)
+				continue
+			}
+			newVals = append(newVals, aggregateSeries(refID, g, aggFunc))
+		}
+	}
+	return newVals, nil
+}
+
+func aggregateNumbers(refID string, g *aggregationGroup, aggFunc AggregateFunc) Number {
+	n := NewNumber(refID, g.labels)
+	vals := make([]float64, 0, len(g.members))
+	for _, m := range g.members {
+		if f := m.(Number).GetFloat64Value(); f != nil {
+			vals = append(vals, *f)
+		}
+	}
+	if len(vals) > 0 {
+		f := aggFunc(vals)
+		n.SetValue(&f)
+	}
+	return n
+}
+
+// aggregateSeries combines the points of all series in the group that share the same timestamp.
+// The resulting series has a point for every timestamp found in any of the series.
+func aggregateSeries(refID string, g *aggregationGroup, aggFunc AggregateFunc) Series {
+	times, byTime := pointsByTime(g.members)
+	s := NewSeries(refID, g.labels, len(times))
+	for i, t := range times {
+		vals := make([]float64, 0, len(g.members))
+		for _, p := range byTime[t] {
+			if p.f != nil {
+				vals = append(vals, *p.f)
+			}
+		}
+		var f *float64
+		if len(vals) > 0 {
+			v := aggFunc(vals)
+			f = &v
+		}
+		s.SetPoint(i, time.Unix(0, t), f)
+	}
+	return s
+}
+
+type memberPoint struct {
+	member int
+	f      *float64
+}
+
+// pointsByTime returns the sorted unix nano timestamps of all the points in the series,
+// and the points of each series at each timestamp.
+func pointsByTime(members []Value) ([]int64, map[int64][]memberPoint) {
+	byTime := map[int64][]memberPoint{}
+	for i, m := range members {
+		s := m.(Series)
+		for j := 0; j < s.Len(); j++ {
+			t, f := s.GetPoint(j)
+			byTime[t.UnixNano()] = append(byTime[t.UnixNano()], memberPoint{member: i, f: f})
+		}
+	}
+	times := make([]int64, 0, len(byTime))
+	for t := range byTime {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times, byTime
+}
+
+// topKNumbers returns copies of the k Numbers with the largest values. Null and NaN values are dropped.
+func topKNumbers(refID string, members []Value, k int) Values {
+	numbers := make([]Number, 0, len(members))
+	for _, m := range members {
+		n := m.(Number)
+		if f := n.GetFloat64Value(); f != nil && !math.IsNaN(*f) {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.SliceStable(numbers, func(i, j int) bool {
+		return *numbers[i].GetFloat64Value() > *numbers[j].GetFloat64Value()
+	})
+	if len(numbers) > k {
+		numbers = numbers[:k]
+	}
+	res := make(Values, 0, len(numbers))
+	for _, n := range numbers {
+		var labels data.Labels
+		if n.GetLabels() != nil {
+			labels = n.GetLabels().Copy()
+		}
+		newN := NewNumber(refID, labels)
+		f := *n.GetFloat64Value()
+		newN.SetValue(&f)
+		res = append(res, newN)
+	}
+	return res
+}
+
+// topKSeries keeps, at each timestamp, the points of the k series with the largest values.
+// Points that are not in the top k are null. Series that are never in the top k are dropped.
+func topKSeries(refID string, members []Value, k int) Values {
+	times, byTime := pointsByTime(members)
+	result := make([]Series, len(members))
+	inTopK := make([]bool, len(members))
+	for i, m := range members {
+		var labels data.Labels
+		if m.GetLabels() != nil {
+			labels = m.GetLabels().Copy()
+		}
+		result[i] = NewSeries(refID, labels, len(times))
+	}
+	for idx, t := range times {
+		ts := time.Unix(0, t)
+		for i := range result {
+			result[i].SetPoint(idx, ts, nil)
+		}
+		points := make([]memberPoint, 0, len(byTime[t]))
+		for _, p := range byTime[t] {
+			if p.f != nil && !math.IsNaN(*p.f) {
+				points = append(points, p)
+			}
+		}
+		sort.SliceStable(points, func(i, j int) bool { return *points[i].f > *points[j].f })
+		if len(points) > k {
+			points = points[:k]
+		}
+		for _, p := range points {
+			f := *p.f
+			result[p.member].SetPoint(idx, ts, &f)
+			inTopK[p.member] = true
+		}
+	}
+	res := Values{}
+	for i, s := range result {
+		if inTopK[i] {
+			res = append(res, s)
+		}
+	}
+	return res
+}
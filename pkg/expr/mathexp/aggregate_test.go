@@ -0,0 +1,138 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateNumbers(t *testing.T) {
+	input := Values{
+		makeNumber("", data.Labels{"job": "api", "instance": "a"}, float64Pointer(1)),
+		makeNumber("", data.Labels{"job": "api", "instance": "b"}, float64Pointer(5)),
+		makeNumber("", data.Labels{"job": "db", "instance": "c"}, float64Pointer(3)),
+		makeNumber("", data.Labels{"job": "db", "instance": "d"}, nil),
+		makeNumber("", data.Labels{"instance": "e"}, float64Pointer(7)),
+	}
+
+	var tests = []struct {
+		name    string
+		fn      string
+		by      []string
+		k       int
+		errIs   require.ErrorAssertionFunc
+		results Values
+	}{
+		{
+			name:  "sum by job",
+			fn:    "sum",
+			by:    []string{"job"},
+			errIs: require.NoError,
+			results: Values{
+				makeNumber("", data.Labels{}, float64Pointer(7)),
+				makeNumber("", data.Labels{"job": "api"}, float64Pointer(6)),
+				makeNumber("", data.Labels{"job": "db"}, float64Pointer(3)),
+			},
+		},
+		{
+			name:  "count by job skips nulls",
+			fn:    "count",
+			by:    []string{"job"},
+			errIs: require.NoError,
+			results: Values{
+				makeNumber("", data.Labels{}, float64Pointer(1)),
+				makeNumber("", data.Labels{"job": "api"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"job": "db"}, float64Pointer(1)),
+			},
+		},
+		{
+			name:  "max without grouping",
+			fn:    "max",
+			errIs: require.NoError,
+			results: Values{
+				makeNumber("", nil, float64Pointer(7)),
+			},
+		},
+		{
+			name:  "topk by job keeps original labels",
+			fn:    "topk",
+			by:    []string{"job"},
+			k:     1,
+			errIs: require.NoError,
+			results: Values{
+				makeNumber("", data.Labels{"instance": "e"}, float64Pointer(7)),
+				makeNumber("", data.Labels{"job": "api", "instance": "b"}, float64Pointer(5)),
+				makeNumber("", data.Labels{"job": "db", "instance": "c"}, float64Pointer(3)),
+			},
+		},
+		{
+			name:  "topk without k should error",
+			fn:    "topk",
+			errIs: require.Error,
+		},
+		{
+			name:  "unknown function should error",
+			fn:    "median",
+			errIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Aggregate("", input, tt.fn, tt.by, tt.k)
+			tt.errIs(t, err)
+			if err == nil {
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
+
+func TestAggregateSeries(t *testing.T) {
+	input := Values{
+		makeSeries("", data.Labels{"job": "api", "instance": "a"},
+			tp{time.Unix(5, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(2)},
+		),
+		makeSeries("", data.Labels{"job": "api", "instance": "b"},
+			tp{time.Unix(5, 0), float64Pointer(3)},
+			tp{time.Unix(10, 0), nil},
+			tp{time.Unix(15, 0), float64Pointer(4)},
+		),
+	}
+
+	t.Run("avg by job aligns points on time", func(t *testing.T) {
+		res, err := Aggregate("", input, "avg", []string{"job"}, 0)
+		require.NoError(t, err)
+		require.Equal(t, Values{
+			makeSeries("", data.Labels{"job": "api"},
+				tp{time.Unix(5, 0), float64Pointer(2)},
+				tp{time.Unix(10, 0), float64Pointer(2)},
+				tp{time.Unix(15, 0), float64Pointer(4)},
+			),
+		}, res)
+	})
+
+	t.Run("topk keeps the largest points at each time", func(t *testing.T) {
+		res, err := Aggregate("", input, "topk", nil, 1)
+		require.NoError(t, err)
+		require.Equal(t, Values{
+			makeSeries("", data.Labels{"job": "api", "instance": "a"},
+				tp{time.Unix(5, 0), nil},
+				tp{time.Unix(10, 0), float64Pointer(2)},
+				tp{time.Unix(15, 0), nil},
+			),
+			makeSeries("", data.Labels{"job": "api", "instance": "b"},
+				tp{time.Unix(5, 0), float64Pointer(3)},
+				tp{time.Unix(10, 0), nil},
+				tp{time.Unix(15, 0), float64Pointer(4)},
+			),
+		}, res)
+	})
+
+	t.Run("mixed types should error", func(t *testing.T) {
+		_, err := Aggregate("", append(input, makeNumber("", nil, float64Pointer(1))), "sum", nil, 0)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,248 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type timePoint struct {
+	t time.Time
+	v float64
+}
+
+type nullableTimePoint struct {
+	t time.Time
+	v *float64
+}
+
+// sortedPoints returns all points of the series ordered by time. The value of points that are
+// null, NaN or Inf is nil.
+func (s Series) sortedPoints() []nullableTimePoint {
+	points := make([]nullableTimePoint, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if f != nil && (math.IsNaN(*f) || math.IsInf(*f, 0)) {
+			f = nil
+		}
+		points = append(points, nullableTimePoint{t: t, v: f})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].t.Before(points[j].t) })
+	return points
+}
+
+// numericPoints returns the points of the series that are not null, NaN or Inf ordered by time.
+func (s Series) numericPoints() []timePoint {
+	points := make([]timePoint, 0, s.Len())
+	for _, p := range s.sortedPoints() {
+		if p.v != nil {
+			points = append(points, timePoint{t: p.t, v: *p.v})
+		}
+	}
+	return points
+}
+
+// ZScore returns a series with the number of standard deviations each point is away from the mean
+// of the points in the trailing window before it. A point is null if it is null or NaN itself, or
+// if there are less than two points in its window or they all have the same value.
+func (s Series) ZScore(refID string, window time.Duration) Series {
+	all := s.sortedPoints()
+	points := s.numericPoints()
+	newSeries := NewSeries(refID, s.GetLabels(), len(all))
+	// points[start:end] are the numeric points before the current point, within its window
+	start, end := 0, 0
+	for i, p := range all {
+		newSeries.SetPoint(i, p.t, nil)
+		if p.v == nil {
+			continue
+		}
+		for start < end && !points[start].t.After(p.t.Add(-window)) {
+			start++
+		}
+		prev := points[start:end]
+		end++
+		if len(prev) < 2 {
+			continue
+		}
+		var sum float64
+		for _, pp := range prev {
+			sum += pp.v
+		}
+		mean := sum / float64(len(prev))
+		var sqDiff float64
+		for _, pp := range prev {
+			sqDiff += (pp.v - mean) * (pp.v - mean)
+		}
+		stdDev := math.Sqrt(sqDiff / float64(len(prev)))
+		if stdDev == 0 {
+			continue
+		}
+		z := (*p.v - mean) / stdDev
+		newSeries.SetPoint(i, p.t, &z)
+	}
+	return newSeries
+}
+
+// Deviation returns a series with the percentage difference between each point and the value
+// of the series offset earlier, such as the same time last week. The earlier value is the last
+// point at or before that time that is not null. A point is null if it is null or NaN itself, or if
+// there is no earlier value or it is zero.
+func (s Series) Deviation(refID string, offset time.Duration) Series {
+	all := s.sortedPoints()
+	points := s.numericPoints()
+	newSeries := NewSeries(refID, s.GetLabels(), len(all))
+	for i, p := range all {
+		newSeries.SetPoint(i, p.t, nil)
+		if p.v == nil {
+			continue
+		}
+		at := p.t.Add(-offset)
+		// index of the first point after at
+		idx := sort.Search(len(points), func(j int) bool { return points[j].t.After(at) })
+		if idx == 0 {
+			continue
+		}
+		baseline := points[idx-1].v
+		if baseline == 0 {
+			continue
+		}
+		d := (*p.v - baseline) / math.Abs(baseline) * 100
+		newSeries.SetPoint(i, p.t, &d)
+	}
+	return newSeries
+}
+
+// PredictLinear returns a Number with the value of the series at the given time, predicted
+// using simple linear regression over all its points. The Number is null if the series has
+// less than two points.
+func (s Series) PredictLinear(refID string, at time.Time) Number {
+	var labels = s.GetLabels()
+	if labels != nil {
+		labels = labels.Copy()
+	}
+	n := NewNumber(refID, labels)
+	points := s.numericPoints()
+	if len(points) < 2 {
+		return n
+	}
+	// x is in seconds relative to at, so the intercept is the predicted value.
+	var sumX, sumY, sumXY, sumX2 float64
+	for _, p := range points {
+		x := p.t.Sub(at).Seconds()
+		sumX += x
+		sumY += p.v
+		sumXY += x * p.v
+		sumX2 += x * x
+	}
+	count := float64(len(points))
+	covXY := sumXY - sumX*sumY/count
+	varX := sumX2 - sumX*sumX/count
+	if varX == 0 {
+		return n
+	}
+	slope := covXY / varX
+	intercept := sumY/count - slope*sumX/count
+	n.SetValue(&intercept)
+	return n
+}
+
+// HoltWintersParams are the smoothing factors of HoltWinters. They must be between 0 and 1.
+// When Season is zero the series is assumed to have no seasonality and Seasonal is not used.
+type HoltWintersParams struct {
+	Level    float64
+	Trend    float64
+	Seasonal float64
+	Season   time.Duration
+}
+
+// Validate returns an error if the parameters are out of range.
+func (p HoltWintersParams) Validate() error {
+	if p.Level <= 0 || p.Level >= 1 {
+		return fmt.Errorf("holt winters level smoothing factor must be between 0 and 1, got %v", p.Level)
+	}
+	if p.Trend <= 0 || p.Trend >= 1 {
+		return fmt.Errorf("holt winters trend smoothing factor must be between 0 and 1, got %v", p.Trend)
+	}
+	if p.Season < 0 {
+		return fmt.Errorf("holt winters season must not be negative, got %v", p.Season)
+	}
+	if p.Season > 0 && (p.Seasonal <= 0 || p.Seasonal >= 1) {
+		return fmt.Errorf("holt winters seasonal smoothing factor must be between 0 and 1, got %v", p.Seasonal)
+	}
+	return nil
+}
+
+// HoltWinters returns a Number with the value of the series at the given time, forecast with
+// (additive) triple exponential smoothing, or double exponential smoothing if there is no season.
+// The points of the series are assumed to be evenly spaced. The Number is null if there are not
+// enough points, which is two, or two full seasons.
+func (s Series) HoltWinters(refID string, at time.Time, params HoltWintersParams) (Number, error) {
+	var labels = s.GetLabels()
+	if labels != nil {
+		labels = labels.Copy()
+	}
+	n := NewNumber(refID, labels)
+	if err := params.Validate(); err != nil {
+		return n, err
+	}
+	points := s.numericPoints()
+	if len(points) < 2 {
+		return n, nil
+	}
+
+	intervals := make([]time.Duration, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		intervals = append(intervals, points[i].t.Sub(points[i-1].t))
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	step := intervals[len(intervals)/2]
+	if step <= 0 {
+		return n, nil
+	}
+
+	last := points[len(points)-1]
+	steps := int(math.Round(float64(at.Sub(last.t)) / float64(step)))
+	if steps < 0 {
+		steps = 0
+	}
+
+	var f float64
+	if params.Season == 0 {
+		level := points[0].v
+		trend := points[1].v - points[0].v
+		for _, p := range points[1:] {
+			lastLevel := level
+			level = params.Level*p.v + (1-params.Level)*(level+trend)
+			trend = params.Trend*(level-lastLevel) + (1-params.Trend)*trend
+		}
+		f = level + float64(steps)*trend
+	} else {
+		m := int(math.Round(float64(params.Season) / float64(step)))
+		if m < 1 || len(points) < 2*m {
+			return n, nil
+		}
+		var first, second float64
+		for i := 0; i < m; i++ {
+			first += points[i].v
+			second += points[m+i].v
+		}
+		level := first / float64(m)
+		trend := (second/float64(m) - level) / float64(m)
+		seasonals := make([]float64, m)
+		for i := 0; i < m; i++ {
+			seasonals[i] = points[i].v - level
+		}
+		for i := m; i < len(points); i++ {
+			y := points[i].v
+			si := i % m
+			lastLevel := level
+			level = params.Level*(y-seasonals[si]) + (1-params.Level)*(level+trend)
+			trend = params.Trend*(level-lastLevel) + (1-params.Trend)*trend
+			seasonals[si] = params.Seasonal*(y-level) + (1-params.Seasonal)*seasonals[si]
+		}
+		f = level + float64(steps)*trend + seasonals[(len(points)-1+steps)%m]
+	}
+	n.SetValue(&f)
+	return n, nil
+}
@@ -0,0 +1,123 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesZScore(t *testing.T) {
+	s := makeSeries("", data.Labels{"host": "a"},
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(60, 0), float64Pointer(3)},
+		tp{time.Unix(120, 0), float64Pointer(5)},
+		tp{time.Unix(180, 0), nil},
+		tp{time.Unix(240, 0), float64Pointer(2)},
+	)
+	res := s.ZScore("B", 4*time.Minute)
+	require.Equal(t, makeSeries("B", data.Labels{"host": "a"},
+		tp{time.Unix(0, 0), nil},
+		tp{time.Unix(60, 0), nil},
+		tp{time.Unix(120, 0), float64Pointer(3)},
+		tp{time.Unix(180, 0), nil},
+		tp{time.Unix(240, 0), float64Pointer(-2)},
+	), res)
+}
+
+func TestSeriesDeviation(t *testing.T) {
+	s := makeSeries("", nil,
+		tp{time.Unix(0, 0), float64Pointer(10)},
+		tp{time.Unix(30, 0), float64Pointer(0)},
+		tp{time.Unix(60, 0), float64Pointer(15)},
+		tp{time.Unix(90, 0), float64Pointer(5)},
+	)
+	res := s.Deviation("B", time.Minute)
+	require.Equal(t, makeSeries("B", nil,
+		tp{time.Unix(0, 0), nil},
+		tp{time.Unix(30, 0), nil},
+		tp{time.Unix(60, 0), float64Pointer(50)},
+		tp{time.Unix(90, 0), nil},
+	), res)
+
+	t.Run("null and NaN points are kept", func(t *testing.T) {
+		s := makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(10)},
+			tp{time.Unix(60, 0), nil},
+			tp{time.Unix(90, 0), float64Pointer(math.NaN())},
+			tp{time.Unix(120, 0), float64Pointer(20)},
+		)
+		res := s.Deviation("B", time.Minute)
+		require.Equal(t, makeSeries("B", nil,
+			tp{time.Unix(0, 0), nil},
+			tp{time.Unix(60, 0), nil},
+			tp{time.Unix(90, 0), nil},
+			tp{time.Unix(120, 0), float64Pointer(100)},
+		), res)
+	})
+}
+
+func TestSeriesPredictLinear(t *testing.T) {
+	s := makeSeries("", data.Labels{"host": "a"},
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(10, 0), float64Pointer(2)},
+		tp{time.Unix(20, 0), nil},
+		tp{time.Unix(30, 0), float64Pointer(4)},
+	)
+	res := s.PredictLinear("B", time.Unix(100, 0))
+	require.InDelta(t, 11, *res.GetFloat64Value(), 1e-9)
+	require.Equal(t, data.Labels{"host": "a"}, res.GetLabels())
+
+	single := makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)})
+	require.Nil(t, single.PredictLinear("B", time.Unix(100, 0)).GetFloat64Value())
+}
+
+func TestSeriesHoltWinters(t *testing.T) {
+	params := HoltWintersParams{Level: 0.5, Trend: 0.5, Seasonal: 0.5}
+
+	t.Run("linear trend without season", func(t *testing.T) {
+		s := NewSeries("", nil, 10)
+		for i := 0; i < 10; i++ {
+			f := float64(2 * i)
+			s.SetPoint(i, time.Unix(int64(i*10), 0), &f)
+		}
+		res, err := s.HoltWinters("B", time.Unix(120, 0), params)
+		require.NoError(t, err)
+		require.InDelta(t, 24, *res.GetFloat64Value(), 1e-9)
+	})
+
+	t.Run("repeating season", func(t *testing.T) {
+		pattern := []float64{1, 5, 3}
+		s := NewSeries("", nil, 12)
+		for i := 0; i < 12; i++ {
+			f := pattern[i%3]
+			s.SetPoint(i, time.Unix(int64(i*10), 0), &f)
+		}
+		params := params
+		params.Season = 30 * time.Second
+		res, err := s.HoltWinters("B", time.Unix(130, 0), params)
+		require.NoError(t, err)
+		require.InDelta(t, 5, *res.GetFloat64Value(), 1e-9)
+	})
+
+	t.Run("not enough points for season", func(t *testing.T) {
+		s := makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(2)},
+			tp{time.Unix(20, 0), float64Pointer(3)},
+		)
+		params := params
+		params.Season = 30 * time.Second
+		res, err := s.HoltWinters("B", time.Unix(30, 0), params)
+		require.NoError(t, err)
+		require.Nil(t, res.GetFloat64Value())
+	})
+
+	t.Run("invalid smoothing factor", func(t *testing.T) {
+		s := makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)})
+		_, err := s.HoltWinters("B", time.Unix(30, 0), HoltWintersParams{Level: 1, Trend: 0.5})
+		require.Error(t, err)
+	})
+}
@@ -68,6 +68,64 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkDurationArg(1),
+	},
+	"moving_sum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingSum,
+		Check:  checkDurationArg(1),
+	},
+	"moving_min": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingMin,
+		Check:  checkDurationArg(1),
+	},
+	"moving_max": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingMax,
+		Check:  checkDurationArg(1),
+	},
+	"moving_percentile": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingPercentile,
+		Check:  checkDurationArg(1),
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+		Check:  checkDurationArg(1),
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+		Check:  checkDurationArg(1),
+	},
+	"derivative": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      derivative,
+	},
+	"cumulative_sum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumulativeSum,
+	},
+	"time_shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      timeShift,
+		Check:  checkDurationArg(1),
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
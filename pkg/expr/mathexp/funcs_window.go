@@ -0,0 +1,283 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// checkDurationArg returns a parse time check that validates that the string argument at
+// position argIdx is a positive duration such as "5m" or "1h".
+func checkDurationArg(argIdx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(t *parse.Tree, f *parse.FuncNode) error {
+		s, ok := f.Args[argIdx].(*parse.StringNode)
+		if !ok {
+			return fmt.Errorf("parse: expected a duration string for argument %v of %s", argIdx, f.Name)
+		}
+		_, err := parseWindow(s.Text)
+		if err != nil {
+			return fmt.Errorf("parse: invalid duration for argument %v of %s: %w", argIdx, f.Name, err)
+		}
+		return nil
+	}
+}
+
+func parseWindow(s string) (time.Duration, error) {
+	d, err := gtime.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be greater than zero, got %q", s)
+	}
+	return d, nil
+}
+
+// movingAvg returns, for each point, the mean of the non-null values in the trailing window.
+func movingAvg(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64) *float64 {
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		f := sum / float64(len(vals))
+		return &f
+	})
+}
+
+// movingSum returns, for each point, the sum of the non-null values in the trailing window.
+func movingSum(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64) *float64 {
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		return &sum
+	})
+}
+
+// movingMin returns, for each point, the smallest non-null value in the trailing window.
+func movingMin(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64) *float64 {
+		f := vals[0]
+		for _, v := range vals[1:] {
+			f = math.Min(f, v)
+		}
+		return &f
+	})
+}
+
+// movingMax returns, for each point, the largest non-null value in the trailing window.
+func movingMax(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64) *float64 {
+		f := vals[0]
+		for _, v := range vals[1:] {
+			f = math.Max(f, v)
+		}
+		return &f
+	})
+}
+
+// movingPercentile returns, for each point, the p-th percentile (0-100) of the non-null values
+// in the trailing window. Values between two ranks are linearly interpolated.
+func movingPercentile(e *State, varSet Results, window string, pRes Results) (Results, error) {
+	p, err := scalarArg(pRes)
+	if err != nil {
+		return Results{}, fmt.Errorf("moving_percentile: %w", err)
+	}
+	if p < 0 || p > 100 {
+		return Results{}, fmt.Errorf("moving_percentile: percentile must be between 0 and 100, got %v", p)
+	}
+	return perWindow(e, varSet, window, func(vals []float64) *float64 {
+		sorted := make([]float64, len(vals))
+		copy(sorted, vals)
+		sort.Float64s(sorted)
+		rank := p / 100 * float64(len(sorted)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+		return &f
+	})
+}
+
+// delta returns, for each point, the difference between its value and the oldest non-null value
+// in the trailing window.
+func delta(e *State, varSet Results, window string) (Results, error) {
+	return perWindowPoints(e, varSet, window, func(first, last windowPoint) *float64 {
+		f := last.v - first.v
+		return &f
+	})
+}
+
+// rate returns, for each point, the per-second rate of change between the oldest non-null value
+// in the trailing window and the point's value.
+func rate(e *State, varSet Results, window string) (Results, error) {
+	return perWindowPoints(e, varSet, window, func(first, last windowPoint) *float64 {
+		elapsed := last.t.Sub(first.t).Seconds()
+		if elapsed == 0 {
+			return nil
+		}
+		f := (last.v - first.v) / elapsed
+		return &f
+	})
+}
+
+// derivative returns the per-second rate of change between each point and the previous point.
+// The first point, and points following a null value, are null.
+func derivative(e *State, varSet Results) (Results, error) {
+	return perSortedSeries(e, varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if i == 0 || f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			prevT, prevF := s.GetPoint(i - 1)
+			elapsed := t.Sub(prevT).Seconds()
+			if prevF == nil || elapsed == 0 {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			d := (*f - *prevF) / elapsed
+			newSeries.SetPoint(i, t, &d)
+		}
+		return newSeries
+	})
+}
+
+// cumulativeSum returns the running total of the series. Null values do not contribute
+// to the total and remain null in the result.
+func cumulativeSum(e *State, varSet Results) (Results, error) {
+	return perSortedSeries(e, varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// timeShift moves every point of the series forward in time by the given duration, so that
+// for example time_shift($A, "1h") can be compared to $A to see the change over an hour.
+func timeShift(e *State, varSet Results, shift string) (Results, error) {
+	d, err := parseWindow(shift)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSortedSeries(e, varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries
+	})
+}
+
+type windowPoint struct {
+	t time.Time
+	v float64
+}
+
+// perWindow calls windowF for each point of each series with the non-null, non-NaN values
+// whose time is within the window ending at (and including) that point. If there are no such
+// values the resulting point is null.
+func perWindow(e *State, varSet Results, window string, windowF func(vals []float64) *float64) (Results, error) {
+	return perWindowRange(e, varSet, window, false, func(points []windowPoint) *float64 {
+		vals := make([]float64, len(points))
+		for i, p := range points {
+			vals[i] = p.v
+		}
+		return windowF(vals)
+	})
+}
+
+// perWindowPoints is like perWindow, but passes the oldest point in the window and the current
+// point to pointsF. The resulting point is null if the current point is null.
+func perWindowPoints(e *State, varSet Results, window string, pointsF func(first, last windowPoint) *float64) (Results, error) {
+	return perWindowRange(e, varSet, window, true, func(points []windowPoint) *float64 {
+		return pointsF(points[0], points[len(points)-1])
+	})
+}
+
+// perWindowRange calls rangeF for each point of each series with the non-null, non-NaN points
+// within the window ending at that point. If requireCurrent is true, the resulting point is
+// null when the point itself is null or NaN.
+func perWindowRange(e *State, varSet Results, window string, requireCurrent bool, rangeF func(points []windowPoint) *float64) (Results, error) {
+	d, err := parseWindow(window)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSortedSeries(e, varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		points := make([]windowPoint, 0)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			current := f != nil && !math.IsNaN(*f)
+			if current {
+				points = append(points, windowPoint{t: t, v: *f})
+			}
+			start := t.Add(-d)
+			drop := 0
+			for drop < len(points) && !points[drop].t.After(start) {
+				drop++
+			}
+			points = points[drop:]
+			if len(points) == 0 || (requireCurrent && !current) {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			newSeries.SetPoint(i, t, rangeF(points))
+		}
+		return newSeries
+	})
+}
+
+// perSortedSeries passes a time ascending copy of each Series in varSet to seriesF.
+// It returns an error if any of the values is not a Series.
+func perSortedSeries(e *State, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, val := range varSet.Values {
+		s, ok := val.(Series)
+		if !ok {
+			return newRes, fmt.Errorf("expected a series, got type %v", val.Type())
+		}
+		sorted := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			sorted.SetPoint(i, t, f)
+		}
+		sorted.SortByTime(false)
+		newRes.Values = append(newRes.Values, seriesF(sorted))
+	}
+	return newRes, nil
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(res Results) (float64, error) {
+	if len(res.Values) != 1 {
+		return 0, fmt.Errorf("expected a single scalar argument")
+	}
+	s, ok := res.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("expected a scalar argument, got type %v", res.Values[0].Type())
+	}
+	f := s.GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("expected a non-null scalar argument")
+	}
+	return *f, nil
+}
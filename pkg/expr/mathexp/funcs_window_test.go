@@ -0,0 +1,228 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowFuncs(t *testing.T) {
+	input := Vars{
+		"A": Results{
+			[]Value{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(3)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(8)},
+					tp{time.Unix(240, 0), float64Pointer(4)},
+				),
+			},
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name:     "moving_avg over two minutes",
+			expr:     `moving_avg($A, "2m")`,
+			vars:     input,
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(60, 0), float64Pointer(2)},
+						tp{time.Unix(120, 0), float64Pointer(3)},
+						tp{time.Unix(180, 0), float64Pointer(8)},
+						tp{time.Unix(240, 0), float64Pointer(6)},
+					),
+				},
+			},
+		},
+		{
+			name:     "moving_max over two minutes",
+			expr:     `moving_max($A, "2m")`,
+			vars:     input,
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(60, 0), float64Pointer(3)},
+						tp{time.Unix(120, 0), float64Pointer(3)},
+						tp{time.Unix(180, 0), float64Pointer(8)},
+						tp{time.Unix(240, 0), float64Pointer(8)},
+					),
+				},
+			},
+		},
+		{
+			name:     "moving_percentile interpolates between ranks",
+			expr:     `moving_percentile($A, "10m", 50)`,
+			vars:     input,
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(60, 0), float64Pointer(2)},
+						tp{time.Unix(120, 0), float64Pointer(2)},
+						tp{time.Unix(180, 0), float64Pointer(3)},
+						tp{time.Unix(240, 0), float64Pointer(3.5)},
+					),
+				},
+			},
+		},
+		{
+			name:     "delta over three minutes",
+			expr:     `delta($A, "3m")`,
+			vars:     input,
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), float64Pointer(0)},
+						tp{time.Unix(60, 0), float64Pointer(2)},
+						tp{time.Unix(120, 0), nil},
+						tp{time.Unix(180, 0), float64Pointer(5)},
+						tp{time.Unix(240, 0), float64Pointer(-4)},
+					),
+				},
+			},
+		},
+		{
+			name:     "rate over three minutes",
+			expr:     `rate($A, "3m")`,
+			vars:     input,
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), nil},
+						tp{time.Unix(60, 0), float64Pointer(2.0 / 60)},
+						tp{time.Unix(120, 0), nil},
+						tp{time.Unix(180, 0), float64Pointer(5.0 / 120)},
+						tp{time.Unix(240, 0), float64Pointer(-4.0 / 60)},
+					),
+				},
+			},
+		},
+		{
+			name:     "derivative",
+			expr:     `derivative($A)`,
+			vars:     input,
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), nil},
+						tp{time.Unix(60, 0), float64Pointer(2.0 / 60)},
+						tp{time.Unix(120, 0), nil},
+						tp{time.Unix(180, 0), nil},
+						tp{time.Unix(240, 0), float64Pointer(-4.0 / 60)},
+					),
+				},
+			},
+		},
+		{
+			name:     "cumulative_sum skips nulls",
+			expr:     `cumulative_sum($A)`,
+			vars:     input,
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(60, 0), float64Pointer(4)},
+						tp{time.Unix(120, 0), nil},
+						tp{time.Unix(180, 0), float64Pointer(12)},
+						tp{time.Unix(240, 0), float64Pointer(16)},
+					),
+				},
+			},
+		},
+		{
+			name:     "time_shift moves points forward",
+			expr:     `time_shift($A, "1h")`,
+			vars:     input,
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", data.Labels{"host": "a"},
+						tp{time.Unix(3600, 0), float64Pointer(1)},
+						tp{time.Unix(3660, 0), float64Pointer(3)},
+						tp{time.Unix(3720, 0), nil},
+						tp{time.Unix(3780, 0), float64Pointer(8)},
+						tp{time.Unix(3840, 0), float64Pointer(4)},
+					),
+				},
+			},
+		},
+		{
+			name: "unsorted input is sorted by time",
+			expr: `cumulative_sum($A)`,
+			vars: Vars{
+				"A": Results{
+					[]Value{
+						makeSeries("", nil,
+							tp{time.Unix(10, 0), float64Pointer(2)},
+							tp{time.Unix(5, 0), float64Pointer(1)},
+						),
+					},
+				},
+			},
+			newErrIs: require.NoError,
+			results: Results{
+				[]Value{
+					makeSeries("", nil,
+						tp{time.Unix(5, 0), float64Pointer(1)},
+						tp{time.Unix(10, 0), float64Pointer(3)},
+					),
+				},
+			},
+		},
+		{
+			name:     "invalid duration should error at parse time",
+			expr:     `moving_avg($A, "soon")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "negative duration should error at parse time",
+			expr:     `time_shift($A, "-1h")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "scalar input should error at parse time",
+			expr:     `moving_avg(1, "1m")`,
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars)
+				require.NoError(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
+
+func TestWindowFuncsOnNumber(t *testing.T) {
+	e, err := New(`moving_avg($A, "5m")`)
+	require.NoError(t, err)
+	_, err = e.Execute("", Vars{
+		"A": Results{[]Value{makeNumber("", nil, float64Pointer(1))}},
+	})
+	require.Error(t, err)
+}
@@ -401,6 +401,8 @@ const (
 	TypeSeriesSet
 	// TypeVariantSet is a collection of the same type Number, Series, or Scalar.
 	TypeVariantSet
+	// TypeTableData is a collection of tables that are neither time series nor numbers.
+	TypeTableData
 )
 
 // String returns a string representation of the ReturnType.
@@ -416,6 +418,8 @@ func (f ReturnType) String() string {
 		return "scalar"
 	case TypeVariantSet:
 		return "variant"
+	case TypeTableData:
+		return "tableData"
 	default:
 		return "unknown"
 	}
@@ -143,3 +143,28 @@ func (ff *Float64Field) Len() int {
 	df := data.Field(*ff)
 	return df.Len()
 }
+
+// TableData is a data frame that is neither a Series nor a Number, such as the rows
+// of a SQL table. It can only be used as input for SQL expressions.
+type TableData struct{ Frame *data.Frame }
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (t TableData) Type() parse.ReturnType { return parse.TypeTableData }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (t TableData) Value() interface{} { return t }
+
+func (t TableData) GetLabels() data.Labels { return nil }
+
+func (t TableData) SetLabels(ls data.Labels) {}
+
+func (t TableData) GetMeta() interface{} {
+	return t.Frame.Meta.Custom
+}
+
+func (t TableData) SetMeta(v interface{}) {
+	t.Frame.SetMeta(&data.FrameMeta{Custom: v})
+}
+
+// AsDataFrame returns the underlying *data.Frame.
+func (t TableData) AsDataFrame() *data.Frame { return t.Frame }
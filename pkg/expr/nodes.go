@@ -119,6 +119,14 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeAggregate:
+		node.Command, err = UnmarshalAggregateCommand(rn)
+	case TypeSQL:
+		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -146,6 +154,10 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// sqlInput is true if the results are an input of a SQL command. Frames that
+	// are not time series are then kept as tables instead of being converted.
+	sqlInput bool
 }
 
 // NodeType returns the data pipeline node type.
@@ -221,7 +233,7 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 		},
 	}
 
-	resp, err := s.dataService.QueryData(ctx, &backend.QueryDataRequest{
+	resp, err := s.queryData(ctx, dn, &backend.QueryDataRequest{
 		PluginContext: pc,
 		Queries:       q,
 		Headers:       dn.request.Headers,
@@ -264,6 +276,13 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 				logger.Warn("ignoring InfluxDB data frame due to missing numeric fields", "frame", frame)
 				continue
 			}
+			// Frames that are not time series, such as tables of SQL data sources, are kept
+			// as they are when they are used by SQL expressions.
+			if dn.sqlInput && frame.TimeSeriesSchema().Type == data.TimeSeriesTypeNot {
+				logger.Debug("expression datasource query (tableData)", "query", refID)
+				vals = append(vals, mathexp.TableData{Frame: frame})
+				continue
+			}
 			series, err := WideToMany(frame)
 			if err != nil {
 				return mathexp.Results{}, err
@@ -323,7 +342,10 @@ func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 				labels = make(data.Labels)
 			}
 			key := stringFieldNames[i] // TODO check for duplicate string column names
-			val, _ := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
+			val, ok := frame.ConcreteAt(stringFieldIdxs[i], rowIdx)
+			if !ok {
+				continue // null values in nullable string columns are not labels
+			}
 			labels[key] = val.(string) // TODO check assertion / return error
 		}
 
@@ -338,6 +360,43 @@ func extractNumberSet(frame *data.Frame) ([]mathexp.Number, error) {
 	return numbers, nil
 }
 
+// valuesFromTable converts the result of a SQL expression to values. A table
+// with one numeric column and otherwise only string columns becomes a set of numbers,
+// a long or wide time series table becomes a set of series, anything else is kept as a table.
+func valuesFromTable(frame *data.Frame) (mathexp.Values, error) {
+	vals := mathexp.Values{}
+	if isNumberTable(frame) {
+		numberSet, err := extractNumberSet(frame)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range numberSet {
+			vals = append(vals, n)
+		}
+		return vals, nil
+	}
+
+	switch frame.TimeSeriesSchema().Type {
+	case data.TimeSeriesTypeLong:
+		wide, err := data.LongToWide(frame, nil)
+		if err != nil {
+			return nil, err
+		}
+		frame = wide
+	case data.TimeSeriesTypeNot:
+		return append(vals, mathexp.TableData{Frame: frame}), nil
+	}
+
+	series, err := WideToMany(frame)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range series {
+		vals = append(vals, s)
+	}
+	return vals, nil
+}
+
 // WideToMany converts a data package wide type Frame to one or multiple Series. A series
 // is created for each value type column of wide frame.
 //
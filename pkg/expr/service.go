@@ -39,6 +39,7 @@ type Service struct {
 	cfg            *setting.Cfg
 	dataService    backend.QueryDataHandler
 	secretsService secrets.Service
+	queryCache     *queryCache
 }
 
 func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, secretsService secrets.Service) *Service {
@@ -46,9 +47,21 @@ func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, secretsServic
 		cfg:            cfg,
 		dataService:    pluginClient,
 		secretsService: secretsService,
+		queryCache:     newQueryCache(cfg.ExpressionsQueryCacheTTL, cfg.ExpressionsQueryCacheAlignment, cfg.ExpressionsQueryCacheMaxEntries, cfg.ExpressionsQueryDedupEnabled),
 	}
 }
 
+// queryData sends the query of a datasource node to the data source, or returns a cached
+// response for it when the query cache is enabled.
+func (s *Service) queryData(ctx context.Context, dn *DSNode, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if s.queryCache == nil {
+		return s.dataService.QueryData(ctx, req)
+	}
+	return s.queryCache.queryData(ctx, dn, func(ctx context.Context) (*backend.QueryDataResponse, error) {
+		return s.dataService.QueryData(ctx, req)
+	})
+}
+
 func (s *Service) isDisabled() bool {
 	if s.cfg == nil {
 		return true
@@ -0,0 +1,308 @@
+// Package sql runs SQL queries over data frames. The frames are loaded as tables into
+// a private in-memory SQLite database that only exists for the duration of the query.
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// maxRows is the maximum number of rows a query may return.
+	maxRows = 100000
+	// queryTimeout is the maximum duration of loading the tables and running the query.
+	queryTimeout = 10 * time.Second
+)
+
+// Query loads each frame of tables as a table with the map key as its name and runs
+// query against them. The query may only read from the tables, recursive common table
+// expressions are not allowed, and the query fails when it returns more than maxRows rows
+// or takes longer than queryTimeout.
+func Query(ctx context.Context, query string, tables map[string]*data.Frame) (*data.Frame, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	c, err := (&sqlite3.SQLiteDriver{}).Open(":memory:")
+	if err != nil {
+		return nil, err
+	}
+	conn := c.(*sqlite3.SQLiteConn)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	for name, frame := range tables {
+		if err := loadTable(ctx, conn, name, frame); err != nil {
+			return nil, fmt.Errorf("failed to load table %q: %w", name, err)
+		}
+	}
+
+	// From here on only reading is allowed, this prevents the query from attaching
+	// database files, changing pragmas or modifying the tables. Every other action is
+	// denied, including SQLITE_RECURSIVE, so recursive common table expressions, which
+	// can generate an unbounded number of rows, are rejected as well.
+	conn.RegisterAuthorizer(func(op int, arg1, arg2, arg3 string) int {
+		switch op {
+		case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ:
+			return sqlite3.SQLITE_OK
+		case sqlite3.SQLITE_FUNCTION:
+			if strings.EqualFold(arg2, "load_extension") {
+				return sqlite3.SQLITE_DENY
+			}
+			return sqlite3.SQLITE_OK
+		default:
+			return sqlite3.SQLITE_DENY
+		}
+	})
+
+	rows, err := conn.QueryContext(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	return frameFromRows(rows.(*sqlite3.SQLiteRows), maxRows)
+}
+
+// quoteIdent quotes a table or column name.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func columnType(ft data.FieldType) string {
+	switch {
+	case ft.Time():
+		return "DATETIME"
+	case ft.NonNullableType() == data.FieldTypeBool:
+		return "BOOLEAN"
+	case ft.NonNullableType() == data.FieldTypeFloat32 || ft.NonNullableType() == data.FieldTypeFloat64:
+		return "REAL"
+	case ft.Numeric():
+		return "INTEGER"
+	default:
+		return "TEXT"
+	}
+}
+
+// columnNames returns unique names for the fields of a frame. Fields without a name
+// are named after their index.
+func columnNames(frame *data.Frame) []string {
+	names := make([]string, len(frame.Fields))
+	seen := map[string]int{}
+	for i, f := range frame.Fields {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("field%d", i)
+		}
+		if n, ok := seen[name]; ok {
+			seen[name] = n + 1
+			name = fmt.Sprintf("%s_%d", name, n+1)
+		}
+		seen[name] = 0
+		names[i] = name
+	}
+	return names
+}
+
+func loadTable(ctx context.Context, conn *sqlite3.SQLiteConn, name string, frame *data.Frame) error {
+	if len(frame.Fields) == 0 {
+		return fmt.Errorf("frame has no fields")
+	}
+
+	names := columnNames(frame)
+	columns := make([]string, len(frame.Fields))
+	placeholders := make([]string, len(frame.Fields))
+	for i, f := range frame.Fields {
+		columns[i] = quoteIdent(names[i]) + " " + columnType(f.Type())
+		placeholders[i] = "?"
+	}
+
+	create := fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(name), strings.Join(columns, ", "))
+	if _, err := conn.ExecContext(ctx, create, nil); err != nil {
+		return err
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdent(name), strings.Join(placeholders, ", "))
+	stmt, err := conn.PrepareContext(ctx, insert)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	args := make([]driver.NamedValue, len(frame.Fields))
+	for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
+		for i, f := range frame.Fields {
+			var v interface{}
+			if cv, ok := f.ConcreteAt(rowIdx); ok {
+				v = cv
+			}
+			args[i] = driver.NamedValue{Ordinal: i + 1, Value: driverValue(v)}
+		}
+		if _, err := stmt.(driver.StmtExecContext).ExecContext(ctx, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// driverValue converts a concrete field value to a value the sqlite3 driver can store.
+func driverValue(v interface{}) driver.Value {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case int8:
+		return int64(t)
+	case int16:
+		return int64(t)
+	case int32:
+		return int64(t)
+	case int64:
+		return t
+	case uint8:
+		return int64(t)
+	case uint16:
+		return int64(t)
+	case uint32:
+		return int64(t)
+	case uint64:
+		return float64(t)
+	case float32:
+		return float64(t)
+	case float64, bool, string, time.Time:
+		return t
+	case json.RawMessage:
+		return string(t)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+// frameFromRows reads all rows into a frame, and fails when there are more than limit rows.
+// The type of each field is taken from the values of the column, or from the declared column
+// type if all values are null.
+func frameFromRows(rows *sqlite3.SQLiteRows, limit int) (*data.Frame, error) {
+	columns := rows.Columns()
+	values := make([][]interface{}, len(columns))
+	dest := make([]driver.Value, len(columns))
+	for n := 0; ; n++ {
+		err := rows.Next(dest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n >= limit {
+			return nil, fmt.Errorf("query returned more than %d rows", limit)
+		}
+		for i, v := range dest {
+			values[i] = append(values[i], v)
+		}
+	}
+
+	frame := data.NewFrame("")
+	for i, name := range columns {
+		frame.Fields = append(frame.Fields, fieldFromValues(name, rows.DeclTypes()[i], values[i]))
+	}
+	return frame, nil
+}
+
+func fieldFromValues(name, declType string, values []interface{}) *data.Field {
+	var hasInt, hasFloat, hasBool, hasTime, hasString bool
+	for _, v := range values {
+		switch v.(type) {
+		case nil:
+		case int64:
+			hasInt = true
+		case float64:
+			hasFloat = true
+		case bool:
+			hasBool = true
+		case time.Time:
+			hasTime = true
+		default:
+			hasString = true
+		}
+	}
+
+	var ft data.FieldType
+	switch {
+	case hasString || (hasTime && (hasInt || hasFloat || hasBool)):
+		ft = data.FieldTypeNullableString
+	case hasTime:
+		ft = data.FieldTypeNullableTime
+	case hasFloat || (hasInt && hasBool):
+		ft = data.FieldTypeNullableFloat64
+	case hasInt:
+		ft = data.FieldTypeNullableInt64
+	case hasBool:
+		ft = data.FieldTypeNullableBool
+	default:
+		ft = fieldTypeFromDecl(declType)
+	}
+
+	field := data.NewFieldFromFieldType(ft, len(values))
+	field.Name = name
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		switch ft {
+		case data.FieldTypeNullableString:
+			var s string
+			switch t := v.(type) {
+			case []byte:
+				s = string(t)
+			case time.Time:
+				s = t.Format(time.RFC3339Nano)
+			default:
+				s = fmt.Sprintf("%v", t)
+			}
+			field.Set(i, &s)
+		case data.FieldTypeNullableFloat64:
+			var f float64
+			switch t := v.(type) {
+			case int64:
+				f = float64(t)
+			case float64:
+				f = t
+			case bool:
+				if t {
+					f = 1
+				}
+			}
+			field.Set(i, &f)
+		default:
+			field.SetConcrete(i, v)
+		}
+	}
+	return field
+}
+
+func fieldTypeFromDecl(declType string) data.FieldType {
+	decl := strings.ToUpper(declType)
+	switch {
+	case strings.Contains(decl, "INT"):
+		return data.FieldTypeNullableInt64
+	case strings.Contains(decl, "DATE"), strings.Contains(decl, "TIME"):
+		return data.FieldTypeNullableTime
+	case strings.Contains(decl, "BOOL"):
+		return data.FieldTypeNullableBool
+	case strings.Contains(decl, "CHAR"), strings.Contains(decl, "TEXT"):
+		return data.FieldTypeNullableString
+	default:
+		return data.FieldTypeNullableFloat64
+	}
+}
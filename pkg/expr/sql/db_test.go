@@ -0,0 +1,129 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func strPointer(s string) *string {
+	return &s
+}
+
+func float64Pointer(f float64) *float64 {
+	return &f
+}
+
+func int64Pointer(i int64) *int64 {
+	return &i
+}
+
+func TestQuery(t *testing.T) {
+	inventory := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("dc", nil, []string{"mia", "nyc", "nyc"}),
+		data.NewField("cores", nil, []int64{4, 8, 16}),
+	)
+
+	usage, err := TableFromValues("B", mathexp.Values{
+		numberWithLabels(data.Labels{"host": "a"}, 2),
+		numberWithLabels(data.Labels{"host": "b"}, 6),
+		numberWithLabels(data.Labels{"host": "c"}, 4),
+	})
+	require.NoError(t, err)
+
+	tables := map[string]*data.Frame{"A": inventory, "B": usage}
+
+	t.Run("join, filter and group", func(t *testing.T) {
+		frame, err := Query(context.Background(), `
+			SELECT A.dc, SUM(B.value) / SUM(A.cores) AS utilization, COUNT(*) AS hosts
+			FROM A JOIN B ON A.host = B.host
+			WHERE A.cores > 4
+			GROUP BY A.dc
+			ORDER BY A.dc`, tables)
+		require.NoError(t, err)
+		require.Equal(t, data.NewFrame("",
+			data.NewField("dc", nil, []*string{strPointer("nyc")}),
+			data.NewField("utilization", nil, []*float64{float64Pointer(10.0 / 24)}),
+			data.NewField("hosts", nil, []*int64{int64Pointer(2)}),
+		), frame)
+	})
+
+	t.Run("writes are not allowed", func(t *testing.T) {
+		_, err := Query(context.Background(), "DELETE FROM A", tables)
+		require.Error(t, err)
+		_, err = Query(context.Background(), "ATTACH DATABASE ':memory:' AS x", tables)
+		require.Error(t, err)
+	})
+
+	t.Run("recursive common table expressions are not allowed", func(t *testing.T) {
+		_, err := Query(context.Background(), "WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n) SELECT x FROM n", tables)
+		require.Error(t, err)
+	})
+
+	t.Run("too many rows are not allowed", func(t *testing.T) {
+		hosts := make([]int64, 400)
+		for i := range hosts {
+			hosts[i] = int64(i)
+		}
+		large := data.NewFrame("", data.NewField("host", nil, hosts))
+
+		_, err := Query(context.Background(), "SELECT * FROM L AS a, L AS b", map[string]*data.Frame{"L": large})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "more than 100000 rows")
+	})
+
+	t.Run("time values are kept", func(t *testing.T) {
+		series := mathexp.NewSeries("", data.Labels{"host": "a"}, 2)
+		series.SetPoint(0, time.Unix(10, 0).UTC(), float64Pointer(1))
+		series.SetPoint(1, time.Unix(20, 0).UTC(), nil)
+		table, err := TableFromValues("S", mathexp.Values{series})
+		require.NoError(t, err)
+
+		frame, err := Query(context.Background(), "SELECT time, host, value FROM S ORDER BY time", map[string]*data.Frame{"S": table})
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.True(t, time.Unix(20, 0).Equal(*frame.Fields[0].At(1).(*time.Time)))
+		require.Equal(t, []*float64{float64Pointer(1), nil}, []*float64{frame.Fields[2].At(0).(*float64), frame.Fields[2].At(1).(*float64)})
+	})
+}
+
+func TestTableFromValues(t *testing.T) {
+	t.Run("numbers with different labels", func(t *testing.T) {
+		frame, err := TableFromValues("A", mathexp.Values{
+			numberWithLabels(data.Labels{"host": "a"}, 1),
+			numberWithLabels(data.Labels{"host": "b", "dc": "mia"}, 2),
+		})
+		require.NoError(t, err)
+		require.Equal(t, data.NewFrame("A",
+			data.NewField("host", nil, []*string{strPointer("a"), strPointer("b")}),
+			data.NewField("value", nil, []*float64{float64Pointer(1), float64Pointer(2)}),
+			data.NewField("dc", nil, []*string{nil, strPointer("mia")}),
+		), frame)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		frame, err := TableFromValues("A", mathexp.Values{})
+		require.NoError(t, err)
+		require.Equal(t, data.NewFrame("A", data.NewField("value", nil, []*float64{})), frame)
+	})
+
+	t.Run("conflicting column types", func(t *testing.T) {
+		_, err := TableFromValues("A", mathexp.Values{
+			numberWithLabels(data.Labels{"value": "a"}, 1),
+		})
+		require.Error(t, err)
+	})
+}
+
+func numberWithLabels(labels data.Labels, f float64) mathexp.Number {
+	n := mathexp.NewNumber("", labels)
+	n.SetValue(&f)
+	return n
+}
@@ -0,0 +1,173 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// TimeColumn is the name of the column that holds the time of each point of a Series.
+	TimeColumn = "time"
+	// ValueColumn is the name of the column that holds the value of a Series, Number or Scalar.
+	ValueColumn = "value"
+)
+
+// column is a field of a table that is being built from the rows of several values.
+type column struct {
+	name   string
+	typ    data.FieldType
+	values []interface{}
+}
+
+type tableBuilder struct {
+	columns []*column
+	byName  map[string]*column
+	rows    int
+}
+
+func (tb *tableBuilder) column(name string, typ data.FieldType) (*column, error) {
+	c, ok := tb.byName[name]
+	if !ok {
+		c = &column{name: name, typ: typ, values: make([]interface{}, tb.rows)}
+		tb.byName[name] = c
+		tb.columns = append(tb.columns, c)
+		return c, nil
+	}
+	if c.typ != typ {
+		return nil, fmt.Errorf("column %q has conflicting types %s and %s", name, c.typ.ItemTypeString(), typ.ItemTypeString())
+	}
+	return c, nil
+}
+
+// addRow appends a row to the table. Columns that are not in row are null.
+func (tb *tableBuilder) addRow(row map[string]interface{}, types map[string]data.FieldType, order []string) error {
+	for _, name := range order {
+		if _, err := tb.column(name, types[name]); err != nil {
+			return err
+		}
+	}
+	for _, c := range tb.columns {
+		c.values = append(c.values, row[c.name])
+	}
+	tb.rows++
+	return nil
+}
+
+func (tb *tableBuilder) frame(name string) *data.Frame {
+	frame := data.NewFrame(name)
+	for _, c := range tb.columns {
+		f := data.NewFieldFromFieldType(c.typ.NullableType(), len(c.values))
+		f.Name = c.name
+		for i, v := range c.values {
+			if v != nil {
+				f.SetConcrete(i, v)
+			}
+		}
+		frame.Fields = append(frame.Fields, f)
+	}
+	return frame
+}
+
+// TableFromValues builds a single table from the values of an expression variable.
+// A Series adds a row for each point with a time and a value column, a Number or a
+// Scalar adds a single row with a value column. Each label becomes a string column.
+// The rows of TableData values are added as is.
+func TableFromValues(name string, vals mathexp.Values) (*data.Frame, error) {
+	tb := &tableBuilder{byName: map[string]*column{}}
+	for _, v := range vals {
+		labelTypes := map[string]data.FieldType{}
+		labelNames := make([]string, 0, len(v.GetLabels()))
+		for k := range v.GetLabels() {
+			if k == TimeColumn || k == ValueColumn {
+				return nil, fmt.Errorf("label %q conflicts with the %q column", k, k)
+			}
+			labelTypes[k] = data.FieldTypeString
+			labelNames = append(labelNames, k)
+		}
+		sort.Strings(labelNames)
+
+		labelRow := func() map[string]interface{} {
+			row := map[string]interface{}{}
+			for k, lv := range v.GetLabels() {
+				row[k] = lv
+			}
+			return row
+		}
+
+		var err error
+		switch t := v.(type) {
+		case mathexp.Series:
+			labelTypes[TimeColumn] = data.FieldTypeTime
+			labelTypes[ValueColumn] = data.FieldTypeFloat64
+			order := append(append([]string{TimeColumn}, labelNames...), ValueColumn)
+			for i := 0; i < t.Len() && err == nil; i++ {
+				ts, f := t.GetPoint(i)
+				row := labelRow()
+				row[TimeColumn] = ts
+				row[ValueColumn] = floatValue(f)
+				err = tb.addRow(row, labelTypes, order)
+			}
+		case mathexp.Number:
+			labelTypes[ValueColumn] = data.FieldTypeFloat64
+			row := labelRow()
+			row[ValueColumn] = floatValue(t.GetFloat64Value())
+			err = tb.addRow(row, labelTypes, append(labelNames, ValueColumn))
+		case mathexp.Scalar:
+			err = tb.addRow(map[string]interface{}{ValueColumn: floatValue(t.GetFloat64Value())},
+				map[string]data.FieldType{ValueColumn: data.FieldTypeFloat64}, []string{ValueColumn})
+		case mathexp.TableData:
+			err = addFrameRows(tb, t.Frame)
+		default:
+			err = fmt.Errorf("can not use type %v as a table", v.Type())
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	// a variable without any values is still a table that can be queried
+	if len(tb.columns) == 0 {
+		if _, err := tb.column(ValueColumn, data.FieldTypeFloat64); err != nil {
+			return nil, err
+		}
+	}
+	return tb.frame(name), nil
+}
+
+func addFrameRows(tb *tableBuilder, frame *data.Frame) error {
+	names := columnNames(frame)
+	types := map[string]data.FieldType{}
+	for i, f := range frame.Fields {
+		types[names[i]] = f.Type().NonNullableType()
+	}
+	for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
+		row := map[string]interface{}{}
+		for i, f := range frame.Fields {
+			if v, ok := f.ConcreteAt(rowIdx); ok {
+				row[names[i]] = v
+			}
+		}
+		if err := tb.addRow(row, types, names); err != nil {
+			return err
+		}
+	}
+	// make sure the columns of an empty frame are still in the table
+	if frame.Rows() == 0 {
+		for _, name := range names {
+			if _, err := tb.column(name, types[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func floatValue(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
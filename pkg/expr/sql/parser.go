@@ -0,0 +1,187 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenIdent tokenType = iota
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	typ tokenType
+	val string
+}
+
+// tokenize splits a query into identifiers, literals and punctuation. Comments and
+// white space are dropped.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	r := []rune(query)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && i+1 < len(r) && r[i+1] == '-':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(r) && r[i+1] == '*':
+			j := i + 2
+			for j+1 < len(r) && !(r[j] == '*' && r[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(r) {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i = j + 2
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			j := i + 1
+			var sb strings.Builder
+			for {
+				if j >= len(r) {
+					return nil, fmt.Errorf("unterminated quote %q", string(c))
+				}
+				if r[j] == closing {
+					// a doubled quote is an escaped quote
+					if closing != ']' && j+1 < len(r) && r[j+1] == closing {
+						sb.WriteRune(closing)
+						j += 2
+						continue
+					}
+					break
+				}
+				sb.WriteRune(r[j])
+				j++
+			}
+			typ := tokenQuotedIdent
+			if c == '\'' {
+				typ = tokenString
+			}
+			tokens = append(tokens, token{typ: typ, val: sb.String()})
+			i = j + 1
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(r) && (r[j] == '_' || r[j] == '$' || unicode.IsLetter(r[j]) || unicode.IsDigit(r[j])) {
+				j++
+			}
+			tokens = append(tokens, token{typ: tokenIdent, val: string(r[i:j])})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(r) && (unicode.IsDigit(r[j]) || r[j] == '.' || r[j] == 'e' || r[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, val: string(r[i:j])})
+			i = j
+		default:
+			tokens = append(tokens, token{typ: tokenPunct, val: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func (t token) isKeyword(kw string) bool {
+	return t.typ == tokenIdent && strings.EqualFold(t.val, kw)
+}
+
+func (t token) isPunct(p string) bool {
+	return t.typ == tokenPunct && t.val == p
+}
+
+func (t token) isName() bool {
+	return t.typ == tokenIdent || t.typ == tokenQuotedIdent
+}
+
+// TableNames validates that the query is a single SELECT statement and returns the names
+// of the tables it reads from, in order of appearance and without duplicates. Names that are
+// defined by a WITH clause of the query are not returned.
+func TableNames(query string) ([]string, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	// a single trailing semicolon is allowed, any other ends a statement and starts another one.
+	if len(tokens) > 0 && tokens[len(tokens)-1].isPunct(";") {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	if !tokens[0].isKeyword("select") && !tokens[0].isKeyword("with") {
+		return nil, fmt.Errorf("only SELECT statements are supported")
+	}
+
+	cte := map[string]bool{}
+	for i, t := range tokens {
+		if t.isPunct(";") {
+			return nil, fmt.Errorf("only a single statement is supported")
+		}
+		// name AS ( ... ) defines a common table expression
+		if t.isName() && i+2 < len(tokens) && tokens[i+1].isKeyword("as") && tokens[i+2].isPunct("(") {
+			cte[t.val] = true
+		}
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	addName := func(name string) {
+		if !seen[name] && !cte[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].isKeyword("from") && !tokens[i].isKeyword("join") {
+			continue
+		}
+		// read a list of table references: name [[AS] alias] {, name [[AS] alias]}
+		j := i + 1
+		for j < len(tokens) {
+			if !tokens[j].isName() || isReserved(tokens[j]) {
+				break // e.g. a sub query
+			}
+			addName(tokens[j].val)
+			j++
+			if j < len(tokens) && tokens[j].isKeyword("as") {
+				j++
+			}
+			if j < len(tokens) && tokens[j].isName() && !isReserved(tokens[j]) {
+				j++
+			}
+			if j < len(tokens) && tokens[j].isPunct(",") && tokens[i].isKeyword("from") {
+				j++
+				continue
+			}
+			break
+		}
+	}
+	return names, nil
+}
+
+var reservedWords = map[string]bool{
+	"select": true, "from": true, "where": true, "group": true, "order": true, "by": true,
+	"having": true, "limit": true, "offset": true, "join": true, "inner": true, "left": true,
+	"right": true, "full": true, "outer": true, "cross": true, "natural": true, "on": true,
+	"using": true, "union": true, "intersect": true, "except": true, "as": true, "with": true,
+	"window": true, "values": true,
+}
+
+func isReserved(t token) bool {
+	return t.typ == tokenIdent && reservedWords[strings.ToLower(t.val)]
+}
@@ -0,0 +1,73 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableNames(t *testing.T) {
+	var tests = []struct {
+		name     string
+		query    string
+		isError  bool
+		expected []string
+	}{
+		{
+			name:     "single table",
+			query:    "SELECT * FROM A",
+			expected: []string{"A"},
+		},
+		{
+			name:     "join with aliases",
+			query:    "SELECT a.host, b.value FROM A a JOIN B AS b ON a.host = b.host WHERE a.value > 1",
+			expected: []string{"A", "B"},
+		},
+		{
+			name:     "comma separated tables and quoted names",
+			query:    `SELECT * FROM "my query" q, B WHERE q.host = B.host;`,
+			expected: []string{"my query", "B"},
+		},
+		{
+			name:     "sub query and duplicate table",
+			query:    "SELECT host FROM (SELECT host FROM A) s LEFT OUTER JOIN A ON s.host = A.host GROUP BY host",
+			expected: []string{"A"},
+		},
+		{
+			name:     "common table expressions are not tables",
+			query:    "WITH top AS (SELECT * FROM A ORDER BY value DESC LIMIT 5) SELECT * FROM top",
+			expected: []string{"A"},
+		},
+		{
+			name:     "keywords in strings and comments are ignored",
+			query:    "SELECT 'from X' AS s -- FROM Y\n FROM /* JOIN Z */ A",
+			expected: []string{"A"},
+		},
+		{
+			name:    "multiple statements are not allowed",
+			query:   "SELECT * FROM A; DROP TABLE A",
+			isError: true,
+		},
+		{
+			name:    "only select is allowed",
+			query:   "ATTACH DATABASE 'x.db' AS x",
+			isError: true,
+		},
+		{
+			name:    "unterminated string",
+			query:   "SELECT * FROM A WHERE host = 'a",
+			isError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := TableNames(tt.query)
+			if tt.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
@@ -14,6 +14,10 @@ var (
 	ErrPathTooLong           = errors.New("path is too long")
 	ErrPathInvalid           = errors.New("path is invalid")
 	ErrPathEndsWithDelimiter = errors.New("path can not end with delimiter")
+	ErrVersioningDisabled    = errors.New("versioning is not enabled for this storage")
+	ErrVersionNotFound       = errors.New("file version not found")
+	ErrTrashedFileNotFound   = errors.New("file not found in trash")
+	ErrFileAlreadyExists     = errors.New("file already exists")
 	Delimiter                = "/"
 	multipleDelimiters       = regexp.MustCompile(`/+`)
 )
@@ -56,6 +60,23 @@ type UpsertFileCommand struct {
 	MimeType   string
 	Contents   *[]byte
 	Properties map[string]string
+	// Author is recorded with the revision of the file when versioning is enabled.
+	Author string
+}
+
+// FileVersion is a previous revision of a file. Created is the time the revision was written.
+type FileVersion struct {
+	Version int64
+	Author  string
+	FileMetadata
+}
+
+// TrashedFile is a deleted file that can be restored until the trash is purged.
+type TrashedFile struct {
+	ID      int64
+	Author  string
+	Deleted time.Time
+	FileMetadata
 }
 
 type PathFilters struct {
@@ -151,3 +172,32 @@ type FileStorage interface {
 
 	close() error
 }
+
+// VersionedFileStorage keeps the previous versions of overwritten files, and moves deleted files
+// to a trash from which they can be restored until it is purged.
+type VersionedFileStorage interface {
+	// ListVersions returns the previous versions of a file, newest first.
+	ListVersions(ctx context.Context, path string) ([]FileVersion, error)
+	GetVersion(ctx context.Context, path string, version int64) (*File, error)
+	// RestoreVersion overwrites a file with one of its previous versions, which keeps the
+	// current contents as a new version.
+	RestoreVersion(ctx context.Context, path string, version int64, author string) error
+
+	// ListTrash returns the deleted files in a folder and its subfolders, most recently deleted first.
+	ListTrash(ctx context.Context, folderPath string) ([]TrashedFile, error)
+	// RestoreFromTrash moves a deleted file back to its path, which must not exist.
+	RestoreFromTrash(ctx context.Context, id int64, author string) (*TrashedFile, error)
+	// PurgeTrash permanently deletes the files that were deleted before the given time.
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
+// versionedStorage is implemented by backends that support versioning. Paths include the root folder.
+type versionedStorage interface {
+	versioningEnabled() bool
+	listVersions(ctx context.Context, path string) ([]FileVersion, error)
+	getVersion(ctx context.Context, path string, version int64) (*File, error)
+	listTrash(ctx context.Context, folderPath string) ([]TrashedFile, error)
+	getTrashedFile(ctx context.Context, id int64) (*TrashedFile, []byte, error)
+	deleteTrashedFile(ctx context.Context, id int64) error
+	purgeTrash(ctx context.Context, folderPath string, deletedBefore time.Time) (int64, error)
+}
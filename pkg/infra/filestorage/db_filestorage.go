@@ -19,6 +19,7 @@ type file struct {
 	Created          time.Time `xorm:"created"`
 	Size             int64     `xorm:"size"`
 	MimeType         string    `xorm:"mime_type"`
+	Author           string    `xorm:"author"`
 }
 
 type fileMeta struct {
@@ -28,14 +29,30 @@ type fileMeta struct {
 }
 
 type dbFileStorage struct {
-	db  *sqlstore.SQLStore
-	log log.Logger
+	db       *sqlstore.SQLStore
+	log      log.Logger
+	versions VersioningOptions
+}
+
+// VersioningOptions configures the versions and the trash of a database storage.
+type VersioningOptions struct {
+	// MaxVersions is the number of previous versions kept of each file, 0 disables versioning.
+	MaxVersions int
+	// Trash moves deleted files to the trash instead of deleting them permanently.
+	Trash bool
 }
 
 func NewDbStorage(log log.Logger, db *sqlstore.SQLStore, pathFilters *PathFilters, rootFolder string) FileStorage {
+	return NewVersionedDbStorage(log, db, pathFilters, rootFolder, VersioningOptions{})
+}
+
+// NewVersionedDbStorage returns a database storage that keeps the previous versions and deleted files
+// as configured by the options.
+func NewVersionedDbStorage(log log.Logger, db *sqlstore.SQLStore, pathFilters *PathFilters, rootFolder string, options VersioningOptions) FileStorage {
 	return newWrapper(log, &dbFileStorage{
-		log: log,
-		db:  db,
+		log:      log,
+		db:       db,
+		versions: options,
 	}, pathFilters, rootFolder)
 }
 
@@ -101,7 +118,8 @@ func (s dbFileStorage) Get(ctx context.Context, filePath string) (*File, error)
 }
 
 func (s dbFileStorage) Delete(ctx context.Context, filePath string) error {
-	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+	now := time.Now()
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		table := &file{}
 		exists, innerErr := sess.Table("file").Where("LOWER(path) = ?", strings.ToLower(filePath)).Get(table)
 		if innerErr != nil {
@@ -112,6 +130,16 @@ func (s dbFileStorage) Delete(ctx context.Context, filePath string) error {
 			return nil
 		}
 
+		if s.versions.Trash {
+			if innerErr = s.moveToTrash(sess, table, now); innerErr != nil {
+				return innerErr
+			}
+		} else if s.versions.MaxVersions > 0 {
+			if innerErr = deleteVersions(sess, filePath); innerErr != nil {
+				return innerErr
+			}
+		}
+
 		number, innerErr := sess.Table("file").Where("LOWER(path) = ?", strings.ToLower(filePath)).Delete(table)
 		if innerErr != nil {
 			return innerErr
@@ -140,7 +168,14 @@ func (s dbFileStorage) Upsert(ctx context.Context, cmd *UpsertFileCommand) error
 		}
 
 		if exists {
+			if cmd.Contents != nil && s.versions.MaxVersions > 0 {
+				if err = s.saveVersion(sess, existing); err != nil {
+					return err
+				}
+			}
+
 			existing.Updated = now
+			existing.Author = cmd.Author
 			if cmd.Contents != nil {
 				contents := *cmd.Contents
 				existing.Contents = contents
@@ -148,7 +183,7 @@ func (s dbFileStorage) Upsert(ctx context.Context, cmd *UpsertFileCommand) error
 				existing.Size = int64(len(contents))
 			}
 
-			_, err = sess.Where("LOWER(path) = ?", strings.ToLower(cmd.Path)).Update(existing)
+			_, err = sess.Where("LOWER(path) = ?", strings.ToLower(cmd.Path)).MustCols("author").Update(existing)
 			if err != nil {
 				return err
 			}
@@ -166,6 +201,7 @@ func (s dbFileStorage) Upsert(ctx context.Context, cmd *UpsertFileCommand) error
 				Size:             int64(len(contentsToInsert)),
 				Updated:          now,
 				Created:          now,
+				Author:           cmd.Author,
 			}
 			_, err := sess.Insert(file)
 			if err != nil {
@@ -0,0 +1,324 @@
+package filestorage
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+type fileVersion struct {
+	ID         int64     `xorm:"pk autoincr 'id'"`
+	Path       string    `xorm:"path"`
+	Version    int64     `xorm:"'version'"`
+	Contents   []byte    `xorm:"contents"`
+	Size       int64     `xorm:"size"`
+	MimeType   string    `xorm:"mime_type"`
+	Properties string    `xorm:"properties"`
+	Author     string    `xorm:"author"`
+	Created    time.Time `xorm:"'created'"`
+}
+
+type fileTrash struct {
+	ID         int64     `xorm:"pk autoincr 'id'"`
+	Path       string    `xorm:"path"`
+	Contents   []byte    `xorm:"contents"`
+	Size       int64     `xorm:"size"`
+	MimeType   string    `xorm:"mime_type"`
+	Properties string    `xorm:"properties"`
+	Author     string    `xorm:"author"`
+	Created    time.Time `xorm:"'created'"`
+	Updated    time.Time `xorm:"'updated'"`
+	Deleted    time.Time `xorm:"'deleted'"`
+}
+
+var (
+	_ versionedStorage = (*dbFileStorage)(nil) // dbFileStorage implements versionedStorage
+)
+
+func encodeProperties(props map[string]string) (string, error) {
+	if len(props) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(props)
+	return string(b), err
+}
+
+func decodeProperties(props string) map[string]string {
+	decoded := make(map[string]string)
+	if props != "" {
+		_ = json.Unmarshal([]byte(props), &decoded)
+	}
+	return decoded
+}
+
+func (s dbFileStorage) currentProperties(sess *sqlstore.DBSession, path string) (string, error) {
+	propertiesByPath, err := s.getProperties(sess, []string{strings.ToLower(path)})
+	if err != nil {
+		return "", err
+	}
+	return encodeProperties(propertiesByPath[strings.ToLower(path)])
+}
+
+// saveVersion keeps the current contents of a file before they are overwritten, and deletes the
+// versions that exceed the maximum.
+func (s dbFileStorage) saveVersion(sess *sqlstore.DBSession, current *file) error {
+	lowerPath := strings.ToLower(current.Path)
+
+	latest := &fileVersion{}
+	exists, err := sess.Table("file_version").Where("LOWER(path) = ?", lowerPath).Cols("version").OrderBy("version DESC").Limit(1).Get(latest)
+	if err != nil {
+		return err
+	}
+	next := int64(1)
+	if exists {
+		next = latest.Version + 1
+	}
+
+	props, err := s.currentProperties(sess, current.Path)
+	if err != nil {
+		return err
+	}
+
+	contents := current.Contents
+	if contents == nil {
+		contents = make([]byte, 0)
+	}
+	if _, err := sess.Insert(&fileVersion{
+		Path:       current.Path,
+		Version:    next,
+		Contents:   contents,
+		Size:       current.Size,
+		MimeType:   current.MimeType,
+		Properties: props,
+		Author:     current.Author,
+		Created:    current.Updated,
+	}); err != nil {
+		return err
+	}
+
+	oldest := &fileVersion{}
+	exists, err = sess.Table("file_version").Where("LOWER(path) = ?", lowerPath).Cols("version").OrderBy("version DESC").Limit(1, s.versions.MaxVersions).Get(oldest)
+	if err != nil || !exists {
+		return err
+	}
+	number, err := sess.Table("file_version").Where("LOWER(path) = ? AND version <= ?", lowerPath, oldest.Version).Delete(&fileVersion{})
+	if err != nil {
+		return err
+	}
+	s.log.Debug("Deleted old file versions", "path", current.Path, "affectedRecords", number)
+	return nil
+}
+
+func deleteVersions(sess *sqlstore.DBSession, path string) error {
+	_, err := sess.Table("file_version").Where("LOWER(path) = ?", strings.ToLower(path)).Delete(&fileVersion{})
+	return err
+}
+
+func (s dbFileStorage) moveToTrash(sess *sqlstore.DBSession, current *file, now time.Time) error {
+	props, err := s.currentProperties(sess, current.Path)
+	if err != nil {
+		return err
+	}
+
+	contents := current.Contents
+	if contents == nil {
+		contents = make([]byte, 0)
+	}
+	_, err = sess.Insert(&fileTrash{
+		Path:       current.Path,
+		Contents:   contents,
+		Size:       current.Size,
+		MimeType:   current.MimeType,
+		Properties: props,
+		Author:     current.Author,
+		Created:    current.Created,
+		Updated:    current.Updated,
+		Deleted:    now,
+	})
+	return err
+}
+
+func (s dbFileStorage) versioningEnabled() bool {
+	return s.versions.MaxVersions > 0 || s.versions.Trash
+}
+
+func (s dbFileStorage) listVersions(ctx context.Context, path string) ([]FileVersion, error) {
+	versions := make([]FileVersion, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		found := make([]*fileVersion, 0)
+		if err := sess.Table("file_version").Where("LOWER(path) = ?", strings.ToLower(path)).Omit("contents").OrderBy("version DESC").Find(&found); err != nil {
+			return err
+		}
+
+		for _, v := range found {
+			versions = append(versions, FileVersion{
+				Version: v.Version,
+				Author:  v.Author,
+				FileMetadata: FileMetadata{
+					Name:       getName(v.Path),
+					FullPath:   v.Path,
+					MimeType:   v.MimeType,
+					Size:       v.Size,
+					Created:    v.Created,
+					Modified:   v.Created,
+					Properties: decodeProperties(v.Properties),
+				},
+			})
+		}
+		return nil
+	})
+	return versions, err
+}
+
+func (s dbFileStorage) getVersion(ctx context.Context, path string, version int64) (*File, error) {
+	var result *File
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		v := &fileVersion{}
+		exists, err := sess.Table("file_version").Where("LOWER(path) = ? AND version = ?", strings.ToLower(path), version).Get(v)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrVersionNotFound
+		}
+
+		contents := v.Contents
+		if contents == nil {
+			contents = make([]byte, 0)
+		}
+		result = &File{
+			Contents: contents,
+			FileMetadata: FileMetadata{
+				Name:       getName(v.Path),
+				FullPath:   v.Path,
+				MimeType:   v.MimeType,
+				Size:       v.Size,
+				Created:    v.Created,
+				Modified:   v.Created,
+				Properties: decodeProperties(v.Properties),
+			},
+		}
+		return nil
+	})
+	return result, err
+}
+
+func trashedFile(t *fileTrash) TrashedFile {
+	return TrashedFile{
+		ID:      t.ID,
+		Author:  t.Author,
+		Deleted: t.Deleted,
+		FileMetadata: FileMetadata{
+			Name:       getName(t.Path),
+			FullPath:   t.Path,
+			MimeType:   t.MimeType,
+			Size:       t.Size,
+			Created:    t.Created,
+			Modified:   t.Updated,
+			Properties: decodeProperties(t.Properties),
+		},
+	}
+}
+
+// likePrefix returns the pattern of a LIKE ... ESCAPE '!' condition that matches the strings
+// starting with prefix, so the wildcards in the prefix are matched literally.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(prefix) + "%"
+}
+
+func (s dbFileStorage) listTrash(ctx context.Context, folderPath string) ([]TrashedFile, error) {
+	files := make([]TrashedFile, 0)
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		found := make([]*fileTrash, 0)
+		if err := sess.Table("file_trash").Where("LOWER(path) LIKE ? ESCAPE '!'", likePrefix(strings.ToLower(folderPath))).Omit("contents").OrderBy("deleted DESC, id DESC").Find(&found); err != nil {
+			return err
+		}
+
+		for _, t := range found {
+			files = append(files, trashedFile(t))
+		}
+		return nil
+	})
+	return files, err
+}
+
+func (s dbFileStorage) getTrashedFile(ctx context.Context, id int64) (*TrashedFile, []byte, error) {
+	var result *TrashedFile
+	var contents []byte
+	err := s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		t := &fileTrash{}
+		exists, err := sess.Table("file_trash").Where("id = ?", id).Get(t)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrTrashedFileNotFound
+		}
+
+		trashed := trashedFile(t)
+		result = &trashed
+		contents = t.Contents
+		if contents == nil {
+			contents = make([]byte, 0)
+		}
+		return nil
+	})
+	return result, contents, err
+}
+
+func (s dbFileStorage) deleteTrashedFile(ctx context.Context, id int64) error {
+	return s.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table("file_trash").Where("id = ?", id).Delete(&fileTrash{})
+		return err
+	})
+}
+
+// purgeTrash deletes the files in the trash of a folder that were deleted before the given time.
+// The versions of the purged files are deleted as well, unless the path was used again.
+func (s dbFileStorage) purgeTrash(ctx context.Context, folderPath string, deletedBefore time.Time) (int64, error) {
+	var purged int64
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		expired := make([]*fileTrash, 0)
+		if err := sess.Table("file_trash").Where("LOWER(path) LIKE ? ESCAPE '!' AND deleted < ?", likePrefix(strings.ToLower(folderPath)), deletedBefore).Cols("id", "path").Find(&expired); err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(expired))
+		paths := make(map[string]bool)
+		for _, t := range expired {
+			ids = append(ids, t.ID)
+			paths[strings.ToLower(t.Path)] = true
+		}
+
+		number, err := sess.Table("file_trash").In("id", ids).Delete(&fileTrash{})
+		if err != nil {
+			return err
+		}
+		purged = number
+
+		for path := range paths {
+			fileExists, err := sess.Table("file").Where("LOWER(path) = ?", path).Exist()
+			if err != nil {
+				return err
+			}
+			trashed, err := sess.Table("file_trash").Where("LOWER(path) = ?", path).Exist()
+			if err != nil {
+				return err
+			}
+			if fileExists || trashed {
+				continue
+			}
+			if err := deleteVersions(sess, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
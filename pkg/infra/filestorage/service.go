@@ -0,0 +1,336 @@
+package filestorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+	"gocloud.dev/blob/memblob"
+
+	_ "gocloud.dev/blob/azureblob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+)
+
+const (
+	RootTypeDB    = "db"
+	RootTypeLocal = "local"
+	RootTypeMem   = "mem"
+	RootTypeS3    = "s3"
+	RootTypeGCS   = "gcs"
+	RootTypeAzure = "azure"
+)
+
+var (
+	ErrRootNotFound = errors.New("storage root not found")
+	ErrReadOnly     = errors.New("storage root is read only")
+
+	rootNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+	// bucketSchemes are the gocloud URL schemes of the bucket root types.
+	bucketSchemes = map[string]string{
+		RootTypeS3:    "s3",
+		RootTypeGCS:   "gs",
+		RootTypeAzure: "azblob",
+	}
+)
+
+// Root is a named root of the file storage.
+type Root struct {
+	Name     string
+	Type     string
+	ReadOnly bool
+	// PathFilters are the filters of the root, which Storage applies by ignoring the paths
+	// that are not allowed. A nil PathFilters allows all paths.
+	PathFilters *PathFilters
+	Storage     FileStorage
+	// Versions gives access to the previous versions and the trash of the files of the root,
+	// and is nil when neither is enabled.
+	Versions VersionedFileStorage
+	// TrashRetention is how long deleted files are kept in the trash, 0 when the trash is disabled.
+	TrashRetention time.Duration
+}
+
+// Service mounts the storage roots configured in the [storage.<name>] sections.
+type Service struct {
+	log   log.Logger
+	roots map[string]*Root
+	names []string
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, features featuremgmt.FeatureToggles) (*Service, error) {
+	// the tables of the database storage are only created when the feature is enabled
+	if !features.IsEnabled(featuremgmt.FlagDbFileStorage) {
+		sqlStore = nil
+	}
+	return newService(context.Background(), cfg.StorageRoots, sqlStore)
+}
+
+func newService(ctx context.Context, settings []setting.StorageRootSettings, sqlStore *sqlstore.SQLStore) (*Service, error) {
+	s := &Service{
+		log:   log.New("filestorage"),
+		roots: make(map[string]*Root, len(settings)),
+		names: make([]string, 0, len(settings)),
+	}
+
+	for _, rs := range settings {
+		storage, err := s.openRoot(ctx, rs, sqlStore)
+		if err != nil {
+			_ = s.close()
+			return nil, fmt.Errorf("failed to open storage root %q: %w", rs.Name, err)
+		}
+		if rs.ReadOnly {
+			storage = &readOnlyFileStorage{wrapped: storage}
+		}
+		root := &Root{
+			Name:           rs.Name,
+			Type:           rs.Type,
+			ReadOnly:       rs.ReadOnly,
+			PathFilters:    pathFiltersFromSettings(rs),
+			Storage:        storage,
+			TrashRetention: rs.TrashRetention,
+		}
+		if rs.Versions > 0 || rs.TrashRetention > 0 {
+			root.Versions = storage.(VersionedFileStorage)
+		}
+		s.roots[rs.Name] = root
+		s.names = append(s.names, rs.Name)
+		s.log.Info("Mounted storage root", "name", rs.Name, "type", rs.Type, "readOnly", rs.ReadOnly)
+	}
+	sort.Strings(s.names)
+
+	return s, nil
+}
+
+func pathFiltersFromSettings(rs setting.StorageRootSettings) *PathFilters {
+	if rs.AllowedPrefixes == nil && rs.AllowedPaths == nil && rs.DisallowedPrefixes == nil && rs.DisallowedPaths == nil {
+		return nil
+	}
+	return NewPathFilters(rs.AllowedPrefixes, rs.AllowedPaths, rs.DisallowedPrefixes, rs.DisallowedPaths)
+}
+
+// rootFolder returns the folder of the backend the files of a root are stored in. Paths in the
+// database start with the delimiter, while keys of blobs do not.
+func rootFolder(prefix string, leadingDelimiter bool) string {
+	prefix = strings.Trim(prefix, Delimiter)
+	if leadingDelimiter {
+		if prefix == "" {
+			return Delimiter
+		}
+		return Delimiter + prefix + Delimiter
+	}
+	if prefix == "" {
+		return ""
+	}
+	return prefix + Delimiter
+}
+
+func (s *Service) openRoot(ctx context.Context, rs setting.StorageRootSettings, sqlStore *sqlstore.SQLStore) (FileStorage, error) {
+	if !rootNameRegex.MatchString(rs.Name) {
+		return nil, fmt.Errorf("invalid name, it can only contain lowercase letters, numbers, '-' and '_'")
+	}
+
+	logger := s.log.New("root", rs.Name)
+	pathFilters := pathFiltersFromSettings(rs)
+
+	if rs.Type != RootTypeDB && (rs.Versions > 0 || rs.TrashRetention > 0) {
+		return nil, fmt.Errorf("versions and trash_retention are only supported by %s storage", RootTypeDB)
+	}
+
+	switch rs.Type {
+	case RootTypeDB:
+		if sqlStore == nil {
+			return nil, fmt.Errorf("database storage requires the %s feature toggle", featuremgmt.FlagDbFileStorage)
+		}
+		// roots share the database, so they are kept apart by their name unless a prefix is set
+		prefix := rs.Prefix
+		if prefix == "" {
+			prefix = rs.Name
+		}
+		return NewVersionedDbStorage(logger, sqlStore, pathFilters, rootFolder(prefix, true), VersioningOptions{
+			MaxVersions: rs.Versions,
+			Trash:       rs.TrashRetention > 0,
+		}), nil
+	case RootTypeLocal:
+		if rs.Path == "" {
+			return nil, errors.New("path is required for local storage")
+		}
+		bucket, err := fileblob.OpenBucket(rs.Path, &fileblob.Options{CreateDir: true})
+		if err != nil {
+			return nil, err
+		}
+		return NewCdkBlobStorage(logger, bucket, rootFolder(rs.Prefix, false), pathFilters), nil
+	case RootTypeMem:
+		return NewCdkBlobStorage(logger, memblob.OpenBucket(nil), rootFolder(rs.Prefix, false), pathFilters), nil
+	case RootTypeS3, RootTypeGCS, RootTypeAzure:
+		u, err := url.Parse(rs.BucketURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket_url: %w", err)
+		}
+		if u.Scheme != bucketSchemes[rs.Type] {
+			return nil, fmt.Errorf("bucket_url of %s storage must start with %s://", rs.Type, bucketSchemes[rs.Type])
+		}
+		bucket, err := blob.OpenBucket(ctx, rs.BucketURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewCdkBlobStorage(logger, bucket, rootFolder(rs.Prefix, false), pathFilters), nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", rs.Type)
+	}
+}
+
+// Root returns the root with the given name, or ErrRootNotFound.
+func (s *Service) Root(name string) (*Root, error) {
+	root, ok := s.roots[name]
+	if !ok {
+		return nil, ErrRootNotFound
+	}
+	return root, nil
+}
+
+// Roots returns all roots ordered by name.
+func (s *Service) Roots() []*Root {
+	roots := make([]*Root, 0, len(s.names))
+	for _, name := range s.names {
+		roots = append(roots, s.roots[name])
+	}
+	return roots
+}
+
+// Run closes the roots when Grafana shuts down.
+func (s *Service) Run(ctx context.Context) error {
+	<-ctx.Done()
+	if err := s.close(); err != nil {
+		s.log.Error("Failed to close storage roots", "error", err)
+	}
+	return ctx.Err()
+}
+
+// PurgeTrash permanently deletes the files that have been in the trash of a root for longer than its retention.
+func (s *Service) PurgeTrash(ctx context.Context) (int64, error) {
+	var purged int64
+	now := time.Now()
+	for _, root := range s.Roots() {
+		if root.Versions == nil || root.TrashRetention <= 0 {
+			continue
+		}
+		number, err := root.Versions.PurgeTrash(ctx, now.Add(-root.TrashRetention))
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge the trash of storage root %q: %w", root.Name, err)
+		}
+		purged += number
+	}
+	return purged, nil
+}
+
+func (s *Service) close() error {
+	var lastErr error
+	for _, root := range s.roots {
+		if err := root.Storage.close(); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// readOnlyFileStorage rejects all changes to the wrapped storage. The trash can still be purged.
+type readOnlyFileStorage struct {
+	wrapped FileStorage
+}
+
+var (
+	_ FileStorage          = (*readOnlyFileStorage)(nil) // readOnlyFileStorage implements FileStorage
+	_ VersionedFileStorage = (*readOnlyFileStorage)(nil) // readOnlyFileStorage implements VersionedFileStorage
+)
+
+func (r readOnlyFileStorage) Get(ctx context.Context, path string) (*File, error) {
+	return r.wrapped.Get(ctx, path)
+}
+
+func (r readOnlyFileStorage) Delete(ctx context.Context, path string) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyFileStorage) Upsert(ctx context.Context, command *UpsertFileCommand) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyFileStorage) ListFiles(ctx context.Context, folderPath string, paging *Paging, options *ListOptions) (*ListFilesResponse, error) {
+	return r.wrapped.ListFiles(ctx, folderPath, paging, options)
+}
+
+func (r readOnlyFileStorage) ListFolders(ctx context.Context, folderPath string, options *ListOptions) ([]FileMetadata, error) {
+	return r.wrapped.ListFolders(ctx, folderPath, options)
+}
+
+func (r readOnlyFileStorage) CreateFolder(ctx context.Context, path string) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyFileStorage) DeleteFolder(ctx context.Context, path string) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyFileStorage) close() error {
+	return r.wrapped.close()
+}
+
+func (r readOnlyFileStorage) versioned() (VersionedFileStorage, error) {
+	vs, ok := r.wrapped.(VersionedFileStorage)
+	if !ok {
+		return nil, ErrVersioningDisabled
+	}
+	return vs, nil
+}
+
+func (r readOnlyFileStorage) ListVersions(ctx context.Context, path string) ([]FileVersion, error) {
+	vs, err := r.versioned()
+	if err != nil {
+		return nil, err
+	}
+	return vs.ListVersions(ctx, path)
+}
+
+func (r readOnlyFileStorage) GetVersion(ctx context.Context, path string, version int64) (*File, error) {
+	vs, err := r.versioned()
+	if err != nil {
+		return nil, err
+	}
+	return vs.GetVersion(ctx, path, version)
+}
+
+func (r readOnlyFileStorage) RestoreVersion(ctx context.Context, path string, version int64, author string) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyFileStorage) ListTrash(ctx context.Context, folderPath string) ([]TrashedFile, error) {
+	vs, err := r.versioned()
+	if err != nil {
+		return nil, err
+	}
+	return vs.ListTrash(ctx, folderPath)
+}
+
+func (r readOnlyFileStorage) RestoreFromTrash(ctx context.Context, id int64, author string) (*TrashedFile, error) {
+	return nil, ErrReadOnly
+}
+
+func (r readOnlyFileStorage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	vs, err := r.versioned()
+	if err != nil {
+		return 0, err
+	}
+	return vs.PurgeTrash(ctx, deletedBefore)
+}
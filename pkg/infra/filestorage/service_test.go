@@ -0,0 +1,342 @@
+package filestorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Roots(t *testing.T) {
+	ctx := context.Background()
+	contents := []byte("contents")
+
+	s, err := newService(ctx, []setting.StorageRootSettings{
+		{
+			Name: "public",
+			Type: RootTypeLocal,
+			Path: t.TempDir(),
+		},
+		{
+			Name:     "assets",
+			Type:     RootTypeMem,
+			ReadOnly: true,
+		},
+		{
+			Name:            "images",
+			Type:            RootTypeMem,
+			Prefix:          "/nested/images/",
+			AllowedPrefixes: []string{"/uploads/"},
+		},
+	}, nil)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.close())
+	}()
+
+	roots := s.Roots()
+	require.Len(t, roots, 3)
+	require.Equal(t, []string{"assets", "images", "public"}, []string{roots[0].Name, roots[1].Name, roots[2].Name})
+
+	_, err = s.Root("unknown")
+	require.ErrorIs(t, err, ErrRootNotFound)
+
+	t.Run("local root", func(t *testing.T) {
+		root, err := s.Root("public")
+		require.NoError(t, err)
+		require.NoError(t, root.Storage.Upsert(ctx, &UpsertFileCommand{Path: "/folder/file.txt", Contents: &contents}))
+		file, err := root.Storage.Get(ctx, "/folder/file.txt")
+		require.NoError(t, err)
+		require.Equal(t, contents, file.Contents)
+		require.Equal(t, "/folder/file.txt", file.FullPath)
+	})
+
+	t.Run("read only root rejects changes", func(t *testing.T) {
+		root, err := s.Root("assets")
+		require.NoError(t, err)
+		require.True(t, root.ReadOnly)
+		require.ErrorIs(t, root.Storage.Upsert(ctx, &UpsertFileCommand{Path: "/file.txt", Contents: &contents}), ErrReadOnly)
+		require.ErrorIs(t, root.Storage.Delete(ctx, "/file.txt"), ErrReadOnly)
+		require.ErrorIs(t, root.Storage.CreateFolder(ctx, "/folder"), ErrReadOnly)
+		require.ErrorIs(t, root.Storage.DeleteFolder(ctx, "/folder"), ErrReadOnly)
+
+		file, err := root.Storage.Get(ctx, "/file.txt")
+		require.NoError(t, err)
+		require.Nil(t, file)
+	})
+
+	t.Run("path filters of the root are applied", func(t *testing.T) {
+		root, err := s.Root("images")
+		require.NoError(t, err)
+		require.NoError(t, root.Storage.Upsert(ctx, &UpsertFileCommand{Path: "/uploads/a.png", Contents: &contents}))
+		require.NoError(t, root.Storage.Upsert(ctx, &UpsertFileCommand{Path: "/other/b.png", Contents: &contents}))
+
+		file, err := root.Storage.Get(ctx, "/uploads/a.png")
+		require.NoError(t, err)
+		require.NotNil(t, file)
+
+		file, err = root.Storage.Get(ctx, "/other/b.png")
+		require.NoError(t, err)
+		require.Nil(t, file)
+
+		resp, err := root.Storage.ListFiles(ctx, "/", nil, &ListOptions{Recursive: true})
+		require.NoError(t, err)
+		require.Len(t, resp.Files, 1)
+		require.Equal(t, "/uploads/a.png", resp.Files[0].FullPath)
+	})
+}
+
+func TestService_DBRoots(t *testing.T) {
+	ctx := context.Background()
+	contents := []byte("contents")
+
+	s, err := newService(ctx, []setting.StorageRootSettings{
+		{Name: "a", Type: RootTypeDB},
+		{Name: "b", Type: RootTypeDB},
+	}, sqlstore.InitTestDB(t))
+	require.NoError(t, err)
+
+	a, err := s.Root("a")
+	require.NoError(t, err)
+	require.NoError(t, a.Storage.Upsert(ctx, &UpsertFileCommand{Path: "/folder/file.txt", Contents: &contents}))
+
+	file, err := a.Storage.Get(ctx, "/folder/file.txt")
+	require.NoError(t, err)
+	require.Equal(t, contents, file.Contents)
+	require.Equal(t, "/folder/file.txt", file.FullPath)
+
+	// roots in the database do not see the files of each other
+	b, err := s.Root("b")
+	require.NoError(t, err)
+	file, err = b.Storage.Get(ctx, "/folder/file.txt")
+	require.NoError(t, err)
+	require.Nil(t, file)
+}
+
+func TestService_DBVersions(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := newService(ctx, []setting.StorageRootSettings{
+		{Name: "assets", Type: RootTypeDB, Versions: 2, TrashRetention: time.Hour},
+		{Name: "other", Type: RootTypeDB, Versions: 2, TrashRetention: time.Hour},
+		{Name: "plain", Type: RootTypeDB},
+	}, sqlstore.InitTestDB(t))
+	require.NoError(t, err)
+
+	plain, err := s.Root("plain")
+	require.NoError(t, err)
+	require.Nil(t, plain.Versions)
+
+	root, err := s.Root("assets")
+	require.NoError(t, err)
+	require.NotNil(t, root.Versions)
+
+	upsert := func(contents string, author string) {
+		b := []byte(contents)
+		require.NoError(t, root.Storage.Upsert(ctx, &UpsertFileCommand{
+			Path:       "/maps/world.geojson",
+			Contents:   &b,
+			Properties: map[string]string{"rev": contents},
+			Author:     author,
+		}))
+	}
+
+	t.Run("keeps the previous versions", func(t *testing.T) {
+		upsert("v1", "alice")
+		upsert("v2", "bob")
+		upsert("v3", "carol")
+		upsert("v4", "dave")
+
+		versions, err := root.Versions.ListVersions(ctx, "/maps/world.geojson")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.Equal(t, int64(3), versions[0].Version)
+		require.Equal(t, "carol", versions[0].Author)
+		require.Equal(t, "/maps/world.geojson", versions[0].FullPath)
+		require.Equal(t, map[string]string{"rev": "v3"}, versions[0].Properties)
+		require.Equal(t, int64(2), versions[1].Version)
+		require.Equal(t, "bob", versions[1].Author)
+
+		file, err := root.Versions.GetVersion(ctx, "/maps/world.geojson", 2)
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), file.Contents)
+
+		_, err = root.Versions.GetVersion(ctx, "/maps/world.geojson", 1)
+		require.ErrorIs(t, err, ErrVersionNotFound)
+	})
+
+	t.Run("restores a version", func(t *testing.T) {
+		require.NoError(t, root.Versions.RestoreVersion(ctx, "/maps/world.geojson", 2, "erin"))
+
+		file, err := root.Storage.Get(ctx, "/maps/world.geojson")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), file.Contents)
+		require.Equal(t, map[string]string{"rev": "v2"}, file.Properties)
+
+		// the overwritten contents are kept as a new version
+		versions, err := root.Versions.ListVersions(ctx, "/maps/world.geojson")
+		require.NoError(t, err)
+		require.Equal(t, int64(4), versions[0].Version)
+		require.Equal(t, "dave", versions[0].Author)
+	})
+
+	t.Run("moves deleted files to the trash", func(t *testing.T) {
+		require.NoError(t, root.Storage.Delete(ctx, "/maps/world.geojson"))
+		file, err := root.Storage.Get(ctx, "/maps/world.geojson")
+		require.NoError(t, err)
+		require.Nil(t, file)
+
+		trash, err := root.Versions.ListTrash(ctx, "/")
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		require.Equal(t, "/maps/world.geojson", trash[0].FullPath)
+		require.Equal(t, "erin", trash[0].Author)
+
+		trash, err = root.Versions.ListTrash(ctx, "/icons")
+		require.NoError(t, err)
+		require.Empty(t, trash)
+
+		// the trash of other roots is separate
+		other, err := s.Root("other")
+		require.NoError(t, err)
+		trash, err = other.Versions.ListTrash(ctx, "/")
+		require.NoError(t, err)
+		require.Empty(t, trash)
+	})
+
+	t.Run("restores a file from the trash", func(t *testing.T) {
+		trash, err := root.Versions.ListTrash(ctx, "/")
+		require.NoError(t, err)
+
+		other, err := s.Root("other")
+		require.NoError(t, err)
+		_, err = other.Versions.RestoreFromTrash(ctx, trash[0].ID, "frank")
+		require.ErrorIs(t, err, ErrTrashedFileNotFound)
+
+		upsert("new", "frank")
+		_, err = root.Versions.RestoreFromTrash(ctx, trash[0].ID, "frank")
+		require.ErrorIs(t, err, ErrFileAlreadyExists)
+		require.NoError(t, root.Storage.Delete(ctx, "/maps/world.geojson"))
+
+		restored, err := root.Versions.RestoreFromTrash(ctx, trash[0].ID, "frank")
+		require.NoError(t, err)
+		require.Equal(t, "/maps/world.geojson", restored.FullPath)
+
+		file, err := root.Storage.Get(ctx, "/maps/world.geojson")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), file.Contents)
+
+		trash, err = root.Versions.ListTrash(ctx, "/")
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		require.Equal(t, "frank", trash[0].Author)
+	})
+
+	t.Run("purges the trash after the retention", func(t *testing.T) {
+		require.NoError(t, root.Storage.Delete(ctx, "/maps/world.geojson"))
+
+		purged, err := s.PurgeTrash(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), purged)
+
+		purged, err = root.Versions.PurgeTrash(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), purged)
+
+		trash, err := root.Versions.ListTrash(ctx, "/")
+		require.NoError(t, err)
+		require.Empty(t, trash)
+
+		// the versions of files that are gone are purged with them
+		versions, err := root.Versions.ListVersions(ctx, "/maps/world.geojson")
+		require.NoError(t, err)
+		require.Empty(t, versions)
+	})
+}
+
+func TestService_DBTrashWildcards(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := newService(ctx, []setting.StorageRootSettings{
+		{Name: "team_a", Type: RootTypeDB, Versions: 2, TrashRetention: time.Hour},
+		{Name: "team-a", Type: RootTypeDB, Versions: 2, TrashRetention: time.Hour},
+	}, sqlstore.InitTestDB(t))
+	require.NoError(t, err)
+
+	wildcard, err := s.Root("team_a")
+	require.NoError(t, err)
+	other, err := s.Root("team-a")
+	require.NoError(t, err)
+
+	b := []byte("contents")
+	require.NoError(t, other.Storage.Upsert(ctx, &UpsertFileCommand{Path: "/notes.txt", Contents: &b}))
+	require.NoError(t, other.Storage.Delete(ctx, "/notes.txt"))
+
+	// the underscore of the root name does not match other characters
+	trash, err := wildcard.Versions.ListTrash(ctx, "/")
+	require.NoError(t, err)
+	require.Empty(t, trash)
+
+	purged, err := wildcard.Versions.PurgeTrash(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), purged)
+
+	trash, err = other.Versions.ListTrash(ctx, "/")
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+}
+
+func TestService_InvalidRoots(t *testing.T) {
+	var tests = []struct {
+		name     string
+		settings setting.StorageRootSettings
+		err      string
+	}{
+		{
+			name:     "invalid name",
+			settings: setting.StorageRootSettings{Name: "My Root", Type: RootTypeMem},
+			err:      `failed to open storage root "My Root": invalid name, it can only contain lowercase letters, numbers, '-' and '_'`,
+		},
+		{
+			name:     "unknown type",
+			settings: setting.StorageRootSettings{Name: "root", Type: "ftp"},
+			err:      `failed to open storage root "root": unknown type "ftp"`,
+		},
+		{
+			name:     "local without path",
+			settings: setting.StorageRootSettings{Name: "root", Type: RootTypeLocal},
+			err:      `failed to open storage root "root": path is required for local storage`,
+		},
+		{
+			name:     "bucket url of another type",
+			settings: setting.StorageRootSettings{Name: "root", Type: RootTypeS3, BucketURL: "gs://bucket"},
+			err:      `failed to open storage root "root": bucket_url of s3 storage must start with s3://`,
+		},
+		{
+			name:     "versions of a blob root",
+			settings: setting.StorageRootSettings{Name: "root", Type: RootTypeMem, Versions: 3},
+			err:      `failed to open storage root "root": versions and trash_retention are only supported by db storage`,
+		},
+		{
+			name:     "db without database",
+			settings: setting.StorageRootSettings{Name: "root", Type: RootTypeDB},
+			err:      `failed to open storage root "root": database storage requires the dbFileStorage feature toggle`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newService(context.Background(), []setting.StorageRootSettings{tt.settings}, nil)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRootFolder(t *testing.T) {
+	require.Equal(t, "", rootFolder("", false))
+	require.Equal(t, "a/b/", rootFolder("/a/b/", false))
+	require.Equal(t, "/", rootFolder("", true))
+	require.Equal(t, "/a/", rootFolder("a", true))
+}
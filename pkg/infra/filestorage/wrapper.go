@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 )
@@ -117,7 +118,8 @@ func newWrapper(log log.Logger, wrapped FileStorage, pathFilters *PathFilters, r
 }
 
 var (
-	_ FileStorage = (*wrapper)(nil) // wrapper implements FileStorage
+	_ FileStorage          = (*wrapper)(nil) // wrapper implements FileStorage
+	_ VersionedFileStorage = (*wrapper)(nil) // wrapper implements VersionedFileStorage
 )
 
 func getParentFolderPath(path string) string {
@@ -147,6 +149,11 @@ func getName(path string) string {
 	return split[len(split)-1]
 }
 
+// ValidatePath returns an error if the path is not an absolute and canonical path of a file or folder.
+func ValidatePath(path string) error {
+	return validatePath(path)
+}
+
 func validatePath(path string) error {
 	if !filepath.IsAbs(path) {
 		return ErrRelativePath
@@ -257,6 +264,7 @@ func (b wrapper) Upsert(ctx context.Context, file *UpsertFileCommand) error {
 		MimeType:   file.MimeType,
 		Contents:   file.Contents,
 		Properties: file.Properties,
+		Author:     file.Author,
 	})
 }
 
@@ -419,6 +427,145 @@ func (b wrapper) isFolderEmpty(ctx context.Context, path string) (bool, error) {
 	return true, nil
 }
 
+func (b wrapper) versioned() (versionedStorage, error) {
+	vs, ok := b.wrapped.(versionedStorage)
+	if !ok || !vs.versioningEnabled() {
+		return nil, ErrVersioningDisabled
+	}
+	return vs, nil
+}
+
+func (b wrapper) ListVersions(ctx context.Context, path string) ([]FileVersion, error) {
+	vs, err := b.versioned()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.validatePath(path); err != nil {
+		return nil, err
+	}
+
+	rootedPath := b.addRoot(path)
+	if !b.pathFilters.IsAllowed(rootedPath) {
+		return []FileVersion{}, nil
+	}
+
+	versions, err := vs.listVersions(ctx, rootedPath)
+	for i := range versions {
+		versions[i].FullPath = b.removeRoot(versions[i].FullPath)
+	}
+	return versions, err
+}
+
+func (b wrapper) GetVersion(ctx context.Context, path string, version int64) (*File, error) {
+	vs, err := b.versioned()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.validatePath(path); err != nil {
+		return nil, err
+	}
+
+	rootedPath := b.addRoot(path)
+	if !b.pathFilters.IsAllowed(rootedPath) {
+		return nil, ErrVersionNotFound
+	}
+
+	file, err := vs.getVersion(ctx, rootedPath, version)
+	if file != nil {
+		file.FullPath = b.removeRoot(file.FullPath)
+	}
+	return file, err
+}
+
+func (b wrapper) RestoreVersion(ctx context.Context, path string, version int64, author string) error {
+	file, err := b.GetVersion(ctx, path, version)
+	if err != nil {
+		return err
+	}
+
+	return b.Upsert(ctx, &UpsertFileCommand{
+		Path:       path,
+		MimeType:   file.MimeType,
+		Contents:   &file.Contents,
+		Properties: file.Properties,
+		Author:     author,
+	})
+}
+
+func (b wrapper) ListTrash(ctx context.Context, folderPath string) ([]TrashedFile, error) {
+	vs, err := b.versioned()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.validatePath(folderPath); err != nil {
+		return nil, err
+	}
+
+	folder := b.addRoot(folderPath)
+	if !strings.HasSuffix(folder, Delimiter) {
+		folder += Delimiter
+	}
+	trashed, err := vs.listTrash(ctx, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]TrashedFile, 0, len(trashed))
+	for _, t := range trashed {
+		if !b.pathFilters.IsAllowed(t.FullPath) {
+			continue
+		}
+		t.FullPath = b.removeRoot(t.FullPath)
+		allowed = append(allowed, t)
+	}
+	return allowed, nil
+}
+
+func (b wrapper) RestoreFromTrash(ctx context.Context, id int64, author string) (*TrashedFile, error) {
+	vs, err := b.versioned()
+	if err != nil {
+		return nil, err
+	}
+
+	trashed, contents, err := vs.getTrashedFile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// the trash of the database is shared by the roots
+	if !strings.HasPrefix(strings.ToLower(trashed.FullPath), strings.ToLower(b.rootFolder)) || !b.pathFilters.IsAllowed(trashed.FullPath) {
+		return nil, ErrTrashedFileNotFound
+	}
+	trashed.FullPath = b.removeRoot(trashed.FullPath)
+
+	existing, err := b.Get(ctx, trashed.FullPath)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrFileAlreadyExists
+	}
+
+	if err := b.Upsert(ctx, &UpsertFileCommand{
+		Path:       trashed.FullPath,
+		MimeType:   trashed.MimeType,
+		Contents:   &contents,
+		Properties: trashed.Properties,
+		Author:     author,
+	}); err != nil {
+		return nil, err
+	}
+
+	return trashed, vs.deleteTrashedFile(ctx, id)
+}
+
+func (b wrapper) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	vs, err := b.versioned()
+	if err != nil {
+		return 0, err
+	}
+	return vs.purgeTrash(ctx, b.rootFolder, deletedBefore)
+}
+
 func (b wrapper) close() error {
 	return b.wrapped.close()
 }
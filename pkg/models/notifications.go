@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var ErrInvalidEmailCode = errors.New("invalid or expired email code")
 var ErrSmtpNotEnabled = errors.New("SMTP not configured, check your grafana.ini config file's [smtp] section")
@@ -37,6 +40,45 @@ type SendWebhookSync struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+	// Signing signs the body of the request when set.
+	Signing *WebhookSigning
+	// TLS configures the client certificate and the trusted CA of the request when set.
+	TLS *WebhookTLS
+	// Retry retries the request when it fails with a network error, a 5xx or a 429 status.
+	Retry *WebhookRetry
+}
+
+// WebhookSigning signs the body of webhook requests with HMAC-SHA256. The signature is computed over the
+// timestamp of the request, a dot and the body, so that the receiver can reject replayed requests.
+type WebhookSigning struct {
+	Secret string
+	// SignatureHeader is the header of the hex encoded signature, X-Grafana-Signature by default.
+	SignatureHeader string
+	// TimestampHeader is the header of the Unix timestamp of the request, X-Grafana-Timestamp by default.
+	TimestampHeader string
+}
+
+// WebhookTLS is the TLS configuration of webhook requests. The certificates and the key are PEM encoded.
+type WebhookTLS struct {
+	ClientCertificate  string
+	ClientKey          string
+	CACertificate      string
+	InsecureSkipVerify bool
+}
+
+const (
+	// MaxWebhookRetries is the maximum number of times a failed webhook request is retried.
+	MaxWebhookRetries = 10
+	// MaxWebhookBackoff is the maximum wait between two retries of a webhook request.
+	MaxWebhookBackoff = time.Minute
+)
+
+// WebhookRetry is the retry policy of webhook requests.
+type WebhookRetry struct {
+	// MaxRetries is the number of times a failed request is retried, up to MaxWebhookRetries.
+	MaxRetries int
+	// Backoff is the wait before the first retry. It doubles at every retry, up to MaxWebhookBackoff.
+	Backoff time.Duration
 }
 
 type SendResetPasswordEmailCommand struct {
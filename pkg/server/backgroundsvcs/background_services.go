@@ -2,6 +2,7 @@ package backgroundsvcs
 
 import (
 	"github.com/grafana/grafana/pkg/api"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -15,29 +16,36 @@ import (
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
+	"github.com/grafana/grafana/pkg/services/live/pushkafka"
+	"github.com/grafana/grafana/pkg/services/live/pushmqtt"
+	"github.com/grafana/grafana/pkg/services/live/pushnats"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/searchV2"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/thumbs"
 	"github.com/grafana/grafana/pkg/services/updatechecker"
 )
 
 func ProvideBackgroundServiceRegistry(
 	httpServer *api.HTTPServer, ng *ngalert.AlertNG, cleanup *cleanup.CleanUpService, live *live.GrafanaLive,
-	pushGateway *pushhttp.Gateway, notifications *notifications.NotificationService, pm *manager.PluginManager,
+	pushGateway *pushhttp.Gateway, mqttGateway *pushmqtt.Gateway, kafkaGateway *pushkafka.Gateway,
+	natsGateway *pushnats.Gateway, notifications *notifications.NotificationService, pm *manager.PluginManager,
 	rendering *rendering.RenderingService, tokenService models.UserTokenBackgroundService, tracing tracing.Tracer,
 	provisioning *provisioning.ProvisioningServiceImpl, alerting *alerting.AlertEngine, usageStats *uss.UsageStats,
 	grafanaUpdateChecker *updatechecker.GrafanaService, pluginsUpdateChecker *updatechecker.PluginsService,
 	metrics *metrics.InternalMetricsService, secretsService *secretsManager.SecretsService,
-	remoteCache *remotecache.RemoteCache, thumbnailsService thumbs.Service,
+	remoteCache *remotecache.RemoteCache, thumbnailsService thumbs.Service, fileStorage *filestorage.Service,
+	searchService *searchV2.StandardSearchService,
 	// Need to make sure these are initialized, is there a better place to put them?
 	_ *dashboardsnapshots.Service, _ *alerting.AlertNotificationService,
 	_ serviceaccounts.Service, _ *guardian.Provider,
-	_ *plugindashboardsservice.DashboardUpdater,
+	_ *plugindashboardsservice.DashboardUpdater, _ *store.StorageHTTPService,
 ) *BackgroundServiceRegistry {
 	return NewBackgroundServiceRegistry(
 		httpServer,
@@ -45,6 +53,9 @@ func ProvideBackgroundServiceRegistry(
 		cleanup,
 		live,
 		pushGateway,
+		mqttGateway,
+		kafkaGateway,
+		natsGateway,
 		notifications,
 		rendering,
 		tokenService,
@@ -58,7 +69,9 @@ func ProvideBackgroundServiceRegistry(
 		tracing,
 		remoteCache,
 		secretsService,
-		thumbnailsService)
+		thumbnailsService,
+		fileStorage,
+		searchService)
 }
 
 // BackgroundServiceRegistry provides background services.
@@ -1,8 +1,15 @@
 package notifiers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -38,6 +45,10 @@ func init() {
 						Value: "PUT",
 						Label: "PUT",
 					},
+					{
+						Value: "PATCH",
+						Label: "PATCH",
+					},
 				},
 				PropertyName: "httpMethod",
 			},
@@ -54,6 +65,86 @@ func init() {
 				PropertyName: "password",
 				Secure:       true,
 			},
+			{
+				Label:        "Body",
+				Description:  "Go template of the body of the request, executed with the default webhook message. The body is the default webhook message if empty.",
+				Element:      alerting.ElementTypeTextArea,
+				PropertyName: "body",
+			},
+			{
+				Label:        "Content Type",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "application/json",
+				PropertyName: "contentType",
+			},
+			{
+				Label:        "Headers",
+				Description:  "Headers of the request, one Name: Value per line",
+				Element:      alerting.ElementTypeTextArea,
+				PropertyName: "headers",
+			},
+			{
+				Label:        "HMAC Secret",
+				Description:  "Signs the requests with HMAC-SHA256 over the timestamp of the request and the body",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypePassword,
+				PropertyName: "hmacSecret",
+				Secure:       true,
+			},
+			{
+				Label:        "HMAC Signature Header",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "X-Grafana-Signature",
+				PropertyName: "hmacSignatureHeader",
+			},
+			{
+				Label:        "HMAC Timestamp Header",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "X-Grafana-Timestamp",
+				PropertyName: "hmacTimestampHeader",
+			},
+			{
+				Label:        "TLS Client Certificate",
+				Description:  "PEM encoded client certificate for mutual TLS",
+				Element:      alerting.ElementTypeTextArea,
+				PropertyName: "tlsClientCertificate",
+			},
+			{
+				Label:        "TLS Client Key",
+				Description:  "PEM encoded key of the client certificate",
+				Element:      alerting.ElementTypeTextArea,
+				PropertyName: "tlsClientKey",
+				Secure:       true,
+			},
+			{
+				Label:        "TLS CA Certificate",
+				Description:  "PEM encoded certificate of the CA of the server",
+				Element:      alerting.ElementTypeTextArea,
+				PropertyName: "tlsCACertificate",
+			},
+			{
+				Label:        "Skip TLS Verify",
+				Element:      alerting.ElementTypeCheckbox,
+				PropertyName: "tlsSkipVerify",
+			},
+			{
+				Label:        "Max Retries",
+				Description:  "Number of times a request that fails with a network error, a 5xx or a 429 status is retried, at most 10",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				PropertyName: "maxRetries",
+			},
+			{
+				Label:        "Retry Backoff",
+				Description:  "Wait before the first retry, doubled at every retry up to 1m",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "1s",
+				PropertyName: "retryBackoff",
+			},
 		},
 	})
 }
@@ -68,14 +159,94 @@ func NewWebHookNotifier(model *models.AlertNotification, fn alerting.GetDecrypte
 
 	password := fn(context.Background(), model.SecureSettings, "password", model.Settings.Get("password").MustString(), setting.SecretKey)
 
-	return &WebhookNotifier{
+	httpMethod := model.Settings.Get("httpMethod").MustString(http.MethodPost)
+	if httpMethod != http.MethodPost && httpMethod != http.MethodPut && httpMethod != http.MethodPatch {
+		return nil, alerting.ValidationError{Reason: fmt.Sprintf("Unsupported HTTP method %s, use POST, PUT or PATCH", httpMethod)}
+	}
+
+	var body *template.Template
+	if text := model.Settings.Get("body").MustString(); text != "" {
+		var err error
+		if body, err = template.New("body").Parse(text); err != nil {
+			return nil, alerting.ValidationError{Reason: "Invalid body template", Err: err}
+		}
+	}
+
+	headers, err := parseHeaders(model.Settings.Get("headers").MustString())
+	if err != nil {
+		return nil, alerting.ValidationError{Reason: err.Error()}
+	}
+
+	notifier := &WebhookNotifier{
 		NotifierBase: NewNotifierBase(model, ns),
 		URL:          url,
 		User:         model.Settings.Get("username").MustString(),
 		Password:     password,
-		HTTPMethod:   model.Settings.Get("httpMethod").MustString("POST"),
+		HTTPMethod:   httpMethod,
+		Body:         body,
+		ContentType:  model.Settings.Get("contentType").MustString(),
+		Headers:      headers,
 		log:          log.New("alerting.notifier.webhook"),
-	}, nil
+	}
+
+	if secret := fn(context.Background(), model.SecureSettings, "hmacSecret", model.Settings.Get("hmacSecret").MustString(), setting.SecretKey); secret != "" {
+		notifier.Signing = &models.WebhookSigning{
+			Secret:          secret,
+			SignatureHeader: model.Settings.Get("hmacSignatureHeader").MustString(),
+			TimestampHeader: model.Settings.Get("hmacTimestampHeader").MustString(),
+		}
+	}
+
+	tlsConfig := models.WebhookTLS{
+		ClientCertificate:  model.Settings.Get("tlsClientCertificate").MustString(),
+		ClientKey:          fn(context.Background(), model.SecureSettings, "tlsClientKey", model.Settings.Get("tlsClientKey").MustString(), setting.SecretKey),
+		CACertificate:      model.Settings.Get("tlsCACertificate").MustString(),
+		InsecureSkipVerify: model.Settings.Get("tlsSkipVerify").MustBool(false),
+	}
+	if (tlsConfig.ClientCertificate == "") != (tlsConfig.ClientKey == "") {
+		return nil, alerting.ValidationError{Reason: "Both the client certificate and the client key must be set"}
+	}
+	if tlsConfig != (models.WebhookTLS{}) {
+		notifier.TLS = &tlsConfig
+	}
+
+	maxRetries := 0
+	if s := model.Settings.Get("maxRetries").MustString(); s != "" {
+		if maxRetries, err = strconv.Atoi(s); err != nil || maxRetries < 0 || maxRetries > models.MaxWebhookRetries {
+			return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid max retries %s, it must be between 0 and %d", s, models.MaxWebhookRetries)}
+		}
+	}
+	if maxRetries > 0 {
+		backoff := time.Second
+		if s := model.Settings.Get("retryBackoff").MustString(); s != "" {
+			if backoff, err = time.ParseDuration(s); err != nil || backoff <= 0 {
+				return nil, alerting.ValidationError{Reason: fmt.Sprintf("Invalid retry backoff %s", s)}
+			}
+		}
+		notifier.Retry = &models.WebhookRetry{MaxRetries: maxRetries, Backoff: backoff}
+	}
+
+	return notifier, nil
+}
+
+// parseHeaders parses the headers of the requests, one "Name: Value" per line.
+func parseHeaders(text string) (map[string]string, error) {
+	var headers map[string]string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid header %q, use Name: Value", line)
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return headers, nil
 }
 
 // WebhookNotifier is responsible for sending
@@ -86,7 +257,14 @@ type WebhookNotifier struct {
 	User       string
 	Password   string
 	HTTPMethod string
-	log        log.Logger
+	// Body is the template of the body of the requests, executed with the WebhookNotifierBody.
+	Body        *template.Template
+	ContentType string
+	Headers     map[string]string
+	Signing     *models.WebhookSigning
+	TLS         *models.WebhookTLS
+	Retry       *models.WebhookRetry
+	log         log.Logger
 }
 
 // WebhookNotifierBody is the body of webhook
@@ -143,14 +321,30 @@ func (wn *WebhookNotifier) Notify(evalContext *alerting.EvalContext) error {
 		body.Message = evalContext.Rule.Message
 	}
 
-	bodyJSON, _ := json.Marshal(body)
+	var content string
+	if wn.Body != nil {
+		var buf bytes.Buffer
+		if err := wn.Body.Execute(&buf, body); err != nil {
+			wn.log.Error("Failed to template webhook body", "error", err, "webhook", wn.Name)
+			return err
+		}
+		content = buf.String()
+	} else {
+		bodyJSON, _ := json.Marshal(body)
+		content = string(bodyJSON)
+	}
 
 	cmd := &models.SendWebhookSync{
-		Url:        wn.URL,
-		User:       wn.User,
-		Password:   wn.Password,
-		Body:       string(bodyJSON),
-		HttpMethod: wn.HTTPMethod,
+		Url:         wn.URL,
+		User:        wn.User,
+		Password:    wn.Password,
+		Body:        content,
+		HttpMethod:  wn.HTTPMethod,
+		HttpHeader:  wn.Headers,
+		ContentType: wn.ContentType,
+		Signing:     wn.Signing,
+		TLS:         wn.TLS,
+		Retry:       wn.Retry,
 	}
 
 	if err := wn.NotificationService.SendWebhookSync(evalContext.Ctx, cmd); err != nil {
@@ -1,11 +1,15 @@
 package notifiers
 
 import (
+	"bytes"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,3 +50,68 @@ func TestWebhookNotifier_parsingFromSettings(t *testing.T) {
 		assert.Equal(t, "http://google.com", webhookNotifier.URL)
 	})
 }
+
+func TestWebhookNotifier_options(t *testing.T) {
+	t.Run("Options should be parsed from the settings", func(t *testing.T) {
+		const json = `{
+			"url": "http://google.com",
+			"httpMethod": "PATCH",
+			"body": "{\"title\": \"{{ .Title }}\"}",
+			"contentType": "application/vnd.incident+json",
+			"headers": "X-Team: ops\nX-Source: grafana",
+			"tlsCACertificate": "ca",
+			"maxRetries": "2",
+			"retryBackoff": "500ms"
+		}`
+
+		settingsJSON, err := simplejson.NewJson([]byte(json))
+		require.NoError(t, err)
+		encryptionService := ossencryption.ProvideService()
+		secret, err := encryptionService.Encrypt(context.Background(), []byte("secret"), setting.SecretKey)
+		require.NoError(t, err)
+		model := &models.AlertNotification{
+			Name:           "ops",
+			Type:           "webhook",
+			Settings:       settingsJSON,
+			SecureSettings: map[string][]byte{"hmacSecret": secret},
+		}
+
+		not, err := NewWebHookNotifier(model, encryptionService.GetDecryptedValue, nil)
+		require.NoError(t, err)
+		webhookNotifier := not.(*WebhookNotifier)
+
+		assert.Equal(t, "PATCH", webhookNotifier.HTTPMethod)
+		assert.Equal(t, "application/vnd.incident+json", webhookNotifier.ContentType)
+		assert.Equal(t, map[string]string{"X-Team": "ops", "X-Source": "grafana"}, webhookNotifier.Headers)
+		assert.Equal(t, &models.WebhookSigning{Secret: "secret"}, webhookNotifier.Signing)
+		assert.Equal(t, &models.WebhookTLS{CACertificate: "ca"}, webhookNotifier.TLS)
+		assert.Equal(t, &models.WebhookRetry{MaxRetries: 2, Backoff: 500 * time.Millisecond}, webhookNotifier.Retry)
+
+		var body bytes.Buffer
+		require.NoError(t, webhookNotifier.Body.Execute(&body, WebhookNotifierBody{Title: "[Alerting] CPU"}))
+		assert.Equal(t, `{"title": "[Alerting] CPU"}`, body.String())
+	})
+
+	for name, json := range map[string]string{
+		"unsupported HTTP method":          `{"url": "http://google.com", "httpMethod": "GET"}`,
+		"invalid body template":            `{"url": "http://google.com", "body": "{{ .Title"}`,
+		"invalid header":                   `{"url": "http://google.com", "headers": "X-Team"}`,
+		"client certificate without a key": `{"url": "http://google.com", "tlsClientCertificate": "cert"}`,
+		"invalid max retries":              `{"url": "http://google.com", "maxRetries": "many"}`,
+		"too many max retries":             `{"url": "http://google.com", "maxRetries": "100"}`,
+		"invalid retry backoff":            `{"url": "http://google.com", "maxRetries": "1", "retryBackoff": "soon"}`,
+	} {
+		t.Run("Settings with "+name+" should cause error", func(t *testing.T) {
+			settingsJSON, err := simplejson.NewJson([]byte(json))
+			require.NoError(t, err)
+			model := &models.AlertNotification{
+				Name:     "ops",
+				Type:     "webhook",
+				Settings: settingsJSON,
+			}
+
+			_, err = NewWebHookNotifier(model, ossencryption.ProvideService().GetDecryptedValue, nil)
+			require.Error(t, err)
+		})
+	}
+}
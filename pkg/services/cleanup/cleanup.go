@@ -8,6 +8,7 @@ import (
 	"path"
 	"time"
 
+	"github.com/grafana/grafana/pkg/infra/filestorage"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 
@@ -19,12 +20,13 @@ import (
 )
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
-	shortURLService shorturls.Service, store sqlstore.Store) *CleanUpService {
+	shortURLService shorturls.Service, store sqlstore.Store, fileStorage *filestorage.Service) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:               cfg,
 		ServerLockService: serverLockService,
 		ShortURLService:   shortURLService,
 		store:             store,
+		fileStorage:       fileStorage,
 		log:               log.New("cleanup"),
 	}
 	return s
@@ -33,6 +35,7 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 type CleanUpService struct {
 	log               log.Logger
 	store             sqlstore.Store
+	fileStorage       *filestorage.Service
 	Cfg               *setting.Cfg
 	ServerLockService *serverlock.ServerLockService
 	ShortURLService   shorturls.Service
@@ -54,6 +57,7 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpOldAnnotations(ctxWithTimeout)
 			srv.expireOldUserInvites(ctx)
 			srv.deleteStaleShortURLs(ctx)
+			srv.purgeFileStorageTrash(ctx)
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func(context.Context) {
 					srv.deleteOldLoginAttempts(ctx)
@@ -183,3 +187,22 @@ func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 		srv.log.Debug("Deleted short urls", "rows affected", cmd.NumDeleted)
 	}
 }
+
+func (srv *CleanUpService) purgeFileStorageTrash(ctx context.Context) {
+	if srv.fileStorage == nil {
+		return
+	}
+
+	err := srv.ServerLockService.LockAndExecute(ctx, "purge file storage trash",
+		time.Minute*10, func(context.Context) {
+			purged, err := srv.fileStorage.PurgeTrash(ctx)
+			if err != nil {
+				srv.log.Error("Problem purging the trash of the file storage", "error", err.Error())
+			} else {
+				srv.log.Debug("Purged the trash of the file storage", "files deleted", purged)
+			}
+		})
+	if err != nil {
+		srv.log.Error("failed to lock and execute purge of the file storage trash", "error", err)
+	}
+}
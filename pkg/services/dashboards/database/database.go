@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
@@ -171,6 +172,9 @@ func (d *DashboardStore) SaveProvisionedDashboard(cmd models.SaveDashboardComman
 
 		return saveProvisionedData(sess, provisioning, cmd.Result)
 	})
+	if err == nil {
+		d.publishDashboardSaved(cmd.Result)
+	}
 
 	return cmd.Result, err
 }
@@ -179,9 +183,29 @@ func (d *DashboardStore) SaveDashboard(cmd models.SaveDashboardCommand) (*models
 	err := d.sqlStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		return saveDashboard(sess, &cmd)
 	})
+	if err == nil {
+		d.publishDashboardSaved(cmd.Result)
+	}
 	return cmd.Result, err
 }
 
+// publishDashboardSaved publishes the DashboardSaved event once the transaction that saved the dashboard is committed.
+func (d *DashboardStore) publishDashboardSaved(dash *models.Dashboard) {
+	if d.sqlStore.Bus == nil {
+		return
+	}
+	err := d.sqlStore.Bus.Publish(context.Background(), &events.DashboardSaved{
+		Timestamp: dash.Updated,
+		ID:        dash.Id,
+		UID:       dash.Uid,
+		OrgID:     dash.OrgId,
+		IsFolder:  dash.IsFolder,
+	})
+	if err != nil {
+		d.log.Error("Failed to publish dashboard saved event", "error", err)
+	}
+}
+
 func (d *DashboardStore) UpdateDashboardACL(ctx context.Context, dashboardID int64, items []*models.DashboardAcl) error {
 	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		// delete existing items
@@ -157,6 +157,11 @@ var (
 			Description: "Enable annotation comments",
 			State:       FeatureStateAlpha,
 		},
+		{
+			Name:        "dbFileStorage",
+			Description: "Allow storage roots in the SQL database",
+			State:       FeatureStateAlpha,
+		},
 		{
 			Name:        "migrationLocking",
 			Description: "Lock database during migrations",
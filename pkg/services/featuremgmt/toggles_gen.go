@@ -119,6 +119,10 @@ const (
 	// Enable annotation comments
 	FlagAnnotationComments = "annotationComments"
 
+	// FlagDbFileStorage
+	// Allow storage roots in the SQL database
+	FlagDbFileStorage = "dbFileStorage"
+
 	// FlagMigrationLocking
 	// Lock database during migrations
 	FlagMigrationLocking = "migrationLocking"
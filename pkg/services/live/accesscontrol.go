@@ -0,0 +1,10 @@
+package live
+
+// Actions of the channel rules of the Live pipeline. Channel rules belong to an
+// organization, so the permissions are evaluated in the organization of the user.
+const (
+	ActionChannelRulesRead   = "live.channelrules:read"
+	ActionChannelRulesCreate = "live.channelrules:create"
+	ActionChannelRulesWrite  = "live.channelrules:write"
+	ActionChannelRulesDelete = "live.channelrules:delete"
+)
@@ -152,10 +152,29 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			return nil, fmt.Errorf("error creating Live Redis presence manager: %v", err)
 		}
 		node.SetPresenceManager(presenceManager)
+	} else {
+		// The memory broker keeps the history of managed streams. Expire the
+		// stream meta data of idle channels like the Redis broker does, the
+		// publications themselves expire after the history TTL.
+		broker, err := centrifuge.NewMemoryBroker(node, centrifuge.MemoryBrokerConfig{
+			HistoryMetaTTL: 7 * 24 * time.Hour,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating Live memory broker: %v", err)
+		}
+		node.SetBroker(broker)
 	}
 
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
+	historyConfig := managedstream.HistoryConfig{
+		Size: g.Cfg.LiveHistorySize,
+		TTL:  g.Cfg.LiveHistoryTTL,
+	}
+	managedStreamPublisher := g.Publish
+	if historyConfig.Enabled() {
+		managedStreamPublisher = g.publishWithHistory(historyConfig)
+	}
 	var managedStreamRunner *managedstream.Runner
 	if g.IsHA() {
 		redisClient := redis.NewClient(&redis.Options{
@@ -166,20 +185,33 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			return nil, fmt.Errorf("error pinging Redis: %v", err)
 		}
 		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
+			managedStreamPublisher,
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
+			historyConfig,
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
+			managedStreamPublisher,
 			channelLocalPublisher,
 			managedstream.NewMemoryFrameCache(),
+			historyConfig,
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 	if g.Features.IsEnabled(featuremgmt.FlagLivePipeline) {
+		if cfg.LiveKafka.Enabled {
+			kafkaConfig, err := pipeline.NewKafkaConfig(cfg.LiveKafka)
+			if err != nil {
+				return nil, err
+			}
+			g.kafkaPublisher = pipeline.NewKafkaPublisher(cfg.LiveKafka.Brokers, kafkaConfig)
+		}
+		if cfg.LiveNATS.Enabled {
+			g.natsPublisher = pipeline.NewNATSPublisher(cfg.LiveNATS.URL, pipeline.NewNATSOptions(cfg.LiveNATS)...)
+		}
+
 		var builder pipeline.RuleBuilder
 		if os.Getenv("GF_LIVE_DEV_BUILDER") != "" {
 			builder = &pipeline.DevRuleBuilder{
@@ -189,11 +221,22 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				ChannelHandlerGetter: g,
 			}
 		} else {
-			storage := &pipeline.FileStorage{
-				DataPath:       cfg.DataPath,
-				SecretsService: g.SecretsService,
+			var storage pipeline.Storage
+			if g.Features.IsEnabled(featuremgmt.FlagLiveConfig) {
+				storage = &pipeline.SQLStorage{
+					SQLStore:       sqlStore,
+					SecretsService: g.SecretsService,
+				}
+			} else {
+				storage = &pipeline.FileStorage{
+					DataPath:       cfg.DataPath,
+					SecretsService: g.SecretsService,
+				}
+			}
+			g.pipelineStorage = &pipeline.NotifyingStorage{
+				Storage:  storage,
+				OnChange: g.notifyPipelineChanged,
 			}
-			g.pipelineStorage = storage
 			builder = &pipeline.StorageRuleBuilder{
 				Node:                 node,
 				ManagedStream:        g.ManagedStreamRunner,
@@ -201,9 +244,15 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 				Storage:              storage,
 				ChannelHandlerGetter: g,
 				SecretsService:       g.SecretsService,
+				KafkaPublisher:       g.kafkaPublisher,
+				KafkaTopicPrefixes:   cfg.LiveKafka.OutputTopicPrefixes,
+				NATSPublisher:        g.natsPublisher,
+				NATSSubjectPrefixes:  cfg.LiveNATS.OutputSubjectPrefixes,
 			}
 		}
 		channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
+		g.channelRuleCache = channelRuleGetter
+		node.OnNotification(g.handleNotification)
 
 		// Pre-build/validate channel rules for all organizations on start.
 		// This can be unreasonable to have in production scenario with many
@@ -290,6 +339,16 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 			}
 		})
 
+		// Called when client requests the history of the channel.
+		client.OnHistory(func(e centrifuge.HistoryEvent, cb centrifuge.HistoryCallback) {
+			err := runConcurrentlyIfNeeded(client.Context(), semaphore, func() {
+				cb(g.handleOnHistory(client, e))
+			})
+			if err != nil {
+				cb(centrifuge.HistoryReply{}, err)
+			}
+		})
+
 		// Called when a client publishes to the channel.
 		// In general, we should prefer writing to the HTTP API, but this
 		// allows some simple prototypes to work quickly.
@@ -426,6 +485,9 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	channelRuleCache    *pipeline.CacheSegmentedTree
+	kafkaPublisher      pipeline.MessagePublisher
+	natsPublisher       pipeline.MessagePublisher
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -710,6 +772,31 @@ func (g *GrafanaLive) handleOnSubscribe(ctx context.Context, client *centrifuge.
 	}, nil
 }
 
+// handleOnHistory returns the history of a channel the client is subscribed to.
+// Only managed streams keep a history, the history of other channels is empty.
+func (g *GrafanaLive) handleOnHistory(client *centrifuge.Client, e centrifuge.HistoryEvent) (centrifuge.HistoryReply, error) {
+	logger.Debug("Client wants history", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
+
+	// Subscribe permissions were checked when the client subscribed.
+	if !client.IsSubscribed(e.Channel) {
+		logger.Info("Error getting history: client not subscribed", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
+		return centrifuge.HistoryReply{}, centrifuge.ErrorPermissionDenied
+	}
+
+	// A zero limit only returns the stream position, return the whole history
+	// instead since it is bounded by the history size.
+	limit := e.Filter.Limit
+	if limit == 0 {
+		limit = centrifuge.NoLimit
+	}
+	result, err := g.node.History(e.Channel, centrifuge.WithLimit(limit), centrifuge.WithSince(e.Filter.Since), centrifuge.WithReverse(e.Filter.Reverse))
+	if err != nil {
+		logger.Error("Error getting history", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.HistoryReply{}, centrifuge.ErrorInternal
+	}
+	return centrifuge.HistoryReply{Result: &result}, nil
+}
+
 func (g *GrafanaLive) handleOnPublish(ctx context.Context, client *centrifuge.Client, e centrifuge.PublishEvent) (centrifuge.PublishReply, error) {
 	logger.Debug("Client wants to publish", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
 
@@ -943,6 +1030,17 @@ func (g *GrafanaLive) Publish(orgID int64, channel string, data []byte) error {
 	return err
 }
 
+// publishWithHistory returns a publisher that keeps the publications in channel
+// history, so that subscribers can load the recent publications and recover
+// the ones they missed.
+func (g *GrafanaLive) publishWithHistory(config managedstream.HistoryConfig) models.ChannelPublisher {
+	return func(orgID int64, channel string, data []byte) error {
+		logger.Debug("publish into channel with history", "channel", channel, "orgId", orgID, "data", string(data))
+		_, err := g.node.Publish(orgchannel.PrependOrgID(orgID, channel), data, centrifuge.WithHistory(config.Size, config.TTL))
+		return err
+	}
+}
+
 // ClientCount returns the number of clients.
 func (g *GrafanaLive) ClientCount(orgID int64, channel string) (int, error) {
 	p, err := g.node.Presence(orgchannel.PrependOrgID(orgID, channel))
@@ -1058,6 +1156,49 @@ func (g *GrafanaLive) HandleInfoHTTP(ctx *models.ReqContext) response.Response {
 	})
 }
 
+// PipelineStorage returns the storage of the channel rules and write configs of
+// the Live pipeline. Its changes are applied on all Grafana instances. It is nil
+// when the pipeline is disabled.
+func (g *GrafanaLive) PipelineStorage() pipeline.Storage {
+	return g.pipelineStorage
+}
+
+// pipelineChangedOp is the operation of node notifications sent when channel
+// rules or write configs of an organization were changed. In HA setup they are
+// delivered to other Grafana instances over Redis.
+const pipelineChangedOp = "pipeline_changed"
+
+type pipelineChangedNotification struct {
+	OrgID int64 `json:"orgId"`
+}
+
+func (g *GrafanaLive) notifyPipelineChanged(orgID int64) {
+	data, err := json.Marshal(pipelineChangedNotification{OrgID: orgID})
+	if err != nil {
+		logger.Error("Error marshaling pipeline change notification", "error", err)
+		return
+	}
+	// Notification is also handled by this node synchronously, so the
+	// changes are applied locally when the request is completed.
+	if err := g.node.Notify(pipelineChangedOp, data, ""); err != nil {
+		logger.Error("Error sending pipeline change notification", "error", err, "orgId", orgID)
+	}
+}
+
+func (g *GrafanaLive) handleNotification(e centrifuge.NotificationEvent) {
+	if e.Op != pipelineChangedOp {
+		return
+	}
+	var n pipelineChangedNotification
+	if err := json.Unmarshal(e.Data, &n); err != nil {
+		logger.Error("Error decoding pipeline change notification", "error", err)
+		return
+	}
+	if err := g.channelRuleCache.Invalidate(n.OrgID); err != nil {
+		logger.Error("Error rebuilding channel rules", "error", err, "orgId", n.OrgID)
+	}
+}
+
 // HandleChannelRulesListHTTP ...
 func (g *GrafanaLive) HandleChannelRulesListHTTP(c *models.ReqContext) response.Response {
 	result, err := g.pipelineStorage.ListChannelRules(c.Req.Context(), c.OrgId)
@@ -1139,6 +1280,10 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *models.ReqContext) respon
 		FrameStorage:         pipeline.NewFrameStorage(),
 		Storage:              storage,
 		ChannelHandlerGetter: g,
+		KafkaPublisher:       g.kafkaPublisher,
+		KafkaTopicPrefixes:   g.Cfg.LiveKafka.OutputTopicPrefixes,
+		NATSPublisher:        g.natsPublisher,
+		NATSSubjectPrefixes:  g.Cfg.LiveNATS.OutputSubjectPrefixes,
 	}
 	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
 	pipe, err := pipeline.New(channelRuleGetter)
@@ -1222,6 +1367,9 @@ func (g *GrafanaLive) HandleChannelRulesDeleteHTTP(c *models.ReqContext) respons
 		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
 	}
 	err = g.pipelineStorage.DeleteChannelRule(c.Req.Context(), c.OrgId, cmd)
+	if errors.Is(err, pipeline.ErrChannelRuleNotFound) {
+		return response.Error(http.StatusNotFound, "Channel rule not found", err)
+	}
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
 	}
@@ -1333,6 +1481,9 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *models.ReqContext) respons
 		return response.Error(http.StatusBadRequest, "UID required", nil)
 	}
 	err = g.pipelineStorage.DeleteWriteConfig(c.Req.Context(), c.OrgId, cmd)
+	if errors.Is(err, pipeline.ErrWriteConfigNotFound) {
+		return response.Error(http.StatusNotFound, "Write config not found", err)
+	}
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to delete write config", err)
 	}
@@ -41,19 +41,39 @@ type Runner struct {
 	publisher      models.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	history        HistoryConfig
+}
+
+// HistoryConfig sets the history of publications kept by Centrifuge for
+// managed stream channels (stream/...). New subscribers load the history to
+// show the recent points, and reconnecting subscribers recover the points they
+// missed. The history is disabled when Size or TTL is zero.
+type HistoryConfig struct {
+	// Size is the maximum number of publications kept per channel.
+	Size int
+	// TTL is how long the history of a channel is kept after its last
+	// publication, so that the history of idle channels expires.
+	TTL time.Duration
+}
+
+// Enabled returns true if the publications of managed streams are kept in history.
+func (c HistoryConfig) Enabled() bool {
+	return c.Size > 0 && c.TTL > 0
 }
 
 type LocalPublisher interface {
 	PublishLocal(channel string, data []byte) error
 }
 
-// NewRunner creates new Runner.
-func NewRunner(publisher models.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache) *Runner {
+// NewRunner creates new Runner. The publisher must keep the publications in
+// channel history when the history is enabled.
+func NewRunner(publisher models.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache, history HistoryConfig) *Runner {
 	return &Runner{
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		streams:        map[int64]map[string]*NamespaceStream{},
 		frameCache:     frameCache,
+		history:        history,
 	}
 }
 
@@ -124,7 +144,7 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	prefix := scope + "/" + namespace
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
-		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache, r.history)
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -138,6 +158,7 @@ type NamespaceStream struct {
 	publisher      models.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	history        HistoryConfig
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
 }
@@ -155,7 +176,7 @@ type ManagedChannel struct {
 }
 
 // NewNamespaceStream creates new NamespaceStream.
-func NewNamespaceStream(orgID int64, scope string, namespace string, publisher models.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache) *NamespaceStream {
+func NewNamespaceStream(orgID int64, scope string, namespace string, publisher models.ChannelPublisher, localPublisher LocalPublisher, schemaUpdater FrameCache, history HistoryConfig) *NamespaceStream {
 	return &NamespaceStream{
 		orgID:          orgID,
 		scope:          scope,
@@ -163,6 +184,7 @@ func NewNamespaceStream(orgID int64, scope string, namespace string, publisher m
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		frameCache:     schemaUpdater,
+		history:        history,
 		rates:          map[string][60]rateEntry{},
 	}
 }
@@ -247,6 +269,9 @@ func (s *NamespaceStream) OnSubscribe(ctx context.Context, u *models.SignedInUse
 	if ok {
 		reply.Data = frameJSON
 	}
+	// Frames of datasource and plugin scopes are published locally, without
+	// history, so only the stream scope can be recovered.
+	reply.Recover = s.history.Enabled() && s.scope == live.ScopeStream
 	return reply, backend.SubscribeStreamStatusOK, nil
 }
 
@@ -5,8 +5,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
 )
 
 type testPublisher struct {
@@ -19,13 +22,13 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), HistoryConfig{})
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), HistoryConfig{})
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -48,7 +51,7 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
 	frameCache := NewMemoryFrameCache()
-	runner := NewRunner(publisher.publish, nil, frameCache)
+	runner := NewRunner(publisher.publish, nil, frameCache, HistoryConfig{})
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
 	s2, err := runner.GetOrCreateStream(1, "stream", "test2")
@@ -83,3 +86,30 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestManagedStreamSubscribeRecover(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	user := &models.SignedInUser{OrgId: 1}
+	history := HistoryConfig{Size: 10, TTL: time.Minute}
+
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), history)
+	err := c.Push(context.Background(), "cpu", data.NewFrame("cpu", data.NewField("value", nil, []float64{1})))
+	require.NoError(t, err)
+
+	reply, status, err := c.OnSubscribe(context.Background(), user, models.SubscribeEvent{Channel: "stream/a/cpu", Path: "cpu"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	require.True(t, reply.Recover)
+	require.NotNil(t, reply.Data)
+
+	// Make sure channels without history are not recovered.
+	c = NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(), HistoryConfig{})
+	reply, _, err = c.OnSubscribe(context.Background(), user, models.SubscribeEvent{Channel: "stream/a/cpu", Path: "cpu"})
+	require.NoError(t, err)
+	require.False(t, reply.Recover)
+
+	c = NewNamespaceStream(1, "plugin", "a", publisher.publish, nil, NewMemoryFrameCache(), history)
+	reply, _, err = c.OnSubscribe(context.Background(), user, models.SubscribeEvent{Channel: "plugin/a/cpu", Path: "cpu"})
+	require.NoError(t, err)
+	require.False(t, reply.Recover)
+}
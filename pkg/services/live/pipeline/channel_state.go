@@ -0,0 +1,8 @@
+package pipeline
+
+import "time"
+
+// channelStateTTL is how long stateful frame processors keep the state of a
+// channel which does not receive frames anymore. Idle state is evicted lazily
+// while processing frames, at most once per channelStateTTL.
+const channelStateTTL = 10 * time.Minute
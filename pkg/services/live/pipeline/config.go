@@ -49,11 +49,71 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type RenameFieldsFrameProcessorConfig struct {
+	// Names maps current field names to new ones.
+	Names map[string]string `json:"names"`
+}
+
+type RelabelFieldsFrameProcessorConfig struct {
+	// FieldNames to relabel, all fields except time fields are relabeled if empty.
+	FieldNames []string `json:"fieldNames,omitempty"`
+	// Labels to set, existing labels with the same name are overwritten.
+	Labels []Label `json:"labels,omitempty"`
+	// DropLabels contains names of labels to remove.
+	DropLabels []string `json:"dropLabels,omitempty"`
+}
+
+type ExpressionFieldFrameProcessorConfig struct {
+	Name string         `json:"name"`
+	Type data.FieldType `json:"type"`
+	// Expression is a JavaScript expression evaluated for every frame row.
+	// Row values are available as properties of x, e.g. x.temperature * 1.8 + 32.
+	Expression string `json:"expression"`
+}
+
+type AggregateReducer string
+
+const (
+	AggregateReducerAvg   AggregateReducer = "avg"
+	AggregateReducerMin   AggregateReducer = "min"
+	AggregateReducerMax   AggregateReducer = "max"
+	AggregateReducerCount AggregateReducer = "count"
+)
+
+type AggregateField struct {
+	FieldName string           `json:"fieldName"`
+	Reducer   AggregateReducer `json:"reducer"`
+}
+
+type AggregateFrameProcessorConfig struct {
+	// IntervalSeconds is a size of a tumbling window.
+	IntervalSeconds int64 `json:"intervalSeconds"`
+	// TimeField to take point time from, the first time field is used if empty.
+	TimeField string           `json:"timeField,omitempty"`
+	Fields    []AggregateField `json:"fields"`
+}
+
+type RateLimitFrameProcessorConfig struct {
+	// IntervalMilliseconds is a minimal interval between frames passed further.
+	IntervalMilliseconds int64 `json:"intervalMilliseconds"`
+}
+
+type SampleFrameProcessorConfig struct {
+	// Every Nth frame is passed further, others are dropped.
+	Every int64 `json:"every"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                           string                               `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig      *DropFieldsFrameProcessorConfig      `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig      *KeepFieldsFrameProcessorConfig      `json:"keepFields,omitempty"`
+	MultipleProcessorConfig        *MultipleFrameProcessorConfig        `json:"multiple,omitempty"`
+	RenameFieldsProcessorConfig    *RenameFieldsFrameProcessorConfig    `json:"renameFields,omitempty"`
+	RelabelFieldsProcessorConfig   *RelabelFieldsFrameProcessorConfig   `json:"relabelFields,omitempty"`
+	ExpressionFieldProcessorConfig *ExpressionFieldFrameProcessorConfig `json:"expressionField,omitempty"`
+	AggregateProcessorConfig       *AggregateFrameProcessorConfig       `json:"aggregate,omitempty"`
+	RateLimitProcessorConfig       *RateLimitFrameProcessorConfig       `json:"rateLimit,omitempty"`
+	SampleProcessorConfig          *SampleFrameProcessorConfig          `json:"sample,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -78,6 +138,14 @@ type LokiOutputConfig struct {
 	UID string `json:"uid"`
 }
 
+type KafkaOutputConfig struct {
+	Topic string `json:"topic"`
+}
+
+type NATSOutputConfig struct {
+	Subject string `json:"subject"`
+}
+
 type MultipleSubscriberConfig struct {
 	Subscribers []SubscriberConfig `json:"subscribers"`
 }
@@ -96,6 +164,8 @@ type DataOutputterConfig struct {
 	Type                     string                    `json:"type" ts_type:"Omit<keyof DataOutputterConfig, 'type'>"`
 	RedirectDataOutputConfig *RedirectDataOutputConfig `json:"redirect,omitempty"`
 	LokiOutputConfig         *LokiOutputConfig         `json:"loki,omitempty"`
+	KafkaOutputConfig        *KafkaOutputConfig        `json:"kafka,omitempty"`
+	NATSOutputConfig         *NATSOutputConfig         `json:"nats,omitempty"`
 }
 
 type FrameOutputterConfig struct {
@@ -108,6 +178,8 @@ type FrameOutputterConfig struct {
 	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
 	LokiOutputConfig        *LokiOutputConfig          `json:"loki,omitempty"`
 	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	KafkaOutputConfig       *KafkaOutputConfig         `json:"kafka,omitempty"`
+	NATSOutputConfig        *NATSOutputConfig          `json:"nats,omitempty"`
 }
 
 type MultipleFrameConditionCheckerConfig struct {
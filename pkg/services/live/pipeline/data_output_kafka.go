@@ -0,0 +1,25 @@
+package pipeline
+
+import (
+	"context"
+)
+
+// KafkaDataOutput can output raw data to a Kafka topic.
+type KafkaDataOutput struct {
+	publisher MessagePublisher
+	config    KafkaOutputConfig
+}
+
+func NewKafkaDataOutput(publisher MessagePublisher, config KafkaOutputConfig) *KafkaDataOutput {
+	return &KafkaDataOutput{publisher: publisher, config: config}
+}
+
+const DataOutputTypeKafka = "kafka"
+
+func (out *KafkaDataOutput) Type() string {
+	return DataOutputTypeKafka
+}
+
+func (out *KafkaDataOutput) OutputData(_ context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	return nil, out.publisher.Publish(out.config.Topic, vars.Channel, data)
+}
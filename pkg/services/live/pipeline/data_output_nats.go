@@ -0,0 +1,25 @@
+package pipeline
+
+import (
+	"context"
+)
+
+// NATSDataOutput can output raw data to a NATS subject.
+type NATSDataOutput struct {
+	publisher MessagePublisher
+	config    NATSOutputConfig
+}
+
+func NewNATSDataOutput(publisher MessagePublisher, config NATSOutputConfig) *NATSDataOutput {
+	return &NATSDataOutput{publisher: publisher, config: config}
+}
+
+const DataOutputTypeNATS = "nats"
+
+func (out *NATSDataOutput) Type() string {
+	return DataOutputTypeNATS
+}
+
+func (out *NATSDataOutput) OutputData(_ context.Context, vars Vars, data []byte) ([]*ChannelData, error) {
+	return nil, out.publisher.Publish(out.config.Subject, vars.Channel, data)
+}
@@ -0,0 +1,31 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// KafkaFrameOutput can output frame encoded to JSON to a Kafka topic.
+type KafkaFrameOutput struct {
+	publisher MessagePublisher
+	config    KafkaOutputConfig
+}
+
+func NewKafkaFrameOutput(publisher MessagePublisher, config KafkaOutputConfig) *KafkaFrameOutput {
+	return &KafkaFrameOutput{publisher: publisher, config: config}
+}
+
+const FrameOutputTypeKafka = "kafka"
+
+func (out *KafkaFrameOutput) Type() string {
+	return FrameOutputTypeKafka
+}
+
+func (out *KafkaFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return nil, err
+	}
+	return nil, out.publisher.Publish(out.config.Topic, vars.Channel, frameJSON)
+}
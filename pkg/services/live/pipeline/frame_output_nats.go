@@ -0,0 +1,31 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// NATSFrameOutput can output frame encoded to JSON to a NATS subject.
+type NATSFrameOutput struct {
+	publisher MessagePublisher
+	config    NATSOutputConfig
+}
+
+func NewNATSFrameOutput(publisher MessagePublisher, config NATSOutputConfig) *NATSFrameOutput {
+	return &NATSFrameOutput{publisher: publisher, config: config}
+}
+
+const FrameOutputTypeNATS = "nats"
+
+func (out *NATSFrameOutput) Type() string {
+	return FrameOutputTypeNATS
+}
+
+func (out *NATSFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return nil, err
+	}
+	return nil, out.publisher.Publish(out.config.Subject, vars.Channel, frameJSON)
+}
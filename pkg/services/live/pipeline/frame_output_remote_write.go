@@ -1,10 +1,7 @@
 package pipeline
 
 import (
-	"bytes"
 	"context"
-	"errors"
-	"fmt"
 	"net/http"
 	"sync"
 	"time"
@@ -140,31 +137,19 @@ func (out *RemoteWriteFrameOutput) flush(timeSeries []prompb.TimeSeries) error {
 		}
 		logger.Debug("After down-sampling", "numTimeSeries", len(timeSeries), "numSamples", numSamples)
 	}
-	remoteWriteData, err := remotewrite.TimeSeriesToBytes(timeSeries)
-	if err != nil {
-		return fmt.Errorf("error converting time series to bytes: %v", err)
+	client := remotewrite.Client{
+		Endpoint:   out.Endpoint,
+		HTTPClient: out.httpClient,
 	}
-	logger.Debug("Sending to remote write endpoint", "url", out.Endpoint, "bodyLength", len(remoteWriteData))
-	req, err := http.NewRequest(http.MethodPost, out.Endpoint, bytes.NewReader(remoteWriteData))
-	if err != nil {
-		return fmt.Errorf("error constructing remote write request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/x-protobuf")
-	req.Header.Set("Content-Encoding", "snappy")
-	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
 	if out.BasicAuth != nil {
-		req.SetBasicAuth(out.BasicAuth.User, out.BasicAuth.Password)
+		client.User = out.BasicAuth.User
+		client.Password = out.BasicAuth.Password
 	}
+	logger.Debug("Sending to remote write endpoint", "url", out.Endpoint)
 
 	started := time.Now()
-	resp, err := out.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error sending remote write request: %w", err)
-	}
-	_ = resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		logger.Error("Unexpected response code from remote write endpoint", "code", resp.StatusCode)
-		return errors.New("unexpected response code from remote write endpoint")
+	if err := client.Send(context.Background(), timeSeries); err != nil {
+		return err
 	}
 	logger.Debug("Successfully sent to remote write endpoint", "url", out.Endpoint, "elapsed", time.Since(started))
 	return nil
@@ -0,0 +1,229 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// AggregateFrameProcessor reduces field values inside tumbling time windows.
+// Frames are held back until a point of the next window arrives, then one
+// row per closed window is passed further. Time of a row is the window start,
+// aggregated fields are named as <fieldName>_<reducer>. Late points are added
+// to the current window. Windows of channels without frames during
+// channelStateTTL are dropped without being passed. Not usable in HA setup:
+// windows are kept in memory of each instance.
+type AggregateFrameProcessor struct {
+	config      AggregateFrameProcessorConfig
+	nowTimeFunc func() time.Time
+
+	mu        sync.Mutex
+	windows   map[string]*aggregateWindow
+	lastSweep time.Time
+}
+
+type aggregateWindow struct {
+	start    time.Time
+	values   []aggregateValue
+	lastSeen time.Time
+}
+
+type aggregateValue struct {
+	labels data.Labels
+	count  int64
+	sum    float64
+	min    float64
+	max    float64
+}
+
+func (v *aggregateValue) add(val float64) {
+	if v.count == 0 || val < v.min {
+		v.min = val
+	}
+	if v.count == 0 || val > v.max {
+		v.max = val
+	}
+	v.count++
+	v.sum += val
+}
+
+func (v *aggregateValue) reduce(reducer AggregateReducer) *float64 {
+	if reducer == AggregateReducerCount {
+		count := float64(v.count)
+		return &count
+	}
+	if v.count == 0 {
+		return nil
+	}
+	var res float64
+	switch reducer {
+	case AggregateReducerAvg:
+		res = v.sum / float64(v.count)
+	case AggregateReducerMin:
+		res = v.min
+	case AggregateReducerMax:
+		res = v.max
+	}
+	return &res
+}
+
+func NewAggregateFrameProcessor(config AggregateFrameProcessorConfig) *AggregateFrameProcessor {
+	return &AggregateFrameProcessor{
+		config:      config,
+		nowTimeFunc: time.Now,
+		windows:     map[string]*aggregateWindow{},
+	}
+}
+
+const FrameProcessorTypeAggregate = "aggregate"
+
+func (p *AggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeAggregate
+}
+
+func (p *AggregateFrameProcessor) timeFieldIndex(frame *data.Frame) (int, error) {
+	for i, f := range frame.Fields {
+		if p.config.TimeField == "" && f.Type().Time() {
+			return i, nil
+		}
+		if p.config.TimeField != "" && f.Name == p.config.TimeField {
+			if !f.Type().Time() {
+				return -1, fmt.Errorf("field %s is not a time field", f.Name)
+			}
+			return i, nil
+		}
+	}
+	if p.config.TimeField != "" {
+		return -1, fmt.Errorf("time field %s not found", p.config.TimeField)
+	}
+	return -1, nil
+}
+
+func (p *AggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	if p.config.IntervalSeconds <= 0 {
+		return nil, fmt.Errorf("invalid aggregation interval: %d", p.config.IntervalSeconds)
+	}
+	for _, f := range p.config.Fields {
+		switch f.Reducer {
+		case AggregateReducerAvg, AggregateReducerMin, AggregateReducerMax, AggregateReducerCount:
+		default:
+			return nil, fmt.Errorf("unknown reducer %s for field %s", f.Reducer, f.FieldName)
+		}
+	}
+
+	interval := time.Duration(p.config.IntervalSeconds) * time.Second
+	timeFieldIndex, err := p.timeFieldIndex(frame)
+	if err != nil {
+		return nil, err
+	}
+	fieldIndexes := make([]int, len(p.config.Fields))
+	for i, aggField := range p.config.Fields {
+		fieldIndexes[i] = -1
+		for j, f := range frame.Fields {
+			if f.Name == aggField.FieldName {
+				fieldIndexes[i] = j
+				break
+			}
+		}
+	}
+
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	key := orgchannel.PrependOrgID(vars.OrgID, vars.Channel)
+
+	now := p.nowTimeFunc()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.evictIdle(now)
+
+	var closed []*aggregateWindow
+	for i := 0; i < rowLen; i++ {
+		t := now
+		if timeFieldIndex >= 0 {
+			switch v := frame.Fields[timeFieldIndex].At(i).(type) {
+			case time.Time:
+				t = v
+			case *time.Time:
+				if v == nil {
+					continue
+				}
+				t = *v
+			}
+		}
+		windowStart := t.Truncate(interval)
+
+		window, ok := p.windows[key]
+		if !ok || windowStart.After(window.start) {
+			if ok {
+				closed = append(closed, window)
+			}
+			window = &aggregateWindow{start: windowStart, values: make([]aggregateValue, len(p.config.Fields))}
+			p.windows[key] = window
+		}
+
+		for j, fieldIndex := range fieldIndexes {
+			if fieldIndex < 0 {
+				continue
+			}
+			field := frame.Fields[fieldIndex]
+			val, err := field.FloatAt(i)
+			if err != nil {
+				return nil, fmt.Errorf("can't aggregate field %s: %w", field.Name, err)
+			}
+			if math.IsNaN(val) {
+				continue
+			}
+			window.values[j].labels = field.Labels
+			window.values[j].add(val)
+		}
+	}
+	if window, ok := p.windows[key]; ok {
+		window.lastSeen = now
+	}
+
+	if len(closed) == 0 {
+		return nil, nil
+	}
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, len(closed))
+	timeField.Name = "time"
+	for i, window := range closed {
+		timeField.Set(i, window.start)
+	}
+	fields := []*data.Field{timeField}
+	for j, aggField := range p.config.Fields {
+		field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(closed))
+		field.Name = aggField.FieldName + "_" + string(aggField.Reducer)
+		for i, window := range closed {
+			if window.values[j].labels != nil {
+				field.Labels = window.values[j].labels
+			}
+			field.Set(i, window.values[j].reduce(aggField.Reducer))
+		}
+		fields = append(fields, field)
+	}
+	return data.NewFrame(frame.Name, fields...), nil
+}
+
+// evictIdle drops windows of channels without frames during channelStateTTL.
+func (p *AggregateFrameProcessor) evictIdle(now time.Time) {
+	if now.Sub(p.lastSweep) < channelStateTTL {
+		return
+	}
+	p.lastSweep = now
+	for key, window := range p.windows {
+		if now.Sub(window.lastSeen) >= channelStateTTL {
+			delete(p.windows, key)
+		}
+	}
+}
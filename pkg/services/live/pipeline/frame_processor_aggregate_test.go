@@ -0,0 +1,120 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateFrameProcessor(t *testing.T) {
+	processor := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalSeconds: 10,
+		Fields: []AggregateField{
+			{FieldName: "value", Reducer: AggregateReducerAvg},
+			{FieldName: "value", Reducer: AggregateReducerMin},
+			{FieldName: "value", Reducer: AggregateReducerMax},
+			{FieldName: "value", Reducer: AggregateReducerCount},
+		},
+	})
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+	start := time.Unix(1000, 0)
+
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start, start.Add(3 * time.Second), start.Add(5 * time.Second)}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{float64Ptr(1), nil, float64Ptr(5)}),
+	)
+	result, err := processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.Nil(t, result, "frame is held back until the window closes")
+
+	frame = data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start.Add(9 * time.Second), start.Add(12 * time.Second), start.Add(35 * time.Second)}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{float64Ptr(3), float64Ptr(10), float64Ptr(20)}),
+	)
+	result, err = processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Len(t, result.Fields, 5)
+	require.Equal(t, 2, result.Fields[0].Len())
+
+	require.Equal(t, start, result.Fields[0].At(0))
+	require.Equal(t, start.Add(10*time.Second), result.Fields[0].At(1))
+	require.Equal(t, "value_avg", result.Fields[1].Name)
+	require.Equal(t, data.Labels{"host": "a"}, result.Fields[1].Labels)
+	require.Equal(t, 3.0, *result.Fields[1].At(0).(*float64))
+	require.Equal(t, 1.0, *result.Fields[2].At(0).(*float64))
+	require.Equal(t, 5.0, *result.Fields[3].At(0).(*float64))
+	require.Equal(t, 3.0, *result.Fields[4].At(0).(*float64))
+	require.Equal(t, 10.0, *result.Fields[1].At(1).(*float64))
+	require.Equal(t, 1.0, *result.Fields[4].At(1).(*float64))
+
+	// Windows of other channels are independent.
+	result, err = processor.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/other"}, frame)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Fields[0].Len())
+}
+
+func TestAggregateFrameProcessor_NoTimeField(t *testing.T) {
+	processor := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalSeconds: 1,
+		Fields:          []AggregateField{{FieldName: "value", Reducer: AggregateReducerMax}},
+	})
+	now := time.Unix(1000, 0)
+	processor.nowTimeFunc = func() time.Time { return now }
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1, 2}))
+
+	result, err := processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	now = now.Add(time.Second)
+	result, err = processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, *result.Fields[1].At(0).(*float64))
+}
+
+func TestAggregateFrameProcessor_EvictIdle(t *testing.T) {
+	processor := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalSeconds: 1,
+		Fields:          []AggregateField{{FieldName: "value", Reducer: AggregateReducerMax}},
+	})
+	now := time.Unix(1000, 0)
+	processor.nowTimeFunc = func() time.Time { return now }
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+
+	_, err := processor.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/idle"}, frame)
+	require.NoError(t, err)
+	require.Len(t, processor.windows, 1)
+
+	now = now.Add(channelStateTTL)
+	_, err = processor.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/active"}, frame)
+	require.NoError(t, err)
+	require.Len(t, processor.windows, 1)
+	require.Contains(t, processor.windows, "1/stream/test/active")
+}
+
+func TestAggregateFrameProcessor_InvalidConfig(t *testing.T) {
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+
+	_, err := NewAggregateFrameProcessor(AggregateFrameProcessorConfig{}).ProcessFrame(context.Background(), Vars{}, frame)
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalSeconds: 1,
+		Fields:          []AggregateField{{FieldName: "value", Reducer: "median"}},
+	}).ProcessFrame(context.Background(), Vars{}, frame)
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(AggregateFrameProcessorConfig{
+		IntervalSeconds: 1,
+		TimeField:       "value",
+	}).ProcessFrame(context.Background(), Vars{}, frame)
+	require.Error(t, err)
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
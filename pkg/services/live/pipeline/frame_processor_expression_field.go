@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ExpressionFieldFrameProcessor can add a field to a data.Frame with values
+// computed by a JavaScript expression for every frame row.
+type ExpressionFieldFrameProcessor struct {
+	config ExpressionFieldFrameProcessorConfig
+}
+
+func NewExpressionFieldFrameProcessor(config ExpressionFieldFrameProcessorConfig) *ExpressionFieldFrameProcessor {
+	return &ExpressionFieldFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeExpressionField = "expressionField"
+
+func (p *ExpressionFieldFrameProcessor) Type() string {
+	return FrameProcessorTypeExpressionField
+}
+
+func (p *ExpressionFieldFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	switch p.config.Type {
+	case data.FieldTypeNullableFloat64, data.FieldTypeNullableBool, data.FieldTypeNullableString:
+	default:
+		return nil, fmt.Errorf("unsupported field type: %s (%s)", p.config.Type, p.config.Name)
+	}
+
+	rowLen, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	field := data.NewFieldFromFieldType(p.config.Type, rowLen)
+	field.Name = p.config.Name
+
+	r := newRuntime()
+	for i := 0; i < rowLen; i++ {
+		if err := r.setVar("x", frameRow(frame, i)); err != nil {
+			return nil, err
+		}
+		switch p.config.Type {
+		case data.FieldTypeNullableFloat64:
+			v, err := r.getFloat64(p.config.Expression)
+			if err != nil {
+				return nil, err
+			}
+			field.SetConcrete(i, v)
+		case data.FieldTypeNullableBool:
+			v, err := r.getBool(p.config.Expression)
+			if err != nil {
+				return nil, err
+			}
+			field.SetConcrete(i, v)
+		case data.FieldTypeNullableString:
+			v, err := r.getString(p.config.Expression)
+			if err != nil {
+				return nil, err
+			}
+			field.SetConcrete(i, v)
+		}
+	}
+
+	for i, f := range frame.Fields {
+		if f.Name == field.Name {
+			frame.Fields[i] = field
+			return frame, nil
+		}
+	}
+	frame.Fields = append(frame.Fields, field)
+	return frame, nil
+}
+
+// frameRow returns values of a frame row by field names. Time values are
+// converted to Unix milliseconds, as JavaScript Date.now() does.
+func frameRow(frame *data.Frame, rowIdx int) map[string]interface{} {
+	row := make(map[string]interface{}, len(frame.Fields))
+	for _, f := range frame.Fields {
+		v := f.At(rowIdx)
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				row[f.Name] = nil
+				continue
+			}
+			v = rv.Elem().Interface()
+		}
+		if t, ok := v.(time.Time); ok {
+			v = t.UnixNano() / int64(time.Millisecond)
+		}
+		row[f.Name] = v
+	}
+	return row
+}
@@ -0,0 +1,59 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressionFieldFrameProcessor(t *testing.T) {
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+		data.NewField("celsius", nil, []*float64{float64Ptr(100), nil}),
+		data.NewField("host", nil, []string{"a", "b"}),
+	)
+
+	processor := NewExpressionFieldFrameProcessor(ExpressionFieldFrameProcessorConfig{
+		Name:       "fahrenheit",
+		Type:       data.FieldTypeNullableFloat64,
+		Expression: "x.celsius === null ? -1 : x.celsius * 1.8 + 32",
+	})
+	result, err := processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, result.Fields, 4)
+	require.Equal(t, "fahrenheit", result.Fields[3].Name)
+	require.Equal(t, 212.0, *result.Fields[3].At(0).(*float64))
+	require.Equal(t, -1.0, *result.Fields[3].At(1).(*float64))
+
+	processor = NewExpressionFieldFrameProcessor(ExpressionFieldFrameProcessorConfig{
+		Name:       "host",
+		Type:       data.FieldTypeNullableString,
+		Expression: "x.host + '-' + x.time",
+	})
+	result, err = processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, result.Fields, 4, "existing field is replaced")
+	require.Equal(t, "a-1000", *result.Fields[2].At(0).(*string))
+	require.Equal(t, "b-2000", *result.Fields[2].At(1).(*string))
+}
+
+func TestExpressionFieldFrameProcessor_Errors(t *testing.T) {
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+
+	_, err := NewExpressionFieldFrameProcessor(ExpressionFieldFrameProcessorConfig{
+		Name:       "time",
+		Type:       data.FieldTypeTime,
+		Expression: "x.value",
+	}).ProcessFrame(context.Background(), Vars{}, frame)
+	require.Error(t, err)
+
+	_, err = NewExpressionFieldFrameProcessor(ExpressionFieldFrameProcessorConfig{
+		Name:       "flag",
+		Type:       data.FieldTypeNullableBool,
+		Expression: "x.value",
+	}).ProcessFrame(context.Background(), Vars{}, frame)
+	require.Error(t, err)
+}
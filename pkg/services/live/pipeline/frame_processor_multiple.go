@@ -26,6 +26,10 @@ func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, fr
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			// Frame was dropped by a processor, nothing left to process.
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
@@ -0,0 +1,30 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipleFrameProcessor_DroppedFrame(t *testing.T) {
+	rateLimit := NewRateLimitFrameProcessor(RateLimitFrameProcessorConfig{IntervalMilliseconds: 1000})
+	now := time.Unix(1000, 0)
+	rateLimit.nowTimeFunc = func() time.Time { return now }
+	processor := NewMultipleFrameProcessor(
+		rateLimit,
+		NewRenameFieldsFrameProcessor(RenameFieldsFrameProcessorConfig{Names: map[string]string{"value": "renamed"}}),
+	)
+	vars := Vars{OrgID: 1, Channel: "stream/test/multiple"}
+
+	result, err := processor.ProcessFrame(context.Background(), vars, data.NewFrame("test", data.NewField("value", nil, []float64{1})))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t, "renamed", result.Fields[0].Name)
+
+	result, err = processor.ProcessFrame(context.Background(), vars, data.NewFrame("test", data.NewField("value", nil, []float64{2})))
+	require.NoError(t, err)
+	require.Nil(t, result, "frame dropped by rate limit must not reach next processors")
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// RateLimitFrameProcessor passes at most one frame per configured interval
+// for each channel, other frames are dropped. Not usable in HA setup: each
+// instance applies the limit to frames it processes.
+type RateLimitFrameProcessor struct {
+	config      RateLimitFrameProcessorConfig
+	nowTimeFunc func() time.Time
+
+	mu        sync.Mutex
+	passed    map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewRateLimitFrameProcessor(config RateLimitFrameProcessorConfig) *RateLimitFrameProcessor {
+	return &RateLimitFrameProcessor{
+		config:      config,
+		nowTimeFunc: time.Now,
+		passed:      map[string]time.Time{},
+	}
+}
+
+const FrameProcessorTypeRateLimit = "rateLimit"
+
+func (p *RateLimitFrameProcessor) Type() string {
+	return FrameProcessorTypeRateLimit
+}
+
+func (p *RateLimitFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	key := orgchannel.PrependOrgID(vars.OrgID, vars.Channel)
+	now := p.nowTimeFunc()
+	interval := time.Duration(p.config.IntervalMilliseconds) * time.Millisecond
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.evictIdle(now, interval)
+	if last, ok := p.passed[key]; ok && now.Sub(last) < interval {
+		return nil, nil
+	}
+	p.passed[key] = now
+	return frame, nil
+}
+
+// evictIdle drops channels which passed their last frame long ago. Channels
+// are kept at least for the limit interval so that eviction does not let a
+// frame through too early.
+func (p *RateLimitFrameProcessor) evictIdle(now time.Time, interval time.Duration) {
+	if now.Sub(p.lastSweep) < channelStateTTL {
+		return
+	}
+	p.lastSweep = now
+	ttl := channelStateTTL
+	if interval > ttl {
+		ttl = interval
+	}
+	for key, last := range p.passed {
+		if now.Sub(last) >= ttl {
+			delete(p.passed, key)
+		}
+	}
+}
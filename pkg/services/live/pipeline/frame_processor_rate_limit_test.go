@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitFrameProcessor(t *testing.T) {
+	processor := NewRateLimitFrameProcessor(RateLimitFrameProcessorConfig{IntervalMilliseconds: 1000})
+	now := time.Unix(1000, 0)
+	processor.nowTimeFunc = func() time.Time { return now }
+	frame := data.NewFrame("test")
+	vars := Vars{OrgID: 1, Channel: "stream/test/rate"}
+
+	result, err := processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	now = now.Add(500 * time.Millisecond)
+	result, err = processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	result, err = processor.ProcessFrame(context.Background(), Vars{OrgID: 2, Channel: "stream/test/rate"}, frame)
+	require.NoError(t, err)
+	require.NotNil(t, result, "channels of other organizations are limited separately")
+
+	now = now.Add(500 * time.Millisecond)
+	result, err = processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+}
+
+func TestRateLimitFrameProcessor_EvictIdle(t *testing.T) {
+	processor := NewRateLimitFrameProcessor(RateLimitFrameProcessorConfig{IntervalMilliseconds: 1000})
+	now := time.Unix(1000, 0)
+	processor.nowTimeFunc = func() time.Time { return now }
+	frame := data.NewFrame("test")
+
+	_, err := processor.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/idle"}, frame)
+	require.NoError(t, err)
+	require.Len(t, processor.passed, 1)
+
+	now = now.Add(channelStateTTL)
+	_, err = processor.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/active"}, frame)
+	require.NoError(t, err)
+	require.Len(t, processor.passed, 1)
+	require.Contains(t, processor.passed, "1/stream/test/active")
+}
+
+func TestSampleFrameProcessor(t *testing.T) {
+	processor := NewSampleFrameProcessor(SampleFrameProcessorConfig{Every: 3})
+	frame := data.NewFrame("test")
+	vars := Vars{OrgID: 1, Channel: "stream/test/sample"}
+
+	var passed int
+	for i := 0; i < 7; i++ {
+		result, err := processor.ProcessFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		if result != nil {
+			passed++
+		}
+	}
+	require.Equal(t, 3, passed)
+}
+
+func TestSampleFrameProcessor_EvictIdle(t *testing.T) {
+	processor := NewSampleFrameProcessor(SampleFrameProcessorConfig{Every: 3})
+	now := time.Unix(1000, 0)
+	processor.nowTimeFunc = func() time.Time { return now }
+	frame := data.NewFrame("test")
+
+	_, err := processor.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/idle"}, frame)
+	require.NoError(t, err)
+	require.Len(t, processor.counters, 1)
+
+	now = now.Add(channelStateTTL)
+	_, err = processor.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/active"}, frame)
+	require.NoError(t, err)
+	require.Len(t, processor.counters, 1)
+	require.Contains(t, processor.counters, "1/stream/test/active")
+}
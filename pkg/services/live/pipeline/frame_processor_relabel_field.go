@@ -0,0 +1,50 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// RelabelFieldsFrameProcessor can set and remove labels of data.Frame fields.
+type RelabelFieldsFrameProcessor struct {
+	config RelabelFieldsFrameProcessorConfig
+}
+
+func NewRelabelFieldsFrameProcessor(config RelabelFieldsFrameProcessorConfig) *RelabelFieldsFrameProcessor {
+	return &RelabelFieldsFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeRelabelFields = "relabelFields"
+
+func (p *RelabelFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeRelabelFields
+}
+
+func (p *RelabelFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, field := range frame.Fields {
+		if len(p.config.FieldNames) > 0 {
+			if !stringInSlice(field.Name, p.config.FieldNames) {
+				continue
+			}
+		} else if field.Type().Time() {
+			continue
+		}
+		// Labels may be shared between frames so always work on a copy.
+		labels := data.Labels{}
+		for k, v := range field.Labels {
+			labels[k] = v
+		}
+		for _, name := range p.config.DropLabels {
+			delete(labels, name)
+		}
+		for _, label := range p.config.Labels {
+			labels[label.Name] = label.Value
+		}
+		if len(labels) == 0 {
+			labels = nil
+		}
+		field.Labels = labels
+	}
+	return frame, nil
+}
@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelabelFieldsFrameProcessor(t *testing.T) {
+	labels := data.Labels{"host": "a", "dc": "eu"}
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("cpu", labels, []float64{1}),
+		data.NewField("mem", nil, []float64{2}),
+	)
+
+	processor := NewRelabelFieldsFrameProcessor(RelabelFieldsFrameProcessorConfig{
+		Labels:     []Label{{Name: "env", Value: "prod"}},
+		DropLabels: []string{"dc"},
+	})
+	result, err := processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Nil(t, result.Fields[0].Labels)
+	require.Equal(t, data.Labels{"host": "a", "env": "prod"}, result.Fields[1].Labels)
+	require.Equal(t, data.Labels{"env": "prod"}, result.Fields[2].Labels)
+	require.Equal(t, data.Labels{"host": "a", "dc": "eu"}, labels, "original labels are not modified")
+
+	processor = NewRelabelFieldsFrameProcessor(RelabelFieldsFrameProcessorConfig{
+		FieldNames: []string{"mem"},
+		DropLabels: []string{"env"},
+	})
+	result, err = processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Equal(t, data.Labels{"host": "a", "env": "prod"}, result.Fields[1].Labels)
+	require.Nil(t, result.Fields[2].Labels)
+}
+
+func TestRenameFieldsFrameProcessor(t *testing.T) {
+	frame := data.NewFrame("test",
+		data.NewField("cpu", nil, []float64{1}),
+		data.NewField("mem", nil, []float64{2}),
+	)
+	processor := NewRenameFieldsFrameProcessor(RenameFieldsFrameProcessorConfig{
+		Names: map[string]string{"cpu": "usage_cpu"},
+	})
+	result, err := processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Equal(t, "usage_cpu", result.Fields[0].Name)
+	require.Equal(t, "mem", result.Fields[1].Name)
+}
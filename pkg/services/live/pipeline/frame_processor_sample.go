@@ -0,0 +1,77 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// SampleFrameProcessor passes every Nth frame of a channel, other frames are dropped.
+type SampleFrameProcessor struct {
+	config      SampleFrameProcessorConfig
+	nowTimeFunc func() time.Time
+
+	mu        sync.Mutex
+	counters  map[string]*sampleCounter
+	lastSweep time.Time
+}
+
+type sampleCounter struct {
+	n        int64
+	lastSeen time.Time
+}
+
+func NewSampleFrameProcessor(config SampleFrameProcessorConfig) *SampleFrameProcessor {
+	return &SampleFrameProcessor{
+		config:      config,
+		nowTimeFunc: time.Now,
+		counters:    map[string]*sampleCounter{},
+	}
+}
+
+const FrameProcessorTypeSample = "sample"
+
+func (p *SampleFrameProcessor) Type() string {
+	return FrameProcessorTypeSample
+}
+
+func (p *SampleFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	if p.config.Every <= 1 {
+		return frame, nil
+	}
+	key := orgchannel.PrependOrgID(vars.OrgID, vars.Channel)
+	now := p.nowTimeFunc()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.evictIdle(now)
+	counter, ok := p.counters[key]
+	if !ok {
+		counter = &sampleCounter{}
+		p.counters[key] = counter
+	}
+	n := counter.n
+	counter.n = (n + 1) % p.config.Every
+	counter.lastSeen = now
+	if n != 0 {
+		return nil, nil
+	}
+	return frame, nil
+}
+
+// evictIdle drops counters of channels without frames during channelStateTTL.
+func (p *SampleFrameProcessor) evictIdle(now time.Time) {
+	if now.Sub(p.lastSweep) < channelStateTTL {
+		return
+	}
+	p.lastSweep = now
+	for key, counter := range p.counters {
+		if now.Sub(counter.lastSeen) >= channelStateTTL {
+			delete(p.counters, key)
+		}
+	}
+}
@@ -9,11 +9,15 @@ import (
 	"github.com/dop251/goja/parser"
 )
 
-func getRuntime(payload []byte) (*gojaRuntime, error) {
+func newRuntime() *gojaRuntime {
 	vm := goja.New()
 	vm.SetMaxCallStackSize(64)
 	vm.SetParserOptions(parser.WithDisableSourceMaps)
-	r := &gojaRuntime{vm}
+	return &gojaRuntime{vm}
+}
+
+func getRuntime(payload []byte) (*gojaRuntime, error) {
+	r := newRuntime()
 	err := r.init(payload)
 	if err != nil {
 		return nil, err
@@ -35,6 +39,11 @@ func (r *gojaRuntime) init(payload []byte) error {
 	return err
 }
 
+// setVar makes value available to scripts under the given name.
+func (r *gojaRuntime) setVar(name string, value interface{}) error {
+	return r.vm.Set(name, value)
+}
+
 func (r *gojaRuntime) runString(script string) (goja.Value, error) {
 	doneCh := make(chan struct{})
 	go func() {
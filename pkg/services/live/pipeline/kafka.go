@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// NewKafkaConfig returns the configuration of Kafka clients made according to Grafana settings.
+func NewKafkaConfig(cfg setting.LiveKafkaSettings) (*sarama.Config, error) {
+	version, err := sarama.ParseKafkaVersion(cfg.Version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kafka version: %w", err)
+	}
+	config := sarama.NewConfig()
+	config.Version = version
+	config.ClientID = cfg.ClientID
+	if cfg.Username != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		config.Net.SASL.User = cfg.Username
+		config.Net.SASL.Password = cfg.Password
+	}
+	if cfg.TLS {
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	config.Consumer.Offsets.Initial = sarama.OffsetNewest
+	if cfg.InitialOffset == "oldest" {
+		config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
+	return config, nil
+}
+
+// KafkaPublisher publishes messages to Kafka topics. It connects to the brokers
+// on the first message, so that Grafana starts when Kafka is not reachable.
+type KafkaPublisher struct {
+	newProducer func() (sarama.AsyncProducer, error)
+
+	mu          sync.Mutex
+	producer    sarama.AsyncProducer
+	lastAttempt time.Time
+}
+
+func NewKafkaPublisher(brokers []string, config *sarama.Config) *KafkaPublisher {
+	return &KafkaPublisher{
+		newProducer: func() (sarama.AsyncProducer, error) {
+			return sarama.NewAsyncProducer(brokers, config)
+		},
+	}
+}
+
+func (p *KafkaPublisher) getProducer() (sarama.AsyncProducer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.producer != nil {
+		return p.producer, nil
+	}
+	if time.Since(p.lastAttempt) < publisherRetryInterval {
+		return nil, errPublisherNotConnected
+	}
+	p.lastAttempt = time.Now()
+	producer, err := p.newProducer()
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to Kafka: %w", err)
+	}
+	go func() {
+		for err := range producer.Errors() {
+			logger.Error("Error publishing to Kafka", "topic", err.Msg.Topic, "error", err.Err)
+		}
+	}()
+	p.producer = producer
+	return producer, nil
+}
+
+func (p *KafkaPublisher) Publish(topic string, key string, value []byte) error {
+	producer, err := p.getProducer()
+	if err != nil {
+		return err
+	}
+	// Sending is asynchronous, delivery errors are logged.
+	producer.Input() <- &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(value),
+	}
+	return nil
+}
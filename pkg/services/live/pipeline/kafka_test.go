@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNewKafkaConfig(t *testing.T) {
+	config, err := NewKafkaConfig(setting.LiveKafkaSettings{
+		Version:       "2.1.0",
+		ClientID:      "grafana",
+		Username:      "grafana",
+		Password:      "secret",
+		TLS:           true,
+		InitialOffset: "oldest",
+	})
+	require.NoError(t, err)
+	require.Equal(t, sarama.V2_1_0_0, config.Version)
+	require.True(t, config.Net.SASL.Enable)
+	require.True(t, config.Net.TLS.Enable)
+	require.Equal(t, sarama.OffsetOldest, config.Consumer.Offsets.Initial)
+	require.NoError(t, config.Validate())
+
+	_, err = NewKafkaConfig(setting.LiveKafkaSettings{Version: "latest"})
+	require.Error(t, err)
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	producer := mocks.NewAsyncProducer(t, nil)
+	producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		key, err := msg.Key.Encode()
+		if err != nil {
+			return err
+		}
+		if msg.Topic != "grafana-live" || string(key) != "stream/test/kafka" {
+			return errors.New("unexpected message")
+		}
+		return nil
+	})
+	producer.ExpectInputWithCheckerFunctionAndSucceed(func(val []byte) error {
+		if string(val) != `{"value": 1}` {
+			return errors.New("unexpected value")
+		}
+		return nil
+	})
+
+	publisher := NewKafkaPublisher(nil, nil)
+	publisher.newProducer = func() (sarama.AsyncProducer, error) {
+		return producer, nil
+	}
+	vars := Vars{OrgID: 1, Channel: "stream/test/kafka"}
+	config := KafkaOutputConfig{Topic: "grafana-live"}
+
+	frame := data.NewFrame("test", data.NewField("time", nil, []time.Time{time.Unix(1, 0)}))
+	_, err := NewKafkaFrameOutput(publisher, config).OutputFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	_, err = NewKafkaDataOutput(publisher, config).OutputData(context.Background(), vars, []byte(`{"value": 1}`))
+	require.NoError(t, err)
+
+	require.NoError(t, producer.Close())
+}
+
+func TestKafkaPublisher_RetryInterval(t *testing.T) {
+	attempts := 0
+	publisher := NewKafkaPublisher(nil, nil)
+	publisher.newProducer = func() (sarama.AsyncProducer, error) {
+		attempts++
+		return nil, sarama.ErrOutOfBrokers
+	}
+
+	require.ErrorIs(t, publisher.Publish("grafana-live", "", nil), sarama.ErrOutOfBrokers)
+	require.ErrorIs(t, publisher.Publish("grafana-live", "", nil), errPublisherNotConnected)
+	require.Equal(t, 1, attempts)
+}
+
+func TestStorageRuleBuilder_KafkaNotEnabled(t *testing.T) {
+	builder := &StorageRuleBuilder{}
+	_, err := builder.extractFrameOutputter(&FrameOutputterConfig{
+		Type:              FrameOutputTypeKafka,
+		KafkaOutputConfig: &KafkaOutputConfig{Topic: "grafana-live"},
+	}, nil)
+	require.ErrorIs(t, err, errKafkaNotEnabled)
+
+	builder.KafkaPublisher = NewKafkaPublisher(nil, nil)
+	builder.KafkaTopicPrefixes = []string{"grafana-"}
+	out, err := builder.extractFrameOutputter(&FrameOutputterConfig{
+		Type:              FrameOutputTypeKafka,
+		KafkaOutputConfig: &KafkaOutputConfig{Topic: "grafana-live"},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, FrameOutputTypeKafka, out.Type())
+}
+
+func TestStorageRuleBuilder_KafkaTopicNotAllowed(t *testing.T) {
+	builder := &StorageRuleBuilder{KafkaPublisher: NewKafkaPublisher(nil, nil)}
+	_, err := builder.extractFrameOutputter(&FrameOutputterConfig{
+		Type:              FrameOutputTypeKafka,
+		KafkaOutputConfig: &KafkaOutputConfig{Topic: "grafana-live"},
+	}, nil)
+	require.Error(t, err, "no topic is allowed without prefixes")
+
+	builder.KafkaTopicPrefixes = []string{"grafana-"}
+	_, err = builder.extractDataOutputter(&DataOutputterConfig{
+		Type:              DataOutputTypeKafka,
+		KafkaOutputConfig: &KafkaOutputConfig{Topic: "billing"},
+	}, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "output_topic_prefixes")
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// NewNATSOptions returns the options of NATS connections made according to Grafana settings.
+func NewNATSOptions(cfg setting.LiveNATSSettings) []nats.Option {
+	opts := []nats.Option{
+		nats.Name(cfg.Name),
+		// Reconnect until the connection is closed.
+		nats.MaxReconnects(-1),
+	}
+	if cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+	return opts
+}
+
+// NATSPublisher publishes messages to NATS subjects. It connects to the server
+// on the first message, so that Grafana starts when NATS is not reachable.
+// NATS messages have no keys, so the key of a message is ignored.
+type NATSPublisher struct {
+	url  string
+	opts []nats.Option
+
+	mu          sync.Mutex
+	conn        *nats.Conn
+	lastAttempt time.Time
+}
+
+func NewNATSPublisher(url string, opts ...nats.Option) *NATSPublisher {
+	return &NATSPublisher{url: url, opts: opts}
+}
+
+func (p *NATSPublisher) getConn() (*nats.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn != nil {
+		return p.conn, nil
+	}
+	if time.Since(p.lastAttempt) < publisherRetryInterval {
+		return nil, errPublisherNotConnected
+	}
+	p.lastAttempt = time.Now()
+	conn, err := nats.Connect(p.url, p.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to NATS: %w", err)
+	}
+	p.conn = conn
+	return conn, nil
+}
+
+func (p *NATSPublisher) Publish(subject string, _ string, value []byte) error {
+	conn, err := p.getConn()
+	if err != nil {
+		return err
+	}
+	// Messages are buffered by the connection while reconnecting.
+	return conn.Publish(subject, value)
+}
@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNATSPublisher(t *testing.T) {
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true})
+	require.NoError(t, err)
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second), "NATS server is not ready")
+
+	conn, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+	sub, err := conn.SubscribeSync("grafana.live")
+	require.NoError(t, err)
+	require.NoError(t, conn.Flush())
+
+	publisher := NewNATSPublisher(srv.ClientURL())
+	vars := Vars{OrgID: 1, Channel: "stream/test/nats"}
+	config := NATSOutputConfig{Subject: "grafana.live"}
+
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1}))
+	_, err = NewNATSFrameOutput(publisher, config).OutputFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	_, err = NewNATSDataOutput(publisher, config).OutputData(context.Background(), vars, []byte(`{"value": 1}`))
+	require.NoError(t, err)
+
+	msg, err := sub.NextMsg(5 * time.Second)
+	require.NoError(t, err)
+	decoded := &data.Frame{}
+	require.NoError(t, decoded.UnmarshalJSON(msg.Data))
+	require.Equal(t, "test", decoded.Name)
+
+	msg, err = sub.NextMsg(5 * time.Second)
+	require.NoError(t, err)
+	require.Equal(t, `{"value": 1}`, string(msg.Data))
+}
+
+func TestNATSPublisher_NotReachable(t *testing.T) {
+	publisher := NewNATSPublisher("nats://127.0.0.1:1", nats.Timeout(100*time.Millisecond))
+	require.Error(t, publisher.Publish("grafana.live", "", nil))
+	require.ErrorIs(t, publisher.Publish("grafana.live", "", nil), errPublisherNotConnected)
+}
+
+func TestStorageRuleBuilder_NATSSubjectNotAllowed(t *testing.T) {
+	builder := &StorageRuleBuilder{}
+	_, err := builder.extractFrameOutputter(&FrameOutputterConfig{
+		Type:             FrameOutputTypeNATS,
+		NATSOutputConfig: &NATSOutputConfig{Subject: "grafana.live"},
+	}, nil)
+	require.ErrorIs(t, err, errNATSNotEnabled)
+
+	builder.NATSPublisher = NewNATSPublisher("nats://127.0.0.1:1")
+	builder.NATSSubjectPrefixes = []string{"grafana."}
+	_, err = builder.extractDataOutputter(&DataOutputterConfig{
+		Type:             DataOutputTypeNATS,
+		NATSOutputConfig: &NATSOutputConfig{Subject: "billing.events"},
+	}, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "output_subject_prefixes")
+
+	out, err := builder.extractFrameOutputter(&FrameOutputterConfig{
+		Type:             FrameOutputTypeNATS,
+		NATSOutputConfig: &NATSOutputConfig{Subject: "grafana.live"},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, FrameOutputTypeNATS, out.Type())
+}
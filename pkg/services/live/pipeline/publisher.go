@@ -0,0 +1,19 @@
+package pipeline
+
+import (
+	"errors"
+	"time"
+)
+
+// publisherRetryInterval is the time to wait before connecting to an event bus
+// again after a failure, messages published meanwhile are rejected.
+const publisherRetryInterval = 5 * time.Second
+
+var errPublisherNotConnected = errors.New("not connected, waiting before the next attempt")
+
+// MessagePublisher publishes messages of Live channels to topics of an event bus like Kafka or NATS.
+type MessagePublisher interface {
+	// Publish sends value to the topic. Key is used to keep the order of the messages with
+	// the same key where the event bus supports it, outputs pass the Live channel as a key.
+	Publish(topic string, key string, value []byte) error
+}
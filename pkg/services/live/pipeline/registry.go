@@ -1,5 +1,9 @@
 package pipeline
 
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
 type EntityInfo struct {
 	Type        string      `json:"type"`
 	Description string      `json:"description"`
@@ -48,6 +52,16 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeKafka,
+		Description: "output frame as JSON to Kafka topic",
+		Example:     KafkaOutputConfig{Topic: "grafana-live"},
+	},
+	{
+		Type:        FrameOutputTypeNATS,
+		Description: "output frame as JSON to NATS subject",
+		Example:     NATSOutputConfig{Subject: "grafana.live"},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -83,6 +97,45 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeRenameFields,
+		Description: "rename fields",
+		Example:     RenameFieldsFrameProcessorConfig{},
+	},
+	{
+		Type:        FrameProcessorTypeRelabelFields,
+		Description: "set or remove field labels",
+		Example:     RelabelFieldsFrameProcessorConfig{},
+	},
+	{
+		Type:        FrameProcessorTypeExpressionField,
+		Description: "add a field computed by JavaScript expression for every row",
+		Example: ExpressionFieldFrameProcessorConfig{
+			Name:       "fahrenheit",
+			Type:       data.FieldTypeNullableFloat64,
+			Expression: "x.celsius * 1.8 + 32",
+		},
+	},
+	{
+		Type:        FrameProcessorTypeAggregate,
+		Description: "aggregate field values over tumbling time windows",
+		Example: AggregateFrameProcessorConfig{
+			IntervalSeconds: 10,
+			Fields: []AggregateField{
+				{FieldName: "value", Reducer: AggregateReducerAvg},
+			},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeRateLimit,
+		Description: "pass at most one frame per interval",
+		Example:     RateLimitFrameProcessorConfig{IntervalMilliseconds: 1000},
+	},
+	{
+		Type:        FrameProcessorTypeSample,
+		Description: "pass every Nth frame",
+		Example:     SampleFrameProcessorConfig{Every: 10},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
@@ -98,4 +151,14 @@ var DataOutputsRegistry = []EntityInfo{
 		Type:        DataOutputTypeLoki,
 		Description: "output data to Loki as logs",
 	},
+	{
+		Type:        DataOutputTypeKafka,
+		Description: "output data to Kafka topic",
+		Example:     KafkaOutputConfig{Topic: "grafana-live"},
+	},
+	{
+		Type:        DataOutputTypeNATS,
+		Description: "output data to NATS subject",
+		Example:     NATSOutputConfig{Subject: "grafana.live"},
+	},
 }
@@ -2,7 +2,9 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/centrifugal/centrifuge"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
@@ -16,8 +18,21 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	// KafkaPublisher is used by Kafka outputs, they can't be used if it is not set.
+	KafkaPublisher MessagePublisher
+	// KafkaTopicPrefixes are the prefixes of the topics Kafka outputs can publish to.
+	KafkaTopicPrefixes []string
+	// NATSPublisher is used by NATS outputs, they can't be used if it is not set.
+	NATSPublisher MessagePublisher
+	// NATSSubjectPrefixes are the prefixes of the subjects NATS outputs can publish to.
+	NATSSubjectPrefixes []string
 }
 
+var (
+	errKafkaNotEnabled = errors.New("kafka is not enabled in [live.kafka] settings")
+	errNATSNotEnabled  = errors.New("nats is not enabled in [live.nats] settings")
+)
+
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
 	if config == nil {
 		return nil, nil
@@ -108,6 +123,36 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeRenameFields:
+		if config.RenameFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRenameFieldsFrameProcessor(*config.RenameFieldsProcessorConfig), nil
+	case FrameProcessorTypeRelabelFields:
+		if config.RelabelFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRelabelFieldsFrameProcessor(*config.RelabelFieldsProcessorConfig), nil
+	case FrameProcessorTypeExpressionField:
+		if config.ExpressionFieldProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewExpressionFieldFrameProcessor(*config.ExpressionFieldProcessorConfig), nil
+	case FrameProcessorTypeAggregate:
+		if config.AggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAggregateFrameProcessor(*config.AggregateProcessorConfig), nil
+	case FrameProcessorTypeRateLimit:
+		if config.RateLimitProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRateLimitFrameProcessor(*config.RateLimitProcessorConfig), nil
+	case FrameProcessorTypeSample:
+		if config.SampleProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewSampleFrameProcessor(*config.SampleProcessorConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -253,6 +298,22 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeKafka:
+		if config.KafkaOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		if err := f.checkKafkaTopic(config.KafkaOutputConfig.Topic); err != nil {
+			return nil, err
+		}
+		return NewKafkaFrameOutput(f.KafkaPublisher, *config.KafkaOutputConfig), nil
+	case FrameOutputTypeNATS:
+		if config.NATSOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		if err := f.checkNATSSubject(config.NATSOutputConfig.Subject); err != nil {
+			return nil, err
+		}
+		return NewNATSFrameOutput(f.NATSPublisher, *config.NATSOutputConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
@@ -289,11 +350,61 @@ func (f *StorageRuleBuilder) extractDataOutputter(config *DataOutputterConfig, w
 		return NewBuiltinDataOutput(f.ChannelHandlerGetter), nil
 	case DataOutputTypeLocalSubscribers:
 		return NewLocalSubscribersDataOutput(f.Node), nil
+	case DataOutputTypeKafka:
+		if config.KafkaOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		if err := f.checkKafkaTopic(config.KafkaOutputConfig.Topic); err != nil {
+			return nil, err
+		}
+		return NewKafkaDataOutput(f.KafkaPublisher, *config.KafkaOutputConfig), nil
+	case DataOutputTypeNATS:
+		if config.NATSOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		if err := f.checkNATSSubject(config.NATSOutputConfig.Subject); err != nil {
+			return nil, err
+		}
+		return NewNATSDataOutput(f.NATSPublisher, *config.NATSOutputConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown data output type: %s", config.Type)
 	}
 }
 
+// checkKafkaTopic checks that Kafka is enabled, and that outputs can publish to the topic.
+func (f *StorageRuleBuilder) checkKafkaTopic(topic string) error {
+	if f.KafkaPublisher == nil {
+		return errKafkaNotEnabled
+	}
+	if !hasAnyPrefix(topic, f.KafkaTopicPrefixes) {
+		return fmt.Errorf("kafka topic %q is not allowed by [live.kafka] output_topic_prefixes", topic)
+	}
+	return nil
+}
+
+// checkNATSSubject checks that NATS is enabled, and that outputs can publish to the subject.
+func (f *StorageRuleBuilder) checkNATSSubject(subject string) error {
+	if f.NATSPublisher == nil {
+		return errNATSNotEnabled
+	}
+	if !hasAnyPrefix(subject, f.NATSSubjectPrefixes) {
+		return fmt.Errorf("nats subject %q is not allowed by [live.nats] output_subject_prefixes", subject)
+	}
+	return nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	if s == "" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *StorageRuleBuilder) getWriteConfig(uid string, writeConfigs []WriteConfig) (WriteConfig, bool) {
 	for _, rwb := range writeConfigs {
 		if rwb.UID == uid {
@@ -59,6 +59,18 @@ func (s *CacheSegmentedTree) fillOrg(orgID int64) error {
 	return nil
 }
 
+// Invalidate rebuilds the channel rules of an organization after they were
+// changed. Organizations without built rules are left to be built on first use.
+func (s *CacheSegmentedTree) Invalidate(orgID int64) error {
+	s.radixMu.RLock()
+	_, ok := s.radix[orgID]
+	s.radixMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return s.fillOrg(orgID)
+}
+
 func (s *CacheSegmentedTree) Get(orgID int64, channel string) (*LiveChannelRule, bool, error) {
 	s.radixMu.RLock()
 	_, ok := s.radix[orgID]
@@ -1,6 +1,16 @@
 package pipeline
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrChannelRuleNotFound is returned when a channel rule with the pattern does not exist in the organization.
+	ErrChannelRuleNotFound = errors.New("rule not found")
+	// ErrWriteConfigNotFound is returned when a write config with the UID does not exist in the organization.
+	ErrWriteConfigNotFound = errors.New("write config not found")
+)
 
 // Storage describes all methods to manage Live pipeline persistent data.
 type Storage interface {
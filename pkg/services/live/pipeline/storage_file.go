@@ -139,7 +139,7 @@ func (f *FileStorage) DeleteWriteConfig(_ context.Context, orgID int64, cmd Writ
 	if index > -1 {
 		writeConfigs.Configs = removeWriteConfigByIndex(writeConfigs.Configs, index)
 	} else {
-		return fmt.Errorf("write config not found")
+		return ErrWriteConfigNotFound
 	}
 
 	return f.saveWriteConfigs(orgID, writeConfigs)
@@ -291,7 +291,7 @@ func (f *FileStorage) DeleteChannelRule(_ context.Context, orgID int64, cmd Chan
 	if index > -1 {
 		channelRules.Rules = removeChannelRuleByIndex(channelRules.Rules, index)
 	} else {
-		return fmt.Errorf("rule not found")
+		return ErrChannelRuleNotFound
 	}
 
 	return f.saveChannelRules(orgID, channelRules)
@@ -0,0 +1,58 @@
+package pipeline
+
+import "context"
+
+// NotifyingStorage wraps a Storage and calls OnChange with the organization of
+// every successful change of channel rules or write configs.
+type NotifyingStorage struct {
+	Storage
+	OnChange func(orgID int64)
+}
+
+func (s *NotifyingStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	writeConfig, err := s.Storage.CreateWriteConfig(ctx, orgID, cmd)
+	if err == nil {
+		s.OnChange(orgID)
+	}
+	return writeConfig, err
+}
+
+func (s *NotifyingStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	writeConfig, err := s.Storage.UpdateWriteConfig(ctx, orgID, cmd)
+	if err == nil {
+		s.OnChange(orgID)
+	}
+	return writeConfig, err
+}
+
+func (s *NotifyingStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	err := s.Storage.DeleteWriteConfig(ctx, orgID, cmd)
+	if err == nil {
+		s.OnChange(orgID)
+	}
+	return err
+}
+
+func (s *NotifyingStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	rule, err := s.Storage.CreateChannelRule(ctx, orgID, cmd)
+	if err == nil {
+		s.OnChange(orgID)
+	}
+	return rule, err
+}
+
+func (s *NotifyingStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	rule, err := s.Storage.UpdateChannelRule(ctx, orgID, cmd)
+	if err == nil {
+		s.OnChange(orgID)
+	}
+	return rule, err
+}
+
+func (s *NotifyingStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	err := s.Storage.DeleteChannelRule(ctx, orgID, cmd)
+	if err == nil {
+		s.OnChange(orgID)
+	}
+	return err
+}
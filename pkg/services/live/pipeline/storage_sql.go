@@ -0,0 +1,336 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// SQLStorage keeps channel rules and write configs in the Grafana database.
+type SQLStorage struct {
+	SQLStore       *sqlstore.SQLStore
+	SecretsService secrets.Service
+}
+
+type channelRuleRecord struct {
+	Id       int64
+	OrgId    int64
+	Pattern  string
+	Settings string
+	Created  time.Time
+	Updated  time.Time
+}
+
+func (r channelRuleRecord) TableName() string {
+	return "live_channel_rule"
+}
+
+func (r channelRuleRecord) toChannelRule() (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:   r.OrgId,
+		Pattern: r.Pattern,
+	}
+	if err := json.Unmarshal([]byte(r.Settings), &rule.Settings); err != nil {
+		return ChannelRule{}, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", r.Pattern, err)
+	}
+	return rule, nil
+}
+
+type writeConfigRecord struct {
+	Id             int64
+	OrgId          int64
+	Uid            string
+	Settings       string
+	SecureSettings string
+	Created        time.Time
+	Updated        time.Time
+}
+
+func (r writeConfigRecord) TableName() string {
+	return "live_write_config"
+}
+
+func (r writeConfigRecord) toWriteConfig() (WriteConfig, error) {
+	writeConfig := WriteConfig{
+		OrgId: r.OrgId,
+		UID:   r.Uid,
+	}
+	if err := json.Unmarshal([]byte(r.Settings), &writeConfig.Settings); err != nil {
+		return WriteConfig{}, fmt.Errorf("can't unmarshal settings of write config %s: %w", r.Uid, err)
+	}
+	if r.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(r.SecureSettings), &writeConfig.SecureSettings); err != nil {
+			return WriteConfig{}, fmt.Errorf("can't unmarshal secure settings of write config %s: %w", r.Uid, err)
+		}
+	}
+	return writeConfig, nil
+}
+
+func (s *SQLStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]WriteConfig, error) {
+	var records []writeConfigRecord
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("org_id = ?", orgID).Asc("uid").Find(&records)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read write configs: %w", err)
+	}
+	writeConfigs := make([]WriteConfig, 0, len(records))
+	for _, r := range records {
+		writeConfig, err := r.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		writeConfigs = append(writeConfigs, writeConfig)
+	}
+	return writeConfigs, nil
+}
+
+func (s *SQLStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigGetCmd) (WriteConfig, bool, error) {
+	var record writeConfigRecord
+	var exists bool
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&record)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, false, fmt.Errorf("can't read write config: %w", err)
+	}
+	if !exists {
+		return WriteConfig{}, false, nil
+	}
+	writeConfig, err := record.toWriteConfig()
+	if err != nil {
+		return WriteConfig{}, false, err
+	}
+	return writeConfig, true, nil
+}
+
+func (s *SQLStorage) newWriteConfigRecord(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string) (writeConfigRecord, WriteConfig, error) {
+	encrypted, err := s.SecretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return writeConfigRecord{}, WriteConfig{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+	writeConfig := WriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       settings,
+		SecureSettings: encrypted,
+	}
+	ok, reason := writeConfig.Valid()
+	if !ok {
+		return writeConfigRecord{}, WriteConfig{}, fmt.Errorf("invalid write config: %s", reason)
+	}
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return writeConfigRecord{}, WriteConfig{}, err
+	}
+	secureSettingsJSON, err := json.Marshal(encrypted)
+	if err != nil {
+		return writeConfigRecord{}, WriteConfig{}, err
+	}
+	now := time.Now()
+	return writeConfigRecord{
+		OrgId:          orgID,
+		Uid:            uid,
+		Settings:       string(settingsJSON),
+		SecureSettings: string(secureSettingsJSON),
+		Created:        now,
+		Updated:        now,
+	}, writeConfig, nil
+}
+
+func (s *SQLStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	record, writeConfig, err := s.newWriteConfigRecord(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Exist(&writeConfigRecord{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("backend already exists in org: %s", cmd.UID)
+		}
+		_, err = sess.Insert(&record)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	return writeConfig, nil
+}
+
+func (s *SQLStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	record, writeConfig, err := s.newWriteConfigRecord(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	var exists bool
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Exist(&writeConfigRecord{})
+		if err != nil || !exists {
+			return err
+		}
+		_, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Cols("settings", "secure_settings", "updated").Update(&record)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	if !exists {
+		return s.CreateWriteConfig(ctx, orgID, WriteConfigCreateCmd(cmd))
+	}
+	return writeConfig, nil
+}
+
+func (s *SQLStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	var deleted int64
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		deleted, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Delete(&writeConfigRecord{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrWriteConfigNotFound
+	}
+	return nil
+}
+
+func (s *SQLStorage) ListChannelRules(ctx context.Context, orgID int64) ([]ChannelRule, error) {
+	var rules []ChannelRule
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		rules, err = listChannelRules(sess, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read channel rules: %w", err)
+	}
+	return rules, nil
+}
+
+func listChannelRules(sess *sqlstore.DBSession, orgID int64) ([]ChannelRule, error) {
+	var records []channelRuleRecord
+	if err := sess.Where("org_id = ?", orgID).Asc("pattern").Find(&records); err != nil {
+		return nil, err
+	}
+	rules := make([]ChannelRule, 0, len(records))
+	for _, r := range records {
+		rule, err := r.toChannelRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func newChannelRuleRecord(orgID int64, pattern string, settings ChannelRuleSettings) (channelRuleRecord, ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  pattern,
+		Settings: settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return channelRuleRecord{}, ChannelRule{}, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return channelRuleRecord{}, ChannelRule{}, err
+	}
+	now := time.Now()
+	return channelRuleRecord{
+		OrgId:    orgID,
+		Pattern:  pattern,
+		Settings: string(settingsJSON),
+		Created:  now,
+		Updated:  now,
+	}, rule, nil
+}
+
+func (s *SQLStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	record, rule, err := newChannelRuleRecord(orgID, cmd.Pattern, cmd.Settings)
+	if err != nil {
+		return ChannelRule{}, err
+	}
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return createChannelRule(sess, record, rule)
+	})
+	if err != nil {
+		return ChannelRule{}, err
+	}
+	return rule, nil
+}
+
+func createChannelRule(sess *sqlstore.DBSession, record channelRuleRecord, rule ChannelRule) error {
+	rules, err := listChannelRules(sess, rule.OrgId)
+	if err != nil {
+		return fmt.Errorf("can't read channel rules: %w", err)
+	}
+	for _, existingRule := range rules {
+		if existingRule.Pattern == rule.Pattern {
+			return fmt.Errorf("pattern already exists in org: %s", rule.Pattern)
+		}
+	}
+	// Patterns of an organization must not conflict with each other in the routing tree.
+	ok, reason := checkRulesValid(rule.OrgId, append(rules, rule))
+	if !ok {
+		return errors.New(reason)
+	}
+	_, err = sess.Insert(&record)
+	return err
+}
+
+func (s *SQLStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	record, rule, err := newChannelRuleRecord(orgID, cmd.Pattern, cmd.Settings)
+	if err != nil {
+		return ChannelRule{}, err
+	}
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		exists, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Exist(&channelRuleRecord{})
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return createChannelRule(sess, record, rule)
+		}
+		_, err = sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Cols("settings", "updated").Update(&record)
+		return err
+	})
+	if err != nil {
+		return ChannelRule{}, err
+	}
+	return rule, nil
+}
+
+func (s *SQLStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	var deleted int64
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var err error
+		deleted, err = sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Delete(&channelRuleRecord{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrChannelRuleNotFound
+	}
+	return nil
+}
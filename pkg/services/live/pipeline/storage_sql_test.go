@@ -0,0 +1,162 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func setupSQLStorage(t *testing.T) *SQLStorage {
+	t.Helper()
+	sqlStore := sqlstore.InitTestDB(t)
+	return &SQLStorage{
+		SQLStore:       sqlStore,
+		SecretsService: secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore)),
+	}
+}
+
+func TestSQLStorage_ChannelRules(t *testing.T) {
+	s := setupSQLStorage(t)
+	ctx := context.Background()
+
+	rule, err := s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+		Pattern: "stream/telegraf/:metric",
+		Settings: ChannelRuleSettings{
+			Converter: &ConverterConfig{Type: ConverterTypeInfluxAuto},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rule.OrgId)
+
+	_, err = s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+	require.Error(t, err, "pattern must be unique in the organization")
+	_, err = s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:other"})
+	require.Error(t, err, "pattern conflicts with the existing one")
+	_, err = s.CreateChannelRule(ctx, 2, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+	require.NoError(t, err, "patterns of other organizations are independent")
+
+	_, err = s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+		Pattern: "stream/telegraf/:metric",
+		Settings: ChannelRuleSettings{
+			Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+		},
+	})
+	require.NoError(t, err)
+	_, err = s.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/json/cpu"})
+	require.NoError(t, err, "update creates missing rules")
+
+	rules, err := s.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, "stream/json/cpu", rules[0].Pattern)
+	require.Equal(t, "stream/telegraf/:metric", rules[1].Pattern)
+	require.Equal(t, ConverterTypeJsonAuto, rules[1].Settings.Converter.Type)
+
+	require.NoError(t, s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/json/cpu"}))
+	require.ErrorIs(t, s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/json/cpu"}), ErrChannelRuleNotFound)
+
+	rules, err = s.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	rules, err = s.ListChannelRules(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+}
+
+func TestSQLStorage_WriteConfigs(t *testing.T) {
+	s := setupSQLStorage(t)
+	ctx := context.Background()
+
+	writeConfig, err := s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+		Settings:       WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+		SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, writeConfig.UID)
+
+	_, err = s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{UID: writeConfig.UID, Settings: WriteSettings{Endpoint: "http://other"}})
+	require.Error(t, err)
+	_, err = s.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{UID: "no-endpoint"})
+	require.Error(t, err)
+
+	stored, ok, err := s.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: writeConfig.UID})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "http://localhost:9090/api/v1/write", stored.Settings.Endpoint)
+	decrypted, err := s.SecretsService.DecryptJsonData(ctx, stored.SecureSettings)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"basicAuthPassword": "secret"}, decrypted)
+
+	_, ok, err = s.GetWriteConfig(ctx, 2, WriteConfigGetCmd{UID: writeConfig.UID})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = s.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{
+		UID:      writeConfig.UID,
+		Settings: WriteSettings{Endpoint: "http://localhost:9091/api/v1/write"},
+	})
+	require.NoError(t, err)
+
+	writeConfigs, err := s.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, writeConfigs, 1)
+	require.Equal(t, "http://localhost:9091/api/v1/write", writeConfigs[0].Settings.Endpoint)
+
+	require.NoError(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: writeConfig.UID}))
+	require.ErrorIs(t, s.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: writeConfig.UID}), ErrWriteConfigNotFound)
+}
+
+type storageRuleBuilder struct {
+	storage Storage
+}
+
+func (b *storageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*LiveChannelRule, error) {
+	rules, err := b.storage.ListChannelRules(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	liveRules := make([]*LiveChannelRule, 0, len(rules))
+	for _, r := range rules {
+		liveRules = append(liveRules, &LiveChannelRule{OrgId: orgID, Pattern: r.Pattern})
+	}
+	return liveRules, nil
+}
+
+func TestNotifyingStorage(t *testing.T) {
+	storage := setupSQLStorage(t)
+	cache := NewCacheSegmentedTree(&storageRuleBuilder{storage: storage})
+	s := &NotifyingStorage{
+		Storage: storage,
+		OnChange: func(orgID int64) {
+			require.NoError(t, cache.Invalidate(orgID))
+		},
+	}
+	ctx := context.Background()
+
+	_, ok, err := cache.Get(1, "stream/test")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = s.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/test"})
+	require.NoError(t, err)
+	_, ok, err = cache.Get(1, "stream/test")
+	require.NoError(t, err)
+	require.True(t, ok, "changed rules are rebuilt")
+
+	_, err = s.CreateChannelRule(ctx, 2, ChannelRuleCreateCmd{Pattern: "stream/test"})
+	require.NoError(t, err)
+	cache.radixMu.RLock()
+	_, built := cache.radix[2]
+	cache.radixMu.RUnlock()
+	require.False(t, built, "rules of organizations which were not used are not built")
+
+	require.NoError(t, s.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/test"}))
+	_, ok, err = cache.Get(1, "stream/test")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
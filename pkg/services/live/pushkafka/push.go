@@ -0,0 +1,46 @@
+package pushkafka
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.push_kafka")
+)
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive) *Gateway {
+	return &Gateway{
+		Cfg:         cfg,
+		GrafanaLive: live,
+	}
+}
+
+// Gateway consumes Kafka topics and pushes their messages to Live Pipeline.
+type Gateway struct {
+	Cfg         *setting.Cfg
+	GrafanaLive *live.GrafanaLive
+}
+
+// IsDisabled returns true if Kafka ingestion is not enabled.
+func (g *Gateway) IsDisabled() bool {
+	return !g.Cfg.LiveKafka.Enabled || len(g.Cfg.LiveKafka.Topics) == 0
+}
+
+// Run Gateway.
+func (g *Gateway) Run(ctx context.Context) error {
+	if g.GrafanaLive.Pipeline == nil {
+		logger.Error("Kafka ingestion requires the live pipeline, enable the live-pipeline feature toggle")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	logger.Info("Live Kafka Gateway initialization", "brokers", g.Cfg.LiveKafka.Brokers)
+	subscriber, err := NewSubscriber(g.Cfg.LiveKafka, g.GrafanaLive.Pipeline)
+	if err != nil {
+		return err
+	}
+	return subscriber.Run(ctx)
+}
@@ -0,0 +1,150 @@
+package pushkafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// connectRetryInterval is the time to wait before connecting again when the brokers cannot be reached.
+const connectRetryInterval = 5 * time.Second
+
+// InputProcessor processes the messages of the topics as the data of Live channels.
+// It is implemented by the Live Pipeline.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// Subscriber consumes Kafka topics as a member of a consumer group and sends their
+// messages to the Live channels the topics are mapped to.
+type Subscriber struct {
+	cfg       setting.LiveKafkaSettings
+	processor InputProcessor
+	// channels maps topics to Live channels.
+	channels map[string]string
+
+	newConsumerGroup func() (sarama.ConsumerGroup, error)
+}
+
+// NewSubscriber creates new Subscriber.
+func NewSubscriber(cfg setting.LiveKafkaSettings, processor InputProcessor) (*Subscriber, error) {
+	channels := make(map[string]string, len(cfg.Topics))
+	for _, t := range cfg.Topics {
+		if _, err := liveDto.ParseChannel(t.Channel); err != nil {
+			return nil, fmt.Errorf("invalid channel %s for Kafka topic %s: %w", t.Channel, t.Topic, err)
+		}
+		channels[t.Topic] = t.Channel
+	}
+	config, err := pipeline.NewKafkaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Subscriber{
+		cfg:       cfg,
+		processor: processor,
+		channels:  channels,
+		newConsumerGroup: func() (sarama.ConsumerGroup, error) {
+			return sarama.NewConsumerGroup(cfg.Brokers, cfg.ConsumerGroup, config)
+		},
+	}, nil
+}
+
+// Run consumes the topics until the context is done.
+func (s *Subscriber) Run(ctx context.Context) error {
+	var group sarama.ConsumerGroup
+	for {
+		var err error
+		group, err = s.newConsumerGroup()
+		if err == nil {
+			break
+		}
+		logger.Error("Error connecting to Kafka brokers", "brokers", s.cfg.Brokers, "error", err)
+		select {
+		case <-time.After(connectRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer func() {
+		if err := group.Close(); err != nil {
+			logger.Warn("Error closing Kafka consumer group", "error", err)
+		}
+	}()
+	logger.Info("Connected to Kafka brokers", "brokers", s.cfg.Brokers, "group", s.cfg.ConsumerGroup)
+
+	go func() {
+		for err := range group.Errors() {
+			logger.Error("Kafka consumer group error", "error", err)
+		}
+	}()
+
+	topics := make([]string, 0, len(s.channels))
+	for topic := range s.channels {
+		topics = append(topics, topic)
+	}
+	handler := &consumerGroupHandler{subscriber: s}
+	for {
+		// Consume returns when the group is rebalanced, so that it should be called again.
+		if err := group.Consume(ctx, topics, handler); err != nil {
+			logger.Error("Error consuming Kafka topics", "error", err)
+			select {
+			case <-time.After(connectRetryInterval):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Subscriber) handleMessage(ctx context.Context, topic string, payload []byte) {
+	channelID, ok := s.channels[topic]
+	if !ok {
+		return
+	}
+
+	logger.Debug("Live channel push request",
+		"protocol", "kafka",
+		"topic", topic,
+		"channel", channelID,
+		"bodyLength", len(payload),
+	)
+
+	ruleFound, err := s.processor.ProcessInput(ctx, s.cfg.OrgID, channelID, payload)
+	if err != nil {
+		logger.Error("Pipeline input processing error", "error", err, "topic", topic, "channel", channelID)
+		return
+	}
+	if !ruleFound {
+		logger.Warn("No conversion rule for a channel", "topic", topic, "channel", channelID)
+	}
+}
+
+type consumerGroupHandler struct {
+	subscriber *Subscriber
+}
+
+func (h *consumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		h.subscriber.handleMessage(session.Context(), msg.Topic, msg.Value)
+		// Messages failed to process are not consumed again, as the following messages
+		// would be stuck behind them.
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package pushkafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type testRuleGetter struct {
+	rules map[string]*pipeline.LiveChannelRule
+}
+
+func (g *testRuleGetter) Get(_ int64, channel string) (*pipeline.LiveChannelRule, bool, error) {
+	rule, ok := g.rules[channel]
+	return rule, ok, nil
+}
+
+type testOutputter struct {
+	frames chan *data.Frame
+}
+
+func (o *testOutputter) Type() string {
+	return "test"
+}
+
+func (o *testOutputter) OutputFrame(_ context.Context, _ pipeline.Vars, frame *data.Frame) ([]*pipeline.ChannelFrame, error) {
+	o.frames <- frame
+	return nil, nil
+}
+
+func TestNewSubscriberInvalidChannel(t *testing.T) {
+	_, err := NewSubscriber(setting.LiveKafkaSettings{
+		Version: "2.0.0",
+		Topics:  []setting.LiveKafkaTopic{{Topic: "sensors", Channel: "stream/sensors"}},
+	}, nil)
+	require.Error(t, err)
+}
+
+func TestSubscriber(t *testing.T) {
+	outputter := &testOutputter{frames: make(chan *data.Frame, 10)}
+	p, err := pipeline.New(&testRuleGetter{
+		rules: map[string]*pipeline.LiveChannelRule{
+			"stream/sensors/temperature": {
+				Converter:       pipeline.NewAutoJsonConverter(pipeline.AutoJsonConverterConfig{}),
+				FrameOutputters: []pipeline.FrameOutputter{outputter},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	s, err := NewSubscriber(setting.LiveKafkaSettings{
+		Brokers:       []string{"127.0.0.1:9092"},
+		Version:       "2.0.0",
+		OrgID:         1,
+		ConsumerGroup: "grafana",
+		Topics: []setting.LiveKafkaTopic{
+			{Topic: "temperature", Channel: "stream/sensors/temperature"},
+		},
+	}, p)
+	require.NoError(t, err)
+
+	group := newTestConsumerGroup()
+	s.newConsumerGroup = func() (sarama.ConsumerGroup, error) {
+		return group, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	select {
+	case topics := <-group.consumed:
+		require.Equal(t, []string{"temperature"}, topics)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for consumption")
+	}
+
+	group.messages <- &sarama.ConsumerMessage{Topic: "temperature", Offset: 1, Value: []byte(`{"value": 21.5}`)}
+	select {
+	case frame := <-outputter.frames:
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, "value", frame.Fields[1].Name)
+		v, ok := frame.Fields[1].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 21.5, v)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for frame")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for subscriber to stop")
+	}
+	require.True(t, group.closed)
+	require.Equal(t, []int64{1}, group.session.marked)
+}
+
+// testConsumerGroup is an in-process consumer group, it assigns a single claim
+// with the messages sent by the test to the handler.
+type testConsumerGroup struct {
+	consumed chan []string
+	messages chan *sarama.ConsumerMessage
+	errors   chan error
+	session  *testSession
+	closed   bool
+}
+
+func newTestConsumerGroup() *testConsumerGroup {
+	return &testConsumerGroup{
+		consumed: make(chan []string, 10),
+		messages: make(chan *sarama.ConsumerMessage, 10),
+		errors:   make(chan error),
+	}
+}
+
+func (g *testConsumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	g.session = &testSession{ctx: ctx}
+	if err := handler.Setup(g.session); err != nil {
+		return err
+	}
+	g.consumed <- topics
+
+	claim := &testClaim{messages: make(chan *sarama.ConsumerMessage)}
+	go func() {
+		defer close(claim.messages)
+		for {
+			select {
+			case msg := <-g.messages:
+				claim.messages <- msg
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	if err := handler.ConsumeClaim(g.session, claim); err != nil {
+		return err
+	}
+	return handler.Cleanup(g.session)
+}
+
+func (g *testConsumerGroup) Errors() <-chan error {
+	return g.errors
+}
+
+func (g *testConsumerGroup) Close() error {
+	g.closed = true
+	close(g.errors)
+	return nil
+}
+
+type testSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *testSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type testClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *testClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
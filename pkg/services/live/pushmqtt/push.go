@@ -0,0 +1,46 @@
+package pushmqtt
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.push_mqtt")
+)
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive) *Gateway {
+	return &Gateway{
+		Cfg:         cfg,
+		GrafanaLive: live,
+	}
+}
+
+// Gateway subscribes to MQTT topics and pushes their messages to Live Pipeline.
+type Gateway struct {
+	Cfg         *setting.Cfg
+	GrafanaLive *live.GrafanaLive
+}
+
+// IsDisabled returns true if MQTT ingestion is not enabled.
+func (g *Gateway) IsDisabled() bool {
+	return !g.Cfg.LiveMQTT.Enabled
+}
+
+// Run Gateway.
+func (g *Gateway) Run(ctx context.Context) error {
+	if g.GrafanaLive.Pipeline == nil {
+		logger.Error("MQTT ingestion requires the live pipeline, enable the livePipeline feature toggle")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	logger.Info("Live MQTT Gateway initialization", "broker", g.Cfg.LiveMQTT.Broker)
+	subscriber, err := NewSubscriber(g.Cfg.LiveMQTT, g.GrafanaLive.Pipeline)
+	if err != nil {
+		return err
+	}
+	return subscriber.Run(ctx)
+}
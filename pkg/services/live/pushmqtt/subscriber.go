@@ -0,0 +1,228 @@
+package pushmqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// connectRetryInterval is the time to wait before connecting again when the broker cannot be reached on start.
+	connectRetryInterval = 5 * time.Second
+	// maxReconnectInterval is the maximum time to wait between reconnections once connected.
+	maxReconnectInterval = time.Minute
+	// disconnectQuiesce is the time in milliseconds to wait for the pending work on disconnect.
+	disconnectQuiesce = 250
+)
+
+// InputProcessor processes the messages of the topics as the data of Live channels.
+// It is implemented by the Live Pipeline.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// Subscriber subscribes to MQTT topics on a broker and sends their messages to
+// the Live channels the topics are mapped to.
+type Subscriber struct {
+	cfg       setting.LiveMQTTSettings
+	processor InputProcessor
+	topics    []topicMapping
+}
+
+// NewSubscriber creates new Subscriber.
+func NewSubscriber(cfg setting.LiveMQTTSettings, processor InputProcessor) (*Subscriber, error) {
+	topics := make([]topicMapping, 0, len(cfg.Topics))
+	for _, t := range cfg.Topics {
+		m, err := newTopicMapping(t.Filter, t.Channel)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, m)
+	}
+	return &Subscriber{
+		cfg:       cfg,
+		processor: processor,
+		topics:    topics,
+	}, nil
+}
+
+// Run connects to the broker and processes the messages of the topics until the context is done.
+func (s *Subscriber) Run(ctx context.Context) error {
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.cfg.Broker).
+		SetClientID(s.cfg.ClientID).
+		SetUsername(s.cfg.Username).
+		SetPassword(s.cfg.Password).
+		SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}).
+		SetCleanSession(true).
+		SetAutoReconnect(true).
+		SetMaxReconnectInterval(maxReconnectInterval).
+		SetDefaultPublishHandler(func(_ mqtt.Client, msg mqtt.Message) {
+			s.handleMessage(ctx, msg.Topic(), msg.Payload())
+		}).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			// Subscriptions are not kept by the broker with a clean session,
+			// so subscribe again after every reconnection.
+			s.subscribe(c)
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			logger.Warn("Connection to MQTT broker lost", "broker", s.cfg.Broker, "error", err)
+		})
+	client := mqtt.NewClient(opts)
+
+	for {
+		token := client.Connect()
+		token.Wait()
+		if token.Error() == nil {
+			break
+		}
+		logger.Error("Error connecting to MQTT broker", "broker", s.cfg.Broker, "error", token.Error())
+		select {
+		case <-time.After(connectRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	logger.Info("Connected to MQTT broker", "broker", s.cfg.Broker)
+
+	<-ctx.Done()
+	client.Disconnect(disconnectQuiesce)
+	return ctx.Err()
+}
+
+func (s *Subscriber) subscribe(c mqtt.Client) {
+	filters := make(map[string]byte, len(s.topics))
+	for _, t := range s.topics {
+		filter := t.filter
+		if s.cfg.SharedSubscriptionGroup != "" {
+			filter = "$share/" + s.cfg.SharedSubscriptionGroup + "/" + filter
+		}
+		filters[filter] = s.cfg.QoS
+	}
+	// Messages are dispatched by the default publish handler, which
+	// also receives the messages of shared subscriptions.
+	token := c.SubscribeMultiple(filters, nil)
+	token.Wait()
+	if token.Error() != nil {
+		logger.Error("Error subscribing to MQTT topics", "broker", s.cfg.Broker, "error", token.Error())
+		return
+	}
+	logger.Debug("Subscribed to MQTT topics", "broker", s.cfg.Broker, "topics", len(filters))
+}
+
+func (s *Subscriber) handleMessage(ctx context.Context, topic string, payload []byte) {
+	for _, t := range s.topics {
+		if !t.match(topic) {
+			continue
+		}
+		channelID, err := t.channel(topic)
+		if err != nil {
+			logger.Warn("Invalid channel for MQTT topic", "topic", topic, "error", err)
+			continue
+		}
+
+		logger.Debug("Live channel push request",
+			"protocol", "mqtt",
+			"topic", topic,
+			"channel", channelID,
+			"bodyLength", len(payload),
+		)
+
+		ruleFound, err := s.processor.ProcessInput(ctx, s.cfg.OrgID, channelID, payload)
+		if err != nil {
+			logger.Error("Pipeline input processing error", "error", err, "topic", topic, "channel", channelID)
+			continue
+		}
+		if !ruleFound {
+			logger.Warn("No conversion rule for a channel", "topic", topic, "channel", channelID)
+		}
+	}
+}
+
+// topicMapping maps the topics matching an MQTT topic filter to a Live channel.
+// When the filter has wildcards, the topic levels from the first wildcard are
+// appended to the path of the channel, so that
+// sensors/+/temperature=stream/sensors maps sensors/kitchen/temperature to
+// stream/sensors/kitchen/temperature.
+type topicMapping struct {
+	filter        string
+	filterLevels  []string
+	channelPrefix string
+	// wildcardLevel is the index of the first wildcard level of the filter, -1 if there is none.
+	wildcardLevel int
+}
+
+func newTopicMapping(filter string, channel string) (topicMapping, error) {
+	m := topicMapping{
+		filter:        filter,
+		filterLevels:  strings.Split(filter, "/"),
+		channelPrefix: channel,
+		wildcardLevel: -1,
+	}
+	for i, level := range m.filterLevels {
+		switch {
+		case level == "#" && i != len(m.filterLevels)-1:
+			return topicMapping{}, fmt.Errorf("invalid MQTT topic filter %s: # must be the last level", filter)
+		case level == "#" || level == "+":
+			if m.wildcardLevel == -1 {
+				m.wildcardLevel = i
+			}
+		case strings.ContainsAny(level, "#+"):
+			return topicMapping{}, fmt.Errorf("invalid MQTT topic filter %s: wildcards must occupy an entire level", filter)
+		}
+	}
+	// With wildcards the channel is a prefix, check it with a path appended.
+	check := channel
+	if m.wildcardLevel != -1 {
+		check += "/path"
+	}
+	if _, err := liveDto.ParseChannel(check); err != nil {
+		return topicMapping{}, fmt.Errorf("invalid channel %s for MQTT topic filter %s: %w", channel, filter, err)
+	}
+	return m, nil
+}
+
+// match returns true if the topic matches the filter of the mapping.
+func (m topicMapping) match(topic string) bool {
+	levels := strings.Split(topic, "/")
+	// Wildcards at the first level do not match topics starting with $.
+	if m.wildcardLevel == 0 && strings.HasPrefix(topic, "$") {
+		return false
+	}
+	for i, level := range m.filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(levels) {
+			return false
+		}
+		if level != "+" && level != levels[i] {
+			return false
+		}
+	}
+	return len(levels) == len(m.filterLevels)
+}
+
+// channel returns the Live channel of a topic matching the filter of the mapping.
+func (m topicMapping) channel(topic string) (string, error) {
+	if m.wildcardLevel == -1 {
+		return m.channelPrefix, nil
+	}
+	levels := strings.Split(topic, "/")
+	path := strings.Join(levels[m.wildcardLevel:], "/")
+	channel := m.channelPrefix
+	if path != "" {
+		channel += "/" + path
+	}
+	if _, err := liveDto.ParseChannel(channel); err != nil {
+		return "", err
+	}
+	return channel, nil
+}
@@ -0,0 +1,255 @@
+package pushmqtt
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTopicMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		channel string
+		topic   string
+		match   bool
+		result  string
+	}{
+		{name: "exact topic", filter: "plant/line1", channel: "stream/plant/line1", topic: "plant/line1", match: true, result: "stream/plant/line1"},
+		{name: "exact topic mismatch", filter: "plant/line1", channel: "stream/plant/line1", topic: "plant/line2"},
+		{name: "single level wildcard", filter: "sensors/+/temperature", channel: "stream/sensors", topic: "sensors/kitchen/temperature", match: true, result: "stream/sensors/kitchen/temperature"},
+		{name: "single level wildcard mismatch", filter: "sensors/+/temperature", channel: "stream/sensors", topic: "sensors/kitchen/humidity"},
+		{name: "single level wildcard too deep", filter: "sensors/+", channel: "stream/sensors", topic: "sensors/kitchen/temperature"},
+		{name: "multi level wildcard", filter: "sensors/#", channel: "stream/sensors", topic: "sensors/kitchen/temperature", match: true, result: "stream/sensors/kitchen/temperature"},
+		{name: "wildcard with channel path", filter: "sensors/#", channel: "stream/iot/sensors", topic: "sensors/kitchen", match: true, result: "stream/iot/sensors/kitchen"},
+		{name: "system topics", filter: "#", channel: "stream/all", topic: "$SYS/uptime"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newTopicMapping(tt.filter, tt.channel)
+			require.NoError(t, err)
+			require.Equal(t, tt.match, m.match(tt.topic))
+			if !tt.match {
+				return
+			}
+			channel, err := m.channel(tt.topic)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, channel)
+		})
+	}
+}
+
+func TestTopicMappingInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		channel string
+	}{
+		{name: "multi level wildcard not last", filter: "sensors/#/temperature", channel: "stream/sensors"},
+		{name: "partial wildcard", filter: "sensors/kitchen+", channel: "stream/sensors"},
+		{name: "channel without path", filter: "plant/line1", channel: "stream/plant"},
+		{name: "invalid channel", filter: "sensors/#", channel: "stream/sen sors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTopicMapping(tt.filter, tt.channel)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTopicMappingInvalidTopic(t *testing.T) {
+	m, err := newTopicMapping("sensors/#", "stream/sensors")
+	require.NoError(t, err)
+	require.True(t, m.match("sensors/living room"))
+	_, err = m.channel("sensors/living room")
+	require.Error(t, err)
+}
+
+type testRuleGetter struct {
+	rules map[string]*pipeline.LiveChannelRule
+}
+
+func (g *testRuleGetter) Get(_ int64, channel string) (*pipeline.LiveChannelRule, bool, error) {
+	rule, ok := g.rules[channel]
+	return rule, ok, nil
+}
+
+type testOutputter struct {
+	frames chan *data.Frame
+}
+
+func (o *testOutputter) Type() string {
+	return "test"
+}
+
+func (o *testOutputter) OutputFrame(_ context.Context, _ pipeline.Vars, frame *data.Frame) ([]*pipeline.ChannelFrame, error) {
+	o.frames <- frame
+	return nil, nil
+}
+
+func TestSubscriber(t *testing.T) {
+	broker := newTestBroker(t)
+
+	outputter := &testOutputter{frames: make(chan *data.Frame, 10)}
+	p, err := pipeline.New(&testRuleGetter{
+		rules: map[string]*pipeline.LiveChannelRule{
+			"stream/sensors/kitchen/temperature": {
+				Converter:       pipeline.NewAutoJsonConverter(pipeline.AutoJsonConverterConfig{}),
+				FrameOutputters: []pipeline.FrameOutputter{outputter},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	s, err := NewSubscriber(setting.LiveMQTTSettings{
+		Broker:                  "tcp://" + broker.addr(),
+		ClientID:                "grafana-test",
+		OrgID:                   1,
+		SharedSubscriptionGroup: "grafana",
+		Topics: []setting.LiveMQTTTopic{
+			{Filter: "sensors/+/temperature", Channel: "stream/sensors"},
+		},
+	}, p)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	select {
+	case filters := <-broker.subscribed:
+		require.Equal(t, []string{"$share/grafana/sensors/+/temperature"}, filters)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for subscription")
+	}
+
+	broker.publish(t, "sensors/kitchen/temperature", []byte(`{"value": 21.5}`))
+	select {
+	case frame := <-outputter.frames:
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, "value", frame.Fields[1].Name)
+		v, ok := frame.Fields[1].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 21.5, v)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for frame")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for subscriber to stop")
+	}
+}
+
+// testBroker is a minimal MQTT broker, it acknowledges connections and subscriptions
+// and sends the publications of the test to all the connected clients.
+type testBroker struct {
+	listener   net.Listener
+	subscribed chan []string
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func newTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &testBroker{
+		listener:   listener,
+		subscribed: make(chan []string, 10),
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for _, conn := range b.conns {
+			_ = conn.Close()
+		}
+	})
+	go b.accept()
+	return b
+}
+
+func (b *testBroker) addr() string {
+	return b.listener.Addr().String()
+}
+
+func (b *testBroker) accept() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.serve(conn)
+	}
+}
+
+func (b *testBroker) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	for {
+		packet, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		var reply packets.ControlPacket
+		switch p := packet.(type) {
+		case *packets.ConnectPacket:
+			b.mu.Lock()
+			b.conns = append(b.conns, conn)
+			b.mu.Unlock()
+			reply = packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+		case *packets.SubscribePacket:
+			suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			suback.MessageID = p.MessageID
+			suback.ReturnCodes = p.Qoss
+			reply = suback
+			b.subscribed <- p.Topics
+		case *packets.PingreqPacket:
+			reply = packets.NewControlPacket(packets.Pingresp)
+		case *packets.DisconnectPacket:
+			return
+		}
+		if reply == nil {
+			continue
+		}
+		b.mu.Lock()
+		err = reply.Write(conn)
+		b.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (b *testBroker) publish(t *testing.T, topic string, payload []byte) {
+	t.Helper()
+	pub := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	pub.TopicName = topic
+	pub.Payload = payload
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	require.NotEmpty(t, b.conns)
+	for _, conn := range b.conns {
+		if err := pub.Write(conn); err != nil && !errors.Is(err, net.ErrClosed) {
+			require.NoError(t, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package pushnats
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.push_nats")
+)
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive) *Gateway {
+	return &Gateway{
+		Cfg:         cfg,
+		GrafanaLive: live,
+	}
+}
+
+// Gateway subscribes to NATS subjects and pushes their messages to Live Pipeline.
+type Gateway struct {
+	Cfg         *setting.Cfg
+	GrafanaLive *live.GrafanaLive
+}
+
+// IsDisabled returns true if NATS ingestion is not enabled.
+func (g *Gateway) IsDisabled() bool {
+	return !g.Cfg.LiveNATS.Enabled || len(g.Cfg.LiveNATS.Subjects) == 0
+}
+
+// Run Gateway.
+func (g *Gateway) Run(ctx context.Context) error {
+	if g.GrafanaLive.Pipeline == nil {
+		logger.Error("NATS ingestion requires the live pipeline, enable the live-pipeline feature toggle")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	logger.Info("Live NATS Gateway initialization", "url", g.Cfg.LiveNATS.URL)
+	subscriber, err := NewSubscriber(g.Cfg.LiveNATS, g.GrafanaLive.Pipeline)
+	if err != nil {
+		return err
+	}
+	return subscriber.Run(ctx)
+}
@@ -0,0 +1,181 @@
+package pushnats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/nats-io/nats.go"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// connectRetryInterval is the time to wait before connecting again when the server cannot be reached on start.
+const connectRetryInterval = 5 * time.Second
+
+// InputProcessor processes the messages of the subjects as the data of Live channels.
+// It is implemented by the Live Pipeline.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// Subscriber subscribes to NATS subjects and sends their messages to the Live
+// channels the subjects are mapped to.
+type Subscriber struct {
+	cfg       setting.LiveNATSSettings
+	processor InputProcessor
+	subjects  []subjectMapping
+}
+
+// NewSubscriber creates new Subscriber.
+func NewSubscriber(cfg setting.LiveNATSSettings, processor InputProcessor) (*Subscriber, error) {
+	subjects := make([]subjectMapping, 0, len(cfg.Subjects))
+	for _, s := range cfg.Subjects {
+		m, err := newSubjectMapping(s.Subject, s.Channel)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, m)
+	}
+	return &Subscriber{
+		cfg:       cfg,
+		processor: processor,
+		subjects:  subjects,
+	}, nil
+}
+
+// Run connects to the server and processes the messages of the subjects until the context is done.
+func (s *Subscriber) Run(ctx context.Context) error {
+	opts := append(pipeline.NewNATSOptions(s.cfg),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("Connection to NATS server lost", "url", s.cfg.URL, "error", err)
+			}
+		}),
+	)
+
+	var conn *nats.Conn
+	for {
+		var err error
+		conn, err = nats.Connect(s.cfg.URL, opts...)
+		if err == nil {
+			break
+		}
+		logger.Error("Error connecting to NATS server", "url", s.cfg.URL, "error", err)
+		select {
+		case <-time.After(connectRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer conn.Close()
+	logger.Info("Connected to NATS server", "url", s.cfg.URL)
+
+	// Subscriptions are restored by the connection after reconnecting.
+	for _, m := range s.subjects {
+		m := m
+		handler := func(msg *nats.Msg) {
+			s.handleMessage(ctx, m, msg.Subject, msg.Data)
+		}
+		var err error
+		if s.cfg.QueueGroup != "" {
+			_, err = conn.QueueSubscribe(m.subject, s.cfg.QueueGroup, handler)
+		} else {
+			_, err = conn.Subscribe(m.subject, handler)
+		}
+		if err != nil {
+			return fmt.Errorf("error subscribing to NATS subject %s: %w", m.subject, err)
+		}
+	}
+	logger.Debug("Subscribed to NATS subjects", "url", s.cfg.URL, "subjects", len(s.subjects))
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (s *Subscriber) handleMessage(ctx context.Context, m subjectMapping, subject string, payload []byte) {
+	channelID, err := m.channel(subject)
+	if err != nil {
+		logger.Warn("Invalid channel for NATS subject", "subject", subject, "error", err)
+		return
+	}
+
+	logger.Debug("Live channel push request",
+		"protocol", "nats",
+		"subject", subject,
+		"channel", channelID,
+		"bodyLength", len(payload),
+	)
+
+	ruleFound, err := s.processor.ProcessInput(ctx, s.cfg.OrgID, channelID, payload)
+	if err != nil {
+		logger.Error("Pipeline input processing error", "error", err, "subject", subject, "channel", channelID)
+		return
+	}
+	if !ruleFound {
+		logger.Warn("No conversion rule for a channel", "subject", subject, "channel", channelID)
+	}
+}
+
+// subjectMapping maps the subjects matching a NATS subject to a Live channel.
+// When the subject has wildcards, the tokens from the first wildcard are
+// appended to the path of the channel, so that
+// sensors.*.temperature=stream/sensors maps sensors.kitchen.temperature to
+// stream/sensors/kitchen/temperature.
+type subjectMapping struct {
+	subject       string
+	channelPrefix string
+	// wildcardToken is the index of the first wildcard token of the subject, -1 if there is none.
+	wildcardToken int
+}
+
+func newSubjectMapping(subject string, channel string) (subjectMapping, error) {
+	m := subjectMapping{
+		subject:       subject,
+		channelPrefix: channel,
+		wildcardToken: -1,
+	}
+	tokens := strings.Split(subject, ".")
+	for i, token := range tokens {
+		switch {
+		case token == "":
+			return subjectMapping{}, fmt.Errorf("invalid NATS subject %s: empty token", subject)
+		case token == ">" && i != len(tokens)-1:
+			return subjectMapping{}, fmt.Errorf("invalid NATS subject %s: > must be the last token", subject)
+		case token == ">" || token == "*":
+			if m.wildcardToken == -1 {
+				m.wildcardToken = i
+			}
+		case strings.ContainsAny(token, "*>"):
+			return subjectMapping{}, fmt.Errorf("invalid NATS subject %s: wildcards must occupy an entire token", subject)
+		}
+	}
+	// With wildcards the channel is a prefix, check it with a path appended.
+	check := channel
+	if m.wildcardToken != -1 {
+		check += "/path"
+	}
+	if _, err := liveDto.ParseChannel(check); err != nil {
+		return subjectMapping{}, fmt.Errorf("invalid channel %s for NATS subject %s: %w", channel, subject, err)
+	}
+	return m, nil
+}
+
+// channel returns the Live channel of a subject matching the subject of the mapping.
+func (m subjectMapping) channel(subject string) (string, error) {
+	if m.wildcardToken == -1 {
+		return m.channelPrefix, nil
+	}
+	tokens := strings.Split(subject, ".")
+	if len(tokens) <= m.wildcardToken {
+		return "", fmt.Errorf("subject %s does not match %s", subject, m.subject)
+	}
+	channel := m.channelPrefix + "/" + strings.Join(tokens[m.wildcardToken:], "/")
+	if _, err := liveDto.ParseChannel(channel); err != nil {
+		return "", err
+	}
+	return channel, nil
+}
@@ -0,0 +1,151 @@
+package pushnats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSubjectMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		channel string
+		msg     string
+		result  string
+	}{
+		{name: "exact subject", subject: "plant.line1", channel: "stream/plant/line1", msg: "plant.line1", result: "stream/plant/line1"},
+		{name: "single token wildcard", subject: "sensors.*.temperature", channel: "stream/sensors", msg: "sensors.kitchen.temperature", result: "stream/sensors/kitchen/temperature"},
+		{name: "full wildcard", subject: "sensors.>", channel: "stream/sensors", msg: "sensors.kitchen.temperature", result: "stream/sensors/kitchen/temperature"},
+		{name: "wildcard with channel path", subject: "sensors.>", channel: "stream/iot/sensors", msg: "sensors.kitchen", result: "stream/iot/sensors/kitchen"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newSubjectMapping(tt.subject, tt.channel)
+			require.NoError(t, err)
+			channel, err := m.channel(tt.msg)
+			require.NoError(t, err)
+			require.Equal(t, tt.result, channel)
+		})
+	}
+}
+
+func TestSubjectMappingInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		channel string
+	}{
+		{name: "full wildcard not last", subject: "sensors.>.temperature", channel: "stream/sensors"},
+		{name: "partial wildcard", subject: "sensors.kitchen*", channel: "stream/sensors"},
+		{name: "empty token", subject: "sensors..kitchen", channel: "stream/sensors/kitchen"},
+		{name: "channel without path", subject: "plant.line1", channel: "stream/plant"},
+		{name: "invalid channel", subject: "sensors.>", channel: "stream/sen sors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSubjectMapping(tt.subject, tt.channel)
+			require.Error(t, err)
+		})
+	}
+}
+
+type testRuleGetter struct {
+	rules map[string]*pipeline.LiveChannelRule
+}
+
+func (g *testRuleGetter) Get(_ int64, channel string) (*pipeline.LiveChannelRule, bool, error) {
+	rule, ok := g.rules[channel]
+	return rule, ok, nil
+}
+
+type testOutputter struct {
+	frames chan *data.Frame
+}
+
+func (o *testOutputter) Type() string {
+	return "test"
+}
+
+func (o *testOutputter) OutputFrame(_ context.Context, _ pipeline.Vars, frame *data.Frame) ([]*pipeline.ChannelFrame, error) {
+	o.frames <- frame
+	return nil, nil
+}
+
+func runTestServer(t *testing.T) *server.Server {
+	t.Helper()
+	s, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true})
+	require.NoError(t, err)
+	go s.Start()
+	t.Cleanup(s.Shutdown)
+	require.True(t, s.ReadyForConnections(5*time.Second), "NATS server is not ready")
+	return s
+}
+
+func TestSubscriber(t *testing.T) {
+	srv := runTestServer(t)
+
+	outputter := &testOutputter{frames: make(chan *data.Frame, 10)}
+	p, err := pipeline.New(&testRuleGetter{
+		rules: map[string]*pipeline.LiveChannelRule{
+			"stream/sensors/kitchen/temperature": {
+				Converter:       pipeline.NewAutoJsonConverter(pipeline.AutoJsonConverterConfig{}),
+				FrameOutputters: []pipeline.FrameOutputter{outputter},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	s, err := NewSubscriber(setting.LiveNATSSettings{
+		URL:        srv.ClientURL(),
+		Name:       "grafana-test",
+		OrgID:      1,
+		QueueGroup: "grafana",
+		Subjects: []setting.LiveNATSSubject{
+			{Subject: "sensors.*.temperature", Channel: "stream/sensors"},
+		},
+	}, p)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return srv.NumSubscriptions() > 0
+	}, 5*time.Second, 10*time.Millisecond, "timeout waiting for subscription")
+
+	conn, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.Publish("sensors.kitchen.temperature", []byte(`{"value": 21.5}`)))
+
+	select {
+	case frame := <-outputter.frames:
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, "value", frame.Fields[1].Name)
+		v, ok := frame.Fields[1].ConcreteAt(0)
+		require.True(t, ok)
+		require.Equal(t, 21.5, v)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for frame")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for subscriber to stop")
+	}
+}
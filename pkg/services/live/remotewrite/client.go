@@ -0,0 +1,53 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Client sends Prometheus TimeSeries to a remote write endpoint.
+type Client struct {
+	// Endpoint to send time series to.
+	Endpoint string
+	// User and Password are the optional basic auth params.
+	User     string
+	Password string
+
+	HTTPClient *http.Client
+}
+
+// Send sends the time series in a single remote write request.
+func (c *Client) Send(ctx context.Context, timeSeries []prompb.TimeSeries) error {
+	remoteWriteData, err := TimeSeriesToBytes(timeSeries)
+	if err != nil {
+		return fmt.Errorf("error converting time series to bytes: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(remoteWriteData))
+	if err != nil {
+		return fmt.Errorf("error constructing remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if c.User != "" {
+		req.SetBasicAuth(c.User, c.Password)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending remote write request: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response code from remote write endpoint: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package remotewrite
 import (
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 
@@ -190,6 +191,36 @@ func TimeSeriesFromFramesLabelsColumn(frames ...*data.Frame) []prompb.TimeSeries
 	return promTimeSeriesBatch
 }
 
+// TimeSeriesFromSample creates a Prometheus TimeSeries with a single sample. Metric and label
+// names are sanitized, and labels are sorted by name. False is returned when the metric name
+// cannot be sanitized. A __name__ label is replaced by the metric name.
+func TimeSeriesFromSample(name string, labels map[string]string, tm time.Time, value float64) (prompb.TimeSeries, bool) {
+	metricName, ok := sanitizeMetricName(name)
+	if !ok {
+		return prompb.TimeSeries{}, false
+	}
+	promLabels := make([]prompb.Label, 0, len(labels)+1)
+	for _, l := range createLabels(labels) {
+		if l.Name != "__name__" {
+			promLabels = append(promLabels, l)
+		}
+	}
+	promLabels = append(promLabels, prompb.Label{
+		Name:  "__name__",
+		Value: metricName,
+	})
+	sort.Slice(promLabels, func(i, j int) bool {
+		return promLabels[i].Name < promLabels[j].Name
+	})
+	return prompb.TimeSeries{
+		Labels: promLabels,
+		Samples: []prompb.Sample{{
+			Timestamp: toSampleTime(tm),
+			Value:     value,
+		}},
+	}, true
+}
+
 func timeFieldIndex(frame *data.Frame) (int, bool) {
 	timeFieldIndex := -1
 	for i, field := range frame.Fields {
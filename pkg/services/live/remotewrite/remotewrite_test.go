@@ -1,10 +1,18 @@
 package remotewrite
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
 )
 
@@ -85,3 +93,49 @@ func TestSerialize(t *testing.T) {
 	_, err := Serialize(frame)
 	require.NoError(t, err)
 }
+
+func TestTsFromSample(t *testing.T) {
+	tm := time.Now()
+	ts, ok := TimeSeriesFromSample("cpu:usage", map[string]string{"zone": "a", "__name__": "other", "host-name": "web"}, tm, 2.5)
+	require.True(t, ok)
+	require.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "cpu:usage"},
+		{Name: "host_name", Value: "web"},
+		{Name: "zone", Value: "a"},
+	}, ts.Labels)
+	require.Equal(t, []prompb.Sample{{Timestamp: toSampleTime(tm), Value: 2.5}}, ts.Samples)
+
+	_, ok = TimeSeriesFromSample("", nil, tm, 1)
+	require.False(t, ok)
+}
+
+func TestClientSend(t *testing.T) {
+	var received prompb.WriteRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "secret", password)
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(b, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ts, ok := TimeSeriesFromSample("test", map[string]string{"a": "b"}, time.Now(), 1)
+	require.True(t, ok)
+
+	client := Client{Endpoint: server.URL, User: "user", Password: "secret"}
+	require.NoError(t, client.Send(context.Background(), []prompb.TimeSeries{ts}))
+	require.Len(t, received.Timeseries, 1)
+	require.Equal(t, ts.Labels, received.Timeseries[0].Labels)
+
+	client.Endpoint = server.URL + "/missing"
+	server.Config.Handler = http.NotFoundHandler()
+	require.Error(t, client.Send(context.Background(), []prompb.TimeSeries{ts}))
+}
@@ -67,11 +67,19 @@ type API struct {
 	InstanceStore        store.InstanceStore
 	AlertingStore        AlertingStore
 	AdminConfigStore     store.AdminConfigurationStore
+	ProvenanceStore      store.ProvisioningStore
+	StateHistoryStore    store.StateHistoryStore
+	NotificationLogStore store.NotificationLogStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
 	SecretsService       secrets.Service
 	AccessControl        accesscontrol.AccessControl
+	AlertRules           AlertRuleService
+	ContactPoints        ContactPointService
+	Policies             NotificationPolicyService
+	MuteTimings          MuteTimingService
+	Templates            TemplateService
 }
 
 // RegisterAPIEndpoints registers API handlers
@@ -85,7 +93,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 	api.RegisterAlertmanagerApiEndpoints(NewForkedAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		&AlertmanagerSrv{store: api.AlertingStore, mam: api.MultiOrgAlertmanager, secrets: api.SecretsService, log: logger},
+		&AlertmanagerSrv{store: api.AlertingStore, provenanceStore: api.ProvenanceStore, mam: api.MultiOrgAlertmanager, secrets: api.SecretsService, log: logger},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkedProm(
@@ -97,7 +105,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		&RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, scheduleService: api.Schedule, store: api.RuleStore, log: logger, cfg: &api.Cfg.UnifiedAlerting},
+		&RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, scheduleService: api.Schedule, store: api.RuleStore, provenanceStore: api.ProvenanceStore, log: logger, cfg: &api.Cfg.UnifiedAlerting},
 	), m)
 	api.RegisterTestingApiEndpoints(NewForkedTestingApi(
 		&TestingApiSrv{
@@ -115,4 +123,37 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			scheduler: api.Schedule,
 		},
 	), m)
+	api.RegisterProvisioningApiEndpoints(NewForkedProvisioningApi(
+		&ProvisioningSrv{
+			log:           logger,
+			alertRules:    api.AlertRules,
+			contactPoints: api.ContactPoints,
+			policies:      api.Policies,
+			muteTimings:   api.MuteTimings,
+			templates:     api.Templates,
+			namespaces:    api.RuleStore,
+		},
+	), m)
+	api.RegisterHistoryApiEndpoints(NewForkedHistoryApi(
+		&HistorySrv{
+			log:        logger,
+			store:      api.StateHistoryStore,
+			namespaces: api.RuleStore,
+		},
+	), m)
+	api.RegisterConvertPrometheusApiEndpoints(NewForkedConvertPrometheusApi(
+		&ConvertPrometheusSrv{
+			log:             logger,
+			cfg:             &api.Cfg.UnifiedAlerting,
+			store:           api.RuleStore,
+			datasourceCache: api.DatasourceCache,
+			quotaService:    api.QuotaService,
+		},
+	), m)
+	api.RegisterNotificationLogApiEndpoints(NewForkedNotificationLogApi(
+		&NotificationLogSrv{
+			log:   logger,
+			store: api.NotificationLogStore,
+		},
+	), m)
 }
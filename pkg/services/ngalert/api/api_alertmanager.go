@@ -28,10 +28,11 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	mam     *notifier.MultiOrgAlertmanager
-	secrets secrets.Service
-	store   AlertingStore
-	log     log.Logger
+	mam             *notifier.MultiOrgAlertmanager
+	secrets         secrets.Service
+	store           AlertingStore
+	provenanceStore store.ProvisioningStore
+	log             log.Logger
 }
 
 type UnknownReceiverError struct {
@@ -154,6 +155,14 @@ func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) respo
 		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
 	}
 
+	provisioned, err := srv.getProvisionedConfig(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of the configuration")
+	}
+	if !provisioned.isEmpty() {
+		return ErrResp(http.StatusConflict, ngmodels.ErrProvisionedResource, "the configuration has provisioned resources and cannot be deleted")
+	}
+
 	am, errResp := srv.AlertmanagerFor(c.OrgId)
 	if errResp != nil {
 		return errResp
@@ -204,6 +213,10 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to unmarshal alertmanager configuration")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.OrgId, ngmodels.ResourceTypeContactPoint)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of contact points")
+	}
 
 	result := apimodels.GettableUserConfig{
 		TemplateFiles: cfg.TemplateFiles,
@@ -232,6 +245,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 				DisableResolveMessage: pr.DisableResolveMessage,
 				Settings:              pr.Settings,
 				SecureFields:          secureFields,
+				Provenance:            provenances[recv.Name],
 			}
 			receivers = append(receivers, &gr)
 		}
@@ -355,6 +369,20 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 
+	var current *apimodels.PostableUserConfig
+	if query.Result != nil {
+		var err error
+		if current, err = notifier.Load([]byte(query.Result.AlertmanagerConfiguration)); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to load latest configuration")
+		}
+	}
+	if err := srv.checkProvisionedConfig(c.Req.Context(), c.OrgId, current, &body); err != nil {
+		if errors.Is(err, ngmodels.ErrProvisionedResource) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
 	if err := body.ProcessConfig(srv.secrets.Encrypt); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to post process Alertmanager configuration")
 	}
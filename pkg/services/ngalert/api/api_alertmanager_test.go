@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/setting"
@@ -226,6 +227,49 @@ func TestAlertmanagerConfig(t *testing.T) {
 	})
 }
 
+func TestAlertmanagerConfigProvenance(t *testing.T) {
+	sut := createSut(t)
+	configStore := newFakeAlertingStore(t)
+	configStore.SetupConfig(1, validConfig)
+	sut.store = configStore
+	err := sut.provenanceStore.SetProvenance(context.Background(), ngmodels.ContactPointResource(1, "grafana-default-email"), ngmodels.ProvenanceFile)
+	require.NoError(t, err)
+
+	rc := models.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &models.SignedInUser{
+			OrgRole: models.ROLE_EDITOR,
+			OrgId:   1,
+		},
+	}
+
+	t.Run("assert 202 when provisioned contact points are unchanged", func(t *testing.T) {
+		request := createAmConfigRequest(t)
+
+		response := sut.RoutePostAlertingConfig(&rc, request)
+
+		require.Equal(t, 202, response.Status())
+	})
+
+	t.Run("assert 409 Conflict when provisioned contact points are changed", func(t *testing.T) {
+		request := createAmConfigRequest(t)
+		request.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0].Settings.Set("addresses", "other@example.com")
+
+		response := sut.RoutePostAlertingConfig(&rc, request)
+
+		require.Equal(t, 409, response.Status())
+		require.Contains(t, string(response.Body()), "grafana-default-email")
+	})
+
+	t.Run("assert 409 Conflict when the configuration with provisioned resources is deleted", func(t *testing.T) {
+		response := sut.RouteDeleteAlertingConfig(&rc)
+
+		require.Equal(t, 409, response.Status())
+	})
+}
+
 func createSut(t *testing.T) AlertmanagerSrv {
 	t.Helper()
 
@@ -235,7 +279,7 @@ func createSut(t *testing.T) AlertmanagerSrv {
 	store.Setup(2)
 	store.Setup(3)
 	secrets := fakes.NewFakeSecretsService()
-	return AlertmanagerSrv{mam: mam, store: store, secrets: secrets}
+	return AlertmanagerSrv{mam: mam, store: store, provenanceStore: ngstore.NewFakeProvisioningStore(), secrets: secrets}
 }
 
 func createAmConfigRequest(t *testing.T) apimodels.PostableUserConfig {
@@ -274,7 +318,7 @@ func createMultiOrgAlertmanager(t *testing.T) *notifier.MultiOrgAlertmanager {
 		}, // do not poll in tests.
 	}
 
-	mam, err := notifier.NewMultiOrgAlertmanager(cfg, &configStore, &orgStore, kvStore, decryptFn, m.GetMultiOrgAlertmanagerMetrics(), nil, log.New("testlogger"))
+	mam, err := notifier.NewMultiOrgAlertmanager(cfg, &configStore, &orgStore, nil, kvStore, decryptFn, m.GetMultiOrgAlertmanagerMetrics(), nil, log.New("testlogger"))
 	require.NoError(t, err)
 	t.Cleanup(cleanOrgDirectories(tmpDir, t))
 	err = mam.LoadAndSyncAlertmanagersForOrgs(context.Background())
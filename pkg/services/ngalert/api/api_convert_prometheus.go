@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ConvertPrometheusSrv implements the API for converting Grafana managed rules to and from the Prometheus rule file format.
+type ConvertPrometheusSrv struct {
+	log             log.Logger
+	cfg             *setting.UnifiedAlertingSettings
+	store           store.RuleStore
+	datasourceCache datasources.CacheService
+	quotaService    *quota.QuotaService
+}
+
+func (srv ConvertPrometheusSrv) RouteConvertPrometheusGetRules(c *models.ReqContext) response.Response {
+	namespaceMap, err := srv.store.GetNamespaces(c.Req.Context(), c.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	if title := c.Query("namespace"); title != "" {
+		namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), title, c.OrgId, c.SignedInUser, false)
+		if err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		namespaceMap = map[string]*models.Folder{namespace.Uid: namespace}
+	}
+	if len(namespaceMap) == 0 {
+		return srv.yamlResponse(apimodels.PrometheusNamespaces{})
+	}
+
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID:         c.OrgId,
+		NamespaceUIDs: make([]string, 0, len(namespaceMap)),
+	}
+	for uid := range namespaceMap {
+		q.NamespaceUIDs = append(q.NamespaceUIDs, uid)
+	}
+	if err := srv.store.GetOrgAlertRules(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+
+	type groupKey struct {
+		namespaceUID string
+		group        string
+	}
+	groupedRules := make(map[groupKey][]*ngmodels.AlertRule)
+	for _, r := range q.Result {
+		key := groupKey{namespaceUID: r.NamespaceUID, group: r.RuleGroup}
+		groupedRules[key] = append(groupedRules[key], r)
+	}
+
+	isPrometheus := srv.prometheusDatasources(c)
+	result := apimodels.PrometheusNamespaces{}
+	for key, rules := range groupedRules {
+		namespace, ok := namespaceMap[key.namespaceUID]
+		if !ok {
+			continue
+		}
+		sort.Slice(rules, func(i, j int) bool {
+			return rules[i].ID < rules[j].ID
+		})
+
+		group := apimodels.PrometheusRuleGroup{
+			Name:  key.group,
+			Rules: make([]apimodels.ApiRuleNode, 0, len(rules)),
+		}
+		for _, r := range rules {
+			node, err := prom.FromGrafanaRule(r, isPrometheus)
+			if err != nil {
+				srv.log.Debug("rule group cannot be exported", "namespace", namespace.Title, "group", key.group, "rule", r.UID, "err", err)
+				group.Rules = nil
+				break
+			}
+			group.Interval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
+			group.Rules = append(group.Rules, node)
+		}
+		if len(group.Rules) > 0 {
+			result[namespace.Title] = append(result[namespace.Title], group)
+		}
+	}
+	for _, groups := range result {
+		sort.Slice(groups, func(i, j int) bool {
+			return groups[i].Name < groups[j].Name
+		})
+	}
+	return srv.yamlResponse(result)
+}
+
+func (srv ConvertPrometheusSrv) RouteConvertPrometheusPostRules(c *models.ReqContext) response.Response {
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the rule file")
+	}
+	var file apimodels.PrometheusRuleFile
+	if err := yaml.Unmarshal(body, &file); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse the rule file")
+	}
+
+	namespaceTitle := c.Query("namespace")
+	if namespaceTitle == "" {
+		namespaceTitle = file.Namespace
+	}
+	if namespaceTitle == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("namespace is not set in the rule file or in the request"), "")
+	}
+
+	datasourceUID := c.Query("datasourceUID")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("datasourceUID is required"), "")
+	}
+	ds, err := srv.datasourceCache.GetDatasourceByUID(c.Req.Context(), datasourceUID, c.SignedInUser, c.SkipCache)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get data source")
+	}
+	if ds.Type != prom.DatasourceType {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("data source %s is of type %s, not %s", datasourceUID, ds.Type, prom.DatasourceType), "")
+	}
+
+	namespace, err := srv.store.GetNamespaceByTitle(c.Req.Context(), namespaceTitle, c.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	cmd := prom.ImportCommand{
+		OrgID:           c.OrgId,
+		NamespaceUID:    namespace.Uid,
+		DatasourceUID:   datasourceUID,
+		Groups:          file.Groups,
+		BaseInterval:    srv.cfg.BaseInterval,
+		DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
+		DryRun:          c.QueryBool("dryRun"),
+	}
+	var report apimodels.PrometheusImportReport
+	err = srv.store.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		var err error
+		if report, err = prom.Import(ctx, srv.store, cmd); err != nil {
+			return err
+		}
+		if cmd.DryRun || len(report.Imported) == 0 {
+			return nil
+		}
+		limitReached, err := srv.quotaService.CheckQuotaReached(ctx, "alert_rule", &quota.ScopeParameters{
+			OrgId:  c.OrgId,
+			UserId: c.UserId,
+		}) // alert rule is table name
+		if err != nil {
+			return fmt.Errorf("failed to get alert rules quota: %w", err)
+		}
+		if limitReached {
+			return errQuotaReached
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+			return ErrResp(http.StatusBadRequest, err, "failed to import the rule file")
+		} else if errors.Is(err, errQuotaReached) {
+			return ErrResp(http.StatusForbidden, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to import the rule file")
+	}
+
+	srv.log.Info("imported Prometheus rule file", "namespace", namespace.Title, "imported", len(report.Imported), "failed", len(report.Failed), "dryRun", cmd.DryRun)
+	return response.JSON(http.StatusOK, report)
+}
+
+// prometheusDatasources returns a function that checks if a data source is a Prometheus data source. The data sources
+// are looked up once per request.
+func (srv ConvertPrometheusSrv) prometheusDatasources(c *models.ReqContext) func(string) bool {
+	types := make(map[string]bool)
+	return func(uid string) bool {
+		if isPrometheus, ok := types[uid]; ok {
+			return isPrometheus
+		}
+		ds, err := srv.datasourceCache.GetDatasourceByUID(c.Req.Context(), uid, c.SignedInUser, c.SkipCache)
+		types[uid] = err == nil && ds.Type == prom.DatasourceType
+		return types[uid]
+	}
+}
+
+func (srv ConvertPrometheusSrv) yamlResponse(namespaces apimodels.PrometheusNamespaces) response.Response {
+	body, err := yaml.Marshal(namespaces)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal the rule groups")
+	}
+	return response.Respond(http.StatusOK, body).SetHeader("Content-Type", "application/yaml")
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const testRuleFile = `
+namespace: team
+groups:
+  - name: api
+    interval: 1m
+    rules:
+      - alert: HighErrorRate
+        expr: rate(errors_total[5m]) > 1
+        for: 5m
+        labels:
+          severity: critical
+      - alert: Invalid
+        expr: sum(up
+`
+
+func TestRouteConvertPrometheusPostRules(t *testing.T) {
+	ruleStore := &convertRuleStore{FakeRuleStore: store.NewFakeRuleStore(t)}
+	srv := createConvertPrometheusSrv(ruleStore)
+
+	t.Run("reports the rules that can be imported", func(t *testing.T) {
+		response := srv.RouteConvertPrometheusPostRules(createConvertRequestCtx(t, http.MethodPost, "datasourceUID=prom&dryRun=true", testRuleFile))
+		require.Equal(t, http.StatusOK, response.Status())
+
+		report := apimodels.PrometheusImportReport{}
+		require.NoError(t, json.Unmarshal(response.Body(), &report))
+		require.Equal(t, []apimodels.PrometheusRuleStatus{{Group: "api", Rule: "HighErrorRate"}}, report.Imported)
+		require.Len(t, report.Failed, 1)
+		require.Equal(t, "Invalid", report.Failed[0].Rule)
+		require.Equal(t, "team", ruleStore.namespaceTitle)
+		for _, op := range ruleStore.RecordedOps {
+			require.IsType(t, ngmodels.ListNamespaceAlertRulesQuery{}, op)
+		}
+	})
+
+	t.Run("uses the namespace of the request", func(t *testing.T) {
+		response := srv.RouteConvertPrometheusPostRules(createConvertRequestCtx(t, http.MethodPost, "datasourceUID=prom&dryRun=true&namespace=other", testRuleFile))
+		require.Equal(t, http.StatusOK, response.Status())
+		require.Equal(t, "other", ruleStore.namespaceTitle)
+	})
+
+	testCases := []struct {
+		desc  string
+		query string
+		body  string
+	}{
+		{desc: "the rule file is not valid", query: "datasourceUID=prom", body: "groups: {"},
+		{desc: "the namespace is not set", query: "datasourceUID=prom", body: "groups: []"},
+		{desc: "the data source is not set", query: "", body: testRuleFile},
+		{desc: "the data source is not a Prometheus data source", query: "datasourceUID=loki", body: testRuleFile},
+		{desc: "the data source does not exist", query: "datasourceUID=unknown", body: testRuleFile},
+	}
+	for _, tc := range testCases {
+		t.Run("returns 400 if "+tc.desc, func(t *testing.T) {
+			response := srv.RouteConvertPrometheusPostRules(createConvertRequestCtx(t, http.MethodPost, tc.query, tc.body))
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+	}
+}
+
+func TestRouteConvertPrometheusGetRules(t *testing.T) {
+	ruleStore := &convertRuleStore{FakeRuleStore: store.NewFakeRuleStore(t)}
+	srv := createConvertPrometheusSrv(ruleStore)
+
+	for i, node := range []apimodels.ApiRuleNode{
+		{Alert: "HighErrorRate", Expr: "rate(errors_total[5m]) > 1"},
+		{Record: "job:errors:rate5m", Expr: "sum by (job) (rate(errors_total[5m]))"},
+	} {
+		rule, err := prom.ToGrafanaRule(1, "team", "api", time.Minute, "prom", node)
+		require.NoError(t, err)
+		rule.ID = int64(i + 1)
+		rule.UID = node.Alert + node.Record
+		ruleStore.PutRule(context.Background(), rule)
+	}
+	other, err := prom.ToGrafanaRule(1, "team", "loki", time.Minute, "loki", apimodels.ApiRuleNode{Alert: "Logs", Expr: "up"})
+	require.NoError(t, err)
+	ruleStore.PutRule(context.Background(), other)
+
+	response := srv.RouteConvertPrometheusGetRules(createConvertRequestCtx(t, http.MethodGet, "", ""))
+	require.Equal(t, http.StatusOK, response.Status())
+
+	result := apimodels.PrometheusNamespaces{}
+	require.NoError(t, yaml.Unmarshal(response.Body(), &result))
+	require.Len(t, result["team"], 1)
+	group := result["team"][0]
+	require.Equal(t, "api", group.Name)
+	require.Equal(t, time.Minute, time.Duration(group.Interval))
+	require.Len(t, group.Rules, 2)
+	require.Equal(t, "HighErrorRate", group.Rules[0].Alert)
+	require.Equal(t, "job:errors:rate5m", group.Rules[1].Record)
+}
+
+// convertRuleStore resolves the folders of the fake rule store by their title.
+type convertRuleStore struct {
+	*store.FakeRuleStore
+	namespaceTitle string
+}
+
+func (f *convertRuleStore) GetNamespaces(ctx context.Context, orgID int64, user *models.SignedInUser) (map[string]*models.Folder, error) {
+	namespaces, err := f.FakeRuleStore.GetNamespaces(ctx, orgID, user)
+	for uid, namespace := range namespaces {
+		namespace.Uid = uid
+		namespace.Title = uid
+	}
+	return namespaces, err
+}
+
+func (f *convertRuleStore) GetNamespaceByTitle(_ context.Context, title string, _ int64, _ *models.SignedInUser, _ bool) (*models.Folder, error) {
+	f.namespaceTitle = title
+	return &models.Folder{Uid: title, Title: title}, nil
+}
+
+type fakeDatasourceCache struct {
+	datasources map[string]*models.DataSource
+}
+
+func (f *fakeDatasourceCache) GetDatasource(_ context.Context, _ int64, _ *models.SignedInUser, _ bool) (*models.DataSource, error) {
+	return nil, models.ErrDataSourceNotFound
+}
+
+func (f *fakeDatasourceCache) GetDatasourceByUID(_ context.Context, uid string, _ *models.SignedInUser, _ bool) (*models.DataSource, error) {
+	if ds, ok := f.datasources[uid]; ok {
+		return ds, nil
+	}
+	return nil, models.ErrDataSourceNotFound
+}
+
+func createConvertPrometheusSrv(ruleStore store.RuleStore) ConvertPrometheusSrv {
+	return ConvertPrometheusSrv{
+		log: log.NewNopLogger(),
+		cfg: &setting.UnifiedAlertingSettings{
+			BaseInterval:                  10 * time.Second,
+			DefaultRuleEvaluationInterval: time.Minute,
+		},
+		store: ruleStore,
+		datasourceCache: &fakeDatasourceCache{datasources: map[string]*models.DataSource{
+			"prom": {Uid: "prom", Type: "prometheus"},
+			"loki": {Uid: "loki", Type: "loki"},
+		}},
+	}
+}
+
+func createConvertRequestCtx(t *testing.T, method, query, body string) *models.ReqContext {
+	t.Helper()
+	u, err := url.Parse("/api/convert/prometheus/config/v1/rules?" + query)
+	require.NoError(t, err)
+	return &models.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{Method: method, URL: u, Body: io.NopCloser(strings.NewReader(body))},
+		},
+		SignedInUser: &models.SignedInUser{
+			OrgRole: models.ROLE_EDITOR,
+			OrgId:   1,
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// defaultStateHistoryLimit is the number of transitions returned when the request does not set a limit.
+const defaultStateHistoryLimit = 1000
+
+// HistorySrv implements the API for querying the state history of the alert instances.
+type HistorySrv struct {
+	log        log.Logger
+	store      store.StateHistoryStore
+	namespaces NamespaceVisibilityStore
+}
+
+type NamespaceVisibilityStore interface {
+	GetNamespaces(ctx context.Context, orgID int64, user *models.SignedInUser) (map[string]*models.Folder, error)
+}
+
+func (srv HistorySrv) RouteGetStateHistory(c *models.ReqContext) response.Response {
+	matchers := make(labels.Matchers, 0)
+	for _, s := range c.QueryStrings("filter") {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid filter")
+		}
+		matchers = append(matchers, m)
+	}
+
+	namespaceMap, err := srv.namespaces.GetNamespaces(c.Req.Context(), c.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	namespaceUIDs := make([]string, 0, len(namespaceMap))
+	for uid := range namespaceMap {
+		namespaceUIDs = append(namespaceUIDs, uid)
+	}
+
+	q := ngmodels.GetStateHistoryQuery{
+		OrgID:         c.OrgId,
+		RuleUID:       c.Query("ruleUID"),
+		NamespaceUIDs: namespaceUIDs,
+		Matchers:      matchers,
+		Limit:         c.QueryInt("limit"),
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		q.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		q.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultStateHistoryLimit
+	}
+
+	if err := srv.store.GetStateHistory(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the state history")
+	}
+
+	result := make(apimodels.StateHistory, 0, len(q.Result))
+	for _, entry := range q.Result {
+		result = append(result, apimodels.StateTransition{
+			RuleUID:       entry.RuleUID,
+			Labels:        entry.Labels,
+			PreviousState: string(entry.PreviousState),
+			State:         string(entry.State),
+			Values:        entry.Values,
+			EvaluatedAt:   entry.EvaluatedAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestRouteGetStateHistory(t *testing.T) {
+	evaluatedAt := time.Unix(1000, 0)
+	history := &fakeStateHistoryStore{
+		entries: []*ngmodels.AlertStateHistoryEntry{{
+			OrgID:         1,
+			RuleUID:       "rule",
+			Labels:        ngmodels.InstanceLabels{"team": "ops"},
+			PreviousState: ngmodels.InstanceStateNormal,
+			State:         ngmodels.InstanceStateFiring,
+			Values:        map[string]float64{"B": 1},
+			EvaluatedAt:   evaluatedAt,
+		}},
+	}
+	srv := HistorySrv{
+		log:        log.NewNopLogger(),
+		store:      history,
+		namespaces: &fakeNamespaceVisibilityStore{uids: []string{"folder"}},
+	}
+
+	t.Run("returns the transitions of the rules visible to the user", func(t *testing.T) {
+		response := srv.RouteGetStateHistory(createHistoryRequestCtx(t, "ruleUID=rule&filter=team%3Dops&from=1000&to=2000"))
+		require.Equal(t, http.StatusOK, response.Status())
+
+		result := apimodels.StateHistory{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 1)
+		require.Equal(t, "rule", result[0].RuleUID)
+		require.Equal(t, map[string]string{"team": "ops"}, result[0].Labels)
+		require.Equal(t, "Normal", result[0].PreviousState)
+		require.Equal(t, "Alerting", result[0].State)
+		require.Equal(t, map[string]float64{"B": 1}, result[0].Values)
+		require.True(t, evaluatedAt.Equal(result[0].EvaluatedAt))
+
+		q := history.lastQuery
+		require.Equal(t, int64(1), q.OrgID)
+		require.Equal(t, "rule", q.RuleUID)
+		require.Equal(t, []string{"folder"}, q.NamespaceUIDs)
+		require.Len(t, q.Matchers, 1)
+		require.Equal(t, `team="ops"`, q.Matchers[0].String())
+		require.Equal(t, time.Unix(1, 0), q.From)
+		require.Equal(t, time.Unix(2, 0), q.To)
+		require.Equal(t, defaultStateHistoryLimit, q.Limit)
+	})
+
+	t.Run("uses the limit of the request", func(t *testing.T) {
+		response := srv.RouteGetStateHistory(createHistoryRequestCtx(t, "limit=5"))
+		require.Equal(t, http.StatusOK, response.Status())
+		require.Equal(t, 5, history.lastQuery.Limit)
+		require.True(t, history.lastQuery.From.IsZero())
+	})
+
+	t.Run("returns 400 for an invalid filter", func(t *testing.T) {
+		response := srv.RouteGetStateHistory(createHistoryRequestCtx(t, "filter=team"))
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
+
+type fakeStateHistoryStore struct {
+	entries   []*ngmodels.AlertStateHistoryEntry
+	lastQuery ngmodels.GetStateHistoryQuery
+}
+
+func (f *fakeStateHistoryStore) SaveStateHistory(_ context.Context, entries []*ngmodels.AlertStateHistoryEntry) error {
+	f.entries = append(f.entries, entries...)
+	return nil
+}
+
+func (f *fakeStateHistoryStore) GetStateHistory(_ context.Context, query *ngmodels.GetStateHistoryQuery) error {
+	f.lastQuery = *query
+	query.Result = f.entries
+	return nil
+}
+
+func (f *fakeStateHistoryStore) DeleteStateHistoryBefore(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+type fakeNamespaceVisibilityStore struct {
+	uids []string
+}
+
+func (f *fakeNamespaceVisibilityStore) GetNamespaces(_ context.Context, _ int64, _ *models.SignedInUser) (map[string]*models.Folder, error) {
+	result := make(map[string]*models.Folder, len(f.uids))
+	for _, uid := range f.uids {
+		result[uid] = &models.Folder{Uid: uid}
+	}
+	return result, nil
+}
+
+func createHistoryRequestCtx(t *testing.T, query string) *models.ReqContext {
+	t.Helper()
+	u, err := url.Parse("/api/v1/rules/history?" + query)
+	require.NoError(t, err)
+	return &models.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{URL: u},
+		},
+		SignedInUser: &models.SignedInUser{
+			OrgRole: models.ROLE_VIEWER,
+			OrgId:   1,
+		},
+	}
+}
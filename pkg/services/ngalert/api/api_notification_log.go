@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// defaultNotificationLogLimit is the number of attempts returned when the request does not set a limit.
+const defaultNotificationLogLimit = 1000
+
+// NotificationLogSrv implements the API for querying the notification attempts of the contact points
+// and the queue of failed notifications.
+type NotificationLogSrv struct {
+	log   log.Logger
+	store store.NotificationLogStore
+}
+
+func (srv NotificationLogSrv) RouteGetNotificationLog(c *models.ReqContext) response.Response {
+	q := ngmodels.GetNotificationLogQuery{
+		OrgID:          c.OrgId,
+		Receiver:       c.Query("receiver"),
+		IntegrationUID: c.Query("integrationUID"),
+		Status:         ngmodels.NotificationStatus(c.Query("status")),
+		Limit:          c.QueryInt("limit"),
+	}
+	switch q.Status {
+	case "", ngmodels.NotificationStatusSuccess, ngmodels.NotificationStatusFailed:
+	default:
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("status should be %s or %s", ngmodels.NotificationStatusSuccess, ngmodels.NotificationStatusFailed), "invalid status")
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		q.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		q.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultNotificationLogLimit
+	}
+
+	if err := srv.store.GetNotificationLog(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the notification log")
+	}
+
+	result := make(apimodels.NotificationLog, 0, len(q.Result))
+	for _, entry := range q.Result {
+		result = append(result, apimodels.NotificationAttempt{
+			ID:              entry.ID,
+			Receiver:        entry.Receiver,
+			IntegrationUID:  entry.IntegrationUID,
+			IntegrationName: entry.IntegrationName,
+			IntegrationType: entry.IntegrationType,
+			GroupKey:        entry.GroupKey,
+			GroupLabels:     entry.Payload.GroupLabels,
+			Firing:          entry.Payload.Firing,
+			Resolved:        entry.Payload.Resolved,
+			AlertNames:      entry.Payload.AlertNames,
+			Status:          string(entry.Status),
+			Error:           entry.Error,
+			Attempt:         entry.Attempt,
+			DurationMs:      entry.Duration.Milliseconds(),
+			SentAt:          entry.SentAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv NotificationLogSrv) RouteGetNotificationRetries(c *models.ReqContext) response.Response {
+	q := ngmodels.GetNotificationRetriesQuery{OrgID: c.OrgId}
+	if err := srv.store.GetNotificationRetries(c.Req.Context(), &q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the notification retries")
+	}
+
+	result := make(apimodels.NotificationRetries, 0, len(q.Result))
+	for _, retry := range q.Result {
+		result = append(result, apimodels.NotificationRetry{
+			Receiver:       retry.Receiver,
+			IntegrationUID: retry.IntegrationUID,
+			GroupKey:       retry.GroupKey,
+			GroupLabels:    retry.GroupLabels,
+			Alerts:         len(retry.Alerts),
+			Attempts:       retry.Attempts,
+			LastError:      retry.LastError,
+			NextAttemptAt:  retry.NextAttemptAt,
+			CreatedAt:      retry.CreatedAt,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
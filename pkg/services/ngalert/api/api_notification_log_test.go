@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestRouteGetNotificationLog(t *testing.T) {
+	sentAt := time.Unix(1000, 0)
+	logStore := &fakeNotificationLogStore{
+		entries: []*ngmodels.NotificationLogEntry{{
+			ID:              1,
+			OrgID:           1,
+			Receiver:        "ops",
+			IntegrationUID:  "slack-uid",
+			IntegrationName: "ops slack",
+			IntegrationType: "slack",
+			GroupKey:        "{}:{}",
+			Payload: ngmodels.NotificationPayloadSummary{
+				GroupLabels: map[string]string{"team": "ops"},
+				Firing:      2,
+				AlertNames:  []string{"HighCPU"},
+			},
+			Status:   ngmodels.NotificationStatusFailed,
+			Error:    "unexpected status code 503",
+			Attempt:  1,
+			Duration: 1500 * time.Millisecond,
+			SentAt:   sentAt,
+		}},
+	}
+	srv := NotificationLogSrv{log: log.NewNopLogger(), store: logStore}
+
+	t.Run("returns the notification attempts", func(t *testing.T) {
+		response := srv.RouteGetNotificationLog(createNotificationLogRequestCtx(t, "receiver=ops&integrationUID=slack-uid&status=failed&from=1000&to=2000"))
+		require.Equal(t, http.StatusOK, response.Status())
+
+		result := apimodels.NotificationLog{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 1)
+		require.Equal(t, "ops", result[0].Receiver)
+		require.Equal(t, "slack", result[0].IntegrationType)
+		require.Equal(t, map[string]string{"team": "ops"}, result[0].GroupLabels)
+		require.Equal(t, 2, result[0].Firing)
+		require.Equal(t, []string{"HighCPU"}, result[0].AlertNames)
+		require.Equal(t, "failed", result[0].Status)
+		require.Equal(t, "unexpected status code 503", result[0].Error)
+		require.Equal(t, int64(1500), result[0].DurationMs)
+		require.True(t, sentAt.Equal(result[0].SentAt))
+
+		q := logStore.lastQuery
+		require.Equal(t, int64(1), q.OrgID)
+		require.Equal(t, "ops", q.Receiver)
+		require.Equal(t, "slack-uid", q.IntegrationUID)
+		require.Equal(t, ngmodels.NotificationStatusFailed, q.Status)
+		require.Equal(t, time.Unix(1, 0), q.From)
+		require.Equal(t, time.Unix(2, 0), q.To)
+		require.Equal(t, defaultNotificationLogLimit, q.Limit)
+	})
+
+	t.Run("uses the limit of the request", func(t *testing.T) {
+		response := srv.RouteGetNotificationLog(createNotificationLogRequestCtx(t, "limit=5"))
+		require.Equal(t, http.StatusOK, response.Status())
+		require.Equal(t, 5, logStore.lastQuery.Limit)
+	})
+
+	t.Run("returns 400 for an invalid status", func(t *testing.T) {
+		response := srv.RouteGetNotificationLog(createNotificationLogRequestCtx(t, "status=unknown"))
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
+
+func TestRouteGetNotificationRetries(t *testing.T) {
+	nextAttemptAt := time.Unix(2000, 0)
+	logStore := &fakeNotificationLogStore{
+		retries: []*ngmodels.NotificationRetry{{
+			OrgID:          1,
+			Receiver:       "ops",
+			IntegrationUID: "slack-uid",
+			GroupKey:       "{}:{}",
+			Alerts:         []*types.Alert{{}, {}},
+			Attempts:       2,
+			LastError:      "unexpected status code 503",
+			NextAttemptAt:  nextAttemptAt,
+		}},
+	}
+	srv := NotificationLogSrv{log: log.NewNopLogger(), store: logStore}
+
+	response := srv.RouteGetNotificationRetries(createNotificationLogRequestCtx(t, ""))
+	require.Equal(t, http.StatusOK, response.Status())
+
+	result := apimodels.NotificationRetries{}
+	require.NoError(t, json.Unmarshal(response.Body(), &result))
+	require.Len(t, result, 1)
+	require.Equal(t, "slack-uid", result[0].IntegrationUID)
+	require.Equal(t, 2, result[0].Alerts)
+	require.Equal(t, 2, result[0].Attempts)
+	require.True(t, nextAttemptAt.Equal(result[0].NextAttemptAt))
+}
+
+type fakeNotificationLogStore struct {
+	entries   []*ngmodels.NotificationLogEntry
+	retries   []*ngmodels.NotificationRetry
+	lastQuery ngmodels.GetNotificationLogQuery
+}
+
+func (f *fakeNotificationLogStore) SaveNotificationLog(_ context.Context, entry *ngmodels.NotificationLogEntry) error {
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func (f *fakeNotificationLogStore) GetNotificationLog(_ context.Context, query *ngmodels.GetNotificationLogQuery) error {
+	f.lastQuery = *query
+	query.Result = f.entries
+	return nil
+}
+
+func (f *fakeNotificationLogStore) DeleteNotificationLogBefore(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeNotificationLogStore) SaveNotificationRetry(_ context.Context, retry *ngmodels.NotificationRetry) error {
+	f.retries = append(f.retries, retry)
+	return nil
+}
+
+func (f *fakeNotificationLogStore) GetNotificationRetries(_ context.Context, query *ngmodels.GetNotificationRetriesQuery) error {
+	query.Result = f.retries
+	return nil
+}
+
+func (f *fakeNotificationLogStore) ClaimNotificationRetry(_ context.Context, _ *ngmodels.NotificationRetry, _ time.Time) (bool, error) {
+	return false, errors.New("not implemented")
+}
+
+func (f *fakeNotificationLogStore) DeleteNotificationRetry(_ context.Context, _ *ngmodels.NotificationRetry) error {
+	return errors.New("not implemented")
+}
+
+func createNotificationLogRequestCtx(t *testing.T, query string) *models.ReqContext {
+	t.Helper()
+	u, err := url.Parse("/api/v1/notifications/log?" + query)
+	require.NoError(t, err)
+	return &models.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{URL: u},
+		},
+		SignedInUser: &models.SignedInUser{
+			OrgRole: models.ROLE_VIEWER,
+			OrgId:   1,
+		},
+	}
+}
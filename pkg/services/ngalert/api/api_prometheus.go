@@ -196,6 +196,8 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 				newRule.Health = "error"
 			case eval.NoData:
 				newRule.Health = "nodata"
+			case eval.DependencyFailed:
+				newRule.Health = "dependencyfailed"
 			}
 
 			if alertState.Error != nil {
@@ -0,0 +1,476 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	amconfig "github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// ProvisioningSrv implements the fine-grained API for provisioning alert rules, contact points,
+// notification policies, mute timings and templates. The objects it changes get the API provenance.
+type ProvisioningSrv struct {
+	log           log.Logger
+	alertRules    AlertRuleService
+	contactPoints ContactPointService
+	policies      NotificationPolicyService
+	muteTimings   MuteTimingService
+	templates     TemplateService
+	namespaces    NamespaceStore
+}
+
+type AlertRuleService interface {
+	GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (ngmodels.AlertRule, ngmodels.Provenance, error)
+	CreateAlertRule(ctx context.Context, rule ngmodels.AlertRule, provenance ngmodels.Provenance) (ngmodels.AlertRule, error)
+	UpdateAlertRule(ctx context.Context, rule ngmodels.AlertRule, provenance ngmodels.Provenance) (ngmodels.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, orgID int64, ruleUID string, provenance ngmodels.Provenance) error
+}
+
+type ContactPointService interface {
+	GetContactPoints(ctx context.Context, orgID int64) ([]provisioning.ContactPoint, error)
+	UpsertContactPoint(ctx context.Context, orgID int64, cp provisioning.ContactPoint, provenance ngmodels.Provenance) error
+	DeleteContactPoint(ctx context.Context, orgID int64, name string, provenance ngmodels.Provenance) error
+}
+
+type NotificationPolicyService interface {
+	GetPolicyTree(ctx context.Context, orgID int64) (apimodels.Route, ngmodels.Provenance, error)
+	UpdatePolicyTree(ctx context.Context, orgID int64, tree apimodels.Route, provenance ngmodels.Provenance) error
+	ResetPolicyTree(ctx context.Context, orgID int64, provenance ngmodels.Provenance) error
+	GetPolicy(ctx context.Context, orgID int64, path []int) (apimodels.Route, ngmodels.Provenance, error)
+	AddPolicy(ctx context.Context, orgID int64, parentPath []int, policy apimodels.Route, provenance ngmodels.Provenance) error
+	UpdatePolicy(ctx context.Context, orgID int64, path []int, policy apimodels.Route, provenance ngmodels.Provenance) error
+	DeletePolicy(ctx context.Context, orgID int64, path []int, provenance ngmodels.Provenance) error
+}
+
+type MuteTimingService interface {
+	GetMuteTimings(ctx context.Context, orgID int64) ([]provisioning.MuteTiming, error)
+	UpsertMuteTiming(ctx context.Context, orgID int64, mt amconfig.MuteTimeInterval, provenance ngmodels.Provenance) error
+	DeleteMuteTiming(ctx context.Context, orgID int64, name string, provenance ngmodels.Provenance) error
+}
+
+type TemplateService interface {
+	GetTemplates(ctx context.Context, orgID int64) ([]provisioning.MessageTemplate, error)
+	SetTemplate(ctx context.Context, orgID int64, tmpl provisioning.MessageTemplate, provenance ngmodels.Provenance) error
+	DeleteTemplate(ctx context.Context, orgID int64, name string, provenance ngmodels.Provenance) error
+}
+
+// NamespaceStore finds the folders of the provisioned alert rules.
+type NamespaceStore interface {
+	GetNamespaceByUID(ctx context.Context, uid string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error)
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertRule(c *models.ReqContext) response.Response {
+	rule, provenance, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.OrgId, web.Params(c.Req)[":UID"])
+	if err != nil {
+		return srv.errResp(err, "failed to get alert rule")
+	}
+	if _, err := srv.namespaces.GetNamespaceByUID(c.Req.Context(), rule.NamespaceUID, c.OrgId, c.SignedInUser, false); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, apimodels.NewAlertRule(rule, provenance))
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	rule, resp := srv.toAlertRule(c, body)
+	if resp != nil {
+		return resp
+	}
+
+	created, err := srv.alertRules.CreateAlertRule(c.Req.Context(), rule, ngmodels.ProvenanceApi)
+	if err != nil {
+		return srv.errResp(err, "failed to create alert rule")
+	}
+	return response.JSON(http.StatusCreated, apimodels.NewAlertRule(created, ngmodels.ProvenanceApi))
+}
+
+func (srv *ProvisioningSrv) RoutePutAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	body.UID = web.Params(c.Req)[":UID"]
+	if resp := srv.checkExistingAlertRule(c, body.UID); resp != nil {
+		return resp
+	}
+	rule, resp := srv.toAlertRule(c, body)
+	if resp != nil {
+		return resp
+	}
+
+	updated, err := srv.alertRules.UpdateAlertRule(c.Req.Context(), rule, ngmodels.ProvenanceApi)
+	if err != nil {
+		return srv.errResp(err, "failed to update alert rule")
+	}
+	return response.JSON(http.StatusOK, apimodels.NewAlertRule(updated, ngmodels.ProvenanceApi))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	uid := web.Params(c.Req)[":UID"]
+	if resp := srv.checkExistingAlertRule(c, uid); resp != nil {
+		return resp
+	}
+	err := srv.alertRules.DeleteAlertRule(c.Req.Context(), c.OrgId, uid, ngmodels.ProvenanceApi)
+	if err != nil {
+		return srv.errResp(err, "failed to delete alert rule")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+// checkExistingAlertRule checks that the alert rule exists, and that the user can save rules in its
+// current folder.
+func (srv *ProvisioningSrv) checkExistingAlertRule(c *models.ReqContext, uid string) response.Response {
+	existing, _, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.OrgId, uid)
+	if err != nil {
+		return srv.errResp(err, "failed to get alert rule")
+	}
+	if _, err := srv.namespaces.GetNamespaceByUID(c.Req.Context(), existing.NamespaceUID, c.OrgId, c.SignedInUser, true); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	return nil
+}
+
+// toAlertRule validates the payload of an alert rule, and checks that the user can save rules in its folder.
+func (srv *ProvisioningSrv) toAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) (ngmodels.AlertRule, response.Response) {
+	rule := body.UpstreamModel()
+	rule.ID = 0
+	rule.OrgID = c.OrgId
+	if rule.NamespaceUID == "" || rule.RuleGroup == "" || rule.Title == "" || rule.Condition == "" || len(rule.Data) == 0 {
+		return rule, ErrResp(http.StatusBadRequest, errors.New("folderUID, ruleGroup, title, condition and data are required"), "")
+	}
+
+	var err error
+	if rule.NoDataState == "" {
+		rule.NoDataState = ngmodels.NoData
+	} else if rule.NoDataState, err = ngmodels.NoDataStateFromString(string(rule.NoDataState)); err != nil {
+		return rule, ErrResp(http.StatusBadRequest, err, "")
+	}
+	if rule.ExecErrState == "" {
+		rule.ExecErrState = ngmodels.AlertingErrState
+	} else if rule.ExecErrState, err = ngmodels.ErrStateFromString(string(rule.ExecErrState)); err != nil {
+		return rule, ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	if _, err := srv.namespaces.GetNamespaceByUID(c.Req.Context(), rule.NamespaceUID, c.OrgId, c.SignedInUser, true); err != nil {
+		return rule, toNamespaceErrorResponse(err)
+	}
+	return rule, nil
+}
+
+func (srv *ProvisioningSrv) RouteGetContactpoints(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	contactPoints, err := srv.contactPoints.GetContactPoints(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return srv.errResp(err, "failed to get contact points")
+	}
+
+	result := make(apimodels.ContactPoints, 0, len(contactPoints))
+	for _, cp := range contactPoints {
+		gettable := apimodels.GettableContactPoint{
+			Name:         cp.Name,
+			Integrations: make([]*apimodels.GettableGrafanaReceiver, 0, len(cp.Integrations)),
+			Provenance:   cp.Provenance,
+		}
+		// secure settings are only reported as set, like in the Alertmanager configuration API
+		for _, integration := range cp.Integrations {
+			secureFields := make(map[string]bool, len(integration.SecureSettings))
+			for k := range integration.SecureSettings {
+				secureFields[k] = true
+			}
+			gettable.Integrations = append(gettable.Integrations, &apimodels.GettableGrafanaReceiver{
+				UID:                   integration.UID,
+				Name:                  integration.Name,
+				Type:                  integration.Type,
+				DisableResolveMessage: integration.DisableResolveMessage,
+				Settings:              integration.Settings,
+				SecureFields:          secureFields,
+			})
+		}
+		result = append(result, gettable)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RoutePutContactpoint(c *models.ReqContext, body apimodels.PostableContactPoint) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	cp := provisioning.ContactPoint{
+		Name:         web.Params(c.Req)[":Name"],
+		Integrations: body.Integrations,
+	}
+	if err := srv.contactPoints.UpsertContactPoint(c.Req.Context(), c.OrgId, cp, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to save contact point")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "contact point saved"})
+}
+
+func (srv *ProvisioningSrv) RouteDeleteContactpoint(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	err := srv.contactPoints.DeleteContactPoint(c.Req.Context(), c.OrgId, web.Params(c.Req)[":Name"], ngmodels.ProvenanceApi)
+	if err != nil {
+		return srv.errResp(err, "failed to delete contact point")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+func (srv *ProvisioningSrv) RouteGetPolicyTree(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	tree, provenance, err := srv.policies.GetPolicyTree(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return srv.errResp(err, "failed to get notification policies")
+	}
+	tree.Provenance = provenance
+	return response.JSON(http.StatusOK, tree)
+}
+
+func (srv *ProvisioningSrv) RoutePutPolicyTree(c *models.ReqContext, body apimodels.Route) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	if err := srv.policies.UpdatePolicyTree(c.Req.Context(), c.OrgId, body, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to save notification policies")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policies updated"})
+}
+
+func (srv *ProvisioningSrv) RouteResetPolicyTree(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	if err := srv.policies.ResetPolicyTree(c.Req.Context(), c.OrgId, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to reset notification policies")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policies reset"})
+}
+
+func (srv *ProvisioningSrv) RouteGetPolicy(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	path, resp := policyPath(c)
+	if resp != nil {
+		return resp
+	}
+	policy, provenance, err := srv.policies.GetPolicy(c.Req.Context(), c.OrgId, path)
+	if err != nil {
+		return srv.errResp(err, "failed to get notification policy")
+	}
+	policy.Provenance = provenance
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (srv *ProvisioningSrv) RoutePostPolicy(c *models.ReqContext, body apimodels.Route) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	if err := srv.policies.AddPolicy(c.Req.Context(), c.OrgId, nil, body, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to add notification policy")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policy added"})
+}
+
+func (srv *ProvisioningSrv) RoutePostNestedPolicy(c *models.ReqContext, body apimodels.Route) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	path, resp := policyPath(c)
+	if resp != nil {
+		return resp
+	}
+	if err := srv.policies.AddPolicy(c.Req.Context(), c.OrgId, path, body, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to add notification policy")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policy added"})
+}
+
+func (srv *ProvisioningSrv) RoutePutPolicy(c *models.ReqContext, body apimodels.Route) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	path, resp := policyPath(c)
+	if resp != nil {
+		return resp
+	}
+	if err := srv.policies.UpdatePolicy(c.Req.Context(), c.OrgId, path, body, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to update notification policy")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policy updated"})
+}
+
+func (srv *ProvisioningSrv) RouteDeletePolicy(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	path, resp := policyPath(c)
+	if resp != nil {
+		return resp
+	}
+	if err := srv.policies.DeletePolicy(c.Req.Context(), c.OrgId, path, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to delete notification policy")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+// policyPath parses the path of a nested notification policy, the dot separated indexes of the
+// nested policies from the root policy, e.g. "0.2".
+func policyPath(c *models.ReqContext) ([]int, response.Response) {
+	raw := web.Params(c.Req)[":Path"]
+	parts := strings.Split(raw, ".")
+	path := make([]int, 0, len(parts))
+	for _, part := range parts {
+		i, err := strconv.Atoi(part)
+		if err != nil || i < 0 {
+			return nil, ErrResp(http.StatusBadRequest, fmt.Errorf("invalid notification policy path %q", raw), "")
+		}
+		path = append(path, i)
+	}
+	return path, nil
+}
+
+func (srv *ProvisioningSrv) RouteGetMuteTimings(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return srv.errResp(err, "failed to get mute timings")
+	}
+	result := make(apimodels.MuteTimings, 0, len(timings))
+	for _, mt := range timings {
+		result = append(result, apimodels.MuteTimeInterval{MuteTimeInterval: mt.MuteTimeInterval, Provenance: mt.Provenance})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetMuteTiming(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	name := web.Params(c.Req)[":Name"]
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return srv.errResp(err, "failed to get mute timings")
+	}
+	for _, mt := range timings {
+		if mt.Name == name {
+			return response.JSON(http.StatusOK, apimodels.MuteTimeInterval{MuteTimeInterval: mt.MuteTimeInterval, Provenance: mt.Provenance})
+		}
+	}
+	return ErrResp(http.StatusNotFound, fmt.Errorf("mute timing %q not found", name), "")
+}
+
+func (srv *ProvisioningSrv) RoutePutMuteTiming(c *models.ReqContext, body apimodels.MuteTimeInterval) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	mt := body.MuteTimeInterval
+	mt.Name = web.Params(c.Req)[":Name"]
+	if err := srv.muteTimings.UpsertMuteTiming(c.Req.Context(), c.OrgId, mt, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to save mute timing")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "mute timing saved"})
+}
+
+func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	err := srv.muteTimings.DeleteMuteTiming(c.Req.Context(), c.OrgId, web.Params(c.Req)[":Name"], ngmodels.ProvenanceApi)
+	if err != nil {
+		return srv.errResp(err, "failed to delete mute timing")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+func (srv *ProvisioningSrv) RouteGetTemplates(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return srv.errResp(err, "failed to get templates")
+	}
+	result := make(apimodels.MessageTemplates, 0, len(templates))
+	for _, tmpl := range templates {
+		result = append(result, apimodels.MessageTemplate{Name: tmpl.Name, Template: tmpl.Template, Provenance: tmpl.Provenance})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetTemplate(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	name := web.Params(c.Req)[":Name"]
+	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return srv.errResp(err, "failed to get templates")
+	}
+	for _, tmpl := range templates {
+		if tmpl.Name == name {
+			return response.JSON(http.StatusOK, apimodels.MessageTemplate{Name: tmpl.Name, Template: tmpl.Template, Provenance: tmpl.Provenance})
+		}
+	}
+	return ErrResp(http.StatusNotFound, fmt.Errorf("template %q not found", name), "")
+}
+
+func (srv *ProvisioningSrv) RoutePutTemplate(c *models.ReqContext, body apimodels.MessageTemplateContent) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	tmpl := provisioning.MessageTemplate{Name: web.Params(c.Req)[":Name"], Template: body.Template}
+	if err := srv.templates.SetTemplate(c.Req.Context(), c.OrgId, tmpl, ngmodels.ProvenanceApi); err != nil {
+		return srv.errResp(err, "failed to save template")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "template saved"})
+}
+
+func (srv *ProvisioningSrv) RouteDeleteTemplate(c *models.ReqContext) response.Response {
+	if !c.HasUserRole(models.ROLE_EDITOR) {
+		return accessForbiddenResp()
+	}
+	err := srv.templates.DeleteTemplate(c.Req.Context(), c.OrgId, web.Params(c.Req)[":Name"], ngmodels.ProvenanceApi)
+	if err != nil {
+		return srv.errResp(err, "failed to delete template")
+	}
+	return response.JSON(http.StatusNoContent, "")
+}
+
+// errResp maps the errors of the provisioning services to responses. Provisioned objects that
+// cannot be changed through the API are reported as conflicts.
+func (srv *ProvisioningSrv) errResp(err error, msg string) response.Response {
+	switch {
+	case errors.Is(err, ngmodels.ErrProvisionedResource), errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation):
+		return ErrResp(http.StatusConflict, err, "")
+	case errors.Is(err, provisioning.ErrValidation), errors.Is(err, ngmodels.ErrAlertRuleFailedValidation):
+		return ErrResp(http.StatusBadRequest, err, "")
+	case errors.Is(err, ngmodels.ErrAlertRuleNotFound), errors.Is(err, provisioning.ErrPolicyNotFound):
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	srv.log.Error(msg, "err", err)
+	return ErrResp(http.StatusInternalServerError, err, msg)
+}
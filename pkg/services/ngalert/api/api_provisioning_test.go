@@ -0,0 +1,340 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestProvisioningApi(t *testing.T) {
+	t.Run("alert rules", func(t *testing.T) {
+		sut, _ := createProvisioningSrvSut(t)
+
+		t.Run("are created with the API provenance", func(t *testing.T) {
+			response := sut.RoutePostAlertRule(createTestRequestCtx(models.ROLE_EDITOR, nil), createTestAlertRule("rule"))
+			require.Equal(t, http.StatusCreated, response.Status())
+
+			response = sut.RouteGetAlertRule(createTestRequestCtx(models.ROLE_VIEWER, map[string]string{":UID": "rule"}))
+			require.Equal(t, http.StatusOK, response.Status())
+			rule := apimodels.ProvisionedAlertRule{}
+			require.NoError(t, json.Unmarshal(response.Body(), &rule))
+			require.Equal(t, ngmodels.ProvenanceApi, rule.Provenance)
+			require.Equal(t, ngmodels.NoData, rule.NoDataState)
+		})
+
+		t.Run("cannot be created twice", func(t *testing.T) {
+			response := sut.RoutePostAlertRule(createTestRequestCtx(models.ROLE_EDITOR, nil), createTestAlertRule("rule"))
+			require.Equal(t, http.StatusConflict, response.Status())
+		})
+
+		t.Run("are updated", func(t *testing.T) {
+			rule := createTestAlertRule("")
+			rule.Title = "changed"
+			response := sut.RoutePutAlertRule(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":UID": "rule"}), rule)
+			require.Equal(t, http.StatusOK, response.Status())
+
+			updated := apimodels.ProvisionedAlertRule{}
+			require.NoError(t, json.Unmarshal(response.Body(), &updated))
+			require.Equal(t, "rule", updated.UID)
+			require.Equal(t, "changed", updated.Title)
+		})
+
+		t.Run("return 400 without required fields", func(t *testing.T) {
+			rule := createTestAlertRule("invalid")
+			rule.Condition = ""
+			response := sut.RoutePostAlertRule(createTestRequestCtx(models.ROLE_EDITOR, nil), rule)
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+
+		t.Run("return 404 when they do not exist", func(t *testing.T) {
+			response := sut.RouteGetAlertRule(createTestRequestCtx(models.ROLE_VIEWER, map[string]string{":UID": "unknown"}))
+			require.Equal(t, http.StatusNotFound, response.Status())
+		})
+
+		t.Run("cannot be read without access to their folder", func(t *testing.T) {
+			payload := createTestAlertRule("private-rule")
+			rule := payload.UpstreamModel()
+			rule.OrgID = 1
+			rule.NamespaceUID = "private"
+			_, err := sut.alertRules.CreateAlertRule(context.Background(), rule, ngmodels.ProvenanceApi)
+			require.NoError(t, err)
+
+			response := sut.RouteGetAlertRule(createTestRequestCtx(models.ROLE_VIEWER, map[string]string{":UID": "private-rule"}))
+			require.Equal(t, http.StatusForbidden, response.Status())
+		})
+
+		t.Run("cannot be moved or deleted without access to their current folder", func(t *testing.T) {
+			response := sut.RoutePutAlertRule(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":UID": "private-rule"}), createTestAlertRule(""))
+			require.Equal(t, http.StatusForbidden, response.Status())
+			response = sut.RouteDeleteAlertRule(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":UID": "private-rule"}))
+			require.Equal(t, http.StatusForbidden, response.Status())
+		})
+
+		t.Run("cannot be changed by viewers", func(t *testing.T) {
+			response := sut.RouteDeleteAlertRule(createTestRequestCtx(models.ROLE_VIEWER, map[string]string{":UID": "rule"}))
+			require.Equal(t, http.StatusForbidden, response.Status())
+		})
+
+		t.Run("provisioned from files cannot be changed", func(t *testing.T) {
+			payload := createTestAlertRule("file-rule")
+			rule := payload.UpstreamModel()
+			rule.OrgID = 1
+			_, err := sut.alertRules.CreateAlertRule(context.Background(), rule, ngmodels.ProvenanceFile)
+			require.NoError(t, err)
+
+			response := sut.RoutePutAlertRule(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":UID": "file-rule"}), createTestAlertRule(""))
+			require.Equal(t, http.StatusConflict, response.Status())
+			response = sut.RouteDeleteAlertRule(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":UID": "file-rule"}))
+			require.Equal(t, http.StatusConflict, response.Status())
+		})
+
+		t.Run("are deleted", func(t *testing.T) {
+			response := sut.RouteDeleteAlertRule(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":UID": "rule"}))
+			require.Equal(t, http.StatusNoContent, response.Status())
+
+			response = sut.RouteDeleteAlertRule(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":UID": "rule"}))
+			require.Equal(t, http.StatusNotFound, response.Status())
+		})
+	})
+
+	t.Run("contact points", func(t *testing.T) {
+		sut, _ := createProvisioningSrvSut(t)
+
+		t.Run("are saved with the API provenance and returned without secure settings", func(t *testing.T) {
+			body := apimodels.PostableContactPoint{
+				Integrations: []*apimodels.PostableGrafanaReceiver{{
+					UID:            "ops-slack",
+					Type:           "slack",
+					Settings:       simplejson.NewFromAny(map[string]interface{}{"recipient": "#ops"}),
+					SecureSettings: map[string]string{"url": "https://hooks.slack.com/secret"},
+				}},
+			}
+			response := sut.RoutePutContactpoint(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "ops"}), body)
+			require.Equal(t, http.StatusAccepted, response.Status())
+
+			response = sut.RouteGetContactpoints(createTestRequestCtx(models.ROLE_EDITOR, nil))
+			require.Equal(t, http.StatusOK, response.Status())
+			require.NotContains(t, string(response.Body()), "secret")
+			contactPoints := apimodels.ContactPoints{}
+			require.NoError(t, json.Unmarshal(response.Body(), &contactPoints))
+			require.Len(t, contactPoints, 2)
+			require.Equal(t, "ops", contactPoints[1].Name)
+			require.Equal(t, ngmodels.ProvenanceApi, contactPoints[1].Provenance)
+			require.Equal(t, map[string]bool{"url": true}, contactPoints[1].Integrations[0].SecureFields)
+		})
+
+		t.Run("cannot be read by viewers", func(t *testing.T) {
+			response := sut.RouteGetContactpoints(createTestRequestCtx(models.ROLE_VIEWER, nil))
+			require.Equal(t, http.StatusForbidden, response.Status())
+		})
+
+		t.Run("return 400 when they are invalid", func(t *testing.T) {
+			body := apimodels.PostableContactPoint{
+				Integrations: []*apimodels.PostableGrafanaReceiver{{Type: "unknown"}},
+			}
+			response := sut.RoutePutContactpoint(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "ops"}), body)
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+
+		t.Run("are deleted", func(t *testing.T) {
+			response := sut.RouteDeleteContactpoint(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "ops"}))
+			require.Equal(t, http.StatusNoContent, response.Status())
+		})
+	})
+
+	t.Run("notification policies", func(t *testing.T) {
+		sut, _ := createProvisioningSrvSut(t)
+
+		t.Run("are updated with the API provenance", func(t *testing.T) {
+			tree := apimodels.Route{Receiver: "grafana-default-email", GroupByStr: []string{"alertname"}}
+			response := sut.RoutePutPolicyTree(createTestRequestCtx(models.ROLE_EDITOR, nil), tree)
+			require.Equal(t, http.StatusAccepted, response.Status())
+
+			response = sut.RouteGetPolicyTree(createTestRequestCtx(models.ROLE_EDITOR, nil))
+			require.Equal(t, http.StatusOK, response.Status())
+			result := apimodels.Route{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Equal(t, ngmodels.ProvenanceApi, result.Provenance)
+			require.Equal(t, []string{"alertname"}, result.GroupByStr)
+		})
+
+		t.Run("cannot be read by viewers", func(t *testing.T) {
+			response := sut.RouteGetPolicyTree(createTestRequestCtx(models.ROLE_VIEWER, nil))
+			require.Equal(t, http.StatusForbidden, response.Status())
+		})
+
+		t.Run("return 400 when they use unknown receivers", func(t *testing.T) {
+			tree := apimodels.Route{Receiver: "unknown"}
+			response := sut.RoutePutPolicyTree(createTestRequestCtx(models.ROLE_EDITOR, nil), tree)
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+
+		t.Run("are changed one at a time", func(t *testing.T) {
+			policy := apimodels.Route{Receiver: "grafana-default-email", GroupByStr: []string{"a"}}
+			response := sut.RoutePostPolicy(createTestRequestCtx(models.ROLE_EDITOR, nil), policy)
+			require.Equal(t, http.StatusAccepted, response.Status())
+			policy.GroupByStr = []string{"b"}
+			response = sut.RoutePostNestedPolicy(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Path": "0"}), policy)
+			require.Equal(t, http.StatusAccepted, response.Status())
+			policy.GroupByStr = []string{"c"}
+			response = sut.RoutePutPolicy(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Path": "0.0"}), policy)
+			require.Equal(t, http.StatusAccepted, response.Status())
+
+			response = sut.RouteGetPolicy(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Path": "0.0"}))
+			require.Equal(t, http.StatusOK, response.Status())
+			result := apimodels.Route{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Equal(t, ngmodels.ProvenanceApi, result.Provenance)
+			require.Equal(t, []string{"c"}, result.GroupByStr)
+
+			response = sut.RouteDeletePolicy(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Path": "0.0"}))
+			require.Equal(t, http.StatusNoContent, response.Status())
+			response = sut.RouteGetPolicy(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Path": "0.0"}))
+			require.Equal(t, http.StatusNotFound, response.Status())
+		})
+
+		t.Run("return 400 for invalid policy paths", func(t *testing.T) {
+			response := sut.RouteGetPolicy(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Path": "0.a"}))
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+
+		t.Run("are reset", func(t *testing.T) {
+			response := sut.RouteResetPolicyTree(createTestRequestCtx(models.ROLE_EDITOR, nil))
+			require.Equal(t, http.StatusAccepted, response.Status())
+
+			response = sut.RouteGetPolicyTree(createTestRequestCtx(models.ROLE_EDITOR, nil))
+			result := apimodels.Route{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Equal(t, ngmodels.ProvenanceNone, result.Provenance)
+		})
+	})
+
+	t.Run("mute timings", func(t *testing.T) {
+		sut, _ := createProvisioningSrvSut(t)
+
+		body := apimodels.MuteTimeInterval{}
+		require.NoError(t, json.Unmarshal([]byte(`{"time_intervals": [{"weekdays": ["saturday", "sunday"]}]}`), &body))
+		response := sut.RoutePutMuteTiming(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "weekends"}), body)
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		response = sut.RouteGetMuteTiming(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "weekends"}))
+		require.Equal(t, http.StatusOK, response.Status())
+		result := apimodels.MuteTimeInterval{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, "weekends", result.Name)
+		require.Equal(t, ngmodels.ProvenanceApi, result.Provenance)
+
+		response = sut.RouteGetMuteTiming(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "unknown"}))
+		require.Equal(t, http.StatusNotFound, response.Status())
+
+		response = sut.RouteGetMuteTimings(createTestRequestCtx(models.ROLE_VIEWER, nil))
+		require.Equal(t, http.StatusForbidden, response.Status())
+
+		response = sut.RouteDeleteMuteTiming(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "weekends"}))
+		require.Equal(t, http.StatusNoContent, response.Status())
+		response = sut.RouteGetMuteTimings(createTestRequestCtx(models.ROLE_EDITOR, nil))
+		require.JSONEq(t, `[]`, string(response.Body()))
+	})
+
+	t.Run("templates", func(t *testing.T) {
+		sut, _ := createProvisioningSrvSut(t)
+
+		body := apimodels.MessageTemplateContent{Template: `{{ define "title" }}{{ .Status }}{{ end }}`}
+		response := sut.RoutePutTemplate(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "title.tmpl"}), body)
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		response = sut.RouteGetTemplates(createTestRequestCtx(models.ROLE_EDITOR, nil))
+		require.Equal(t, http.StatusOK, response.Status())
+		require.JSONEq(t, `[{"name": "title.tmpl", "template": "{{ define \"title\" }}{{ .Status }}{{ end }}", "provenance": "api"}]`, string(response.Body()))
+
+		response = sut.RouteGetTemplate(createTestRequestCtx(models.ROLE_VIEWER, map[string]string{":Name": "title.tmpl"}))
+		require.Equal(t, http.StatusForbidden, response.Status())
+
+		body = apimodels.MessageTemplateContent{Template: `{{ define "title" }}`}
+		response = sut.RoutePutTemplate(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "title.tmpl"}), body)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+
+		response = sut.RouteDeleteTemplate(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "title.tmpl"}))
+		require.Equal(t, http.StatusNoContent, response.Status())
+		response = sut.RouteGetTemplate(createTestRequestCtx(models.ROLE_EDITOR, map[string]string{":Name": "title.tmpl"}))
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
+func createProvisioningSrvSut(t *testing.T) (ProvisioningSrv, *store.DBstore) {
+	t.Helper()
+
+	sqlStore := sqlstore.InitTestDB(t)
+	logger := log.New("test")
+	dbstore := &store.DBstore{
+		BaseInterval:    10 * time.Second,
+		DefaultInterval: time.Minute,
+		SQLStore:        sqlStore,
+		Logger:          logger,
+	}
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	defaultConfig := setting.GetAlertmanagerDefaultConfiguration()
+
+	return ProvisioningSrv{
+		log:           logger,
+		alertRules:    provisioning.NewAlertRuleService(dbstore, dbstore, dbstore, dbstore.DefaultInterval, logger),
+		contactPoints: provisioning.NewContactPointService(dbstore, defaultConfig, dbstore, dbstore, secretsService, logger),
+		policies:      provisioning.NewNotificationPolicyService(dbstore, defaultConfig, dbstore, dbstore, logger),
+		muteTimings:   provisioning.NewMuteTimingService(dbstore, defaultConfig, dbstore, dbstore, logger),
+		templates:     provisioning.NewTemplateService(dbstore, defaultConfig, dbstore, dbstore, logger),
+		namespaces:    &fakeNamespaceStore{},
+	}, dbstore
+}
+
+type fakeNamespaceStore struct{}
+
+func (f *fakeNamespaceStore) GetNamespaceByUID(_ context.Context, uid string, _ int64, _ *models.SignedInUser, _ bool) (*models.Folder, error) {
+	if uid == "private" {
+		return nil, models.ErrFolderAccessDenied
+	}
+	if uid != "folder" {
+		return nil, models.ErrFolderNotFound
+	}
+	return &models.Folder{Uid: uid}, nil
+}
+
+func createTestRequestCtx(role models.RoleType, params map[string]string) *models.ReqContext {
+	req := web.SetURLParams(&http.Request{}, params)
+	return &models.ReqContext{
+		Context: &web.Context{
+			Req: req,
+		},
+		SignedInUser: &models.SignedInUser{
+			OrgRole: role,
+			OrgId:   1,
+		},
+	}
+}
+
+func createTestAlertRule(uid string) apimodels.ProvisionedAlertRule {
+	return apimodels.ProvisionedAlertRule{
+		UID:       uid,
+		FolderUID: "folder",
+		RuleGroup: "group",
+		Title:     "rule " + uid,
+		Condition: "A",
+		Data:      []ngmodels.AlertQuery{ngmodels.GenerateAlertQuery()},
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 type RulerSrv struct {
 	store           store.RuleStore
+	provenanceStore store.ProvisioningStore
 	DatasourceCache datasources.CacheService
 	QuotaService    *quota.QuotaService
 	scheduleService schedule.ScheduleService
@@ -46,8 +47,25 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
-	uids, err := srv.store.DeleteNamespaceAlertRules(c.Req.Context(), c.SignedInUser.OrgId, namespace.Uid)
+	var uids []string
+	err = srv.store.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		q := ngmodels.ListNamespaceAlertRulesQuery{
+			OrgID:        c.SignedInUser.OrgId,
+			NamespaceUID: namespace.Uid,
+		}
+		if err := srv.store.GetNamespaceAlertRules(ctx, &q); err != nil {
+			return err
+		}
+		if err := srv.checkProvenance(ctx, c.SignedInUser.OrgId, q.Result); err != nil {
+			return err
+		}
+		uids, err = srv.store.DeleteNamespaceAlertRules(ctx, c.SignedInUser.OrgId, namespace.Uid)
+		return err
+	})
 	if err != nil {
+		if errors.Is(err, ngmodels.ErrProvisionedResource) {
+			return ErrResp(http.StatusConflict, err, "failed to delete namespace alert rules")
+		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete namespace alert rules")
 	}
 
@@ -68,12 +86,30 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := web.Params(c.Req)[":Groupname"]
-	uids, err := srv.store.DeleteRuleGroupAlertRules(c.Req.Context(), c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
+	var uids []string
+	err = srv.store.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		q := ngmodels.ListRuleGroupAlertRulesQuery{
+			OrgID:        c.SignedInUser.OrgId,
+			NamespaceUID: namespace.Uid,
+			RuleGroup:    ruleGroup,
+		}
+		if err := srv.store.GetRuleGroupAlertRules(ctx, &q); err != nil {
+			return err
+		}
+		if err := srv.checkProvenance(ctx, c.SignedInUser.OrgId, q.Result); err != nil {
+			return err
+		}
+		uids, err = srv.store.DeleteRuleGroupAlertRules(ctx, c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
+		return err
+	})
 
 	if err != nil {
 		if errors.Is(err, ngmodels.ErrRuleGroupNamespaceNotFound) {
 			return ErrResp(http.StatusNotFound, err, "failed to delete rule group")
 		}
+		if errors.Is(err, ngmodels.ErrProvisionedResource) {
+			return ErrResp(http.StatusConflict, err, "failed to delete rule group")
+		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete rule group")
 	}
 
@@ -101,6 +137,10 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 	if err := srv.store.GetNamespaceAlertRules(c.Req.Context(), &q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of alert rules")
+	}
 
 	result := apimodels.NamespaceConfigResponse{}
 	ruleGroupConfigs := make(map[string]apimodels.GettableRuleGroupConfig)
@@ -112,11 +152,11 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 				Name:     r.RuleGroup,
 				Interval: ruleGroupInterval,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, namespace.Id),
+					toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]),
 				},
 			}
 		} else {
-			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id))
+			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]))
 			ruleGroupConfigs[r.RuleGroup] = ruleGroupConfig
 		}
 	}
@@ -144,12 +184,16 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 	if err := srv.store.GetRuleGroupAlertRules(c.Req.Context(), &q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of alert rules")
+	}
 
 	var ruleGroupInterval model.Duration
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(q.Result))
 	for _, r := range q.Result {
 		ruleGroupInterval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
-		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id))
+		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]))
 	}
 
 	result := apimodels.RuleGroupConfigResponse{
@@ -198,6 +242,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 	if err := srv.store.GetOrgAlertRules(c.Req.Context(), &q); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
 	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.Req.Context(), c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get provenance of alert rules")
+	}
 
 	configs := make(map[string]map[string]apimodels.GettableRuleGroupConfig)
 	for _, r := range q.Result {
@@ -215,7 +263,7 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 				Name:     r.RuleGroup,
 				Interval: ruleGroupInterval,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, folder.Id),
+					toGettableExtendedRuleNode(*r, folder.Id, provenances[r.UID]),
 				},
 			}
 		} else {
@@ -226,11 +274,11 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 					Name:     r.RuleGroup,
 					Interval: ruleGroupInterval,
 					Rules: []apimodels.GettableExtendedRuleNode{
-						toGettableExtendedRuleNode(*r, folder.Id),
+						toGettableExtendedRuleNode(*r, folder.Id, provenances[r.UID]),
 					},
 				}
 			} else {
-				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id))
+				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id, provenances[r.UID]))
 				configs[namespace][r.RuleGroup] = ruleGroupConfig
 			}
 		}
@@ -275,6 +323,19 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, namespace *mod
 			return nil
 		}
 
+		if err := validateChangedRuleDependencies(tranCtx, srv.store, c.SignedInUser.OrgId, groupChanges); err != nil {
+			return err
+		}
+
+		changed := make([]*ngmodels.AlertRule, 0, len(groupChanges.Update)+len(groupChanges.Delete))
+		for _, update := range groupChanges.Update {
+			changed = append(changed, update.Existing)
+		}
+		changed = append(changed, groupChanges.Delete...)
+		if err := srv.checkProvenance(tranCtx, c.SignedInUser.OrgId, changed); err != nil {
+			return err
+		}
+
 		if len(groupChanges.Update) > 0 || len(groupChanges.New) > 0 {
 			upsert := make([]store.UpsertRule, 0, len(groupChanges.Update)+len(groupChanges.New))
 			for _, update := range groupChanges.Update {
@@ -325,6 +386,8 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, namespace *mod
 			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
 		} else if errors.Is(err, errQuotaReached) {
 			return ErrResp(http.StatusForbidden, err, "")
+		} else if errors.Is(err, ngmodels.ErrProvisionedResource) {
+			return ErrResp(http.StatusConflict, err, "failed to update rule group")
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
@@ -350,7 +413,25 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, namespace *mod
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
 }
 
-func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
+// checkProvenance returns an error when one of the rules is provisioned, as provisioned rules
+// cannot be changed or deleted with the ruler API.
+func (srv RulerSrv) checkProvenance(ctx context.Context, orgID int64, rules []*ngmodels.AlertRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	provenances, err := srv.provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if err := provenances[r.UID].CanUpdate(ngmodels.ProvenanceNone); err != nil {
+			return fmt.Errorf("alert rule %q: %w", r.Title, err)
+		}
+	}
+	return nil
+}
+
+func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provenance ngmodels.Provenance) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
 			ID:              r.ID,
@@ -367,8 +448,16 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			Provenance:      provenance,
+			Dependencies:    r.Dependencies,
 		},
 	}
+	if len(r.Dependencies) > 0 {
+		gettableExtendedRuleNode.GrafanaManagedAlert.DependencyAction = apimodels.DependencyAction(r.DependencyAction)
+	}
+	if r.IsRecordingRule() {
+		gettableExtendedRuleNode.GrafanaManagedAlert.Record = &apimodels.Record{Metric: r.RecordMetric}
+	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
 		For:         model.Duration(r.For),
 		Annotations: r.Annotations,
@@ -476,5 +565,31 @@ func calculateChanges(ctx context.Context, ruleStore store.RuleStore, orgId int6
 	}, nil
 }
 
+// validateChangedRuleDependencies checks that the dependencies of the alert rules of the organization do not form
+// a cycle once the changes are applied, as the rules of other groups can depend on the changed rules and vice versa.
+func validateChangedRuleDependencies(ctx context.Context, ruleStore store.RuleStore, orgId int64, ch *changes) error {
+	q := &ngmodels.ListAlertRulesQuery{OrgID: orgId}
+	if err := ruleStore.GetOrgAlertRules(ctx, q); err != nil {
+		return fmt.Errorf("failed to query database for the rules of the organization: %w", err)
+	}
+
+	replaced := make(map[string]struct{}, len(ch.Update)+len(ch.Delete))
+	rules := make([]*ngmodels.AlertRule, 0, len(q.Result)+len(ch.New))
+	for _, r := range ch.Delete {
+		replaced[r.UID] = struct{}{}
+	}
+	for _, update := range ch.Update {
+		replaced[update.Existing.UID] = struct{}{}
+		rules = append(rules, update.New)
+	}
+	rules = append(rules, ch.New...)
+	for _, r := range q.Result {
+		if _, ok := replaced[r.UID]; !ok {
+			rules = append(rules, r)
+		}
+	}
+	return validateRuleDependencies(rules)
+}
+
 // alertRuleFieldsToIgnoreInDiff contains fields that the AlertRule.Diff should ignore
 var alertRuleFieldsToIgnoreInDiff = []string{"ID", "Version", "Updated"}
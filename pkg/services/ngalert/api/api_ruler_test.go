@@ -297,3 +297,33 @@ func withUIDs(uids map[string]*models.AlertRule) func(rule *models.AlertRule) {
 		unused = unused[1:]
 	}
 }
+
+func TestValidateChangedRuleDependencies(t *testing.T) {
+	orgId := rand.Int63()
+	upstream := models.AlertRuleGen(withOrgID(orgId))()
+	upstream.Dependencies = nil
+	downstream := models.AlertRuleGen(withOrgID(orgId))()
+	downstream.Dependencies = []models.AlertRuleDependency{{RuleUID: upstream.UID}}
+
+	fakeStore := store.NewFakeRuleStore(t)
+	fakeStore.PutRule(context.Background(), upstream, downstream)
+
+	t.Run("rejects an update that closes a cycle with the rules of other groups", func(t *testing.T) {
+		updated := *upstream
+		updated.Dependencies = []models.AlertRuleDependency{{RuleUID: downstream.UID}}
+		err := validateChangedRuleDependencies(context.Background(), fakeStore, orgId, &changes{
+			Update: []ruleUpdate{{Existing: upstream, New: &updated}},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("accepts an update once the other side of the cycle is deleted", func(t *testing.T) {
+		updated := *upstream
+		updated.Dependencies = []models.AlertRuleDependency{{RuleUID: downstream.UID}}
+		err := validateChangedRuleDependencies(context.Background(), fakeStore, orgId, &changes{
+			Update: []ruleUpdate{{Existing: upstream, New: &updated}},
+			Delete: []*models.AlertRule{downstream},
+		})
+		require.NoError(t, err)
+	})
+}
@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -110,6 +113,30 @@ func validateRuleNode(
 		ExecErrState:    errorState,
 	}
 
+	if ruleNode.GrafanaManagedAlert.Record != nil {
+		if !model.IsValidMetricName(model.LabelValue(ruleNode.GrafanaManagedAlert.Record.Metric)) {
+			return nil, fmt.Errorf("%w: invalid metric name of the recording rule: '%s'", ngmodels.ErrAlertRuleFailedValidation, ruleNode.GrafanaManagedAlert.Record.Metric)
+		}
+		newAlertRule.RecordMetric = ruleNode.GrafanaManagedAlert.Record.Metric
+	}
+
+	if len(ruleNode.GrafanaManagedAlert.Dependencies) > 0 {
+		for _, d := range ruleNode.GrafanaManagedAlert.Dependencies {
+			if err := d.Validate(); err != nil {
+				return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+			}
+		}
+		newAlertRule.Dependencies = ruleNode.GrafanaManagedAlert.Dependencies
+		newAlertRule.DependencyAction = ngmodels.SuppressDependencyAction
+		if ruleNode.GrafanaManagedAlert.DependencyAction != "" {
+			action, err := ngmodels.DependencyActionFromString(string(ruleNode.GrafanaManagedAlert.DependencyAction))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+			}
+			newAlertRule.DependencyAction = action
+		}
+	}
+
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.For = time.Duration(ruleNode.ApiRuleNode.For)
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
@@ -179,5 +206,62 @@ func validateRuleGroup(
 		}
 		result = append(result, rule)
 	}
+	if err := validateRuleDependencies(result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
+
+// validateRuleDependencies returns an error if the dependencies of the alert rules form a cycle, in which
+// the rules would suppress each other and none of them would fire.
+func validateRuleDependencies(rules []*ngmodels.AlertRule) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(rules))
+	path := make([]int, 0)
+	var visit func(i int) error
+	visit = func(i int) error {
+		marks[i] = visiting
+		path = append(path, i)
+		for j, other := range rules {
+			if i == j || !rules[i].DependsOn(other) {
+				continue
+			}
+			switch marks[j] {
+			case visiting:
+				titles := make([]string, 0, len(path)+1)
+				for k := len(path) - 1; k >= 0; k-- {
+					titles = append(titles, rules[path[k]].Title)
+					if path[k] == j {
+						break
+					}
+				}
+				for l, r := 0, len(titles)-1; l < r; l, r = l+1, r-1 {
+					titles[l], titles[r] = titles[r], titles[l]
+				}
+				titles = append(titles, other.Title)
+				return fmt.Errorf("%w: dependencies of alert rules form a cycle: %s", ngmodels.ErrAlertRuleFailedValidation, strings.Join(titles, " -> "))
+			case unvisited:
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		marks[i] = visited
+		return nil
+	}
+	for i, rule := range rules {
+		// rules without dependencies cannot start a cycle.
+		if marks[i] != unvisited || len(rule.Dependencies) == 0 {
+			continue
+		}
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
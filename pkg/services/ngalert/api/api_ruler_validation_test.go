@@ -190,6 +190,25 @@ func TestValidateRuleGroupFailures(t *testing.T) {
 				require.Contains(t, err.Error(), apiModel.Rules[0].GrafanaManagedAlert.UID)
 			},
 		},
+		{
+			name: "fail if dependencies of rules form a cycle",
+			group: func() *apimodels.PostableRuleGroupConfig {
+				r1 := validRule()
+				r2 := validRule()
+				r1.GrafanaManagedAlert.Title = "db"
+				r1.ApiRuleNode.Labels = map[string]string{"service": "db"}
+				r1.GrafanaManagedAlert.Dependencies = []models.AlertRuleDependency{{Matchers: []string{`service="web"`}}}
+				r2.GrafanaManagedAlert.Title = "web"
+				r2.ApiRuleNode.Labels = map[string]string{"service": "web"}
+				r2.GrafanaManagedAlert.Dependencies = []models.AlertRuleDependency{{Matchers: []string{`service="db"`}}}
+				g := validGroup(cfg, r1, r2)
+				return &g
+			},
+			assert: func(t *testing.T, apiModel *apimodels.PostableRuleGroupConfig, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.Contains(t, err.Error(), "db -> web -> db")
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -297,6 +316,42 @@ func TestValidateRuleNode_NoUID(t *testing.T) {
 				require.Equal(t, int64(panelId), *alert.PanelID)
 			},
 		},
+		{
+			name: "sets the metric of a recording rule",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "grafana_cpu_usage:avg"}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.True(t, alert.IsRecordingRule())
+				require.Equal(t, "grafana_cpu_usage:avg", alert.RecordMetric)
+			},
+		},
+		{
+			name: "defaults the dependency action to suppress",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.AlertRuleDependency{{RuleUID: "upstream"}}
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, api.GrafanaManagedAlert.Dependencies, alert.Dependencies)
+				require.Equal(t, models.SuppressDependencyAction, alert.DependencyAction)
+			},
+		},
+		{
+			name: "sets the dependency action",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.AlertRuleDependency{{Matchers: []string{`cluster="eu-1"`}}}
+				r.GrafanaManagedAlert.DependencyAction = apimodels.FailDependencyAction
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, models.FailDependencyAction, alert.DependencyAction)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -437,6 +492,42 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if the metric of a recording rule is not valid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Record = &apimodels.Record{Metric: "cpu usage"}
+				return &r
+			},
+		},
+		{
+			name: "fail if a dependency has neither a rule UID nor matchers",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.AlertRuleDependency{{}}
+				return &r
+			},
+		},
+		{
+			name: "fail if a dependency matcher is not valid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.AlertRuleDependency{{Matchers: []string{"cluster"}}}
+				return &r
+			},
+		},
+		{
+			name: "fail if the dependency action is not valid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Dependencies = []models.AlertRuleDependency{{RuleUID: "upstream"}}
+				r.GrafanaManagedAlert.DependencyAction = "Ignore"
+				return &r
+			},
+			assert: func(t *testing.T, model *apimodels.PostableExtendedRuleNode, err error) {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -714,3 +805,50 @@ func TestValidateRuleNodeIntervalFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRuleDependencies(t *testing.T) {
+	rule := func(uid string, lbs map[string]string, dependencies ...models.AlertRuleDependency) *models.AlertRule {
+		return &models.AlertRule{UID: uid, Title: uid, Labels: lbs, Dependencies: dependencies}
+	}
+
+	t.Run("accepts a chain of dependencies", func(t *testing.T) {
+		require.NoError(t, validateRuleDependencies([]*models.AlertRule{
+			rule("a", nil, models.AlertRuleDependency{RuleUID: "b"}),
+			rule("b", map[string]string{"team": "ops"}, models.AlertRuleDependency{RuleUID: "c"}),
+			rule("c", nil),
+			rule("d", nil, models.AlertRuleDependency{Matchers: []string{`team="ops"`}}),
+		}))
+	})
+
+	t.Run("accepts matchers that do not select the labels of a rule", func(t *testing.T) {
+		require.NoError(t, validateRuleDependencies([]*models.AlertRule{
+			rule("a", map[string]string{"service": "db"}, models.AlertRuleDependency{Matchers: []string{`service="web"`}}),
+			rule("b", map[string]string{"service": "api"}, models.AlertRuleDependency{Matchers: []string{`service="db"`}}),
+		}))
+	})
+
+	t.Run("accepts a rule that selects its own instances", func(t *testing.T) {
+		require.NoError(t, validateRuleDependencies([]*models.AlertRule{
+			rule("a", map[string]string{"service": "db"}, models.AlertRuleDependency{Matchers: []string{`service="db"`}}),
+		}))
+	})
+
+	t.Run("rejects a cycle of rule UIDs", func(t *testing.T) {
+		err := validateRuleDependencies([]*models.AlertRule{
+			rule("a", nil, models.AlertRuleDependency{RuleUID: "b"}),
+			rule("b", nil, models.AlertRuleDependency{RuleUID: "c"}),
+			rule("c", nil, models.AlertRuleDependency{RuleUID: "b"}),
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.Contains(t, err.Error(), "b -> c -> b")
+	})
+
+	t.Run("rejects a cycle of rule UIDs and matchers", func(t *testing.T) {
+		err := validateRuleDependencies([]*models.AlertRule{
+			rule("a", map[string]string{"service": "db"}, models.AlertRuleDependency{RuleUID: "b"}),
+			rule("b", nil, models.AlertRuleDependency{Matchers: []string{`service=~"db|web"`}}),
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.Contains(t, err.Error(), "a -> b -> a")
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/expr"
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
@@ -96,3 +98,57 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+func (srv TestingApiSrv) RouteBacktestConfig(c *models.ReqContext, cmd apimodels.BacktestConfig) response.Response {
+	if cmd.Rule.GrafanaManagedAlert == nil {
+		return ErrResp(http.StatusBadRequest, errors.New("not Grafana managed alert rule"), "")
+	}
+
+	interval := time.Duration(cmd.Interval)
+	if interval == 0 {
+		interval = srv.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval
+	}
+
+	// the rule is not saved, so it is always validated as a new rule
+	cmd.Rule.GrafanaManagedAlert.UID = ""
+	rule, err := validateRuleNode(&cmd.Rule, "", interval, c.SignedInUser.OrgId, &models.Folder{}, conditionValidator(c, srv.DatasourceCache), &srv.Cfg.UnifiedAlerting)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid rule specification")
+	}
+	if rule.IsRecordingRule() {
+		return ErrResp(http.StatusBadRequest, errors.New("recording rules do not produce alerts"), "")
+	}
+
+	appURL, err := url.Parse(srv.Cfg.AppURL)
+	if err != nil {
+		appURL = nil
+	}
+	evaluator := eval.NewEvaluator(srv.Cfg, srv.log, srv.DatasourceCache, srv.secretsService)
+	engine := backtesting.NewEngine(srv.log, evaluator, srv.ExpressionService, appURL)
+	result, err := engine.Test(c.Req.Context(), rule, cmd.From, cmd.To)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidBacktest) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to backtest the rule")
+	}
+
+	body := apimodels.BacktestResult{
+		Instances:     make([]apimodels.BacktestInstance, 0, len(result.Instances)),
+		Notifications: result.Notifications,
+	}
+	for _, instance := range result.Instances {
+		timeline := make([]apimodels.BacktestStateChange, 0, len(instance.Changes))
+		for _, change := range instance.Changes {
+			timeline = append(timeline, apimodels.BacktestStateChange{
+				State:       change.State.String(),
+				EvaluatedAt: change.EvaluatedAt,
+			})
+		}
+		body.Instances = append(body.Instances, apimodels.BacktestInstance{
+			Labels:   instance.Labels,
+			Timeline: timeline,
+		})
+	}
+	return response.JSON(http.StatusOK, body)
+}
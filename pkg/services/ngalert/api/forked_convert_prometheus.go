@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// ForkedConvertPrometheusApi always forwards requests to grafana backend
+type ForkedConvertPrometheusApi struct {
+	svc *ConvertPrometheusSrv
+}
+
+// NewForkedConvertPrometheusApi creates a new ForkedConvertPrometheusApi instance
+func NewForkedConvertPrometheusApi(svc *ConvertPrometheusSrv) *ForkedConvertPrometheusApi {
+	return &ForkedConvertPrometheusApi{
+		svc: svc,
+	}
+}
+
+func (f *ForkedConvertPrometheusApi) forkRouteConvertPrometheusGetRules(c *models.ReqContext) response.Response {
+	return f.svc.RouteConvertPrometheusGetRules(c)
+}
+
+func (f *ForkedConvertPrometheusApi) forkRouteConvertPrometheusPostRules(c *models.ReqContext) response.Response {
+	return f.svc.RouteConvertPrometheusPostRules(c)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// ForkedNotificationLogApi always forwards requests to grafana backend
+type ForkedNotificationLogApi struct {
+	svc *NotificationLogSrv
+}
+
+// NewForkedNotificationLogApi creates a new ForkedNotificationLogApi instance
+func NewForkedNotificationLogApi(svc *NotificationLogSrv) *ForkedNotificationLogApi {
+	return &ForkedNotificationLogApi{
+		svc: svc,
+	}
+}
+
+func (f *ForkedNotificationLogApi) forkRouteGetNotificationLog(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetNotificationLog(c)
+}
+
+func (f *ForkedNotificationLogApi) forkRouteGetNotificationRetries(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetNotificationRetries(c)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// ForkedProvisioningApi always forwards requests to grafana backend
+type ForkedProvisioningApi struct {
+	svc *ProvisioningSrv
+}
+
+// NewForkedProvisioningApi creates a new ForkedProvisioningApi instance
+func NewForkedProvisioningApi(svc *ProvisioningSrv) *ForkedProvisioningApi {
+	return &ForkedProvisioningApi{
+		svc: svc,
+	}
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeleteAlertRule(c *models.ReqContext) response.Response {
+	return f.svc.RouteDeleteAlertRule(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeleteContactpoint(c *models.ReqContext) response.Response {
+	return f.svc.RouteDeleteContactpoint(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeleteMuteTiming(c *models.ReqContext) response.Response {
+	return f.svc.RouteDeleteMuteTiming(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeletePolicy(c *models.ReqContext) response.Response {
+	return f.svc.RouteDeletePolicy(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteDeleteTemplate(c *models.ReqContext) response.Response {
+	return f.svc.RouteDeleteTemplate(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetAlertRule(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetAlertRule(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetContactpoints(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetContactpoints(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetMuteTiming(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetMuteTiming(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetMuteTimings(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetMuteTimings(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetPolicyTree(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetPolicyTree(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetPolicy(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetPolicy(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetTemplate(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetTemplate(c)
+}
+
+func (f *ForkedProvisioningApi) forkRouteGetTemplates(c *models.ReqContext) response.Response {
+	return f.svc.RouteGetTemplates(c)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) response.Response {
+	return f.svc.RoutePostAlertRule(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostNestedPolicy(c *models.ReqContext, body apimodels.Route) response.Response {
+	return f.svc.RoutePostNestedPolicy(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePostPolicy(c *models.ReqContext, body apimodels.Route) response.Response {
+	return f.svc.RoutePostPolicy(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) response.Response {
+	return f.svc.RoutePutAlertRule(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutContactpoint(c *models.ReqContext, body apimodels.PostableContactPoint) response.Response {
+	return f.svc.RoutePutContactpoint(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutMuteTiming(c *models.ReqContext, body apimodels.MuteTimeInterval) response.Response {
+	return f.svc.RoutePutMuteTiming(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutPolicyTree(c *models.ReqContext, body apimodels.Route) response.Response {
+	return f.svc.RoutePutPolicyTree(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutPolicy(c *models.ReqContext, body apimodels.Route) response.Response {
+	return f.svc.RoutePutPolicy(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRoutePutTemplate(c *models.ReqContext, body apimodels.MessageTemplateContent) response.Response {
+	return f.svc.RoutePutTemplate(c, body)
+}
+
+func (f *ForkedProvisioningApi) forkRouteResetPolicyTree(c *models.ReqContext) response.Response {
+	return f.svc.RouteResetPolicyTree(c)
+}
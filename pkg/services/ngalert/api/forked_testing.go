@@ -26,6 +26,10 @@ func (f *ForkedTestingApi) forkRouteTestRuleGrafanaConfig(c *models.ReqContext,
 	return f.svc.RouteTestGrafanaRuleConfig(c, body)
 }
 
+func (f *ForkedTestingApi) forkRouteBacktestConfig(c *models.ReqContext, body apimodels.BacktestConfig) response.Response {
+	return f.svc.RouteBacktestConfig(c, body)
+}
+
 func (f *ForkedTestingApi) forkRouteEvalQueries(c *models.ReqContext, body apimodels.EvalQueriesPayload) response.Response {
 	return f.svc.RouteEvalQueries(c, body)
 }
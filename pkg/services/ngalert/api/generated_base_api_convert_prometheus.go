@@ -0,0 +1,55 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type ConvertPrometheusApiForkingService interface {
+	RouteConvertPrometheusGetRules(*models.ReqContext) response.Response
+	RouteConvertPrometheusPostRules(*models.ReqContext) response.Response
+}
+
+func (f *ForkedConvertPrometheusApi) RouteConvertPrometheusGetRules(ctx *models.ReqContext) response.Response {
+	return f.forkRouteConvertPrometheusGetRules(ctx)
+}
+
+func (f *ForkedConvertPrometheusApi) RouteConvertPrometheusPostRules(ctx *models.ReqContext) response.Response {
+	return f.forkRouteConvertPrometheusPostRules(ctx)
+}
+
+func (api *API) RegisterConvertPrometheusApiEndpoints(srv ConvertPrometheusApiForkingService, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/convert/prometheus/config/v1/rules"),
+			api.authorize(http.MethodGet, "/api/convert/prometheus/config/v1/rules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/convert/prometheus/config/v1/rules",
+				srv.RouteConvertPrometheusGetRules,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/convert/prometheus/config/v1/rules"),
+			api.authorize(http.MethodPost, "/api/convert/prometheus/config/v1/rules"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/convert/prometheus/config/v1/rules",
+				srv.RouteConvertPrometheusPostRules,
+				m,
+			),
+		)
+	})
+}
@@ -0,0 +1,55 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type NotificationLogApiForkingService interface {
+	RouteGetNotificationLog(*models.ReqContext) response.Response
+	RouteGetNotificationRetries(*models.ReqContext) response.Response
+}
+
+func (f *ForkedNotificationLogApi) RouteGetNotificationLog(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetNotificationLog(ctx)
+}
+
+func (f *ForkedNotificationLogApi) RouteGetNotificationRetries(ctx *models.ReqContext) response.Response {
+	return f.forkRouteGetNotificationRetries(ctx)
+}
+
+func (api *API) RegisterNotificationLogApiEndpoints(srv NotificationLogApiForkingService, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/notifications/log"),
+			api.authorize(http.MethodGet, "/api/v1/notifications/log"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/notifications/log",
+				srv.RouteGetNotificationLog,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/notifications/retries"),
+			api.authorize(http.MethodGet, "/api/v1/notifications/retries"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/notifications/retries",
+				srv.RouteGetNotificationRetries,
+				m,
+			),
+		)
+	})
+}
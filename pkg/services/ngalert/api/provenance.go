@@ -0,0 +1,146 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// provisionedConfig holds the provenance of the provisioned parts of an Alertmanager configuration.
+type provisionedConfig struct {
+	contactPoints map[string]ngmodels.Provenance
+	muteTimings   map[string]ngmodels.Provenance
+	templates     map[string]ngmodels.Provenance
+	policies      ngmodels.Provenance
+}
+
+func (p provisionedConfig) isEmpty() bool {
+	return len(p.contactPoints)+len(p.muteTimings)+len(p.templates) == 0 && p.policies == ngmodels.ProvenanceNone
+}
+
+func (srv AlertmanagerSrv) getProvisionedConfig(ctx context.Context, orgID int64) (provisionedConfig, error) {
+	var (
+		result provisionedConfig
+		err    error
+	)
+	if result.contactPoints, err = srv.provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeContactPoint); err != nil {
+		return result, err
+	}
+	if result.muteTimings, err = srv.provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeMuteTiming); err != nil {
+		return result, err
+	}
+	if result.templates, err = srv.provenanceStore.GetProvenances(ctx, orgID, ngmodels.ResourceTypeTemplate); err != nil {
+		return result, err
+	}
+	if result.policies, err = srv.provenanceStore.GetProvenance(ctx, ngmodels.NotificationPolicyResource(orgID)); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// checkProvisionedConfig returns an error wrapping ngmodels.ErrProvisionedResource when the new
+// configuration changes or removes a provisioned part of the current configuration. The secure
+// settings of the new configuration must be in plain text, as they are before ProcessConfig.
+func (srv AlertmanagerSrv) checkProvisionedConfig(ctx context.Context, orgID int64, current, cfg *apimodels.PostableUserConfig) error {
+	provisioned, err := srv.getProvisionedConfig(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if provisioned.isEmpty() || current == nil {
+		return nil
+	}
+
+	if provisioned.policies != ngmodels.ProvenanceNone {
+		equal, err := jsonEqual(current.AlertmanagerConfig.Route, cfg.AlertmanagerConfig.Route)
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return fmt.Errorf("notification policies: %w", ngmodels.ErrProvisionedResource)
+		}
+	}
+
+	for name := range provisioned.templates {
+		if content, ok := current.TemplateFiles[name]; ok && cfg.TemplateFiles[name] != content {
+			return fmt.Errorf("template %q: %w", name, ngmodels.ErrProvisionedResource)
+		}
+	}
+
+	for _, mt := range current.AlertmanagerConfig.MuteTimeIntervals {
+		if _, ok := provisioned.muteTimings[mt.Name]; !ok {
+			continue
+		}
+		var equal bool
+		for _, other := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+			if other.Name == mt.Name {
+				if equal, err = jsonEqual(mt, other); err != nil {
+					return err
+				}
+			}
+		}
+		if !equal {
+			return fmt.Errorf("mute timing %q: %w", mt.Name, ngmodels.ErrProvisionedResource)
+		}
+	}
+
+	for _, r := range current.AlertmanagerConfig.Receivers {
+		if _, ok := provisioned.contactPoints[r.Name]; !ok {
+			continue
+		}
+		var equal bool
+		for _, other := range cfg.AlertmanagerConfig.Receivers {
+			if other.Name == r.Name {
+				if equal, err = srv.receiversEqual(r, other); err != nil {
+					return err
+				}
+			}
+		}
+		if !equal {
+			return fmt.Errorf("contact point %q: %w", r.Name, ngmodels.ErrProvisionedResource)
+		}
+	}
+	return nil
+}
+
+// receiversEqual compares a stored receiver, with encrypted secure settings, to a receiver of the API
+// with secure settings in plain text.
+func (srv AlertmanagerSrv) receiversEqual(stored, r *apimodels.PostableApiReceiver) (bool, error) {
+	if len(stored.GrafanaManagedReceivers) != len(r.GrafanaManagedReceivers) {
+		return false, nil
+	}
+	for i, s := range stored.GrafanaManagedReceivers {
+		o := r.GrafanaManagedReceivers[i]
+		if s.UID != o.UID || s.Type != o.Type || s.DisableResolveMessage != o.DisableResolveMessage || len(s.SecureSettings) != len(o.SecureSettings) {
+			return false, nil
+		}
+		if equal, err := jsonEqual(s.Settings, o.Settings); err != nil || !equal {
+			return false, err
+		}
+		for k := range s.SecureSettings {
+			decrypted, err := srv.getDecryptedSecret(s, k)
+			if err != nil {
+				return false, fmt.Errorf("failed to decrypt stored secure setting: %s: %w", k, err)
+			}
+			if value, ok := o.SecureSettings[k]; !ok || value != decrypted {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func jsonEqual(a, b interface{}) (bool, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ja, jb), nil
+}
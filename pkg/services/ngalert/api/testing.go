@@ -17,6 +17,8 @@ import (
 
 type FakeAlertingStore struct {
 	orgsWithConfig map[int64]bool
+	// configs are the latest configurations of the organizations that have one
+	configs map[int64]string
 }
 
 func newFakeAlertingStore(t *testing.T) FakeAlertingStore {
@@ -24,6 +26,7 @@ func newFakeAlertingStore(t *testing.T) FakeAlertingStore {
 
 	return FakeAlertingStore{
 		orgsWithConfig: map[int64]bool{},
+		configs:        map[int64]string{},
 	}
 }
 
@@ -31,8 +34,17 @@ func (f FakeAlertingStore) Setup(orgID int64) {
 	f.orgsWithConfig[orgID] = true
 }
 
+// SetupConfig makes the given configuration the latest configuration of the organization.
+func (f FakeAlertingStore) SetupConfig(orgID int64, config string) {
+	f.orgsWithConfig[orgID] = true
+	f.configs[orgID] = config
+}
+
 func (f FakeAlertingStore) GetLatestAlertmanagerConfiguration(_ context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) error {
 	if _, ok := f.orgsWithConfig[query.OrgID]; ok {
+		if config, ok := f.configs[query.OrgID]; ok {
+			query.Result = &models.AlertConfiguration{AlertmanagerConfiguration: config, OrgID: query.OrgID}
+		}
 		return nil
 	}
 	return store.ErrNoAlertmanagerConfiguration
@@ -0,0 +1,91 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/convert/prometheus/config/v1/rules convert_prometheus RouteConvertPrometheusGetRules
+//
+// Export the Grafana managed rules that query Prometheus data sources in the Prometheus rule file format.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusNamespaces
+
+// swagger:route POST /api/convert/prometheus/config/v1/rules convert_prometheus RouteConvertPrometheusPostRules
+//
+// Import a Prometheus, Cortex or Mimir rule file as Grafana managed rules.
+//
+//     Consumes:
+//     - application/yaml
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: PrometheusImportReport
+//       400: ValidationError
+
+// swagger:parameters RouteConvertPrometheusGetRules
+type ConvertPrometheusGetRulesParams struct {
+	// Title of the folder the rules are exported from. All folders are exported if empty.
+	// in: query
+	// required: false
+	Namespace string `json:"namespace"`
+}
+
+// swagger:parameters RouteConvertPrometheusPostRules
+type ConvertPrometheusPostRulesParams struct {
+	// Title of the folder the rules are imported into. Defaults to the namespace of the rule file.
+	// in: query
+	// required: false
+	Namespace string `json:"namespace"`
+
+	// UID of the Prometheus data source the rules query
+	// in: query
+	// required: true
+	DatasourceUID string `json:"datasourceUID"`
+
+	// Convert the rules and report the result without saving them
+	// in: query
+	// required: false
+	DryRun bool `json:"dryRun"`
+
+	// in: body
+	Body PrometheusRuleFile
+}
+
+// PrometheusRuleFile is a Prometheus rule file. The rule files of Cortex and Mimir also have a namespace.
+// swagger:model
+type PrometheusRuleFile struct {
+	Namespace string                `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Groups    []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// PrometheusNamespaces are the rule groups of each namespace, as returned by the Cortex ruler API.
+// swagger:model
+type PrometheusNamespaces map[string][]PrometheusRuleGroup
+
+// PrometheusRuleGroup is a rule group of a Prometheus rule file.
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
+
+// swagger:model
+type PrometheusImportReport struct {
+	// Imported are the rules converted to Grafana managed rules.
+	Imported []PrometheusRuleStatus `json:"imported"`
+	// Failed are the rules that could not be converted. If the rule is empty, the whole group failed.
+	Failed []PrometheusRuleStatus `json:"failed"`
+}
+
+type PrometheusRuleStatus struct {
+	Group string `json:"group"`
+	// Name of the alert or of the recording rule
+	Rule  string `json:"rule,omitempty"`
+	Error string `json:"error,omitempty"`
+}
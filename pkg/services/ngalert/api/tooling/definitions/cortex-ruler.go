@@ -351,6 +351,14 @@ const (
 	OK       NoDataState = "OK"
 )
 
+// swagger:enum DependencyAction
+type DependencyAction string
+
+const (
+	SuppressDependencyAction DependencyAction = "Suppress"
+	FailDependencyAction     DependencyAction = "DependencyFailed"
+)
+
 // swagger:enum ExecutionErrorState
 type ExecutionErrorState string
 
@@ -368,6 +376,27 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	Record       *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+	// Dependencies are the alert instances the rule depends on. While one of them is firing,
+	// the dependency action is applied to the alerts of the rule.
+	Dependencies     []models.AlertRuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	DependencyAction DependencyAction             `json:"dependency_action,omitempty" yaml:"dependency_action,omitempty"`
+}
+
+// Record configures a recording rule: the results of the evaluation are written as series instead of producing alerts.
+// swagger:model
+type Record struct {
+	// Name of the metric the results are written to.
+	// required: true
+	// example: grafana_cpu_usage:avg
+	Metric string `json:"metric" yaml:"metric"`
+}
+
+func (r *Record) metric() string {
+	if r == nil {
+		return ""
+	}
+	return r.Metric
 }
 
 // swagger:model
@@ -386,4 +415,10 @@ type GettableGrafanaRule struct {
 	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
 	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	Provenance      models.Provenance   `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	Record          *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+	// Dependencies are the alert instances the rule depends on. While one of them is firing,
+	// the dependency action is applied to the alerts of the rule.
+	Dependencies     []models.AlertRuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	DependencyAction DependencyAction             `json:"dependency_action,omitempty" yaml:"dependency_action,omitempty"`
 }
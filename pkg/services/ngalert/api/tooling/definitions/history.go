@@ -0,0 +1,54 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/v1/rules/history history RouteGetStateHistory
+//
+// Get the state transitions of the alert instances of Grafana managed alert rules.
+//
+//     Responses:
+//       200: StateHistory
+//       400: ValidationError
+
+// swagger:parameters RouteGetStateHistory
+type StateHistoryParams struct {
+	// The UID of the alert rule
+	// in: query
+	// required: false
+	RuleUID string `json:"ruleUID"`
+
+	// A list of matchers to filter the alert instances by
+	// in: query
+	// required: false
+	Matchers []string `json:"filter"`
+
+	// Start of the time range, in epoch milliseconds
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// End of the time range, in epoch milliseconds
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// Maximum number of transitions, the most recent ones are returned
+	// in: query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type StateHistory []StateTransition
+
+// StateTransition is a change of the state of an alert instance.
+type StateTransition struct {
+	RuleUID       string             `json:"ruleUID"`
+	Labels        map[string]string  `json:"labels"`
+	PreviousState string             `json:"previousState"`
+	State         string             `json:"state"`
+	Values        map[string]float64 `json:"values,omitempty"`
+	EvaluatedAt   time.Time          `json:"evaluatedAt"`
+}
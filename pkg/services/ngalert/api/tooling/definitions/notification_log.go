@@ -0,0 +1,96 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/v1/notifications/log notifications RouteGetNotificationLog
+//
+// Get the attempts to send notifications with the integrations of the contact points.
+//
+//     Responses:
+//       200: NotificationLog
+//       400: ValidationError
+
+// swagger:route GET /api/v1/notifications/retries notifications RouteGetNotificationRetries
+//
+// Get the failed notifications waiting to be sent again.
+//
+//     Responses:
+//       200: NotificationRetries
+
+// swagger:parameters RouteGetNotificationLog
+type NotificationLogParams struct {
+	// The name of the contact point
+	// in: query
+	// required: false
+	Receiver string `json:"receiver"`
+
+	// The UID of the integration of the contact point
+	// in: query
+	// required: false
+	IntegrationUID string `json:"integrationUID"`
+
+	// The status of the attempts, success or failed
+	// in: query
+	// required: false
+	Status string `json:"status"`
+
+	// Start of the time range, in epoch milliseconds
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+
+	// End of the time range, in epoch milliseconds
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+
+	// Maximum number of attempts, the most recent ones are returned
+	// in: query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type NotificationLog []NotificationAttempt
+
+// NotificationAttempt is an attempt to send a notification with an integration of a contact point.
+type NotificationAttempt struct {
+	ID              int64             `json:"id"`
+	Receiver        string            `json:"receiver"`
+	IntegrationUID  string            `json:"integrationUID"`
+	IntegrationName string            `json:"integrationName"`
+	IntegrationType string            `json:"integrationType"`
+	GroupKey        string            `json:"groupKey"`
+	GroupLabels     map[string]string `json:"groupLabels,omitempty"`
+	// Number of firing alerts of the notification
+	Firing int `json:"firing"`
+	// Number of resolved alerts of the notification
+	Resolved   int      `json:"resolved"`
+	AlertNames []string `json:"alertNames,omitempty"`
+	Status     string   `json:"status"`
+	Error      string   `json:"error,omitempty"`
+	// Attempt is 1 for the notifications sent by the notification policies, and is incremented by the retries
+	Attempt    int       `json:"attempt"`
+	DurationMs int64     `json:"durationMs"`
+	SentAt     time.Time `json:"sentAt"`
+}
+
+// swagger:model
+type NotificationRetries []NotificationRetry
+
+// NotificationRetry is a failed notification waiting to be sent again.
+type NotificationRetry struct {
+	Receiver       string            `json:"receiver"`
+	IntegrationUID string            `json:"integrationUID"`
+	GroupKey       string            `json:"groupKey"`
+	GroupLabels    map[string]string `json:"groupLabels,omitempty"`
+	// Number of alerts of the notification
+	Alerts int `json:"alerts"`
+	// Number of attempts that failed so far
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"lastError"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
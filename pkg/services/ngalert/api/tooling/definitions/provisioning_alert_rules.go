@@ -0,0 +1,140 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/provisioning/alert-rules/{UID} provisioning RouteGetAlertRule
+//
+// Get a specific alert rule by UID.
+//
+//     Responses:
+//       200: ProvisionedAlertRule
+//       404: Failure
+
+// swagger:route POST /api/provisioning/alert-rules provisioning RoutePostAlertRule
+//
+// Create a new alert rule.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: ProvisionedAlertRule
+//       400: ValidationError
+
+// swagger:route PUT /api/provisioning/alert-rules/{UID} provisioning RoutePutAlertRule
+//
+// Update an existing alert rule.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedAlertRule
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route DELETE /api/provisioning/alert-rules/{UID} provisioning RouteDeleteAlertRule
+//
+// Delete a specific alert rule by UID.
+//
+//     Responses:
+//       204: Ack
+//       409: Failure
+
+// swagger:parameters RouteGetAlertRule RoutePutAlertRule RouteDeleteAlertRule
+type AlertRuleUIDReference struct {
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostAlertRule RoutePutAlertRule
+type AlertRulePayload struct {
+	// in:body
+	Body ProvisionedAlertRule
+}
+
+// swagger:model
+type ProvisionedAlertRule struct {
+	ID    int64  `json:"id"`
+	UID   string `json:"uid"`
+	OrgID int64  `json:"orgID"`
+	// required: true
+	FolderUID string `json:"folderUID"`
+	// required: true
+	RuleGroup string `json:"ruleGroup"`
+	// required: true
+	Title string `json:"title"`
+	// required: true
+	Condition string `json:"condition"`
+	// required: true
+	Data         []models.AlertQuery        `json:"data"`
+	Updated      time.Time                  `json:"updated,omitempty"`
+	NoDataState  models.NoDataState         `json:"noDataState"`
+	ExecErrState models.ExecutionErrorState `json:"execErrState"`
+	For          model.Duration             `json:"for"`
+	Annotations  map[string]string          `json:"annotations,omitempty"`
+	Labels       map[string]string          `json:"labels,omitempty"`
+	Provenance   models.Provenance          `json:"provenance,omitempty"`
+	Record       *Record                    `json:"record,omitempty"`
+	// Dependencies are the alert instances the rule depends on. While one of them is firing,
+	// the dependency action is applied to the alerts of the rule.
+	Dependencies     []models.AlertRuleDependency `json:"dependencies,omitempty"`
+	DependencyAction models.DependencyAction      `json:"dependencyAction,omitempty"`
+}
+
+// UpstreamModel returns the alert rule described by the payload.
+func (a *ProvisionedAlertRule) UpstreamModel() models.AlertRule {
+	return models.AlertRule{
+		ID:               a.ID,
+		UID:              a.UID,
+		OrgID:            a.OrgID,
+		NamespaceUID:     a.FolderUID,
+		RuleGroup:        a.RuleGroup,
+		Title:            a.Title,
+		Condition:        a.Condition,
+		Data:             a.Data,
+		Updated:          a.Updated,
+		NoDataState:      a.NoDataState,
+		ExecErrState:     a.ExecErrState,
+		For:              time.Duration(a.For),
+		Annotations:      a.Annotations,
+		Labels:           a.Labels,
+		RecordMetric:     a.Record.metric(),
+		Dependencies:     a.Dependencies,
+		DependencyAction: a.DependencyAction,
+	}
+}
+
+// NewAlertRule returns the payload of an alert rule with its provenance.
+func NewAlertRule(rule models.AlertRule, provenance models.Provenance) ProvisionedAlertRule {
+	result := ProvisionedAlertRule{
+		ID:               rule.ID,
+		UID:              rule.UID,
+		OrgID:            rule.OrgID,
+		FolderUID:        rule.NamespaceUID,
+		RuleGroup:        rule.RuleGroup,
+		Title:            rule.Title,
+		Condition:        rule.Condition,
+		Data:             rule.Data,
+		Updated:          rule.Updated,
+		NoDataState:      rule.NoDataState,
+		ExecErrState:     rule.ExecErrState,
+		For:              model.Duration(rule.For),
+		Annotations:      rule.Annotations,
+		Labels:           rule.Labels,
+		Provenance:       provenance,
+		Dependencies:     rule.Dependencies,
+		DependencyAction: rule.DependencyAction,
+	}
+	if rule.IsRecordingRule() {
+		result.Record = &Record{Metric: rule.RecordMetric}
+	}
+	return result
+}
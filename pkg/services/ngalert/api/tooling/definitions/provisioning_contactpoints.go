@@ -0,0 +1,60 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/provisioning/contact-points provisioning RouteGetContactpoints
+//
+// Get all the contact points.
+//
+//     Responses:
+//       200: ContactPoints
+
+// swagger:route PUT /api/provisioning/contact-points/{Name} provisioning RoutePutContactpoint
+//
+// Create or replace a contact point. The secure settings of its integrations are sent in plain text.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route DELETE /api/provisioning/contact-points/{Name} provisioning RouteDeleteContactpoint
+//
+// Delete a contact point.
+//
+//     Responses:
+//       204: Ack
+//       409: Failure
+
+// swagger:parameters RoutePutContactpoint RouteDeleteContactpoint
+type ContactPointNameReference struct {
+	// in:path
+	Name string
+}
+
+// swagger:parameters RoutePutContactpoint
+type ContactPointPayload struct {
+	// in:body
+	Body PostableContactPoint
+}
+
+// swagger:model
+type ContactPoints []GettableContactPoint
+
+// swagger:model
+type PostableContactPoint struct {
+	// required: true
+	Integrations []*PostableGrafanaReceiver `json:"integrations"`
+}
+
+// swagger:model
+type GettableContactPoint struct {
+	Name         string                     `json:"name"`
+	Integrations []*GettableGrafanaReceiver `json:"integrations"`
+	Provenance   models.Provenance          `json:"provenance,omitempty"`
+}
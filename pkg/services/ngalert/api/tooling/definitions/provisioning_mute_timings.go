@@ -0,0 +1,63 @@
+package definitions
+
+import (
+	"github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/provisioning/mute-timings provisioning RouteGetMuteTimings
+//
+// Get all the mute timings.
+//
+//     Responses:
+//       200: MuteTimings
+
+// swagger:route GET /api/provisioning/mute-timings/{Name} provisioning RouteGetMuteTiming
+//
+// Get a mute timing.
+//
+//     Responses:
+//       200: MuteTimeInterval
+//       404: Failure
+
+// swagger:route PUT /api/provisioning/mute-timings/{Name} provisioning RoutePutMuteTiming
+//
+// Create or replace a mute timing.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route DELETE /api/provisioning/mute-timings/{Name} provisioning RouteDeleteMuteTiming
+//
+// Delete a mute timing.
+//
+//     Responses:
+//       204: Ack
+//       409: Failure
+
+// swagger:parameters RouteGetMuteTiming RoutePutMuteTiming RouteDeleteMuteTiming
+type MuteTimingNameReference struct {
+	// in:path
+	Name string
+}
+
+// swagger:parameters RoutePutMuteTiming
+type MuteTimingPayload struct {
+	// in:body
+	Body MuteTimeInterval
+}
+
+// swagger:model
+type MuteTimings []MuteTimeInterval
+
+// swagger:model
+type MuteTimeInterval struct {
+	config.MuteTimeInterval `json:",inline" yaml:",inline"`
+	Provenance              models.Provenance `json:"provenance,omitempty"`
+}
@@ -0,0 +1,105 @@
+package definitions
+
+// swagger:route GET /api/provisioning/policies provisioning RouteGetPolicyTree
+//
+// Get the notification policy tree.
+//
+//     Responses:
+//       200: Route
+
+// swagger:route PUT /api/provisioning/policies provisioning RoutePutPolicyTree
+//
+// Replace the notification policy tree.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route DELETE /api/provisioning/policies provisioning RouteResetPolicyTree
+//
+// Reset the notification policy tree to the default.
+//
+//     Responses:
+//       202: Ack
+//       409: Failure
+
+// swagger:route GET /api/provisioning/policies/routes/{Path} provisioning RouteGetPolicy
+//
+// Get a nested notification policy.
+//
+//     Responses:
+//       200: Route
+//       400: ValidationError
+//       404: Failure
+
+// swagger:route POST /api/provisioning/policies/routes provisioning RoutePostPolicy
+//
+// Add a notification policy to the root policy.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route POST /api/provisioning/policies/routes/{Path} provisioning RoutePostNestedPolicy
+//
+// Add a notification policy to a nested policy.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route PUT /api/provisioning/policies/routes/{Path} provisioning RoutePutPolicy
+//
+// Replace a nested notification policy and its nested policies.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route DELETE /api/provisioning/policies/routes/{Path} provisioning RouteDeletePolicy
+//
+// Delete a nested notification policy and its nested policies.
+//
+//     Responses:
+//       204: Ack
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:parameters RoutePutPolicyTree
+type Policytree struct {
+	// The new notification routing tree to use
+	// in:body
+	Body Route
+}
+
+// swagger:parameters RouteGetPolicy RoutePostNestedPolicy RoutePutPolicy RouteDeletePolicy
+type PolicyPathReference struct {
+	// Dot separated indexes of the nested policies from the root policy, e.g. 0.2
+	// in:path
+	Path string
+}
+
+// swagger:parameters RoutePostPolicy RoutePostNestedPolicy RoutePutPolicy
+type PolicyPayload struct {
+	// in:body
+	Body Route
+}
@@ -0,0 +1,68 @@
+package definitions
+
+import (
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/provisioning/templates provisioning RouteGetTemplates
+//
+// Get all the message templates.
+//
+//     Responses:
+//       200: MessageTemplates
+
+// swagger:route GET /api/provisioning/templates/{Name} provisioning RouteGetTemplate
+//
+// Get a message template.
+//
+//     Responses:
+//       200: MessageTemplate
+//       404: Failure
+
+// swagger:route PUT /api/provisioning/templates/{Name} provisioning RoutePutTemplate
+//
+// Create or replace a message template.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route DELETE /api/provisioning/templates/{Name} provisioning RouteDeleteTemplate
+//
+// Delete a message template.
+//
+//     Responses:
+//       204: Ack
+//       409: Failure
+
+// swagger:parameters RouteGetTemplate RoutePutTemplate RouteDeleteTemplate
+type TemplateNameReference struct {
+	// in:path
+	Name string
+}
+
+// swagger:parameters RoutePutTemplate
+type TemplatePayload struct {
+	// in:body
+	Body MessageTemplateContent
+}
+
+// swagger:model
+type MessageTemplates []MessageTemplate
+
+// swagger:model
+type MessageTemplate struct {
+	Name       string            `json:"name"`
+	Template   string            `json:"template"`
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type MessageTemplateContent struct {
+	// required: true
+	Template string `json:"template"`
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -52,6 +53,20 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /api/v1/rule/backtest testing RouteBacktestConfig
+//
+// Replay a Grafana managed rule over a past time range
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BacktestResult
+//       400: ValidationError
+
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
 	// in:body
@@ -84,6 +99,44 @@ type EvalQueriesPayload struct {
 	Now  time.Time           `json:"now"`
 }
 
+// swagger:parameters RouteBacktestConfig
+type BacktestConfigRequest struct {
+	// in:body
+	Body BacktestConfig
+}
+
+// swagger:model
+type BacktestConfig struct {
+	// Start of the time range the rule is evaluated over.
+	From time.Time `json:"from"`
+	// End of the time range the rule is evaluated over.
+	To time.Time `json:"to"`
+	// Interval between the evaluations. Defaults to the default evaluation interval of the rules.
+	Interval model.Duration `json:"interval,omitempty"`
+	// Rule is the definition of the Grafana managed rule, as in a rule group of the Ruler API.
+	Rule PostableExtendedRuleNode `json:"rule"`
+}
+
+// swagger:model
+type BacktestResult struct {
+	// Instances are the state timelines of the alert instances, in the order they first appeared.
+	Instances []BacktestInstance `json:"instances"`
+	// Notifications is the number of times an alert instance started firing or was resolved.
+	Notifications int `json:"notifications"`
+}
+
+type BacktestInstance struct {
+	Labels map[string]string `json:"labels"`
+	// Timeline is the list of the states the alert instance moved to.
+	Timeline []BacktestStateChange `json:"timeline"`
+}
+
+type BacktestStateChange struct {
+	// Example: Pending
+	State       string    `json:"state"`
+	EvaluatedAt time.Time `json:"evaluatedAt"`
+}
+
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
 	type plain TestRulePayload
 	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
@@ -0,0 +1,125 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// MaxEvaluations is the maximum number of evaluations of a rule in a single backtest.
+const MaxEvaluations = 1000
+
+// ErrInvalidBacktest is returned when the time range or the interval of a backtest are not valid.
+var ErrInvalidBacktest = errors.New("invalid backtest")
+
+// Evaluator evaluates the condition of an alert rule at a point in time.
+type Evaluator interface {
+	ConditionEval(condition *models.Condition, now time.Time, expressionService *expr.Service) (eval.Results, error)
+}
+
+// StateChange is a state an alert instance moved to.
+type StateChange struct {
+	State       eval.State
+	EvaluatedAt time.Time
+}
+
+// InstanceTimeline is the list of the state changes of an alert instance.
+type InstanceTimeline struct {
+	Labels  data.Labels
+	Changes []StateChange
+}
+
+// Result is the outcome of a backtest.
+type Result struct {
+	// Instances are the timelines of the alert instances, in the order they first appeared.
+	Instances []*InstanceTimeline
+	// Notifications is the number of times an alert instance started firing or was resolved,
+	// that is, the number of notifications the Alertmanager would have sent.
+	Notifications int
+}
+
+// Engine replays alert rules over a past time range. The rules are evaluated at every interval, and the
+// results are processed by a dry run state manager, so the pending periods and the NoData and Error states
+// are handled exactly like in the scheduler. Nothing is persisted.
+type Engine struct {
+	log               log.Logger
+	evaluator         Evaluator
+	expressionService *expr.Service
+	appURL            *url.URL
+}
+
+func NewEngine(logger log.Logger, evaluator Evaluator, expressionService *expr.Service, appURL *url.URL) *Engine {
+	return &Engine{
+		log:               logger,
+		evaluator:         evaluator,
+		expressionService: expressionService,
+		appURL:            appURL,
+	}
+}
+
+// Test evaluates the rule at every interval of the rule between from and to, both included.
+func (e *Engine) Test(ctx context.Context, rule *models.AlertRule, from, to time.Time) (*Result, error) {
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		return nil, fmt.Errorf("%w: interval must be positive", ErrInvalidBacktest)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: start of the time range must be before its end", ErrInvalidBacktest)
+	}
+	if evaluations := int64(to.Sub(from)/interval) + 1; evaluations > MaxEvaluations {
+		return nil, fmt.Errorf("%w: %d evaluations exceed the maximum of %d, use a shorter time range or a longer interval", ErrInvalidBacktest, evaluations, MaxEvaluations)
+	}
+
+	clk := clock.NewMock()
+	manager := state.NewDryRunManager(e.log, e.appURL, clk)
+	condition := models.Condition{
+		Condition: rule.Condition,
+		OrgID:     rule.OrgID,
+		Data:      rule.Data,
+	}
+
+	result := &Result{}
+	timelines := make(map[string]*InstanceTimeline)
+	for now := from; !now.After(to); now = now.Add(interval) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		clk.Set(now)
+
+		results, err := e.evaluator.ConditionEval(&condition, now, e.expressionService)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate the rule at %s: %w", now, err)
+		}
+
+		for _, s := range manager.ProcessEvalResults(ctx, rule, results) {
+			timeline, ok := timelines[s.CacheId]
+			if !ok {
+				timeline = &InstanceTimeline{Labels: s.Labels}
+				timelines[s.CacheId] = timeline
+				result.Instances = append(result.Instances, timeline)
+			}
+			if n := len(timeline.Changes); n == 0 || timeline.Changes[n-1].State != s.State {
+				timeline.Changes = append(timeline.Changes, StateChange{State: s.State, EvaluatedAt: now})
+				if s.State == eval.Alerting {
+					result.Notifications++
+				}
+			}
+			if s.Resolved {
+				result.Notifications++
+			}
+		}
+	}
+	e.log.Debug("backtested alert rule", "title", rule.Title, "from", from, "to", to, "instances", len(result.Instances), "notifications", result.Notifications)
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package backtesting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeEvaluator struct {
+	evaluate func(now time.Time) eval.Results
+	calls    int
+}
+
+func (f *fakeEvaluator) ConditionEval(_ *models.Condition, now time.Time, _ *expr.Service) (eval.Results, error) {
+	f.calls++
+	return f.evaluate(now), nil
+}
+
+func TestEngine_Test(t *testing.T) {
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	rule := &models.AlertRule{
+		OrgID:           1,
+		UID:             "backtest",
+		Title:           "test",
+		Condition:       "A",
+		IntervalSeconds: 10,
+		For:             20 * time.Second,
+		NoDataState:     models.NoData,
+		ExecErrState:    models.AlertingErrState,
+	}
+
+	t.Run("replays the pending period and the resolution", func(t *testing.T) {
+		evaluator := &fakeEvaluator{evaluate: func(now time.Time) eval.Results {
+			state := eval.Normal
+			if offset := now.Sub(from); offset >= 30*time.Second && offset < 70*time.Second {
+				state = eval.Alerting
+			}
+			return eval.Results{{Instance: data.Labels{"instance": "a"}, State: state, EvaluatedAt: now}}
+		}}
+		engine := NewEngine(log.NewNopLogger(), evaluator, nil, nil)
+
+		result, err := engine.Test(context.Background(), rule, from, from.Add(100*time.Second))
+		require.NoError(t, err)
+		require.Equal(t, 11, evaluator.calls)
+		require.Len(t, result.Instances, 1)
+		require.Equal(t, "a", result.Instances[0].Labels["instance"])
+		require.Equal(t, []StateChange{
+			{State: eval.Normal, EvaluatedAt: from},
+			{State: eval.Pending, EvaluatedAt: from.Add(30 * time.Second)},
+			{State: eval.Alerting, EvaluatedAt: from.Add(50 * time.Second)},
+			{State: eval.Normal, EvaluatedAt: from.Add(70 * time.Second)},
+		}, result.Instances[0].Changes)
+		require.Equal(t, 2, result.Notifications)
+	})
+
+	t.Run("handles no data", func(t *testing.T) {
+		evaluator := &fakeEvaluator{evaluate: func(now time.Time) eval.Results {
+			return eval.Results{{Instance: data.Labels{}, State: eval.NoData, EvaluatedAt: now}}
+		}}
+		engine := NewEngine(log.NewNopLogger(), evaluator, nil, nil)
+
+		result, err := engine.Test(context.Background(), rule, from, from.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, result.Instances, 1)
+		require.Equal(t, []StateChange{{State: eval.NoData, EvaluatedAt: from}}, result.Instances[0].Changes)
+		require.Equal(t, 0, result.Notifications)
+	})
+
+	t.Run("fails if the time range is not valid", func(t *testing.T) {
+		engine := NewEngine(log.NewNopLogger(), &fakeEvaluator{}, nil, nil)
+
+		_, err := engine.Test(context.Background(), rule, from, from)
+		require.ErrorIs(t, err, ErrInvalidBacktest)
+	})
+
+	t.Run("fails if there are too many evaluations", func(t *testing.T) {
+		engine := NewEngine(log.NewNopLogger(), &fakeEvaluator{}, nil, nil)
+
+		_, err := engine.Test(context.Background(), rule, from, from.Add(MaxEvaluations*10*time.Second))
+		require.ErrorIs(t, err, ErrInvalidBacktest)
+	})
+}
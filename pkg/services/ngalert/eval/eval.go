@@ -123,10 +123,14 @@ const (
 	// Error is the eval state for an alert rule condition
 	// that evaluated to Error.
 	Error
+
+	// DependencyFailed is the state of an alert instance
+	// whose rule depends on alert instances that are firing.
+	DependencyFailed
 )
 
 func (s State) String() string {
-	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error"}[s]
+	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error", "DependencyFailed"}[s]
 }
 
 // AlertExecCtx is the context provided for executing an alert condition.
@@ -108,6 +108,18 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// RecordMetric is the name of the series the results of the rule are written to.
+	// A rule with a RecordMetric is a recording rule and does not produce alerts.
+	RecordMetric string `xorm:"record_metric"`
+	// Dependencies are the alert instances the rule depends on. While one of them is firing,
+	// DependencyAction is applied to the results of the rule.
+	Dependencies     []AlertRuleDependency
+	DependencyAction DependencyAction
+}
+
+// IsRecordingRule returns true if the results of the rule are written back as series instead of producing alerts.
+func (alertRule *AlertRule) IsRecordingRule() bool {
+	return alertRule.RecordMetric != ""
 }
 
 // Diff calculates diff between two alert rules. Returns nil if two rules are equal. Otherwise, returns cmputil.DiffReport
@@ -157,7 +169,7 @@ func (alertRule *AlertRule) PreSave(timeNow func() time.Time) error {
 }
 
 func (alertRule *AlertRule) ResourceType() string {
-	return "alertRule"
+	return ResourceTypeAlertRule
 }
 
 func (alertRule *AlertRule) ResourceID() string {
@@ -188,9 +200,12 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
+	For              time.Duration
+	Annotations      map[string]string
+	Labels           map[string]string
+	RecordMetric     string `xorm:"record_metric"`
+	Dependencies     []AlertRuleDependency
+	DependencyAction DependencyAction
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
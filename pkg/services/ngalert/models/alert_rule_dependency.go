@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// DependencyAction is what happens to the alert instances of a rule while one of its dependencies is failing.
+type DependencyAction string
+
+func (action DependencyAction) String() string {
+	return string(action)
+}
+
+func DependencyActionFromString(action string) (DependencyAction, error) {
+	switch action {
+	case string(SuppressDependencyAction):
+		return SuppressDependencyAction, nil
+	case string(FailDependencyAction):
+		return FailDependencyAction, nil
+	default:
+		return "", fmt.Errorf("unknown dependency action %s", action)
+	}
+}
+
+const (
+	// SuppressDependencyAction evaluates the alert instances as Normal.
+	SuppressDependencyAction DependencyAction = "Suppress"
+	// FailDependencyAction puts the alert instances in the DependencyFailed state.
+	FailDependencyAction DependencyAction = "DependencyFailed"
+)
+
+// AlertRuleDependency selects the alert instances an alert rule depends on. The dependency is
+// failing while at least one of the selected alert instances is firing.
+type AlertRuleDependency struct {
+	// RuleUID is the UID of the rule the alert instances belong to. If empty, the alert instances
+	// of all the rules of the organization are selected.
+	RuleUID string `json:"ruleUID,omitempty"`
+	// Matchers select the alert instances by their labels, e.g. service="db".
+	Matchers []string `json:"matchers,omitempty"`
+}
+
+// ParseMatchers returns the label matchers of the dependency.
+func (d AlertRuleDependency) ParseMatchers() (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(d.Matchers))
+	for _, s := range d.Matchers {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %w", s, err)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// Validate checks that the dependency selects the alert instances by rule or by labels, and
+// that its matchers are valid.
+func (d AlertRuleDependency) Validate() error {
+	if d.RuleUID == "" && len(d.Matchers) == 0 {
+		return errors.New("dependency must have a rule UID or matchers")
+	}
+	_, err := d.ParseMatchers()
+	return err
+}
+
+// DependsOn returns true if one of the dependencies of the rule selects the alert instances of the other
+// rule. The matchers are checked against the labels of the other rule, which all its alert instances carry.
+func (alertRule *AlertRule) DependsOn(other *AlertRule) bool {
+	if alertRule == other || alertRule.UID != "" && alertRule.UID == other.UID {
+		return false
+	}
+	for _, d := range alertRule.Dependencies {
+		if d.RuleUID != "" && d.RuleUID != other.UID {
+			continue
+		}
+		matchers, err := d.ParseMatchers()
+		if err != nil {
+			continue
+		}
+		selected := true
+		for _, m := range matchers {
+			if !m.Matches(other.Labels[m.Name]) {
+				selected = false
+				break
+			}
+		}
+		if selected {
+			return true
+		}
+	}
+	return false
+}
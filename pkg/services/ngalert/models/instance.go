@@ -31,6 +31,8 @@ const (
 	InstanceStateNoData InstanceStateType = "NoData"
 	// InstanceStateError is for a erroring alert.
 	InstanceStateError InstanceStateType = "Error"
+	// InstanceStateDependencyFailed is for an alert whose dependencies are firing.
+	InstanceStateDependencyFailed InstanceStateType = "DependencyFailed"
 )
 
 // IsValid checks that the value of InstanceStateType is a valid
@@ -40,7 +42,8 @@ func (i InstanceStateType) IsValid() bool {
 		i == InstanceStateNormal ||
 		i == InstanceStateNoData ||
 		i == InstanceStatePending ||
-		i == InstanceStateError
+		i == InstanceStateError ||
+		i == InstanceStateDependencyFailed
 }
 
 // SaveAlertInstanceCommand is the query for saving a new alert instance.
@@ -68,7 +71,9 @@ type GetAlertInstanceQuery struct {
 type ListAlertInstancesQuery struct {
 	RuleOrgID int64 `json:"-"`
 	RuleUID   string
-	State     InstanceStateType
+	// RuleUIDs selects the alert instances of several rules at once.
+	RuleUIDs []string
+	State    InstanceStateType
 
 	Result []*ListAlertInstancesQueryResult
 }
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+)
+
+// NotificationStatus is the outcome of an attempt to send a notification.
+type NotificationStatus string
+
+const (
+	NotificationStatusSuccess NotificationStatus = "success"
+	NotificationStatusFailed  NotificationStatus = "failed"
+)
+
+// NotificationLogEntry is a single attempt to send a notification with an integration of a contact point.
+type NotificationLogEntry struct {
+	ID              int64
+	OrgID           int64
+	Receiver        string
+	IntegrationUID  string
+	IntegrationName string
+	IntegrationType string
+	GroupKey        string
+	Payload         NotificationPayloadSummary
+	Status          NotificationStatus
+	Error           string
+	// Attempt is 1 for the notifications sent by the notification policies, and is incremented by the retry queue.
+	Attempt  int
+	Duration time.Duration
+	SentAt   time.Time
+}
+
+// NotificationPayloadSummary summarizes the alerts of a notification.
+type NotificationPayloadSummary struct {
+	GroupLabels map[string]string `json:"groupLabels,omitempty"`
+	Firing      int               `json:"firing"`
+	Resolved    int               `json:"resolved"`
+	AlertNames  []string          `json:"alertNames,omitempty"`
+}
+
+// GetNotificationLogQuery is the query for the notification attempts of an organization.
+type GetNotificationLogQuery struct {
+	OrgID          int64
+	Receiver       string
+	IntegrationUID string
+	Status         NotificationStatus
+	From           time.Time
+	To             time.Time
+	// Limit is the maximum number of attempts to return, the most recent ones are kept.
+	Limit int
+
+	Result []*NotificationLogEntry
+}
+
+// NotificationRetry is a failed notification waiting to be sent again. There is at most one retry
+// per integration and group of alerts, a newer failure replaces the pending retry.
+type NotificationRetry struct {
+	ID             int64
+	OrgID          int64
+	Receiver       string
+	IntegrationUID string
+	GroupKey       string
+	GroupLabels    map[string]string
+	Alerts         []*types.Alert
+	// Attempts is the number of attempts that failed so far.
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
+
+// GetNotificationRetriesQuery is the query for the pending retries of an organization.
+type GetNotificationRetriesQuery struct {
+	OrgID int64
+	// DueBefore only returns the retries that should be attempted before this time when it is not zero.
+	DueBefore time.Time
+	Limit     int
+
+	Result []*NotificationRetry
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+)
+
 type Provenance string
 
 const (
@@ -8,9 +13,68 @@ const (
 	ProvenanceFile Provenance = "file"
 )
 
+// ErrProvisionedResource is returned when a provisioned resource is changed with another provenance.
+var ErrProvisionedResource = errors.New("the resource is provisioned and cannot be changed")
+
+// CanUpdate returns nil when a resource that was provisioned with provenance p can be changed with
+// the given provenance. Resources that are not provisioned can be changed by anyone, files can
+// take over any resource, and other resources can only be changed with the same provenance.
+func (p Provenance) CanUpdate(provenance Provenance) error {
+	if p == ProvenanceNone || p == provenance || provenance == ProvenanceFile {
+		return nil
+	}
+	return fmt.Errorf("%w: it is provisioned with provenance %q", ErrProvisionedResource, p)
+}
+
 // Provisionable represents a resource that can be created through a provisioning mechanism, such as Terraform or config file.
 type Provisionable interface {
 	ResourceType() string
 	ResourceID() string
 	ResourceOrgID() int64
 }
+
+// The types of the resources that can be provisioned.
+const (
+	ResourceTypeAlertRule          = "alertRule"
+	ResourceTypeContactPoint       = "contactPoint"
+	ResourceTypeNotificationPolicy = "notificationPolicy"
+	ResourceTypeMuteTiming         = "muteTiming"
+	ResourceTypeTemplate           = "template"
+)
+
+// ConfigResource is a provisionable part of the Alertmanager configuration of an organization. Contact
+// points, mute timings and templates are identified by their name, and the notification policy tree
+// has no identifier as there is only one.
+type ConfigResource struct {
+	Type  string
+	Name  string
+	OrgID int64
+}
+
+func (r ConfigResource) ResourceType() string {
+	return r.Type
+}
+
+func (r ConfigResource) ResourceID() string {
+	return r.Name
+}
+
+func (r ConfigResource) ResourceOrgID() int64 {
+	return r.OrgID
+}
+
+func ContactPointResource(orgID int64, name string) ConfigResource {
+	return ConfigResource{Type: ResourceTypeContactPoint, Name: name, OrgID: orgID}
+}
+
+func NotificationPolicyResource(orgID int64) ConfigResource {
+	return ConfigResource{Type: ResourceTypeNotificationPolicy, OrgID: orgID}
+}
+
+func MuteTimingResource(orgID int64, name string) ConfigResource {
+	return ConfigResource{Type: ResourceTypeMuteTiming, Name: name, OrgID: orgID}
+}
+
+func TemplateResource(orgID int64, name string) ConfigResource {
+	return ConfigResource{Type: ResourceTypeTemplate, Name: name, OrgID: orgID}
+}
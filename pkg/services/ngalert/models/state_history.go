@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// AlertStateHistoryEntry is a single state transition of an alert instance. NamespaceUID is the
+// namespace of the rule at the time of the transition.
+type AlertStateHistoryEntry struct {
+	ID            int64
+	OrgID         int64
+	RuleUID       string
+	NamespaceUID  string
+	Labels        InstanceLabels
+	PreviousState InstanceStateType
+	State         InstanceStateType
+	// Values are the values of the queries and expressions of the evaluation that caused the transition.
+	Values      map[string]float64
+	EvaluatedAt time.Time
+}
+
+// GetStateHistoryQuery is the query for the state transitions of the alert instances of an organization.
+type GetStateHistoryQuery struct {
+	OrgID   int64
+	RuleUID string
+	// NamespaceUIDs limits the history to the rules of these namespaces when it is not nil.
+	NamespaceUIDs []string
+	// Matchers filter the transitions by the labels of the alert instance.
+	Matchers labels.Matchers
+	From     time.Time
+	To       time.Time
+	// Limit is the maximum number of transitions to return, the most recent ones are kept.
+	Limit int
+
+	Result []*AlertStateHistoryEntry
+}
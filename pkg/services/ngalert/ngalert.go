@@ -3,6 +3,7 @@ package ngalert
 import (
 	"context"
 	"net/url"
+	"time"
 
 	"github.com/benbjohnson/clock"
 	"golang.org/x/sync/errgroup"
@@ -11,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
@@ -19,9 +21,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/secrets"
@@ -32,7 +36,7 @@ import (
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, expressionService *expr.Service, dataProxy *datasourceproxy.DataSourceProxyService,
 	quotaService *quota.QuotaService, secretsService secrets.Service, notificationService notifications.Service, m *metrics.NGAlert,
-	folderService dashboards.FolderService, ac accesscontrol.AccessControl) (*AlertNG, error) {
+	folderService dashboards.FolderService, ac accesscontrol.AccessControl, serverLockService *serverlock.ServerLockService) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                 cfg,
 		DataSourceCache:     dataSourceCache,
@@ -48,6 +52,7 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		NotificationService: notificationService,
 		folderService:       folderService,
 		accesscontrol:       ac,
+		serverLockService:   serverLockService,
 	}
 
 	if ng.IsDisabled() {
@@ -82,6 +87,8 @@ type AlertNG struct {
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	accesscontrol        accesscontrol.AccessControl
+
+	serverLockService *serverlock.ServerLockService
 }
 
 func (ng *AlertNG) init() error {
@@ -97,7 +104,7 @@ func (ng *AlertNG) init() error {
 
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
-	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"))
+	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, store, ng.KVStore, decryptFn, multiOrgMetrics, ng.NotificationService, log.New("ngalert.multiorg.alertmanager"))
 	if err != nil {
 		return err
 	}
@@ -123,18 +130,38 @@ func (ng *AlertNG) init() error {
 		DisabledOrgs:            ng.Cfg.UnifiedAlerting.DisabledOrgs,
 		MinRuleInterval:         ng.Cfg.UnifiedAlerting.MinInterval,
 	}
+	if ng.Cfg.UnifiedAlerting.HARuleSharding {
+		if membership := ng.MultiOrgAlertmanager.ClusterMembership(); membership != nil {
+			schedCfg.ClusterMembership = membership
+			schedCfg.HandOffStore = ng.KVStore
+		} else {
+			ng.Log.Warn("alert rules are not sharded because high availability is not configured")
+		}
+	}
+	if ng.Cfg.UnifiedAlerting.RecordingRules.URL != "" {
+		schedCfg.RecordingWriter = writer.NewRemoteWriter(ng.Cfg.UnifiedAlerting.RecordingRules)
+	}
 
 	appUrl, err := url.Parse(ng.Cfg.AppURL)
 	if err != nil {
 		ng.Log.Error("Failed to parse application URL. Continue without it.", "error", err)
 		appUrl = nil
 	}
-	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, ng.SQLStore)
+	stateManager := state.NewManager(ng.Log, ng.Metrics.GetStateMetrics(), appUrl, store, store, store, ng.SQLStore)
 	scheduler := schedule.NewScheduler(schedCfg, ng.ExpressionService, appUrl, stateManager)
 
 	ng.stateManager = stateManager
 	ng.schedule = scheduler
 
+	// Provisioning
+	defaultConfig := ng.Cfg.UnifiedAlerting.DefaultConfiguration
+	provisioningLogger := log.New("ngalert.provisioning")
+	alertRuleService := provisioning.NewAlertRuleService(store, store, store, store.DefaultInterval, provisioningLogger)
+	contactPointService := provisioning.NewContactPointService(store, defaultConfig, store, store, ng.SecretsService, provisioningLogger)
+	policyService := provisioning.NewNotificationPolicyService(store, defaultConfig, store, store, provisioningLogger)
+	muteTimingService := provisioning.NewMuteTimingService(store, defaultConfig, store, store, provisioningLogger)
+	templateService := provisioning.NewTemplateService(store, defaultConfig, store, store, provisioningLogger)
+
 	api := api.API{
 		Cfg:                  ng.Cfg,
 		DatasourceCache:      ng.DataSourceCache,
@@ -148,9 +175,17 @@ func (ng *AlertNG) init() error {
 		RuleStore:            store,
 		AlertingStore:        store,
 		AdminConfigStore:     store,
+		ProvenanceStore:      store,
+		StateHistoryStore:    store,
+		NotificationLogStore: store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
 		AccessControl:        ng.accesscontrol,
+		AlertRules:           alertRuleService,
+		ContactPoints:        contactPointService,
+		Policies:             policyService,
+		MuteTimings:          muteTimingService,
+		Templates:            templateService,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -172,9 +207,41 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.MultiOrgAlertmanager.Run(subCtx)
 	})
+	if retention := ng.Cfg.UnifiedAlerting.StateHistoryRetention; retention > 0 {
+		children.Go(func() error {
+			return ng.runLocked(subCtx, "prune alert state history", state.HistoryPruneInterval, func(ctx context.Context) {
+				ng.stateManager.PruneStateHistory(ctx, retention)
+			})
+		})
+	}
+	if retention := ng.Cfg.UnifiedAlerting.NotificationLogRetention; retention > 0 {
+		children.Go(func() error {
+			return ng.runLocked(subCtx, "prune alerting notification log", notifier.NotificationLogPruneInterval, func(ctx context.Context) {
+				ng.MultiOrgAlertmanager.PruneNotificationLog(ctx, retention)
+			})
+		})
+	}
 	return children.Wait()
 }
 
+// runLocked runs fn every interval until the context is done. The server lock makes sure that only
+// one of the Grafana instances that share the database runs it in each interval.
+func (ng *AlertNG) runLocked(ctx context.Context, actionName string, interval time.Duration, fn func(context.Context)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := ng.serverLockService.LockAndExecute(ctx, actionName, interval, fn); err != nil {
+			ng.Log.Error("failed to lock and execute", "action", actionName, "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -124,24 +124,28 @@ type Alertmanager struct {
 	orgID           int64
 
 	decryptFn channels.GetDecryptedValueFn
+
+	// notificationLogStore records the notification attempts and queues the failed notifications, it is optional.
+	notificationLogStore store.NotificationLogStore
 }
 
-func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store store.AlertingStore, kvStore kvstore.KVStore,
+func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store store.AlertingStore, notificationLogStore store.NotificationLogStore, kvStore kvstore.KVStore,
 	peer ClusterPeer, decryptFn channels.GetDecryptedValueFn, ns notifications.Service, m *metrics.Alertmanager) (*Alertmanager, error) {
 	am := &Alertmanager{
-		Settings:            cfg,
-		stopc:               make(chan struct{}),
-		logger:              log.New("alertmanager", "org", orgID),
-		marker:              types.NewMarker(m.Registerer),
-		stageMetrics:        notify.NewMetrics(m.Registerer),
-		dispatcherMetrics:   dispatch.NewDispatcherMetrics(false, m.Registerer),
-		Store:               store,
-		peer:                peer,
-		peerTimeout:         cfg.UnifiedAlerting.HAPeerTimeout,
-		Metrics:             m,
-		NotificationService: ns,
-		orgID:               orgID,
-		decryptFn:           decryptFn,
+		Settings:             cfg,
+		stopc:                make(chan struct{}),
+		logger:               log.New("alertmanager", "org", orgID),
+		marker:               types.NewMarker(m.Registerer),
+		stageMetrics:         notify.NewMetrics(m.Registerer),
+		dispatcherMetrics:    dispatch.NewDispatcherMetrics(false, m.Registerer),
+		Store:                store,
+		notificationLogStore: notificationLogStore,
+		peer:                 peer,
+		peerTimeout:          cfg.UnifiedAlerting.HAPeerTimeout,
+		Metrics:              m,
+		NotificationService:  ns,
+		orgID:                orgID,
+		decryptFn:            decryptFn,
 	}
 
 	am.fileStore = NewFileStore(am.orgID, kvStore, am.WorkingDirPath())
@@ -197,6 +201,14 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store s
 		return nil, fmt.Errorf("unable to initialize the alert provider component of alerting: %w", err)
 	}
 
+	if am.notificationLogStore != nil && cfg.UnifiedAlerting.NotificationRetryMaxAttempts > 1 {
+		am.wg.Add(1)
+		go func() {
+			defer am.wg.Done()
+			am.runNotificationRetries()
+		}()
+	}
+
 	return am, nil
 }
 
@@ -459,7 +471,8 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableAp
 		if err != nil {
 			return nil, err
 		}
-		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
+		ln := am.newLoggingNotifier(n, r)
+		integrations = append(integrations, notify.NewIntegration(ln, ln, r.Type, i))
 	}
 	return integrations, nil
 }
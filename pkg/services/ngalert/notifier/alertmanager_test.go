@@ -50,7 +50,7 @@ func setupAMTest(t *testing.T) *Alertmanager {
 	kvStore := NewFakeKVStore(t)
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
 	decryptFn := secretsService.GetDecryptedValue
-	am, err := newAlertmanager(context.Background(), 1, cfg, s, kvStore, &NilPeer{}, decryptFn, nil, m)
+	am, err := newAlertmanager(context.Background(), 1, cfg, s, s, kvStore, &NilPeer{}, decryptFn, nil, m)
 	require.NoError(t, err)
 	return am
 }
@@ -626,6 +626,10 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 							Value: "PUT",
 							Label: "PUT",
 						},
+						{
+							Value: "PATCH",
+							Label: "PATCH",
+						},
 					},
 					PropertyName: "httpMethod",
 				},
@@ -649,6 +653,86 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 					InputType:    alerting.InputTypeText,
 					PropertyName: "maxAlerts",
 				},
+				{
+					Label:        "Body",
+					Description:  "Template of the body of the request. The body is the default webhook message if empty.",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "body",
+				},
+				{
+					Label:        "Content Type",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "application/json",
+					PropertyName: "contentType",
+				},
+				{
+					Label:        "Headers",
+					Description:  "Headers of the request",
+					Element:      alerting.ElementTypeKeyValueMap,
+					PropertyName: "headers",
+				},
+				{
+					Label:        "HMAC Secret",
+					Description:  "Signs the requests with HMAC-SHA256 over the timestamp of the request and the body",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypePassword,
+					PropertyName: "hmacSecret",
+					Secure:       true,
+				},
+				{
+					Label:        "HMAC Signature Header",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "X-Grafana-Signature",
+					PropertyName: "hmacSignatureHeader",
+				},
+				{
+					Label:        "HMAC Timestamp Header",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "X-Grafana-Timestamp",
+					PropertyName: "hmacTimestampHeader",
+				},
+				{
+					Label:        "TLS Client Certificate",
+					Description:  "PEM encoded client certificate for mutual TLS",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsClientCertificate",
+				},
+				{
+					Label:        "TLS Client Key",
+					Description:  "PEM encoded key of the client certificate",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsClientKey",
+					Secure:       true,
+				},
+				{
+					Label:        "TLS CA Certificate",
+					Description:  "PEM encoded certificate of the CA of the server",
+					Element:      alerting.ElementTypeTextArea,
+					PropertyName: "tlsCACertificate",
+				},
+				{
+					Label:        "Skip TLS Verify",
+					Element:      alerting.ElementTypeCheckbox,
+					PropertyName: "tlsSkipVerify",
+				},
+				{
+					Label:        "Max Retries",
+					Description:  "Number of times a request that fails with a network error, a 5xx or a 429 status is retried, at most 10",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					PropertyName: "maxRetries",
+				},
+				{
+					Label:        "Retry Backoff",
+					Description:  "Wait before the first retry, doubled at every retry up to 1m",
+					Element:      alerting.ElementTypeInput,
+					InputType:    alerting.InputTypeText,
+					Placeholder:  "1s",
+					PropertyName: "retryBackoff",
+				},
 			},
 		},
 		{
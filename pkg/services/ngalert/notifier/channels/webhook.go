@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -18,15 +22,21 @@ import (
 // alert notifications as webhooks.
 type WebhookNotifier struct {
 	*Base
-	URL        string
-	User       string
-	Password   string
-	HTTPMethod string
-	MaxAlerts  int
-	log        log.Logger
-	ns         notifications.WebhookSender
-	tmpl       *template.Template
-	orgID      int64
+	URL         string
+	User        string
+	Password    string
+	HTTPMethod  string
+	MaxAlerts   int
+	Body        string
+	ContentType string
+	Headers     map[string]string
+	Signing     *models.WebhookSigning
+	TLS         *models.WebhookTLS
+	Retry       *models.WebhookRetry
+	log         log.Logger
+	ns          notifications.WebhookSender
+	tmpl        *template.Template
+	orgID       int64
 }
 
 type WebhookConfig struct {
@@ -36,6 +46,13 @@ type WebhookConfig struct {
 	Password   string
 	HTTPMethod string
 	MaxAlerts  int
+	// Body is the template of the body of the requests. The body is the JSON webhook message if empty.
+	Body        string
+	ContentType string
+	Headers     map[string]string
+	Signing     *models.WebhookSigning
+	TLS         *models.WebhookTLS
+	Retry       *models.WebhookRetry
 }
 
 func WebHookFactory(fc FactoryConfig) (NotificationChannel, error) {
@@ -54,14 +71,71 @@ func NewWebHookConfig(config *NotificationChannelConfig, decryptFunc GetDecrypte
 	if url == "" {
 		return nil, errors.New("could not find url property in settings")
 	}
-	return &WebhookConfig{
+	httpMethod := config.Settings.Get("httpMethod").MustString(http.MethodPost)
+	if httpMethod != http.MethodPost && httpMethod != http.MethodPut && httpMethod != http.MethodPatch {
+		return nil, fmt.Errorf("unsupported HTTP method %s, use POST, PUT or PATCH", httpMethod)
+	}
+	cfg := &WebhookConfig{
 		NotificationChannelConfig: config,
 		URL:                       url,
 		User:                      config.Settings.Get("username").MustString(),
 		Password:                  decryptFunc(context.Background(), config.SecureSettings, "password", config.Settings.Get("password").MustString()),
-		HTTPMethod:                config.Settings.Get("httpMethod").MustString("POST"),
+		HTTPMethod:                httpMethod,
 		MaxAlerts:                 config.Settings.Get("maxAlerts").MustInt(0),
-	}, nil
+		Body:                      config.Settings.Get("body").MustString(),
+		ContentType:               config.Settings.Get("contentType").MustString(),
+	}
+
+	headers, err := config.Settings.Get("headers").Map()
+	if err == nil {
+		cfg.Headers = make(map[string]string, len(headers))
+		for k, v := range headers {
+			value, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("value of the header %s must be a string", k)
+			}
+			cfg.Headers[k] = value
+		}
+	}
+
+	if secret := decryptFunc(context.Background(), config.SecureSettings, "hmacSecret", config.Settings.Get("hmacSecret").MustString()); secret != "" {
+		cfg.Signing = &models.WebhookSigning{
+			Secret:          secret,
+			SignatureHeader: config.Settings.Get("hmacSignatureHeader").MustString(),
+			TimestampHeader: config.Settings.Get("hmacTimestampHeader").MustString(),
+		}
+	}
+
+	tlsConfig := models.WebhookTLS{
+		ClientCertificate:  config.Settings.Get("tlsClientCertificate").MustString(),
+		ClientKey:          decryptFunc(context.Background(), config.SecureSettings, "tlsClientKey", config.Settings.Get("tlsClientKey").MustString()),
+		CACertificate:      config.Settings.Get("tlsCACertificate").MustString(),
+		InsecureSkipVerify: config.Settings.Get("tlsSkipVerify").MustBool(false),
+	}
+	if (tlsConfig.ClientCertificate == "") != (tlsConfig.ClientKey == "") {
+		return nil, errors.New("both the client certificate and the client key must be set")
+	}
+	if tlsConfig != (models.WebhookTLS{}) {
+		cfg.TLS = &tlsConfig
+	}
+
+	// The max retries are a string when set in the UI, and a number when provisioned.
+	maxRetries := 0
+	if v := config.Settings.Get("maxRetries").Interface(); v != nil && v != "" {
+		if maxRetries, err = strconv.Atoi(fmt.Sprint(v)); err != nil || maxRetries < 0 || maxRetries > models.MaxWebhookRetries {
+			return nil, fmt.Errorf("invalid max retries %v, it must be between 0 and %d", v, models.MaxWebhookRetries)
+		}
+	}
+	if maxRetries > 0 {
+		backoff := time.Second
+		if s := config.Settings.Get("retryBackoff").MustString(); s != "" {
+			if backoff, err = time.ParseDuration(s); err != nil || backoff <= 0 {
+				return nil, fmt.Errorf("invalid retry backoff %q", s)
+			}
+		}
+		cfg.Retry = &models.WebhookRetry{MaxRetries: maxRetries, Backoff: backoff}
+	}
+	return cfg, nil
 }
 
 // NewWebHookNotifier is the constructor for
@@ -75,15 +149,21 @@ func NewWebHookNotifier(config *WebhookConfig, ns notifications.WebhookSender, t
 			DisableResolveMessage: config.DisableResolveMessage,
 			Settings:              config.Settings,
 		}),
-		orgID:      config.OrgID,
-		URL:        config.URL,
-		User:       config.User,
-		Password:   config.Password,
-		HTTPMethod: config.HTTPMethod,
-		MaxAlerts:  config.MaxAlerts,
-		log:        log.New("alerting.notifier.webhook"),
-		ns:         ns,
-		tmpl:       t,
+		orgID:       config.OrgID,
+		URL:         config.URL,
+		User:        config.User,
+		Password:    config.Password,
+		HTTPMethod:  config.HTTPMethod,
+		MaxAlerts:   config.MaxAlerts,
+		Body:        config.Body,
+		ContentType: config.ContentType,
+		Headers:     config.Headers,
+		Signing:     config.Signing,
+		TLS:         config.TLS,
+		Retry:       config.Retry,
+		log:         log.New("alerting.notifier.webhook"),
+		ns:          ns,
+		tmpl:        t,
 	}
 }
 
@@ -131,19 +211,35 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 
 	if tmplErr != nil {
 		wn.log.Warn("failed to template webhook message", "err", tmplErr.Error())
+		tmplErr = nil
 	}
 
-	body, err := json.Marshal(msg)
-	if err != nil {
-		return false, err
+	var body string
+	if wn.Body != "" {
+		// A custom body that cannot be templated is not sent, as the receiver could not parse it.
+		body = tmpl(wn.Body)
+		if tmplErr != nil {
+			return false, fmt.Errorf("failed to template webhook body: %w", tmplErr)
+		}
+	} else {
+		b, err := json.Marshal(msg)
+		if err != nil {
+			return false, err
+		}
+		body = string(b)
 	}
 
 	cmd := &models.SendWebhookSync{
-		Url:        wn.URL,
-		User:       wn.User,
-		Password:   wn.Password,
-		Body:       string(body),
-		HttpMethod: wn.HTTPMethod,
+		Url:         wn.URL,
+		User:        wn.User,
+		Password:    wn.Password,
+		Body:        body,
+		HttpMethod:  wn.HTTPMethod,
+		HttpHeader:  wn.Headers,
+		ContentType: wn.ContentType,
+		Signing:     wn.Signing,
+		TLS:         wn.TLS,
+		Retry:       wn.Retry,
 	}
 
 	if err := wn.ns.SendWebhookSync(ctx, cmd); err != nil {
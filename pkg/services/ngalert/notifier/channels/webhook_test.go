@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 
@@ -226,3 +229,120 @@ func TestWebhookNotifier(t *testing.T) {
 		})
 	}
 }
+
+func TestWebhookNotifier_Options(t *testing.T) {
+	tmpl := templateForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+
+	newConfig := func(t *testing.T, settings string, secureSettings map[string]string) (*WebhookConfig, error) {
+		t.Helper()
+		settingsJSON, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		encrypted := make(map[string][]byte, len(secureSettings))
+		for k, v := range secureSettings {
+			encrypted[k], err = secretsService.Encrypt(context.Background(), []byte(v), secrets.WithoutScope())
+			require.NoError(t, err)
+		}
+		return NewWebHookConfig(&NotificationChannelConfig{
+			OrgID:          1,
+			Name:           "webhook_testing",
+			Type:           "webhook",
+			Settings:       settingsJSON,
+			SecureSettings: encrypted,
+		}, secretsService.GetDecryptedValue)
+	}
+
+	t.Run("sends a templated body with the options of the request", func(t *testing.T) {
+		cfg, err := newConfig(t, `{
+			"url": "http://localhost/test",
+			"httpMethod": "PATCH",
+			"body": "{\"summary\": \"{{ .Status }} {{ len .Alerts }}\"}",
+			"contentType": "application/vnd.incident+json",
+			"headers": {"X-Team": "ops"},
+			"hmacSignatureHeader": "X-Signature",
+			"tlsClientCertificate": "cert",
+			"tlsSkipVerify": true,
+			"maxRetries": "3",
+			"retryBackoff": "2s"
+		}`, map[string]string{"hmacSecret": "secret", "tlsClientKey": "key"})
+		require.NoError(t, err)
+
+		webhookSender := mockNotificationService()
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		ok, err := NewWebHookNotifier(cfg, webhookSender, tmpl).Notify(ctx, &types.Alert{
+			Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}},
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		sent := webhookSender.Webhook
+		require.Equal(t, `{"summary": "firing 1"}`, sent.Body)
+		require.Equal(t, "PATCH", sent.HttpMethod)
+		require.Equal(t, "application/vnd.incident+json", sent.ContentType)
+		require.Equal(t, map[string]string{"X-Team": "ops"}, sent.HttpHeader)
+		require.Equal(t, &models.WebhookSigning{Secret: "secret", SignatureHeader: "X-Signature"}, sent.Signing)
+		require.Equal(t, &models.WebhookTLS{ClientCertificate: "cert", ClientKey: "key", InsecureSkipVerify: true}, sent.TLS)
+		require.Equal(t, &models.WebhookRetry{MaxRetries: 3, Backoff: 2 * time.Second}, sent.Retry)
+	})
+
+	t.Run("does not send a body that cannot be templated", func(t *testing.T) {
+		cfg, err := newConfig(t, `{"url": "http://localhost/test", "body": "{{ .Missing }}"}`, nil)
+		require.NoError(t, err)
+
+		webhookSender := mockNotificationService()
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ok, err := NewWebHookNotifier(cfg, webhookSender, tmpl).Notify(ctx, &types.Alert{
+			Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}},
+		})
+		require.Error(t, err)
+		require.False(t, ok)
+		require.Empty(t, webhookSender.Webhook.Url)
+	})
+
+	invalidCases := []struct {
+		name     string
+		settings string
+		expError string
+	}{
+		{
+			name:     "unsupported HTTP method",
+			settings: `{"url": "http://localhost/test", "httpMethod": "GET"}`,
+			expError: "unsupported HTTP method GET, use POST, PUT or PATCH",
+		},
+		{
+			name:     "client certificate without key",
+			settings: `{"url": "http://localhost/test", "tlsClientCertificate": "cert"}`,
+			expError: "both the client certificate and the client key must be set",
+		},
+		{
+			name:     "invalid max retries",
+			settings: `{"url": "http://localhost/test", "maxRetries": "many"}`,
+			expError: "invalid max retries many, it must be between 0 and 10",
+		},
+		{
+			name:     "too many max retries",
+			settings: `{"url": "http://localhost/test", "maxRetries": 100}`,
+			expError: "invalid max retries 100, it must be between 0 and 10",
+		},
+		{
+			name:     "invalid retry backoff",
+			settings: `{"url": "http://localhost/test", "maxRetries": 2, "retryBackoff": "soon"}`,
+			expError: `invalid retry backoff "soon"`,
+		},
+		{
+			name:     "header that is not a string",
+			settings: `{"url": "http://localhost/test", "headers": {"X-Count": 1}}`,
+			expError: "value of the header X-Count must be a string",
+		},
+	}
+	for _, c := range invalidCases {
+		t.Run("fails with "+c.name, func(t *testing.T) {
+			_, err := newConfig(t, c.settings, nil)
+			require.EqualError(t, err, c.expError)
+		})
+	}
+}
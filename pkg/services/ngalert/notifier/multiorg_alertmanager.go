@@ -39,9 +39,10 @@ type MultiOrgAlertmanager struct {
 	peer         ClusterPeer
 	settleCancel context.CancelFunc
 
-	configStore store.AlertingStore
-	orgStore    store.OrgStore
-	kvStore     kvstore.KVStore
+	configStore          store.AlertingStore
+	orgStore             store.OrgStore
+	notificationLogStore store.NotificationLogStore
+	kvStore              kvstore.KVStore
 
 	decryptFn channels.GetDecryptedValueFn
 
@@ -49,20 +50,21 @@ type MultiOrgAlertmanager struct {
 	ns      notifications.Service
 }
 
-func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore, orgStore store.OrgStore,
+func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore, orgStore store.OrgStore, notificationLogStore store.NotificationLogStore,
 	kvStore kvstore.KVStore, decryptFn channels.GetDecryptedValueFn, m *metrics.MultiOrgAlertmanager,
 	ns notifications.Service, l log.Logger,
 ) (*MultiOrgAlertmanager, error) {
 	moa := &MultiOrgAlertmanager{
-		logger:        l,
-		settings:      cfg,
-		alertmanagers: map[int64]*Alertmanager{},
-		configStore:   configStore,
-		orgStore:      orgStore,
-		kvStore:       kvStore,
-		decryptFn:     decryptFn,
-		metrics:       m,
-		ns:            ns,
+		logger:               l,
+		settings:             cfg,
+		alertmanagers:        map[int64]*Alertmanager{},
+		configStore:          configStore,
+		orgStore:             orgStore,
+		notificationLogStore: notificationLogStore,
+		kvStore:              kvStore,
+		decryptFn:            decryptFn,
+		metrics:              m,
+		ns:                   ns,
 	}
 
 	clusterLogger := l.New("component", "cluster")
@@ -174,7 +176,7 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 			// To export them, we need to translate the metrics from each individual registry and,
 			// then aggregate them on the main registry.
 			m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID))
-			am, err := newAlertmanager(ctx, orgID, moa.settings, moa.configStore, moa.kvStore, moa.peer, moa.decryptFn, moa.ns, m)
+			am, err := newAlertmanager(ctx, orgID, moa.settings, moa.configStore, moa.notificationLogStore, moa.kvStore, moa.peer, moa.decryptFn, moa.ns, m)
 			if err != nil {
 				moa.logger.Error("unable to create Alertmanager for org", "org", orgID, "err", err)
 			}
@@ -320,6 +322,36 @@ func (moa *MultiOrgAlertmanager) AlertmanagerFor(orgID int64) (*Alertmanager, er
 	return orgAM, nil
 }
 
+// ClusterMembership returns the members of the HA cluster the Alertmanagers gossip with,
+// or nil if Grafana does not run in HA mode.
+func (moa *MultiOrgAlertmanager) ClusterMembership() *PeerMembership {
+	p, ok := moa.peer.(*cluster.Peer)
+	if !ok {
+		return nil
+	}
+	return &PeerMembership{peer: p}
+}
+
+// PeerMembership lists the instances of the HA cluster through the gossip peer of the Alertmanagers.
+type PeerMembership struct {
+	peer *cluster.Peer
+}
+
+// Self returns the name of this instance in the cluster.
+func (m *PeerMembership) Self() string {
+	return m.peer.Name()
+}
+
+// Members returns the names of the alive instances of the cluster, including this one.
+func (m *PeerMembership) Members() []string {
+	peers := m.peer.Peers()
+	members := make([]string, 0, len(peers))
+	for _, p := range peers {
+		members = append(members, p.Name())
+	}
+	return members
+}
+
 // NilPeer and NilChannel implements the Alertmanager clustering interface.
 type NilPeer struct{}
 
@@ -0,0 +1,286 @@
+package notifier
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+const (
+	// notificationRetryPollInterval is how often the queue of failed notifications is checked for due retries.
+	notificationRetryPollInterval = 10 * time.Second
+	// notificationRetryBatchSize is the maximum number of retries sent at each poll.
+	notificationRetryBatchSize = 100
+	// notificationRetryTimeout is the timeout of a retry. A retry is claimed for this duration, so
+	// that other Grafana instances do not send it at the same time.
+	notificationRetryTimeout = time.Minute
+	// maxSummaryAlertNames is the maximum number of alert names in the payload summary of a notification attempt.
+	maxSummaryAlertNames = 10
+)
+
+// attemptKey is the context key of the attempt number of the notifications sent by the retry queue.
+type attemptKey struct{}
+
+// loggingNotifier records the attempts of an integration of a contact point in the notification log,
+// and queues the notifications that failed so they are sent again.
+type loggingNotifier struct {
+	channels.NotificationChannel
+	am  *Alertmanager
+	cfg *apimodels.PostableGrafanaReceiver
+}
+
+func (am *Alertmanager) newLoggingNotifier(n channels.NotificationChannel, cfg *apimodels.PostableGrafanaReceiver) *loggingNotifier {
+	return &loggingNotifier{NotificationChannel: n, am: am, cfg: cfg}
+}
+
+func (n *loggingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	start := time.Now()
+	retry, err := n.NotificationChannel.Notify(ctx, alerts...)
+	n.am.recordNotification(ctx, n.cfg, alerts, start, err)
+	return retry, err
+}
+
+// recordNotification saves a notification attempt in the notification log. A failed notification
+// is queued until the maximum number of attempts is reached, and the pending retry of a group of
+// alerts is deleted once a notification of the group is sent.
+func (am *Alertmanager) recordNotification(ctx context.Context, cfg *apimodels.PostableGrafanaReceiver, alerts []*types.Alert, start time.Time, notifyErr error) {
+	if am.notificationLogStore == nil {
+		return
+	}
+
+	attempt, ok := ctx.Value(attemptKey{}).(int)
+	if !ok {
+		attempt = 1
+	}
+	receiver, _ := notify.ReceiverName(ctx)
+	groupKey, _ := notify.GroupKey(ctx)
+	groupLabels, _ := notify.GroupLabels(ctx)
+
+	// the context of the notification is done if the notification timed out
+	ctx = context.Background()
+	logger := am.logger.New("receiver", receiver, "integration", cfg.Name, "type", cfg.Type, "attempt", attempt)
+
+	entry := &ngmodels.NotificationLogEntry{
+		OrgID:           am.orgID,
+		Receiver:        receiver,
+		IntegrationUID:  cfg.UID,
+		IntegrationName: cfg.Name,
+		IntegrationType: cfg.Type,
+		GroupKey:        groupKey,
+		Payload:         summarizeAlerts(groupLabels, alerts),
+		Status:          ngmodels.NotificationStatusSuccess,
+		Attempt:         attempt,
+		Duration:        time.Since(start),
+		SentAt:          start,
+	}
+	if notifyErr != nil {
+		entry.Status = ngmodels.NotificationStatusFailed
+		entry.Error = notifyErr.Error()
+	}
+	if err := am.notificationLogStore.SaveNotificationLog(ctx, entry); err != nil {
+		logger.Error("failed to save the notification attempt", "err", err)
+	}
+
+	maxAttempts := am.Settings.UnifiedAlerting.NotificationRetryMaxAttempts
+	if maxAttempts <= 1 {
+		return
+	}
+
+	retry := &ngmodels.NotificationRetry{
+		OrgID:          am.orgID,
+		Receiver:       receiver,
+		IntegrationUID: cfg.UID,
+		GroupKey:       groupKey,
+	}
+	if notifyErr == nil || attempt >= maxAttempts {
+		if notifyErr != nil {
+			logger.Warn("giving up sending the notification", "err", notifyErr)
+		}
+		if err := am.notificationLogStore.DeleteNotificationRetry(ctx, retry); err != nil {
+			logger.Error("failed to delete the pending retry of the notification", "err", err)
+		}
+		return
+	}
+
+	retry.GroupLabels = make(map[string]string, len(groupLabels))
+	for k, v := range groupLabels {
+		retry.GroupLabels[string(k)] = string(v)
+	}
+	retry.Alerts = alerts
+	retry.Attempts = attempt
+	retry.LastError = notifyErr.Error()
+	retry.CreatedAt = time.Now()
+	retry.NextAttemptAt = retry.CreatedAt.Add(am.Settings.UnifiedAlerting.NotificationRetryBackoff << (attempt - 1))
+	if err := am.notificationLogStore.SaveNotificationRetry(ctx, retry); err != nil {
+		logger.Error("failed to queue the notification", "err", err)
+		return
+	}
+	logger.Debug("queued the notification", "next_attempt", retry.NextAttemptAt)
+}
+
+func summarizeAlerts(groupLabels model.LabelSet, alerts []*types.Alert) ngmodels.NotificationPayloadSummary {
+	summary := ngmodels.NotificationPayloadSummary{}
+	if len(groupLabels) > 0 {
+		summary.GroupLabels = make(map[string]string, len(groupLabels))
+		for k, v := range groupLabels {
+			summary.GroupLabels[string(k)] = string(v)
+		}
+	}
+
+	names := make(map[string]struct{})
+	for _, alert := range alerts {
+		if alert.Resolved() {
+			summary.Resolved++
+		} else {
+			summary.Firing++
+		}
+		if name := string(alert.Labels[model.AlertNameLabel]); name != "" {
+			names[name] = struct{}{}
+		}
+	}
+	for name := range names {
+		summary.AlertNames = append(summary.AlertNames, name)
+	}
+	sort.Strings(summary.AlertNames)
+	if len(summary.AlertNames) > maxSummaryAlertNames {
+		summary.AlertNames = summary.AlertNames[:maxSummaryAlertNames]
+	}
+	return summary
+}
+
+// runNotificationRetries sends the failed notifications again when they are due, until the Alertmanager is stopped.
+func (am *Alertmanager) runNotificationRetries() {
+	ticker := time.NewTicker(notificationRetryPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-am.stopc:
+			return
+		case <-ticker.C:
+			am.processNotificationRetries(context.Background())
+		}
+	}
+}
+
+func (am *Alertmanager) processNotificationRetries(ctx context.Context) {
+	if !am.Ready() {
+		return
+	}
+
+	now := time.Now()
+	q := ngmodels.GetNotificationRetriesQuery{
+		OrgID:     am.orgID,
+		DueBefore: now,
+		Limit:     notificationRetryBatchSize,
+	}
+	if err := am.notificationLogStore.GetNotificationRetries(ctx, &q); err != nil {
+		am.logger.Error("failed to get the pending notification retries", "err", err)
+		return
+	}
+	if len(q.Result) == 0 {
+		return
+	}
+
+	tmpl, err := am.getTemplate()
+	if err != nil {
+		am.logger.Error("failed to get the notification templates", "err", err)
+		return
+	}
+
+	for _, retry := range q.Result {
+		claimed, err := am.notificationLogStore.ClaimNotificationRetry(ctx, retry, now.Add(notificationRetryTimeout))
+		if err != nil {
+			am.logger.Error("failed to claim the notification retry", "err", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		am.sendNotificationRetry(ctx, retry, tmpl)
+	}
+}
+
+// sendNotificationRetry sends a queued notification with the current configuration of its integration.
+// The retry is dropped if the integration does not exist anymore.
+func (am *Alertmanager) sendNotificationRetry(ctx context.Context, retry *ngmodels.NotificationRetry, tmpl *template.Template) {
+	logger := am.logger.New("receiver", retry.Receiver, "integration_uid", retry.IntegrationUID, "attempt", retry.Attempts+1)
+
+	cfg := am.findIntegration(retry.Receiver, retry.IntegrationUID)
+	if cfg == nil {
+		logger.Warn("dropping the notification retry, the integration does not exist anymore")
+		if err := am.notificationLogStore.DeleteNotificationRetry(ctx, retry); err != nil {
+			logger.Error("failed to delete the notification retry", "err", err)
+		}
+		return
+	}
+	n, err := am.buildReceiverIntegration(cfg, tmpl)
+	if err != nil {
+		logger.Error("failed to build the integration of the notification retry", "err", err)
+		return
+	}
+
+	groupLabels := make(model.LabelSet, len(retry.GroupLabels))
+	for k, v := range retry.GroupLabels {
+		groupLabels[model.LabelName(k)] = model.LabelValue(v)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notificationRetryTimeout)
+	defer cancel()
+	ctx = notify.WithReceiverName(ctx, retry.Receiver)
+	ctx = notify.WithGroupKey(ctx, retry.GroupKey)
+	ctx = notify.WithGroupLabels(ctx, groupLabels)
+	ctx = notify.WithNow(ctx, time.Now())
+	ctx = context.WithValue(ctx, attemptKey{}, retry.Attempts+1)
+
+	if _, err := am.newLoggingNotifier(n, cfg).Notify(ctx, retry.Alerts...); err != nil {
+		logger.Warn("failed to send the notification retry", "err", err)
+		return
+	}
+	logger.Info("sent the notification retry")
+}
+
+// findIntegration returns the configuration of an integration of a contact point of the current configuration.
+func (am *Alertmanager) findIntegration(receiver, uid string) *apimodels.PostableGrafanaReceiver {
+	am.reloadConfigMtx.RLock()
+	defer am.reloadConfigMtx.RUnlock()
+	if !am.ready() {
+		return nil
+	}
+	for _, r := range am.config.AlertmanagerConfig.Receivers {
+		if r.Name != receiver {
+			continue
+		}
+		for _, cfg := range r.GrafanaManagedReceivers {
+			if cfg.UID == uid {
+				return cfg
+			}
+		}
+	}
+	return nil
+}
+
+// NotificationLogPruneInterval is how often the notification attempts older than the retention are deleted.
+var NotificationLogPruneInterval = time.Hour
+
+// PruneNotificationLog deletes the notification attempts older than the retention.
+func (moa *MultiOrgAlertmanager) PruneNotificationLog(ctx context.Context, retention time.Duration) {
+	if moa.notificationLogStore == nil || retention <= 0 {
+		return
+	}
+
+	deleted, err := moa.notificationLogStore.DeleteNotificationLogBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		moa.logger.Error("unable to prune the notification log", "err", err)
+		return
+	}
+	moa.logger.Debug("pruned the notification log", "deleted", deleted)
+}
@@ -0,0 +1,257 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const notificationLogTestConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "ops"
+		},
+		"receivers": [{
+			"name": "ops",
+			"grafana_managed_receiver_configs": [{
+				"uid": "webhook-uid",
+				"name": "ops webhook",
+				"type": "webhook",
+				"settings": {
+					"url": "http://localhost/hook"
+				}
+			}]
+		}]
+	}
+}`
+
+func TestNotificationLog(t *testing.T) {
+	am, logStore, ns := setupNotificationLogTest(t)
+
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "HighCPU", "team": "ops"},
+			StartsAt: time.Now(),
+			EndsAt:   time.Now().Add(time.Hour),
+		},
+	}
+	ctx := notify.WithReceiverName(context.Background(), "ops")
+	ctx = notify.WithGroupKey(ctx, "{}:{team=\"ops\"}")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"team": "ops"})
+
+	tmpl, err := am.getTemplate()
+	require.NoError(t, err)
+	cfg := am.findIntegration("ops", "webhook-uid")
+	require.NotNil(t, cfg)
+	n, err := am.buildReceiverIntegration(cfg, tmpl)
+	require.NoError(t, err)
+	notifier := am.newLoggingNotifier(n, cfg)
+
+	t.Run("records and queues the failed notifications", func(t *testing.T) {
+		ns.ShouldError = errors.New("connection refused")
+		_, err := notifier.Notify(ctx, alert)
+		require.Error(t, err)
+
+		require.Len(t, logStore.entries, 1)
+		entry := logStore.entries[0]
+		require.Equal(t, "ops", entry.Receiver)
+		require.Equal(t, "webhook-uid", entry.IntegrationUID)
+		require.Equal(t, "ops webhook", entry.IntegrationName)
+		require.Equal(t, "webhook", entry.IntegrationType)
+		require.Equal(t, ngmodels.NotificationStatusFailed, entry.Status)
+		require.Equal(t, "connection refused", entry.Error)
+		require.Equal(t, 1, entry.Attempt)
+		require.Equal(t, ngmodels.NotificationPayloadSummary{
+			GroupLabels: map[string]string{"team": "ops"},
+			Firing:      1,
+			AlertNames:  []string{"HighCPU"},
+		}, entry.Payload)
+
+		require.Len(t, logStore.retries, 1)
+		retry := logStore.retries[0]
+		require.Equal(t, 1, retry.Attempts)
+		require.Equal(t, "connection refused", retry.LastError)
+		require.Equal(t, map[string]string{"team": "ops"}, retry.GroupLabels)
+		require.WithinDuration(t, time.Now().Add(time.Minute), retry.NextAttemptAt, 5*time.Second)
+	})
+
+	t.Run("does not send the retries that are not due", func(t *testing.T) {
+		am.processNotificationRetries(context.Background())
+		require.Len(t, logStore.entries, 1)
+	})
+
+	t.Run("sends the due retries", func(t *testing.T) {
+		ns.ShouldError = nil
+		logStore.retries[0].NextAttemptAt = time.Now().Add(-time.Second)
+		am.processNotificationRetries(context.Background())
+
+		require.Len(t, logStore.entries, 2)
+		entry := logStore.entries[1]
+		require.Equal(t, ngmodels.NotificationStatusSuccess, entry.Status)
+		require.Equal(t, 2, entry.Attempt)
+		require.Equal(t, "{}:{team=\"ops\"}", entry.GroupKey)
+		require.Contains(t, ns.Webhook.Body, "HighCPU")
+		require.Empty(t, logStore.retries)
+	})
+
+	t.Run("gives up after the maximum number of attempts", func(t *testing.T) {
+		ns.ShouldError = errors.New("connection refused")
+		logStore.retries = []*ngmodels.NotificationRetry{{
+			OrgID:          1,
+			Receiver:       "ops",
+			IntegrationUID: "webhook-uid",
+			GroupKey:       "{}:{team=\"ops\"}",
+			Alerts:         []*types.Alert{alert},
+			Attempts:       2,
+			NextAttemptAt:  time.Now().Add(-time.Second),
+		}}
+		am.processNotificationRetries(context.Background())
+
+		require.Len(t, logStore.entries, 3)
+		require.Equal(t, ngmodels.NotificationStatusFailed, logStore.entries[2].Status)
+		require.Equal(t, 3, logStore.entries[2].Attempt)
+		require.Empty(t, logStore.retries)
+	})
+
+	t.Run("drops the retries of deleted integrations", func(t *testing.T) {
+		logStore.retries = []*ngmodels.NotificationRetry{{
+			OrgID:          1,
+			Receiver:       "ops",
+			IntegrationUID: "deleted-uid",
+			NextAttemptAt:  time.Now().Add(-time.Second),
+		}}
+		am.processNotificationRetries(context.Background())
+
+		require.Len(t, logStore.entries, 3)
+		require.Empty(t, logStore.retries)
+	})
+}
+
+func TestSummarizeAlerts(t *testing.T) {
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "B"}, EndsAt: now.Add(time.Hour)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "A"}, EndsAt: now.Add(time.Hour)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "A"}, EndsAt: now.Add(-time.Hour)}},
+	}
+	summary := summarizeAlerts(nil, alerts)
+	require.Equal(t, ngmodels.NotificationPayloadSummary{
+		Firing:     2,
+		Resolved:   1,
+		AlertNames: []string{"A", "B"},
+	}, summary)
+}
+
+func setupNotificationLogTest(t *testing.T) (*Alertmanager, *fakeNotificationLogStore, *notifications.NotificationServiceMock) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	cfg := &setting.Cfg{
+		DataPath: dir,
+		UnifiedAlerting: setting.UnifiedAlertingSettings{
+			NotificationRetryMaxAttempts: 3,
+			NotificationRetryBackoff:     time.Minute,
+		},
+	}
+
+	logStore := &fakeNotificationLogStore{}
+	ns := notifications.MockNotificationService()
+	secretsService := secretsManager.SetupTestService(t, fakes.NewFakeSecretsStore())
+	m := metrics.NewAlertmanagerMetrics(prometheus.NewRegistry())
+	am, err := newAlertmanager(context.Background(), 1, cfg, &FakeConfigStore{}, logStore, NewFakeKVStore(t), &NilPeer{}, secretsService.GetDecryptedValue, ns, m)
+	require.NoError(t, err)
+
+	require.NoError(t, am.ApplyConfig(&ngmodels.AlertConfiguration{AlertmanagerConfiguration: notificationLogTestConfig}))
+	return am, logStore, ns
+}
+
+type fakeNotificationLogStore struct {
+	mtx     sync.Mutex
+	entries []*ngmodels.NotificationLogEntry
+	retries []*ngmodels.NotificationRetry
+}
+
+func (f *fakeNotificationLogStore) SaveNotificationLog(_ context.Context, entry *ngmodels.NotificationLogEntry) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func (f *fakeNotificationLogStore) GetNotificationLog(_ context.Context, query *ngmodels.GetNotificationLogQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	query.Result = f.entries
+	return nil
+}
+
+func (f *fakeNotificationLogStore) DeleteNotificationLogBefore(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeNotificationLogStore) SaveNotificationRetry(ctx context.Context, retry *ngmodels.NotificationRetry) error {
+	_ = f.DeleteNotificationRetry(ctx, retry)
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.retries = append(f.retries, retry)
+	return nil
+}
+
+func (f *fakeNotificationLogStore) GetNotificationRetries(_ context.Context, query *ngmodels.GetNotificationRetriesQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	query.Result = nil
+	for _, retry := range f.retries {
+		if query.DueBefore.IsZero() || !retry.NextAttemptAt.After(query.DueBefore) {
+			copied := *retry
+			query.Result = append(query.Result, &copied)
+		}
+	}
+	return nil
+}
+
+func (f *fakeNotificationLogStore) ClaimNotificationRetry(_ context.Context, retry *ngmodels.NotificationRetry, until time.Time) (bool, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, r := range f.retries {
+		if r.IntegrationUID == retry.IntegrationUID && r.GroupKey == retry.GroupKey && r.NextAttemptAt.Equal(retry.NextAttemptAt) {
+			r.NextAttemptAt = until
+			retry.NextAttemptAt = until
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeNotificationLogStore) DeleteNotificationRetry(_ context.Context, retry *ngmodels.NotificationRetry) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	retries := f.retries[:0]
+	for _, r := range f.retries {
+		if r.Receiver != retry.Receiver || r.IntegrationUID != retry.IntegrationUID || r.GroupKey != retry.GroupKey {
+			retries = append(retries, r)
+		}
+	}
+	f.retries = retries
+	return nil
+}
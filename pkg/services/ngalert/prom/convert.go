@@ -0,0 +1,249 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// DatasourceType is the type of the data sources the converted rules query.
+	DatasourceType = "prometheus"
+
+	queryRefID     = "A"
+	conditionRefID = "B"
+
+	// queryTimeRange is the relative time range of the queries of the imported rules. Instant queries only
+	// use its end, the start is the lookback of the range selectors of the expressions.
+	queryTimeRange = 10 * time.Minute
+)
+
+// thresholdCondition matches the math expressions that compare a query to a number, e.g. $A > 80.
+var thresholdCondition = regexp.MustCompile(`^\s*\$\{?([A-Za-z0-9_]+)\}?\s*(==|!=|>=|<=|>|<)\s*(-?[0-9.]+(?:[eE][-+]?[0-9]+)?)\s*$`)
+
+// ErrNotConvertible is returned when a rule cannot be converted between the Grafana and the Prometheus formats.
+var ErrNotConvertible = errors.New("rule cannot be converted")
+
+// ToGrafanaRule converts a Prometheus alerting or recording rule to a Grafana managed rule that runs its
+// expression as an instant query of the data source.
+func ToGrafanaRule(orgID int64, namespaceUID, group string, interval time.Duration, datasourceUID string, rule apimodels.ApiRuleNode) (*ngmodels.AlertRule, error) {
+	if (rule.Alert == "") == (rule.Record == "") {
+		return nil, fmt.Errorf("%w: rule must have either an alert or a record name", ErrNotConvertible)
+	}
+	if _, err := parser.ParseExpr(rule.Expr); err != nil {
+		return nil, fmt.Errorf("%w: invalid expression: %s", ErrNotConvertible, err)
+	}
+	for name := range rule.Labels {
+		if !model.LabelName(name).IsValid() {
+			return nil, fmt.Errorf("%w: invalid label name %q", ErrNotConvertible, name)
+		}
+	}
+
+	query, err := newQuery(datasourceUID, rule.Expr)
+	if err != nil {
+		return nil, err
+	}
+	r := &ngmodels.AlertRule{
+		OrgID:           orgID,
+		NamespaceUID:    namespaceUID,
+		RuleGroup:       group,
+		IntervalSeconds: int64(interval.Seconds()),
+		Labels:          rule.Labels,
+		NoDataState:     ngmodels.OK,
+		ExecErrState:    ngmodels.ErrorErrState,
+	}
+
+	if rule.Record != "" {
+		if !model.IsValidMetricName(model.LabelValue(rule.Record)) {
+			return nil, fmt.Errorf("%w: invalid metric name %q", ErrNotConvertible, rule.Record)
+		}
+		r.Title = rule.Record
+		r.RecordMetric = rule.Record
+		r.Condition = queryRefID
+		r.Data = []ngmodels.AlertQuery{query}
+		return r, nil
+	}
+
+	condition, err := newMathExpression(conditionRefID, seriesCondition(queryRefID))
+	if err != nil {
+		return nil, err
+	}
+	r.Title = rule.Alert
+	r.Condition = conditionRefID
+	r.Data = []ngmodels.AlertQuery{query, condition}
+	r.For = time.Duration(rule.For)
+	r.Annotations = rule.Annotations
+	return r, nil
+}
+
+func newQuery(datasourceUID, expression string) (ngmodels.AlertQuery, error) {
+	model, err := json.Marshal(map[string]interface{}{
+		"refId":         queryRefID,
+		"expr":          expression,
+		"instant":       true,
+		"range":         false,
+		"intervalMs":    1000,
+		"maxDataPoints": 43200,
+		"datasource": map[string]string{
+			"type": DatasourceType,
+			"uid":  datasourceUID,
+		},
+	})
+	if err != nil {
+		return ngmodels.AlertQuery{}, err
+	}
+	return ngmodels.AlertQuery{
+		RefID:         queryRefID,
+		DatasourceUID: datasourceUID,
+		RelativeTimeRange: ngmodels.RelativeTimeRange{
+			From: ngmodels.Duration(queryTimeRange),
+			To:   0,
+		},
+		Model: model,
+	}, nil
+}
+
+func newMathExpression(refID, expression string) (ngmodels.AlertQuery, error) {
+	model, err := json.Marshal(map[string]interface{}{
+		"refId":      refID,
+		"type":       "math",
+		"expression": expression,
+		"datasource": map[string]string{
+			"type": expr.DatasourceType,
+			"uid":  expr.DatasourceUID,
+		},
+	})
+	if err != nil {
+		return ngmodels.AlertQuery{}, err
+	}
+	return ngmodels.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         model,
+	}, nil
+}
+
+// FromGrafanaRule converts a Grafana managed rule to a Prometheus rule. Only the rules that run a single
+// instant query of a Prometheus data source can be converted, with a condition that is either the query,
+// a comparison of the query with a number, or the condition of the imported rules.
+func FromGrafanaRule(rule *ngmodels.AlertRule, isPrometheus func(datasourceUID string) bool) (apimodels.ApiRuleNode, error) {
+	var query *ngmodels.AlertQuery
+	var condition *ngmodels.AlertQuery
+	for i := range rule.Data {
+		q := &rule.Data[i]
+		if expr.IsDataSource(q.DatasourceUID) {
+			if q.RefID != rule.Condition {
+				return apimodels.ApiRuleNode{}, fmt.Errorf("%w: only the condition can be an expression", ErrNotConvertible)
+			}
+			condition = q
+			continue
+		}
+		if query != nil {
+			return apimodels.ApiRuleNode{}, fmt.Errorf("%w: rule has more than one query", ErrNotConvertible)
+		}
+		query = q
+	}
+	if query == nil {
+		return apimodels.ApiRuleNode{}, fmt.Errorf("%w: rule has no query", ErrNotConvertible)
+	}
+	if !isPrometheus(query.DatasourceUID) {
+		return apimodels.ApiRuleNode{}, fmt.Errorf("%w: query %s is not a query of a Prometheus data source", ErrNotConvertible, query.RefID)
+	}
+
+	var props struct {
+		Expr    string `json:"expr"`
+		Instant bool   `json:"instant"`
+		Range   bool   `json:"range"`
+	}
+	if err := json.Unmarshal(query.Model, &props); err != nil {
+		return apimodels.ApiRuleNode{}, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	if props.Expr == "" {
+		return apimodels.ApiRuleNode{}, fmt.Errorf("%w: query %s has no expression", ErrNotConvertible, query.RefID)
+	}
+	if props.Range && !props.Instant {
+		return apimodels.ApiRuleNode{}, fmt.Errorf("%w: query %s is a range query", ErrNotConvertible, query.RefID)
+	}
+
+	if rule.IsRecordingRule() {
+		if condition != nil {
+			return apimodels.ApiRuleNode{}, fmt.Errorf("%w: recording rule has an expression", ErrNotConvertible)
+		}
+		return apimodels.ApiRuleNode{
+			Record: rule.RecordMetric,
+			Expr:   props.Expr,
+			Labels: rule.Labels,
+		}, nil
+	}
+
+	expression := fmt.Sprintf("(%s) != 0", props.Expr)
+	if condition != nil {
+		var err error
+		if expression, err = conditionToPromQL(condition, query.RefID, props.Expr); err != nil {
+			return apimodels.ApiRuleNode{}, err
+		}
+	}
+
+	return apimodels.ApiRuleNode{
+		Alert:       rule.Title,
+		Expr:        expression,
+		For:         model.Duration(rule.For),
+		Labels:      rule.Labels,
+		Annotations: publicAnnotations(rule.Annotations),
+	}, nil
+}
+
+// conditionToPromQL converts the math expression of a condition to a PromQL expression.
+func conditionToPromQL(condition *ngmodels.AlertQuery, refID, query string) (string, error) {
+	var props struct {
+		Type       string `json:"type"`
+		Expression string `json:"expression"`
+	}
+	if err := json.Unmarshal(condition.Model, &props); err != nil {
+		return "", fmt.Errorf("failed to unmarshal expression model: %w", err)
+	}
+	if props.Type != "math" {
+		return "", fmt.Errorf("%w: %s expressions are not supported", ErrNotConvertible, props.Type)
+	}
+
+	if props.Expression == seriesCondition(refID) {
+		return query, nil
+	}
+	m := thresholdCondition.FindStringSubmatch(props.Expression)
+	if m == nil || m[1] != refID {
+		return "", fmt.Errorf("%w: math expression %q is not supported", ErrNotConvertible, props.Expression)
+	}
+	return fmt.Sprintf("(%s) %s %s", query, m[2], m[3]), nil
+}
+
+// seriesCondition returns the math expression of the condition of the imported alert rules. Like Prometheus,
+// it alerts for every series returned by the query, whatever its value.
+func seriesCondition(refID string) string {
+	return fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", refID)
+}
+
+// publicAnnotations returns the annotations without the ones Grafana uses internally, like the dashboard and
+// the panel of the rule.
+func publicAnnotations(annotations map[string]string) map[string]string {
+	var result map[string]string
+	for k, v := range annotations {
+		if strings.HasPrefix(k, "__") && strings.HasSuffix(k, "__") {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string, len(annotations))
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func isPrometheus(uid string) bool {
+	return uid == "prom"
+}
+
+func TestToGrafanaRule(t *testing.T) {
+	t.Run("converts an alerting rule", func(t *testing.T) {
+		rule, err := ToGrafanaRule(1, "folder", "group", time.Minute, "prom", apimodels.ApiRuleNode{
+			Alert:       "HighLatency",
+			Expr:        `histogram_quantile(0.99, rate(http_request_duration_seconds_bucket[5m])) > 1`,
+			For:         model.Duration(5 * time.Minute),
+			Labels:      map[string]string{"severity": "critical"},
+			Annotations: map[string]string{"summary": "latency is high"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "HighLatency", rule.Title)
+		require.Equal(t, "group", rule.RuleGroup)
+		require.Equal(t, int64(60), rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, map[string]string{"severity": "critical"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "latency is high"}, rule.Annotations)
+		require.Equal(t, ngmodels.OK, rule.NoDataState)
+		require.Equal(t, ngmodels.ErrorErrState, rule.ExecErrState)
+		require.Equal(t, conditionRefID, rule.Condition)
+		require.Len(t, rule.Data, 2)
+		require.Equal(t, "prom", rule.Data[0].DatasourceUID)
+		require.True(t, expr.IsDataSource(rule.Data[1].DatasourceUID))
+		require.False(t, rule.IsRecordingRule())
+	})
+
+	t.Run("converts a recording rule", func(t *testing.T) {
+		rule, err := ToGrafanaRule(1, "folder", "group", time.Minute, "prom", apimodels.ApiRuleNode{
+			Record: "job:http_requests:rate5m",
+			Expr:   `sum by (job) (rate(http_requests_total[5m]))`,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "job:http_requests:rate5m", rule.Title)
+		require.Equal(t, "job:http_requests:rate5m", rule.RecordMetric)
+		require.Equal(t, queryRefID, rule.Condition)
+		require.Len(t, rule.Data, 1)
+	})
+
+	testCases := []struct {
+		desc string
+		rule apimodels.ApiRuleNode
+	}{
+		{desc: "no name", rule: apimodels.ApiRuleNode{Expr: "up"}},
+		{desc: "both alert and record", rule: apimodels.ApiRuleNode{Alert: "a", Record: "b", Expr: "up"}},
+		{desc: "invalid expression", rule: apimodels.ApiRuleNode{Alert: "a", Expr: "sum(up"}},
+		{desc: "invalid label name", rule: apimodels.ApiRuleNode{Alert: "a", Expr: "up", Labels: map[string]string{"1abc": "x"}}},
+		{desc: "invalid metric name", rule: apimodels.ApiRuleNode{Record: "job-rate", Expr: "up"}},
+	}
+	for _, tc := range testCases {
+		t.Run("fails with "+tc.desc, func(t *testing.T) {
+			_, err := ToGrafanaRule(1, "folder", "group", time.Minute, "prom", tc.rule)
+			require.ErrorIs(t, err, ErrNotConvertible)
+		})
+	}
+}
+
+func TestFromGrafanaRule(t *testing.T) {
+	t.Run("round trips the converted rules", func(t *testing.T) {
+		for _, r := range []apimodels.ApiRuleNode{
+			{
+				Alert:       "HighLatency",
+				Expr:        `histogram_quantile(0.99, rate(http_request_duration_seconds_bucket[5m])) > 1`,
+				For:         model.Duration(5 * time.Minute),
+				Labels:      map[string]string{"severity": "critical"},
+				Annotations: map[string]string{"summary": "latency is high"},
+			},
+			{
+				Record: "job:http_requests:rate5m",
+				Expr:   `sum by (job) (rate(http_requests_total[5m]))`,
+			},
+		} {
+			rule, err := ToGrafanaRule(1, "folder", "group", time.Minute, "prom", r)
+			require.NoError(t, err)
+			converted, err := FromGrafanaRule(rule, isPrometheus)
+			require.NoError(t, err)
+			require.Equal(t, r, converted)
+		}
+	})
+
+	t.Run("converts threshold conditions", func(t *testing.T) {
+		query, err := newQuery("prom", "rate(errors_total[5m])")
+		require.NoError(t, err)
+		condition, err := newMathExpression(conditionRefID, "$A >= 0.5")
+		require.NoError(t, err)
+		rule := &ngmodels.AlertRule{
+			Title:       "Errors",
+			Condition:   conditionRefID,
+			Data:        []ngmodels.AlertQuery{query, condition},
+			Annotations: map[string]string{ngmodels.DashboardUIDAnnotation: "dashboard", "summary": "errors"},
+		}
+
+		converted, err := FromGrafanaRule(rule, isPrometheus)
+		require.NoError(t, err)
+		require.Equal(t, "(rate(errors_total[5m])) >= 0.5", converted.Expr)
+		require.Equal(t, map[string]string{"summary": "errors"}, converted.Annotations)
+	})
+
+	t.Run("uses the query as condition", func(t *testing.T) {
+		query, err := newQuery("prom", "up")
+		require.NoError(t, err)
+		rule := &ngmodels.AlertRule{Title: "Up", Condition: queryRefID, Data: []ngmodels.AlertQuery{query}}
+
+		converted, err := FromGrafanaRule(rule, isPrometheus)
+		require.NoError(t, err)
+		require.Equal(t, "(up) != 0", converted.Expr)
+	})
+
+	t.Run("fails if the rule cannot be converted", func(t *testing.T) {
+		query, err := newQuery("prom", "up")
+		require.NoError(t, err)
+		other, err := newQuery("loki", "up")
+		require.NoError(t, err)
+		other.RefID = "C"
+		reduce := ngmodels.AlertQuery{
+			RefID:         conditionRefID,
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(`{"type": "reduce", "expression": "A", "reducer": "last"}`),
+		}
+		unsupported, err := newMathExpression(conditionRefID, "abs($A) > 1")
+		require.NoError(t, err)
+
+		testCases := []struct {
+			desc string
+			rule *ngmodels.AlertRule
+		}{
+			{desc: "not a Prometheus data source", rule: &ngmodels.AlertRule{Condition: "C", Data: []ngmodels.AlertQuery{other}}},
+			{desc: "more than one query", rule: &ngmodels.AlertRule{Condition: queryRefID, Data: []ngmodels.AlertQuery{query, other}}},
+			{desc: "reduce expression", rule: &ngmodels.AlertRule{Condition: conditionRefID, Data: []ngmodels.AlertQuery{query, reduce}}},
+			{desc: "unsupported math expression", rule: &ngmodels.AlertRule{Condition: conditionRefID, Data: []ngmodels.AlertQuery{query, unsupported}}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.desc, func(t *testing.T) {
+				_, err := FromGrafanaRule(tc.rule, isPrometheus)
+				require.ErrorIs(t, err, ErrNotConvertible)
+			})
+		}
+	})
+}
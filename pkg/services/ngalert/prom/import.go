@@ -0,0 +1,124 @@
+package prom
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// RuleStore is the subset of the alert rule store the rules are imported into.
+type RuleStore interface {
+	GetNamespaceAlertRules(ctx context.Context, query *ngmodels.ListNamespaceAlertRulesQuery) error
+	UpsertAlertRules(ctx context.Context, rules []store.UpsertRule) error
+}
+
+// ImportCommand imports the rule groups of a Prometheus rule file into a folder.
+type ImportCommand struct {
+	OrgID         int64
+	NamespaceUID  string
+	DatasourceUID string
+	Groups        []apimodels.PrometheusRuleGroup
+	// BaseInterval is the base interval of the scheduler, the interval of the groups must be a multiple of it.
+	BaseInterval time.Duration
+	// DefaultInterval is the interval of the groups that do not have one.
+	DefaultInterval time.Duration
+	// DryRun only converts the rules and reports the result.
+	DryRun bool
+}
+
+// Import converts the rule groups to Grafana managed rules and saves them. The groups that already exist in
+// the folder are not imported, and neither are the rules whose title is already used in the folder, because
+// the title of a Grafana managed rule is the alertname label of its alerts. The rules that cannot be
+// converted are reported, and the other rules of their group are imported.
+func Import(ctx context.Context, st RuleStore, cmd ImportCommand) (apimodels.PrometheusImportReport, error) {
+	report := apimodels.PrometheusImportReport{
+		Imported: []apimodels.PrometheusRuleStatus{},
+		Failed:   []apimodels.PrometheusRuleStatus{},
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        cmd.OrgID,
+		NamespaceUID: cmd.NamespaceUID,
+	}
+	if err := st.GetNamespaceAlertRules(ctx, &q); err != nil {
+		return report, fmt.Errorf("failed to get the alert rules of the folder: %w", err)
+	}
+	groups := make(map[string]struct{})
+	titles := make(map[string]struct{}, len(q.Result))
+	for _, r := range q.Result {
+		groups[r.RuleGroup] = struct{}{}
+		titles[r.Title] = struct{}{}
+	}
+
+	var rules []store.UpsertRule
+	for _, g := range cmd.Groups {
+		if err := validateGroup(g, groups, cmd.BaseInterval); err != nil {
+			report.Failed = append(report.Failed, apimodels.PrometheusRuleStatus{Group: g.Name, Error: err.Error()})
+			continue
+		}
+		groups[g.Name] = struct{}{}
+
+		interval := time.Duration(g.Interval)
+		if interval == 0 {
+			interval = cmd.DefaultInterval
+		}
+		for _, r := range g.Rules {
+			status := apimodels.PrometheusRuleStatus{Group: g.Name, Rule: r.Alert}
+			if r.Record != "" {
+				status.Rule = r.Record
+			}
+
+			rule, err := ToGrafanaRule(cmd.OrgID, cmd.NamespaceUID, g.Name, interval, cmd.DatasourceUID, r)
+			if err == nil {
+				err = validateTitle(rule.Title, titles)
+			}
+			if err != nil {
+				status.Error = err.Error()
+				report.Failed = append(report.Failed, status)
+				continue
+			}
+
+			titles[rule.Title] = struct{}{}
+			rules = append(rules, store.UpsertRule{New: *rule})
+			report.Imported = append(report.Imported, status)
+		}
+	}
+
+	if cmd.DryRun || len(rules) == 0 {
+		return report, nil
+	}
+	if err := st.UpsertAlertRules(ctx, rules); err != nil {
+		return report, fmt.Errorf("failed to save the alert rules: %w", err)
+	}
+	return report, nil
+}
+
+func validateTitle(title string, titles map[string]struct{}) error {
+	if len(title) > store.AlertRuleMaxTitleLength {
+		return fmt.Errorf("%w: name length should not be greater than %d", ErrNotConvertible, store.AlertRuleMaxTitleLength)
+	}
+	if _, ok := titles[title]; ok {
+		return fmt.Errorf("%w: an alert rule with the title %q already exists in the folder", ErrNotConvertible, title)
+	}
+	return nil
+}
+
+func validateGroup(g apimodels.PrometheusRuleGroup, groups map[string]struct{}, baseInterval time.Duration) error {
+	if g.Name == "" {
+		return fmt.Errorf("rule group name cannot be empty")
+	}
+	if len(g.Name) > store.AlertRuleMaxRuleGroupNameLength {
+		return fmt.Errorf("rule group name is too long. Max length is %d", store.AlertRuleMaxRuleGroupNameLength)
+	}
+	if _, ok := groups[g.Name]; ok {
+		return fmt.Errorf("rule group already exists in the folder")
+	}
+	if interval := time.Duration(g.Interval); interval < 0 || baseInterval > 0 && interval%baseInterval != 0 {
+		return fmt.Errorf("rule evaluation interval %s should be a multiple of the base interval of %s", interval, baseInterval)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package prom
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type fakeRuleStore struct {
+	rules    []*ngmodels.AlertRule
+	upserted []store.UpsertRule
+}
+
+func (f *fakeRuleStore) GetNamespaceAlertRules(_ context.Context, q *ngmodels.ListNamespaceAlertRulesQuery) error {
+	q.Result = f.rules
+	return nil
+}
+
+func (f *fakeRuleStore) UpsertAlertRules(_ context.Context, rules []store.UpsertRule) error {
+	f.upserted = append(f.upserted, rules...)
+	return nil
+}
+
+func TestImport(t *testing.T) {
+	groups := []apimodels.PrometheusRuleGroup{
+		{
+			Name:     "api",
+			Interval: model.Duration(30 * time.Second),
+			Rules: []apimodels.ApiRuleNode{
+				{Alert: "HighErrorRate", Expr: `rate(errors_total[5m]) > 1`},
+				{Alert: "Existing", Expr: `up == 0`},
+				{Alert: "Invalid", Expr: `sum(up`},
+				{Record: "job:errors:rate5m", Expr: `sum by (job) (rate(errors_total[5m]))`},
+			},
+		},
+		{
+			Name:  "existing",
+			Rules: []apimodels.ApiRuleNode{{Alert: "Other", Expr: `up == 0`}},
+		},
+		{
+			Name:  "default-interval",
+			Rules: []apimodels.ApiRuleNode{{Alert: "Down", Expr: `up == 0`}},
+		},
+		{
+			Name:     "invalid-interval",
+			Interval: model.Duration(15 * time.Second),
+			Rules:    []apimodels.ApiRuleNode{{Alert: "Late", Expr: `up == 0`}},
+		},
+	}
+	cmd := ImportCommand{
+		OrgID:           1,
+		NamespaceUID:    "folder",
+		DatasourceUID:   "prom",
+		Groups:          groups,
+		BaseInterval:    10 * time.Second,
+		DefaultInterval: time.Minute,
+	}
+	existing := []*ngmodels.AlertRule{{OrgID: 1, NamespaceUID: "folder", RuleGroup: "existing", Title: "Existing"}}
+
+	t.Run("imports the rules that can be converted", func(t *testing.T) {
+		st := &fakeRuleStore{rules: existing}
+
+		report, err := Import(context.Background(), st, cmd)
+		require.NoError(t, err)
+		require.Equal(t, []apimodels.PrometheusRuleStatus{
+			{Group: "api", Rule: "HighErrorRate"},
+			{Group: "api", Rule: "job:errors:rate5m"},
+			{Group: "default-interval", Rule: "Down"},
+		}, report.Imported)
+
+		require.Len(t, report.Failed, 4)
+		require.Equal(t, "Existing", report.Failed[0].Rule)
+		require.Equal(t, "Invalid", report.Failed[1].Rule)
+		require.Equal(t, apimodels.PrometheusRuleStatus{Group: "existing", Error: "rule group already exists in the folder"}, report.Failed[2])
+		require.Equal(t, "invalid-interval", report.Failed[3].Group)
+		require.Empty(t, report.Failed[3].Rule)
+
+		require.Len(t, st.upserted, 3)
+		require.Equal(t, int64(30), st.upserted[0].New.IntervalSeconds)
+		require.Equal(t, "folder", st.upserted[0].New.NamespaceUID)
+		require.Equal(t, "job:errors:rate5m", st.upserted[1].New.RecordMetric)
+		require.Equal(t, int64(60), st.upserted[2].New.IntervalSeconds)
+	})
+
+	t.Run("does not save the rules in a dry run", func(t *testing.T) {
+		st := &fakeRuleStore{rules: existing}
+		dryRun := cmd
+		dryRun.DryRun = true
+
+		report, err := Import(context.Background(), st, dryRun)
+		require.NoError(t, err)
+		require.Len(t, report.Imported, 3)
+		require.Empty(t, st.upserted)
+	})
+}
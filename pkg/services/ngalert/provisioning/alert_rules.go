@@ -0,0 +1,128 @@
+package provisioning
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// AlertRuleService creates, updates and deletes alert rules and records their provenance.
+type AlertRuleService struct {
+	defaultInterval time.Duration
+	ruleStore       RuleStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	log             log.Logger
+}
+
+func NewAlertRuleService(ruleStore RuleStore, provenanceStore ProvisioningStore, xact TransactionManager,
+	defaultInterval time.Duration, log log.Logger) *AlertRuleService {
+	return &AlertRuleService{
+		defaultInterval: defaultInterval,
+		ruleStore:       ruleStore,
+		provenanceStore: provenanceStore,
+		xact:            xact,
+		log:             log,
+	}
+}
+
+// GetAlertRule returns the alert rule with the given uid and its provenance.
+func (service *AlertRuleService) GetAlertRule(ctx context.Context, orgID int64, ruleUID string) (models.AlertRule, models.Provenance, error) {
+	query := &models.GetAlertRuleByUIDQuery{OrgID: orgID, UID: ruleUID}
+	if err := service.ruleStore.GetAlertRuleByUID(ctx, query); err != nil {
+		return models.AlertRule{}, models.ProvenanceNone, err
+	}
+	provenance, err := service.provenanceStore.GetProvenance(ctx, query.Result)
+	if err != nil {
+		return models.AlertRule{}, models.ProvenanceNone, err
+	}
+	return *query.Result, provenance, nil
+}
+
+// CreateAlertRule creates a new alert rule. The rule keeps its uid when it is set. When it has no
+// interval, it is evaluated at the interval of its rule group, or at the default interval when the
+// group is new.
+func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule models.AlertRule, provenance models.Provenance) (models.AlertRule, error) {
+	if rule.UID == "" {
+		rule.UID = util.GenerateShortUID()
+	}
+
+	err := service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if rule.IntervalSeconds == 0 {
+			interval, err := service.groupInterval(ctx, rule.OrgID, rule.NamespaceUID, rule.RuleGroup)
+			if err != nil {
+				return err
+			}
+			rule.IntervalSeconds = interval
+		}
+		if err := service.ruleStore.UpsertAlertRules(ctx, []store.UpsertRule{{New: rule}}); err != nil {
+			return err
+		}
+		return service.provenanceStore.SetProvenance(ctx, &rule, provenance)
+	})
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	created, _, err := service.GetAlertRule(ctx, rule.OrgID, rule.UID)
+	return created, err
+}
+
+// groupInterval returns the interval in seconds of the rules of a rule group.
+func (service *AlertRuleService) groupInterval(ctx context.Context, orgID int64, namespaceUID, ruleGroup string) (int64, error) {
+	query := &models.ListRuleGroupAlertRulesQuery{OrgID: orgID, NamespaceUID: namespaceUID, RuleGroup: ruleGroup}
+	if err := service.ruleStore.GetRuleGroupAlertRules(ctx, query); err != nil {
+		return 0, err
+	}
+	if len(query.Result) > 0 {
+		return query.Result[0].IntervalSeconds, nil
+	}
+	return int64(service.defaultInterval.Seconds()), nil
+}
+
+// UpdateAlertRule replaces an existing alert rule, when its provenance allows it.
+func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule models.AlertRule, provenance models.Provenance) (models.AlertRule, error) {
+	err := service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		existing, stored, err := service.GetAlertRule(ctx, rule.OrgID, rule.UID)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+		if rule.IntervalSeconds == 0 {
+			rule.IntervalSeconds = existing.IntervalSeconds
+		}
+
+		if err := service.ruleStore.UpsertAlertRules(ctx, []store.UpsertRule{{Existing: &existing, New: rule}}); err != nil {
+			return err
+		}
+		return service.provenanceStore.SetProvenance(ctx, &rule, provenance)
+	})
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	updated, _, err := service.GetAlertRule(ctx, rule.OrgID, rule.UID)
+	return updated, err
+}
+
+// DeleteAlertRule deletes an existing alert rule, when its provenance allows it.
+func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, orgID int64, ruleUID string, provenance models.Provenance) error {
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		rule, stored, err := service.GetAlertRule(ctx, orgID, ruleUID)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+
+		if err := service.ruleStore.DeleteAlertRuleByUID(ctx, orgID, ruleUID); err != nil {
+			return err
+		}
+		return service.provenanceStore.DeleteProvenance(ctx, &rule)
+	})
+}
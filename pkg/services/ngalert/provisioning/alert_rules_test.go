@@ -0,0 +1,107 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func setupTestStore(t *testing.T) *store.DBstore {
+	t.Helper()
+	return &store.DBstore{
+		SQLStore:        sqlstore.InitTestDB(t),
+		BaseInterval:    10 * time.Second,
+		DefaultInterval: time.Minute,
+		Logger:          log.New("ngalert.provisioning.test"),
+	}
+}
+
+func TestAlertRuleService(t *testing.T) {
+	dbstore := setupTestStore(t)
+	service := NewAlertRuleService(dbstore, dbstore, dbstore.SQLStore, time.Minute, log.New("test"))
+	ctx := context.Background()
+
+	t.Run("created rules keep their uid and get the default interval", func(t *testing.T) {
+		rule, err := service.CreateAlertRule(ctx, testRule("provisioned-rule"), models.ProvenanceFile)
+		require.NoError(t, err)
+		require.Equal(t, "provisioned-rule", rule.UID)
+		require.Equal(t, int64(60), rule.IntervalSeconds)
+
+		_, provenance, err := service.GetAlertRule(ctx, 1, "provisioned-rule")
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceFile, provenance)
+	})
+
+	t.Run("provisioned rules cannot be changed without provenance", func(t *testing.T) {
+		rule := testRule("provisioned-rule")
+		rule.Title = "changed"
+
+		_, err := service.UpdateAlertRule(ctx, rule, models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+		err = service.DeleteAlertRule(ctx, 1, "provisioned-rule", models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+
+		updated, err := service.UpdateAlertRule(ctx, rule, models.ProvenanceFile)
+		require.NoError(t, err)
+		require.Equal(t, "changed", updated.Title)
+		require.Equal(t, int64(2), updated.Version)
+	})
+
+	t.Run("deleted rules are no longer provisioned", func(t *testing.T) {
+		err := service.DeleteAlertRule(ctx, 1, "provisioned-rule", models.ProvenanceFile)
+		require.NoError(t, err)
+
+		_, _, err = service.GetAlertRule(ctx, 1, "provisioned-rule")
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+		err = service.DeleteAlertRule(ctx, 1, "provisioned-rule", models.ProvenanceFile)
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+		provenance, err := dbstore.GetProvenance(ctx, &models.AlertRule{OrgID: 1, UID: "provisioned-rule"})
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, provenance)
+	})
+
+	t.Run("rules without provenance can be changed by anyone", func(t *testing.T) {
+		rule := testRule("")
+		created, err := service.CreateAlertRule(ctx, rule, models.ProvenanceNone)
+		require.NoError(t, err)
+		require.NotEmpty(t, created.UID)
+
+		created.Title = "changed"
+		_, err = service.UpdateAlertRule(ctx, created, models.ProvenanceApi)
+		require.NoError(t, err)
+		err = service.DeleteAlertRule(ctx, 1, created.UID, models.ProvenanceFile)
+		require.NoError(t, err)
+	})
+
+	t.Run("created rules get the interval of their rule group", func(t *testing.T) {
+		rule := testRule("group-rule-1")
+		rule.IntervalSeconds = 30
+		_, err := service.CreateAlertRule(ctx, rule, models.ProvenanceApi)
+		require.NoError(t, err)
+
+		created, err := service.CreateAlertRule(ctx, testRule("group-rule-2"), models.ProvenanceApi)
+		require.NoError(t, err)
+		require.Equal(t, int64(30), created.IntervalSeconds)
+	})
+}
+
+func testRule(uid string) models.AlertRule {
+	return models.AlertRule{
+		OrgID:        1,
+		UID:          uid,
+		Title:        "rule " + uid,
+		Condition:    "A",
+		Data:         []models.AlertQuery{models.GenerateAlertQuery()},
+		NamespaceUID: "folder",
+		RuleGroup:    "group",
+		NoDataState:  models.NoData,
+		ExecErrState: models.AlertingErrState,
+	}
+}
@@ -0,0 +1,75 @@
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ErrValidation is returned when a resource or the configuration that contains it is invalid.
+var ErrValidation = errors.New("invalid resource")
+
+// alertmanagerConfigStore reads and writes the latest Alertmanager configuration of organizations.
+type alertmanagerConfigStore struct {
+	store AMConfigStore
+	// defaultConfig is used for the organizations that have no configuration yet
+	defaultConfig string
+}
+
+func (s alertmanagerConfigStore) load(ctx context.Context, orgID int64) (*definitions.PostableUserConfig, error) {
+	raw := s.defaultConfig
+	query := models.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+	err := s.store.GetLatestAlertmanagerConfiguration(ctx, &query)
+	switch {
+	case err == nil:
+		raw = query.Result.AlertmanagerConfiguration
+	case !errors.Is(err, store.ErrNoAlertmanagerConfiguration):
+		return nil, err
+	}
+
+	cfg := &definitions.PostableUserConfig{}
+	if err := json.Unmarshal([]byte(raw), cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse the Alertmanager configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// update changes the latest configuration of the organization with fn. A new version of the
+// configuration is only saved when it changed, and when it is still valid.
+func (s alertmanagerConfigStore) update(ctx context.Context, orgID int64, fn func(cfg *definitions.PostableUserConfig) error) error {
+	cfg, err := s.load(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	before, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(cfg); err != nil {
+		return err
+	}
+	after, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(before, after) {
+		return nil
+	}
+
+	// the configuration is validated when it is parsed, like the configurations of the API
+	if err := json.Unmarshal(after, &definitions.PostableUserConfig{}); err != nil {
+		return fmt.Errorf("%w: %s", ErrValidation, err)
+	}
+	return s.store.SaveAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+		AlertmanagerConfiguration: string(after),
+		ConfigurationVersion:      fmt.Sprintf("v%d", models.AlertConfigurationVersion),
+		OrgID:                     orgID,
+	})
+}
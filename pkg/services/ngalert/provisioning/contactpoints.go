@@ -0,0 +1,235 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ContactPoint is a receiver of the Alertmanager configuration with its Grafana integrations.
+type ContactPoint struct {
+	Name string `json:"name"`
+	// Integrations have their secure settings in plain text when they are created or updated, and
+	// encrypted when they are read
+	Integrations []*definitions.PostableGrafanaReceiver `json:"integrations"`
+	Provenance   models.Provenance                      `json:"provenance,omitempty"`
+}
+
+// ContactPointService manages the contact points of the Alertmanager configurations and records
+// their provenance.
+type ContactPointService struct {
+	configStore     alertmanagerConfigStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	secrets         secrets.Service
+	log             log.Logger
+}
+
+func NewContactPointService(configStore AMConfigStore, defaultConfig string, provenanceStore ProvisioningStore,
+	xact TransactionManager, secretsService secrets.Service, log log.Logger) *ContactPointService {
+	return &ContactPointService{
+		configStore:     alertmanagerConfigStore{store: configStore, defaultConfig: defaultConfig},
+		provenanceStore: provenanceStore,
+		xact:            xact,
+		secrets:         secretsService,
+		log:             log,
+	}
+}
+
+// GetContactPoints returns the contact points of the organization with their provenance.
+func (service *ContactPointService) GetContactPoints(ctx context.Context, orgID int64) ([]ContactPoint, error) {
+	cfg, err := service.configStore.load(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := service.provenanceStore.GetProvenances(ctx, orgID, models.ResourceTypeContactPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	contactPoints := make([]ContactPoint, 0, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		contactPoints = append(contactPoints, ContactPoint{
+			Name:         r.Name,
+			Integrations: r.GrafanaManagedReceivers,
+			Provenance:   provenances[r.Name],
+		})
+	}
+	return contactPoints, nil
+}
+
+// UpsertContactPoint creates a contact point, or replaces the contact point with the same name.
+// Integrations without uid keep the uid of the existing integration at the same position, and
+// secure settings that did not change keep their encrypted value, so provisioning the same
+// contact point again does not change the configuration.
+func (service *ContactPointService) UpsertContactPoint(ctx context.Context, orgID int64, cp ContactPoint, provenance models.Provenance) error {
+	if err := validateContactPoint(cp); err != nil {
+		return err
+	}
+
+	resource := models.ContactPointResource(orgID, cp.Name)
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		stored, err := service.provenanceStore.GetProvenance(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+
+		err = service.configStore.update(ctx, orgID, func(cfg *definitions.PostableUserConfig) error {
+			var existing *definitions.PostableApiReceiver
+			uids := make(map[string]string) // integration uid -> contact point name
+			for _, r := range cfg.AlertmanagerConfig.Receivers {
+				if r.Name == cp.Name {
+					existing = r
+					continue
+				}
+				for _, integration := range r.GrafanaManagedReceivers {
+					uids[integration.UID] = r.Name
+				}
+			}
+
+			integrations, err := service.integrations(ctx, cp, existing)
+			if err != nil {
+				return err
+			}
+			for _, integration := range integrations {
+				if name, ok := uids[integration.UID]; ok {
+					return fmt.Errorf("%w: integration uid %q is already used by contact point %q", ErrValidation, integration.UID, name)
+				}
+			}
+
+			if existing != nil {
+				existing.GrafanaManagedReceivers = integrations
+				return nil
+			}
+			cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, &definitions.PostableApiReceiver{
+				Receiver:                 config.Receiver{Name: cp.Name},
+				PostableGrafanaReceivers: definitions.PostableGrafanaReceivers{GrafanaManagedReceivers: integrations},
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return service.provenanceStore.SetProvenance(ctx, resource, provenance)
+	})
+}
+
+// integrations returns the integrations of the contact point as they are stored in the configuration.
+func (service *ContactPointService) integrations(ctx context.Context, cp ContactPoint, existing *definitions.PostableApiReceiver) ([]*definitions.PostableGrafanaReceiver, error) {
+	var current []*definitions.PostableGrafanaReceiver
+	if existing != nil {
+		current = existing.GrafanaManagedReceivers
+	}
+
+	integrations := make([]*definitions.PostableGrafanaReceiver, 0, len(cp.Integrations))
+	for i, in := range cp.Integrations {
+		integration := &definitions.PostableGrafanaReceiver{
+			UID:                   in.UID,
+			Name:                  cp.Name,
+			Type:                  in.Type,
+			DisableResolveMessage: in.DisableResolveMessage,
+			Settings:              in.Settings,
+			SecureSettings:        make(map[string]string, len(in.SecureSettings)),
+		}
+		if integration.UID == "" {
+			if i < len(current) && current[i].Type == in.Type {
+				integration.UID = current[i].UID
+			} else {
+				integration.UID = util.GenerateShortUID()
+			}
+		}
+
+		var previous *definitions.PostableGrafanaReceiver
+		for _, c := range current {
+			if c.UID == integration.UID {
+				previous = c
+			}
+		}
+		for k, v := range in.SecureSettings {
+			if previous != nil && service.decrypt(ctx, previous.SecureSettings[k]) == v {
+				integration.SecureSettings[k] = previous.SecureSettings[k]
+				continue
+			}
+			encrypted, err := service.secrets.Encrypt(ctx, []byte(v), secrets.WithoutScope())
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt secure settings: %w", err)
+			}
+			integration.SecureSettings[k] = base64.StdEncoding.EncodeToString(encrypted)
+		}
+		integrations = append(integrations, integration)
+	}
+	return integrations, nil
+}
+
+// decrypt returns the plain text of an encrypted secure setting, or an empty string.
+func (service *ContactPointService) decrypt(ctx context.Context, value string) string {
+	if value == "" {
+		return ""
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return ""
+	}
+	decrypted, err := service.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return ""
+	}
+	return string(decrypted)
+}
+
+// DeleteContactPoint deletes a contact point, when its provenance allows it and when it is not used
+// by the notification policies.
+func (service *ContactPointService) DeleteContactPoint(ctx context.Context, orgID int64, name string, provenance models.Provenance) error {
+	resource := models.ContactPointResource(orgID, name)
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		stored, err := service.provenanceStore.GetProvenance(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+
+		err = service.configStore.update(ctx, orgID, func(cfg *definitions.PostableUserConfig) error {
+			receivers := cfg.AlertmanagerConfig.Receivers[:0]
+			for _, r := range cfg.AlertmanagerConfig.Receivers {
+				if r.Name != name {
+					receivers = append(receivers, r)
+				}
+			}
+			cfg.AlertmanagerConfig.Receivers = receivers
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return service.provenanceStore.DeleteProvenance(ctx, resource)
+	})
+}
+
+func validateContactPoint(cp ContactPoint) error {
+	if cp.Name == "" {
+		return fmt.Errorf("%w: contact point name is empty", ErrValidation)
+	}
+	if len(cp.Integrations) == 0 {
+		return fmt.Errorf("%w: contact point %q has no integrations", ErrValidation, cp.Name)
+	}
+	for _, integration := range cp.Integrations {
+		if _, ok := channels.Factory(integration.Type); !ok {
+			return fmt.Errorf("%w: contact point %q has an integration of unknown type %q", ErrValidation, cp.Name, integration.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestContactPointService(t *testing.T) {
+	dbstore := setupTestStore(t)
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(dbstore.SQLStore))
+	service := NewContactPointService(dbstore, setting.GetAlertmanagerDefaultConfiguration(), dbstore, dbstore.SQLStore, secretsService, log.New("test"))
+	ctx := context.Background()
+
+	slack := func(url string) ContactPoint {
+		return ContactPoint{
+			Name: "slack",
+			Integrations: []*definitions.PostableGrafanaReceiver{{
+				Type:           "slack",
+				Settings:       simplejson.NewFromAny(map[string]interface{}{"recipient": "#alerts"}),
+				SecureSettings: map[string]string{"url": url},
+			}},
+		}
+	}
+
+	t.Run("the default configuration is used when there is none", func(t *testing.T) {
+		contactPoints, err := service.GetContactPoints(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, contactPoints, 1)
+		require.Equal(t, "grafana-default-email", contactPoints[0].Name)
+		require.Equal(t, models.ProvenanceNone, contactPoints[0].Provenance)
+	})
+
+	t.Run("created contact points have encrypted secure settings", func(t *testing.T) {
+		err := service.UpsertContactPoint(ctx, 1, slack("https://hooks.slack.com/secret"), models.ProvenanceFile)
+		require.NoError(t, err)
+
+		cp := getContactPoint(t, service, "slack")
+		require.Equal(t, models.ProvenanceFile, cp.Provenance)
+		require.Len(t, cp.Integrations, 1)
+		require.NotEmpty(t, cp.Integrations[0].UID)
+		require.Equal(t, "slack", cp.Integrations[0].Name)
+
+		encrypted, err := base64.StdEncoding.DecodeString(cp.Integrations[0].SecureSettings["url"])
+		require.NoError(t, err)
+		decrypted, err := secretsService.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		require.Equal(t, "https://hooks.slack.com/secret", string(decrypted))
+	})
+
+	t.Run("provisioning the same contact point again does not change the configuration", func(t *testing.T) {
+		before := latestConfig(t, dbstore)
+		err := service.UpsertContactPoint(ctx, 1, slack("https://hooks.slack.com/secret"), models.ProvenanceFile)
+		require.NoError(t, err)
+		require.Equal(t, before, latestConfig(t, dbstore))
+	})
+
+	t.Run("provisioned contact points cannot be changed without provenance", func(t *testing.T) {
+		err := service.UpsertContactPoint(ctx, 1, slack("https://hooks.slack.com/other"), models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+		err = service.DeleteContactPoint(ctx, 1, "slack", models.ProvenanceApi)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+	})
+
+	t.Run("invalid contact points are refused", func(t *testing.T) {
+		cp := slack("https://hooks.slack.com/secret")
+		cp.Name = "unknown"
+		cp.Integrations[0].Type = "unknown"
+		err := service.UpsertContactPoint(ctx, 1, cp, models.ProvenanceFile)
+		require.ErrorIs(t, err, ErrValidation)
+
+		cp = slack("https://hooks.slack.com/secret")
+		cp.Name = "duplicate"
+		cp.Integrations[0].UID = getContactPoint(t, service, "slack").Integrations[0].UID
+		err = service.UpsertContactPoint(ctx, 1, cp, models.ProvenanceFile)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("contact points used by notification policies cannot be deleted", func(t *testing.T) {
+		err := service.DeleteContactPoint(ctx, 1, "grafana-default-email", models.ProvenanceFile)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("deleted contact points are no longer provisioned", func(t *testing.T) {
+		err := service.DeleteContactPoint(ctx, 1, "slack", models.ProvenanceFile)
+		require.NoError(t, err)
+
+		contactPoints, err := service.GetContactPoints(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, contactPoints, 1)
+		provenance, err := dbstore.GetProvenance(ctx, models.ContactPointResource(1, "slack"))
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, provenance)
+	})
+}
+
+func getContactPoint(t *testing.T, service *ContactPointService, name string) ContactPoint {
+	t.Helper()
+	contactPoints, err := service.GetContactPoints(context.Background(), 1)
+	require.NoError(t, err)
+	for _, cp := range contactPoints {
+		if cp.Name == name {
+			return cp
+		}
+	}
+	require.Failf(t, "contact point not found", "name: %s", name)
+	return ContactPoint{}
+}
+
+func latestConfig(t *testing.T, configStore AMConfigStore) string {
+	t.Helper()
+	query := models.GetLatestAlertmanagerConfigurationQuery{OrgID: 1}
+	require.NoError(t, configStore.GetLatestAlertmanagerConfiguration(context.Background(), &query))
+	return query.Result.AlertmanagerConfiguration
+}
@@ -0,0 +1,117 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// MuteTiming is a mute time interval of the Alertmanager configuration.
+type MuteTiming struct {
+	config.MuteTimeInterval
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+// MuteTimingService manages the mute timings of the Alertmanager configurations and records their provenance.
+type MuteTimingService struct {
+	configStore     alertmanagerConfigStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	log             log.Logger
+}
+
+func NewMuteTimingService(configStore AMConfigStore, defaultConfig string, provenanceStore ProvisioningStore,
+	xact TransactionManager, log log.Logger) *MuteTimingService {
+	return &MuteTimingService{
+		configStore:     alertmanagerConfigStore{store: configStore, defaultConfig: defaultConfig},
+		provenanceStore: provenanceStore,
+		xact:            xact,
+		log:             log,
+	}
+}
+
+// GetMuteTimings returns the mute timings of the organization with their provenance.
+func (service *MuteTimingService) GetMuteTimings(ctx context.Context, orgID int64) ([]MuteTiming, error) {
+	cfg, err := service.configStore.load(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := service.provenanceStore.GetProvenances(ctx, orgID, models.ResourceTypeMuteTiming)
+	if err != nil {
+		return nil, err
+	}
+
+	timings := make([]MuteTiming, 0, len(cfg.AlertmanagerConfig.MuteTimeIntervals))
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		timings = append(timings, MuteTiming{MuteTimeInterval: mt, Provenance: provenances[mt.Name]})
+	}
+	return timings, nil
+}
+
+// UpsertMuteTiming creates a mute timing, or replaces the mute timing with the same name.
+func (service *MuteTimingService) UpsertMuteTiming(ctx context.Context, orgID int64, mt config.MuteTimeInterval, provenance models.Provenance) error {
+	if mt.Name == "" {
+		return fmt.Errorf("%w: mute timing name is empty", ErrValidation)
+	}
+
+	resource := models.MuteTimingResource(orgID, mt.Name)
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		stored, err := service.provenanceStore.GetProvenance(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+
+		err = service.configStore.update(ctx, orgID, func(cfg *definitions.PostableUserConfig) error {
+			for i, existing := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+				if existing.Name == mt.Name {
+					cfg.AlertmanagerConfig.MuteTimeIntervals[i] = mt
+					return nil
+				}
+			}
+			cfg.AlertmanagerConfig.MuteTimeIntervals = append(cfg.AlertmanagerConfig.MuteTimeIntervals, mt)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return service.provenanceStore.SetProvenance(ctx, resource, provenance)
+	})
+}
+
+// DeleteMuteTiming deletes a mute timing, when its provenance allows it and when it is not used by
+// the notification policies.
+func (service *MuteTimingService) DeleteMuteTiming(ctx context.Context, orgID int64, name string, provenance models.Provenance) error {
+	resource := models.MuteTimingResource(orgID, name)
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		stored, err := service.provenanceStore.GetProvenance(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+
+		err = service.configStore.update(ctx, orgID, func(cfg *definitions.PostableUserConfig) error {
+			timings := cfg.AlertmanagerConfig.MuteTimeIntervals[:0]
+			for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+				if mt.Name != name {
+					timings = append(timings, mt)
+				}
+			}
+			cfg.AlertmanagerConfig.MuteTimeIntervals = timings
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return service.provenanceStore.DeleteProvenance(ctx, resource)
+	})
+}
@@ -0,0 +1,175 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ErrPolicyNotFound is returned when a path does not address a policy of the notification policy tree.
+var ErrPolicyNotFound = errors.New("notification policy not found")
+
+// NotificationPolicyService manages the notification policy tree of the Alertmanager configurations
+// and records its provenance.
+type NotificationPolicyService struct {
+	configStore     alertmanagerConfigStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	log             log.Logger
+}
+
+func NewNotificationPolicyService(configStore AMConfigStore, defaultConfig string, provenanceStore ProvisioningStore,
+	xact TransactionManager, log log.Logger) *NotificationPolicyService {
+	return &NotificationPolicyService{
+		configStore:     alertmanagerConfigStore{store: configStore, defaultConfig: defaultConfig},
+		provenanceStore: provenanceStore,
+		xact:            xact,
+		log:             log,
+	}
+}
+
+// GetPolicyTree returns the root route of the notification policies of the organization, and its provenance.
+func (service *NotificationPolicyService) GetPolicyTree(ctx context.Context, orgID int64) (definitions.Route, models.Provenance, error) {
+	cfg, err := service.configStore.load(ctx, orgID)
+	if err != nil {
+		return definitions.Route{}, models.ProvenanceNone, err
+	}
+	if cfg.AlertmanagerConfig.Route == nil {
+		return definitions.Route{}, models.ProvenanceNone, fmt.Errorf("the configuration of organization %d has no notification policies", orgID)
+	}
+	provenance, err := service.provenanceStore.GetProvenance(ctx, models.NotificationPolicyResource(orgID))
+	if err != nil {
+		return definitions.Route{}, models.ProvenanceNone, err
+	}
+	return *cfg.AlertmanagerConfig.Route, provenance, nil
+}
+
+// UpdatePolicyTree replaces the notification policy tree of the organization.
+func (service *NotificationPolicyService) UpdatePolicyTree(ctx context.Context, orgID int64, tree definitions.Route, provenance models.Provenance) error {
+	// the provenance is recorded in the provisioning store, not in the configuration
+	tree.Provenance = models.ProvenanceNone
+	return service.setPolicyTree(ctx, orgID, &tree, provenance, false)
+}
+
+// ResetPolicyTree restores the notification policy tree of the default configuration, and makes it
+// editable again.
+func (service *NotificationPolicyService) ResetPolicyTree(ctx context.Context, orgID int64, provenance models.Provenance) error {
+	defaultCfg := &definitions.PostableUserConfig{}
+	if err := json.Unmarshal([]byte(service.configStore.defaultConfig), defaultCfg); err != nil {
+		return fmt.Errorf("failed to parse the default Alertmanager configuration: %w", err)
+	}
+	return service.setPolicyTree(ctx, orgID, defaultCfg.AlertmanagerConfig.Route, provenance, true)
+}
+
+// GetPolicy returns the notification policy at path, and the provenance of the tree. The path lists the
+// indexes of the nested policies from the root policy, the empty path addresses the root policy.
+func (service *NotificationPolicyService) GetPolicy(ctx context.Context, orgID int64, path []int) (definitions.Route, models.Provenance, error) {
+	tree, provenance, err := service.GetPolicyTree(ctx, orgID)
+	if err != nil {
+		return definitions.Route{}, models.ProvenanceNone, err
+	}
+	policy, err := policyAt(&tree, path)
+	if err != nil {
+		return definitions.Route{}, models.ProvenanceNone, err
+	}
+	return *policy, provenance, nil
+}
+
+// AddPolicy appends a policy to the nested policies of the policy at parentPath.
+func (service *NotificationPolicyService) AddPolicy(ctx context.Context, orgID int64, parentPath []int, policy definitions.Route, provenance models.Provenance) error {
+	policy.Provenance = models.ProvenanceNone
+	return service.changePolicyTree(ctx, orgID, provenance, false, func(tree *definitions.Route) error {
+		parent, err := policyAt(tree, parentPath)
+		if err != nil {
+			return err
+		}
+		parent.Routes = append(parent.Routes, &policy)
+		return nil
+	})
+}
+
+// UpdatePolicy replaces the policy at path, together with its nested policies.
+func (service *NotificationPolicyService) UpdatePolicy(ctx context.Context, orgID int64, path []int, policy definitions.Route, provenance models.Provenance) error {
+	policy.Provenance = models.ProvenanceNone
+	return service.changePolicyTree(ctx, orgID, provenance, false, func(tree *definitions.Route) error {
+		existing, err := policyAt(tree, path)
+		if err != nil {
+			return err
+		}
+		*existing = policy
+		return nil
+	})
+}
+
+// DeletePolicy removes the policy at path, together with its nested policies. The root policy cannot
+// be deleted, use ResetPolicyTree instead.
+func (service *NotificationPolicyService) DeletePolicy(ctx context.Context, orgID int64, path []int, provenance models.Provenance) error {
+	if len(path) == 0 {
+		return fmt.Errorf("%w: the root notification policy cannot be deleted", ErrValidation)
+	}
+	return service.changePolicyTree(ctx, orgID, provenance, false, func(tree *definitions.Route) error {
+		parent, err := policyAt(tree, path[:len(path)-1])
+		if err != nil {
+			return err
+		}
+		i := path[len(path)-1]
+		if i < 0 || i >= len(parent.Routes) {
+			return ErrPolicyNotFound
+		}
+		parent.Routes = append(parent.Routes[:i], parent.Routes[i+1:]...)
+		return nil
+	})
+}
+
+// policyAt returns the policy of the tree at path.
+func policyAt(tree *definitions.Route, path []int) (*definitions.Route, error) {
+	policy := tree
+	for _, i := range path {
+		if i < 0 || i >= len(policy.Routes) || policy.Routes[i] == nil {
+			return nil, ErrPolicyNotFound
+		}
+		policy = policy.Routes[i]
+	}
+	return policy, nil
+}
+
+func (service *NotificationPolicyService) setPolicyTree(ctx context.Context, orgID int64, tree *definitions.Route, provenance models.Provenance, reset bool) error {
+	return service.changePolicyTree(ctx, orgID, provenance, reset, func(root *definitions.Route) error {
+		*root = *tree
+		return nil
+	})
+}
+
+// changePolicyTree changes the notification policy tree of the organization with fn, when the provenance
+// of the tree allows it.
+func (service *NotificationPolicyService) changePolicyTree(ctx context.Context, orgID int64, provenance models.Provenance, reset bool, fn func(tree *definitions.Route) error) error {
+	resource := models.NotificationPolicyResource(orgID)
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		stored, err := service.provenanceStore.GetProvenance(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+
+		err = service.configStore.update(ctx, orgID, func(cfg *definitions.PostableUserConfig) error {
+			if cfg.AlertmanagerConfig.Route == nil {
+				cfg.AlertmanagerConfig.Route = &definitions.Route{}
+			}
+			return fn(cfg.AlertmanagerConfig.Route)
+		})
+		if err != nil {
+			return err
+		}
+		if reset {
+			return service.provenanceStore.DeleteProvenance(ctx, resource)
+		}
+		return service.provenanceStore.SetProvenance(ctx, resource, provenance)
+	})
+}
@@ -0,0 +1,162 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNotificationPolicyService(t *testing.T) {
+	dbstore := setupTestStore(t)
+	defaultConfig := setting.GetAlertmanagerDefaultConfiguration()
+	policies := NewNotificationPolicyService(dbstore, defaultConfig, dbstore, dbstore.SQLStore, log.New("test"))
+	muteTimings := NewMuteTimingService(dbstore, defaultConfig, dbstore, dbstore.SQLStore, log.New("test"))
+	ctx := context.Background()
+
+	weekends := config.MuteTimeInterval{
+		Name: "weekends",
+		TimeIntervals: []timeinterval.TimeInterval{{
+			Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}},
+		}},
+	}
+	tree := definitions.Route{
+		Receiver: "grafana-default-email",
+		Routes: []*definitions.Route{{
+			Receiver:          "grafana-default-email",
+			MuteTimeIntervals: []string{"weekends"},
+		}},
+	}
+
+	t.Run("policies cannot reference unknown mute timings", func(t *testing.T) {
+		err := policies.UpdatePolicyTree(ctx, 1, tree, models.ProvenanceFile)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("provisioned policies cannot be changed without provenance", func(t *testing.T) {
+		require.NoError(t, muteTimings.UpsertMuteTiming(ctx, 1, weekends, models.ProvenanceFile))
+		require.NoError(t, policies.UpdatePolicyTree(ctx, 1, tree, models.ProvenanceFile))
+
+		actual, provenance, err := policies.GetPolicyTree(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceFile, provenance)
+		require.Equal(t, []string{"weekends"}, actual.Routes[0].MuteTimeIntervals)
+
+		err = policies.UpdatePolicyTree(ctx, 1, definitions.Route{Receiver: "grafana-default-email"}, models.ProvenanceApi)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+		err = muteTimings.DeleteMuteTiming(ctx, 1, "weekends", models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+	})
+
+	t.Run("mute timings used by policies cannot be deleted", func(t *testing.T) {
+		err := muteTimings.DeleteMuteTiming(ctx, 1, "weekends", models.ProvenanceFile)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("reset policies are no longer provisioned", func(t *testing.T) {
+		require.NoError(t, policies.ResetPolicyTree(ctx, 1, models.ProvenanceFile))
+
+		actual, provenance, err := policies.GetPolicyTree(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, provenance)
+		require.Empty(t, actual.Routes)
+
+		require.NoError(t, muteTimings.DeleteMuteTiming(ctx, 1, "weekends", models.ProvenanceFile))
+		timings, err := muteTimings.GetMuteTimings(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, timings)
+	})
+}
+
+func TestNotificationPolicyService_Policy(t *testing.T) {
+	dbstore := setupTestStore(t)
+	policies := NewNotificationPolicyService(dbstore, setting.GetAlertmanagerDefaultConfiguration(), dbstore, dbstore.SQLStore, log.New("test"))
+	ctx := context.Background()
+
+	require.NoError(t, policies.AddPolicy(ctx, 1, nil, definitions.Route{Receiver: "grafana-default-email", GroupByStr: []string{"a"}}, models.ProvenanceApi))
+	require.NoError(t, policies.AddPolicy(ctx, 1, []int{0}, definitions.Route{Receiver: "grafana-default-email", GroupByStr: []string{"b"}}, models.ProvenanceApi))
+	require.NoError(t, policies.AddPolicy(ctx, 1, nil, definitions.Route{Receiver: "grafana-default-email", GroupByStr: []string{"c"}}, models.ProvenanceApi))
+
+	policy, provenance, err := policies.GetPolicy(ctx, 1, []int{0, 0})
+	require.NoError(t, err)
+	require.Equal(t, models.ProvenanceApi, provenance)
+	require.Equal(t, []string{"b"}, policy.GroupByStr)
+
+	t.Run("updating a policy keeps its siblings", func(t *testing.T) {
+		require.NoError(t, policies.UpdatePolicy(ctx, 1, []int{0}, definitions.Route{Receiver: "grafana-default-email", GroupByStr: []string{"d"}}, models.ProvenanceApi))
+
+		tree, _, err := policies.GetPolicyTree(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, tree.Routes, 2)
+		require.Equal(t, []string{"d"}, tree.Routes[0].GroupByStr)
+		require.Empty(t, tree.Routes[0].Routes)
+		require.Equal(t, []string{"c"}, tree.Routes[1].GroupByStr)
+	})
+
+	t.Run("deleting a policy keeps its siblings", func(t *testing.T) {
+		require.NoError(t, policies.DeletePolicy(ctx, 1, []int{0}, models.ProvenanceApi))
+
+		tree, _, err := policies.GetPolicyTree(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, tree.Routes, 1)
+		require.Equal(t, []string{"c"}, tree.Routes[0].GroupByStr)
+	})
+
+	t.Run("unknown policies are not found", func(t *testing.T) {
+		_, _, err := policies.GetPolicy(ctx, 1, []int{3})
+		require.ErrorIs(t, err, ErrPolicyNotFound)
+		err = policies.UpdatePolicy(ctx, 1, []int{0, 1}, definitions.Route{Receiver: "grafana-default-email"}, models.ProvenanceApi)
+		require.ErrorIs(t, err, ErrPolicyNotFound)
+		err = policies.DeletePolicy(ctx, 1, []int{1}, models.ProvenanceApi)
+		require.ErrorIs(t, err, ErrPolicyNotFound)
+	})
+
+	t.Run("the root policy cannot be deleted", func(t *testing.T) {
+		err := policies.DeletePolicy(ctx, 1, nil, models.ProvenanceApi)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("policies provisioned by files cannot be changed by the API", func(t *testing.T) {
+		require.NoError(t, policies.UpdatePolicy(ctx, 1, []int{0}, definitions.Route{Receiver: "grafana-default-email"}, models.ProvenanceFile))
+
+		err := policies.AddPolicy(ctx, 1, nil, definitions.Route{Receiver: "grafana-default-email"}, models.ProvenanceApi)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+	})
+}
+
+func TestTemplateService(t *testing.T) {
+	dbstore := setupTestStore(t)
+	service := NewTemplateService(dbstore, setting.GetAlertmanagerDefaultConfiguration(), dbstore, dbstore.SQLStore, log.New("test"))
+	ctx := context.Background()
+
+	t.Run("invalid templates are refused", func(t *testing.T) {
+		err := service.SetTemplate(ctx, 1, MessageTemplate{Name: "broken", Template: `{{ define "broken" }}`}, models.ProvenanceFile)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("provisioned templates cannot be changed without provenance", func(t *testing.T) {
+		tmpl := MessageTemplate{Name: "title", Template: `{{ define "title" }}{{ .Status | toUpper }}{{ end }}`}
+		require.NoError(t, service.SetTemplate(ctx, 1, tmpl, models.ProvenanceFile))
+
+		templates, err := service.GetTemplates(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, []MessageTemplate{{Name: "title", Template: tmpl.Template, Provenance: models.ProvenanceFile}}, templates)
+
+		err = service.SetTemplate(ctx, 1, MessageTemplate{Name: "title", Template: "changed"}, models.ProvenanceNone)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+		err = service.DeleteTemplate(ctx, 1, "title", models.ProvenanceApi)
+		require.ErrorIs(t, err, models.ErrProvisionedResource)
+
+		require.NoError(t, service.DeleteTemplate(ctx, 1, "title", models.ProvenanceFile))
+		templates, err = service.GetTemplates(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, templates)
+	})
+}
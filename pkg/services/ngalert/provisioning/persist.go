@@ -0,0 +1,35 @@
+package provisioning
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// AMConfigStore is a store of the Alertmanager configurations of organizations.
+type AMConfigStore interface {
+	GetLatestAlertmanagerConfiguration(ctx context.Context, query *models.GetLatestAlertmanagerConfigurationQuery) error
+	SaveAlertmanagerConfiguration(ctx context.Context, cmd *models.SaveAlertmanagerConfigurationCmd) error
+}
+
+// ProvisioningStore is a store of the provenance of provisioned resources.
+type ProvisioningStore interface {
+	GetProvenance(ctx context.Context, o models.Provisionable) (models.Provenance, error)
+	GetProvenances(ctx context.Context, orgID int64, resourceType string) (map[string]models.Provenance, error)
+	SetProvenance(ctx context.Context, o models.Provisionable, p models.Provenance) error
+	DeleteProvenance(ctx context.Context, o models.Provisionable) error
+}
+
+// RuleStore is a store of alert rules.
+type RuleStore interface {
+	GetAlertRuleByUID(ctx context.Context, query *models.GetAlertRuleByUIDQuery) error
+	GetRuleGroupAlertRules(ctx context.Context, query *models.ListRuleGroupAlertRulesQuery) error
+	UpsertAlertRules(ctx context.Context, rule []store.UpsertRule) error
+	DeleteAlertRuleByUID(ctx context.Context, orgID int64, ruleUID string) error
+}
+
+// TransactionManager runs the changes of a resource and of its provenance in a transaction.
+type TransactionManager interface {
+	InTransaction(ctx context.Context, work func(ctx context.Context) error) error
+}
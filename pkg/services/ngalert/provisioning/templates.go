@@ -0,0 +1,118 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"text/template"
+
+	amtemplate "github.com/prometheus/alertmanager/template"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// MessageTemplate is a notification template of the Alertmanager configuration.
+type MessageTemplate struct {
+	Name       string            `json:"name"`
+	Template   string            `json:"template"`
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+// TemplateService manages the notification templates of the Alertmanager configurations and records
+// their provenance.
+type TemplateService struct {
+	configStore     alertmanagerConfigStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	log             log.Logger
+}
+
+func NewTemplateService(configStore AMConfigStore, defaultConfig string, provenanceStore ProvisioningStore,
+	xact TransactionManager, log log.Logger) *TemplateService {
+	return &TemplateService{
+		configStore:     alertmanagerConfigStore{store: configStore, defaultConfig: defaultConfig},
+		provenanceStore: provenanceStore,
+		xact:            xact,
+		log:             log,
+	}
+}
+
+// GetTemplates returns the notification templates of the organization with their provenance, sorted
+// by name.
+func (service *TemplateService) GetTemplates(ctx context.Context, orgID int64) ([]MessageTemplate, error) {
+	cfg, err := service.configStore.load(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	provenances, err := service.provenanceStore.GetProvenances(ctx, orgID, models.ResourceTypeTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]MessageTemplate, 0, len(cfg.TemplateFiles))
+	for name, content := range cfg.TemplateFiles {
+		templates = append(templates, MessageTemplate{Name: name, Template: content, Provenance: provenances[name]})
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// SetTemplate creates or replaces a notification template. The template must be valid.
+func (service *TemplateService) SetTemplate(ctx context.Context, orgID int64, tmpl MessageTemplate, provenance models.Provenance) error {
+	if tmpl.Name == "" {
+		return fmt.Errorf("%w: template name is empty", ErrValidation)
+	}
+	if _, err := template.New(tmpl.Name).Funcs(template.FuncMap(amtemplate.DefaultFuncs)).Parse(tmpl.Template); err != nil {
+		return fmt.Errorf("%w: template %q: %s", ErrValidation, tmpl.Name, err)
+	}
+
+	resource := models.TemplateResource(orgID, tmpl.Name)
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		stored, err := service.provenanceStore.GetProvenance(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+
+		err = service.configStore.update(ctx, orgID, func(cfg *definitions.PostableUserConfig) error {
+			if cfg.TemplateFiles == nil {
+				cfg.TemplateFiles = map[string]string{}
+			}
+			cfg.TemplateFiles[tmpl.Name] = tmpl.Template
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return service.provenanceStore.SetProvenance(ctx, resource, provenance)
+	})
+}
+
+// DeleteTemplate deletes a notification template, when its provenance allows it.
+func (service *TemplateService) DeleteTemplate(ctx context.Context, orgID int64, name string, provenance models.Provenance) error {
+	resource := models.TemplateResource(orgID, name)
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		stored, err := service.provenanceStore.GetProvenance(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if err := stored.CanUpdate(provenance); err != nil {
+			return err
+		}
+
+		err = service.configStore.update(ctx, orgID, func(cfg *definitions.PostableUserConfig) error {
+			delete(cfg.TemplateFiles, name)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return service.provenanceStore.DeleteProvenance(ctx, resource)
+	})
+}
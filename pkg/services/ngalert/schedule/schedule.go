@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -19,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 
 	"github.com/benbjohnson/clock"
 	"golang.org/x/sync/errgroup"
@@ -98,6 +101,25 @@ type schedule struct {
 	adminConfigPollInterval time.Duration
 	disabledOrgs            map[int64]struct{}
 	minRuleInterval         time.Duration
+
+	// recordingWriter writes the results of recording rules. Recording rules are not evaluated if it is nil.
+	recordingWriter writer.Writer
+
+	// membership lists the instances of the cluster the alert rules are sharded across.
+	// Every alert rule is evaluated by this instance if it is nil.
+	membership ClusterMembership
+	ring       *hashRing
+	// releasedRules are the alert rules that are evaluated by another instance of the cluster from now on.
+	// Their routines save the state of the alert instances instead of resolving them when they stop.
+	releasedRulesMtx sync.Mutex
+	releasedRules    map[models.AlertRuleKey]struct{}
+	// handOffStore keeps the acknowledgements of the instances that handed off the state of released rules.
+	// The state of acquired rules is loaded without waiting for the acknowledgement if it is nil.
+	handOffStore kvstore.KVStore
+	// acquiredRules are the alert rules this instance evaluates since the cluster changed, with the time of
+	// the change. They are not evaluated until their state is handed off by the instance that evaluated them.
+	acquiredRulesMtx sync.Mutex
+	acquiredRules    map[models.AlertRuleKey]time.Time
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -118,6 +140,9 @@ type SchedulerCfg struct {
 	AdminConfigPollInterval time.Duration
 	DisabledOrgs            map[int64]struct{}
 	MinRuleInterval         time.Duration
+	RecordingWriter         writer.Writer
+	ClusterMembership       ClusterMembership
+	HandOffStore            kvstore.KVStore
 }
 
 // NewScheduler returns a new schedule.
@@ -149,6 +174,11 @@ func NewScheduler(cfg SchedulerCfg, expressionService *expr.Service, appURL *url
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
 		disabledOrgs:            cfg.DisabledOrgs,
 		minRuleInterval:         cfg.MinRuleInterval,
+		recordingWriter:         cfg.RecordingWriter,
+		membership:              cfg.ClusterMembership,
+		releasedRules:           map[models.AlertRuleKey]struct{}{},
+		handOffStore:            cfg.HandOffStore,
+		acquiredRules:           map[models.AlertRuleKey]time.Time{},
 	}
 	return &sch
 }
@@ -384,6 +414,15 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 			alertRules := sch.getAlertRules(ctx, disabledOrgs)
 			sch.log.Debug("alert rules fetched", "count", len(alertRules), "disabled_orgs", disabledOrgs)
 
+			// the state of the rules handed off by other instances is loaded when their routines start, or
+			// once the previous owner acknowledged the hand-off. It is not needed on the first tick because
+			// the state manager has loaded all of it.
+			warmNewRoutines := sch.membership != nil && sch.ring != nil
+			if sch.membership != nil {
+				alertRules = sch.ownedAlertRules(alertRules)
+				sch.log.Debug("alert rules owned by this instance", "count", len(alertRules))
+			}
+
 			// registeredDefinitions is a map used for finding deleted alert rules
 			// initially it is assigned to all known alert rules from the previous cycle
 			// each alert rule found also in this cycle is removed
@@ -411,6 +450,9 @@ func (sch *schedule) schedulePeriodic(ctx context.Context) error {
 				invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 				if newRoutine && !invalidInterval {
+					if warmNewRoutines && !sch.isAcquired(key) {
+						sch.stateManager.WarmRule(ctx, item)
+					}
 					dispatcherGroup.Go(func() error {
 						return sch.ruleRoutine(ruleInfo.ctx, key, ruleInfo.evalCh, ruleInfo.updateCh)
 					})
@@ -532,6 +574,21 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		notify(expiredAlerts, logger)
 	}
 
+	// handOffState saves the state of the alert instances so the instance of the cluster that evaluates
+	// the rule from now on can continue from it, and acknowledges the hand-off to that instance.
+	handOffState := func() {
+		states := sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID)
+		sch.saveAlertStates(context.Background(), states)
+		sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+		if sch.handOffStore != nil {
+			ack := strconv.FormatInt(sch.clock.Now().UnixNano(), 10)
+			if err := sch.handOffStore.Set(context.Background(), key.OrgID, handOffNamespace, key.UID, ack); err != nil {
+				logger.Error("failed to acknowledge the hand-off of the alert rule", "err", err)
+			}
+		}
+		logger.Debug("alert rule handed off to another instance", "states", len(states))
+	}
+
 	updateRule := func(ctx context.Context, oldRule *models.AlertRule) (*models.AlertRule, error) {
 		q := models.GetAlertRuleByUIDQuery{OrgID: key.OrgID, UID: key.UID}
 		err := sch.ruleStore.GetAlertRuleByUID(ctx, &q)
@@ -547,6 +604,10 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 
 	evaluate := func(ctx context.Context, r *models.AlertRule, attempt int64, e *evaluation) error {
 		logger := logger.New("version", r.Version, "attempt", attempt, "now", e.scheduledAt)
+		if r.IsRecordingRule() && sch.recordingWriter == nil {
+			logger.Debug("skipping evaluation of recording rule because recording rules are not configured")
+			return nil
+		}
 		start := sch.clock.Now()
 
 		condition := models.Condition{
@@ -566,6 +627,14 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 		}
 		logger.Debug("alert rule evaluated", "results", results, "duration", dur)
 
+		if r.IsRecordingRule() {
+			if err := sch.recordingWriter.Write(ctx, r, results); err != nil {
+				logger.Error("failed to write the results of recording rule", "err", err)
+				return err
+			}
+			return nil
+		}
+
 		processedStates := sch.stateManager.ProcessEvalResults(ctx, r, results)
 		sch.saveAlertStates(ctx, processedStates)
 		alerts := FromAlertStateToPostableAlerts(processedStates, sch.stateManager, sch.appURL)
@@ -633,6 +702,10 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 						currentRule = newRule
 						logger.Debug("new alert rule version fetched", "title", newRule.Title, "version", newRule.Version)
 					}
+					if !sch.takeHandOff(grafanaCtx, currentRule) {
+						logger.Debug("skipping evaluation until the previous owner of the alert rule hands off its state")
+						return nil
+					}
 					return evaluate(grafanaCtx, currentRule, attempt, ctx)
 				})
 				if err != nil {
@@ -640,13 +713,141 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 				}
 			}()
 		case <-grafanaCtx.Done():
-			clearState()
+			if sch.takeReleased(key) {
+				handOffState()
+			} else {
+				clearState()
+			}
 			logger.Debug("stopping alert rule routine")
 			return nil
 		}
 	}
 }
 
+// ownedAlertRules returns the alert rules evaluated by this instance of the cluster. The routines of the
+// rules owned by other instances are marked as released, so they hand off the state when they are stopped.
+// If this instance is not a member of the cluster, it evaluates all alert rules.
+func (sch *schedule) ownedAlertRules(alertRules []*models.AlertRule) []*models.AlertRule {
+	self := sch.membership.Self()
+	members := sch.membership.Members()
+	isMember := false
+	for _, m := range members {
+		if m == self {
+			isMember = true
+			break
+		}
+	}
+	if !isMember {
+		sch.log.Warn("this instance is not a member of the cluster, evaluating all alert rules", "self", self, "members", members)
+		return alertRules
+	}
+
+	firstTick := sch.ring == nil
+	var previous *hashRing
+	if firstTick || !sch.ring.hasMembers(members) {
+		previous = sch.ring
+		sch.ring = newHashRing(members)
+		sch.log.Info("sharding alert rules across the instances of the cluster", "self", self, "members", members)
+	}
+
+	changedAt := sch.clock.Now()
+	owned := make([]*models.AlertRule, 0, len(alertRules)/len(members)+1)
+	for _, rule := range alertRules {
+		key := rule.GetKey()
+		if sch.ring.owner(key) == self {
+			owned = append(owned, rule)
+			if previous != nil && sch.handOffStore != nil && previous.owner(key) != self && !sch.registry.exists(key) {
+				sch.markAcquired(key, changedAt)
+			}
+			continue
+		}
+		if sch.registry.exists(key) {
+			sch.markReleased(key)
+		} else if firstTick {
+			// the state manager loads the state of all alert rules on startup.
+			sch.stateManager.RemoveByRuleUID(key.OrgID, key.UID)
+		}
+	}
+	return owned
+}
+
+func (sch *schedule) markReleased(key models.AlertRuleKey) {
+	sch.releasedRulesMtx.Lock()
+	defer sch.releasedRulesMtx.Unlock()
+	sch.releasedRules[key] = struct{}{}
+
+	// the rule may be released again before its previous owner handed it off.
+	sch.acquiredRulesMtx.Lock()
+	defer sch.acquiredRulesMtx.Unlock()
+	delete(sch.acquiredRules, key)
+}
+
+func (sch *schedule) markAcquired(key models.AlertRuleKey, changedAt time.Time) {
+	sch.acquiredRulesMtx.Lock()
+	defer sch.acquiredRulesMtx.Unlock()
+	sch.acquiredRules[key] = changedAt
+}
+
+// isAcquired returns true if the state of the alert rule has not been handed off by its previous owner yet.
+func (sch *schedule) isAcquired(key models.AlertRuleKey) bool {
+	sch.acquiredRulesMtx.Lock()
+	defer sch.acquiredRulesMtx.Unlock()
+	_, ok := sch.acquiredRules[key]
+	return ok
+}
+
+// takeHandOff returns true if the alert rule can be evaluated. The state of an acquired rule is loaded once
+// its previous owner acknowledged the hand-off, or once handOffTimeoutIntervals passed since the cluster
+// changed because the previous owner may have left the cluster without handing off.
+func (sch *schedule) takeHandOff(ctx context.Context, rule *models.AlertRule) bool {
+	key := rule.GetKey()
+	sch.acquiredRulesMtx.Lock()
+	changedAt, ok := sch.acquiredRules[key]
+	sch.acquiredRulesMtx.Unlock()
+	if !ok {
+		return true
+	}
+
+	timeout := handOffTimeoutIntervals * sch.baseInterval
+	value, found, err := sch.handOffStore.Get(ctx, key.OrgID, handOffNamespace, key.UID)
+	if err != nil {
+		sch.log.Error("failed to get the hand-off of the alert rule", "uid", key.UID, "orgId", key.OrgID, "err", err)
+	}
+	acknowledged := false
+	if found {
+		// acknowledgements older than the change of the cluster are left from a previous hand-off. The
+		// clocks of the instances are allowed to drift by the timeout.
+		ackedAt, err := strconv.ParseInt(value, 10, 64)
+		acknowledged = err == nil && time.Unix(0, ackedAt).After(changedAt.Add(-timeout))
+	}
+	if !acknowledged && sch.clock.Now().Before(changedAt.Add(timeout)) {
+		return false
+	}
+	if !acknowledged {
+		sch.log.Warn("loading the state of the alert rule without a hand-off from its previous owner", "uid", key.UID, "orgId", key.OrgID)
+	}
+	if found {
+		if err := sch.handOffStore.Del(ctx, key.OrgID, handOffNamespace, key.UID); err != nil {
+			sch.log.Error("failed to delete the hand-off of the alert rule", "uid", key.UID, "orgId", key.OrgID, "err", err)
+		}
+	}
+
+	sch.stateManager.WarmRule(ctx, rule)
+	sch.acquiredRulesMtx.Lock()
+	delete(sch.acquiredRules, key)
+	sch.acquiredRulesMtx.Unlock()
+	return true
+}
+
+// takeReleased returns true if the alert rule was released to another instance of the cluster, and clears the mark.
+func (sch *schedule) takeReleased(key models.AlertRuleKey) bool {
+	sch.releasedRulesMtx.Lock()
+	defer sch.releasedRulesMtx.Unlock()
+	_, ok := sch.releasedRules[key]
+	delete(sch.releasedRules, key)
+	return ok
+}
+
 func (sch *schedule) saveAlertStates(ctx context.Context, states []*state.State) {
 	sch.log.Debug("saving alert states", "count", len(states))
 	for _, s := range states {
@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ringReplicas is the number of points of each member on the hash ring. More points spread the
+// alert rules more evenly across the members.
+const ringReplicas = 128
+
+// handOffNamespace is the namespace of the kvstore keys with which an instance acknowledges that it saved the
+// state of an alert rule it released, so the new owner of the rule can load it.
+const handOffNamespace = "alerting.rule_handoff"
+
+// handOffTimeoutIntervals is the number of base intervals the new owner of an alert rule waits for the
+// acknowledgement of the previous owner before it evaluates the rule anyway.
+const handOffTimeoutIntervals = 3
+
+// ClusterMembership lists the instances of Grafana that share the evaluation of alert rules.
+type ClusterMembership interface {
+	// Self returns the name of this instance.
+	Self() string
+	// Members returns the names of the alive instances of the cluster, including this one.
+	Members() []string
+}
+
+// hashRing assigns alert rules to the members of the cluster with consistent hashing, so that
+// only the rules of a member that joins or leaves the cluster move to other members.
+type hashRing struct {
+	members []string
+	points  []uint64
+	owners  map[uint64]string
+}
+
+func newHashRing(members []string) *hashRing {
+	sorted := make([]string, len(members))
+	copy(sorted, members)
+	sort.Strings(sorted)
+
+	r := &hashRing{
+		members: sorted,
+		points:  make([]uint64, 0, len(sorted)*ringReplicas),
+		owners:  make(map[uint64]string, len(sorted)*ringReplicas),
+	}
+	for _, member := range sorted {
+		for i := 0; i < ringReplicas; i++ {
+			point := hashString(member + "#" + strconv.Itoa(i))
+			if _, ok := r.owners[point]; ok {
+				continue
+			}
+			r.owners[point] = member
+			r.points = append(r.points, point)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i] < r.points[j]
+	})
+	return r
+}
+
+// hasMembers returns true if the ring was built for exactly these members.
+func (r *hashRing) hasMembers(members []string) bool {
+	if len(members) != len(r.members) {
+		return false
+	}
+	sorted := make([]string, len(members))
+	copy(sorted, members)
+	sort.Strings(sorted)
+	for i := range sorted {
+		if sorted[i] != r.members[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// owner returns the member that evaluates the alert rule, or an empty string if the ring has no members.
+func (r *hashRing) owner(key models.AlertRuleKey) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hashString(strconv.FormatInt(key.OrgID, 10) + "/" + key.UID)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	// FNV does not spread similar strings well over the ring, so the bits are mixed with the
+	// finalizer of SplitMix64.
+	x := h.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
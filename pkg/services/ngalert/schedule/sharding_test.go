@@ -0,0 +1,269 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, models.AlertRuleKey{OrgID: int64(i%3 + 1), UID: fmt.Sprintf("rule-%d", i)})
+	}
+	owners := func(r *hashRing) map[models.AlertRuleKey]string {
+		result := make(map[models.AlertRuleKey]string, len(keys))
+		for _, key := range keys {
+			result[key] = r.owner(key)
+		}
+		return result
+	}
+
+	ring := newHashRing([]string{"grafana-1", "grafana-2", "grafana-3"})
+	before := owners(ring)
+
+	t.Run("spreads the rules across the members", func(t *testing.T) {
+		count := map[string]int{}
+		for _, owner := range before {
+			count[owner]++
+		}
+		require.Len(t, count, 3)
+		for member, c := range count {
+			require.Greaterf(t, c, len(keys)/5, "member %s owns too few rules", member)
+			require.Lessf(t, c, len(keys)/2, "member %s owns too many rules", member)
+		}
+	})
+
+	t.Run("moves only the rules of the new member when a member joins", func(t *testing.T) {
+		after := owners(newHashRing([]string{"grafana-4", "grafana-1", "grafana-2", "grafana-3"}))
+		moved := 0
+		for key, owner := range after {
+			if owner != before[key] {
+				require.Equal(t, "grafana-4", owner)
+				moved++
+			}
+		}
+		require.Greater(t, moved, 0)
+	})
+
+	t.Run("moves only the rules of the member that leaves", func(t *testing.T) {
+		after := owners(newHashRing([]string{"grafana-1", "grafana-3"}))
+		for key, owner := range after {
+			if before[key] != "grafana-2" {
+				require.Equal(t, before[key], owner)
+			}
+		}
+	})
+
+	t.Run("does not depend on the order of members", func(t *testing.T) {
+		require.True(t, ring.hasMembers([]string{"grafana-3", "grafana-1", "grafana-2"}))
+		require.False(t, ring.hasMembers([]string{"grafana-1", "grafana-2"}))
+		require.Equal(t, before, owners(newHashRing([]string{"grafana-2", "grafana-3", "grafana-1"})))
+	})
+
+	t.Run("has no owner without members", func(t *testing.T) {
+		require.Empty(t, newHashRing(nil).owner(keys[0]))
+	})
+}
+
+func TestSchedule_ownedAlertRules(t *testing.T) {
+	rules := make([]*models.AlertRule, 0, 100)
+	for i := 0; i < cap(rules); i++ {
+		rules = append(rules, &models.AlertRule{OrgID: 1, UID: fmt.Sprintf("rule-%d", i)})
+	}
+
+	t.Run("returns the rules owned by this instance", func(t *testing.T) {
+		sch := setupSchedulerWithFakeStores(t)
+		sch.membership = &fakeClusterMembership{self: "grafana-1", members: []string{"grafana-1", "grafana-2"}}
+
+		owned := sch.ownedAlertRules(rules)
+		require.NotEmpty(t, owned)
+		require.Less(t, len(owned), len(rules))
+		for _, rule := range owned {
+			require.Equal(t, "grafana-1", sch.ring.owner(rule.GetKey()))
+		}
+
+		other := newHashRing([]string{"grafana-1", "grafana-2"})
+		sch.membership = &fakeClusterMembership{self: "grafana-2", members: []string{"grafana-1", "grafana-2"}}
+		require.Len(t, sch.ownedAlertRules(rules), len(rules)-len(owned))
+		require.Equal(t, other.members, sch.ring.members)
+	})
+
+	t.Run("releases the running rules owned by other instances", func(t *testing.T) {
+		sch := setupSchedulerWithFakeStores(t)
+		sch.membership = &fakeClusterMembership{self: "grafana-1", members: []string{"grafana-1"}}
+		require.Len(t, sch.ownedAlertRules(rules), len(rules))
+		for _, rule := range rules {
+			sch.registry.getOrCreateInfo(context.Background(), rule.GetKey())
+		}
+
+		sch.membership = &fakeClusterMembership{self: "grafana-1", members: []string{"grafana-1", "grafana-2"}}
+		owned := sch.ownedAlertRules(rules)
+		ownedKeys := make(map[models.AlertRuleKey]struct{}, len(owned))
+		for _, rule := range owned {
+			ownedKeys[rule.GetKey()] = struct{}{}
+		}
+		for _, rule := range rules {
+			_, isOwned := ownedKeys[rule.GetKey()]
+			require.Equal(t, !isOwned, sch.takeReleased(rule.GetKey()))
+		}
+	})
+
+	t.Run("acquires the rules owned by other instances before the cluster changed", func(t *testing.T) {
+		sch := setupSchedulerWithFakeStores(t)
+		sch.handOffStore = notifier.NewFakeKVStore(t)
+		sch.membership = &fakeClusterMembership{self: "grafana-2", members: []string{"grafana-1", "grafana-2"}}
+		before := sch.ownedAlertRules(rules)
+		for _, rule := range before {
+			sch.registry.getOrCreateInfo(context.Background(), rule.GetKey())
+		}
+
+		sch.membership = &fakeClusterMembership{self: "grafana-2", members: []string{"grafana-2"}}
+		require.Len(t, sch.ownedAlertRules(rules), len(rules))
+		acquired := 0
+		for _, rule := range rules {
+			if sch.isAcquired(rule.GetKey()) {
+				acquired++
+			}
+		}
+		require.Equal(t, len(rules)-len(before), acquired)
+		for _, rule := range before {
+			require.False(t, sch.isAcquired(rule.GetKey()))
+		}
+	})
+
+	t.Run("returns all rules if this instance is not a member of the cluster", func(t *testing.T) {
+		sch := setupSchedulerWithFakeStores(t)
+		sch.membership = &fakeClusterMembership{self: "grafana-3", members: []string{"grafana-1", "grafana-2"}}
+		require.Len(t, sch.ownedAlertRules(rules), len(rules))
+	})
+}
+
+func TestSchedule_ruleRoutine_handOff(t *testing.T) {
+	instanceStore := &store.FakeInstanceStore{}
+	sch, _ := setupScheduler(t, store.NewFakeRuleStore(t), instanceStore, store.NewFakeAdminConfigStore(t), nil)
+	handOffStore := notifier.NewFakeKVStore(t)
+	sch.handOffStore = handOffStore
+	key := generateRuleKey()
+	sch.stateManager.Put([]*state.State{{
+		AlertRuleUID: key.UID,
+		OrgID:        key.OrgID,
+		CacheId:      "instance",
+		Labels:       map[string]string{"team": "ops"},
+		State:        eval.Alerting,
+		StartsAt:     time.Unix(1, 0),
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- sch.ruleRoutine(ctx, key, make(chan *evaluation), make(chan struct{}))
+	}()
+	sch.markReleased(key)
+	cancel()
+	require.NoError(t, waitForErrChannel(t, done))
+
+	require.Empty(t, sch.stateManager.GetStatesForRuleUID(key.OrgID, key.UID))
+	require.Len(t, instanceStore.RecordedOps, 1)
+	saved := instanceStore.RecordedOps[0].(models.SaveAlertInstanceCommand)
+	require.Equal(t, key.UID, saved.RuleUID)
+	require.Equal(t, models.InstanceStateFiring, saved.State)
+	require.False(t, sch.takeReleased(key))
+	_, acknowledged, err := handOffStore.Get(context.Background(), key.OrgID, handOffNamespace, key.UID)
+	require.NoError(t, err)
+	require.True(t, acknowledged)
+}
+
+func TestSchedule_takeHandOff(t *testing.T) {
+	countWarmed := func(instanceStore *store.FakeInstanceStore) int {
+		count := 0
+		for _, op := range instanceStore.RecordedOps {
+			if _, ok := op.(models.ListAlertInstancesQuery); ok {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("evaluates rules that are not acquired", func(t *testing.T) {
+		instanceStore := &store.FakeInstanceStore{}
+		sch, _ := setupScheduler(t, store.NewFakeRuleStore(t), instanceStore, store.NewFakeAdminConfigStore(t), nil)
+		sch.handOffStore = notifier.NewFakeKVStore(t)
+		rule := models.AlertRuleGen()()
+
+		require.True(t, sch.takeHandOff(context.Background(), rule))
+		require.Zero(t, countWarmed(instanceStore))
+	})
+
+	t.Run("warms the rule once the previous owner acknowledged the hand-off", func(t *testing.T) {
+		instanceStore := &store.FakeInstanceStore{}
+		sch, mockedClock := setupScheduler(t, store.NewFakeRuleStore(t), instanceStore, store.NewFakeAdminConfigStore(t), nil)
+		handOffStore := notifier.NewFakeKVStore(t)
+		sch.handOffStore = handOffStore
+		rule := models.AlertRuleGen()()
+		key := rule.GetKey()
+		sch.markAcquired(key, mockedClock.Now())
+
+		require.False(t, sch.takeHandOff(context.Background(), rule))
+		require.Zero(t, countWarmed(instanceStore))
+
+		mockedClock.Add(sch.baseInterval)
+		ack := strconv.FormatInt(mockedClock.Now().UnixNano(), 10)
+		require.NoError(t, handOffStore.Set(context.Background(), key.OrgID, handOffNamespace, key.UID, ack))
+		require.True(t, sch.takeHandOff(context.Background(), rule))
+		require.Equal(t, 1, countWarmed(instanceStore))
+		require.False(t, sch.isAcquired(key))
+		_, found, err := handOffStore.Get(context.Background(), key.OrgID, handOffNamespace, key.UID)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("ignores acknowledgements of previous hand-offs", func(t *testing.T) {
+		instanceStore := &store.FakeInstanceStore{}
+		sch, mockedClock := setupScheduler(t, store.NewFakeRuleStore(t), instanceStore, store.NewFakeAdminConfigStore(t), nil)
+		handOffStore := notifier.NewFakeKVStore(t)
+		sch.handOffStore = handOffStore
+		rule := models.AlertRuleGen()()
+		key := rule.GetKey()
+		ack := strconv.FormatInt(mockedClock.Now().UnixNano(), 10)
+		require.NoError(t, handOffStore.Set(context.Background(), key.OrgID, handOffNamespace, key.UID, ack))
+
+		mockedClock.Add(time.Hour)
+		sch.markAcquired(key, mockedClock.Now())
+		require.False(t, sch.takeHandOff(context.Background(), rule))
+		require.Zero(t, countWarmed(instanceStore))
+	})
+
+	t.Run("warms the rule without an acknowledgement after the timeout", func(t *testing.T) {
+		instanceStore := &store.FakeInstanceStore{}
+		sch, mockedClock := setupScheduler(t, store.NewFakeRuleStore(t), instanceStore, store.NewFakeAdminConfigStore(t), nil)
+		sch.handOffStore = notifier.NewFakeKVStore(t)
+		rule := models.AlertRuleGen()()
+		sch.markAcquired(rule.GetKey(), mockedClock.Now())
+
+		mockedClock.Add(handOffTimeoutIntervals*sch.baseInterval - time.Second)
+		require.False(t, sch.takeHandOff(context.Background(), rule))
+		mockedClock.Add(time.Second)
+		require.True(t, sch.takeHandOff(context.Background(), rule))
+		require.Equal(t, 1, countWarmed(instanceStore))
+		require.False(t, sch.isAcquired(rule.GetKey()))
+	})
+}
+
+type fakeClusterMembership struct {
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) Self() string      { return f.self }
+func (f *fakeClusterMembership) Members() []string { return f.members }
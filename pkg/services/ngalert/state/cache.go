@@ -170,11 +170,12 @@ func (c *cache) recordMetrics() {
 	// Set default values to zero such that gauges are reset
 	// after all values from a single state disappear.
 	ct := map[eval.State]int{
-		eval.Normal:   0,
-		eval.Alerting: 0,
-		eval.Pending:  0,
-		eval.NoData:   0,
-		eval.Error:    0,
+		eval.Normal:           0,
+		eval.Alerting:         0,
+		eval.Pending:          0,
+		eval.NoData:           0,
+		eval.Error:            0,
+		eval.DependencyFailed: 0,
 	}
 
 	for org, orgMap := range c.states {
@@ -3,12 +3,15 @@ package state
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -22,6 +25,9 @@ import (
 
 var ResendDelay = 30 * time.Second
 
+// HistoryPruneInterval is how often the state history older than the retention is deleted.
+var HistoryPruneInterval = time.Hour
+
 // AlertInstanceManager defines the interface for querying the current alert instances.
 type AlertInstanceManager interface {
 	GetAll(orgID int64) []*State
@@ -35,28 +41,49 @@ type Manager struct {
 	cache       *cache
 	quit        chan struct{}
 	ResendDelay time.Duration
+	clock       clock.Clock
+	// dryRun disables the access to the database: no annotation, state history or alert instance is
+	// written, and the dependencies of the rules are not evaluated.
+	dryRun bool
 
 	ruleStore     store.RuleStore
 	instanceStore store.InstanceStore
+	historyStore  store.StateHistoryStore
 	sqlStore      sqlstore.Store
 }
 
 func NewManager(logger log.Logger, metrics *metrics.State, externalURL *url.URL, ruleStore store.RuleStore,
-	instanceStore store.InstanceStore, sqlStore sqlstore.Store) *Manager {
+	instanceStore store.InstanceStore, historyStore store.StateHistoryStore, sqlStore sqlstore.Store) *Manager {
 	manager := &Manager{
 		cache:         newCache(logger, metrics, externalURL),
 		quit:          make(chan struct{}),
 		ResendDelay:   ResendDelay, // TODO: make this configurable
+		clock:         clock.New(),
 		log:           logger,
 		metrics:       metrics,
 		ruleStore:     ruleStore,
 		instanceStore: instanceStore,
+		historyStore:  historyStore,
 		sqlStore:      sqlStore,
 	}
 	go manager.recordMetrics()
 	return manager
 }
 
+// NewDryRunManager creates a Manager that computes the state of the alert instances without any side effect.
+// Nothing is persisted, annotated or exposed as metrics, and the clock is used to find the stale states, so
+// that past evaluations can be replayed with a mock clock.
+func NewDryRunManager(logger log.Logger, externalURL *url.URL, clk clock.Clock) *Manager {
+	return &Manager{
+		cache:       newCache(logger, nil, externalURL),
+		quit:        make(chan struct{}),
+		ResendDelay: ResendDelay,
+		clock:       clk,
+		dryRun:      true,
+		log:         logger,
+	}
+}
+
 func (st *Manager) Close() {
 	st.quit <- struct{}{}
 }
@@ -100,24 +127,7 @@ func (st *Manager) Warm(ctx context.Context) {
 				continue
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheId:              cacheId,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+			states = append(states, st.stateFromInstance(ruleForEntry, entry))
 		}
 	}
 
@@ -126,6 +136,41 @@ func (st *Manager) Warm(ctx context.Context) {
 	}
 }
 
+// WarmRule loads the state of the alert instances of a single rule from the database. It is used
+// when the evaluation of the rule is handed off by another instance of the cluster.
+func (st *Manager) WarmRule(ctx context.Context, rule *ngModels.AlertRule) {
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		st.log.Error("unable to fetch previous state", "uid", rule.UID, "org", rule.OrgID, "msg", err.Error())
+		return
+	}
+	for _, entry := range cmd.Result {
+		st.set(st.stateFromInstance(rule, entry))
+	}
+}
+
+func (st *Manager) stateFromInstance(rule *ngModels.AlertRule, entry *ngModels.ListAlertInstancesQueryResult) *State {
+	cacheId, err := entry.Labels.StringKey()
+	if err != nil {
+		st.log.Error("error getting cacheId for entry", "msg", err.Error())
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheId:              cacheId,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+	}
+}
+
 func (st *Manager) getOrCreate(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result) *State {
 	return st.cache.getOrCreate(ctx, alertRule, result)
 }
@@ -150,6 +195,9 @@ func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 
 func (st *Manager) ProcessEvalResults(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results) []*State {
 	st.log.Debug("state manager processing evaluation results", "uid", alertRule.UID, "resultCount", len(results))
+	if len(alertRule.Dependencies) > 0 && !st.dryRun && st.dependencyFailed(ctx, alertRule) {
+		results = applyDependencyAction(alertRule, results)
+	}
 	var states []*State
 	processedResults := make(map[string]*State, len(results))
 	for _, result := range results {
@@ -186,20 +234,104 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.resultError(alertRule, result)
 	case eval.NoData:
 		currentState.resultNoData(alertRule, result)
+	case eval.DependencyFailed:
+		currentState.resultDependencyFailed(alertRule, result)
 	case eval.Pending: // we do not emit results with this state
 	}
 
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
-	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
+	currentState.Resolved = oldState == eval.Alerting && (currentState.State == eval.Normal || currentState.State == eval.DependencyFailed)
 
 	st.set(currentState)
-	if oldState != currentState.State {
+	if oldState != currentState.State && !st.dryRun {
 		go st.annotateState(ctx, alertRule, currentState.Labels, result.EvaluatedAt, currentState.State, oldState)
+		go st.recordStateHistory(ctx, alertRule, currentState.Labels, result.EvaluatedAt, currentState.State, oldState, result.Values)
 	}
 	return currentState
 }
 
+// dependencyFailed returns true if one of the alert instances the rule depends on is firing. The alert
+// instances are read from the database, so the rules they belong to can be evaluated by any instance of the
+// cluster. The firing instances of all the dependencies are read at once.
+func (st *Manager) dependencyFailed(ctx context.Context, alertRule *ngModels.AlertRule) bool {
+	type dependency struct {
+		ruleUID  string
+		matchers labels.Matchers
+	}
+	dependencies := make([]dependency, 0, len(alertRule.Dependencies))
+	ruleUIDs := make([]string, 0, len(alertRule.Dependencies))
+	anyRule := false
+	for _, d := range alertRule.Dependencies {
+		matchers, err := d.ParseMatchers()
+		if err != nil {
+			st.log.Error("invalid dependency of alert rule", "uid", alertRule.UID, "err", err)
+			continue
+		}
+		dependencies = append(dependencies, dependency{ruleUID: d.RuleUID, matchers: matchers})
+		if d.RuleUID == "" {
+			anyRule = true
+		} else {
+			ruleUIDs = append(ruleUIDs, d.RuleUID)
+		}
+	}
+	if len(dependencies) == 0 {
+		return false
+	}
+
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: alertRule.OrgID,
+		State:     ngModels.InstanceStateFiring,
+	}
+	if !anyRule {
+		cmd.RuleUIDs = ruleUIDs
+	}
+	if err := st.instanceStore.ListAlertInstances(ctx, &cmd); err != nil {
+		st.log.Error("unable to fetch the alert instances of the dependencies", "uid", alertRule.UID, "err", err)
+		return false
+	}
+	for _, instance := range cmd.Result {
+		if instance.RuleUID == alertRule.UID {
+			continue
+		}
+		for _, d := range dependencies {
+			if d.ruleUID != "" && d.ruleUID != instance.RuleUID {
+				continue
+			}
+			if matchesAll(d.matchers, instance.Labels) {
+				st.log.Debug("dependency of alert rule is firing", "uid", alertRule.UID, "dependency", instance.RuleUID, "labels", instance.Labels)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesAll(matchers labels.Matchers, lbs ngModels.InstanceLabels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbs[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyDependencyAction returns the results the rule produces while one of its dependencies is failing.
+func applyDependencyAction(alertRule *ngModels.AlertRule, results eval.Results) eval.Results {
+	applied := make(eval.Results, 0, len(results))
+	for _, result := range results {
+		switch alertRule.DependencyAction {
+		case ngModels.FailDependencyAction:
+			result.State = eval.DependencyFailed
+		default:
+			result.State = eval.Normal
+		}
+		result.Error = nil
+		applied = append(applied, result)
+	}
+	return applied
+}
+
 func (st *Manager) GetAll(orgID int64) []*State {
 	return st.cache.getAll(orgID)
 }
@@ -238,6 +370,8 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 		return eval.Alerting
 	case state == ngModels.InstanceStateNormal:
 		return eval.Normal
+	case state == ngModels.InstanceStateDependencyFailed:
+		return eval.DependencyFailed
 	default:
 		return eval.Error
 	}
@@ -290,13 +424,60 @@ func (st *Manager) annotateState(ctx context.Context, alertRule *ngModels.AlertR
 	}
 }
 
+// recordStateHistory saves the transition of an alert instance to the state history.
+func (st *Manager) recordStateHistory(ctx context.Context, alertRule *ngModels.AlertRule, labels data.Labels, evaluatedAt time.Time, state eval.State, previousState eval.State, values map[string]eval.NumberValueCapture) {
+	if st.historyStore == nil {
+		return
+	}
+
+	entry := &ngModels.AlertStateHistoryEntry{
+		OrgID:         alertRule.OrgID,
+		RuleUID:       alertRule.UID,
+		NamespaceUID:  alertRule.NamespaceUID,
+		Labels:        ngModels.InstanceLabels(removePrivateLabels(labels)),
+		PreviousState: ngModels.InstanceStateType(previousState.String()),
+		State:         ngModels.InstanceStateType(state.String()),
+		EvaluatedAt:   evaluatedAt,
+	}
+	for k, v := range values {
+		if v.Value == nil || math.IsNaN(*v.Value) || math.IsInf(*v.Value, 0) {
+			continue
+		}
+		if entry.Values == nil {
+			entry.Values = make(map[string]float64, len(values))
+		}
+		entry.Values[k] = *v.Value
+	}
+
+	if err := st.historyStore.SaveStateHistory(ctx, []*ngModels.AlertStateHistoryEntry{entry}); err != nil {
+		st.log.Error("error saving alert state history", "alertRuleUID", alertRule.UID, "error", err.Error())
+	}
+}
+
+// PruneStateHistory deletes the state history older than the retention.
+func (st *Manager) PruneStateHistory(ctx context.Context, retention time.Duration) {
+	if st.historyStore == nil || retention <= 0 {
+		return
+	}
+
+	deleted, err := st.historyStore.DeleteStateHistoryBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		st.log.Error("unable to prune the alert state history", "error", err.Error())
+		return
+	}
+	st.log.Debug("pruned the alert state history", "deleted", deleted)
+}
+
 func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.AlertRule, states map[string]*State) {
 	allStates := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
 	for _, s := range allStates {
 		_, ok := states[s.CacheId]
-		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds) {
+		if !ok && isItStale(st.clock.Now(), s.LastEvaluationTime, alertRule.IntervalSeconds) {
 			st.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			st.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
+			if st.dryRun {
+				continue
+			}
 			ilbs := ngModels.InstanceLabels(s.Labels)
 			_, labelsHash, err := ilbs.StringAndHash()
 			if err != nil {
@@ -308,14 +489,16 @@ func (st *Manager) staleResultsHandler(ctx context.Context, alertRule *ngModels.
 			}
 
 			if s.State == eval.Alerting {
-				st.annotateState(ctx, alertRule, s.Labels, time.Now(), eval.Normal, s.State)
+				now := st.clock.Now()
+				st.annotateState(ctx, alertRule, s.Labels, now, eval.Normal, s.State)
+				st.recordStateHistory(ctx, alertRule, s.Labels, now, eval.Normal, s.State, nil)
 			}
 		}
 	}
 }
 
-func isItStale(lastEval time.Time, intervalSeconds int64) bool {
-	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(time.Now())
+func isItStale(now time.Time, lastEval time.Time, intervalSeconds int64) bool {
+	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(now)
 }
 
 func removePrivateLabels(labels data.Labels) data.Labels {
@@ -37,7 +37,7 @@ func TestDashboardAnnotations(t *testing.T) {
 	_, dbstore := tests.SetupTestEnv(t, 1)
 
 	sqlStore := mockstore.NewSQLStoreMock()
-	st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, sqlStore)
+	st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, sqlStore)
 
 	fakeAnnoRepo := store.NewFakeAnnotationsRepo()
 	annotations.SetRepository(fakeAnnoRepo)
@@ -76,6 +76,134 @@ func TestDashboardAnnotations(t *testing.T) {
 	}, time.Second, 100*time.Millisecond, "unexpected annotations")
 }
 
+func TestStateHistory(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2022-01-01")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	st := state.NewManager(log.New("test_state_history"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, mockstore.NewSQLStoreMock())
+	annotations.SetRepository(store.NewFakeAnnotationsRepo())
+
+	const mainOrgID int64 = 1
+
+	rule := tests.CreateTestAlertRuleWithLabels(t, ctx, dbstore, 600, mainOrgID, map[string]string{"team": "ops"})
+
+	value := 3.0
+	results := []eval.Results{
+		{{Instance: data.Labels{"instance": "a"}, State: eval.Normal, EvaluatedAt: evaluationTime}},
+		{{Instance: data.Labels{"instance": "a"}, State: eval.Normal, EvaluatedAt: evaluationTime.Add(time.Minute)}},
+		{{
+			Instance:    data.Labels{"instance": "a"},
+			State:       eval.Alerting,
+			EvaluatedAt: evaluationTime.Add(2 * time.Minute),
+			Values:      map[string]eval.NumberValueCapture{"B": {Var: "B", Value: &value}},
+		}},
+	}
+	for _, res := range results {
+		_ = st.ProcessEvalResults(ctx, rule, res)
+	}
+
+	var history []*models.AlertStateHistoryEntry
+	require.Eventually(t, func() bool {
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID, RuleUID: rule.UID}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		history = q.Result
+		return len(history) == 1
+	}, time.Second, 100*time.Millisecond, "the transition to alerting is not recorded")
+
+	require.Equal(t, models.InstanceStateNormal, history[0].PreviousState)
+	require.Equal(t, models.InstanceStateFiring, history[0].State)
+	require.Equal(t, evaluationTime.Add(2*time.Minute), history[0].EvaluatedAt.UTC())
+	require.Equal(t, map[string]float64{"B": 3}, history[0].Values)
+	require.Equal(t, models.InstanceLabels{"alertname": rule.Title, "instance": "a", "team": "ops"}, history[0].Labels)
+}
+
+func TestDependencies(t *testing.T) {
+	evaluationTime, err := time.Parse("2006-01-02", "2022-01-01")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	st := state.NewManager(log.New("test_dependencies"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, mockstore.NewSQLStoreMock())
+	fakeAnnoRepo := store.NewFakeAnnotationsRepo()
+	annotations.SetRepository(fakeAnnoRepo)
+
+	const mainOrgID int64 = 1
+
+	upstream := tests.CreateTestAlertRuleWithLabels(t, ctx, dbstore, 600, mainOrgID, nil)
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, &models.SaveAlertInstanceCommand{
+		RuleOrgID:         upstream.OrgID,
+		RuleUID:           upstream.UID,
+		Labels:            models.InstanceLabels{"cluster": "eu-1"},
+		State:             models.InstanceStateFiring,
+		LastEvalTime:      evaluationTime,
+		CurrentStateSince: evaluationTime,
+		CurrentStateEnd:   evaluationTime.Add(time.Minute),
+	}))
+
+	testCases := []struct {
+		desc          string
+		dependencies  []models.AlertRuleDependency
+		action        models.DependencyAction
+		expectedState eval.State
+	}{
+		{
+			desc:          "suppressed while the dependency rule is firing",
+			dependencies:  []models.AlertRuleDependency{{RuleUID: upstream.UID}},
+			action:        models.SuppressDependencyAction,
+			expectedState: eval.Normal,
+		},
+		{
+			desc:          "failed while the dependency rule is firing",
+			dependencies:  []models.AlertRuleDependency{{RuleUID: upstream.UID}},
+			action:        models.FailDependencyAction,
+			expectedState: eval.DependencyFailed,
+		},
+		{
+			desc:          "failed while an instance matching the dependency is firing",
+			dependencies:  []models.AlertRuleDependency{{Matchers: []string{`cluster=~"eu-.*"`}}},
+			action:        models.FailDependencyAction,
+			expectedState: eval.DependencyFailed,
+		},
+		{
+			desc:          "failed while one of the dependency rules is firing",
+			dependencies:  []models.AlertRuleDependency{{RuleUID: "unknown"}, {RuleUID: upstream.UID}},
+			action:        models.FailDependencyAction,
+			expectedState: eval.DependencyFailed,
+		},
+		{
+			desc:          "alerting while no instance matching the dependency is firing",
+			dependencies:  []models.AlertRuleDependency{{RuleUID: upstream.UID, Matchers: []string{`cluster="us-1"`}}},
+			action:        models.SuppressDependencyAction,
+			expectedState: eval.Alerting,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rule := tests.CreateTestAlertRuleWithLabels(t, ctx, dbstore, 600, mainOrgID, nil)
+			rule.Dependencies = tc.dependencies
+			rule.DependencyAction = tc.action
+
+			states := st.ProcessEvalResults(ctx, rule, eval.Results{{
+				Instance:    data.Labels{"cluster": "eu-1"},
+				State:       eval.Alerting,
+				EvaluatedAt: evaluationTime,
+			}})
+			require.Len(t, states, 1)
+			require.Equal(t, tc.expectedState, states[0].State)
+		})
+	}
+
+	// Every transition but the suppressed one is annotated.
+	require.Eventuallyf(t, func() bool {
+		return fakeAnnoRepo.Len() == 4
+	}, time.Second, 100*time.Millisecond, "only %d annotations are present", fakeAnnoRepo.Len())
+}
+
 func TestProcessEvalResults(t *testing.T) {
 	evaluationTime, err := time.Parse("2006-01-02", "2021-03-25")
 	if err != nil {
@@ -1530,7 +1658,7 @@ func TestProcessEvalResults(t *testing.T) {
 
 	for _, tc := range testCases {
 		ss := mockstore.NewSQLStoreMock()
-		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, &store.FakeInstanceStore{}, ss)
+		st := state.NewManager(log.New("test_state_manager"), testMetrics.GetStateMetrics(), nil, nil, &store.FakeInstanceStore{}, nil, ss)
 		t.Run(tc.desc, func(t *testing.T) {
 			fakeAnnoRepo := store.NewFakeAnnotationsRepo()
 			annotations.SetRepository(fakeAnnoRepo)
@@ -1640,7 +1768,7 @@ func TestStaleResultsHandler(t *testing.T) {
 	for _, tc := range testCases {
 		ctx := context.Background()
 		sqlStore := mockstore.NewSQLStoreMock()
-		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, sqlStore)
+		st := state.NewManager(log.New("test_stale_results_handler"), testMetrics.GetStateMetrics(), nil, dbstore, dbstore, dbstore, sqlStore)
 		st.Warm(ctx)
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
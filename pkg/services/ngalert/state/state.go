@@ -81,6 +81,16 @@ func (a *State) resultAlerting(alertRule *ngModels.AlertRule, result eval.Result
 	}
 }
 
+func (a *State) resultDependencyFailed(alertRule *ngModels.AlertRule, result eval.Result) {
+	a.Error = nil
+
+	if a.State != eval.DependencyFailed {
+		a.EndsAt = result.EvaluatedAt
+		a.StartsAt = result.EvaluatedAt
+	}
+	a.State = eval.DependencyFailed
+}
+
 func (a *State) resultError(alertRule *ngModels.AlertRule, result eval.Result) {
 	a.Error = result.Error
 
@@ -131,7 +141,7 @@ func (a *State) resultNoData(alertRule *ngModels.AlertRule, result eval.Result)
 }
 
 func (a *State) NeedsSending(resendDelay time.Duration) bool {
-	if a.State == eval.Pending || a.State == eval.Normal && !a.Resolved {
+	if a.State == eval.Pending || (a.State == eval.Normal || a.State == eval.DependencyFailed) && !a.Resolved {
 		return false
 	}
 	// if LastSentAt is before or equal to LastEvaluationTime + resendDelay, send again
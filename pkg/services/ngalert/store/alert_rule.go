@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
+
 	"github.com/grafana/grafana/pkg/services/guardian"
 
 	"github.com/grafana/grafana/pkg/models"
@@ -46,6 +48,7 @@ type RuleStore interface {
 	GetRuleGroupAlertRules(ctx context.Context, query *ngmodels.ListRuleGroupAlertRulesQuery) error
 	GetNamespaces(context.Context, int64, *models.SignedInUser) (map[string]*models.Folder, error)
 	GetNamespaceByTitle(context.Context, string, int64, *models.SignedInUser, bool) (*models.Folder, error)
+	GetNamespaceByUID(context.Context, string, int64, *models.SignedInUser, bool) (*models.Folder, error)
 	GetOrgRuleGroups(ctx context.Context, query *ngmodels.ListOrgRuleGroupsQuery) error
 	UpsertAlertRules(ctx context.Context, rule []UpsertRule) error
 	InTransaction(ctx context.Context, f func(ctx context.Context) error) error
@@ -189,11 +192,14 @@ func (st DBstore) UpsertAlertRules(ctx context.Context, rules []UpsertRule) erro
 			var parentVersion int64
 			switch r.Existing {
 			case nil: // new rule
-				uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
-				if err != nil {
-					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+				// provisioned rules keep their uid
+				if r.New.UID == "" {
+					uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
+					if err != nil {
+						return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
+					}
+					r.New.UID = uid
 				}
-				r.New.UID = uid
 				r.New.Version = 1
 
 				if err := st.validateAlertRule(r.New); err != nil {
@@ -243,6 +249,9 @@ func (st DBstore) UpsertAlertRules(ctx context.Context, rules []UpsertRule) erro
 				For:              r.New.For,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
+				RecordMetric:     r.New.RecordMetric,
+				Dependencies:     r.New.Dependencies,
+				DependencyAction: r.New.DependencyAction,
 			})
 		}
 
@@ -384,6 +393,26 @@ func (st DBstore) GetNamespaceByTitle(ctx context.Context, namespace string, org
 	return folder, nil
 }
 
+// GetNamespaceByUID is a handler for retrieving a namespace by its UID. Alerting rules follow a Grafana folder-like structure which we call namespaces.
+func (st DBstore) GetNamespaceByUID(ctx context.Context, uid string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
+	folder, err := st.FolderService.GetFolderByUID(ctx, user, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if withCanSave {
+		g := guardian.New(ctx, folder.Id, orgID, user)
+		if canSave, err := g.CanSave(); err != nil || !canSave {
+			if err != nil {
+				st.Logger.Error("checking can save permission has failed", "userId", user.UserId, "username", user.Login, "namespace", uid, "orgId", orgID, "error", err)
+			}
+			return nil, ngmodels.ErrCannotEditNamespace
+		}
+	}
+
+	return folder, nil
+}
+
 // GetAlertRulesForScheduling returns alert rule info (identifier, interval, version state)
 // that is useful for it's scheduling.
 func (st DBstore) GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.ListAlertRulesQuery) error {
@@ -453,6 +482,24 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: cannot have Panel ID without a Dashboard UID", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.IsRecordingRule() && !model.IsValidMetricName(model.LabelValue(alertRule.RecordMetric)) {
+		return fmt.Errorf("%w: invalid metric name of the recording rule: %q", ngmodels.ErrAlertRuleFailedValidation, alertRule.RecordMetric)
+	}
+
+	for _, d := range alertRule.Dependencies {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+		}
+		if d.RuleUID != "" && d.RuleUID == alertRule.UID {
+			return fmt.Errorf("%w: alert rule cannot depend on itself", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+	if len(alertRule.Dependencies) > 0 {
+		if _, err := ngmodels.DependencyActionFromString(string(alertRule.DependencyAction)); err != nil {
+			return fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+		}
+	}
+
 	return nil
 }
 
@@ -70,6 +70,13 @@ func (st DBstore) ListAlertInstances(ctx context.Context, cmd *models.ListAlertI
 			addToQuery(` AND rule_uid = ?`, cmd.RuleUID)
 		}
 
+		if len(cmd.RuleUIDs) > 0 {
+			addToQuery(` AND rule_uid IN (?` + strings.Repeat(`, ?`, len(cmd.RuleUIDs)-1) + `)`)
+			for _, uid := range cmd.RuleUIDs {
+				params = append(params, uid)
+			}
+		}
+
 		if cmd.State != "" {
 			addToQuery(` AND current_state = ?`, cmd.State)
 		}
@@ -0,0 +1,326 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// NotificationLogStore is the database interface of the notification attempts of the integrations
+// of contact points, and of the queue of failed notifications that are sent again.
+type NotificationLogStore interface {
+	SaveNotificationLog(ctx context.Context, entry *models.NotificationLogEntry) error
+	GetNotificationLog(ctx context.Context, query *models.GetNotificationLogQuery) error
+	DeleteNotificationLogBefore(ctx context.Context, before time.Time) (int64, error)
+
+	SaveNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error
+	GetNotificationRetries(ctx context.Context, query *models.GetNotificationRetriesQuery) error
+	ClaimNotificationRetry(ctx context.Context, retry *models.NotificationRetry, until time.Time) (bool, error)
+	DeleteNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error
+}
+
+// notificationLogRow is the alert_notification_log table, the payload summary is stored as json
+// and the times as unix milliseconds.
+type notificationLogRow struct {
+	ID              int64  `xorm:"pk autoincr 'id'"`
+	OrgID           int64  `xorm:"org_id"`
+	Receiver        string `xorm:"receiver"`
+	IntegrationUID  string `xorm:"integration_uid"`
+	IntegrationName string `xorm:"integration_name"`
+	IntegrationType string `xorm:"integration_type"`
+	GroupKey        string `xorm:"group_key"`
+	Payload         string `xorm:"payload"`
+	Status          string `xorm:"status"`
+	Error           string `xorm:"error"`
+	Attempt         int    `xorm:"attempt"`
+	DurationMs      int64  `xorm:"duration_ms"`
+	SentAt          int64  `xorm:"sent_at"`
+}
+
+// notificationRetryRow is the alert_notification_retry table, the group labels and the alerts are
+// stored as json and the times as unix milliseconds.
+type notificationRetryRow struct {
+	ID             int64  `xorm:"pk autoincr 'id'"`
+	OrgID          int64  `xorm:"org_id"`
+	Receiver       string `xorm:"receiver"`
+	IntegrationUID string `xorm:"integration_uid"`
+	GroupKey       string `xorm:"group_key"`
+	GroupLabels    string `xorm:"group_labels"`
+	Alerts         string `xorm:"alerts"`
+	Attempts       int    `xorm:"attempts"`
+	LastError      string `xorm:"last_error"`
+	NextAttemptAt  int64  `xorm:"next_attempt_at"`
+	CreatedAt      int64  `xorm:"created_at"`
+}
+
+func toUnixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fromUnixMilli(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// SaveNotificationLog is a handler for saving a notification attempt.
+func (st DBstore) SaveNotificationLog(ctx context.Context, entry *models.NotificationLogEntry) error {
+	payload, err := json.Marshal(entry.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode the payload summary: %w", err)
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		row := notificationLogRow{
+			OrgID:           entry.OrgID,
+			Receiver:        entry.Receiver,
+			IntegrationUID:  entry.IntegrationUID,
+			IntegrationName: entry.IntegrationName,
+			IntegrationType: entry.IntegrationType,
+			GroupKey:        entry.GroupKey,
+			Payload:         string(payload),
+			Status:          string(entry.Status),
+			Error:           entry.Error,
+			Attempt:         entry.Attempt,
+			DurationMs:      entry.Duration.Milliseconds(),
+			SentAt:          toUnixMilli(entry.SentAt),
+		}
+		if _, err := sess.Table("alert_notification_log").Insert(&row); err != nil {
+			return err
+		}
+		entry.ID = row.ID
+		return nil
+	})
+}
+
+// GetNotificationLog is a handler for retrieving the notification attempts of an organisation
+// based on various filters. The attempts are sorted by time.
+func (st DBstore) GetNotificationLog(ctx context.Context, query *models.GetNotificationLogQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_notification_log WHERE org_id = ?", query.OrgID)
+
+		if query.Receiver != "" {
+			addToQuery(" AND receiver = ?", query.Receiver)
+		}
+
+		if query.IntegrationUID != "" {
+			addToQuery(" AND integration_uid = ?", query.IntegrationUID)
+		}
+
+		if query.Status != "" {
+			addToQuery(" AND status = ?", string(query.Status))
+		}
+
+		if !query.From.IsZero() {
+			addToQuery(" AND sent_at >= ?", toUnixMilli(query.From))
+		}
+
+		if !query.To.IsZero() {
+			addToQuery(" AND sent_at <= ?", toUnixMilli(query.To))
+		}
+
+		addToQuery(" ORDER BY sent_at DESC, id DESC")
+
+		if query.Limit > 0 {
+			addToQuery(" LIMIT ?", query.Limit)
+		}
+
+		rows := make([]notificationLogRow, 0)
+		if err := sess.SQL(s.String(), params...).Find(&rows); err != nil {
+			return err
+		}
+
+		// the most recent attempts are kept when a limit is set, return them in chronological order
+		result := make([]*models.NotificationLogEntry, 0, len(rows))
+		for i := len(rows) - 1; i >= 0; i-- {
+			row := rows[i]
+			entry := &models.NotificationLogEntry{
+				ID:              row.ID,
+				OrgID:           row.OrgID,
+				Receiver:        row.Receiver,
+				IntegrationUID:  row.IntegrationUID,
+				IntegrationName: row.IntegrationName,
+				IntegrationType: row.IntegrationType,
+				GroupKey:        row.GroupKey,
+				Status:          models.NotificationStatus(row.Status),
+				Error:           row.Error,
+				Attempt:         row.Attempt,
+				Duration:        time.Duration(row.DurationMs) * time.Millisecond,
+				SentAt:          fromUnixMilli(row.SentAt),
+			}
+			if err := json.Unmarshal([]byte(row.Payload), &entry.Payload); err != nil {
+				return fmt.Errorf("failed to decode the payload summary: %w", err)
+			}
+			result = append(result, entry)
+		}
+
+		query.Result = result
+		return nil
+	})
+}
+
+// DeleteNotificationLogBefore deletes the notification attempts sent before the given time,
+// and returns the number of deleted attempts.
+func (st DBstore) DeleteNotificationLogBefore(ctx context.Context, before time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_notification_log WHERE sent_at < ?", toUnixMilli(before))
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
+
+// SaveNotificationRetry is a handler for queueing a failed notification. It replaces the pending
+// retry of the same integration and group of alerts. When the pending retry has more attempts, for
+// instance when the group is notified again while its retry is pending, its attempts and the time
+// of its next attempt are kept, so that the maximum number of attempts is still reached.
+func (st DBstore) SaveNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error {
+	groupLabels, err := json.Marshal(retry.GroupLabels)
+	if err != nil {
+		return fmt.Errorf("failed to encode the group labels: %w", err)
+	}
+	alerts, err := json.Marshal(retry.Alerts)
+	if err != nil {
+		return fmt.Errorf("failed to encode the alerts: %w", err)
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		pending := notificationRetryRow{}
+		has, err := sess.Table("alert_notification_retry").Where("org_id = ? AND receiver = ? AND integration_uid = ? AND group_key = ?",
+			retry.OrgID, retry.Receiver, retry.IntegrationUID, retry.GroupKey).Get(&pending)
+		if err != nil {
+			return err
+		}
+		if has && pending.Attempts > retry.Attempts {
+			retry.Attempts = pending.Attempts
+			retry.NextAttemptAt = fromUnixMilli(pending.NextAttemptAt)
+			retry.CreatedAt = fromUnixMilli(pending.CreatedAt)
+		}
+
+		if err := deleteNotificationRetry(sess, retry); err != nil {
+			return err
+		}
+		row := notificationRetryRow{
+			OrgID:          retry.OrgID,
+			Receiver:       retry.Receiver,
+			IntegrationUID: retry.IntegrationUID,
+			GroupKey:       retry.GroupKey,
+			GroupLabels:    string(groupLabels),
+			Alerts:         string(alerts),
+			Attempts:       retry.Attempts,
+			LastError:      retry.LastError,
+			NextAttemptAt:  toUnixMilli(retry.NextAttemptAt),
+			CreatedAt:      toUnixMilli(retry.CreatedAt),
+		}
+		if _, err := sess.Table("alert_notification_retry").Insert(&row); err != nil {
+			return err
+		}
+		retry.ID = row.ID
+		return nil
+	})
+}
+
+// GetNotificationRetries is a handler for retrieving the pending retries of an organisation,
+// sorted by the time of their next attempt.
+func (st DBstore) GetNotificationRetries(ctx context.Context, query *models.GetNotificationRetriesQuery) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT * FROM alert_notification_retry WHERE org_id = ?", query.OrgID)
+
+		if !query.DueBefore.IsZero() {
+			addToQuery(" AND next_attempt_at <= ?", toUnixMilli(query.DueBefore))
+		}
+
+		addToQuery(" ORDER BY next_attempt_at ASC, id ASC")
+
+		if query.Limit > 0 {
+			addToQuery(" LIMIT ?", query.Limit)
+		}
+
+		rows := make([]notificationRetryRow, 0)
+		if err := sess.SQL(s.String(), params...).Find(&rows); err != nil {
+			return err
+		}
+
+		result := make([]*models.NotificationRetry, 0, len(rows))
+		for _, row := range rows {
+			retry := &models.NotificationRetry{
+				ID:             row.ID,
+				OrgID:          row.OrgID,
+				Receiver:       row.Receiver,
+				IntegrationUID: row.IntegrationUID,
+				GroupKey:       row.GroupKey,
+				Attempts:       row.Attempts,
+				LastError:      row.LastError,
+				NextAttemptAt:  fromUnixMilli(row.NextAttemptAt),
+				CreatedAt:      fromUnixMilli(row.CreatedAt),
+			}
+			if err := json.Unmarshal([]byte(row.GroupLabels), &retry.GroupLabels); err != nil {
+				return fmt.Errorf("failed to decode the group labels: %w", err)
+			}
+			if err := json.Unmarshal([]byte(row.Alerts), &retry.Alerts); err != nil {
+				return fmt.Errorf("failed to decode the alerts: %w", err)
+			}
+			result = append(result, retry)
+		}
+
+		query.Result = result
+		return nil
+	})
+}
+
+// ClaimNotificationRetry postpones the next attempt of a retry to the given time, unless another
+// instance has claimed the retry in the meantime. It returns true if the retry was claimed.
+func (st DBstore) ClaimNotificationRetry(ctx context.Context, retry *models.NotificationRetry, until time.Time) (bool, error) {
+	var claimed bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("UPDATE alert_notification_retry SET next_attempt_at = ? WHERE id = ? AND next_attempt_at = ?",
+			toUnixMilli(until), retry.ID, toUnixMilli(retry.NextAttemptAt))
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		claimed = affected == 1
+		if claimed {
+			retry.NextAttemptAt = until
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+// DeleteNotificationRetry deletes the pending retry of an integration of a contact point and group of alerts.
+func (st DBstore) DeleteNotificationRetry(ctx context.Context, retry *models.NotificationRetry) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return deleteNotificationRetry(sess, retry)
+	})
+}
+
+func deleteNotificationRetry(sess *sqlstore.DBSession, retry *models.NotificationRetry) error {
+	_, err := sess.Exec("DELETE FROM alert_notification_retry WHERE org_id = ? AND receiver = ? AND integration_uid = ? AND group_key = ?",
+		retry.OrgID, retry.Receiver, retry.IntegrationUID, retry.GroupKey)
+	return err
+}
@@ -0,0 +1,164 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestNotificationLogOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	start := time.Unix(1000, 0)
+	entry := func(uid string, offset time.Duration, status models.NotificationStatus) *models.NotificationLogEntry {
+		return &models.NotificationLogEntry{
+			OrgID:           mainOrgID,
+			Receiver:        "ops",
+			IntegrationUID:  uid,
+			IntegrationName: uid,
+			IntegrationType: "webhook",
+			GroupKey:        "{}:{}",
+			Payload:         models.NotificationPayloadSummary{Firing: 1, AlertNames: []string{"HighCPU"}},
+			Status:          status,
+			Attempt:         1,
+			Duration:        250 * time.Millisecond,
+			SentAt:          start.Add(offset),
+		}
+	}
+	entries := []*models.NotificationLogEntry{
+		entry("slack", 0, models.NotificationStatusFailed),
+		entry("slack", time.Minute, models.NotificationStatusSuccess),
+		entry("email", 2*time.Minute, models.NotificationStatusSuccess),
+	}
+	entries[0].Error = "unexpected status code 503"
+	for _, e := range entries {
+		require.NoError(t, dbstore.SaveNotificationLog(ctx, e))
+		require.NotZero(t, e.ID)
+	}
+
+	t.Run("returns the attempts in chronological order", func(t *testing.T) {
+		q := &models.GetNotificationLogQuery{OrgID: mainOrgID, IntegrationUID: "slack"}
+		require.NoError(t, dbstore.GetNotificationLog(ctx, q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, start, q.Result[0].SentAt)
+		require.Equal(t, models.NotificationStatusFailed, q.Result[0].Status)
+		require.Equal(t, "unexpected status code 503", q.Result[0].Error)
+		require.Equal(t, 250*time.Millisecond, q.Result[0].Duration)
+		require.Equal(t, []string{"HighCPU"}, q.Result[0].Payload.AlertNames)
+	})
+
+	t.Run("filters the attempts by status and time", func(t *testing.T) {
+		q := &models.GetNotificationLogQuery{OrgID: mainOrgID, Status: models.NotificationStatusSuccess, From: start.Add(90 * time.Second)}
+		require.NoError(t, dbstore.GetNotificationLog(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "email", q.Result[0].IntegrationUID)
+	})
+
+	t.Run("keeps the most recent attempts", func(t *testing.T) {
+		q := &models.GetNotificationLogQuery{OrgID: mainOrgID, Limit: 2}
+		require.NoError(t, dbstore.GetNotificationLog(ctx, q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, start.Add(time.Minute), q.Result[0].SentAt)
+		require.Equal(t, start.Add(2*time.Minute), q.Result[1].SentAt)
+	})
+
+	t.Run("deletes the attempts older than the retention", func(t *testing.T) {
+		deleted, err := dbstore.DeleteNotificationLogBefore(ctx, start.Add(time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+	})
+}
+
+func TestNotificationRetryOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	now := time.Unix(1000, 0)
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "HighCPU"}, StartsAt: now}}
+	retry := func(uid string, attempts int, next time.Time) *models.NotificationRetry {
+		return &models.NotificationRetry{
+			OrgID:          mainOrgID,
+			Receiver:       "ops",
+			IntegrationUID: uid,
+			GroupKey:       "{}:{}",
+			GroupLabels:    map[string]string{"team": "ops"},
+			Alerts:         []*types.Alert{alert},
+			Attempts:       attempts,
+			LastError:      "unexpected status code 503",
+			NextAttemptAt:  next,
+			CreatedAt:      now,
+		}
+	}
+
+	require.NoError(t, dbstore.SaveNotificationRetry(ctx, retry("slack", 1, now.Add(time.Minute))))
+	require.NoError(t, dbstore.SaveNotificationRetry(ctx, retry("email", 1, now.Add(time.Hour))))
+
+	t.Run("replaces the pending retry of the same group", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveNotificationRetry(ctx, retry("slack", 2, now.Add(2*time.Minute))))
+
+		q := &models.GetNotificationRetriesQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetNotificationRetries(ctx, q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, "slack", q.Result[0].IntegrationUID)
+		require.Equal(t, 2, q.Result[0].Attempts)
+		require.Equal(t, map[string]string{"team": "ops"}, q.Result[0].GroupLabels)
+		require.Len(t, q.Result[0].Alerts, 1)
+		require.Equal(t, alert.Labels, q.Result[0].Alerts[0].Labels)
+	})
+
+	t.Run("keeps the attempts of the pending retry of the same group", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveNotificationRetry(ctx, retry("slack", 1, now.Add(time.Hour))))
+
+		q := &models.GetNotificationRetriesQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetNotificationRetries(ctx, q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, "slack", q.Result[0].IntegrationUID)
+		require.Equal(t, 2, q.Result[0].Attempts)
+		require.Equal(t, now.Add(2*time.Minute).UnixNano()/int64(time.Millisecond), q.Result[0].NextAttemptAt.UnixNano()/int64(time.Millisecond))
+	})
+
+	t.Run("returns the due retries", func(t *testing.T) {
+		q := &models.GetNotificationRetriesQuery{OrgID: mainOrgID, DueBefore: now.Add(10 * time.Minute)}
+		require.NoError(t, dbstore.GetNotificationRetries(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "slack", q.Result[0].IntegrationUID)
+	})
+
+	t.Run("claims a retry once", func(t *testing.T) {
+		q := &models.GetNotificationRetriesQuery{OrgID: mainOrgID, DueBefore: now.Add(10 * time.Minute)}
+		require.NoError(t, dbstore.GetNotificationRetries(ctx, q))
+		stale := *q.Result[0]
+
+		claimed, err := dbstore.ClaimNotificationRetry(ctx, q.Result[0], now.Add(time.Hour))
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = dbstore.ClaimNotificationRetry(ctx, &stale, now.Add(time.Hour))
+		require.NoError(t, err)
+		require.False(t, claimed)
+	})
+
+	t.Run("deletes a retry", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteNotificationRetry(ctx, retry("slack", 0, time.Time{})))
+
+		q := &models.GetNotificationRetriesQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetNotificationRetries(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, "email", q.Result[0].IntegrationUID)
+	})
+}
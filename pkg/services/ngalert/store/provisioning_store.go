@@ -23,8 +23,10 @@ func (pr provenanceRecord) TableName() string {
 // ProvisioningStore is a store of provisioning data for arbitrary objects.
 type ProvisioningStore interface {
 	GetProvenance(ctx context.Context, o models.Provisionable) (models.Provenance, error)
-	// TODO: API to query all provenances for a specific type?
+	// GetProvenances returns the provenances of the provisioned resources of a type, by resource id.
+	GetProvenances(ctx context.Context, orgID int64, resourceType string) (map[string]models.Provenance, error)
 	SetProvenance(ctx context.Context, o models.Provisionable, p models.Provenance) error
+	DeleteProvenance(ctx context.Context, o models.Provisionable) error
 }
 
 // GetProvenance gets the provenance status for a provisionable object.
@@ -52,6 +54,27 @@ func (st DBstore) GetProvenance(ctx context.Context, o models.Provisionable) (mo
 	return provenance, nil
 }
 
+// GetProvenances gets the provenance status of all provisioned objects of a type in an organization.
+func (st DBstore) GetProvenances(ctx context.Context, orgID int64, resourceType string) (map[string]models.Provenance, error) {
+	provenances := make(map[string]models.Provenance)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		var records []provenanceRecord
+		if err := sess.Table(provenanceRecord{}).Where("record_type = ? AND org_id = ?", resourceType, orgID).Find(&records); err != nil {
+			return fmt.Errorf("failed to query for existing provenance status: %w", err)
+		}
+		for _, r := range records {
+			if r.Provenance != models.ProvenanceNone {
+				provenances[r.RecordKey] = r.Provenance
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return provenances, nil
+}
+
 // SetProvenance changes the provenance status for a provisionable object.
 func (st DBstore) SetProvenance(ctx context.Context, o models.Provisionable, p models.Provenance) error {
 	recordType := o.ResourceType()
@@ -83,3 +106,14 @@ func (st DBstore) SetProvenance(ctx context.Context, o models.Provisionable, p m
 		return nil
 	})
 }
+
+// DeleteProvenance deletes the provenance status of a provisionable object, it is no longer provisioned.
+func (st DBstore) DeleteProvenance(ctx context.Context, o models.Provisionable) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		filter := "record_key = ? AND record_type = ? AND org_id = ?"
+		if _, err := sess.Table(provenanceRecord{}).Where(filter, o.ResourceID(), o.ResourceType(), o.ResourceOrgID()).Delete(provenanceRecord{}); err != nil {
+			return fmt.Errorf("failed to delete provisioning status: %w", err)
+		}
+		return nil
+	})
+}
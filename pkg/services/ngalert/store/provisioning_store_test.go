@@ -80,4 +80,36 @@ func TestProvisioningStore(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, models.ProvenanceFile, p)
 	})
+	t.Run("Store returns provenances of a resource type by id", func(t *testing.T) {
+		err := dbstore.SetProvenance(context.Background(), models.ContactPointResource(4, "email"), models.ProvenanceFile)
+		require.NoError(t, err)
+		err = dbstore.SetProvenance(context.Background(), models.ContactPointResource(4, "slack"), models.ProvenanceApi)
+		require.NoError(t, err)
+		err = dbstore.SetProvenance(context.Background(), models.MuteTimingResource(4, "weekends"), models.ProvenanceFile)
+		require.NoError(t, err)
+
+		provenances, err := dbstore.GetProvenances(context.Background(), 4, models.ResourceTypeContactPoint)
+
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{
+			"email": models.ProvenanceFile,
+			"slack": models.ProvenanceApi,
+		}, provenances)
+	})
+
+	t.Run("Store deletes provenance of record", func(t *testing.T) {
+		rule := models.AlertRule{
+			UID:   "deleted",
+			OrgID: 5,
+		}
+		err := dbstore.SetProvenance(context.Background(), &rule, models.ProvenanceFile)
+		require.NoError(t, err)
+
+		err = dbstore.DeleteProvenance(context.Background(), &rule)
+		require.NoError(t, err)
+
+		p, err := dbstore.GetProvenance(context.Background(), &rule)
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, p)
+	})
 }
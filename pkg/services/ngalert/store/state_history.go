@@ -0,0 +1,232 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	models2 "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// StateHistoryStore is the database interface of the state transitions of alert instances.
+type StateHistoryStore interface {
+	SaveStateHistory(ctx context.Context, entries []*models.AlertStateHistoryEntry) error
+	GetStateHistory(ctx context.Context, query *models.GetStateHistoryQuery) error
+	DeleteStateHistoryBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// StateHistoryReader is the read-only access to the state history used outside of alerting,
+// the namespaces are used to restrict the history to the folders visible to the user.
+type StateHistoryReader interface {
+	GetStateHistory(ctx context.Context, query *models.GetStateHistoryQuery) error
+	GetNamespaces(ctx context.Context, orgID int64, user *models2.SignedInUser) (map[string]*models2.Folder, error)
+}
+
+// ProvideStateHistoryReader returns the StateHistoryReader backed by the alerting database.
+func ProvideStateHistoryReader(sqlStore *sqlstore.SQLStore, folderService dashboards.FolderService) StateHistoryReader {
+	return &DBstore{
+		SQLStore:      sqlStore,
+		Logger:        log.New("ngalert.state_history"),
+		FolderService: folderService,
+	}
+}
+
+// maxStateHistoryScan is the maximum number of transitions read from the database by a query
+// for the state history, before the label matchers that cannot be checked in SQL are applied.
+const maxStateHistoryScan = 10000
+
+// stateHistoryRow is the alert_state_history table, the labels and values are stored as json
+// and the evaluation time as unix milliseconds.
+type stateHistoryRow struct {
+	ID            int64                    `xorm:"id"`
+	OrgID         int64                    `xorm:"org_id"`
+	RuleUID       string                   `xorm:"rule_uid"`
+	NamespaceUID  string                   `xorm:"namespace_uid"`
+	Labels        models.InstanceLabels    `xorm:"labels"`
+	PreviousState models.InstanceStateType `xorm:"previous_state"`
+	State         models.InstanceStateType `xorm:"state"`
+	EvalValues    string                   `xorm:"eval_values"`
+	EvaluatedAt   int64                    `xorm:"evaluated_at"`
+}
+
+// SaveStateHistory is a handler for saving state transitions of alert instances.
+func (st DBstore) SaveStateHistory(ctx context.Context, entries []*models.AlertStateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for _, entry := range entries {
+			labelTupleJSON, labelsHash, err := entry.Labels.StringAndHash()
+			if err != nil {
+				return err
+			}
+
+			values := ""
+			if len(entry.Values) > 0 {
+				b, err := json.Marshal(entry.Values)
+				if err != nil {
+					return fmt.Errorf("failed to encode the values of the evaluation: %w", err)
+				}
+				values = string(b)
+			}
+
+			_, err = sess.Exec("INSERT INTO alert_state_history (org_id, rule_uid, namespace_uid, labels, labels_hash, previous_state, state, eval_values, evaluated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+				entry.OrgID, entry.RuleUID, entry.NamespaceUID, labelTupleJSON, labelsHash, entry.PreviousState, entry.State, values, entry.EvaluatedAt.UnixNano()/int64(time.Millisecond))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetStateHistory is a handler for retrieving the state transitions of alert instances within
+// specific organisation based on various filters. The transitions are sorted by evaluation time.
+// They are filtered by the namespace of their rule at the time of the transition, so that the
+// transitions of deleted rules are kept in their namespace. Equality matchers are checked in SQL,
+// the other matchers are applied to at most maxStateHistoryScan transitions.
+func (st DBstore) GetStateHistory(ctx context.Context, query *models.GetStateHistoryQuery) error {
+	if query.NamespaceUIDs != nil && len(query.NamespaceUIDs) == 0 {
+		query.Result = []*models.AlertStateHistoryEntry{}
+		return nil
+	}
+
+	return st.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		s := strings.Builder{}
+		params := make([]interface{}, 0)
+
+		addToQuery := func(stmt string, p ...interface{}) {
+			s.WriteString(stmt)
+			params = append(params, p...)
+		}
+
+		addToQuery("SELECT h.* FROM alert_state_history AS h WHERE h.org_id = ?", query.OrgID)
+
+		if len(query.NamespaceUIDs) > 0 {
+			placeholders := make([]string, 0, len(query.NamespaceUIDs))
+			for _, uid := range query.NamespaceUIDs {
+				params = append(params, uid)
+				placeholders = append(placeholders, "?")
+			}
+			addToQuery(fmt.Sprintf(" AND h.namespace_uid IN (%s)", strings.Join(placeholders, ",")))
+		}
+
+		for _, m := range query.Matchers {
+			if pattern, ok := labelsLikePattern(m); ok {
+				addToQuery(" AND h.labels LIKE ? ESCAPE '!'", pattern)
+			}
+		}
+
+		if query.RuleUID != "" {
+			addToQuery(" AND h.rule_uid = ?", query.RuleUID)
+		}
+
+		if !query.From.IsZero() {
+			addToQuery(" AND h.evaluated_at >= ?", query.From.UnixNano()/int64(time.Millisecond))
+		}
+
+		if !query.To.IsZero() {
+			addToQuery(" AND h.evaluated_at <= ?", query.To.UnixNano()/int64(time.Millisecond))
+		}
+
+		addToQuery(" ORDER BY h.evaluated_at DESC, h.id DESC")
+		addToQuery(st.SQLStore.Dialect.Limit(maxStateHistoryScan))
+
+		rows, err := sess.SQL(s.String(), params...).Rows(&stateHistoryRow{})
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		result := make([]*models.AlertStateHistoryEntry, 0)
+		for rows.Next() {
+			if query.Limit > 0 && len(result) >= query.Limit {
+				break
+			}
+
+			row := stateHistoryRow{}
+			if err := rows.Scan(&row); err != nil {
+				return err
+			}
+
+			if !matchesLabels(query, row.Labels) {
+				continue
+			}
+
+			entry := &models.AlertStateHistoryEntry{
+				ID:            row.ID,
+				OrgID:         row.OrgID,
+				RuleUID:       row.RuleUID,
+				NamespaceUID:  row.NamespaceUID,
+				Labels:        row.Labels,
+				PreviousState: row.PreviousState,
+				State:         row.State,
+				EvaluatedAt:   time.Unix(0, row.EvaluatedAt*int64(time.Millisecond)),
+			}
+			if row.EvalValues != "" {
+				if err := json.Unmarshal([]byte(row.EvalValues), &entry.Values); err != nil {
+					return fmt.Errorf("failed to decode the values of the evaluation: %w", err)
+				}
+			}
+			result = append(result, entry)
+		}
+
+		// the most recent transitions are kept when a limit is set, return them in chronological order
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+
+		query.Result = result
+		return nil
+	})
+}
+
+// labelsLikePattern returns the LIKE pattern of the labels column for an equality matcher, which
+// matches the label in the JSON encoded tuples. Matchers of empty values match missing labels,
+// which cannot be checked with a pattern. The matchers are still applied to the rows, as LIKE is
+// case insensitive on some databases.
+func labelsLikePattern(m *labels.Matcher) (string, bool) {
+	if m.Type != labels.MatchEqual || m.Value == "" {
+		return "", false
+	}
+	b, err := json.Marshal([2]string{m.Name, m.Value})
+	if err != nil {
+		return "", false
+	}
+	escaped := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(string(b))
+	return "%" + escaped + "%", true
+}
+
+func matchesLabels(query *models.GetStateHistoryQuery, instanceLabels models.InstanceLabels) bool {
+	for _, m := range query.Matchers {
+		if !m.Matches(instanceLabels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteStateHistoryBefore deletes the state transitions evaluated before the given time,
+// and returns the number of deleted transitions.
+func (st DBstore) DeleteStateHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	var affected int64
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE evaluated_at < ?", before.UnixNano()/int64(time.Millisecond))
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
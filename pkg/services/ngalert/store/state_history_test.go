@@ -0,0 +1,148 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestStateHistoryOperations(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	const mainOrgID int64 = 1
+
+	rule1 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+	rule2 := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	start := time.Unix(1000, 0)
+	entry := func(rule *models.AlertRule, team string, offset time.Duration, prev, state models.InstanceStateType) *models.AlertStateHistoryEntry {
+		return &models.AlertStateHistoryEntry{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			NamespaceUID:  rule.NamespaceUID,
+			Labels:        models.InstanceLabels{"team": team},
+			PreviousState: prev,
+			State:         state,
+			EvaluatedAt:   start.Add(offset),
+		}
+	}
+
+	entries := []*models.AlertStateHistoryEntry{
+		entry(rule1, "ops", 0, models.InstanceStateNormal, models.InstanceStatePending),
+		entry(rule1, "ops", time.Minute, models.InstanceStatePending, models.InstanceStateFiring),
+		entry(rule1, "dev", time.Minute, models.InstanceStateNormal, models.InstanceStateFiring),
+		entry(rule1, "ops", 2*time.Minute, models.InstanceStateFiring, models.InstanceStateNormal),
+		entry(rule2, "ops", 3*time.Minute, models.InstanceStateNormal, models.InstanceStateError),
+	}
+	entries[1].Values = map[string]float64{"B": 42}
+	require.NoError(t, dbstore.SaveStateHistory(ctx, entries))
+
+	t.Run("returns the transitions of a rule in chronological order", func(t *testing.T) {
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID, RuleUID: rule1.UID}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Len(t, q.Result, 4)
+		for i := 1; i < len(q.Result); i++ {
+			require.False(t, q.Result[i].EvaluatedAt.Before(q.Result[i-1].EvaluatedAt))
+		}
+		require.Equal(t, models.InstanceStatePending, q.Result[0].State)
+		require.Equal(t, start, q.Result[0].EvaluatedAt)
+		require.Equal(t, models.InstanceLabels{"team": "ops"}, q.Result[0].Labels)
+	})
+
+	t.Run("keeps the values of the evaluation", func(t *testing.T) {
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID, RuleUID: rule1.UID, From: start.Add(time.Minute), To: start.Add(time.Minute)}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+		var values []map[string]float64
+		for _, e := range q.Result {
+			values = append(values, e.Values)
+		}
+		require.Contains(t, values, map[string]float64{"B": 42})
+	})
+
+	t.Run("filters by label matchers", func(t *testing.T) {
+		m, err := labels.NewMatcher(labels.MatchEqual, "team", "dev")
+		require.NoError(t, err)
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID, Matchers: labels.Matchers{m}}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, rule1.UID, q.Result[0].RuleUID)
+	})
+
+	t.Run("filters by label matchers with special characters", func(t *testing.T) {
+		special := entry(rule2, "o_s", 3*time.Minute, models.InstanceStateError, models.InstanceStateNormal)
+		require.NoError(t, dbstore.SaveStateHistory(ctx, []*models.AlertStateHistoryEntry{special}))
+
+		m, err := labels.NewMatcher(labels.MatchEqual, "team", "o_s")
+		require.NoError(t, err)
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID, Matchers: labels.Matchers{m}}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, models.InstanceLabels{"team": "o_s"}, q.Result[0].Labels)
+
+		m, err = labels.NewMatcher(labels.MatchEqual, "team", "OPS")
+		require.NoError(t, err)
+		q = &models.GetStateHistoryQuery{OrgID: mainOrgID, Matchers: labels.Matchers{m}}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Empty(t, q.Result)
+
+		_, err = dbstore.DeleteStateHistoryBefore(ctx, start.Add(time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, dbstore.SaveStateHistory(ctx, entries))
+	})
+
+	t.Run("keeps the most recent transitions when limited", func(t *testing.T) {
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID, Limit: 2}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+		require.Equal(t, start.Add(2*time.Minute), q.Result[0].EvaluatedAt)
+		require.Equal(t, rule2.UID, q.Result[1].RuleUID)
+	})
+
+	t.Run("filters by namespaces", func(t *testing.T) {
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID, NamespaceUIDs: []string{rule1.NamespaceUID}}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Len(t, q.Result, 5)
+
+		q = &models.GetStateHistoryQuery{OrgID: mainOrgID, NamespaceUIDs: []string{}}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Empty(t, q.Result)
+
+		q = &models.GetStateHistoryQuery{OrgID: mainOrgID, NamespaceUIDs: []string{"unknown"}}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Empty(t, q.Result)
+	})
+
+	t.Run("keeps the transitions of deleted rules in their namespace", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteAlertRuleByUID(ctx, mainOrgID, rule2.UID))
+
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID, RuleUID: rule2.UID, NamespaceUIDs: []string{rule2.NamespaceUID}}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Len(t, q.Result, 1)
+		require.Equal(t, rule2.NamespaceUID, q.Result[0].NamespaceUID)
+
+		q = &models.GetStateHistoryQuery{OrgID: mainOrgID, NamespaceUIDs: []string{"unknown"}}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Empty(t, q.Result)
+	})
+
+	t.Run("deletes the transitions older than a time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteStateHistoryBefore(ctx, start.Add(2*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(3), deleted)
+
+		q := &models.GetStateHistoryQuery{OrgID: mainOrgID}
+		require.NoError(t, dbstore.GetStateHistory(ctx, q))
+		require.Len(t, q.Result, 2)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -223,6 +224,9 @@ func (f *FakeRuleStore) GetNamespaces(_ context.Context, orgID int64, _ *models2
 func (f *FakeRuleStore) GetNamespaceByTitle(_ context.Context, _ string, _ int64, _ *models2.SignedInUser, _ bool) (*models2.Folder, error) {
 	return nil, nil
 }
+func (f *FakeRuleStore) GetNamespaceByUID(_ context.Context, _ string, _ int64, _ *models2.SignedInUser, _ bool) (*models2.Folder, error) {
+	return nil, nil
+}
 func (f *FakeRuleStore) GetOrgRuleGroups(_ context.Context, q *models.ListOrgRuleGroupsQuery) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -404,6 +408,51 @@ func (f *FakeAdminConfigStore) UpdateAdminConfiguration(cmd UpdateAdminConfigura
 	return nil
 }
 
+func NewFakeProvisioningStore() *FakeProvisioningStore {
+	return &FakeProvisioningStore{Records: map[int64]map[string]models.Provenance{}}
+}
+
+// FakeProvisioningStore keeps the provenances by organization and by resource type and id.
+type FakeProvisioningStore struct {
+	mtx     sync.Mutex
+	Records map[int64]map[string]models.Provenance
+}
+
+func (f *FakeProvisioningStore) GetProvenance(_ context.Context, o models.Provisionable) (models.Provenance, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.Records[o.ResourceOrgID()][o.ResourceType()+"/"+o.ResourceID()], nil
+}
+
+func (f *FakeProvisioningStore) GetProvenances(_ context.Context, orgID int64, resourceType string) (map[string]models.Provenance, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := make(map[string]models.Provenance)
+	for key, p := range f.Records[orgID] {
+		if id := strings.TrimPrefix(key, resourceType+"/"); id != key {
+			result[id] = p
+		}
+	}
+	return result, nil
+}
+
+func (f *FakeProvisioningStore) SetProvenance(_ context.Context, o models.Provisionable, p models.Provenance) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, ok := f.Records[o.ResourceOrgID()]; !ok {
+		f.Records[o.ResourceOrgID()] = map[string]models.Provenance{}
+	}
+	f.Records[o.ResourceOrgID()][o.ResourceType()+"/"+o.ResourceID()] = p
+	return nil
+}
+
+func (f *FakeProvisioningStore) DeleteProvenance(_ context.Context, o models.Provisionable) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.Records[o.ResourceOrgID()], o.ResourceType()+"/"+o.ResourceID())
+	return nil
+}
+
 type FakeExternalAlertmanager struct {
 	t      *testing.T
 	mtx    sync.Mutex
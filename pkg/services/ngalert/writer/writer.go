@@ -0,0 +1,80 @@
+package writer
+
+import (
+	"context"
+	"math"
+	"net/http"
+
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Writer writes the results of recording rules as series.
+type Writer interface {
+	Write(ctx context.Context, rule *ngmodels.AlertRule, results eval.Results) error
+}
+
+// RemoteWriter writes the results of recording rules to a Prometheus remote write endpoint.
+type RemoteWriter struct {
+	logger log.Logger
+	client *remotewrite.Client
+}
+
+func NewRemoteWriter(cfg setting.RecordingRuleSettings) *RemoteWriter {
+	return &RemoteWriter{
+		logger: log.New("ngalert.writer"),
+		client: &remotewrite.Client{
+			Endpoint:   cfg.URL,
+			User:       cfg.BasicAuthUsername,
+			Password:   cfg.BasicAuthPassword,
+			HTTPClient: &http.Client{Timeout: cfg.Timeout},
+		},
+	}
+}
+
+// Write sends a sample for each result of the rule that has a value.
+func (w *RemoteWriter) Write(ctx context.Context, rule *ngmodels.AlertRule, results eval.Results) error {
+	timeSeries := toTimeSeries(rule, results)
+	if len(timeSeries) == 0 {
+		return nil
+	}
+	w.logger.Debug("writing series of recording rule", "uid", rule.UID, "org", rule.OrgID, "series", len(timeSeries))
+	return w.client.Send(ctx, timeSeries)
+}
+
+// toTimeSeries converts the results of a recording rule to series named after the metric of the rule.
+// The value of a series is the value of the condition of the rule, and its labels are the labels of
+// the instance and the labels of the rule, which take precedence. Results without a value, such as
+// errors and no data, are skipped.
+func toTimeSeries(rule *ngmodels.AlertRule, results eval.Results) []prompb.TimeSeries {
+	timeSeries := make([]prompb.TimeSeries, 0, len(results))
+	for _, result := range results {
+		if result.State == eval.Error || result.State == eval.NoData {
+			continue
+		}
+		capture, ok := result.Values[rule.Condition]
+		if !ok || capture.Value == nil || math.IsNaN(*capture.Value) {
+			continue
+		}
+
+		labels := make(map[string]string, len(result.Instance)+len(rule.Labels))
+		for k, v := range result.Instance {
+			labels[k] = v
+		}
+		for k, v := range rule.Labels {
+			labels[k] = v
+		}
+
+		ts, ok := remotewrite.TimeSeriesFromSample(rule.RecordMetric, labels, result.EvaluatedAt, *capture.Value)
+		if !ok {
+			continue
+		}
+		timeSeries = append(timeSeries, ts)
+	}
+	return timeSeries
+}
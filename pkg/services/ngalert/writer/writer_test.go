@@ -0,0 +1,95 @@
+package writer
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestToTimeSeries(t *testing.T) {
+	evaluatedAt := time.Unix(1000, 0)
+	value := func(v float64) map[string]eval.NumberValueCapture {
+		return map[string]eval.NumberValueCapture{"B": {Var: "B", Value: &v}}
+	}
+	rule := &ngmodels.AlertRule{
+		Condition:    "B",
+		RecordMetric: "cpu_usage:avg",
+		Labels:       map[string]string{"team": "ops"},
+	}
+	results := eval.Results{
+		{Instance: data.Labels{"host": "a", "team": "dev"}, State: eval.Alerting, EvaluatedAt: evaluatedAt, Values: value(2.5)},
+		{Instance: data.Labels{"host": "b"}, State: eval.Normal, EvaluatedAt: evaluatedAt, Values: value(0)},
+		{Instance: data.Labels{"host": "c"}, State: eval.NoData, EvaluatedAt: evaluatedAt},
+		{Instance: data.Labels{"host": "d"}, State: eval.Error, EvaluatedAt: evaluatedAt},
+	}
+
+	timeSeries := toTimeSeries(rule, results)
+	require.Equal(t, []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "cpu_usage:avg"},
+				{Name: "host", Value: "a"},
+				{Name: "team", Value: "ops"},
+			},
+			Samples: []prompb.Sample{{Timestamp: 1000000, Value: 2.5}},
+		},
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "cpu_usage:avg"},
+				{Name: "host", Value: "b"},
+				{Name: "team", Value: "ops"},
+			},
+			Samples: []prompb.Sample{{Timestamp: 1000000, Value: 0}},
+		},
+	}, timeSeries)
+}
+
+func TestRemoteWriter_Write(t *testing.T) {
+	var received prompb.WriteRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(b, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := NewRemoteWriter(setting.RecordingRuleSettings{
+		URL:               server.URL,
+		BasicAuthUsername: "user",
+		BasicAuthPassword: "password",
+		Timeout:           time.Second,
+	})
+
+	v := 42.0
+	rule := &ngmodels.AlertRule{UID: "rule", Condition: "A", RecordMetric: "answer"}
+	results := eval.Results{{
+		State:       eval.Alerting,
+		EvaluatedAt: time.Unix(1, 0),
+		Values:      map[string]eval.NumberValueCapture{"A": {Var: "A", Value: &v}},
+	}}
+	require.NoError(t, w.Write(context.Background(), rule, results))
+	require.Len(t, received.Timeseries, 1)
+	require.Equal(t, []prompb.Label{{Name: "__name__", Value: "answer"}}, received.Timeseries[0].Labels)
+	require.Equal(t, 42.0, received.Timeseries[0].Samples[0].Value)
+}
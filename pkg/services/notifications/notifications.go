@@ -131,6 +131,9 @@ func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *models.
 		HttpMethod:  cmd.HttpMethod,
 		HttpHeader:  cmd.HttpHeader,
 		ContentType: cmd.ContentType,
+		Signing:     cmd.Signing,
+		TLS:         cmd.TLS,
+		Retry:       cmd.Retry,
 	})
 }
 
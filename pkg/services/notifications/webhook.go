@@ -3,19 +3,31 @@ package notifications
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"golang.org/x/net/context/ctxhttp"
 
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+const (
+	defaultSignatureHeader = "X-Grafana-Signature"
+	defaultTimestampHeader = "X-Grafana-Timestamp"
+)
+
 type Webhook struct {
 	Url         string
 	User        string
@@ -24,6 +36,9 @@ type Webhook struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+	Signing     *models.WebhookSigning
+	TLS         *models.WebhookTLS
+	Retry       *models.WebhookRetry
 }
 
 var netTransport = &http.Transport{
@@ -41,6 +56,22 @@ var netClient = &http.Client{
 	Transport: netTransport,
 }
 
+// timeNow makes it possible to test the signature of the requests.
+var timeNow = time.Now
+
+// errRetryable wraps the errors of the requests that can be retried.
+type errRetryable struct {
+	err error
+}
+
+func (e errRetryable) Error() string {
+	return e.err.Error()
+}
+
+func (e errRetryable) Unwrap() error {
+	return e.err
+}
+
 func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *Webhook) error {
 	ns.log.Debug("Sending webhook", "url", webhook.Url, "http method", webhook.HttpMethod)
 
@@ -48,19 +79,70 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		webhook.HttpMethod = http.MethodPost
 	}
 
-	if webhook.HttpMethod != http.MethodPost && webhook.HttpMethod != http.MethodPut {
-		return fmt.Errorf("webhook only supports HTTP methods PUT or POST")
+	if webhook.HttpMethod != http.MethodPost && webhook.HttpMethod != http.MethodPut && webhook.HttpMethod != http.MethodPatch {
+		return fmt.Errorf("webhook only supports HTTP methods PUT, POST or PATCH")
+	}
+
+	if webhook.ContentType == "" {
+		webhook.ContentType = "application/json"
+	}
+
+	client := netClient
+	if webhook.TLS != nil {
+		transport, err := newTLSTransport(webhook.TLS)
+		if err != nil {
+			return err
+		}
+		defer transport.CloseIdleConnections()
+		client = &http.Client{
+			Timeout:   netClient.Timeout,
+			Transport: transport,
+		}
+	}
+
+	var retry models.WebhookRetry
+	if webhook.Retry != nil {
+		retry = *webhook.Retry
+	}
+	if retry.MaxRetries > models.MaxWebhookRetries {
+		retry.MaxRetries = models.MaxWebhookRetries
+	}
+	backoff := retry.Backoff
+	for attempt := 0; ; attempt++ {
+		err := ns.sendWebRequest(ctx, client, webhook)
+		var retryable errRetryable
+		if err == nil || !errors.As(err, &retryable) || attempt >= retry.MaxRetries {
+			return err
+		}
+
+		backoff = capWebhookBackoff(backoff)
+		ns.log.Debug("Retrying webhook", "url", webhook.Url, "attempt", attempt+1, "backoff", backoff, "err", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("webhook retry canceled: %w, last error: %s", ctx.Err(), err)
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// capWebhookBackoff returns the backoff limited to models.MaxWebhookBackoff. Doubling a large backoff can
+// also overflow into a negative duration.
+func capWebhookBackoff(backoff time.Duration) time.Duration {
+	if backoff <= 0 || backoff > models.MaxWebhookBackoff {
+		return models.MaxWebhookBackoff
 	}
+	return backoff
+}
 
+func (ns *NotificationService) sendWebRequest(ctx context.Context, client *http.Client, webhook *Webhook) error {
 	request, err := http.NewRequest(webhook.HttpMethod, webhook.Url, bytes.NewReader([]byte(webhook.Body)))
 	if err != nil {
 		return err
 	}
 
-	if webhook.ContentType == "" {
-		webhook.ContentType = "application/json"
-	}
-
 	request.Header.Set("Content-Type", webhook.ContentType)
 	request.Header.Set("User-Agent", "Grafana")
 
@@ -72,9 +154,16 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		request.Header.Set(k, v)
 	}
 
-	resp, err := ctxhttp.Do(ctx, netClient, request)
+	if webhook.Signing != nil {
+		signRequest(request, webhook.Signing, webhook.Body)
+	}
+
+	resp, err := ctxhttp.Do(ctx, client, request)
 	if err != nil {
-		return err
+		if ctx.Err() != nil {
+			return err
+		}
+		return errRetryable{err: err}
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -97,5 +186,55 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 	}
 
 	ns.log.Debug("Webhook failed", "url", webhook.Url, "statuscode", resp.Status, "body", string(body))
-	return fmt.Errorf("Webhook response status %v", resp.Status)
+	err = fmt.Errorf("Webhook response status %v", resp.Status)
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return errRetryable{err: err}
+	}
+	return err
+}
+
+// signRequest sets the HMAC-SHA256 signature of the timestamp and the body of the request.
+func signRequest(request *http.Request, signing *models.WebhookSigning, body string) {
+	timestamp := strconv.FormatInt(timeNow().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(signing.Secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write([]byte(body))
+
+	signatureHeader := signing.SignatureHeader
+	if signatureHeader == "" {
+		signatureHeader = defaultSignatureHeader
+	}
+	timestampHeader := signing.TimestampHeader
+	if timestampHeader == "" {
+		timestampHeader = defaultTimestampHeader
+	}
+	request.Header.Set(timestampHeader, timestamp)
+	request.Header.Set(signatureHeader, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func newTLSTransport(cfg *models.WebhookTLS) (*http.Transport, error) {
+	tlsConfig := &tls.Config{
+		Renegotiation: tls.RenegotiateFreelyAsClient,
+		// nolint:gosec
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.ClientCertificate != "" || cfg.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(cfg.ClientCertificate), []byte(cfg.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.CACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACertificate)) {
+			return nil, errors.New("invalid webhook CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := netTransport.Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
 }
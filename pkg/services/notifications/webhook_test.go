@@ -0,0 +1,169 @@
+package notifications
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestSendWebhookSync(t *testing.T) {
+	ns, _ := createSut(t, bus.New())
+
+	t.Run("signs the body of the request", func(t *testing.T) {
+		timeNow = func() time.Time { return time.Unix(1650000000, 0) }
+		t.Cleanup(func() { timeNow = time.Now })
+
+		var request *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			body, _ = ioutil.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		err := ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{
+			Url:        server.URL,
+			Body:       `{"status":"firing"}`,
+			HttpMethod: http.MethodPatch,
+			HttpHeader: map[string]string{"X-Team": "ops"},
+			Signing:    &models.WebhookSigning{Secret: "secret"},
+		})
+		require.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		_, _ = mac.Write([]byte(`1650000000.{"status":"firing"}`))
+		require.Equal(t, http.MethodPatch, request.Method)
+		require.Equal(t, `{"status":"firing"}`, string(body))
+		require.Equal(t, "ops", request.Header.Get("X-Team"))
+		require.Equal(t, "1650000000", request.Header.Get("X-Grafana-Timestamp"))
+		require.Equal(t, hex.EncodeToString(mac.Sum(nil)), request.Header.Get("X-Grafana-Signature"))
+	})
+
+	t.Run("retries the requests that fail with a server error", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		err := ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{
+			Url:   server.URL,
+			Retry: &models.WebhookRetry{MaxRetries: 3, Backoff: time.Millisecond},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("does not retry the requests that fail with a client error", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		err := ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{
+			Url:   server.URL,
+			Retry: &models.WebhookRetry{MaxRetries: 3, Backoff: time.Millisecond},
+		})
+		require.Error(t, err)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("gives up after the maximum number of retries", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		err := ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{
+			Url:   server.URL,
+			Retry: &models.WebhookRetry{MaxRetries: 2, Backoff: time.Millisecond},
+		})
+		require.Error(t, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("caps the number of retries", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{
+			Url:   server.URL,
+			Retry: &models.WebhookRetry{MaxRetries: 1000, Backoff: time.Nanosecond},
+		})
+		require.Error(t, err)
+		require.Equal(t, models.MaxWebhookRetries+1, attempts)
+	})
+
+	t.Run("stops retrying when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cancel()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := ns.SendWebhookSync(ctx, &models.SendWebhookSync{
+			Url:   server.URL,
+			Retry: &models.WebhookRetry{MaxRetries: 3, Backoff: time.Hour},
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("trusts the CA certificate", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		err := ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{Url: server.URL})
+		require.Error(t, err)
+
+		err = ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{
+			Url: server.URL,
+			TLS: &models.WebhookTLS{CACertificate: string(ca)},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("fails with an invalid client certificate", func(t *testing.T) {
+		err := ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{
+			Url: "https://localhost",
+			TLS: &models.WebhookTLS{ClientCertificate: "invalid", ClientKey: "invalid"},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("fails with an unsupported method", func(t *testing.T) {
+		err := ns.SendWebhookSync(context.Background(), &models.SendWebhookSync{Url: "http://localhost", HttpMethod: http.MethodGet})
+		require.Error(t, err)
+	})
+}
+
+func TestCapWebhookBackoff(t *testing.T) {
+	require.Equal(t, time.Second, capWebhookBackoff(time.Second))
+	require.Equal(t, models.MaxWebhookBackoff, capWebhookBackoff(time.Hour))
+	// the backoff overflows when it is doubled too many times.
+	require.Equal(t, models.MaxWebhookBackoff, capWebhookBackoff(time.Duration(-1)))
+}
@@ -0,0 +1,250 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngprovisioning "github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+// SQLStore is used to check the organizations of the provisioned resources, and to find the
+// folders of the rule groups.
+type SQLStore interface {
+	GetOrgById(ctx context.Context, query *models.GetOrgByIdQuery) error
+	GetDashboard(ctx context.Context, query *models.GetDashboardQuery) error
+}
+
+// Services are the unified alerting services that change the provisioned resources and record
+// their provenance.
+type Services struct {
+	AlertRules           *ngprovisioning.AlertRuleService
+	ContactPoints        *ngprovisioning.ContactPointService
+	NotificationPolicies *ngprovisioning.NotificationPolicyService
+	MuteTimings          *ngprovisioning.MuteTimingService
+	Templates            *ngprovisioning.TemplateService
+}
+
+// Provision provisions the alert rules, contact points, notification policies, mute timings and
+// notification templates of unified alerting.
+func Provision(ctx context.Context, configDirectory string, store SQLStore, dashboardService dashboards.DashboardProvisioningService, services Services) error {
+	ap := newAlertingProvisioner(store, dashboardService, services, log.New("provisioning.alerting"))
+	return ap.applyChanges(ctx, configDirectory)
+}
+
+// AlertingProvisioner is responsible for provisioning unified alerting resources. They are
+// provisioned with the file provenance, and cannot be changed with the API afterwards.
+type AlertingProvisioner struct {
+	log              log.Logger
+	cfgProvider      *configReader
+	sqlstore         SQLStore
+	dashboardService dashboards.DashboardProvisioningService
+	services         Services
+}
+
+func newAlertingProvisioner(store SQLStore, dashboardService dashboards.DashboardProvisioningService, services Services, log log.Logger) AlertingProvisioner {
+	return AlertingProvisioner{
+		log:              log,
+		cfgProvider:      &configReader{orgStore: store, log: log},
+		sqlstore:         store,
+		dashboardService: dashboardService,
+		services:         services,
+	}
+}
+
+func (ap *AlertingProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
+	// the resources of all files are applied together, as they can reference each other: the
+	// contact points and mute timings must exist before the policies that use them, and the
+	// policies must no longer use them before they are deleted
+	steps := []func(context.Context, *alertingAsConfig) error{
+		ap.applyTemplates,
+		ap.applyContactPoints,
+		ap.applyMuteTimes,
+		ap.applyPolicies,
+		ap.deleteMuteTimes,
+		ap.deleteContactPoints,
+		ap.deleteTemplates,
+		ap.deleteRules,
+		ap.applyRuleGroups,
+	}
+	for _, step := range steps {
+		for _, cfg := range configs {
+			if err := step(ctx, cfg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) applyTemplates(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, t := range cfg.Templates {
+		ap.log.Debug("Provisioning notification template", "org", t.OrgID, "name", t.Name)
+		if err := ap.services.Templates.SetTemplate(ctx, t.OrgID, t.MessageTemplate, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to provision template %q: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) deleteTemplates(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, t := range cfg.DeleteTemplates {
+		ap.log.Info("Deleting notification template", "org", t.OrgID, "name", t.Name)
+		if err := ap.services.Templates.DeleteTemplate(ctx, t.OrgID, t.Name, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to delete template %q: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) applyContactPoints(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, cp := range cfg.ContactPoints {
+		ap.log.Debug("Provisioning contact point", "org", cp.OrgID, "name", cp.Name)
+		if err := ap.services.ContactPoints.UpsertContactPoint(ctx, cp.OrgID, cp.ContactPoint, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to provision contact point %q: %w", cp.Name, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) deleteContactPoints(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, cp := range cfg.DeleteContactPoints {
+		ap.log.Info("Deleting contact point", "org", cp.OrgID, "name", cp.Name)
+		if err := ap.services.ContactPoints.DeleteContactPoint(ctx, cp.OrgID, cp.Name, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to delete contact point %q: %w", cp.Name, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) applyMuteTimes(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, mt := range cfg.MuteTimes {
+		ap.log.Debug("Provisioning mute time", "org", mt.OrgID, "name", mt.Name)
+		if err := ap.services.MuteTimings.UpsertMuteTiming(ctx, mt.OrgID, mt.MuteTimeInterval, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to provision mute time %q: %w", mt.Name, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) deleteMuteTimes(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, mt := range cfg.DeleteMuteTimes {
+		ap.log.Info("Deleting mute time", "org", mt.OrgID, "name", mt.Name)
+		if err := ap.services.MuteTimings.DeleteMuteTiming(ctx, mt.OrgID, mt.Name, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to delete mute time %q: %w", mt.Name, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) applyPolicies(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, p := range cfg.Policies {
+		ap.log.Debug("Provisioning notification policies", "org", p.OrgID)
+		if err := ap.services.NotificationPolicies.UpdatePolicyTree(ctx, p.OrgID, p.Policy, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to provision the notification policies of organization %d: %w", p.OrgID, err)
+		}
+	}
+	for _, orgID := range cfg.ResetPolicies {
+		ap.log.Info("Resetting notification policies", "org", orgID)
+		if err := ap.services.NotificationPolicies.ResetPolicyTree(ctx, orgID, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to reset the notification policies of organization %d: %w", orgID, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) deleteRules(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, rule := range cfg.DeleteRules {
+		ap.log.Info("Deleting alert rule", "org", rule.OrgID, "uid", rule.UID)
+		if err := ap.services.AlertRules.DeleteAlertRule(ctx, rule.OrgID, rule.UID, ngmodels.ProvenanceFile); err != nil {
+			return fmt.Errorf("failed to delete alert rule %q: %w", rule.UID, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) applyRuleGroups(ctx context.Context, cfg *alertingAsConfig) error {
+	for _, group := range cfg.Groups {
+		folderUID, err := ap.getOrCreateFolderUID(ctx, group.OrgID, group.Folder, group.FolderUID)
+		if err != nil {
+			return fmt.Errorf("failed to provision the folder of rule group %q: %w", group.Name, err)
+		}
+
+		for _, rule := range group.Rules {
+			rule.NamespaceUID = folderUID
+			if err := ap.applyRule(ctx, rule); err != nil {
+				return fmt.Errorf("failed to provision alert rule %q: %w", rule.UID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applyRule creates the rule, or updates it when it changed or was not provisioned from a file.
+func (ap *AlertingProvisioner) applyRule(ctx context.Context, rule ngmodels.AlertRule) error {
+	existing, provenance, err := ap.services.AlertRules.GetAlertRule(ctx, rule.OrgID, rule.UID)
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		ap.log.Info("Creating alert rule", "org", rule.OrgID, "uid", rule.UID)
+		_, err := ap.services.AlertRules.CreateAlertRule(ctx, rule, ngmodels.ProvenanceFile)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if rule.IntervalSeconds == 0 {
+		rule.IntervalSeconds = existing.IntervalSeconds
+	}
+	// the stored queries have their defaults, they are set before the rules are compared
+	if err := rule.PreSave(time.Now); err != nil {
+		return err
+	}
+	if provenance == ngmodels.ProvenanceFile && len(existing.Diff(&rule, "ID", "Version", "Updated")) == 0 {
+		return nil
+	}
+
+	ap.log.Info("Updating alert rule", "org", rule.OrgID, "uid", rule.UID)
+	_, err = ap.services.AlertRules.UpdateAlertRule(ctx, rule, ngmodels.ProvenanceFile)
+	return err
+}
+
+func (ap *AlertingProvisioner) getOrCreateFolderUID(ctx context.Context, orgID int64, title, uid string) (string, error) {
+	query := &models.GetDashboardQuery{OrgId: orgID, Uid: uid}
+	if uid == "" {
+		query.Slug = models.SlugifyTitle(title)
+	}
+	err := ap.sqlstore.GetDashboard(ctx, query)
+	if err != nil && !errors.Is(err, models.ErrDashboardNotFound) {
+		return "", err
+	}
+
+	// folder not found. create one.
+	if errors.Is(err, models.ErrDashboardNotFound) {
+		dash := &dashboards.SaveDashboardDTO{}
+		dash.Dashboard = models.NewDashboardFolder(title)
+		dash.Dashboard.IsFolder = true
+		dash.Overwrite = true
+		dash.OrgId = orgID
+		dash.Dashboard.SetUid(uid)
+		folder, err := ap.dashboardService.SaveFolderForProvisionedDashboards(ctx, dash)
+		if err != nil {
+			return "", err
+		}
+		return folder.Uid, nil
+	}
+
+	if !query.Result.IsFolder {
+		return "", fmt.Errorf("got invalid response. expected folder, found dashboard")
+	}
+	return query.Result.Uid, nil
+}
@@ -0,0 +1,217 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type configReader struct {
+	orgStore utils.OrgStore
+	log      log.Logger
+}
+
+func (cr *configReader) readConfig(ctx context.Context, path string) ([]*alertingAsConfig, error) {
+	var configs []*alertingAsConfig
+	cr.log.Debug("Looking for alerting provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read alerting provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing alerting provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseConfig(path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating alerting provisioning files")
+	if err := cr.validateRequiredFields(configs); err != nil {
+		return nil, err
+	}
+
+	if err := cr.checkOrgIDs(ctx, configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseConfig(path string, file os.FileInfo) (*alertingAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiVersion *configVersion
+	if err := yaml.Unmarshal(yamlFile, &apiVersion); err != nil {
+		return nil, err
+	}
+	if apiVersion == nil || apiVersion.APIVersion != 1 {
+		return nil, fmt.Errorf("unsupported apiVersion, only apiVersion 1 is supported")
+	}
+
+	var cfg *alertingAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToAlertingFromConfig()
+}
+
+func (cr *configReader) validateRequiredFields(configs []*alertingAsConfig) error {
+	var errStrings []string
+	addError := func(format string, args ...interface{}) {
+		errStrings = append(errStrings, fmt.Sprintf(format, args...))
+	}
+
+	for _, cfg := range configs {
+		for i, group := range cfg.Groups {
+			if group.Name == "" {
+				addError("Rule group %d in configuration doesn't contain required field name", i+1)
+			}
+			if group.Folder == "" {
+				addError("Rule group %q in configuration doesn't contain required field folder", group.Name)
+			}
+			for j, rule := range group.Rules {
+				if rule.UID == "" || rule.Title == "" || rule.Condition == "" || len(rule.Data) == 0 {
+					addError("Rule %d of rule group %q in configuration doesn't contain required fields uid, title, condition and data", j+1, group.Name)
+				}
+			}
+		}
+		for i, rule := range cfg.DeleteRules {
+			if rule.UID == "" {
+				addError("Deleted rule %d in configuration doesn't contain required field uid", i+1)
+			}
+		}
+
+		for i, cp := range cfg.ContactPoints {
+			if cp.Name == "" {
+				addError("Contact point %d in configuration doesn't contain required field name", i+1)
+			}
+			for j, integration := range cp.Integrations {
+				if integration.Type == "" {
+					addError("Receiver %d of contact point %q in configuration doesn't contain required field type", j+1, cp.Name)
+				}
+			}
+		}
+		for i, mt := range cfg.MuteTimes {
+			if mt.Name == "" {
+				addError("Mute time %d in configuration doesn't contain required field name", i+1)
+			}
+		}
+		for i, t := range cfg.Templates {
+			if t.Name == "" {
+				addError("Template %d in configuration doesn't contain required field name", i+1)
+			}
+		}
+
+		for _, deleted := range []struct {
+			kind  string
+			items []*deleteByName
+		}{
+			{"contact point", cfg.DeleteContactPoints},
+			{"mute time", cfg.DeleteMuteTimes},
+			{"template", cfg.DeleteTemplates},
+		} {
+			for i, item := range deleted.items {
+				if item.Name == "" {
+					addError("Deleted %s %d in configuration doesn't contain required field name", deleted.kind, i+1)
+				}
+			}
+		}
+	}
+
+	if len(errStrings) != 0 {
+		return errors.New(strings.Join(errStrings, "\n"))
+	}
+	return nil
+}
+
+// checkOrgIDs defaults the organization of the resources to the main organization, and checks that
+// the other organizations exist.
+func (cr *configReader) checkOrgIDs(ctx context.Context, configs []*alertingAsConfig) error {
+	checked := map[int64]bool{}
+	check := func(orgID *int64) error {
+		if *orgID < 1 {
+			*orgID = 1
+			return nil
+		}
+		if checked[*orgID] {
+			return nil
+		}
+		if err := utils.CheckOrgExists(ctx, cr.orgStore, *orgID); err != nil {
+			return fmt.Errorf("failed to provision alerting resources of organization %d: %w", *orgID, err)
+		}
+		checked[*orgID] = true
+		return nil
+	}
+
+	for _, cfg := range configs {
+		orgIDs := make([]*int64, 0)
+		for _, group := range cfg.Groups {
+			orgIDs = append(orgIDs, &group.OrgID)
+		}
+		for _, rule := range cfg.DeleteRules {
+			orgIDs = append(orgIDs, &rule.OrgID)
+		}
+		for _, cp := range cfg.ContactPoints {
+			orgIDs = append(orgIDs, &cp.OrgID)
+		}
+		for _, p := range cfg.Policies {
+			orgIDs = append(orgIDs, &p.OrgID)
+		}
+		for i := range cfg.ResetPolicies {
+			orgIDs = append(orgIDs, &cfg.ResetPolicies[i])
+		}
+		for _, mt := range cfg.MuteTimes {
+			orgIDs = append(orgIDs, &mt.OrgID)
+		}
+		for _, t := range cfg.Templates {
+			orgIDs = append(orgIDs, &t.OrgID)
+		}
+		for _, items := range [][]*deleteByName{cfg.DeleteContactPoints, cfg.DeleteMuteTimes, cfg.DeleteTemplates} {
+			for _, item := range items {
+				orgIDs = append(orgIDs, &item.OrgID)
+			}
+		}
+
+		for _, orgID := range orgIDs {
+			if err := check(orgID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, cfg := range configs {
+		for _, group := range cfg.Groups {
+			for i := range group.Rules {
+				group.Rules[i].OrgID = group.OrgID
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngprovisioning "github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	correctProperties  = "./testdata/test-configs/correct-properties"
+	noRequiredFields   = "./testdata/test-configs/no-required-fields"
+	unsupportedVersion = "./testdata/test-configs/unsupported-version"
+)
+
+func TestAlertingAsConfig(t *testing.T) {
+	setup := func(t *testing.T) *sqlstore.SQLStore {
+		sqlStore := sqlstore.InitTestDB(t)
+		for i := 1; i < 3; i++ {
+			orgCommand := models.CreateOrgCommand{Name: fmt.Sprintf("Main Org. %v", i)}
+			require.NoError(t, sqlstore.CreateOrg(context.Background(), &orgCommand))
+		}
+		t.Setenv("TEST_SLACK_URL", "https://hooks.slack.com/secret")
+		return sqlStore
+	}
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		cfgProvider := &configReader{orgStore: setup(t), log: log.New("test logger")}
+
+		cfg, err := cfgProvider.readConfig(context.Background(), correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+
+		require.Len(t, cfg[0].Groups, 1)
+		group := cfg[0].Groups[0]
+		require.Equal(t, "Infrastructure", group.Folder)
+		require.Equal(t, time.Minute, group.Interval)
+		require.Len(t, group.Rules, 1)
+		rule := group.Rules[0]
+		require.Equal(t, "high-cpu", rule.UID)
+		require.Equal(t, "cpu", rule.RuleGroup)
+		require.Equal(t, int64(60), rule.IntervalSeconds)
+		require.Equal(t, ngmodels.OK, rule.NoDataState)
+		require.Equal(t, ngmodels.AlertingErrState, rule.ExecErrState)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, map[string]string{"team": "ops"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "CPU usage is {{ $values.A }}"}, rule.Annotations)
+		require.Len(t, rule.Data, 2)
+		require.Equal(t, ngmodels.Duration(10*time.Minute), rule.Data[0].RelativeTimeRange.From)
+		require.JSONEq(t, `{"expr": "avg(rate(node_cpu_seconds_total{mode!=\"idle\"}[$__rate_interval]))"}`, string(rule.Data[0].Model))
+
+		require.Equal(t, []*deleteRule{{OrgID: 1, UID: "old-rule"}}, cfg[0].DeleteRules)
+
+		require.Len(t, cfg[0].ContactPoints, 1)
+		cp := cfg[0].ContactPoints[0]
+		require.Equal(t, "ops", cp.Name)
+		require.Len(t, cp.Integrations, 1)
+		require.Equal(t, "slack", cp.Integrations[0].Type)
+		require.Equal(t, "#ops", cp.Integrations[0].Settings.Get("recipient").MustString())
+		require.Equal(t, map[string]string{"url": "https://hooks.slack.com/secret"}, cp.Integrations[0].SecureSettings)
+		require.Equal(t, []*deleteByName{{OrgID: 1, Name: "old-contact-point"}}, cfg[0].DeleteContactPoints)
+
+		require.Len(t, cfg[0].Policies, 1)
+		policy := cfg[0].Policies[0].Policy
+		require.Equal(t, "ops", policy.Receiver)
+		require.Len(t, policy.Routes, 1)
+		require.Equal(t, []string{"weekends"}, policy.Routes[0].MuteTimeIntervals)
+		require.Len(t, policy.Routes[0].ObjectMatchers, 1)
+		require.Equal(t, []int64{2}, cfg[0].ResetPolicies)
+
+		require.Len(t, cfg[0].MuteTimes, 1)
+		require.Equal(t, "weekends", cfg[0].MuteTimes[0].Name)
+		require.Len(t, cfg[0].MuteTimes[0].TimeIntervals[0].Weekdays, 2)
+
+		require.Len(t, cfg[0].Templates, 1)
+		require.Equal(t, "ops.tmpl", cfg[0].Templates[0].Name)
+		require.Equal(t, `{{ define "ops.title" }}{{ .Status | toUpper }}{{ end }}`, cfg[0].Templates[0].Template)
+	})
+
+	t.Run("Missing required fields should fail", func(t *testing.T) {
+		cfgProvider := &configReader{orgStore: setup(t), log: log.New("test logger")}
+
+		_, err := cfgProvider.readConfig(context.Background(), noRequiredFields)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Rule group 1 in configuration doesn't contain required field name")
+		require.Contains(t, err.Error(), "Rule 1 of rule group \"\" in configuration doesn't contain required fields uid, title, condition and data")
+		require.Contains(t, err.Error(), "Contact point 1 in configuration doesn't contain required field name")
+		require.Contains(t, err.Error(), "Receiver 1 of contact point \"\" in configuration doesn't contain required field type")
+		require.Contains(t, err.Error(), "Deleted template 1 in configuration doesn't contain required field name")
+	})
+
+	t.Run("Files without apiVersion 1 should fail", func(t *testing.T) {
+		cfgProvider := &configReader{orgStore: setup(t), log: log.New("test logger")}
+
+		_, err := cfgProvider.readConfig(context.Background(), unsupportedVersion)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "only apiVersion 1 is supported")
+	})
+
+	t.Run("Unknown organizations should fail", func(t *testing.T) {
+		cfgProvider := &configReader{orgStore: setup(t), log: log.New("test logger")}
+		cfg := []*alertingAsConfig{{Templates: []*template{{OrgID: 12}}}}
+
+		err := cfgProvider.checkOrgIDs(context.Background(), cfg)
+		require.ErrorIs(t, err, models.ErrOrgNotFound)
+	})
+
+	t.Run("Provisioned resources are read-only and applied once", func(t *testing.T) {
+		sqlStore := setup(t)
+		dashboardService := &dashboards.FakeDashboardProvisioning{}
+		dashboardService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).
+			Return(&models.Dashboard{Uid: "infrastructure", IsFolder: true}, nil)
+		services, st := setupServices(t, sqlStore)
+
+		err := Provision(context.Background(), correctProperties, sqlStore, dashboardService, services)
+		require.NoError(t, err)
+
+		rule, provenance, err := services.AlertRules.GetAlertRule(context.Background(), 1, "high-cpu")
+		require.NoError(t, err)
+		require.Equal(t, ngmodels.ProvenanceFile, provenance)
+		require.Equal(t, "infrastructure", rule.NamespaceUID)
+		require.Equal(t, int64(1), rule.Version)
+
+		contactPoints, err := services.ContactPoints.GetContactPoints(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, contactPoints, 2)
+		require.Equal(t, "ops", contactPoints[1].Name)
+		require.Equal(t, ngmodels.ProvenanceFile, contactPoints[1].Provenance)
+
+		tree, provenance, err := services.NotificationPolicies.GetPolicyTree(context.Background(), 1)
+		require.NoError(t, err)
+		require.Equal(t, ngmodels.ProvenanceFile, provenance)
+		require.Equal(t, "ops", tree.Receiver)
+
+		err = services.ContactPoints.DeleteContactPoint(context.Background(), 1, "ops", ngmodels.ProvenanceNone)
+		require.ErrorIs(t, err, ngmodels.ErrProvisionedResource)
+
+		query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: 1}
+		require.NoError(t, st.GetLatestAlertmanagerConfiguration(context.Background(), &query))
+		configID := query.Result.ID
+
+		err = Provision(context.Background(), correctProperties, sqlStore, dashboardService, services)
+		require.NoError(t, err)
+
+		rule, _, err = services.AlertRules.GetAlertRule(context.Background(), 1, "high-cpu")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), rule.Version)
+		require.NoError(t, st.GetLatestAlertmanagerConfiguration(context.Background(), &query))
+		require.Equal(t, configID, query.Result.ID)
+	})
+}
+
+func setupServices(t *testing.T, sqlStore *sqlstore.SQLStore) (Services, *store.DBstore) {
+	t.Helper()
+	logger := log.New("test logger")
+	st := &store.DBstore{
+		BaseInterval:    10 * time.Second,
+		DefaultInterval: time.Minute,
+		SQLStore:        sqlStore,
+		Logger:          logger,
+	}
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	defaultConfig := setting.GetAlertmanagerDefaultConfiguration()
+	return Services{
+		AlertRules:           ngprovisioning.NewAlertRuleService(st, st, sqlStore, st.DefaultInterval, logger),
+		ContactPoints:        ngprovisioning.NewContactPointService(st, defaultConfig, st, sqlStore, secretsService, logger),
+		NotificationPolicies: ngprovisioning.NewNotificationPolicyService(st, defaultConfig, st, sqlStore, logger),
+		MuteTimings:          ngprovisioning.NewMuteTimingService(st, defaultConfig, st, sqlStore, logger),
+		Templates:            ngprovisioning.NewTemplateService(st, defaultConfig, st, sqlStore, logger),
+	}, st
+}
@@ -0,0 +1,359 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngprovisioning "github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// configVersion is used to figure out which API version a config uses.
+type configVersion struct {
+	APIVersion int64 `json:"apiVersion" yaml:"apiVersion"`
+}
+
+// alertingAsConfig is normalized data object for the unified alerting config data. Any config version
+// should be mappable to this type.
+type alertingAsConfig struct {
+	Groups              []*alertRuleGroup
+	DeleteRules         []*deleteRule
+	ContactPoints       []*contactPoint
+	DeleteContactPoints []*deleteByName
+	Policies            []*policy
+	ResetPolicies       []int64
+	MuteTimes           []*muteTime
+	DeleteMuteTimes     []*deleteByName
+	Templates           []*template
+	DeleteTemplates     []*deleteByName
+}
+
+type alertRuleGroup struct {
+	OrgID     int64
+	Name      string
+	Folder    string
+	FolderUID string
+	Interval  time.Duration
+	// Rules have no namespace uid, it is the uid of the folder once it is known
+	Rules []ngmodels.AlertRule
+}
+
+type deleteRule struct {
+	OrgID int64
+	UID   string
+}
+
+type contactPoint struct {
+	OrgID int64
+	ngprovisioning.ContactPoint
+}
+
+type policy struct {
+	OrgID  int64
+	Policy definitions.Route
+}
+
+type muteTime struct {
+	OrgID int64
+	config.MuteTimeInterval
+}
+
+type template struct {
+	OrgID int64
+	ngprovisioning.MessageTemplate
+}
+
+// deleteByName identifies contact points, mute timings and templates to delete.
+type deleteByName struct {
+	OrgID int64
+	Name  string
+}
+
+// alertingAsConfigV1 is mapping for the version 1 configs. This is mapped to its normalised version.
+type alertingAsConfigV1 struct {
+	Groups              []*alertRuleGroupV1 `json:"groups" yaml:"groups"`
+	DeleteRules         []*deleteRuleV1     `json:"deleteRules" yaml:"deleteRules"`
+	ContactPoints       []*contactPointV1   `json:"contactPoints" yaml:"contactPoints"`
+	DeleteContactPoints []*deleteByNameV1   `json:"deleteContactPoints" yaml:"deleteContactPoints"`
+	Policies            []*policyV1         `json:"policies" yaml:"policies"`
+	ResetPolicies       []values.Int64Value `json:"resetPolicies" yaml:"resetPolicies"`
+	MuteTimes           []*muteTimeV1       `json:"muteTimes" yaml:"muteTimes"`
+	DeleteMuteTimes     []*deleteByNameV1   `json:"deleteMuteTimes" yaml:"deleteMuteTimes"`
+	Templates           []*templateV1       `json:"templates" yaml:"templates"`
+	DeleteTemplates     []*deleteByNameV1   `json:"deleteTemplates" yaml:"deleteTemplates"`
+}
+
+type alertRuleGroupV1 struct {
+	OrgID     values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name      values.StringValue `json:"name" yaml:"name"`
+	Folder    values.StringValue `json:"folder" yaml:"folder"`
+	FolderUID values.StringValue `json:"folderUid" yaml:"folderUid"`
+	Interval  values.StringValue `json:"interval" yaml:"interval"`
+	Rules     []*alertRuleV1     `json:"rules" yaml:"rules"`
+}
+
+// alertRuleV1 is an alert rule of a group. The query models, annotations and labels are not
+// interpolated, as they are templates that use '$' themselves.
+type alertRuleV1 struct {
+	UID          values.StringValue    `json:"uid" yaml:"uid"`
+	Title        values.StringValue    `json:"title" yaml:"title"`
+	Condition    values.StringValue    `json:"condition" yaml:"condition"`
+	Data         []*alertQueryV1       `json:"data" yaml:"data"`
+	DashboardUID values.StringValue    `json:"dashboardUid" yaml:"dashboardUid"`
+	PanelID      values.Int64Value     `json:"panelId" yaml:"panelId"`
+	NoDataState  values.StringValue    `json:"noDataState" yaml:"noDataState"`
+	ExecErrState values.StringValue    `json:"execErrState" yaml:"execErrState"`
+	For          values.StringValue    `json:"for" yaml:"for"`
+	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
+	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
+}
+
+type alertQueryV1 struct {
+	RefID             values.StringValue  `json:"refId" yaml:"refId"`
+	QueryType         values.StringValue  `json:"queryType" yaml:"queryType"`
+	RelativeTimeRange relativeTimeRangeV1 `json:"relativeTimeRange" yaml:"relativeTimeRange"`
+	DatasourceUID     values.StringValue  `json:"datasourceUid" yaml:"datasourceUid"`
+	Model             values.JSONValue    `json:"model" yaml:"model"`
+}
+
+type relativeTimeRangeV1 struct {
+	From values.StringValue `json:"from" yaml:"from"`
+	To   values.StringValue `json:"to" yaml:"to"`
+}
+
+type deleteRuleV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+type contactPointV1 struct {
+	OrgID     values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name      values.StringValue `json:"name" yaml:"name"`
+	Receivers []*receiverV1      `json:"receivers" yaml:"receivers"`
+}
+
+type receiverV1 struct {
+	UID                   values.StringValue    `json:"uid" yaml:"uid"`
+	Type                  values.StringValue    `json:"type" yaml:"type"`
+	Settings              values.JSONValue      `json:"settings" yaml:"settings"`
+	SecureSettings        values.StringMapValue `json:"secureSettings" yaml:"secureSettings"`
+	DisableResolveMessage values.BoolValue      `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+}
+
+// policyV1 is a notification policy tree, in the format of the Alertmanager configuration, with the
+// id of its organization.
+type policyV1 struct {
+	OrgID  values.Int64Value
+	Policy definitions.Route
+}
+
+func (p *policyV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var org struct {
+		OrgID values.Int64Value `yaml:"orgId"`
+	}
+	if err := unmarshal(&org); err != nil {
+		return err
+	}
+	p.OrgID = org.OrgID
+	return unmarshal(&p.Policy)
+}
+
+// muteTimeV1 is a mute time interval, in the format of the Alertmanager configuration, with the id
+// of its organization.
+type muteTimeV1 struct {
+	OrgID    values.Int64Value
+	MuteTime config.MuteTimeInterval
+}
+
+func (mt *muteTimeV1) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var org struct {
+		OrgID values.Int64Value `yaml:"orgId"`
+	}
+	if err := unmarshal(&org); err != nil {
+		return err
+	}
+	mt.OrgID = org.OrgID
+	return unmarshal(&mt.MuteTime)
+}
+
+// templateV1 is a notification template. Its content is not interpolated.
+type templateV1 struct {
+	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name     values.StringValue `json:"name" yaml:"name"`
+	Template string             `json:"template" yaml:"template"`
+}
+
+type deleteByNameV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name  values.StringValue `json:"name" yaml:"name"`
+}
+
+// mapToAlertingFromConfig maps config syntax to normalized alertingAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *alertingAsConfigV1) mapToAlertingFromConfig() (*alertingAsConfig, error) {
+	r := &alertingAsConfig{}
+	if cfg == nil {
+		return r, nil
+	}
+
+	for _, g := range cfg.Groups {
+		group, err := g.mapToModel()
+		if err != nil {
+			return nil, err
+		}
+		r.Groups = append(r.Groups, group)
+	}
+	for _, rule := range cfg.DeleteRules {
+		r.DeleteRules = append(r.DeleteRules, &deleteRule{OrgID: rule.OrgID.Value(), UID: rule.UID.Value()})
+	}
+
+	for _, cp := range cfg.ContactPoints {
+		integrations := make([]*definitions.PostableGrafanaReceiver, 0, len(cp.Receivers))
+		for _, receiver := range cp.Receivers {
+			integrations = append(integrations, &definitions.PostableGrafanaReceiver{
+				UID:                   receiver.UID.Value(),
+				Name:                  cp.Name.Value(),
+				Type:                  receiver.Type.Value(),
+				DisableResolveMessage: receiver.DisableResolveMessage.Value(),
+				Settings:              simplejson.NewFromAny(receiver.Settings.Value()),
+				SecureSettings:        receiver.SecureSettings.Value(),
+			})
+		}
+		r.ContactPoints = append(r.ContactPoints, &contactPoint{
+			OrgID:        cp.OrgID.Value(),
+			ContactPoint: ngprovisioning.ContactPoint{Name: cp.Name.Value(), Integrations: integrations},
+		})
+	}
+	r.DeleteContactPoints = mapToDeleteByName(cfg.DeleteContactPoints)
+
+	for _, p := range cfg.Policies {
+		r.Policies = append(r.Policies, &policy{OrgID: p.OrgID.Value(), Policy: p.Policy})
+	}
+	for _, orgID := range cfg.ResetPolicies {
+		r.ResetPolicies = append(r.ResetPolicies, orgID.Value())
+	}
+
+	for _, mt := range cfg.MuteTimes {
+		r.MuteTimes = append(r.MuteTimes, &muteTime{OrgID: mt.OrgID.Value(), MuteTimeInterval: mt.MuteTime})
+	}
+	r.DeleteMuteTimes = mapToDeleteByName(cfg.DeleteMuteTimes)
+
+	for _, t := range cfg.Templates {
+		r.Templates = append(r.Templates, &template{
+			OrgID:           t.OrgID.Value(),
+			MessageTemplate: ngprovisioning.MessageTemplate{Name: t.Name.Value(), Template: t.Template},
+		})
+	}
+	r.DeleteTemplates = mapToDeleteByName(cfg.DeleteTemplates)
+
+	return r, nil
+}
+
+func mapToDeleteByName(items []*deleteByNameV1) []*deleteByName {
+	var r []*deleteByName
+	for _, item := range items {
+		r = append(r, &deleteByName{OrgID: item.OrgID.Value(), Name: item.Name.Value()})
+	}
+	return r
+}
+
+func (g *alertRuleGroupV1) mapToModel() (*alertRuleGroup, error) {
+	group := &alertRuleGroup{
+		OrgID:     g.OrgID.Value(),
+		Name:      g.Name.Value(),
+		Folder:    g.Folder.Value(),
+		FolderUID: g.FolderUID.Value(),
+	}
+	interval, err := parseDuration(g.Interval.Value())
+	if err != nil {
+		return nil, fmt.Errorf("rule group %q has an invalid interval: %w", group.Name, err)
+	}
+	group.Interval = interval
+
+	for _, r := range g.Rules {
+		rule, err := r.mapToModel(group)
+		if err != nil {
+			return nil, fmt.Errorf("rule group %q: %w", group.Name, err)
+		}
+		group.Rules = append(group.Rules, rule)
+	}
+	return group, nil
+}
+
+func (r *alertRuleV1) mapToModel(group *alertRuleGroup) (ngmodels.AlertRule, error) {
+	rule := ngmodels.AlertRule{
+		OrgID:           group.OrgID,
+		UID:             r.UID.Value(),
+		Title:           r.Title.Value(),
+		Condition:       r.Condition.Value(),
+		RuleGroup:       group.Name,
+		IntervalSeconds: int64(group.Interval.Seconds()),
+		NoDataState:     ngmodels.NoData,
+		ExecErrState:    ngmodels.AlertingErrState,
+		Annotations:     r.Annotations.Raw,
+		Labels:          r.Labels.Raw,
+	}
+
+	var err error
+	if r.NoDataState.Value() != "" {
+		if rule.NoDataState, err = ngmodels.NoDataStateFromString(r.NoDataState.Value()); err != nil {
+			return rule, fmt.Errorf("rule %q: %w", rule.UID, err)
+		}
+	}
+	if r.ExecErrState.Value() != "" {
+		if rule.ExecErrState, err = ngmodels.ErrStateFromString(r.ExecErrState.Value()); err != nil {
+			return rule, fmt.Errorf("rule %q: %w", rule.UID, err)
+		}
+	}
+	if rule.For, err = parseDuration(r.For.Value()); err != nil {
+		return rule, fmt.Errorf("rule %q has an invalid for duration: %w", rule.UID, err)
+	}
+	if dashboardUID := r.DashboardUID.Value(); dashboardUID != "" {
+		rule.DashboardUID = &dashboardUID
+		if panelID := r.PanelID.Value(); panelID != 0 {
+			rule.PanelID = &panelID
+		}
+	}
+
+	for _, q := range r.Data {
+		query := ngmodels.AlertQuery{
+			RefID:         q.RefID.Value(),
+			QueryType:     q.QueryType.Value(),
+			DatasourceUID: q.DatasourceUID.Value(),
+		}
+		from, err := parseDuration(q.RelativeTimeRange.From.Value())
+		if err != nil {
+			return rule, fmt.Errorf("rule %q has an invalid relative time range: %w", rule.UID, err)
+		}
+		to, err := parseDuration(q.RelativeTimeRange.To.Value())
+		if err != nil {
+			return rule, fmt.Errorf("rule %q has an invalid relative time range: %w", rule.UID, err)
+		}
+		query.RelativeTimeRange = ngmodels.RelativeTimeRange{From: ngmodels.Duration(from), To: ngmodels.Duration(to)}
+		if query.Model, err = json.Marshal(q.Model.Raw); err != nil {
+			return rule, fmt.Errorf("rule %q has an invalid query model: %w", rule.UID, err)
+		}
+		rule.Data = append(rule.Data, query)
+	}
+	return rule, nil
+}
+
+// parseDuration parses durations like 5m or 1d, or a number of seconds. An empty string is no duration.
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := model.ParseDuration(s)
+	return time.Duration(d), err
+}
@@ -0,0 +1,138 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+type configReader struct {
+	orgStore utils.OrgStore
+	log      log.Logger
+}
+
+func (cr *configReader) readConfig(ctx context.Context, path string) ([]*liveAsConfig, error) {
+	var configs []*liveAsConfig
+	cr.log.Debug("Looking for Live provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read Live provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing Live provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseConfig(path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+			}
+
+			if cfg != nil {
+				configs = append(configs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating Live provisioning files")
+	if err := cr.validateRequiredFields(configs); err != nil {
+		return nil, err
+	}
+
+	if err := cr.checkOrgIDs(ctx, configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseConfig(path string, file os.FileInfo) (*liveAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiVersion *configVersion
+	if err := yaml.Unmarshal(yamlFile, &apiVersion); err != nil {
+		return nil, err
+	}
+	if apiVersion == nil || apiVersion.APIVersion != 1 {
+		return nil, fmt.Errorf("unsupported apiVersion, only apiVersion 1 is supported")
+	}
+
+	var cfg *liveAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToLiveFromConfig()
+}
+
+func (cr *configReader) validateRequiredFields(configs []*liveAsConfig) error {
+	var errStrings []string
+	for _, cfg := range configs {
+		for i, rule := range cfg.ChannelRules {
+			if rule.Pattern == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Channel rule %d in configuration doesn't contain required field pattern", i+1))
+			}
+		}
+		for i, rule := range cfg.DeleteChannelRules {
+			if rule.Pattern == "" {
+				errStrings = append(errStrings, fmt.Sprintf("Deleted channel rule %d in configuration doesn't contain required field pattern", i+1))
+			}
+		}
+	}
+
+	if len(errStrings) != 0 {
+		return errors.New(strings.Join(errStrings, "\n"))
+	}
+	return nil
+}
+
+// checkOrgIDs defaults the organization of the channel rules to the main organization, and checks
+// that the other organizations exist.
+func (cr *configReader) checkOrgIDs(ctx context.Context, configs []*liveAsConfig) error {
+	checked := map[int64]bool{}
+	check := func(orgID *int64) error {
+		if *orgID < 1 {
+			*orgID = 1
+			return nil
+		}
+		if checked[*orgID] {
+			return nil
+		}
+		if err := utils.CheckOrgExists(ctx, cr.orgStore, *orgID); err != nil {
+			return fmt.Errorf("failed to provision channel rules of organization %d: %w", *orgID, err)
+		}
+		checked[*orgID] = true
+		return nil
+	}
+
+	for _, cfg := range configs {
+		for _, rule := range cfg.ChannelRules {
+			if err := check(&rule.OrgID); err != nil {
+				return err
+			}
+		}
+		for _, rule := range cfg.DeleteChannelRules {
+			if err := check(&rule.OrgID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+var (
+	correctProperties  = "./testdata/test-configs/correct-properties"
+	noRequiredFields   = "./testdata/test-configs/no-required-fields"
+	unsupportedVersion = "./testdata/test-configs/unsupported-version"
+	unknownSettings    = "./testdata/test-configs/unknown-settings"
+)
+
+func setupOrgStore(t *testing.T) *sqlstore.SQLStore {
+	t.Helper()
+	sqlStore := sqlstore.InitTestDB(t)
+	for i := 1; i < 3; i++ {
+		orgCommand := models.CreateOrgCommand{Name: fmt.Sprintf("Main Org. %v", i)}
+		require.NoError(t, sqlstore.CreateOrg(context.Background(), &orgCommand))
+	}
+	return sqlStore
+}
+
+func TestLiveAsConfig(t *testing.T) {
+	t.Run("Can read correct properties", func(t *testing.T) {
+		cfgProvider := &configReader{orgStore: setupOrgStore(t), log: log.New("test logger")}
+
+		cfg, err := cfgProvider.readConfig(context.Background(), correctProperties)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+
+		require.Len(t, cfg[0].ChannelRules, 2)
+		rule := cfg[0].ChannelRules[0]
+		require.Equal(t, int64(2), rule.OrgID)
+		require.Equal(t, "stream/telegraf/:metric", rule.Pattern)
+		require.Equal(t, pipeline.ConverterTypeInfluxAuto, rule.Settings.Converter.Type)
+		require.Equal(t, "labels_column", rule.Settings.Converter.AutoInfluxConverterConfig.FrameFormat)
+		require.Len(t, rule.Settings.FrameOutputters, 1)
+		require.Equal(t, pipeline.FrameOutputTypeManagedStream, rule.Settings.FrameOutputters[0].Type)
+		require.Equal(t, int64(1), cfg[0].ChannelRules[1].OrgID)
+
+		require.Len(t, cfg[0].DeleteChannelRules, 1)
+		require.Equal(t, int64(1), cfg[0].DeleteChannelRules[0].OrgID)
+		require.Equal(t, "stream/old", cfg[0].DeleteChannelRules[0].Pattern)
+	})
+
+	t.Run("Should fail without required fields", func(t *testing.T) {
+		cfgProvider := &configReader{orgStore: setupOrgStore(t), log: log.New("test logger")}
+
+		_, err := cfgProvider.readConfig(context.Background(), noRequiredFields)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Channel rule 1 in configuration doesn't contain required field pattern")
+		require.Contains(t, err.Error(), "Deleted channel rule 1 in configuration doesn't contain required field pattern")
+	})
+
+	t.Run("Should fail with unsupported version", func(t *testing.T) {
+		cfgProvider := &configReader{orgStore: setupOrgStore(t), log: log.New("test logger")}
+
+		_, err := cfgProvider.readConfig(context.Background(), unsupportedVersion)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported apiVersion")
+	})
+
+	t.Run("Should fail with unknown settings", func(t *testing.T) {
+		cfgProvider := &configReader{orgStore: setupOrgStore(t), log: log.New("test logger")}
+
+		_, err := cfgProvider.readConfig(context.Background(), unknownSettings)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "convertor")
+	})
+}
+
+type fakeStore struct {
+	rules   map[int64]map[string]pipeline.ChannelRule
+	updates int
+}
+
+func (s *fakeStore) ListChannelRules(_ context.Context, orgID int64) ([]pipeline.ChannelRule, error) {
+	var rules []pipeline.ChannelRule
+	for _, r := range s.rules[orgID] {
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func (s *fakeStore) UpdateChannelRule(_ context.Context, orgID int64, cmd pipeline.ChannelRuleUpdateCmd) (pipeline.ChannelRule, error) {
+	if s.rules[orgID] == nil {
+		s.rules[orgID] = map[string]pipeline.ChannelRule{}
+	}
+	rule := pipeline.ChannelRule{OrgId: orgID, Pattern: cmd.Pattern, Settings: cmd.Settings}
+	s.rules[orgID][cmd.Pattern] = rule
+	s.updates++
+	return rule, nil
+}
+
+func (s *fakeStore) DeleteChannelRule(_ context.Context, orgID int64, cmd pipeline.ChannelRuleDeleteCmd) error {
+	if _, ok := s.rules[orgID][cmd.Pattern]; !ok {
+		return pipeline.ErrChannelRuleNotFound
+	}
+	delete(s.rules[orgID], cmd.Pattern)
+	return nil
+}
+
+func TestProvision(t *testing.T) {
+	store := &fakeStore{rules: map[int64]map[string]pipeline.ChannelRule{
+		1: {"stream/old": {OrgId: 1, Pattern: "stream/old"}},
+	}}
+	orgStore := setupOrgStore(t)
+
+	require.NoError(t, Provision(context.Background(), correctProperties, orgStore, store))
+	require.Len(t, store.rules[1], 1)
+	require.Contains(t, store.rules[1], "stream/json/cpu")
+	require.Contains(t, store.rules[2], "stream/telegraf/:metric")
+	require.Equal(t, 2, store.updates)
+
+	// provisioning again does not change anything, and deleting missing rules is not an error
+	require.NoError(t, Provision(context.Background(), correctProperties, orgStore, store))
+	require.Equal(t, 2, store.updates)
+}
@@ -0,0 +1,104 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// Store is the storage of the Live pipeline the channel rules are provisioned to.
+type Store interface {
+	ListChannelRules(ctx context.Context, orgID int64) ([]pipeline.ChannelRule, error)
+	UpdateChannelRule(ctx context.Context, orgID int64, cmd pipeline.ChannelRuleUpdateCmd) (pipeline.ChannelRule, error)
+	DeleteChannelRule(ctx context.Context, orgID int64, cmd pipeline.ChannelRuleDeleteCmd) error
+}
+
+// Provision provisions the channel rules of the Live pipeline.
+func Provision(ctx context.Context, configDirectory string, orgStore utils.OrgStore, store Store) error {
+	lp := newLiveProvisioner(orgStore, store, log.New("provisioning.live"))
+	return lp.applyChanges(ctx, configDirectory)
+}
+
+// LiveProvisioner is responsible for provisioning the channel rules of the Live pipeline.
+type LiveProvisioner struct {
+	log         log.Logger
+	cfgProvider *configReader
+	store       Store
+}
+
+func newLiveProvisioner(orgStore utils.OrgStore, store Store, log log.Logger) LiveProvisioner {
+	return LiveProvisioner{
+		log:         log,
+		cfgProvider: &configReader{orgStore: orgStore, log: log},
+		store:       store,
+	}
+}
+
+func (lp *LiveProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := lp.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
+	// the deletions of all files are applied first, so a pattern can be moved between files
+	for _, cfg := range configs {
+		if err := lp.deleteChannelRules(ctx, cfg); err != nil {
+			return err
+		}
+	}
+	for _, cfg := range configs {
+		if err := lp.applyChannelRules(ctx, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (lp *LiveProvisioner) deleteChannelRules(ctx context.Context, cfg *liveAsConfig) error {
+	for _, rule := range cfg.DeleteChannelRules {
+		lp.log.Info("Deleting channel rule", "org", rule.OrgID, "pattern", rule.Pattern)
+		err := lp.store.DeleteChannelRule(ctx, rule.OrgID, pipeline.ChannelRuleDeleteCmd{Pattern: rule.Pattern})
+		if err != nil && !errors.Is(err, pipeline.ErrChannelRuleNotFound) {
+			return fmt.Errorf("failed to delete channel rule %q: %w", rule.Pattern, err)
+		}
+	}
+	return nil
+}
+
+func (lp *LiveProvisioner) applyChannelRules(ctx context.Context, cfg *liveAsConfig) error {
+	existing := map[int64]map[string]pipeline.ChannelRule{}
+	for _, rule := range cfg.ChannelRules {
+		orgRules, ok := existing[rule.OrgID]
+		if !ok {
+			rules, err := lp.store.ListChannelRules(ctx, rule.OrgID)
+			if err != nil {
+				return fmt.Errorf("failed to get the channel rules of organization %d: %w", rule.OrgID, err)
+			}
+			orgRules = make(map[string]pipeline.ChannelRule, len(rules))
+			for _, r := range rules {
+				orgRules[r.Pattern] = r
+			}
+			existing[rule.OrgID] = orgRules
+		}
+
+		// unchanged rules are not stored again, so all Grafana instances do not rebuild them
+		if current, ok := orgRules[rule.Pattern]; ok && reflect.DeepEqual(current.Settings, rule.Settings) {
+			continue
+		}
+
+		lp.log.Info("Provisioning channel rule", "org", rule.OrgID, "pattern", rule.Pattern)
+		_, err := lp.store.UpdateChannelRule(ctx, rule.OrgID, pipeline.ChannelRuleUpdateCmd{
+			Pattern:  rule.Pattern,
+			Settings: rule.Settings,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to provision channel rule %q: %w", rule.Pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package live
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// configVersion is used to figure out which API version a config uses.
+type configVersion struct {
+	APIVersion int64 `json:"apiVersion" yaml:"apiVersion"`
+}
+
+// liveAsConfig is normalized data object for the Live config data. Any config version should be
+// mappable to this type.
+type liveAsConfig struct {
+	ChannelRules       []*channelRule
+	DeleteChannelRules []*deleteChannelRule
+}
+
+type channelRule struct {
+	OrgID    int64
+	Pattern  string
+	Settings pipeline.ChannelRuleSettings
+}
+
+type deleteChannelRule struct {
+	OrgID   int64
+	Pattern string
+}
+
+// liveAsConfigV1 is mapping for the version 1 configs. This is mapped to its normalised version.
+type liveAsConfigV1 struct {
+	ChannelRules       []*channelRuleV1       `json:"channelRules" yaml:"channelRules"`
+	DeleteChannelRules []*deleteChannelRuleV1 `json:"deleteChannelRules" yaml:"deleteChannelRules"`
+}
+
+type channelRuleV1 struct {
+	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Pattern  values.StringValue `json:"pattern" yaml:"pattern"`
+	Settings values.JSONValue   `json:"settings" yaml:"settings"`
+}
+
+type deleteChannelRuleV1 struct {
+	OrgID   values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Pattern values.StringValue `json:"pattern" yaml:"pattern"`
+}
+
+// mapToLiveFromConfig maps config syntax to normalized liveAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *liveAsConfigV1) mapToLiveFromConfig() (*liveAsConfig, error) {
+	r := &liveAsConfig{}
+	if cfg == nil {
+		return r, nil
+	}
+
+	for _, rule := range cfg.ChannelRules {
+		settings, err := channelRuleSettings(rule.Settings.Value())
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings of channel rule %q: %w", rule.Pattern.Value(), err)
+		}
+		r.ChannelRules = append(r.ChannelRules, &channelRule{
+			OrgID:    rule.OrgID.Value(),
+			Pattern:  rule.Pattern.Value(),
+			Settings: settings,
+		})
+	}
+	for _, rule := range cfg.DeleteChannelRules {
+		r.DeleteChannelRules = append(r.DeleteChannelRules, &deleteChannelRule{
+			OrgID:   rule.OrgID.Value(),
+			Pattern: rule.Pattern.Value(),
+		})
+	}
+	return r, nil
+}
+
+// channelRuleSettings decodes the settings of a channel rule the same way as the HTTP API, so they
+// use the same field names. Unknown fields are rejected to catch typos in the config files.
+func channelRuleSettings(value map[string]interface{}) (pipeline.ChannelRuleSettings, error) {
+	var settings pipeline.ChannelRuleSettings
+	if len(value) == 0 {
+		return settings, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return settings, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&settings); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
@@ -12,13 +12,19 @@ import (
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/live"
+	ngprovisioning "github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/pluginsettings"
+	alertingprovisioning "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	liveprovisioning "github.com/grafana/grafana/pkg/services/provisioning/live"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -29,6 +35,7 @@ func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginStore p
 	dashboardService dashboardservice.DashboardProvisioningService,
 	datasourceService datasourceservice.DataSourceService,
 	alertingService *alerting.AlertNotificationService, pluginSettings pluginsettings.Service,
+	secretsService secrets.Service, grafanaLive *live.GrafanaLive,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                     cfg,
@@ -36,11 +43,15 @@ func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginStore p
 		pluginStore:             pluginStore,
 		EncryptionService:       encryptionService,
 		NotificationService:     notificatonService,
+		SecretsService:          secretsService,
+		GrafanaLive:             grafanaLive,
 		log:                     log.New("provisioning"),
 		newDashboardProvisioner: dashboards.New,
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alertingprovisioning.Provision,
+		provisionLive:           liveprovisioning.Provision,
 		dashboardService:        dashboardService,
 		datasourceService:       datasourceService,
 		alertingService:         alertingService,
@@ -55,6 +66,8 @@ type ProvisioningService interface {
 	ProvisionDatasources(ctx context.Context) error
 	ProvisionPlugins(ctx context.Context) error
 	ProvisionNotifications(ctx context.Context) error
+	ProvisionAlerting(ctx context.Context) error
+	ProvisionLive(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
@@ -68,6 +81,8 @@ func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alertingprovisioning.Provision,
+		provisionLive:           liveprovisioning.Provision,
 	}
 }
 
@@ -77,6 +92,8 @@ func newProvisioningServiceImpl(
 	provisionNotifiers func(context.Context, string, notifiers.Manager, notifiers.SQLStore, encryption.Internal, *notifications.NotificationService) error,
 	provisionDatasources func(context.Context, string, datasources.Store, utils.OrgStore) error,
 	provisionPlugins func(context.Context, string, plugins.Store, plugifaces.Store, pluginsettings.Service) error,
+	provisionAlerting func(context.Context, string, alertingprovisioning.SQLStore, dashboardservice.DashboardProvisioningService, alertingprovisioning.Services) error,
+	provisionLive func(context.Context, string, utils.OrgStore, liveprovisioning.Store) error,
 ) *ProvisioningServiceImpl {
 	return &ProvisioningServiceImpl{
 		log:                     log.New("provisioning"),
@@ -84,6 +101,8 @@ func newProvisioningServiceImpl(
 		provisionNotifiers:      provisionNotifiers,
 		provisionDatasources:    provisionDatasources,
 		provisionPlugins:        provisionPlugins,
+		provisionAlerting:       provisionAlerting,
+		provisionLive:           provisionLive,
 	}
 }
 
@@ -93,6 +112,8 @@ type ProvisioningServiceImpl struct {
 	pluginStore             plugifaces.Store
 	EncryptionService       encryption.Internal
 	NotificationService     *notifications.NotificationService
+	SecretsService          secrets.Service
+	GrafanaLive             *live.GrafanaLive
 	log                     log.Logger
 	pollingCtxCancel        context.CancelFunc
 	newDashboardProvisioner dashboards.DashboardProvisionerFactory
@@ -100,6 +121,8 @@ type ProvisioningServiceImpl struct {
 	provisionNotifiers      func(context.Context, string, notifiers.Manager, notifiers.SQLStore, encryption.Internal, *notifications.NotificationService) error
 	provisionDatasources    func(context.Context, string, datasources.Store, utils.OrgStore) error
 	provisionPlugins        func(context.Context, string, plugins.Store, plugifaces.Store, pluginsettings.Service) error
+	provisionAlerting       func(context.Context, string, alertingprovisioning.SQLStore, dashboardservice.DashboardProvisioningService, alertingprovisioning.Services) error
+	provisionLive           func(context.Context, string, utils.OrgStore, liveprovisioning.Store) error
 	mutex                   sync.Mutex
 	dashboardService        dashboardservice.DashboardProvisioningService
 	datasourceService       datasourceservice.DataSourceService
@@ -123,6 +146,16 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionAlerting(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = ps.ProvisionLive(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -185,6 +218,51 @@ func (ps *ProvisioningServiceImpl) ProvisionNotifications(ctx context.Context) e
 	return nil
 }
 
+// ProvisionAlerting provisions the resources of unified alerting, when it is enabled.
+func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
+	if !ps.Cfg.UnifiedAlerting.IsEnabled() {
+		return nil
+	}
+
+	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	logger := log.New("provisioning.alerting")
+	st := &store.DBstore{
+		BaseInterval:    ps.Cfg.UnifiedAlerting.BaseInterval,
+		DefaultInterval: ps.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval,
+		SQLStore:        ps.SQLStore,
+		Logger:          logger,
+	}
+	defaultConfig := ps.Cfg.UnifiedAlerting.DefaultConfiguration
+	services := alertingprovisioning.Services{
+		AlertRules:           ngprovisioning.NewAlertRuleService(st, st, ps.SQLStore, st.DefaultInterval, logger),
+		ContactPoints:        ngprovisioning.NewContactPointService(st, defaultConfig, st, ps.SQLStore, ps.SecretsService, logger),
+		NotificationPolicies: ngprovisioning.NewNotificationPolicyService(st, defaultConfig, st, ps.SQLStore, logger),
+		MuteTimings:          ngprovisioning.NewMuteTimingService(st, defaultConfig, st, ps.SQLStore, logger),
+		Templates:            ngprovisioning.NewTemplateService(st, defaultConfig, st, ps.SQLStore, logger),
+	}
+	if err := ps.provisionAlerting(ctx, alertingPath, ps.SQLStore, ps.dashboardService, services); err != nil {
+		err = errutil.Wrap("Alerting provisioning error", err)
+		ps.log.Error("Failed to provision alerting", "error", err)
+		return err
+	}
+	return nil
+}
+
+// ProvisionLive provisions the channel rules of the Live pipeline, when it is enabled.
+func (ps *ProvisioningServiceImpl) ProvisionLive(ctx context.Context) error {
+	if ps.GrafanaLive == nil || ps.GrafanaLive.PipelineStorage() == nil {
+		return nil
+	}
+
+	livePath := filepath.Join(ps.Cfg.ProvisioningPath, "live")
+	if err := ps.provisionLive(ctx, livePath, ps.SQLStore, ps.GrafanaLive.PipelineStorage()); err != nil {
+		err = errutil.Wrap("Live provisioning error", err)
+		ps.log.Error("Failed to provision Live channel rules", "error", err)
+		return err
+	}
+	return nil
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.dashboardService, ps.SQLStore)
@@ -7,6 +7,8 @@ type Calls struct {
 	ProvisionDatasources                []interface{}
 	ProvisionPlugins                    []interface{}
 	ProvisionNotifications              []interface{}
+	ProvisionAlerting                   []interface{}
+	ProvisionLive                       []interface{}
 	ProvisionDashboards                 []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
@@ -19,6 +21,8 @@ type ProvisioningServiceMock struct {
 	ProvisionDatasourcesFunc                func(ctx context.Context) error
 	ProvisionPluginsFunc                    func() error
 	ProvisionNotificationsFunc              func() error
+	ProvisionAlertingFunc                   func() error
+	ProvisionLiveFunc                       func() error
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
@@ -63,6 +67,22 @@ func (mock *ProvisioningServiceMock) ProvisionNotifications(ctx context.Context)
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) error {
+	mock.Calls.ProvisionAlerting = append(mock.Calls.ProvisionAlerting, nil)
+	if mock.ProvisionAlertingFunc != nil {
+		return mock.ProvisionAlertingFunc()
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) ProvisionLive(ctx context.Context) error {
+	mock.Calls.ProvisionLive = append(mock.Calls.ProvisionLive, nil)
+	if mock.ProvisionLiveFunc != nil {
+		return mock.ProvisionLiveFunc()
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) ProvisionDashboards(ctx context.Context) error {
 	mock.Calls.ProvisionDashboards = append(mock.Calls.ProvisionDashboards, nil)
 	if mock.ProvisionDashboardsFunc != nil {
@@ -99,6 +99,8 @@ func setup() *serviceTestStruct {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 	)
 	serviceTest.service.Cfg = setting.NewCfg()
 
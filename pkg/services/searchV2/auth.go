@@ -4,6 +4,9 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	ngapi "github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/permissions"
 )
@@ -13,32 +16,70 @@ type ResourceFilter func(uid string) bool
 
 // FutureAuthService eventually implemented by the security service
 type FutureAuthService interface {
-	GetDashboardReadFilter(user *models.SignedInUser) (ResourceFilter, error)
+	GetDashboardReadFilter(ctx context.Context, user *models.SignedInUser) (ResourceFilter, error)
 }
 
 type simpleSQLAuthService struct {
 	sql *sqlstore.SQLStore
+	ac  accesscontrol.AccessControl
+}
+
+// sqlFilter is a permission filter on the dashboard table.
+type sqlFilter interface {
+	Where() (string, []interface{})
 }
 
 type dashIdQueryResult struct {
 	UID string `xorm:"uid"`
 }
 
-func (a *simpleSQLAuthService) GetDashboardReadFilter(user *models.SignedInUser) (ResourceFilter, error) {
-	// this filter works on the legacy `dashboard_acl` table
-	// we will also need to use `accesscontrol` after https://github.com/grafana/grafana/pull/44702/files is merged
-	// see https://github.com/grafana/grafana/blob/e355bd6d3a04111b8c9959f85e81beabbeb746bf/pkg/services/sqlstore/permissions/dashboard.go#L84
-	filter := permissions.DashboardPermissionFilter{
-		OrgRole:         user.OrgRole,
-		OrgId:           user.OrgId,
-		Dialect:         a.sql.Dialect,
-		UserId:          user.UserId,
+// loadPermissions sets the permissions of the user in its organization, when they are not
+// loaded yet.
+func loadPermissions(ctx context.Context, ac accesscontrol.AccessControl, user *models.SignedInUser) error {
+	if user.Permissions[user.OrgId] != nil {
+		return nil
+	}
+	perms, err := ac.GetUserPermissions(ctx, user, accesscontrol.Options{ReloadCache: false})
+	if err != nil {
+		return err
+	}
+	if user.Permissions == nil {
+		user.Permissions = make(map[int64]map[string][]string)
+	}
+	user.Permissions[user.OrgId] = accesscontrol.GroupScopesByAction(perms)
+	return nil
+}
+
+func (a *simpleSQLAuthService) getDashboardFilter(ctx context.Context, user *models.SignedInUser) (sqlFilter, error) {
+	if a.ac == nil || a.ac.IsDisabled() {
+		// this filter works on the legacy `dashboard_acl` table
+		return permissions.DashboardPermissionFilter{
+			OrgRole:         user.OrgRole,
+			OrgId:           user.OrgId,
+			Dialect:         a.sql.Dialect,
+			UserId:          user.UserId,
+			PermissionLevel: models.PERMISSION_VIEW,
+		}, nil
+	}
+
+	if err := loadPermissions(ctx, a.ac, user); err != nil {
+		return nil, err
+	}
+	return permissions.AccessControlDashboardPermissionFilter{
+		User:            user,
 		PermissionLevel: models.PERMISSION_VIEW,
+	}, nil
+}
+
+func (a *simpleSQLAuthService) GetDashboardReadFilter(ctx context.Context, user *models.SignedInUser) (ResourceFilter, error) {
+	filter, err := a.getDashboardFilter(ctx, user)
+	if err != nil {
+		return nil, err
 	}
 
 	rows := make([]*dashIdQueryResult, 0)
 
-	err := a.sql.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+	err = a.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		sql, params := filter.Where()
 		sess.Table("dashboard").
 			Where(sql, params...).
@@ -65,3 +106,49 @@ func (a *simpleSQLAuthService) GetDashboardReadFilter(user *models.SignedInUser)
 		return uids[uid]
 	}, err
 }
+
+// hitFilter checks if the user can read a search hit.
+type hitFilter func(hit searchHit) bool
+
+// getHitFilter returns the filter of the hits of all kinds. Dashboards, folders and panels
+// need the dashboard to be readable, and library panels and alert rules their folder.
+// Datasources are checked like in the datasources API, and playlists have no permissions.
+func (s *StandardSearchService) getHitFilter(ctx context.Context, user *models.SignedInUser) (hitFilter, error) {
+	dashboards, err := s.auth.GetDashboardReadFilter(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	acEnabled := s.ac != nil && !s.ac.IsDisabled()
+	if acEnabled {
+		if err := loadPermissions(ctx, s.ac, user); err != nil {
+			return nil, err
+		}
+	}
+	evaluate := func(evaluator accesscontrol.Evaluator) bool {
+		ok, err := evaluator.Evaluate(user.Permissions[user.OrgId])
+		return err == nil && ok
+	}
+
+	canReadRules := !acEnabled || evaluate(accesscontrol.EvalPermission(ngapi.ActionAlertingRuleRead))
+
+	return func(hit searchHit) bool {
+		switch hit.kind {
+		case kindDashboard, kindFolder, kindPanel:
+			return dashboards(hit.meta.dash.UID)
+		case kindLibraryPanel:
+			return hit.entity.FolderUID == "" || dashboards(hit.entity.FolderUID)
+		case kindAlertRule:
+			return canReadRules && dashboards(hit.entity.FolderUID)
+		case kindDatasource:
+			if !acEnabled {
+				return user.OrgRole == models.ROLE_ADMIN
+			}
+			return evaluate(accesscontrol.EvalPermission(datasources.ActionDatasourcesRead,
+				datasources.ScopeDatasourcesProvider.GetResourceScopeUID(hit.entity.UID)))
+		case kindPlaylist:
+			return true
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,371 @@
+package searchV2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/searchV2/extract"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	kindLibraryPanel = "librarypanel"
+	kindAlertRule    = "alertrule"
+	kindDatasource   = "datasource"
+	kindPlaylist     = "playlist"
+
+	// libraryPanelKind is the kind of the library elements that are panels
+	libraryPanelKind = 1
+
+	// expressionDatasourceUID is the datasource uid of the server side expressions of alert rules
+	expressionDatasourceUID = "-100"
+
+	// uidBatchSize is the maximum number of uids in the IN clause of a query, which stays below
+	// the bind variable limits of the databases
+	uidBatchSize = 500
+)
+
+// entityMeta is an indexed entity that is not a dashboard or a folder. It is stored in the index
+// as JSON, like storedMeta.
+type entityMeta struct {
+	Kind        string `json:"kind"`
+	ID          int64  `json:"id"`
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	// FolderID and FolderUID are the folder of library panels, and the namespace of alert rules
+	FolderID       int64    `json:"folderId,omitempty"`
+	FolderUID      string   `json:"folderUid,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	PanelType      string   `json:"panelType,omitempty"`
+	Datasource     []string `json:"datasource,omitempty"`
+	DatasourceType []string `json:"datasourceType,omitempty"`
+	Queries        []string `json:"queries,omitempty"`
+	// Version changes on every update of the entity, it is compared with the database to find
+	// the entities that changed
+	Version string `json:"version"`
+}
+
+// entityVersion is the version of an entity in the database.
+type entityVersion struct {
+	OrgID   int64
+	UID     string
+	Version string
+}
+
+// entityLoader reads the entities of a kind from the database.
+type entityLoader struct {
+	kind string
+	// versions returns the versions of the entities of all organizations
+	versions func(sess *sqlstore.DBSession) ([]entityVersion, error)
+	// load returns the entities of an organization with the given uids
+	load func(sess *sqlstore.DBSession, orgID int64, uids []string) ([]*entityMeta, error)
+}
+
+var entityLoaders = []entityLoader{
+	{kind: kindLibraryPanel, versions: libraryPanelVersions, load: loadLibraryPanels},
+	{kind: kindAlertRule, versions: alertRuleVersions, load: loadAlertRules},
+	{kind: kindDatasource, versions: datasourceVersions, load: loadDatasources},
+	{kind: kindPlaylist, versions: playlistVersions, load: loadPlaylists},
+}
+
+// isEntityKind returns true for the kinds of entityMeta.
+func isEntityKind(kind string) bool {
+	for _, loader := range entityLoaders {
+		if loader.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type versionQueryResult struct {
+	OrgID   int64  `xorm:"org_id"`
+	UID     string `xorm:"uid"`
+	Version int64  `xorm:"version"`
+}
+
+func numericVersions(sess *sqlstore.DBSession, table string, where string, args ...interface{}) ([]entityVersion, error) {
+	rows := make([]*versionQueryResult, 0)
+	sess.Table(table).Cols("org_id", "uid", "version")
+	if where != "" {
+		sess.Where(where, args...)
+	}
+	if err := sess.Find(&rows); err != nil {
+		return nil, err
+	}
+
+	versions := make([]entityVersion, 0, len(rows))
+	for _, row := range rows {
+		versions = append(versions, entityVersion{OrgID: row.OrgID, UID: row.UID, Version: strconv.FormatInt(row.Version, 10)})
+	}
+	return versions, nil
+}
+
+func libraryPanelVersions(sess *sqlstore.DBSession) ([]entityVersion, error) {
+	return numericVersions(sess, "library_element", "kind = ?", libraryPanelKind)
+}
+
+type libraryPanelQueryResult struct {
+	ID          int64  `xorm:"id"`
+	UID         string `xorm:"uid"`
+	Name        string `xorm:"name"`
+	Type        string `xorm:"type"`
+	Description string `xorm:"description"`
+	Model       []byte `xorm:"model"`
+	Version     int64  `xorm:"version"`
+	FolderID    int64  `xorm:"folder_id"`
+	FolderUID   string `xorm:"folder_uid"`
+}
+
+func loadLibraryPanels(sess *sqlstore.DBSession, orgID int64, uids []string) ([]*entityMeta, error) {
+	lookup, err := loadDatasourceLookup(sess, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*libraryPanelQueryResult, 0)
+	err = inBatches(uids, func(batch []string) error {
+		return sess.SQL(`SELECT le.id, le.uid, le.name, le.type, le.description, le.model, le.version, le.folder_id, d.uid AS folder_uid
+			FROM library_element AS le
+			LEFT JOIN dashboard AS d ON d.id = le.folder_id AND d.org_id = le.org_id
+			WHERE le.org_id = ? AND le.kind = ? AND le.uid IN (?`+strings.Repeat(",?", len(batch)-1)+`)`,
+			append([]interface{}{orgID, libraryPanelKind}, stringArgs(batch)...)...).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*entityMeta, 0, len(rows))
+	for _, row := range rows {
+		panel := extract.ReadPanel(bytes.NewReader(row.Model), lookup)
+		entities = append(entities, &entityMeta{
+			Kind:           kindLibraryPanel,
+			ID:             row.ID,
+			UID:            row.UID,
+			Title:          row.Name,
+			Description:    row.Description,
+			URL:            "/library-panels",
+			FolderID:       row.FolderID,
+			FolderUID:      row.FolderUID,
+			PanelType:      row.Type,
+			Datasource:     panel.Datasource,
+			DatasourceType: panel.DatasourceType,
+			Queries:        panel.Queries,
+			Version:        strconv.FormatInt(row.Version, 10),
+		})
+	}
+	return entities, nil
+}
+
+func alertRuleVersions(sess *sqlstore.DBSession) ([]entityVersion, error) {
+	return numericVersions(sess, "alert_rule", "")
+}
+
+type alertRuleQueryResult struct {
+	ID           int64  `xorm:"id"`
+	UID          string `xorm:"uid"`
+	Title        string `xorm:"title"`
+	NamespaceUID string `xorm:"namespace_uid"`
+	Data         string `xorm:"data"`
+	Labels       string `xorm:"labels"`
+	Annotations  string `xorm:"annotations"`
+	Version      int64  `xorm:"version"`
+	FolderID     int64  `xorm:"folder_id"`
+}
+
+// alertQuery is the part of the queries of alert rules that is indexed.
+type alertQuery struct {
+	DatasourceUID string                 `json:"datasourceUid"`
+	Model         map[string]interface{} `json:"model"`
+}
+
+func loadAlertRules(sess *sqlstore.DBSession, orgID int64, uids []string) ([]*entityMeta, error) {
+	lookup, err := loadDatasourceLookup(sess, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*alertRuleQueryResult, 0)
+	err = inBatches(uids, func(batch []string) error {
+		return sess.SQL(`SELECT ar.id, ar.uid, ar.title, ar.namespace_uid, ar.data, ar.labels, ar.annotations, ar.version, d.id AS folder_id
+			FROM alert_rule AS ar
+			LEFT JOIN dashboard AS d ON d.uid = ar.namespace_uid AND d.org_id = ar.org_id
+			WHERE ar.org_id = ? AND ar.uid IN (?`+strings.Repeat(",?", len(batch)-1)+`)`,
+			append([]interface{}{orgID}, stringArgs(batch)...)...).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*entityMeta, 0, len(rows))
+	for _, row := range rows {
+		rule := &entityMeta{
+			Kind:      kindAlertRule,
+			ID:        row.ID,
+			UID:       row.UID,
+			Title:     row.Title,
+			URL:       fmt.Sprintf("/alerting/grafana/%s/view", row.UID),
+			FolderID:  row.FolderID,
+			FolderUID: row.NamespaceUID,
+			Version:   strconv.FormatInt(row.Version, 10),
+		}
+
+		var queries []alertQuery
+		if err := json.Unmarshal([]byte(row.Data), &queries); err == nil {
+			for _, q := range queries {
+				if q.DatasourceUID == expressionDatasourceUID {
+					continue
+				}
+				rule.Datasource = appendUnique(rule.Datasource, q.DatasourceUID)
+				if ds := lookup(q.DatasourceUID); ds != nil {
+					rule.DatasourceType = appendUnique(rule.DatasourceType, ds.Type)
+				}
+				rule.Queries = append(rule.Queries, extract.QueryText(q.Model)...)
+			}
+		}
+
+		// labels are indexed as tags, such as severity=critical
+		labels := make(map[string]string)
+		if err := json.Unmarshal([]byte(row.Labels), &labels); err == nil {
+			for k, v := range labels {
+				rule.Tags = append(rule.Tags, k+"="+v)
+			}
+			sort.Strings(rule.Tags)
+		}
+
+		annotations := make(map[string]string)
+		if err := json.Unmarshal([]byte(row.Annotations), &annotations); err == nil {
+			rule.Description = strings.TrimSpace(annotations["summary"] + " " + annotations["description"])
+		}
+
+		entities = append(entities, rule)
+	}
+	return entities, nil
+}
+
+func datasourceVersions(sess *sqlstore.DBSession) ([]entityVersion, error) {
+	return numericVersions(sess, "data_source", "")
+}
+
+type datasourceEntityQueryResult struct {
+	ID      int64  `xorm:"id"`
+	UID     string `xorm:"uid"`
+	Name    string `xorm:"name"`
+	Type    string `xorm:"type"`
+	Version int64  `xorm:"version"`
+}
+
+func loadDatasources(sess *sqlstore.DBSession, orgID int64, uids []string) ([]*entityMeta, error) {
+	rows := make([]*datasourceEntityQueryResult, 0)
+	err := inBatches(uids, func(batch []string) error {
+		return sess.Table("data_source").
+			Where("org_id = ?", orgID).
+			In("uid", stringArgs(batch)...).
+			Cols("id", "uid", "name", "type", "version").
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*entityMeta, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, &entityMeta{
+			Kind:           kindDatasource,
+			ID:             row.ID,
+			UID:            row.UID,
+			Title:          row.Name,
+			URL:            fmt.Sprintf("/datasources/edit/%d", row.ID),
+			Datasource:     []string{row.UID},
+			DatasourceType: []string{row.Type},
+			Version:        strconv.FormatInt(row.Version, 10),
+		})
+	}
+	return entities, nil
+}
+
+type playlistQueryResult struct {
+	ID       int64  `xorm:"id"`
+	OrgID    int64  `xorm:"org_id"`
+	Name     string `xorm:"name"`
+	Interval string `xorm:"interval"`
+}
+
+// playlistVersion is the version of a playlist, which has no version or updated column.
+func playlistVersion(row *playlistQueryResult) string {
+	return row.Name + "/" + row.Interval
+}
+
+func playlistVersions(sess *sqlstore.DBSession) ([]entityVersion, error) {
+	rows := make([]*playlistQueryResult, 0)
+	if err := sess.Table("playlist").Cols("id", "org_id", "name", "interval").Find(&rows); err != nil {
+		return nil, err
+	}
+
+	versions := make([]entityVersion, 0, len(rows))
+	for _, row := range rows {
+		versions = append(versions, entityVersion{OrgID: row.OrgID, UID: strconv.FormatInt(row.ID, 10), Version: playlistVersion(row)})
+	}
+	return versions, nil
+}
+
+func loadPlaylists(sess *sqlstore.DBSession, orgID int64, uids []string) ([]*entityMeta, error) {
+	rows := make([]*playlistQueryResult, 0)
+	err := inBatches(uids, func(batch []string) error {
+		ids := make([]interface{}, 0, len(batch))
+		for _, uid := range batch {
+			if id, err := strconv.ParseInt(uid, 10, 64); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		return sess.Table("playlist").
+			Where("org_id = ?", orgID).
+			In("id", ids...).
+			Cols("id", "org_id", "name", "interval").
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*entityMeta, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, &entityMeta{
+			Kind:    kindPlaylist,
+			ID:      row.ID,
+			UID:     strconv.FormatInt(row.ID, 10),
+			Title:   row.Name,
+			URL:     fmt.Sprintf("/playlists/play/%d", row.ID),
+			Version: playlistVersion(row),
+		})
+	}
+	return entities, nil
+}
+
+// inBatches calls fn with the uids split in batches of at most uidBatchSize uids.
+func inBatches(uids []string, fn func(batch []string) error) error {
+	for start := 0; start < len(uids); start += uidBatchSize {
+		end := start + uidBatchSize
+		if end > len(uids) {
+			end = len(uids)
+		}
+		if err := fn(uids[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
@@ -73,7 +73,7 @@ func ReadDashboard(stream io.Reader, datasource DatasourceLookup) *DashboardInfo
 
 		case "panels":
 			for iter.ReadArray() {
-				dash.Panels = append(dash.Panels, readPanelInfo(iter))
+				dash.Panels = append(dash.Panels, readPanelInfo(iter, datasource))
 			}
 
 		case "rows":
@@ -129,12 +129,97 @@ func ReadDashboard(stream io.Reader, datasource DatasourceLookup) *DashboardInfo
 		logf("All dashbaords should have a UID defined")
 	}
 
+	// the dashboard uses all datasources of its panels
+	for _, panel := range dash.Panels {
+		addDashboardDatasources(dash, panel)
+	}
+
 	return dash
 }
 
+func addDashboardDatasources(dash *DashboardInfo, panel PanelInfo) {
+	dash.Datasource = appendUnique(dash.Datasource, panel.Datasource...)
+	dash.DatasourceType = appendUnique(dash.DatasourceType, panel.DatasourceType...)
+	for _, sub := range panel.Collapsed {
+		addDashboardDatasources(dash, sub)
+	}
+}
+
+func appendUnique(values []string, add ...string) []string {
+	for _, v := range add {
+		found := false
+		for _, existing := range values {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// queryKeys are the properties of the targets that hold the query text of the common datasources
+var queryKeys = []string{"expr", "query", "rawSql", "queryText", "target", "expression"}
+
+// addDatasource adds the datasource referenced by the value of a "datasource" property to the panel.
+// The value is either the name of the datasource (before 8.3) or an object with its uid and type.
+func addDatasource(panel *PanelInfo, v interface{}, lookup DatasourceLookup) {
+	var uid, dsType string
+	switch ref := v.(type) {
+	case string:
+		if ds := lookup(ref); ds != nil {
+			uid, dsType = ds.UID, ds.Type
+		}
+	case map[string]interface{}:
+		uid, _ = ref["uid"].(string)
+		dsType, _ = ref["type"].(string)
+		if dsType == "" && uid != "" {
+			if ds := lookup(uid); ds != nil {
+				dsType = ds.Type
+			}
+		}
+	}
+
+	if uid != "" {
+		panel.Datasource = appendUnique(panel.Datasource, uid)
+	}
+	if dsType != "" {
+		panel.DatasourceType = appendUnique(panel.DatasourceType, dsType)
+	}
+}
+
+// QueryText returns the query text of a target or of the model of an alert query.
+func QueryText(target map[string]interface{}) []string {
+	var queries []string
+	for _, key := range queryKeys {
+		if q, ok := target[key].(string); ok && q != "" {
+			queries = append(queries, q)
+		}
+	}
+	return queries
+}
+
+func readTarget(panel *PanelInfo, target map[string]interface{}, lookup DatasourceLookup) {
+	if ds, ok := target["datasource"]; ok && ds != nil {
+		addDatasource(panel, ds, lookup)
+	}
+	panel.Queries = append(panel.Queries, QueryText(target)...)
+}
+
+// ReadPanel will take a byte stream and return the info of a panel, such as the model of a library panel
+func ReadPanel(stream io.Reader, datasource DatasourceLookup) *PanelInfo {
+	iter := jsoniter.Parse(jsoniter.ConfigDefault, stream, 1024)
+	panel := readPanelInfo(iter, datasource)
+	return &panel
+}
+
 // will always return strings for now
-func readPanelInfo(iter *jsoniter.Iterator) PanelInfo {
+func readPanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) PanelInfo {
 	panel := PanelInfo{}
+	hasTargets := false
 
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 		// Skip null values so we don't need special int handling
@@ -160,13 +245,15 @@ func readPanelInfo(iter *jsoniter.Iterator) PanelInfo {
 			panel.PluginVersion = iter.ReadString() // since 7x (the saved version for the plugin model)
 
 		case "datasource":
-			v := iter.Read()
-			logf(">>Panel.datasource = %v\n", v) // string or object!!!
+			addDatasource(&panel, iter.Read(), lookup)
 
 		case "targets":
 			for iter.ReadArray() {
-				v := iter.Read()
-				logf("[Panel.TARGET] %v\n", v)
+				hasTargets = true
+				target, ok := iter.Read().(map[string]interface{})
+				if ok {
+					readTarget(&panel, target, lookup)
+				}
 			}
 
 		case "transformations":
@@ -183,7 +270,7 @@ func readPanelInfo(iter *jsoniter.Iterator) PanelInfo {
 		// Rows have nested panels
 		case "panels":
 			for iter.ReadArray() {
-				panel.Collapsed = append(panel.Collapsed, readPanelInfo(iter))
+				panel.Collapsed = append(panel.Collapsed, readPanelInfo(iter, lookup))
 			}
 
 		case "options":
@@ -201,5 +288,10 @@ func readPanelInfo(iter *jsoniter.Iterator) PanelInfo {
 		}
 	}
 
+	// panels with queries but without a datasource use the default datasource
+	if hasTargets && len(panel.Datasource) == 0 {
+		addDatasource(&panel, "", lookup)
+	}
+
 	return panel
 }
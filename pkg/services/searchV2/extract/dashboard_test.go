@@ -16,9 +16,14 @@ func TestReadDashboard(t *testing.T) {
 		"panel-graph/graph-shared-tooltips.json",
 	}
 
-	// key will allow name or uid
+	testdata := &DatasourceInfo{UID: "PD8C576611E62080A", Name: "gdev-testdata", Type: "testdata"}
+
+	// key will allow name or uid, the empty key is the default datasource
 	ds := func(key string) *DatasourceInfo {
-		return nil // TODO!
+		if key == "" || key == testdata.Name || key == testdata.UID {
+			return testdata
+		}
+		return nil
 	}
 
 	for _, input := range inputs {
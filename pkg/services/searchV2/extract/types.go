@@ -1,5 +1,7 @@
 package extract
 
+// DatasourceLookup finds a datasource by name or uid, and returns the default datasource for an
+// empty key. It returns nil when the datasource does not exist.
 type DatasourceLookup = func(key string) *DatasourceInfo
 
 type DatasourceInfo struct {
@@ -19,6 +21,7 @@ type PanelInfo struct {
 	Datasource      []string `json:"datasource,omitempty"`      // UIDs
 	DatasourceType  []string `json:"datasourceType,omitempty"`  // PluginIDs
 	Transformations []string `json:"transformations,omitempty"` // ids of the transformation steps
+	Queries         []string `json:"queries,omitempty"`         // query text of the targets
 
 	// Rows define panels as sub objects
 	Collapsed []PanelInfo `json:"collapsed,omitempty"`
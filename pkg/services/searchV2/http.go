@@ -0,0 +1,149 @@
+package searchV2
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// SearchHit is a result of the search API.
+type SearchHit struct {
+	Kind        string   `json:"kind"`
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	URL         string   `json:"url"`
+	Score       float64  `json:"score"`
+	FolderUID   string   `json:"folderUid,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// DashboardUID and PanelID are only set for panels, the uid of a panel is the uid of its
+	// dashboard followed by its id
+	DashboardUID   string   `json:"dashboardUid,omitempty"`
+	PanelID        int64    `json:"panelId,omitempty"`
+	PanelType      string   `json:"panelType,omitempty"`
+	Datasource     []string `json:"datasource,omitempty"`
+	DatasourceType []string `json:"datasourceType,omitempty"`
+}
+
+// FacetValue is the number of results with a value of a facet.
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchResponse is the response of the search API.
+type SearchResponse struct {
+	Hits   []SearchHit             `json:"hits"`
+	Facets map[string][]FacetValue `json:"facets,omitempty"`
+	// Total is the number of results before the limit
+	Total int `json:"total"`
+}
+
+func (s *StandardSearchService) registerAPIEndpoints() {
+	s.routeRegister.Get("/api/search-v2", middleware.ReqSignedIn, routing.Wrap(s.searchHandler))
+}
+
+func (s *StandardSearchService) searchHandler(c *models.ReqContext) response.Response {
+	query := DashboardQuery{
+		Query:          c.Query("query"),
+		Kind:           c.QueryStrings("kind"),
+		Tags:           c.QueryStrings("tag"),
+		Datasource:     c.QueryStrings("datasource"),
+		DatasourceType: c.QueryStrings("datasourceType"),
+		PanelType:      c.QueryStrings("panelType"),
+		Facets:         c.QueryStrings("facet"),
+		Limit:          c.QueryInt("limit"),
+	}
+	for _, id := range c.QueryStrings("folderId") {
+		folderID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, fmt.Sprintf("invalid folder id %q", id), err)
+		}
+		query.FolderIDs = append(query.FolderIDs, folderID)
+	}
+	if err := query.validate(); err != nil {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	hits, err := s.searchHits(c.Req.Context(), c.SignedInUser, query)
+	if err != nil {
+		if errors.Is(err, errIndexNotReady) {
+			return response.Error(http.StatusServiceUnavailable, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to search", err)
+	}
+
+	res := SearchResponse{
+		Hits:  make([]SearchHit, 0, len(hits)),
+		Total: len(hits),
+	}
+	for _, hit := range limitHits(hits, query.Limit) {
+		res.Hits = append(res.Hits, hitToDTO(hit))
+	}
+	if len(query.Facets) > 0 {
+		res.Facets = make(map[string][]FacetValue, len(query.Facets))
+		for _, frame := range facetFrames(hits, query.Facets) {
+			values := make([]FacetValue, 0, frame.Rows())
+			for row := 0; row < frame.Rows(); row++ {
+				values = append(values, FacetValue{
+					Value: frame.Fields[0].At(row).(string),
+					Count: frame.Fields[1].At(row).(int64),
+				})
+			}
+			res.Facets[frame.Name[len("facet-"):]] = values
+		}
+	}
+	return response.JSON(http.StatusOK, res)
+}
+
+func hitToDTO(hit searchHit) SearchHit {
+	if e := hit.entity; e != nil {
+		return SearchHit{
+			Kind:           e.Kind,
+			UID:            e.UID,
+			Title:          e.Title,
+			Description:    e.Description,
+			URL:            e.URL,
+			Score:          hit.score,
+			FolderUID:      e.FolderUID,
+			Tags:           e.Tags,
+			PanelType:      e.PanelType,
+			Datasource:     e.Datasource,
+			DatasourceType: e.DatasourceType,
+		}
+	}
+
+	dash := hit.meta.dash
+	dto := SearchHit{
+		Kind:           hit.kind,
+		UID:            dash.UID,
+		Title:          dash.Title,
+		Description:    dash.Description,
+		URL:            fmt.Sprintf("/d/%s/%s", dash.UID, hit.meta.slug),
+		Score:          hit.score,
+		Tags:           dash.Tags,
+		Datasource:     dash.Datasource,
+		DatasourceType: dash.DatasourceType,
+	}
+	switch hit.kind {
+	case kindFolder:
+		dto.URL = fmt.Sprintf("/dashboards/f/%s/%s", dash.UID, hit.meta.slug)
+	case kindPanel:
+		dto.UID = fmt.Sprintf("%s#%d", dash.UID, hit.panel.ID)
+		dto.Title = hit.panel.Title
+		dto.Description = hit.panel.Description
+		dto.URL = fmt.Sprintf("/d/%s/%s?viewPanel=%d", dash.UID, hit.meta.slug, hit.panel.ID)
+		dto.DashboardUID = dash.UID
+		dto.PanelID = hit.panel.ID
+		dto.PanelType = hit.panel.Type
+		dto.Datasource = hit.panel.Datasource
+		dto.DatasourceType = hit.panel.DatasourceType
+	}
+	return dto
+}
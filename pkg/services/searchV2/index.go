@@ -0,0 +1,682 @@
+package searchV2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/mapping"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/searchV2/extract"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	kindDashboard = "dashboard"
+	kindFolder    = "folder"
+	kindPanel     = "panel"
+
+	// indexMappingVersion must be increased when the mapping or the documents change, so that
+	// existing indexes are rebuilt
+	indexMappingVersion = "2"
+)
+
+var indexMappingVersionKey = []byte("mapping_version")
+
+// indexDocument is a dashboard, folder, panel or other entity in the index. Panels are indexed
+// with the tags and the folder of their dashboard, and dashboards with the panel types and
+// datasources of their panels, so the filters work the same for all kinds.
+type indexDocument struct {
+	Kind           string    `json:"kind"`
+	OrgID          string    `json:"org_id"`
+	DashboardUID   string    `json:"dash_uid"`
+	FolderID       string    `json:"folder_id"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Tags           []string  `json:"tags"`
+	PanelType      []string  `json:"panel_type"`
+	Datasource     []string  `json:"ds_uid"`
+	DatasourceType []string  `json:"ds_type"`
+	Queries        []string  `json:"query"`
+	Updated        time.Time `json:"updated"`
+	// Meta is the stored JSON of the storedMeta of dashboards and folders, or of the entityMeta of
+	// other entities, it is not set on panels
+	Meta string `json:"meta,omitempty"`
+}
+
+// storedMeta is the dashMeta kept in the index, so the cache can be loaded without reading all
+// dashboards from the database when Grafana starts.
+type storedMeta struct {
+	ID        int64                  `json:"id"`
+	IsFolder  bool                   `json:"isFolder"`
+	FolderID  int64                  `json:"folderId"`
+	Slug      string                 `json:"slug"`
+	Created   time.Time              `json:"created"`
+	Updated   time.Time              `json:"updated"`
+	Dashboard *extract.DashboardInfo `json:"dashboard"`
+}
+
+func newIndexMapping() mapping.IndexMapping {
+	keywordField := bleve.NewKeywordFieldMapping()
+
+	textField := bleve.NewTextFieldMapping()
+	textField.Analyzer = standard.Name
+	textField.Store = false
+
+	storedField := bleve.NewTextFieldMapping()
+	storedField.Index = false
+	storedField.IncludeInAll = false
+
+	updatedField := bleve.NewDateTimeFieldMapping()
+	updatedField.Store = false
+
+	doc := bleve.NewDocumentStaticMapping()
+	for _, name := range []string{"kind", "org_id", "dash_uid", "folder_id", "tags", "panel_type", "ds_uid", "ds_type"} {
+		doc.AddFieldMappingsAt(name, keywordField)
+	}
+	for _, name := range []string{"title", "description", "query"} {
+		doc.AddFieldMappingsAt(name, textField)
+	}
+	doc.AddFieldMappingsAt("updated", updatedField)
+	doc.AddFieldMappingsAt("meta", storedField)
+
+	m := bleve.NewIndexMapping()
+	m.DefaultMapping = doc
+	m.DefaultAnalyzer = keyword.Name
+	return m
+}
+
+// dashboardIndex is the full-text index of the dashboards of all organizations. It also keeps
+// the meta of all dashboards in memory, which is used to build the results.
+type dashboardIndex struct {
+	log   log.Logger
+	sql   *sqlstore.SQLStore
+	index bleve.Index
+
+	mu       sync.RWMutex
+	orgs     map[int64]map[string]*dashMeta   // org id -> dashboard uid -> meta
+	entities map[int64]map[string]*entityMeta // org id -> entity key -> meta
+}
+
+// openDashboardIndex opens the index stored at path, or creates it. The index is rebuilt when it
+// was created with another mapping. An empty path keeps the index in memory.
+func openDashboardIndex(logger log.Logger, sql *sqlstore.SQLStore, path string) (*dashboardIndex, error) {
+	index, err := openBleveIndex(logger, path)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &dashboardIndex{
+		log:      logger,
+		sql:      sql,
+		index:    index,
+		orgs:     make(map[int64]map[string]*dashMeta),
+		entities: make(map[int64]map[string]*entityMeta),
+	}
+	if err := i.loadCache(); err != nil {
+		_ = index.Close()
+		return nil, fmt.Errorf("failed to load the dashboards of the search index: %w", err)
+	}
+	return i, nil
+}
+
+func openBleveIndex(logger log.Logger, path string) (bleve.Index, error) {
+	if path == "" {
+		return newBleveIndex(path)
+	}
+
+	index, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return nil, err
+		}
+		return newBleveIndex(path)
+	}
+	if err != nil {
+		logger.Warn("Failed to open search index, rebuilding it", "path", path, "error", err)
+		return recreateBleveIndex(path)
+	}
+
+	version, err := index.GetInternal(indexMappingVersionKey)
+	if err != nil || string(version) != indexMappingVersion {
+		logger.Info("Search index mapping changed, rebuilding it", "path", path, "version", string(version))
+		_ = index.Close()
+		return recreateBleveIndex(path)
+	}
+	return index, nil
+}
+
+func recreateBleveIndex(path string) (bleve.Index, error) {
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+	return newBleveIndex(path)
+}
+
+func newBleveIndex(path string) (bleve.Index, error) {
+	var index bleve.Index
+	var err error
+	if path == "" {
+		index, err = bleve.NewMemOnly(newIndexMapping())
+	} else {
+		index, err = bleve.New(path, newIndexMapping())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := index.SetInternal(indexMappingVersionKey, []byte(indexMappingVersion)); err != nil {
+		_ = index.Close()
+		return nil, err
+	}
+	return index, nil
+}
+
+func (i *dashboardIndex) close() error {
+	return i.index.Close()
+}
+
+// loadCache reads the meta of the dashboards, folders and entities stored in the index.
+func (i *dashboardIndex) loadCache() error {
+	count, err := i.index.DocCount()
+	if err != nil || count == 0 {
+		return err
+	}
+
+	kinds := []string{kindDashboard, kindFolder}
+	for _, loader := range entityLoaders {
+		kinds = append(kinds, loader.kind)
+	}
+	req := bleve.NewSearchRequestOptions(anyTermQuery("kind", kinds), int(count), 0, false)
+	req.Fields = []string{"kind", "org_id", "meta"}
+	res, err := i.index.Search(req)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, hit := range res.Hits {
+		orgField, _ := hit.Fields["org_id"].(string)
+		orgID, err := strconv.ParseInt(orgField, 10, 64)
+		if err != nil {
+			continue
+		}
+		kind, _ := hit.Fields["kind"].(string)
+		stored, _ := hit.Fields["meta"].(string)
+
+		if isEntityKind(kind) {
+			var e entityMeta
+			if err := json.Unmarshal([]byte(stored), &e); err != nil {
+				i.log.Warn("Ignoring invalid entity in search index", "id", hit.ID, "error", err)
+				continue
+			}
+			i.entityCache(orgID)[entityKey(e.Kind, e.UID)] = &e
+			continue
+		}
+
+		var sm storedMeta
+		if err := json.Unmarshal([]byte(stored), &sm); err != nil || sm.Dashboard == nil {
+			i.log.Warn("Ignoring invalid dashboard in search index", "id", hit.ID, "error", err)
+			continue
+		}
+		i.orgCache(orgID)[sm.Dashboard.UID] = &dashMeta{
+			id:        sm.ID,
+			is_folder: sm.IsFolder,
+			folder_id: sm.FolderID,
+			slug:      sm.Slug,
+			created:   sm.Created,
+			updated:   sm.Updated,
+			dash:      sm.Dashboard,
+		}
+	}
+	return nil
+}
+
+// orgCache returns the cached dashboards of an organization, i.mu must be locked for writing.
+func (i *dashboardIndex) orgCache(orgID int64) map[string]*dashMeta {
+	org, ok := i.orgs[orgID]
+	if !ok {
+		org = make(map[string]*dashMeta)
+		i.orgs[orgID] = org
+	}
+	return org
+}
+
+// entityCache returns the cached entities of an organization, i.mu must be locked for writing.
+func (i *dashboardIndex) entityCache(orgID int64) map[string]*entityMeta {
+	org, ok := i.entities[orgID]
+	if !ok {
+		org = make(map[string]*entityMeta)
+		i.entities[orgID] = org
+	}
+	return org
+}
+
+// entity returns the cached entity of the given kind and uid.
+func (i *dashboardIndex) entity(orgID int64, kind, uid string) (*entityMeta, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	e, ok := i.entities[orgID][entityKey(kind, uid)]
+	return e, ok
+}
+
+// dashboards returns the cached dashboards and folders of an organization.
+func (i *dashboardIndex) dashboards(orgID int64) []dashMeta {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	res := make([]dashMeta, 0, len(i.orgs[orgID]))
+	for _, meta := range i.orgs[orgID] {
+		res = append(res, *meta)
+	}
+	sort.Slice(res, func(a, b int) bool {
+		return res[a].id < res[b].id
+	})
+	return res
+}
+
+// dashboard returns the cached dashboard or folder with the given uid.
+func (i *dashboardIndex) dashboard(orgID int64, uid string) (*dashMeta, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	meta, ok := i.orgs[orgID][uid]
+	return meta, ok
+}
+
+func documentID(orgID int64, uid string) string {
+	return strconv.FormatInt(orgID, 10) + "/" + uid
+}
+
+func panelDocumentID(orgID int64, uid string, panelID int64) string {
+	return documentID(orgID, uid) + "#" + strconv.FormatInt(panelID, 10)
+}
+
+// entityKey is the key of an entity in the cache, uids are only unique for a kind.
+func entityKey(kind, uid string) string {
+	return kind + ":" + uid
+}
+
+func entityDocumentID(orgID int64, kind, uid string) string {
+	return documentID(orgID, entityKey(kind, uid))
+}
+
+// documentRef is what a document id refers to.
+type documentRef struct {
+	orgID int64
+	// kind is only set for entities, the kind of dashboards and folders is in their meta
+	kind    string
+	uid     string
+	panelID int64 // -1 when the document is not a panel
+}
+
+// parseDocumentID returns what a document refers to. Dashboard uids cannot contain ':' or '#',
+// so entities and panels are recognized by them.
+func parseDocumentID(id string) (documentRef, error) {
+	idx := strings.Index(id, "/")
+	if idx < 0 {
+		return documentRef{}, fmt.Errorf("invalid document id %q", id)
+	}
+	orgID, err := strconv.ParseInt(id[:idx], 10, 64)
+	if err != nil {
+		return documentRef{}, fmt.Errorf("invalid document id %q", id)
+	}
+
+	ref := documentRef{orgID: orgID, uid: id[idx+1:], panelID: -1}
+	if idx := strings.Index(ref.uid, ":"); idx > 0 && isEntityKind(ref.uid[:idx]) {
+		ref.kind = ref.uid[:idx]
+		ref.uid = ref.uid[idx+1:]
+		return ref, nil
+	}
+	if idx := strings.LastIndex(ref.uid, "#"); idx >= 0 {
+		ref.panelID, err = strconv.ParseInt(ref.uid[idx+1:], 10, 64)
+		if err != nil {
+			return documentRef{}, fmt.Errorf("invalid document id %q", id)
+		}
+		ref.uid = ref.uid[:idx]
+	}
+	return ref, nil
+}
+
+// indexedPanels returns the panels of a dashboard that are indexed, which are all panels
+// including the panels of collapsed rows, but not the rows themselves.
+func indexedPanels(dash *extract.DashboardInfo) []extract.PanelInfo {
+	panels := make([]extract.PanelInfo, 0, len(dash.Panels))
+	var add func(ps []extract.PanelInfo)
+	add = func(ps []extract.PanelInfo) {
+		for _, p := range ps {
+			if p.Type != "row" {
+				panels = append(panels, p)
+			}
+			add(p.Collapsed)
+		}
+	}
+	add(dash.Panels)
+	return panels
+}
+
+func (i *dashboardIndex) deleteDocuments(batch *bleve.Batch, orgID int64, meta *dashMeta) {
+	batch.Delete(documentID(orgID, meta.dash.UID))
+	for _, panel := range indexedPanels(meta.dash) {
+		batch.Delete(panelDocumentID(orgID, meta.dash.UID, panel.ID))
+	}
+}
+
+func (i *dashboardIndex) addDocuments(batch *bleve.Batch, orgID int64, meta *dashMeta) error {
+	stored, err := json.Marshal(storedMeta{
+		ID:        meta.id,
+		IsFolder:  meta.is_folder,
+		FolderID:  meta.folder_id,
+		Slug:      meta.slug,
+		Created:   meta.created,
+		Updated:   meta.updated,
+		Dashboard: meta.dash,
+	})
+	if err != nil {
+		return err
+	}
+
+	org := strconv.FormatInt(orgID, 10)
+	folderID := strconv.FormatInt(meta.folder_id, 10)
+	panels := indexedPanels(meta.dash)
+
+	kind := kindDashboard
+	if meta.is_folder {
+		kind = kindFolder
+	}
+	doc := indexDocument{
+		Kind:           kind,
+		OrgID:          org,
+		DashboardUID:   meta.dash.UID,
+		FolderID:       folderID,
+		Title:          meta.dash.Title,
+		Description:    meta.dash.Description,
+		Tags:           meta.dash.Tags,
+		Datasource:     meta.dash.Datasource,
+		DatasourceType: meta.dash.DatasourceType,
+		Updated:        meta.updated,
+		Meta:           string(stored),
+	}
+	for _, panel := range panels {
+		doc.PanelType = appendUnique(doc.PanelType, panel.Type)
+	}
+	if err := batch.Index(documentID(orgID, meta.dash.UID), doc); err != nil {
+		return err
+	}
+
+	for _, panel := range panels {
+		panelDoc := indexDocument{
+			Kind:           kindPanel,
+			OrgID:          org,
+			DashboardUID:   meta.dash.UID,
+			FolderID:       folderID,
+			Title:          panel.Title,
+			Description:    panel.Description,
+			Tags:           meta.dash.Tags,
+			PanelType:      []string{panel.Type},
+			Datasource:     panel.Datasource,
+			DatasourceType: panel.DatasourceType,
+			Queries:        panel.Queries,
+			Updated:        meta.updated,
+		}
+		if err := batch.Index(panelDocumentID(orgID, meta.dash.UID, panel.ID), panelDoc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendUnique(values []string, v string) []string {
+	for _, existing := range values {
+		if existing == v {
+			return values
+		}
+	}
+	return append(values, v)
+}
+
+func (i *dashboardIndex) addEntityDocument(batch *bleve.Batch, orgID int64, e *entityMeta) error {
+	stored, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	doc := indexDocument{
+		Kind:           e.Kind,
+		OrgID:          strconv.FormatInt(orgID, 10),
+		Title:          e.Title,
+		Description:    e.Description,
+		Tags:           e.Tags,
+		Datasource:     e.Datasource,
+		DatasourceType: e.DatasourceType,
+		Queries:        e.Queries,
+		Meta:           string(stored),
+	}
+	if e.Kind == kindLibraryPanel || e.Kind == kindAlertRule {
+		doc.FolderID = strconv.FormatInt(e.FolderID, 10)
+	}
+	if e.PanelType != "" {
+		doc.PanelType = []string{e.PanelType}
+	}
+	return batch.Index(entityDocumentID(orgID, e.Kind, e.UID), doc)
+}
+
+// updateEntities replaces the documents of the given entities, and deletes the documents of the
+// entities with the given keys.
+func (i *dashboardIndex) updateEntities(orgID int64, entities []*entityMeta, deleted []string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	batch := i.index.NewBatch()
+	for _, key := range deleted {
+		batch.Delete(documentID(orgID, key))
+	}
+	for _, e := range entities {
+		if err := i.addEntityDocument(batch, orgID, e); err != nil {
+			return err
+		}
+	}
+	if err := i.index.Batch(batch); err != nil {
+		return err
+	}
+
+	org := i.entityCache(orgID)
+	for _, key := range deleted {
+		delete(org, key)
+	}
+	for _, e := range entities {
+		org[entityKey(e.Kind, e.UID)] = e
+	}
+	return nil
+}
+
+// update replaces the documents of the given dashboards, and deletes the documents of the
+// dashboards with the given uids.
+func (i *dashboardIndex) update(orgID int64, dashboards []dashMeta, deleted []string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	org := i.orgCache(orgID)
+	batch := i.index.NewBatch()
+	for _, uid := range deleted {
+		if existing, ok := org[uid]; ok {
+			i.deleteDocuments(batch, orgID, existing)
+		}
+	}
+	for idx := range dashboards {
+		meta := &dashboards[idx]
+		// the panels of the previous version may have been removed
+		if existing, ok := org[meta.dash.UID]; ok {
+			i.deleteDocuments(batch, orgID, existing)
+		}
+		// the id is used by the frames, dashboards without a uid in their JSON cannot be indexed
+		if meta.dash.UID == "" {
+			continue
+		}
+		if err := i.addDocuments(batch, orgID, meta); err != nil {
+			return err
+		}
+	}
+	if err := i.index.Batch(batch); err != nil {
+		return err
+	}
+
+	for _, uid := range deleted {
+		delete(org, uid)
+	}
+	for idx := range dashboards {
+		if dashboards[idx].dash.UID != "" {
+			org[dashboards[idx].dash.UID] = &dashboards[idx]
+		}
+	}
+	return nil
+}
+
+// deleteFolder deletes a folder and the dashboards that were in it.
+func (i *dashboardIndex) deleteFolder(orgID int64, folderID int64, uid string) error {
+	i.mu.RLock()
+	deleted := []string{uid}
+	for _, meta := range i.orgs[orgID] {
+		if meta.folder_id == folderID && !meta.is_folder {
+			deleted = append(deleted, meta.dash.UID)
+		}
+	}
+	i.mu.RUnlock()
+
+	return i.update(orgID, nil, deleted)
+}
+
+// indexStateQueryResult is the state of a dashboard in the database that is compared with the cache.
+type indexStateQueryResult struct {
+	ID      int64     `xorm:"id"`
+	OrgID   int64     `xorm:"org_id"`
+	UID     string    `xorm:"uid"`
+	Updated time.Time `xorm:"updated"`
+}
+
+// reconcile updates the index with the dashboards that changed in the database without an event
+// being received, for example when they were changed by another Grafana instance.
+func (i *dashboardIndex) reconcile(ctx context.Context) error {
+	rows := make([]*indexStateQueryResult, 0)
+	err := i.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Table("dashboard").Cols("id", "org_id", "uid", "updated").Find(&rows)
+	})
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[int64][]int64)
+	existing := make(map[int64]map[string]bool)
+	i.mu.RLock()
+	for _, row := range rows {
+		if existing[row.OrgID] == nil {
+			existing[row.OrgID] = make(map[string]bool)
+		}
+		existing[row.OrgID][row.UID] = true
+
+		meta, ok := i.orgs[row.OrgID][row.UID]
+		if !ok || meta.id != row.ID || !meta.updated.Equal(row.Updated) {
+			changed[row.OrgID] = append(changed[row.OrgID], row.ID)
+		}
+	}
+	deleted := make(map[int64][]string)
+	for orgID, org := range i.orgs {
+		for uid := range org {
+			if !existing[orgID][uid] {
+				deleted[orgID] = append(deleted[orgID], uid)
+			}
+		}
+	}
+	i.mu.RUnlock()
+
+	for orgID, uids := range deleted {
+		if err := i.update(orgID, nil, uids); err != nil {
+			return err
+		}
+	}
+	for orgID, ids := range changed {
+		dashboards, err := loadDashboards(ctx, orgID, i.sql, ids...)
+		if err != nil {
+			return err
+		}
+		if err := i.update(orgID, dashboards, nil); err != nil {
+			return err
+		}
+	}
+
+	if len(changed) > 0 || len(deleted) > 0 {
+		i.log.Debug("Reconciled search index", "changedOrgs", len(changed), "deletedOrgs", len(deleted))
+	}
+
+	for _, loader := range entityLoaders {
+		if err := i.reconcileEntities(ctx, loader); err != nil {
+			return fmt.Errorf("failed to index %s entities: %w", loader.kind, err)
+		}
+	}
+	return nil
+}
+
+// reconcileEntities updates the index with the entities of a kind that changed in the database.
+// These entities do not publish events, so they are only indexed by the reconciliation.
+func (i *dashboardIndex) reconcileEntities(ctx context.Context, loader entityLoader) error {
+	return i.sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		versions, err := loader.versions(sess)
+		if err != nil {
+			return err
+		}
+
+		changed := make(map[int64][]string)
+		existing := make(map[int64]map[string]bool)
+		i.mu.RLock()
+		for _, v := range versions {
+			key := entityKey(loader.kind, v.UID)
+			if existing[v.OrgID] == nil {
+				existing[v.OrgID] = make(map[string]bool)
+			}
+			existing[v.OrgID][key] = true
+
+			e, ok := i.entities[v.OrgID][key]
+			if !ok || e.Version != v.Version {
+				changed[v.OrgID] = append(changed[v.OrgID], v.UID)
+			}
+		}
+		deleted := make(map[int64][]string)
+		for orgID, org := range i.entities {
+			for key, e := range org {
+				if e.Kind == loader.kind && !existing[orgID][key] {
+					deleted[orgID] = append(deleted[orgID], key)
+				}
+			}
+		}
+		i.mu.RUnlock()
+
+		for orgID, keys := range deleted {
+			if err := i.updateEntities(orgID, nil, keys); err != nil {
+				return err
+			}
+		}
+		for orgID, uids := range changed {
+			entities, err := loader.load(sess, orgID, uids)
+			if err != nil {
+				return err
+			}
+			if err := i.updateEntities(orgID, entities, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,426 @@
+package searchV2
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/searchV2/extract"
+)
+
+const defaultSearchLimit = 100
+
+const (
+	FacetKind           = "kind"
+	FacetTags           = "tags"
+	FacetPanelType      = "panelType"
+	FacetDatasource     = "datasource"
+	FacetDatasourceType = "datasourceType"
+)
+
+var knownFacets = map[string]bool{
+	FacetKind:           true,
+	FacetTags:           true,
+	FacetPanelType:      true,
+	FacetDatasource:     true,
+	FacetDatasourceType: true,
+}
+
+// searchHit is a dashboard, folder, panel or other entity that matched the query.
+type searchHit struct {
+	kind   string
+	score  float64
+	meta   *dashMeta          // only set for dashboards, folders and panels
+	panel  *extract.PanelInfo // only set for panels
+	entity *entityMeta        // only set for the other kinds
+}
+
+func (h searchHit) title() string {
+	switch {
+	case h.entity != nil:
+		return h.entity.Title
+	case h.panel != nil:
+		return h.panel.Title
+	}
+	return h.meta.dash.Title
+}
+
+func termQuery(field, term string) *query.TermQuery {
+	q := bleve.NewTermQuery(term)
+	q.SetField(field)
+	return q
+}
+
+// anyTermQuery matches the documents with one of the terms in the field.
+func anyTermQuery(field string, terms []string) query.Query {
+	disjuncts := make([]query.Query, 0, len(terms))
+	for _, t := range terms {
+		disjuncts = append(disjuncts, termQuery(field, t))
+	}
+	return bleve.NewDisjunctionQuery(disjuncts...)
+}
+
+// textQuery ranks titles higher than descriptions and panel queries. The last word also
+// matches as a prefix of the title, so results are found while typing.
+func textQuery(text string) query.Query {
+	title := bleve.NewMatchQuery(text)
+	title.SetField("title")
+	title.SetBoost(3)
+
+	description := bleve.NewMatchQuery(text)
+	description.SetField("description")
+
+	queries := bleve.NewMatchQuery(text)
+	queries.SetField("query")
+
+	tag := termQuery("tags", text)
+	tag.SetBoost(2)
+
+	disjuncts := []query.Query{title, description, queries, tag}
+	if words := strings.Fields(strings.ToLower(text)); len(words) > 0 {
+		prefix := bleve.NewPrefixQuery(words[len(words)-1])
+		prefix.SetField("title")
+		prefix.SetBoost(2)
+		disjuncts = append(disjuncts, prefix)
+	}
+	return bleve.NewDisjunctionQuery(disjuncts...)
+}
+
+func (q DashboardQuery) validate() error {
+	for _, kind := range q.Kind {
+		if kind != kindDashboard && kind != kindFolder && kind != kindPanel && !isEntityKind(kind) {
+			return fmt.Errorf("unknown kind %q", kind)
+		}
+	}
+	for _, facet := range q.Facets {
+		if !knownFacets[facet] {
+			return fmt.Errorf("unknown facet %q", facet)
+		}
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("invalid limit %d", q.Limit)
+	}
+	return nil
+}
+
+// bleveQuery returns the query that matches the documents of the organization for the given
+// text and filters. All filters must match, and any value of a filter.
+func (q DashboardQuery) bleveQuery(orgID int64) query.Query {
+	conjuncts := []query.Query{termQuery("org_id", strconv.FormatInt(orgID, 10))}
+
+	if q.Query != "" {
+		conjuncts = append(conjuncts, textQuery(q.Query))
+	}
+	if len(q.Kind) > 0 {
+		conjuncts = append(conjuncts, anyTermQuery("kind", q.Kind))
+	}
+	for _, tag := range q.Tags {
+		conjuncts = append(conjuncts, termQuery("tags", tag))
+	}
+	if len(q.Datasource) > 0 {
+		conjuncts = append(conjuncts, anyTermQuery("ds_uid", q.Datasource))
+	}
+	if len(q.DatasourceType) > 0 {
+		conjuncts = append(conjuncts, anyTermQuery("ds_type", q.DatasourceType))
+	}
+	if len(q.PanelType) > 0 {
+		conjuncts = append(conjuncts, anyTermQuery("panel_type", q.PanelType))
+	}
+	if len(q.FolderIDs) > 0 {
+		folders := make([]string, 0, len(q.FolderIDs))
+		for _, id := range q.FolderIDs {
+			folders = append(folders, strconv.FormatInt(id, 10))
+		}
+		conjuncts = append(conjuncts, anyTermQuery("folder_id", folders))
+	}
+	return bleve.NewConjunctionQuery(conjuncts...)
+}
+
+// search returns the documents of the organization that match the query and that the filter
+// allows, ordered by score. Hits are only limited after they are filtered, so the results and the
+// facets never include anything the user cannot read.
+func (i *dashboardIndex) search(orgID int64, q DashboardQuery, filter hitFilter) ([]searchHit, error) {
+	count, err := i.index.DocCount()
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	res, err := i.index.Search(bleve.NewSearchRequestOptions(q.bleveQuery(orgID), int(count), 0, false))
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]searchHit, 0, len(res.Hits))
+	for _, match := range res.Hits {
+		ref, err := parseDocumentID(match.ID)
+		if err != nil {
+			continue
+		}
+
+		hit, ok := i.searchHit(orgID, ref)
+		if !ok || !filter(hit) {
+			continue
+		}
+		hit.score = match.Score
+		hits = append(hits, hit)
+	}
+
+	sort.SliceStable(hits, func(a, b int) bool {
+		if hits[a].score != hits[b].score {
+			return hits[a].score > hits[b].score
+		}
+		return hits[a].title() < hits[b].title()
+	})
+	return hits, nil
+}
+
+// searchHit returns the cached dashboard, folder, panel or entity that a document refers to.
+func (i *dashboardIndex) searchHit(orgID int64, ref documentRef) (searchHit, bool) {
+	if ref.kind != "" {
+		e, ok := i.entity(orgID, ref.kind, ref.uid)
+		return searchHit{kind: ref.kind, entity: e}, ok
+	}
+
+	meta, ok := i.dashboard(orgID, ref.uid)
+	if !ok {
+		return searchHit{}, false
+	}
+	hit := searchHit{kind: kindDashboard, meta: meta}
+	switch {
+	case ref.panelID >= 0:
+		hit.kind = kindPanel
+		for _, panel := range indexedPanels(meta.dash) {
+			if panel.ID == ref.panelID {
+				p := panel
+				hit.panel = &p
+				break
+			}
+		}
+		if hit.panel == nil {
+			return searchHit{}, false
+		}
+	case meta.is_folder:
+		hit.kind = kindFolder
+	}
+	return hit, true
+}
+
+type facetCounter struct {
+	name   string
+	counts map[string]int64
+}
+
+func (c *facetCounter) add(values ...string) {
+	for _, v := range values {
+		if v != "" {
+			c.counts[v]++
+		}
+	}
+}
+
+func (c *facetCounter) addEntity(e *entityMeta) {
+	switch c.name {
+	case FacetKind:
+		c.add(e.Kind)
+	case FacetTags:
+		c.add(e.Tags...)
+	case FacetPanelType:
+		c.add(e.PanelType)
+	case FacetDatasource:
+		c.add(e.Datasource...)
+	case FacetDatasourceType:
+		c.add(e.DatasourceType...)
+	}
+}
+
+// toFrame returns the values of the facet ordered by count, then by value.
+func (c *facetCounter) toFrame() *data.Frame {
+	values := make([]string, 0, len(c.counts))
+	for v := range c.counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(a, b int) bool {
+		if c.counts[values[a]] != c.counts[values[b]] {
+			return c.counts[values[a]] > c.counts[values[b]]
+		}
+		return values[a] < values[b]
+	})
+
+	value := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	count := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	value.Name = "Value"
+	count.Name = "Count"
+	for _, v := range values {
+		value.Append(v)
+		count.Append(c.counts[v])
+	}
+	return data.NewFrame("facet-"+c.name, value, count)
+}
+
+// facetFrames counts the values of the requested facets over all hits.
+func facetFrames(hits []searchHit, facets []string) data.Frames {
+	frames := make(data.Frames, 0, len(facets))
+	for _, name := range facets {
+		c := &facetCounter{name: name, counts: make(map[string]int64)}
+		for _, hit := range hits {
+			if hit.entity != nil {
+				c.addEntity(hit.entity)
+				continue
+			}
+			dash := hit.meta.dash
+			switch name {
+			case FacetKind:
+				c.add(hit.kind)
+			case FacetTags:
+				c.add(dash.Tags...)
+			case FacetPanelType:
+				if hit.panel != nil {
+					c.add(hit.panel.Type)
+				} else {
+					types := make([]string, 0)
+					for _, panel := range indexedPanels(dash) {
+						types = appendUnique(types, panel.Type)
+					}
+					c.add(types...)
+				}
+			case FacetDatasource:
+				if hit.panel != nil {
+					c.add(hit.panel.Datasource...)
+				} else {
+					c.add(dash.Datasource...)
+				}
+			case FacetDatasourceType:
+				if hit.panel != nil {
+					c.add(hit.panel.DatasourceType...)
+				} else {
+					c.add(dash.DatasourceType...)
+				}
+			}
+		}
+		frames = append(frames, c.toFrame())
+	}
+	return frames
+}
+
+// hitsToFrames returns the folders, dashboards and panels that matched in the same frames as
+// metaToFrame, and the other entities in the entities frame, with the score of each result.
+func hitsToFrames(hits []searchHit) data.Frames {
+	folderID := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	folderUID := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	folderName := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	folderScore := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
+
+	folderID.Name = "ID"
+	folderUID.Name = "UID"
+	folderName.Name = "Name"
+	folderScore.Name = "Score"
+
+	dashID := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	dashUID := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	dashURL := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	dashFolderID := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	dashName := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	dashDescr := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	dashTags := data.NewFieldFromFieldType(data.FieldTypeNullableString, 0)
+	dashUpdated := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	dashScore := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
+
+	dashID.Name = "ID"
+	dashUID.Name = "UID"
+	dashURL.Name = "URL"
+	dashFolderID.Name = "FolderID"
+	dashName.Name = "Name"
+	dashDescr.Name = "Description"
+	dashTags.Name = "Tags"
+	dashUpdated.Name = "Updated"
+	dashScore.Name = "Score"
+
+	panelDashID := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	panelDashUID := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	panelID := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
+	panelName := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	panelDescr := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	panelType := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	panelScore := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
+
+	panelDashID.Name = "DashboardID"
+	panelDashUID.Name = "DashboardUID"
+	panelID.Name = "ID"
+	panelName.Name = "Name"
+	panelDescr.Name = "Description"
+	panelType.Name = "Type"
+	panelScore.Name = "Score"
+
+	entityKind := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	entityUID := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	entityName := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	entityDescr := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	entityURL := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	entityFolderUID := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	entityTags := data.NewFieldFromFieldType(data.FieldTypeNullableString, 0)
+	entityScore := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
+
+	entityKind.Name = "Kind"
+	entityUID.Name = "UID"
+	entityName.Name = "Name"
+	entityDescr.Name = "Description"
+	entityURL.Name = "URL"
+	entityFolderUID.Name = "FolderUID"
+	entityTags.Name = "Tags"
+	entityScore.Name = "Score"
+
+	for _, hit := range hits {
+		if e := hit.entity; e != nil {
+			entityKind.Append(e.Kind)
+			entityUID.Append(e.UID)
+			entityName.Append(e.Title)
+			entityDescr.Append(e.Description)
+			entityURL.Append(e.URL)
+			entityFolderUID.Append(e.FolderUID)
+			entityTags.Append(tagsJSON(e.Tags))
+			entityScore.Append(hit.score)
+			continue
+		}
+
+		meta := hit.meta
+		switch hit.kind {
+		case kindFolder:
+			folderID.Append(meta.id)
+			folderUID.Append(meta.dash.UID)
+			folderName.Append(meta.dash.Title)
+			folderScore.Append(hit.score)
+		case kindDashboard:
+			dashID.Append(meta.id)
+			dashUID.Append(meta.dash.UID)
+			dashURL.Append(fmt.Sprintf("/d/%s/%s", meta.dash.UID, meta.slug))
+			dashFolderID.Append(meta.folder_id)
+			dashName.Append(meta.dash.Title)
+			dashDescr.Append(meta.dash.Description)
+			dashTags.Append(tagsJSON(meta.dash.Tags))
+			dashUpdated.Append(meta.updated)
+			dashScore.Append(hit.score)
+		case kindPanel:
+			panelDashID.Append(meta.id)
+			panelDashUID.Append(meta.dash.UID)
+			panelID.Append(hit.panel.ID)
+			panelName.Append(hit.panel.Title)
+			panelDescr.Append(hit.panel.Description)
+			panelType.Append(hit.panel.Type)
+			panelScore.Append(hit.score)
+		}
+	}
+
+	return data.Frames{
+		data.NewFrame("folders", folderID, folderUID, folderName, folderScore),
+		data.NewFrame("dashboards", dashID, dashUID, dashURL, dashFolderID, dashName, dashDescr, dashTags, dashUpdated, dashScore),
+		data.NewFrame("panels", panelDashID, panelDashUID, panelID, panelName, panelDescr, panelType, panelScore),
+		data.NewFrame("entities", entityKind, entityUID, entityName, entityDescr, entityURL, entityFolderUID, entityTags, entityScore),
+	}
+}
@@ -4,29 +4,170 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/searchV2/extract"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type StandardSearchService struct {
-	sql  *sqlstore.SQLStore
-	auth FutureAuthService // eventually injected from elsewhere
+	cfg           *setting.Cfg
+	sql           *sqlstore.SQLStore
+	auth          FutureAuthService // eventually injected from elsewhere
+	ac            accesscontrol.AccessControl
+	features      featuremgmt.FeatureToggles
+	routeRegister routing.RouteRegister
+	log           log.Logger
+
+	indexMu sync.RWMutex
+	index   *dashboardIndex // nil until the index is opened by Run
+	updates chan indexUpdate
 }
 
-func ProvideService(sql *sqlstore.SQLStore) SearchService {
-	return &StandardSearchService{
+// indexUpdate is a dashboard or folder that was saved or deleted.
+type indexUpdate struct {
+	orgID    int64
+	id       int64
+	uid      string
+	isFolder bool
+	deleted  bool
+}
+
+// reconcileInterval is how often the index is compared with the database, to find the changes
+// made by other instances and the changes of the entities that have no events.
+const reconcileInterval = time.Minute
+
+func ProvideService(cfg *setting.Cfg, sql *sqlstore.SQLStore, bus bus.Bus, features featuremgmt.FeatureToggles, ac accesscontrol.AccessControl,
+	routeRegister routing.RouteRegister) *StandardSearchService {
+	s := &StandardSearchService{
+		cfg: cfg,
 		sql: sql,
 		auth: &simpleSQLAuthService{
 			sql: sql,
+			ac:  ac,
 		},
+		ac:            ac,
+		routeRegister: routeRegister,
+		features:      features,
+		log:           log.New("searchV2"),
+		updates:       make(chan indexUpdate, 1000),
+	}
+	if !s.IsDisabled() {
+		bus.AddEventListener(s.onDashboardSaved)
+		bus.AddEventListener(s.onDashboardDeleted)
+		s.registerAPIEndpoints()
+	}
+	return s
+}
+
+// IsDisabled returns true when the search index is not used, then all dashboards are read from
+// the database for each query.
+func (s *StandardSearchService) IsDisabled() bool {
+	return !s.features.IsEnabled(featuremgmt.FlagPanelTitleSearch)
+}
+
+func (s *StandardSearchService) indexPath() string {
+	return filepath.Join(s.cfg.DataPath, "search", "dashboards.bleve")
+}
+
+// Run opens the search index, and keeps it up to date until Grafana shuts down.
+func (s *StandardSearchService) Run(ctx context.Context) error {
+	index, err := openDashboardIndex(s.log, s.sql, s.indexPath())
+	if err != nil {
+		s.log.Error("Failed to open search index, dashboards are read from the database", "error", err)
+		<-ctx.Done()
+		return ctx.Err()
 	}
+	defer func() {
+		s.setIndex(nil)
+		if err := index.close(); err != nil {
+			s.log.Error("Failed to close search index", "error", err)
+		}
+	}()
+
+	if err := index.reconcile(ctx); err != nil {
+		s.log.Error("Failed to build search index", "error", err)
+	}
+	s.setIndex(index)
+
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case u := <-s.updates:
+			if err := s.applyUpdate(ctx, index, u); err != nil {
+				s.log.Error("Failed to update search index", "orgId", u.orgID, "uid", u.uid, "error", err)
+			}
+		case <-ticker.C:
+			if err := index.reconcile(ctx); err != nil {
+				s.log.Error("Failed to reconcile search index", "error", err)
+			}
+		}
+	}
+}
+
+func (s *StandardSearchService) setIndex(index *dashboardIndex) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	s.index = index
+}
+
+func (s *StandardSearchService) getIndex() *dashboardIndex {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+	return s.index
+}
+
+func (s *StandardSearchService) applyUpdate(ctx context.Context, index *dashboardIndex, u indexUpdate) error {
+	if u.deleted {
+		if u.isFolder {
+			return index.deleteFolder(u.orgID, u.id, u.uid)
+		}
+		return index.update(u.orgID, nil, []string{u.uid})
+	}
+
+	dashboards, err := loadDashboards(ctx, u.orgID, s.sql, u.id)
+	if err != nil {
+		return err
+	}
+	return index.update(u.orgID, dashboards, nil)
+}
+
+// enqueue queues an update of the index without blocking the request that changed the dashboard.
+// When the queue is full, the change is found by the next reconciliation.
+func (s *StandardSearchService) enqueue(u indexUpdate) {
+	select {
+	case s.updates <- u:
+	default:
+		s.log.Warn("Search index update queue is full", "orgId", u.orgID, "uid", u.uid)
+	}
+}
+
+func (s *StandardSearchService) onDashboardSaved(ctx context.Context, e *events.DashboardSaved) error {
+	s.enqueue(indexUpdate{orgID: e.OrgID, id: e.ID, uid: e.UID, isFolder: e.IsFolder})
+	return nil
+}
+
+func (s *StandardSearchService) onDashboardDeleted(ctx context.Context, e *events.DashboardDeleted) error {
+	s.enqueue(indexUpdate{orgID: e.OrgID, id: e.ID, uid: e.UID, isFolder: e.IsFolder, deleted: true})
+	return nil
 }
 
 type dashMeta struct {
@@ -42,9 +183,7 @@ type dashMeta struct {
 func (s *StandardSearchService) DoDashboardQuery(ctx context.Context, user *backend.User, orgId int64, query DashboardQuery) *backend.DataResponse {
 	rsp := &backend.DataResponse{}
 
-	// Load and parse all dashboards for given orgId
-	dash, err := loadDashboards(ctx, orgId, s.sql)
-	if err != nil {
+	if err := query.validate(); err != nil {
 		rsp.Error = err
 		return rsp
 	}
@@ -56,20 +195,39 @@ func (s *StandardSearchService) DoDashboardQuery(ctx context.Context, user *back
 		OrgId: orgId,
 	}
 
-	err = s.sql.GetSignedInUser(ctx, getSignedInUserQuery)
+	err := s.sql.GetSignedInUser(ctx, getSignedInUserQuery)
 	if err != nil {
-		fmt.Printf("error while retrieving user %s\n", err)
+		s.log.Warn("Error while retrieving user", "error", err)
 		rsp.Error = fmt.Errorf("auth error")
 		return rsp
 	}
 
 	if getSignedInUserQuery.Result == nil {
-		fmt.Printf("no user %s", user.Email)
+		s.log.Warn("No user", "email", user.Email)
 		rsp.Error = fmt.Errorf("auth error")
 		return rsp
 	}
 
-	dash, err = s.applyAuthFilter(getSignedInUserQuery.Result, dash)
+	if query.isSearch() {
+		rsp.Frames, rsp.Error = s.doSearch(ctx, getSignedInUserQuery.Result, query)
+		return rsp
+	}
+
+	index := s.getIndex()
+
+	// Load and parse all dashboards for given orgId
+	var dash []dashMeta
+	if index != nil {
+		dash = index.dashboards(orgId)
+	} else {
+		dash, err = loadDashboards(ctx, orgId, s.sql)
+		if err != nil {
+			rsp.Error = err
+			return rsp
+		}
+	}
+
+	dash, err = s.applyAuthFilter(ctx, getSignedInUserQuery.Result, dash)
 	if err != nil {
 		rsp.Error = err
 		return rsp
@@ -80,8 +238,47 @@ func (s *StandardSearchService) DoDashboardQuery(ctx context.Context, user *back
 	return rsp
 }
 
-func (s *StandardSearchService) applyAuthFilter(user *models.SignedInUser, dash []dashMeta) ([]dashMeta, error) {
-	filter, err := s.auth.GetDashboardReadFilter(user)
+// errIndexNotReady is returned by searches until the index is opened.
+var errIndexNotReady = errors.New("search index is not ready")
+
+// searchHits returns all hits of the query in the organization of the user that the user can
+// read, ordered by score.
+func (s *StandardSearchService) searchHits(ctx context.Context, user *models.SignedInUser, query DashboardQuery) ([]searchHit, error) {
+	index := s.getIndex()
+	if index == nil {
+		return nil, errIndexNotReady
+	}
+
+	filter, err := s.getHitFilter(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return index.search(user.OrgId, query, filter)
+}
+
+// limitHits returns the hits that are returned for the limit of the query.
+func limitHits(hits []searchHit, limit int) []searchHit {
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if len(hits) > limit {
+		return hits[:limit]
+	}
+	return hits
+}
+
+// doSearch returns the ranked results of the query that the user can read, followed by the
+// requested facets.
+func (s *StandardSearchService) doSearch(ctx context.Context, user *models.SignedInUser, query DashboardQuery) (data.Frames, error) {
+	hits, err := s.searchHits(ctx, user, query)
+	if err != nil {
+		return nil, err
+	}
+	return append(hitsToFrames(limitHits(hits, query.Limit)), facetFrames(hits, query.Facets)...), nil
+}
+
+func (s *StandardSearchService) applyAuthFilter(ctx context.Context, user *models.SignedInUser, dash []dashMeta) ([]dashMeta, error) {
+	filter, err := s.auth.GetDashboardReadFilter(ctx, user)
 	if err != nil {
 		return nil, err
 	}
@@ -106,22 +303,65 @@ type dashDataQueryResult struct {
 	Updated  time.Time
 }
 
-func loadDashboards(ctx context.Context, orgID int64, sql *sqlstore.SQLStore) ([]dashMeta, error) {
-	meta := make([]dashMeta, 0, 200)
+type datasourceQueryResult struct {
+	UID       string `xorm:"uid"`
+	Name      string `xorm:"name"`
+	Type      string `xorm:"type"`
+	IsDefault bool   `xorm:"is_default"`
+}
 
-	// key will allow name or uid
-	lookup := func(key string) *extract.DatasourceInfo {
-		return nil // TODO!
+// loadDatasourceLookup returns the lookup of the datasources of an organization by name or uid.
+func loadDatasourceLookup(sess *sqlstore.DBSession, orgID int64) (extract.DatasourceLookup, error) {
+	rows := make([]*datasourceQueryResult, 0)
+	err := sess.Table("data_source").
+		Where("org_id = ?", orgID).
+		Cols("uid", "name", "type", "is_default").
+		Find(&rows)
+	if err != nil {
+		return nil, err
 	}
 
+	byKey := make(map[string]*extract.DatasourceInfo, len(rows)*2+1)
+	for _, row := range rows {
+		ds := &extract.DatasourceInfo{
+			UID:  row.UID,
+			Name: row.Name,
+			Type: row.Type,
+		}
+		byKey[row.UID] = ds
+		byKey[row.Name] = ds
+		if row.IsDefault {
+			byKey[""] = ds
+		}
+	}
+
+	// key will allow name or uid
+	return func(key string) *extract.DatasourceInfo {
+		return byKey[key]
+	}, nil
+}
+
+// loadDashboards reads and parses the dashboards of an organization, or only the dashboards
+// with the given ids.
+func loadDashboards(ctx context.Context, orgID int64, sql *sqlstore.SQLStore, ids ...int64) ([]dashMeta, error) {
+	meta := make([]dashMeta, 0, 200)
+
 	err := sql.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		lookup, err := loadDatasourceLookup(sess, orgID)
+		if err != nil {
+			return err
+		}
+
 		rows := make([]*dashDataQueryResult, 0)
 
 		sess.Table("dashboard").
 			Where("org_id = ?", orgID).
 			Cols("id", "is_folder", "folder_id", "data", "slug", "created", "updated")
+		if len(ids) > 0 {
+			sess.In("id", ids)
+		}
 
-		err := sess.Find(&rows)
+		err = sess.Find(&rows)
 		if err != nil {
 			return err
 		}
@@ -168,6 +408,19 @@ func (c *simpleCounter) toFrame(name string) *data.Frame {
 	return data.NewFrame(name, key, val)
 }
 
+// tagsJSON returns the tags as a JSON array, or nil when there are none.
+func tagsJSON(tags []string) *string {
+	if len(tags) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(tags)
+	if err != nil {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
+
 // UGLY... but helpful for now
 func metaToFrame(meta []dashMeta) data.Frames {
 	folderID := data.NewFieldFromFieldType(data.FieldTypeInt64, 0)
@@ -232,7 +485,6 @@ func metaToFrame(meta []dashMeta) data.Frames {
 		values: make(map[string]int64, 30),
 	}
 
-	var tags *string
 	for _, row := range meta {
 		if row.is_folder {
 			folderID.Append(row.id)
@@ -245,7 +497,7 @@ func metaToFrame(meta []dashMeta) data.Frames {
 		dashUID.Append(row.dash.UID)
 		dashFolderID.Append(row.folder_id)
 		dashName.Append(row.dash.Title)
-		dashDescr.Append(row.dash.Title)
+		dashDescr.Append(row.dash.Description)
 		dashSchemaVersion.Append(row.dash.SchemaVersion)
 		dashCreated.Append(row.created)
 		dashUpdated.Append(row.updated)
@@ -256,16 +508,7 @@ func metaToFrame(meta []dashMeta) data.Frames {
 		// stats
 		schemaVersionCounter.add(strconv.FormatInt(row.dash.SchemaVersion, 10))
 
-		// Send tags as JSON array
-		tags = nil
-		if len(row.dash.Tags) > 0 {
-			b, err := json.Marshal(row.dash.Tags)
-			if err == nil {
-				s := string(b)
-				tags = &s
-			}
-		}
-		dashTags.Append(tags)
+		dashTags.Append(tagsJSON(row.dash.Tags))
 
 		// Row for each panel
 		for _, panel := range row.dash.Panels {
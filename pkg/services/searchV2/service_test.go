@@ -0,0 +1,510 @@
+package searchV2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuthService struct {
+	allowed map[string]bool
+}
+
+func (a *fakeAuthService) GetDashboardReadFilter(ctx context.Context, user *models.SignedInUser) (ResourceFilter, error) {
+	return func(uid string) bool {
+		return a.allowed == nil || a.allowed[uid]
+	}, nil
+}
+
+type testEnv struct {
+	sqlStore  *sqlstore.SQLStore
+	dashboard *database.DashboardStore
+	service   *StandardSearchService
+	auth      *fakeAuthService
+	user      *backend.User
+	orgID     int64
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	sqlStore := sqlstore.InitTestDB(t)
+	t.Cleanup(bus.ClearBusHandlers)
+
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	s := ProvideService(cfg, sqlStore, bus.GetBus(), featuremgmt.WithFeatures(featuremgmt.FlagPanelTitleSearch),
+		accesscontrolmock.New().WithDisabled(), routing.NewRouteRegister())
+	auth := &fakeAuthService{}
+	s.auth = auth
+
+	u, err := sqlStore.CreateUser(context.Background(), models.CreateUserCommand{Login: "viewer", Email: "viewer@localhost"})
+	require.NoError(t, err)
+
+	return &testEnv{
+		sqlStore:  sqlStore,
+		dashboard: database.ProvideDashboardStore(sqlStore),
+		service:   s,
+		auth:      auth,
+		user:      &backend.User{Login: u.Login, Email: u.Email},
+		orgID:     u.OrgId,
+	}
+}
+
+func (e *testEnv) saveDashboard(t *testing.T, folderID int64, isFolder bool, dash map[string]interface{}) *models.Dashboard {
+	t.Helper()
+	saved, err := e.dashboard.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     e.orgID,
+		FolderId:  folderID,
+		IsFolder:  isFolder,
+		Overwrite: true,
+		Dashboard: simplejson.NewFromAny(dash),
+	})
+	require.NoError(t, err)
+	return saved
+}
+
+// applyEvents applies the updates that were queued by the dashboard events.
+func (e *testEnv) applyEvents(t *testing.T, index *dashboardIndex) {
+	t.Helper()
+	for {
+		select {
+		case u := <-e.service.updates:
+			require.NoError(t, e.service.applyUpdate(context.Background(), index, u))
+		default:
+			return
+		}
+	}
+}
+
+func (e *testEnv) query(t *testing.T, q DashboardQuery) data.Frames {
+	t.Helper()
+	rsp := e.service.DoDashboardQuery(context.Background(), e.user, e.orgID, q)
+	require.NoError(t, rsp.Error)
+	return rsp.Frames
+}
+
+func frameByName(t *testing.T, frames data.Frames, name string) *data.Frame {
+	t.Helper()
+	for _, f := range frames {
+		if f.Name == name {
+			return f
+		}
+	}
+	require.Failf(t, "frame not found", "frame %q", name)
+	return nil
+}
+
+func stringValues(t *testing.T, frame *data.Frame, field string) []string {
+	t.Helper()
+	f, _ := frame.FieldByName(field)
+	require.NotNil(t, f, "field %q", field)
+	values := make([]string, 0, f.Len())
+	for i := 0; i < f.Len(); i++ {
+		values = append(values, f.At(i).(string))
+	}
+	return values
+}
+
+func facetCounts(t *testing.T, frames data.Frames, facet string) map[string]int64 {
+	t.Helper()
+	frame := frameByName(t, frames, "facet-"+facet)
+	counts := make(map[string]int64)
+	for i := 0; i < frame.Rows(); i++ {
+		counts[frame.Fields[0].At(i).(string)] = frame.Fields[1].At(i).(int64)
+	}
+	return counts
+}
+
+func graphDashboard(uid, title, description string, tags []interface{}, panels ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"uid":         uid,
+		"title":       title,
+		"description": description,
+		"tags":        tags,
+		"panels":      panels,
+	}
+}
+
+func panel(id int64, panelType, title string, ds map[string]interface{}, expr string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         id,
+		"type":       panelType,
+		"title":      title,
+		"datasource": ds,
+		"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": expr}},
+	}
+}
+
+var (
+	prometheus = map[string]interface{}{"uid": "prom", "type": "prometheus"}
+	loki       = map[string]interface{}{"uid": "loki", "type": "loki"}
+)
+
+func TestSearchService_Index(t *testing.T) {
+	e := setupTestEnv(t)
+	folder := e.saveDashboard(t, 0, true, map[string]interface{}{"uid": "infra", "title": "Infrastructure"})
+	nodes := e.saveDashboard(t, folder.Id, false, graphDashboard("nodes", "Node exporter", "CPU and memory of the hosts, and their cache",
+		[]interface{}{"linux", "prod"},
+		panel(1, "timeseries", "CPU usage", prometheus, `rate(node_cpu_seconds_total[5m])`),
+		panel(2, "stat", "Uptime", prometheus, `node_time_seconds - node_boot_time_seconds`),
+	))
+	e.saveDashboard(t, 0, false, graphDashboard("logs", "Application cache logs", "Errors of the memory cache",
+		[]interface{}{"prod"},
+		panel(1, "logs", "Error logs", loki, `{app="cache"} |= "error"`),
+		panel(2, "timeseries", "Error rate", loki, `rate({app="cache"} |= "error" [1m])`),
+	))
+
+	index, err := openDashboardIndex(log.New("test"), e.sqlStore, e.service.indexPath())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = index.close() })
+	require.NoError(t, index.reconcile(context.Background()))
+	e.service.setIndex(index)
+	e.applyEvents(t, index)
+
+	t.Run("empty query returns all dashboards", func(t *testing.T) {
+		frames := e.query(t, DashboardQuery{})
+		require.ElementsMatch(t, []string{"nodes", "logs"}, stringValues(t, frameByName(t, frames, "dashboards"), "UID"))
+		require.Equal(t, []string{"infra"}, stringValues(t, frameByName(t, frames, "folders"), "UID"))
+		require.Equal(t, 4, frameByName(t, frames, "panels").Rows())
+	})
+
+	t.Run("ranks titles higher than descriptions", func(t *testing.T) {
+		frames := e.query(t, DashboardQuery{Query: "cache", Kind: []string{kindDashboard}})
+		require.Equal(t, []string{"logs", "nodes"}, stringValues(t, frameByName(t, frames, "dashboards"), "UID"))
+
+		frames = e.query(t, DashboardQuery{Query: "logs"})
+		require.Equal(t, []string{"logs"}, stringValues(t, frameByName(t, frames, "dashboards"), "UID"))
+		require.Equal(t, []string{"Error logs"}, stringValues(t, frameByName(t, frames, "panels"), "Name"))
+	})
+
+	t.Run("matches panel queries and title prefixes", func(t *testing.T) {
+		frames := e.query(t, DashboardQuery{Query: "node_boot_time_seconds"})
+		require.Equal(t, []string{"Uptime"}, stringValues(t, frameByName(t, frames, "panels"), "Name"))
+
+		frames = e.query(t, DashboardQuery{Query: "infra"})
+		require.Equal(t, []string{"Infrastructure"}, stringValues(t, frameByName(t, frames, "folders"), "Name"))
+	})
+
+	t.Run("filters and facets", func(t *testing.T) {
+		frames := e.query(t, DashboardQuery{
+			DatasourceType: []string{"loki"},
+			Facets:         []string{FacetKind, FacetPanelType, FacetTags},
+		})
+		require.Equal(t, []string{"logs"}, stringValues(t, frameByName(t, frames, "dashboards"), "UID"))
+		require.Equal(t, 2, frameByName(t, frames, "panels").Rows())
+		assert.Equal(t, map[string]int64{kindDashboard: 1, kindPanel: 2}, facetCounts(t, frames, FacetKind))
+		assert.Equal(t, map[string]int64{"logs": 2, "timeseries": 2}, facetCounts(t, frames, FacetPanelType))
+		assert.Equal(t, map[string]int64{"prod": 3}, facetCounts(t, frames, FacetTags))
+
+		frames = e.query(t, DashboardQuery{Tags: []string{"linux"}, PanelType: []string{"stat"}, FolderIDs: []int64{folder.Id}})
+		require.Equal(t, []string{"nodes"}, stringValues(t, frameByName(t, frames, "dashboards"), "UID"))
+		require.Equal(t, []string{"Uptime"}, stringValues(t, frameByName(t, frames, "panels"), "Name"))
+
+		frames = e.query(t, DashboardQuery{Query: "error", Kind: []string{kindPanel}, Limit: 1})
+		require.Equal(t, 1, frameByName(t, frames, "panels").Rows())
+		require.Equal(t, 0, frameByName(t, frames, "dashboards").Rows())
+	})
+
+	t.Run("results and facets only include readable dashboards", func(t *testing.T) {
+		e.auth.allowed = map[string]bool{"nodes": true}
+		t.Cleanup(func() { e.auth.allowed = nil })
+
+		frames := e.query(t, DashboardQuery{Query: "memory", Facets: []string{FacetDatasource}})
+		require.Equal(t, []string{"nodes"}, stringValues(t, frameByName(t, frames, "dashboards"), "UID"))
+		assert.Equal(t, map[string]int64{"prom": 1}, facetCounts(t, frames, FacetDatasource))
+
+		frames = e.query(t, DashboardQuery{})
+		require.Equal(t, []string{"nodes"}, stringValues(t, frameByName(t, frames, "dashboards"), "UID"))
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		rsp := e.service.DoDashboardQuery(context.Background(), e.user, e.orgID, DashboardQuery{Facets: []string{"unknown"}})
+		require.Error(t, rsp.Error)
+		rsp = e.service.DoDashboardQuery(context.Background(), e.user, e.orgID, DashboardQuery{Kind: []string{"unknown"}})
+		require.Error(t, rsp.Error)
+	})
+
+	t.Run("updates the index on save and delete", func(t *testing.T) {
+		updated := graphDashboard("nodes", "Node exporter", "CPU and memory of the hosts",
+			[]interface{}{"linux", "prod"},
+			panel(1, "timeseries", "CPU usage", prometheus, `rate(node_cpu_seconds_total[5m])`),
+			panel(3, "gauge", "Disk usage", prometheus, `node_filesystem_avail_bytes`),
+		)
+		updated["id"] = nodes.Id
+		e.saveDashboard(t, folder.Id, false, updated)
+		e.applyEvents(t, index)
+
+		frames := e.query(t, DashboardQuery{Query: "uptime"})
+		require.Equal(t, 0, frameByName(t, frames, "panels").Rows())
+		frames = e.query(t, DashboardQuery{Query: "disk"})
+		require.Equal(t, []string{"Disk usage"}, stringValues(t, frameByName(t, frames, "panels"), "Name"))
+
+		require.NoError(t, e.sqlStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: folder.Id, OrgId: e.orgID}))
+		e.applyEvents(t, index)
+
+		frames = e.query(t, DashboardQuery{Query: "disk"})
+		require.Equal(t, 0, frameByName(t, frames, "panels").Rows())
+		frames = e.query(t, DashboardQuery{})
+		require.Equal(t, []string{"logs"}, stringValues(t, frameByName(t, frames, "dashboards"), "UID"))
+		require.Equal(t, 0, frameByName(t, frames, "folders").Rows())
+	})
+
+	t.Run("reopened index keeps the dashboards", func(t *testing.T) {
+		require.NoError(t, index.close())
+		reopened, err := openDashboardIndex(log.New("test"), e.sqlStore, e.service.indexPath())
+		require.NoError(t, err)
+		index = reopened
+
+		dashboards := reopened.dashboards(e.orgID)
+		require.Len(t, dashboards, 1)
+		require.Equal(t, "logs", dashboards[0].dash.UID)
+		require.Len(t, dashboards[0].dash.Panels, 2)
+		require.Equal(t, []string{"loki"}, dashboards[0].dash.Datasource)
+	})
+}
+
+func TestSearchService_Reconcile(t *testing.T) {
+	e := setupTestEnv(t)
+	e.saveDashboard(t, 0, false, graphDashboard("first", "First", "", nil))
+	second := e.saveDashboard(t, 0, false, graphDashboard("second", "Second", "", nil))
+	// changes of other instances are not received as events
+	bus.ClearBusHandlers()
+
+	index, err := openDashboardIndex(log.New("test"), e.sqlStore, "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = index.close() })
+	require.NoError(t, index.reconcile(context.Background()))
+	require.Len(t, index.dashboards(e.orgID), 2)
+
+	require.NoError(t, e.sqlStore.DeleteDashboard(context.Background(), &models.DeleteDashboardCommand{Id: second.Id, OrgId: e.orgID}))
+	e.saveDashboard(t, 0, false, graphDashboard("third", "Third", "", nil,
+		panel(1, "timeseries", "Requests", prometheus, `rate(http_requests_total[5m])`),
+	))
+	require.NoError(t, index.reconcile(context.Background()))
+
+	dashboards := index.dashboards(e.orgID)
+	require.Len(t, dashboards, 2)
+	require.Equal(t, "first", dashboards[0].dash.UID)
+	require.Equal(t, "third", dashboards[1].dash.UID)
+
+	hits, err := index.search(e.orgID, DashboardQuery{Query: "http_requests_total"}, func(hit searchHit) bool { return true })
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, kindPanel, hits[0].kind)
+}
+
+func (e *testEnv) addDatasource(t *testing.T, uid, name, dsType string) *models.DataSource {
+	t.Helper()
+	cmd := &models.AddDataSourceCommand{OrgId: e.orgID, Uid: uid, Name: name, Type: dsType, Access: models.DS_ACCESS_PROXY}
+	require.NoError(t, e.sqlStore.AddDataSource(context.Background(), cmd))
+	return cmd.Result
+}
+
+func (e *testEnv) addLibraryPanel(t *testing.T, folderID int64, uid string, model map[string]interface{}) {
+	t.Helper()
+	data, err := json.Marshal(model)
+	require.NoError(t, err)
+	err = e.sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec(`INSERT INTO library_element
+			(org_id, folder_id, uid, name, kind, type, description, model, created, created_by, updated, updated_by, version)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.orgID, folderID, uid, model["title"], libraryPanelKind, model["type"], "", string(data), time.Now(), 1, time.Now(), 1, 1)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func (e *testEnv) addAlertRule(t *testing.T, rule *ngmodels.AlertRule) {
+	t.Helper()
+	err := e.sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(rule)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestSearchService_Entities(t *testing.T) {
+	e := setupTestEnv(t)
+	folder := e.saveDashboard(t, 0, true, map[string]interface{}{"uid": "hosts", "title": "Hosts"})
+	e.addDatasource(t, "prom", "Prometheus", "prometheus")
+	e.addDatasource(t, "loki", "Loki", "loki")
+	e.addLibraryPanel(t, folder.Id, "shared-cpu", panel(1, "timeseries", "Shared CPU usage", prometheus, `node_cpu_seconds_total`))
+	e.addLibraryPanel(t, 0, "shared-errors", panel(1, "logs", "Shared error logs", loki, `{app="api"} |= "error"`))
+	e.addAlertRule(t, &ngmodels.AlertRule{
+		OrgID:        e.orgID,
+		UID:          "high-cpu",
+		Title:        "High CPU",
+		NamespaceUID: folder.Uid,
+		RuleGroup:    "hosts",
+		Condition:    "B",
+		Data: []ngmodels.AlertQuery{
+			{RefID: "A", DatasourceUID: "prom", Model: json.RawMessage(`{"expr": "avg(rate(node_cpu_seconds_total[5m]))"}`)},
+			{RefID: "B", DatasourceUID: expressionDatasourceUID, Model: json.RawMessage(`{"type": "threshold"}`)},
+		},
+		Labels:          map[string]string{"severity": "critical"},
+		Annotations:     map[string]string{"summary": "The CPU of a host is saturated"},
+		IntervalSeconds: 60,
+		Version:         1,
+		NoDataState:     ngmodels.NoData,
+		ExecErrState:    ngmodels.AlertingErrState,
+		Updated:         time.Now(),
+	})
+	playlist := &models.CreatePlaylistCommand{OrgId: e.orgID, Name: "Host overview", Interval: "5m",
+		Items: []models.PlaylistItemDTO{{Type: "dashboard_by_tag", Title: "linux", Value: "linux", Order: 1}}}
+	require.NoError(t, e.sqlStore.CreatePlaylist(context.Background(), playlist))
+
+	index, err := openDashboardIndex(log.New("test"), e.sqlStore, e.service.indexPath())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = index.close() })
+	require.NoError(t, index.reconcile(context.Background()))
+	e.service.setIndex(index)
+
+	entities := func(frames data.Frames) []string {
+		t.Helper()
+		frame := frameByName(t, frames, "entities")
+		kinds := stringValues(t, frame, "Kind")
+		uids := stringValues(t, frame, "UID")
+		keys := make([]string, 0, len(uids))
+		for i := range uids {
+			keys = append(keys, entityKey(kinds[i], uids[i]))
+		}
+		return keys
+	}
+
+	t.Run("finds where a metric is used", func(t *testing.T) {
+		frames := e.query(t, DashboardQuery{Query: "node_cpu_seconds_total"})
+		require.ElementsMatch(t, []string{"librarypanel:shared-cpu", "alertrule:high-cpu"}, entities(frames))
+	})
+
+	t.Run("finds where a datasource is used", func(t *testing.T) {
+		frames := e.query(t, DashboardQuery{Datasource: []string{"prom"}, Facets: []string{FacetKind}})
+		require.ElementsMatch(t, []string{"librarypanel:shared-cpu", "alertrule:high-cpu", "datasource:prom"}, entities(frames))
+		assert.Equal(t, map[string]int64{kindLibraryPanel: 1, kindAlertRule: 1, kindDatasource: 1}, facetCounts(t, frames, FacetKind))
+
+		frames = e.query(t, DashboardQuery{Tags: []string{"severity=critical"}, FolderIDs: []int64{folder.Id}})
+		require.Equal(t, []string{"alertrule:high-cpu"}, entities(frames))
+	})
+
+	t.Run("matches titles and descriptions", func(t *testing.T) {
+		frames := e.query(t, DashboardQuery{Query: "host", Kind: []string{kindPlaylist, kindAlertRule}})
+		require.ElementsMatch(t, []string{"playlist:" + strconv.FormatInt(playlist.Result.Id, 10), "alertrule:high-cpu"}, entities(frames))
+		require.Equal(t, "/playlists/play/"+strconv.FormatInt(playlist.Result.Id, 10), stringValues(t, frameByName(t, frames, "entities"), "URL")[0])
+	})
+
+	t.Run("checks the access to each kind", func(t *testing.T) {
+		e.auth.allowed = map[string]bool{}
+		e.service.ac = accesscontrolmock.New().WithPermissions([]*accesscontrol.Permission{
+			{Action: datasources.ActionDatasourcesRead, Scope: datasources.ScopeDatasourcesProvider.GetResourceScopeUID("loki")},
+		})
+		t.Cleanup(func() {
+			e.auth.allowed = nil
+			e.service.ac = accesscontrolmock.New().WithDisabled()
+		})
+
+		frames := e.query(t, DashboardQuery{Kind: []string{kindLibraryPanel, kindAlertRule, kindDatasource, kindPlaylist}})
+		require.ElementsMatch(t, []string{
+			"librarypanel:shared-errors", // in the General folder
+			"datasource:loki",
+			"playlist:" + strconv.FormatInt(playlist.Result.Id, 10),
+		}, entities(frames))
+
+		// alert rules also need the folder to be readable
+		e.auth.allowed = map[string]bool{"hosts": true}
+		e.service.ac = accesscontrolmock.New().WithPermissions([]*accesscontrol.Permission{
+			{Action: "alert.rules:read", Scope: "folders:*"},
+		})
+		frames = e.query(t, DashboardQuery{Kind: []string{kindAlertRule, kindDatasource}})
+		require.Equal(t, []string{"alertrule:high-cpu"}, entities(frames))
+	})
+
+	t.Run("loads entities in batches of uids", func(t *testing.T) {
+		uids := make([]string, 0, 2*uidBatchSize+2)
+		for i := 0; i < 2*uidBatchSize; i++ {
+			uids = append(uids, "missing-"+strconv.Itoa(i))
+		}
+		uids = append(uids, "shared-cpu", "high-cpu")
+
+		err := e.sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			panels, err := loadLibraryPanels(sess, e.orgID, uids)
+			require.NoError(t, err)
+			require.Len(t, panels, 1)
+			require.Equal(t, "shared-cpu", panels[0].UID)
+
+			rules, err := loadAlertRules(sess, e.orgID, uids)
+			require.NoError(t, err)
+			require.Len(t, rules, 1)
+			require.Equal(t, "high-cpu", rules[0].UID)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("search API", func(t *testing.T) {
+		rsp := e.searchAPI(t, "/api/search-v2?query=node_cpu_seconds_total&kind=alertrule&facet=datasource")
+		require.Equal(t, http.StatusOK, rsp.Status())
+
+		var res SearchResponse
+		require.NoError(t, json.Unmarshal(rsp.Body(), &res))
+		require.Equal(t, 1, res.Total)
+		require.Equal(t, "high-cpu", res.Hits[0].UID)
+		require.Equal(t, "/alerting/grafana/high-cpu/view", res.Hits[0].URL)
+		require.Equal(t, "hosts", res.Hits[0].FolderUID)
+		require.Equal(t, []FacetValue{{Value: "prom", Count: 1}}, res.Facets[FacetDatasource])
+
+		rsp = e.searchAPI(t, "/api/search-v2?kind=unknown")
+		require.Equal(t, http.StatusBadRequest, rsp.Status())
+	})
+
+	t.Run("reconciles changed and deleted entities", func(t *testing.T) {
+		require.NoError(t, e.sqlStore.DeletePlaylist(context.Background(), &models.DeletePlaylistCommand{Id: playlist.Result.Id, OrgId: e.orgID}))
+		require.NoError(t, e.sqlStore.DeleteDataSource(context.Background(), &models.DeleteDataSourceCommand{UID: "loki", OrgID: e.orgID}))
+		e.addDatasource(t, "tempo", "Tempo", "tempo")
+		require.NoError(t, index.reconcile(context.Background()))
+
+		frames := e.query(t, DashboardQuery{Kind: []string{kindDatasource, kindPlaylist}})
+		require.ElementsMatch(t, []string{"datasource:prom", "datasource:tempo"}, entities(frames))
+
+		require.NoError(t, index.close())
+		reopened, err := openDashboardIndex(log.New("test"), e.sqlStore, e.service.indexPath())
+		require.NoError(t, err)
+		index = reopened
+		e.service.setIndex(reopened)
+
+		rule, ok := reopened.entity(e.orgID, kindAlertRule, "high-cpu")
+		require.True(t, ok)
+		require.Equal(t, []string{"severity=critical"}, rule.Tags)
+		require.Equal(t, []string{"prom"}, rule.Datasource)
+		require.Equal(t, []string{"prometheus"}, rule.DatasourceType)
+	})
+}
+
+func (e *testEnv) searchAPI(t *testing.T, url string) response.Response {
+	t.Helper()
+	user := &models.SignedInUser{OrgId: e.orgID, OrgRole: models.ROLE_ADMIN, Login: e.user.Login}
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	return e.service.searchHandler(&models.ReqContext{Context: &web.Context{Req: req}, SignedInUser: user})
+}
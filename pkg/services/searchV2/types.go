@@ -7,7 +7,32 @@ import (
 )
 
 type DashboardQuery struct {
-	Query string
+	// Query is the free text that is matched with the titles, descriptions, tags and panel queries
+	Query string `json:"query"`
+	// Kind limits the results to dashboards, folders, panels, library panels, alert rules,
+	// datasources or playlists
+	Kind []string `json:"kind,omitempty"`
+	// Tags are the tags that all results must have
+	Tags []string `json:"tags,omitempty"`
+	// Datasource are the uids of the datasources, results must use one of them
+	Datasource []string `json:"datasource,omitempty"`
+	// DatasourceType are the plugin ids of the datasources, results must use one of them
+	DatasourceType []string `json:"datasourceType,omitempty"`
+	// PanelType are the plugin ids of the panels, results must contain one of them
+	PanelType []string `json:"panelType,omitempty"`
+	// FolderIDs are the folders the results must be in, 0 is the General folder
+	FolderIDs []int64 `json:"folderIds,omitempty"`
+	// Facets are the facets that are counted over all results
+	Facets []string `json:"facets,omitempty"`
+	// Limit is the maximum number of results, 100 by default
+	Limit int `json:"limit,omitempty"`
+}
+
+// isSearch returns true when the query needs the search index, an empty query returns all
+// dashboards of the organization.
+func (q DashboardQuery) isSearch() bool {
+	return q.Query != "" || len(q.Kind) > 0 || len(q.Tags) > 0 || len(q.Datasource) > 0 ||
+		len(q.DatasourceType) > 0 || len(q.PanelType) > 0 || len(q.FolderIDs) > 0 || len(q.Facets) > 0
 }
 
 type SearchService interface {
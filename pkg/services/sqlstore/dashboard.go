@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -351,6 +353,14 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 		}
 	}
 
+	sess.publishAfterCommit(&events.DashboardDeleted{
+		Timestamp: time.Now(),
+		ID:        dashboard.Id,
+		UID:       dashboard.Uid,
+		OrgID:     dashboard.OrgId,
+		IsFolder:  dashboard.IsFolder,
+	})
+
 	return nil
 }
 
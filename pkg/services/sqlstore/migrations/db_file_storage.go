@@ -2,8 +2,6 @@ package migrations
 
 import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 
-// TODO: remove nolint as part of https://github.com/grafana/grafana/issues/45498
-// nolint:unused,deadcode
 func addDbFileStorageMigration(mg *migrator.Migrator) {
 	filesTable := migrator.Table{
 		Name: "file",
@@ -39,3 +37,53 @@ func addDbFileStorageMigration(mg *migrator.Migrator) {
 	mg.AddMigration("create file_meta table", migrator.NewAddTableMigration(fileMetaTable))
 	mg.AddMigration("file table idx: path key", migrator.NewAddIndexMigration(fileMetaTable, fileMetaTable.Indices[0]))
 }
+
+func addDbFileStorageVersionsMigration(mg *migrator.Migrator) {
+	filesTable := migrator.Table{Name: "file"}
+	mg.AddMigration("add author column to file table", migrator.NewAddColumnMigration(filesTable, &migrator.Column{
+		Name: "author", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''",
+	}))
+
+	fileVersionTable := migrator.Table{
+		Name: "file_version",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "path", Type: migrator.DB_NVarchar, Length: 1024, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "contents", Type: migrator.DB_Blob, Nullable: false},
+			{Name: "size", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "mime_type", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "properties", Type: migrator.DB_Text, Nullable: false},
+			{Name: "author", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"path", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create file_version table", migrator.NewAddTableMigration(fileVersionTable))
+	mg.AddMigration("file_version table idx: path version", migrator.NewAddIndexMigration(fileVersionTable, fileVersionTable.Indices[0]))
+
+	fileTrashTable := migrator.Table{
+		Name: "file_trash",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "path", Type: migrator.DB_NVarchar, Length: 1024, Nullable: false},
+			{Name: "contents", Type: migrator.DB_Blob, Nullable: false},
+			{Name: "size", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "mime_type", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "properties", Type: migrator.DB_Text, Nullable: false},
+			{Name: "author", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "deleted", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"deleted"}},
+		},
+	}
+
+	mg.AddMigration("create file_trash table", migrator.NewAddTableMigration(fileTrashTable))
+	mg.AddMigration("file_trash table idx: deleted", migrator.NewAddIndexMigration(fileTrashTable, fileTrashTable.Indices[0]))
+}
@@ -2,9 +2,9 @@ package migrations
 
 import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 
-// For now disable migration. For now we are using local cache as storage to evaluate ideas.
-// This will be turned on soon though.
 func addLiveChannelMigrations(mg *migrator.Migrator) {
+	// For now disable live_message migration. For now we are using local cache as storage to evaluate ideas.
+	// This will be turned on soon though.
 	//liveMessage := migrator.Table{
 	//	Name: "live_message",
 	//	Columns: []*migrator.Column{
@@ -21,4 +21,41 @@ func addLiveChannelMigrations(mg *migrator.Migrator) {
 	//
 	//mg.AddMigration("create live message table", migrator.NewAddTableMigration(liveMessage))
 	//mg.AddMigration("add index live_message.org_id_channel_unique", migrator.NewAddIndexMigration(liveMessage, liveMessage.Indices[0]))
+
+	liveChannelRule := migrator.Table{
+		Name: "live_channel_rule",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "pattern", Type: migrator.DB_NVarchar, Length: 189, Nullable: false},
+			{Name: "settings", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "pattern"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live channel rule table", migrator.NewAddTableMigration(liveChannelRule))
+	mg.AddMigration("add index live_channel_rule.org_id_pattern_unique", migrator.NewAddIndexMigration(liveChannelRule, liveChannelRule.Indices[0]))
+
+	liveWriteConfig := migrator.Table{
+		Name: "live_write_config",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "settings", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "secure_settings", Type: migrator.DB_MediumText, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live write config table", migrator.NewAddTableMigration(liveWriteConfig))
+	mg.AddMigration("add index live_write_config.org_id_uid_unique", migrator.NewAddIndexMigration(liveWriteConfig, liveWriteConfig.Indices[0]))
 }
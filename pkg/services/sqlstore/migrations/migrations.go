@@ -86,6 +86,10 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 			addCommentGroupMigrations(mg)
 			addCommentMigrations(mg)
 		}
+		if mg.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagDbFileStorage) {
+			addDbFileStorageMigration(mg)
+			addDbFileStorageVersionsMigration(mg)
+		}
 	}
 }
 
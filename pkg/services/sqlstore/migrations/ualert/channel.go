@@ -375,9 +375,34 @@ func migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json,
 		}
 	}
 
+	if chanType == "webhook" {
+		migrateWebhookSettings(cloneSettings)
+	}
+
 	return cloneSettings, decryptedSecureSettings, nil
 }
 
+// migrateWebhookSettings converts the headers of legacy webhooks, one "Name: Value" per line, to a map.
+// The body template is removed because it is executed with the legacy webhook message, so the migrated
+// webhook sends the default webhook message instead.
+func migrateWebhookSettings(settings *simplejson.Json) {
+	settings.Del("body")
+
+	text := settings.Get("headers").MustString()
+	if text == "" {
+		return
+	}
+	headers := make(map[string]interface{})
+	for _, line := range strings.Split(text, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	settings.Set("headers", headers)
+}
+
 func getLabelForRouteMatching(ruleUID string) (string, string) {
 	return "rule_uid", ruleUID
 }
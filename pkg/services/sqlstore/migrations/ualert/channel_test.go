@@ -0,0 +1,24 @@
+package ualert
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateWebhookSettings(t *testing.T) {
+	settings := simplejson.NewFromAny(map[string]interface{}{
+		"url":     "http://localhost",
+		"body":    `{"title": "{{ .Title }}"}`,
+		"headers": "X-Team: ops\ninvalid\nX-Source: grafana",
+	})
+
+	migrated, _, err := migrateSettingsToSecureSettings("webhook", settings, SecureJsonData{})
+	require.NoError(t, err)
+
+	encoded, err := migrated.Encode()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"url": "http://localhost", "headers": {"X-Team": "ops", "X-Source": "grafana"}}`, string(encoded))
+}
@@ -25,6 +25,12 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create provisioning data table
 	AddProvisioningMigrations(mg)
+
+	// Create alert state history table
+	AddAlertStateHistoryMigrations(mg)
+
+	// Create notification log and retry queue tables
+	AddNotificationLogMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -234,6 +240,20 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 			Cols: []string{"org_id", "dashboard_uid", "panel_id"},
 		},
 	))
+
+	mg.AddMigration("add record_metric column to alert_rule", migrator.NewAddColumnMigration(
+		migrator.Table{Name: "alert_rule"},
+		&migrator.Column{
+			Name:     "record_metric",
+			Type:     migrator.DB_NVarchar,
+			Length:   190,
+			Nullable: true,
+		},
+	))
+
+	// add dependencies columns
+	mg.AddMigration("add column dependencies to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "dependencies", Type: migrator.DB_Text, Nullable: true}))
+	mg.AddMigration("add column dependency_action to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "dependency_action", Type: migrator.DB_NVarchar, Length: 40, Nullable: true}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -277,6 +297,13 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add record_metric column
+	mg.AddMigration("add column record_metric to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "record_metric", Type: migrator.DB_NVarchar, Length: 190, Nullable: true}))
+
+	// add dependencies columns
+	mg.AddMigration("add column dependencies to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "dependencies", Type: migrator.DB_Text, Nullable: true}))
+	mg.AddMigration("add column dependency_action to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "dependency_action", Type: migrator.DB_NVarchar, Length: 40, Nullable: true}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -349,3 +376,89 @@ func AddProvisioningMigrations(mg *migrator.Migrator) {
 	mg.AddMigration("create provenance_type table", migrator.NewAddTableMigration(provisioningTable))
 	mg.AddMigration("add index to uniquify (record_key, record_type, org_id) columns", migrator.NewAddIndexMigration(provisioningTable, provisioningTable.Indices[0]))
 }
+
+func AddAlertStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "eval_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "evaluated_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "evaluated_at"}, Type: migrator.IndexType},
+			{Cols: []string{"evaluated_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on org_id, rule_uid and evaluated_at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on evaluated_at column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+
+	// the namespace of the rule is kept with its transitions, the history of deleted rules is still restricted to their folder
+	mg.AddMigration("add namespace_uid column to alert_state_history", migrator.NewAddColumnMigration(stateHistory, &migrator.Column{
+		Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false, Default: "''",
+	}))
+	mg.AddMigration("set namespace_uid of existing transitions in alert_state_history", migrator.NewRawSQLMigration(
+		"UPDATE alert_state_history SET namespace_uid = COALESCE((SELECT alert_rule.namespace_uid FROM alert_rule WHERE alert_rule.org_id = alert_state_history.org_id AND alert_rule.uid = alert_state_history.rule_uid), '')"))
+}
+
+func AddNotificationLogMigrations(mg *migrator.Migrator) {
+	notificationLog := migrator.Table{
+		Name: "alert_notification_log",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "integration_name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_type", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "payload", Type: migrator.DB_Text, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "attempt", Type: migrator.DB_Int, Nullable: false},
+			{Name: "duration_ms", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "sent_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"sent_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_log table", migrator.NewAddTableMigration(notificationLog))
+	mg.AddMigration("add index in alert_notification_log on org_id and sent_at columns", migrator.NewAddIndexMigration(notificationLog, notificationLog.Indices[0]))
+	mg.AddMigration("add index in alert_notification_log on sent_at column", migrator.NewAddIndexMigration(notificationLog, notificationLog.Indices[1]))
+
+	notificationRetry := migrator.Table{
+		Name: "alert_notification_retry",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "integration_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "group_labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alerts", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "attempts", Type: migrator.DB_Int, Nullable: false},
+			{Name: "last_error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "next_attempt_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "next_attempt_at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "integration_uid"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_retry table", migrator.NewAddTableMigration(notificationRetry))
+	mg.AddMigration("add index in alert_notification_retry on org_id and next_attempt_at columns", migrator.NewAddIndexMigration(notificationRetry, notificationRetry.Indices[0]))
+	mg.AddMigration("add index in alert_notification_retry on org_id and integration_uid columns", migrator.NewAddIndexMigration(notificationRetry, notificationRetry.Indices[1]))
+}
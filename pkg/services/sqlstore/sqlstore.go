@@ -465,6 +465,8 @@ type InitTestDBOpt struct {
 var featuresEnabledDuringTests = []string{
 	featuremgmt.FlagDashboardPreviews,
 	featuremgmt.FlagDashboardComments,
+	featuremgmt.FlagDbFileStorage,
+	featuremgmt.FlagLiveConfig,
 }
 
 // InitTestDBWithMigration initializes the test DB given custom migrations.
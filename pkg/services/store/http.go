@@ -0,0 +1,411 @@
+package store
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// multipartOverhead is the room left in upload requests for the multipart encoding.
+	multipartOverhead = 1024 * 1024
+
+	// PropertyUploadedBy is the file property with the login of the user that uploaded the file.
+	// Blob stores lowercase metadata keys, so file properties are always lowercase.
+	PropertyUploadedBy = "uploaded_by"
+)
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, filestorage.ErrRootNotFound):
+		return response.Error(http.StatusNotFound, "Storage root not found", err)
+	case errors.Is(err, filestorage.ErrReadOnly):
+		return response.Error(http.StatusForbidden, "Storage root is read only", err)
+	case errors.Is(err, filestorage.ErrRelativePath), errors.Is(err, filestorage.ErrNonCanonicalPath),
+		errors.Is(err, filestorage.ErrPathTooLong), errors.Is(err, filestorage.ErrPathInvalid),
+		errors.Is(err, filestorage.ErrPathEndsWithDelimiter):
+		return response.Error(http.StatusBadRequest, "Invalid path", err)
+	case errors.Is(err, filestorage.ErrVersioningDisabled):
+		return response.Error(http.StatusBadRequest, "Versioning is not enabled for this storage root", err)
+	case errors.Is(err, filestorage.ErrVersionNotFound):
+		return response.Error(http.StatusNotFound, "File version not found", err)
+	case errors.Is(err, filestorage.ErrTrashedFileNotFound):
+		return response.Error(http.StatusNotFound, "File not found in trash", err)
+	case errors.Is(err, filestorage.ErrFileAlreadyExists):
+		return response.Error(http.StatusConflict, "File already exists", err)
+	default:
+		return response.Error(http.StatusInternalServerError, message, err)
+	}
+}
+
+// cleanPath turns a path of a URL or request into a path of a root.
+func cleanPath(p string) (string, error) {
+	p = filestorage.Join(p)
+	if p != filestorage.Delimiter {
+		p = strings.TrimSuffix(p, filestorage.Delimiter)
+	}
+	if err := filestorage.ValidatePath(p); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+// isAllowed checks the path against the path filters of the root. Folders are allowed when a
+// path in them is, so that the folders of allowed prefixes such as /uploads/ can be created.
+func isAllowed(root *filestorage.Root, p string, folder bool) bool {
+	if root.PathFilters.IsAllowed(p) {
+		return true
+	}
+	return folder && root.PathFilters.IsAllowed(p+filestorage.Delimiter)
+}
+
+// rootAndPath returns the root and the path in the root of the request.
+func (s *StorageHTTPService) rootAndPath(c *models.ReqContext) (*filestorage.Root, string, response.Response) {
+	params := web.Params(c.Req)
+	root, err := s.storage.Root(params[":root"])
+	if err != nil {
+		return nil, "", errorResponse(err, "Failed to get storage root")
+	}
+	p, err := cleanPath(params["*"])
+	if err != nil {
+		return nil, "", errorResponse(err, "Invalid path")
+	}
+	return root, p, nil
+}
+
+func (s *StorageHTTPService) getRoots(c *models.ReqContext) response.Response {
+	roots := make([]RootDTO, 0)
+	for _, root := range s.storage.Roots() {
+		if !s.accessControl.IsDisabled() {
+			ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser,
+				accesscontrol.EvalPermission(ActionStorageRead, ScopeRootsProvider.GetResourceScopeName(root.Name)))
+			if err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		roots = append(roots, RootDTO{
+			Name:      root.Name,
+			Type:      root.Type,
+			ReadOnly:  root.ReadOnly,
+			Versioned: root.Versions != nil,
+			Trash:     root.TrashRetention > 0,
+		})
+	}
+	return response.JSON(http.StatusOK, roots)
+}
+
+func (s *StorageHTTPService) list(c *models.ReqContext) response.Response {
+	root, folder, errResp := s.rootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	paging := &filestorage.Paging{
+		First: c.QueryInt("first"),
+		After: c.Query("after"),
+	}
+	res := ListResponse{
+		Folders: make([]FileDTO, 0),
+		Files:   make([]FileDTO, 0),
+	}
+
+	if paging.After == "" {
+		folders, err := root.Storage.ListFolders(c.Req.Context(), folder, &filestorage.ListOptions{})
+		if err != nil {
+			return errorResponse(err, "Failed to list folders")
+		}
+		for _, f := range folders {
+			res.Folders = append(res.Folders, fileDTO(f))
+		}
+	}
+
+	files, err := root.Storage.ListFiles(c.Req.Context(), folder, paging, &filestorage.ListOptions{})
+	if err != nil {
+		return errorResponse(err, "Failed to list files")
+	}
+	for _, f := range files.Files {
+		// a path of a file lists the file itself
+		if f.FullPath == folder && folder != filestorage.Delimiter {
+			return response.Error(http.StatusBadRequest, "Path is a file", nil)
+		}
+		res.Files = append(res.Files, fileDTO(f))
+	}
+	res.HasMore = files.HasMore
+	res.LastPath = files.LastPath
+
+	return response.JSON(http.StatusOK, res)
+}
+
+func (s *StorageHTTPService) read(c *models.ReqContext) response.Response {
+	root, p, errResp := s.rootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+	if !isAllowed(root, p, false) {
+		return response.Error(http.StatusForbidden, "Path is not allowed", nil)
+	}
+
+	var file *filestorage.File
+	var err error
+	if version := c.QueryInt64("version"); version > 0 {
+		if root.Versions == nil {
+			return errorResponse(filestorage.ErrVersioningDisabled, "")
+		}
+		file, err = root.Versions.GetVersion(c.Req.Context(), p, version)
+	} else {
+		file, err = root.Storage.Get(c.Req.Context(), p)
+	}
+	if err != nil {
+		return errorResponse(err, "Failed to read file")
+	}
+	if file == nil {
+		return response.Error(http.StatusNotFound, "File not found", nil)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", file.MimeType)
+	header.Set("X-Content-Type-Options", "nosniff")
+	// uploaded files such as SVG images can contain scripts
+	header.Set("Content-Security-Policy", "sandbox")
+	return response.CreateNormalResponse(header, file.Contents, http.StatusOK)
+}
+
+func (s *StorageHTTPService) upload(c *models.ReqContext) response.Response {
+	root, folder, errResp := s.rootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+	if root.ReadOnly {
+		return errorResponse(filestorage.ErrReadOnly, "")
+	}
+
+	maxSize := s.cfg.StorageMaxUploadSize
+	if c.Req.ContentLength > maxSize+multipartOverhead {
+		return response.Error(http.StatusRequestEntityTooLarge, "Upload is too large", nil)
+	}
+	c.Req.Body = http.MaxBytesReader(c.Resp, c.Req.Body, maxSize+multipartOverhead)
+	if err := c.Req.ParseMultipartForm(web.MaxMemory); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to parse the upload, it may be too large", err)
+	}
+	overwrite := c.Req.FormValue("overwrite") == "true"
+
+	headers := c.Req.MultipartForm.File["file"]
+	if len(headers) == 0 {
+		return response.Error(http.StatusBadRequest, "Missing multipart form field named 'file'", nil)
+	}
+
+	var total int64
+	for _, h := range headers {
+		total += h.Size
+	}
+	if total > maxSize {
+		return response.Error(http.StatusRequestEntityTooLarge, "Upload is too large", nil)
+	}
+
+	type upload struct {
+		path     string
+		mimeType string
+		contents []byte
+	}
+	uploads := make([]upload, 0, len(headers))
+	// validate all files before storing any of them
+	for _, h := range headers {
+		// browsers may send the path of the file on the client
+		name := path.Base(strings.ReplaceAll(h.Filename, "\\", "/"))
+		p, err := cleanPath(filestorage.Join(folder, name))
+		if err != nil {
+			return errorResponse(err, "Invalid file name")
+		}
+		if !isAllowed(root, p, false) {
+			return response.Error(http.StatusForbidden, "Path is not allowed", nil)
+		}
+
+		f, err := h.Open()
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Failed to read the upload", err)
+		}
+		contents, err := ioutil.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Failed to read the upload", err)
+		}
+
+		mimeType, err := detectMimeType(name, contents, s.cfg.StorageAllowedMimeTypes)
+		if err != nil {
+			return response.Error(http.StatusUnsupportedMediaType, err.Error(), nil)
+		}
+
+		if !overwrite {
+			existing, err := root.Storage.Get(c.Req.Context(), p)
+			if err != nil {
+				return errorResponse(err, "Failed to check the file")
+			}
+			if existing != nil {
+				return response.Error(http.StatusConflict, "File already exists", nil)
+			}
+		}
+
+		uploads = append(uploads, upload{path: p, mimeType: mimeType, contents: contents})
+	}
+
+	res := UploadResponse{
+		Message: "Files uploaded",
+		Files:   make([]string, 0, len(uploads)),
+	}
+	for i := range uploads {
+		u := uploads[i]
+		if err := root.Storage.Upsert(c.Req.Context(), &filestorage.UpsertFileCommand{
+			Path:       u.path,
+			MimeType:   u.mimeType,
+			Contents:   &u.contents,
+			Properties: map[string]string{PropertyUploadedBy: c.SignedInUser.Login},
+			Author:     c.SignedInUser.Login,
+		}); err != nil {
+			return errorResponse(err, "Failed to store file")
+		}
+		res.Files = append(res.Files, u.path)
+	}
+
+	return response.JSON(http.StatusOK, res)
+}
+
+func (s *StorageHTTPService) createFolder(c *models.ReqContext) response.Response {
+	root, p, errResp := s.rootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+	if p == filestorage.Delimiter {
+		return response.Error(http.StatusBadRequest, "Folder already exists", nil)
+	}
+	if !isAllowed(root, p, true) {
+		return response.Error(http.StatusForbidden, "Path is not allowed", nil)
+	}
+
+	if err := root.Storage.CreateFolder(c.Req.Context(), p); err != nil {
+		return errorResponse(err, "Failed to create folder")
+	}
+	return response.Success("Folder created")
+}
+
+func (s *StorageHTTPService) deleteFolder(c *models.ReqContext) response.Response {
+	root, p, errResp := s.rootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+	if p == filestorage.Delimiter {
+		return response.Error(http.StatusBadRequest, "The root folder cannot be deleted", nil)
+	}
+	if !isAllowed(root, p, true) {
+		return response.Error(http.StatusForbidden, "Path is not allowed", nil)
+	}
+
+	files, err := root.Storage.ListFiles(c.Req.Context(), p, &filestorage.Paging{First: 1}, &filestorage.ListOptions{Recursive: true})
+	if err != nil {
+		return errorResponse(err, "Failed to delete folder")
+	}
+	if len(files.Files) > 0 {
+		return response.Error(http.StatusConflict, "Folder is not empty", nil)
+	}
+
+	if err := root.Storage.DeleteFolder(c.Req.Context(), p); err != nil {
+		return errorResponse(err, "Failed to delete folder")
+	}
+	return response.Success("Folder deleted")
+}
+
+func (s *StorageHTTPService) delete(c *models.ReqContext) response.Response {
+	root, p, errResp := s.rootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+	if !isAllowed(root, p, false) {
+		return response.Error(http.StatusForbidden, "Path is not allowed", nil)
+	}
+
+	file, err := root.Storage.Get(c.Req.Context(), p)
+	if err != nil {
+		return errorResponse(err, "Failed to delete file")
+	}
+	if file == nil {
+		return response.Error(http.StatusNotFound, "File not found", nil)
+	}
+
+	if err := root.Storage.Delete(c.Req.Context(), p); err != nil {
+		return errorResponse(err, "Failed to delete file")
+	}
+	return response.Success("File deleted")
+}
+
+func (s *StorageHTTPService) move(c *models.ReqContext) response.Response {
+	root, err := s.storage.Root(web.Params(c.Req)[":root"])
+	if err != nil {
+		return errorResponse(err, "Failed to get storage root")
+	}
+
+	cmd := MoveCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	from, err := cleanPath(cmd.From)
+	if err != nil {
+		return errorResponse(err, "Invalid path")
+	}
+	to, err := cleanPath(cmd.To)
+	if err != nil {
+		return errorResponse(err, "Invalid path")
+	}
+	if from == to {
+		return response.Error(http.StatusBadRequest, "Source and destination are the same", nil)
+	}
+	if !isAllowed(root, from, false) || !isAllowed(root, to, false) {
+		return response.Error(http.StatusForbidden, "Path is not allowed", nil)
+	}
+
+	ctx := c.Req.Context()
+	file, err := root.Storage.Get(ctx, from)
+	if err != nil {
+		return errorResponse(err, "Failed to move file")
+	}
+	if file == nil {
+		return response.Error(http.StatusNotFound, "File not found", nil)
+	}
+	// the type of the file follows the extension of its new path, which must be allowed like in uploads
+	mimeType, err := detectMimeType(path.Base(to), file.Contents, s.cfg.StorageAllowedMimeTypes)
+	if err != nil {
+		return response.Error(http.StatusUnsupportedMediaType, err.Error(), nil)
+	}
+	if !cmd.Overwrite {
+		existing, err := root.Storage.Get(ctx, to)
+		if err != nil {
+			return errorResponse(err, "Failed to move file")
+		}
+		if existing != nil {
+			return response.Error(http.StatusConflict, "File already exists", nil)
+		}
+	}
+
+	if err := root.Storage.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:       to,
+		MimeType:   mimeType,
+		Contents:   &file.Contents,
+		Properties: file.Properties,
+		Author:     c.SignedInUser.Login,
+	}); err != nil {
+		return errorResponse(err, "Failed to move file")
+	}
+	if err := root.Storage.Delete(ctx, from); err != nil {
+		return errorResponse(err, "Failed to move file")
+	}
+	return response.Success("File moved")
+}
@@ -0,0 +1,377 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/stretchr/testify/require"
+)
+
+var pngContents = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func setupTestServer(t *testing.T, permissions []*accesscontrol.Permission) (*web.Mux, *filestorage.Service) {
+	t.Helper()
+	return setupTestServerWithRoots(t, permissions, []setting.StorageRootSettings{
+		{Name: "images", Type: filestorage.RootTypeMem},
+		{Name: "public", Type: filestorage.RootTypeMem, ReadOnly: true},
+		{Name: "uploads", Type: filestorage.RootTypeMem, AllowedPrefixes: []string{"/allowed/"}},
+	}, nil)
+}
+
+func setupTestServerWithRoots(t *testing.T, permissions []*accesscontrol.Permission, roots []setting.StorageRootSettings,
+	sqlStore *sqlstore.SQLStore) (*web.Mux, *filestorage.Service) {
+	t.Helper()
+
+	acmock := accesscontrolmock.New()
+	acmock.GetUserPermissionsFunc = func(ctx context.Context, user *models.SignedInUser, options accesscontrol.Options) ([]*accesscontrol.Permission, error) {
+		return permissions, nil
+	}
+	return setupTestServerForUser(t, acmock, roots, sqlStore, &models.SignedInUser{OrgId: 1, Login: "editor", OrgRole: models.ROLE_EDITOR})
+}
+
+func setupTestServerForUser(t *testing.T, ac accesscontrol.AccessControl, roots []setting.StorageRootSettings,
+	sqlStore *sqlstore.SQLStore, user *models.SignedInUser) (*web.Mux, *filestorage.Service) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.StorageMaxUploadSize = 1024
+	cfg.StorageAllowedMimeTypes = []string{"image/png", "application/geo+json", "text/plain"}
+	cfg.StorageRoots = roots
+	storage, err := filestorage.ProvideService(cfg, sqlStore, featuremgmt.WithFeatures(featuremgmt.FlagDbFileStorage))
+	require.NoError(t, err)
+
+	routeRegister := routing.NewRouteRegister()
+	_, err = ProvideService(cfg, storage, ac, routeRegister)
+	require.NoError(t, err)
+
+	m := web.New()
+	m.Use(func(c *web.Context) {
+		c.Map(&models.ReqContext{
+			Context:      c,
+			IsSignedIn:   true,
+			SignedInUser: user,
+			Logger:       log.New("storage-test"),
+		})
+	})
+	routeRegister.Register(m.Router)
+	return m, storage
+}
+
+func allPermissions(actions ...string) []*accesscontrol.Permission {
+	permissions := make([]*accesscontrol.Permission, 0, len(actions))
+	for _, action := range actions {
+		permissions = append(permissions, &accesscontrol.Permission{Action: action, Scope: ScopeRootsAll})
+	}
+	return permissions
+}
+
+func request(t *testing.T, m *web.Mux, method, url string, body io.Reader, contentType string) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest(method, url, body)
+	require.NoError(t, err)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	recorder := httptest.NewRecorder()
+	m.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func uploadRequest(t *testing.T, m *web.Mux, url string, files map[string][]byte) *httptest.ResponseRecorder {
+	t.Helper()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for name, contents := range files {
+		fw, err := w.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = fw.Write(contents)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return request(t, m, http.MethodPost, url, body, w.FormDataContentType())
+}
+
+func TestStorageAPI(t *testing.T) {
+	m, storage := setupTestServer(t, allPermissions(ActionStorageRead, ActionStorageWrite, ActionStorageDelete))
+
+	t.Run("roots", func(t *testing.T) {
+		res := request(t, m, http.MethodGet, "/api/storage/roots", nil, "")
+		require.Equal(t, http.StatusOK, res.Code)
+		var roots []RootDTO
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &roots))
+		require.Equal(t, []RootDTO{
+			{Name: "images", Type: "mem"},
+			{Name: "public", Type: "mem", ReadOnly: true},
+			{Name: "uploads", Type: "mem"},
+		}, roots)
+	})
+
+	t.Run("upload, list and read", func(t *testing.T) {
+		res := uploadRequest(t, m, "/api/storage/upload/images/dashboards", map[string][]byte{
+			"logo.png":  pngContents,
+			"C:\\x.txt": []byte("hello"),
+		})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		res = request(t, m, http.MethodGet, "/api/storage/list/images", nil, "")
+		require.Equal(t, http.StatusOK, res.Code)
+		var list ListResponse
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &list))
+		require.Len(t, list.Folders, 1)
+		require.Equal(t, "/dashboards", list.Folders[0].FullPath)
+		require.Empty(t, list.Files)
+
+		res = request(t, m, http.MethodGet, "/api/storage/list/images/dashboards", nil, "")
+		require.Equal(t, http.StatusOK, res.Code)
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &list))
+		require.Len(t, list.Files, 2)
+		require.Equal(t, "/dashboards/logo.png", list.Files[0].FullPath)
+		require.Equal(t, "image/png", list.Files[0].MimeType)
+		require.Equal(t, "editor", list.Files[0].Properties[PropertyUploadedBy])
+		require.Equal(t, "/dashboards/x.txt", list.Files[1].FullPath)
+
+		res = request(t, m, http.MethodGet, "/api/storage/read/images/dashboards/logo.png", nil, "")
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Equal(t, pngContents, res.Body.Bytes())
+		require.Equal(t, "image/png", res.Header().Get("Content-Type"))
+		require.Equal(t, "nosniff", res.Header().Get("X-Content-Type-Options"))
+
+		res = request(t, m, http.MethodGet, "/api/storage/read/images/dashboards/missing.png", nil, "")
+		require.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("upload of an existing file requires overwrite", func(t *testing.T) {
+		res := uploadRequest(t, m, "/api/storage/upload/images/dashboards", map[string][]byte{"logo.png": pngContents})
+		require.Equal(t, http.StatusConflict, res.Code)
+
+		res = uploadRequest(t, m, "/api/storage/upload/images/dashboards?overwrite=true", map[string][]byte{"logo.png": pngContents})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	})
+
+	t.Run("upload checks type and size", func(t *testing.T) {
+		res := uploadRequest(t, m, "/api/storage/upload/images", map[string][]byte{"page.html": []byte("<html></html>")})
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+
+		res = uploadRequest(t, m, "/api/storage/upload/images", map[string][]byte{"fake.png": []byte("<script></script>")})
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+
+		res = uploadRequest(t, m, "/api/storage/upload/images", map[string][]byte{"map.geojson": []byte("{")})
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+
+		res = uploadRequest(t, m, "/api/storage/upload/images", map[string][]byte{"big.txt": bytes.Repeat([]byte("a"), 2048)})
+		require.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+
+		res = uploadRequest(t, m, "/api/storage/upload/images", map[string][]byte{"map.geojson": []byte(`{"type":"FeatureCollection","features":[]}`)})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	})
+
+	t.Run("read only root", func(t *testing.T) {
+		res := uploadRequest(t, m, "/api/storage/upload/public", map[string][]byte{"logo.png": pngContents})
+		require.Equal(t, http.StatusForbidden, res.Code)
+
+		res = request(t, m, http.MethodPost, "/api/storage/folder/public/new", nil, "")
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("path filters", func(t *testing.T) {
+		res := uploadRequest(t, m, "/api/storage/upload/uploads/other", map[string][]byte{"logo.png": pngContents})
+		require.Equal(t, http.StatusForbidden, res.Code)
+
+		res = request(t, m, http.MethodPost, "/api/storage/folder/uploads/allowed", nil, "")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		res = uploadRequest(t, m, "/api/storage/upload/uploads/allowed", map[string][]byte{"logo.png": pngContents})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		res = request(t, m, http.MethodGet, "/api/storage/read/uploads/other/logo.png", nil, "")
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("move", func(t *testing.T) {
+		res := request(t, m, http.MethodPost, "/api/storage/move/images",
+			strings.NewReader(`{"from":"/dashboards/logo.png","to":"/dashboards/x.txt"}`), "application/json")
+		require.Equal(t, http.StatusConflict, res.Code)
+
+		// the destination is checked like an upload
+		res = request(t, m, http.MethodPost, "/api/storage/move/images",
+			strings.NewReader(`{"from":"/dashboards/x.txt","to":"/dashboards/page.html"}`), "application/json")
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		res = request(t, m, http.MethodPost, "/api/storage/move/images",
+			strings.NewReader(`{"from":"/dashboards/x.txt","to":"/dashboards/fake.png","overwrite":true}`), "application/json")
+		require.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+
+		res = request(t, m, http.MethodPost, "/api/storage/move/images",
+			strings.NewReader(`{"from":"/dashboards/x.txt","to":"/text/y.txt"}`), "application/json")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		root, err := storage.Root("images")
+		require.NoError(t, err)
+		file, err := root.Storage.Get(context.Background(), "/text/y.txt")
+		require.NoError(t, err)
+		require.Equal(t, []byte("hello"), file.Contents)
+		file, err = root.Storage.Get(context.Background(), "/dashboards/x.txt")
+		require.NoError(t, err)
+		require.Nil(t, file)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		res := request(t, m, http.MethodDelete, "/api/storage/folder/images/text", nil, "")
+		require.Equal(t, http.StatusConflict, res.Code)
+
+		res = request(t, m, http.MethodDelete, "/api/storage/delete/images/text/y.txt", nil, "")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		res = request(t, m, http.MethodDelete, "/api/storage/delete/images/text/y.txt", nil, "")
+		require.Equal(t, http.StatusNotFound, res.Code)
+
+		res = request(t, m, http.MethodDelete, "/api/storage/folder/images/text", nil, "")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	})
+
+	t.Run("unknown root", func(t *testing.T) {
+		res := request(t, m, http.MethodGet, "/api/storage/list/unknown", nil, "")
+		require.Equal(t, http.StatusNotFound, res.Code)
+	})
+}
+
+func TestStorageAPI_AccessControl(t *testing.T) {
+	m, _ := setupTestServer(t, []*accesscontrol.Permission{
+		{Action: ActionStorageRead, Scope: ScopeRootsProvider.GetResourceScopeName("images")},
+	})
+
+	res := request(t, m, http.MethodGet, "/api/storage/roots", nil, "")
+	require.Equal(t, http.StatusOK, res.Code)
+	var roots []RootDTO
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &roots))
+	require.Equal(t, []RootDTO{{Name: "images", Type: "mem"}}, roots)
+
+	res = request(t, m, http.MethodGet, "/api/storage/list/images", nil, "")
+	require.Equal(t, http.StatusOK, res.Code)
+
+	res = request(t, m, http.MethodGet, "/api/storage/list/uploads", nil, "")
+	require.Equal(t, http.StatusForbidden, res.Code)
+
+	res = uploadRequest(t, m, "/api/storage/upload/images", map[string][]byte{"logo.png": pngContents})
+	require.Equal(t, http.StatusForbidden, res.Code)
+}
+
+func TestStorageAPI_WithoutAccessControl(t *testing.T) {
+	roots := []setting.StorageRootSettings{{Name: "images", Type: filestorage.RootTypeMem}}
+
+	t.Run("editors can only read", func(t *testing.T) {
+		m, _ := setupTestServerForUser(t, accesscontrolmock.New().WithDisabled(), roots, nil,
+			&models.SignedInUser{OrgId: 1, Login: "editor", OrgRole: models.ROLE_EDITOR})
+
+		res := request(t, m, http.MethodGet, "/api/storage/list/images", nil, "")
+		require.Equal(t, http.StatusOK, res.Code)
+
+		res = uploadRequest(t, m, "/api/storage/upload/images", map[string][]byte{"logo.png": pngContents})
+		require.Equal(t, http.StatusForbidden, res.Code)
+
+		res = request(t, m, http.MethodDelete, "/api/storage/delete/images/logo.png", nil, "")
+		require.Equal(t, http.StatusForbidden, res.Code)
+	})
+
+	t.Run("grafana admins can write", func(t *testing.T) {
+		m, _ := setupTestServerForUser(t, accesscontrolmock.New().WithDisabled(), roots, nil,
+			&models.SignedInUser{OrgId: 1, Login: "admin", OrgRole: models.ROLE_VIEWER, IsGrafanaAdmin: true})
+
+		res := uploadRequest(t, m, "/api/storage/upload/images", map[string][]byte{"logo.png": pngContents})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+		res = request(t, m, http.MethodDelete, "/api/storage/delete/images/logo.png", nil, "")
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	})
+}
+
+func TestStorageAPI_Versions(t *testing.T) {
+	m, _ := setupTestServerWithRoots(t, allPermissions(ActionStorageRead, ActionStorageWrite, ActionStorageDelete),
+		[]setting.StorageRootSettings{
+			{Name: "assets", Type: filestorage.RootTypeDB, Versions: 5, TrashRetention: time.Hour},
+			{Name: "images", Type: filestorage.RootTypeMem},
+		}, sqlstore.InitTestDB(t))
+
+	upload := func(contents string) {
+		res := uploadRequest(t, m, "/api/storage/upload/assets/maps?overwrite=true", map[string][]byte{"world.geojson": []byte(contents)})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+	}
+	upload(`{"v":1}`)
+	upload(`{"v":2}`)
+
+	res := request(t, m, http.MethodGet, "/api/storage/roots", nil, "")
+	require.Equal(t, http.StatusOK, res.Code)
+	var roots []RootDTO
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &roots))
+	require.Equal(t, []RootDTO{
+		{Name: "assets", Type: "db", Versioned: true, Trash: true},
+		{Name: "images", Type: "mem"},
+	}, roots)
+
+	res = request(t, m, http.MethodGet, "/api/storage/versions/assets/maps/world.geojson", nil, "")
+	require.Equal(t, http.StatusOK, res.Code)
+	var versions []FileVersionDTO
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &versions))
+	require.Len(t, versions, 1)
+	require.Equal(t, int64(1), versions[0].Version)
+	require.Equal(t, "editor", versions[0].Author)
+	require.Equal(t, "/maps/world.geojson", versions[0].FullPath)
+
+	res = request(t, m, http.MethodGet, "/api/storage/read/assets/maps/world.geojson?version=1", nil, "")
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, `{"v":1}`, res.Body.String())
+
+	res = request(t, m, http.MethodGet, "/api/storage/read/assets/maps/world.geojson?version=7", nil, "")
+	require.Equal(t, http.StatusNotFound, res.Code)
+
+	res = request(t, m, http.MethodPost, "/api/storage/versions/assets/maps/world.geojson", strings.NewReader(`{"version":1}`), "application/json")
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	res = request(t, m, http.MethodGet, "/api/storage/read/assets/maps/world.geojson", nil, "")
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, `{"v":1}`, res.Body.String())
+
+	res = request(t, m, http.MethodDelete, "/api/storage/delete/assets/maps/world.geojson", nil, "")
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	res = request(t, m, http.MethodGet, "/api/storage/trash/assets", nil, "")
+	require.Equal(t, http.StatusOK, res.Code)
+	var trash []TrashedFileDTO
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &trash))
+	require.Len(t, trash, 1)
+	require.Equal(t, "/maps/world.geojson", trash[0].FullPath)
+
+	res = request(t, m, http.MethodPost, fmt.Sprintf("/api/storage/trash/restore/assets/%d", trash[0].ID), nil, "")
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	res = request(t, m, http.MethodPost, fmt.Sprintf("/api/storage/trash/restore/assets/%d", trash[0].ID), nil, "")
+	require.Equal(t, http.StatusNotFound, res.Code)
+
+	res = request(t, m, http.MethodGet, "/api/storage/read/assets/maps/world.geojson", nil, "")
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Equal(t, `{"v":1}`, res.Body.String())
+
+	// roots without versioning
+	res = request(t, m, http.MethodGet, "/api/storage/versions/images/logo.png", nil, "")
+	require.Equal(t, http.StatusBadRequest, res.Code)
+	res = request(t, m, http.MethodGet, "/api/storage/trash/images", nil, "")
+	require.Equal(t, http.StatusBadRequest, res.Code)
+}
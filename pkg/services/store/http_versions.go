@@ -0,0 +1,120 @@
+package store
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// versionedRootAndPath returns the root and the path of the request, and an error response if
+// the root does not keep versions.
+func (s *StorageHTTPService) versionedRootAndPath(c *models.ReqContext) (*filestorage.Root, string, response.Response) {
+	root, p, errResp := s.rootAndPath(c)
+	if errResp != nil {
+		return nil, "", errResp
+	}
+	if root.Versions == nil {
+		return nil, "", errorResponse(filestorage.ErrVersioningDisabled, "")
+	}
+	return root, p, nil
+}
+
+func (s *StorageHTTPService) listVersions(c *models.ReqContext) response.Response {
+	root, p, errResp := s.versionedRootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+	if !isAllowed(root, p, false) {
+		return response.Error(http.StatusForbidden, "Path is not allowed", nil)
+	}
+
+	versions, err := root.Versions.ListVersions(c.Req.Context(), p)
+	if err != nil {
+		return errorResponse(err, "Failed to list file versions")
+	}
+
+	res := make([]FileVersionDTO, 0, len(versions))
+	for _, v := range versions {
+		res = append(res, FileVersionDTO{
+			Version: v.Version,
+			Author:  v.Author,
+			FileDTO: fileDTO(v.FileMetadata),
+		})
+	}
+	return response.JSON(http.StatusOK, res)
+}
+
+func (s *StorageHTTPService) restoreVersion(c *models.ReqContext) response.Response {
+	root, p, errResp := s.versionedRootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+	if !isAllowed(root, p, false) {
+		return response.Error(http.StatusForbidden, "Path is not allowed", nil)
+	}
+
+	cmd := RestoreVersionCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Version <= 0 {
+		return response.Error(http.StatusBadRequest, "Missing version", nil)
+	}
+
+	if err := root.Versions.RestoreVersion(c.Req.Context(), p, cmd.Version, c.SignedInUser.Login); err != nil {
+		return errorResponse(err, "Failed to restore file version")
+	}
+	return response.Success("File version restored")
+}
+
+func (s *StorageHTTPService) listTrash(c *models.ReqContext) response.Response {
+	root, folder, errResp := s.versionedRootAndPath(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	trashed, err := root.Versions.ListTrash(c.Req.Context(), folder)
+	if err != nil {
+		return errorResponse(err, "Failed to list trash")
+	}
+
+	res := make([]TrashedFileDTO, 0, len(trashed))
+	for _, t := range trashed {
+		res = append(res, TrashedFileDTO{
+			ID:      t.ID,
+			Author:  t.Author,
+			Deleted: t.Deleted,
+			FileDTO: fileDTO(t.FileMetadata),
+		})
+	}
+	return response.JSON(http.StatusOK, res)
+}
+
+func (s *StorageHTTPService) restoreFromTrash(c *models.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	root, err := s.storage.Root(params[":root"])
+	if err != nil {
+		return errorResponse(err, "Failed to get storage root")
+	}
+	if root.Versions == nil {
+		return errorResponse(filestorage.ErrVersioningDisabled, "")
+	}
+	id, err := strconv.ParseInt(params[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid id", err)
+	}
+
+	restored, err := root.Versions.RestoreFromTrash(c.Req.Context(), id, c.SignedInUser.Login)
+	if err != nil {
+		return errorResponse(err, "Failed to restore file")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "File restored",
+		"path":    restored.FullPath,
+	})
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// extensionMimeTypes are the MIME types of extensions that are not known to
+// the standard library on every system.
+var extensionMimeTypes = map[string]string{
+	".geojson": "application/geo+json",
+	".json":    "application/json",
+	".csv":     "text/csv",
+	".txt":     "text/plain",
+	".svg":     "image/svg+xml",
+}
+
+// detectMimeType returns the MIME type of an uploaded file based on its extension, and
+// returns an error if the type is not allowed or the contents do not match the type.
+func detectMimeType(name string, contents []byte, allowed []string) (string, error) {
+	ext := strings.ToLower(path.Ext(name))
+	mimeType, ok := extensionMimeTypes[ext]
+	if !ok {
+		mimeType = mime.TypeByExtension(ext)
+	}
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		mimeType = mediaType
+	}
+	if mimeType == "" {
+		return "", fmt.Errorf("unknown file type of %s", name)
+	}
+
+	isAllowed := false
+	for _, a := range allowed {
+		if strings.EqualFold(a, mimeType) {
+			isAllowed = true
+			break
+		}
+	}
+	if !isAllowed {
+		return "", fmt.Errorf("file type %s is not allowed", mimeType)
+	}
+
+	switch {
+	case mimeType == "application/json" || mimeType == "application/geo+json":
+		if !json.Valid(contents) {
+			return "", fmt.Errorf("%s is not valid JSON", name)
+		}
+	case strings.HasPrefix(mimeType, "image/") && mimeType != "image/svg+xml":
+		// binary images are sniffed, so that other content cannot be uploaded as an image
+		if sniffed := http.DetectContentType(contents); sniffed != mimeType {
+			return "", fmt.Errorf("contents of %s do not match the file type %s", name, mimeType)
+		}
+	}
+
+	return mimeType, nil
+}
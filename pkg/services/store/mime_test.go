@@ -0,0 +1,42 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectMimeType(t *testing.T) {
+	allowed := []string{"image/png", "image/svg+xml", "application/json", "application/geo+json", "text/csv"}
+
+	tests := []struct {
+		name     string
+		file     string
+		contents []byte
+		expected string
+		err      string
+	}{
+		{name: "png", file: "logo.PNG", contents: pngContents, expected: "image/png"},
+		{name: "png with other contents", file: "logo.png", contents: []byte("GIF89a"), err: "do not match"},
+		{name: "svg is not sniffed", file: "icon.svg", contents: []byte("<svg></svg>"), expected: "image/svg+xml"},
+		{name: "json", file: "data.json", contents: []byte(`{"a":1}`), expected: "application/json"},
+		{name: "invalid json", file: "data.json", contents: []byte(`{"a":`), err: "not valid JSON"},
+		{name: "geojson", file: "map.geojson", contents: []byte(`{}`), expected: "application/geo+json"},
+		{name: "csv", file: "data.csv", contents: []byte("a,b\n1,2"), expected: "text/csv"},
+		{name: "not allowed", file: "notes.txt", contents: []byte("hello"), err: "not allowed"},
+		{name: "unknown extension", file: "archive.unknownext", contents: []byte("x"), err: "unknown file type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mimeType, err := detectMimeType(tt.file, tt.contents, allowed)
+			if tt.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, mimeType)
+		})
+	}
+}
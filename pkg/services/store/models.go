@@ -0,0 +1,96 @@
+package store
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	ActionStorageRead   = "storage:read"
+	ActionStorageWrite  = "storage:write"
+	ActionStorageDelete = "storage:delete"
+)
+
+var (
+	// ScopeRootsAll is the scope of all storage roots.
+	ScopeRootsAll = accesscontrol.GetResourceAllScope("storage")
+	// ScopeRootsProvider builds the scope of a storage root by its name.
+	ScopeRootsProvider = accesscontrol.NewScopeProvider("storage")
+	// ScopeRoot is the scope of the root in the URL of a request.
+	ScopeRoot = accesscontrol.GetResourceScopeName("storage", accesscontrol.Parameter(":root"))
+)
+
+// RootDTO describes a storage root.
+type RootDTO struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	ReadOnly  bool   `json:"readOnly"`
+	Versioned bool   `json:"versioned"`
+	Trash     bool   `json:"trash"`
+}
+
+// FileDTO describes a file or a folder of a storage root.
+type FileDTO struct {
+	Name       string            `json:"name"`
+	FullPath   string            `json:"fullPath"`
+	MimeType   string            `json:"mimeType,omitempty"`
+	Size       int64             `json:"size"`
+	Created    time.Time         `json:"created"`
+	Modified   time.Time         `json:"modified"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// ListResponse is the content of a folder. Folders are only listed on the first page.
+type ListResponse struct {
+	Folders  []FileDTO `json:"folders"`
+	Files    []FileDTO `json:"files"`
+	HasMore  bool      `json:"hasMore"`
+	LastPath string    `json:"lastPath,omitempty"`
+}
+
+// UploadResponse lists the paths of the uploaded files.
+type UploadResponse struct {
+	Message string   `json:"message"`
+	Files   []string `json:"files"`
+}
+
+// MoveCommand moves a file to another path in the same root.
+type MoveCommand struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Overwrite bool   `json:"overwrite"`
+}
+
+// FileVersionDTO describes a previous version of a file.
+type FileVersionDTO struct {
+	Version int64  `json:"version"`
+	Author  string `json:"author"`
+	FileDTO
+}
+
+// TrashedFileDTO describes a deleted file in the trash of a root.
+type TrashedFileDTO struct {
+	ID      int64     `json:"id"`
+	Author  string    `json:"author"`
+	Deleted time.Time `json:"deleted"`
+	FileDTO
+}
+
+// RestoreVersionCommand overwrites a file with one of its previous versions.
+type RestoreVersionCommand struct {
+	Version int64 `json:"version"`
+}
+
+func fileDTO(m filestorage.FileMetadata) FileDTO {
+	return FileDTO{
+		Name:       m.Name,
+		FullPath:   m.FullPath,
+		MimeType:   m.MimeType,
+		Size:       m.Size,
+		Created:    m.Created,
+		Modified:   m.Modified,
+		Properties: m.Properties,
+	}
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// StorageHTTPService exposes the roots of the file storage through the /api/storage HTTP API.
+type StorageHTTPService struct {
+	cfg           *setting.Cfg
+	storage       *filestorage.Service
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+	log           log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg, storage *filestorage.Service, accessControl accesscontrol.AccessControl,
+	routeRegister routing.RouteRegister) (*StorageHTTPService, error) {
+	s := &StorageHTTPService{
+		cfg:           cfg,
+		storage:       storage,
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+		log:           log.New("storage.api"),
+	}
+
+	if err := registerRoles(accessControl); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func registerRoles(ac accesscontrol.AccessControl) error {
+	reader := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Version:     1,
+			Name:        "fixed:storage:reader",
+			DisplayName: "Storage reader",
+			Description: "Browse and read the files of all storage roots.",
+			Group:       "Storage",
+			Permissions: []accesscontrol.Permission{
+				{Action: ActionStorageRead, Scope: ScopeRootsAll},
+			},
+		},
+		Grants: []string{string(models.ROLE_VIEWER)},
+	}
+
+	writer := accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Version:     1,
+			Name:        "fixed:storage:writer",
+			DisplayName: "Storage writer",
+			Description: "Browse, upload, move, and delete the files of all storage roots. The roots are shared by all organizations.",
+			Group:       "Storage",
+			Permissions: []accesscontrol.Permission{
+				{Action: ActionStorageRead, Scope: ScopeRootsAll},
+				{Action: ActionStorageWrite, Scope: ScopeRootsAll},
+				{Action: ActionStorageDelete, Scope: ScopeRootsAll},
+			},
+		},
+		// the roots are shared by all organizations, so only server admins can change them
+		Grants: []string{accesscontrol.RoleGrafanaAdmin},
+	}
+
+	return ac.DeclareFixedRoles(reader, writer)
+}
+
+func (s *StorageHTTPService) registerAPIEndpoints() {
+	auth := acmiddleware.Middleware(s.accessControl)
+	canRead := auth(middleware.ReqSignedIn, accesscontrol.EvalPermission(ActionStorageRead, ScopeRoot))
+	canWrite := auth(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(ActionStorageWrite, ScopeRoot))
+	canDelete := auth(middleware.ReqGrafanaAdmin, accesscontrol.EvalPermission(ActionStorageDelete, ScopeRoot))
+	canMove := auth(middleware.ReqGrafanaAdmin, accesscontrol.EvalAll(
+		accesscontrol.EvalPermission(ActionStorageWrite, ScopeRoot),
+		accesscontrol.EvalPermission(ActionStorageDelete, ScopeRoot),
+	))
+
+	s.routeRegister.Group("/api/storage", func(storageRoute routing.RouteRegister) {
+		storageRoute.Get("/roots", middleware.ReqSignedIn, routing.Wrap(s.getRoots))
+
+		// the path in the root is optional, no path is the root folder
+		for _, p := range []string{"/:root", "/:root/*"} {
+			storageRoute.Get("/list"+p, canRead, routing.Wrap(s.list))
+			storageRoute.Get("/read"+p, canRead, routing.Wrap(s.read))
+			storageRoute.Post("/upload"+p, canWrite, routing.Wrap(s.upload))
+			storageRoute.Post("/folder"+p, canWrite, routing.Wrap(s.createFolder))
+			storageRoute.Delete("/folder"+p, canDelete, routing.Wrap(s.deleteFolder))
+			storageRoute.Delete("/delete"+p, canDelete, routing.Wrap(s.delete))
+			storageRoute.Get("/versions"+p, canRead, routing.Wrap(s.listVersions))
+			storageRoute.Post("/versions"+p, canWrite, routing.Wrap(s.restoreVersion))
+			storageRoute.Get("/trash"+p, canRead, routing.Wrap(s.listTrash))
+		}
+		storageRoute.Post("/trash/restore/:root/:id", canWrite, routing.Wrap(s.restoreFromTrash))
+		storageRoute.Post("/move/:root", canMove, routing.Wrap(s.move))
+	})
+}
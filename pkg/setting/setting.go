@@ -231,6 +231,13 @@ type Cfg struct {
 	// SMTP email settings
 	Smtp SmtpSettings
 
+	// StorageRoots are the named roots of the file storage.
+	StorageRoots []StorageRootSettings
+	// StorageMaxUploadSize is the maximum size in bytes of files uploaded to the file storage.
+	StorageMaxUploadSize int64
+	// StorageAllowedMimeTypes are the MIME types of the files that can be uploaded to the file storage.
+	StorageAllowedMimeTypes []string
+
 	// Rendering
 	ImagesDir                      string
 	CSVsDir                        string
@@ -402,6 +409,14 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+	// ExpressionsQueryCacheTTL is how long data source query results of expressions are cached. Zero disables the cache.
+	ExpressionsQueryCacheTTL time.Duration
+	// ExpressionsQueryCacheAlignment is the interval the time range of queries is truncated to for the cache key.
+	ExpressionsQueryCacheAlignment time.Duration
+	// ExpressionsQueryCacheMaxEntries is the maximum number of query results in the cache.
+	ExpressionsQueryCacheMaxEntries int
+	// ExpressionsQueryDedupEnabled specifies whether identical data source queries in flight are sent only once.
+	ExpressionsQueryDedupEnabled bool
 
 	ImageUploadProvider string
 
@@ -417,6 +432,18 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveHistorySize is a maximum number of publications of managed stream
+	// channels kept in channel history. 0 disables the history.
+	LiveHistorySize int
+	// LiveHistoryTTL is how long the history of a managed stream channel is
+	// kept after its last publication.
+	LiveHistoryTTL time.Duration
+	// LiveMQTT configures the ingestion of MQTT topics into Live channels.
+	LiveMQTT LiveMQTTSettings
+	// LiveKafka configures the Kafka outputs of the Live pipeline and the ingestion of Kafka topics.
+	LiveKafka LiveKafkaSettings
+	// LiveNATS configures the NATS outputs of the Live pipeline and the ingestion of NATS subjects.
+	LiveNATS LiveNATSSettings
 
 	// Grafana.com URL
 	GrafanaComURL string
@@ -597,6 +624,10 @@ func (cfg *Cfg) readAnnotationSettings() {
 func (cfg *Cfg) readExpressionsSettings() {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
+	cfg.ExpressionsQueryCacheTTL = expressions.Key("query_cache_ttl").MustDuration(0)
+	cfg.ExpressionsQueryCacheAlignment = expressions.Key("query_cache_time_alignment").MustDuration(10 * time.Second)
+	cfg.ExpressionsQueryCacheMaxEntries = expressions.Key("query_cache_max_entries").MustInt(1000)
+	cfg.ExpressionsQueryDedupEnabled = expressions.Key("query_dedup_enabled").MustBool(false)
 }
 
 type AnnotationCleanupSettings struct {
@@ -974,6 +1005,9 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.readQuotaSettings()
 	cfg.readAnnotationSettings()
 	cfg.readExpressionsSettings()
+	if err := cfg.readStorageSettings(iniFile); err != nil {
+		return err
+	}
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
 	}
@@ -1027,6 +1061,18 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		return err
 	}
 
+	if err := cfg.readLiveMQTTSettings(iniFile); err != nil {
+		return err
+	}
+
+	if err := cfg.readLiveKafkaSettings(iniFile); err != nil {
+		return err
+	}
+
+	if err := cfg.readLiveNATSSettings(iniFile); err != nil {
+		return err
+	}
+
 	cfg.LogConfigSources()
 
 	return nil
@@ -1535,6 +1581,19 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 
+	cfg.LiveHistorySize = section.Key("history_size").MustInt(0)
+	if cfg.LiveHistorySize < 0 {
+		return fmt.Errorf("unexpected value %d for [live] history_size", cfg.LiveHistorySize)
+	}
+	historyTTL, err := gtime.ParseDuration(valueAsString(section, "history_ttl", "10m"))
+	if err != nil {
+		return fmt.Errorf("unexpected value for [live] history_ttl: %w", err)
+	}
+	if historyTTL < time.Second {
+		return fmt.Errorf("unexpected value %s for [live] history_ttl, it must be at least 1s", historyTTL)
+	}
+	cfg.LiveHistoryTTL = historyTTL
+
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	for _, originPattern := range strings.Split(allowedOrigins, ",") {
@@ -1544,7 +1603,7 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		}
 		originPatterns = append(originPatterns, originPattern)
 	}
-	_, err := GetAllowedOriginGlobs(originPatterns)
+	_, err = GetAllowedOriginGlobs(originPatterns)
 	if err != nil {
 		return err
 	}
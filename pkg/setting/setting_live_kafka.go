@@ -0,0 +1,70 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// LiveKafkaSettings configures the connection of Live to Kafka, which is used by the
+// Kafka outputs of the Live pipeline and to consume Kafka topics into Live channels.
+type LiveKafkaSettings struct {
+	Enabled  bool
+	Brokers  []string
+	Version  string
+	ClientID string
+	// Username and Password enable SASL/PLAIN authentication when set.
+	Username string
+	Password string
+	TLS      bool
+	// OrgID is the organization of the channels the messages are pushed to.
+	OrgID int64
+	// ConsumerGroup of the Grafana servers, each message is only received by one
+	// Grafana server of a HA setup.
+	ConsumerGroup string
+	// InitialOffset is either "newest" or "oldest", it is used when the group has no committed offset.
+	InitialOffset string
+	Topics        []LiveKafkaTopic
+	// OutputTopicPrefixes are the prefixes of the topics the Kafka outputs can publish to,
+	// outputs to other topics are rejected.
+	OutputTopicPrefixes []string
+}
+
+// LiveKafkaTopic maps a Kafka topic to a Live channel.
+type LiveKafkaTopic struct {
+	Topic   string
+	Channel string
+}
+
+func (cfg *Cfg) readLiveKafkaSettings(iniFile *ini.File) error {
+	section := iniFile.Section("live.kafka")
+	cfg.LiveKafka.Enabled = section.Key("enabled").MustBool(false)
+	cfg.LiveKafka.Brokers = util.SplitString(section.Key("brokers").MustString("127.0.0.1:9092"))
+	cfg.LiveKafka.Version = section.Key("version").MustString("2.0.0")
+	cfg.LiveKafka.ClientID = section.Key("client_id").MustString("grafana")
+	cfg.LiveKafka.Username = section.Key("username").String()
+	cfg.LiveKafka.Password = section.Key("password").String()
+	cfg.LiveKafka.TLS = section.Key("tls").MustBool(false)
+	cfg.LiveKafka.OrgID = section.Key("org_id").MustInt64(1)
+	cfg.LiveKafka.ConsumerGroup = section.Key("consumer_group").MustString("grafana")
+
+	cfg.LiveKafka.InitialOffset = section.Key("initial_offset").MustString("newest")
+	if cfg.LiveKafka.InitialOffset != "newest" && cfg.LiveKafka.InitialOffset != "oldest" {
+		return fmt.Errorf("unexpected value %q for [live.kafka] initial_offset, use newest or oldest", cfg.LiveKafka.InitialOffset)
+	}
+
+	cfg.LiveKafka.OutputTopicPrefixes = util.SplitString(section.Key("output_topic_prefixes").String())
+
+	cfg.LiveKafka.Topics = nil
+	for _, mapping := range util.SplitString(strings.TrimSpace(section.Key("topics").String())) {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("unexpected value %q for [live.kafka] topics, use <topic>=<channel>", mapping)
+		}
+		cfg.LiveKafka.Topics = append(cfg.LiveKafka.Topics, LiveKafkaTopic{Topic: parts[0], Channel: parts[1]})
+	}
+	return nil
+}
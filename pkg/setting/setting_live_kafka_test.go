@@ -0,0 +1,59 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestLiveKafkaSettings(t *testing.T) {
+	iniFile, err := ini.Load([]byte(`
+[live.kafka]
+enabled = true
+brokers = kafka1:9092, kafka2:9092
+username = grafana
+password = secret
+tls = true
+org_id = 2
+initial_offset = oldest
+topics = temperature=stream/sensors/temperature, line1=stream/plant/line1
+output_topic_prefixes = grafana-live-
+`))
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.readLiveKafkaSettings(iniFile))
+	require.Equal(t, LiveKafkaSettings{
+		Enabled:       true,
+		Brokers:       []string{"kafka1:9092", "kafka2:9092"},
+		Version:       "2.0.0",
+		ClientID:      "grafana",
+		Username:      "grafana",
+		Password:      "secret",
+		TLS:           true,
+		OrgID:         2,
+		ConsumerGroup: "grafana",
+		InitialOffset: "oldest",
+		Topics: []LiveKafkaTopic{
+			{Topic: "temperature", Channel: "stream/sensors/temperature"},
+			{Topic: "line1", Channel: "stream/plant/line1"},
+		},
+		OutputTopicPrefixes: []string{"grafana-live-"},
+	}, cfg.LiveKafka)
+}
+
+func TestLiveKafkaSettingsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid initial offset":  "initial_offset = latest",
+		"invalid topic mapping":   "topics = temperature",
+		"mapping without a topic": "topics = =stream/a/b",
+	}
+	for name, section := range tests {
+		t.Run(name, func(t *testing.T) {
+			iniFile, err := ini.Load([]byte("[live.kafka]\n" + section))
+			require.NoError(t, err)
+			require.Error(t, NewCfg().readLiveKafkaSettings(iniFile))
+		})
+	}
+}
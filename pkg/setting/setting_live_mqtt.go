@@ -0,0 +1,64 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// LiveMQTTSettings configures the ingestion of the messages of MQTT topics into Live channels.
+type LiveMQTTSettings struct {
+	Enabled  bool
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// OrgID is the organization of the channels the messages are pushed to.
+	OrgID int64
+	QoS   byte
+	// SharedSubscriptionGroup subscribes to the topics with MQTT shared subscriptions, so that each
+	// message is only received by one Grafana server of a HA setup.
+	SharedSubscriptionGroup string
+	Topics                  []LiveMQTTTopic
+}
+
+// LiveMQTTTopic maps an MQTT topic filter to a Live channel.
+type LiveMQTTTopic struct {
+	Filter  string
+	Channel string
+}
+
+func (cfg *Cfg) readLiveMQTTSettings(iniFile *ini.File) error {
+	section := iniFile.Section("live.mqtt")
+	cfg.LiveMQTT.Enabled = section.Key("enabled").MustBool(false)
+	cfg.LiveMQTT.Broker = section.Key("broker").MustString("tcp://127.0.0.1:1883")
+	// brokers disconnect a client when another one connects with the same ID, so the servers of
+	// a HA setup get a unique ID by default
+	cfg.LiveMQTT.ClientID = section.Key("client_id").MustString("grafana-" + util.GenerateShortUID())
+	cfg.LiveMQTT.Username = section.Key("username").String()
+	cfg.LiveMQTT.Password = section.Key("password").String()
+	cfg.LiveMQTT.OrgID = section.Key("org_id").MustInt64(1)
+	cfg.LiveMQTT.SharedSubscriptionGroup = section.Key("shared_subscription_group").String()
+
+	qos := section.Key("qos").MustInt(0)
+	if qos < 0 || qos > 2 {
+		return fmt.Errorf("unexpected value %d for [live.mqtt] qos", qos)
+	}
+	cfg.LiveMQTT.QoS = byte(qos)
+
+	cfg.LiveMQTT.Topics = nil
+	for _, mapping := range util.SplitString(strings.TrimSpace(section.Key("topics").String())) {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("unexpected value %q for [live.mqtt] topics, use <topic filter>=<channel>", mapping)
+		}
+		cfg.LiveMQTT.Topics = append(cfg.LiveMQTT.Topics, LiveMQTTTopic{Filter: parts[0], Channel: parts[1]})
+	}
+	if cfg.LiveMQTT.Enabled && len(cfg.LiveMQTT.Topics) == 0 {
+		return fmt.Errorf("[live.mqtt] topics should be set when MQTT ingestion is enabled")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package setting
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestLiveMQTTSettings(t *testing.T) {
+	iniFile, err := ini.Load([]byte(`
+[live.mqtt]
+enabled = true
+broker = ssl://mqtt.example.com:8883
+client_id = grafana
+username = grafana
+password = secret
+org_id = 2
+qos = 1
+shared_subscription_group = grafana
+topics = sensors/+/temperature=stream/sensors, plant/line1=stream/plant/line1
+`))
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.readLiveMQTTSettings(iniFile))
+	require.Equal(t, LiveMQTTSettings{
+		Enabled:                 true,
+		Broker:                  "ssl://mqtt.example.com:8883",
+		ClientID:                "grafana",
+		Username:                "grafana",
+		Password:                "secret",
+		OrgID:                   2,
+		QoS:                     1,
+		SharedSubscriptionGroup: "grafana",
+		Topics: []LiveMQTTTopic{
+			{Filter: "sensors/+/temperature", Channel: "stream/sensors"},
+			{Filter: "plant/line1", Channel: "stream/plant/line1"},
+		},
+	}, cfg.LiveMQTT)
+}
+
+func TestLiveMQTTSettingsDefaultClientID(t *testing.T) {
+	first := NewCfg()
+	require.NoError(t, first.readLiveMQTTSettings(ini.Empty()))
+	second := NewCfg()
+	require.NoError(t, second.readLiveMQTTSettings(ini.Empty()))
+
+	require.True(t, strings.HasPrefix(first.LiveMQTT.ClientID, "grafana-"))
+	require.NotEqual(t, first.LiveMQTT.ClientID, second.LiveMQTT.ClientID, "each server gets a unique client ID")
+}
+
+func TestLiveMQTTSettingsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid qos":              "qos = 3\ntopics = a=stream/a/b",
+		"invalid topic mapping":    "topics = sensors/#",
+		"enabled without topics":   "enabled = true",
+		"mapping without a filter": "topics = =stream/a/b",
+	}
+	for name, section := range tests {
+		t.Run(name, func(t *testing.T) {
+			iniFile, err := ini.Load([]byte("[live.mqtt]\n" + section))
+			require.NoError(t, err)
+			require.Error(t, NewCfg().readLiveMQTTSettings(iniFile))
+		})
+	}
+}
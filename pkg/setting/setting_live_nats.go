@@ -0,0 +1,61 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// LiveNATSSettings configures the connection of Live to NATS, which is used by the
+// NATS outputs of the Live pipeline and to consume NATS subjects into Live channels.
+type LiveNATSSettings struct {
+	Enabled bool
+	URL     string
+	Name    string
+	// Username and Password or Token are used to authenticate when set.
+	Username string
+	Password string
+	Token    string
+	// OrgID is the organization of the channels the messages are pushed to.
+	OrgID int64
+	// QueueGroup subscribes to the subjects with a queue group, so that each message is
+	// only received by one Grafana server of a HA setup.
+	QueueGroup string
+	Subjects   []LiveNATSSubject
+	// OutputSubjectPrefixes are the prefixes of the subjects the NATS outputs can publish to,
+	// outputs to other subjects are rejected.
+	OutputSubjectPrefixes []string
+}
+
+// LiveNATSSubject maps a NATS subject to a Live channel.
+type LiveNATSSubject struct {
+	Subject string
+	Channel string
+}
+
+func (cfg *Cfg) readLiveNATSSettings(iniFile *ini.File) error {
+	section := iniFile.Section("live.nats")
+	cfg.LiveNATS.Enabled = section.Key("enabled").MustBool(false)
+	cfg.LiveNATS.URL = section.Key("url").MustString("nats://127.0.0.1:4222")
+	cfg.LiveNATS.Name = section.Key("name").MustString("grafana")
+	cfg.LiveNATS.Username = section.Key("username").String()
+	cfg.LiveNATS.Password = section.Key("password").String()
+	cfg.LiveNATS.Token = section.Key("token").String()
+	cfg.LiveNATS.OrgID = section.Key("org_id").MustInt64(1)
+	cfg.LiveNATS.QueueGroup = section.Key("queue_group").String()
+
+	cfg.LiveNATS.OutputSubjectPrefixes = util.SplitString(section.Key("output_subject_prefixes").String())
+
+	cfg.LiveNATS.Subjects = nil
+	for _, mapping := range util.SplitString(strings.TrimSpace(section.Key("subjects").String())) {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("unexpected value %q for [live.nats] subjects, use <subject>=<channel>", mapping)
+		}
+		cfg.LiveNATS.Subjects = append(cfg.LiveNATS.Subjects, LiveNATSSubject{Subject: parts[0], Channel: parts[1]})
+	}
+	return nil
+}
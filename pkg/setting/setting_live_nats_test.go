@@ -0,0 +1,44 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestLiveNATSSettings(t *testing.T) {
+	iniFile, err := ini.Load([]byte(`
+[live.nats]
+enabled = true
+url = nats://nats.example.com:4222
+token = secret
+org_id = 2
+queue_group = grafana
+subjects = sensors.*.temperature=stream/sensors, plant.line1=stream/plant/line1
+output_subject_prefixes = grafana.live., alerts.
+`))
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.readLiveNATSSettings(iniFile))
+	require.Equal(t, LiveNATSSettings{
+		Enabled:    true,
+		URL:        "nats://nats.example.com:4222",
+		Name:       "grafana",
+		Token:      "secret",
+		OrgID:      2,
+		QueueGroup: "grafana",
+		Subjects: []LiveNATSSubject{
+			{Subject: "sensors.*.temperature", Channel: "stream/sensors"},
+			{Subject: "plant.line1", Channel: "stream/plant/line1"},
+		},
+		OutputSubjectPrefixes: []string{"grafana.live.", "alerts."},
+	}, cfg.LiveNATS)
+}
+
+func TestLiveNATSSettingsInvalid(t *testing.T) {
+	iniFile, err := ini.Load([]byte("[live.nats]\nsubjects = sensors.>"))
+	require.NoError(t, err)
+	require.Error(t, NewCfg().readLiveNATSSettings(iniFile))
+}
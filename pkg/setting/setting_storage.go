@@ -0,0 +1,98 @@
+package setting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/util"
+	"gopkg.in/ini.v1"
+)
+
+const storageSectionPrefix = "storage."
+
+// StorageRootSettings configures a named root of the file storage. Each root is read
+// from a [storage.<name>] section.
+type StorageRootSettings struct {
+	Name string
+	// Type is the backend of the root: db, local, mem, s3, gcs or azure.
+	Type string
+	// Path is the directory of a local root, relative paths are relative to the home path.
+	Path string
+	// BucketURL is the gocloud URL of the bucket of an s3, gcs or azure root, such as s3://my-bucket?region=us-east-1.
+	BucketURL string
+	// Prefix is the folder in the backend where the files of the root are stored.
+	Prefix   string
+	ReadOnly bool
+
+	// The path filters of the root. A nil list does not filter.
+	AllowedPrefixes    []string
+	AllowedPaths       []string
+	DisallowedPrefixes []string
+	DisallowedPaths    []string
+
+	// Versions is the number of previous versions of each file that are kept, 0 disables versioning.
+	Versions int
+	// TrashRetention is how long deleted files are kept in the trash, 0 deletes files permanently.
+	TrashRetention time.Duration
+}
+
+func splitStorageList(sec *ini.Section, key string) []string {
+	if !sec.HasKey(key) {
+		return nil
+	}
+	list := make([]string, 0)
+	for _, el := range util.SplitString(sec.Key(key).String()) {
+		if el != "" {
+			list = append(list, el)
+		}
+	}
+	return list
+}
+
+func (cfg *Cfg) readStorageSettings(iniFile *ini.File) error {
+	storage := iniFile.Section("storage")
+	cfg.StorageMaxUploadSize = storage.Key("max_upload_size_mb").MustInt64(10) * 1024 * 1024
+	cfg.StorageAllowedMimeTypes = util.SplitString(storage.Key("allowed_mime_types").MustString(
+		"image/png, image/jpeg, image/gif, image/webp, image/svg+xml, application/json, application/geo+json, text/plain, text/csv"))
+
+	cfg.StorageRoots = make([]StorageRootSettings, 0)
+	for _, sec := range iniFile.Sections() {
+		if !strings.HasPrefix(sec.Name(), storageSectionPrefix) {
+			continue
+		}
+
+		root := StorageRootSettings{
+			Name:               strings.TrimPrefix(sec.Name(), storageSectionPrefix),
+			Type:               sec.Key("type").MustString("db"),
+			BucketURL:          sec.Key("bucket_url").String(),
+			Prefix:             sec.Key("prefix").String(),
+			ReadOnly:           sec.Key("read_only").MustBool(false),
+			AllowedPrefixes:    splitStorageList(sec, "allowed_prefixes"),
+			AllowedPaths:       splitStorageList(sec, "allowed_paths"),
+			DisallowedPrefixes: splitStorageList(sec, "disallowed_prefixes"),
+			DisallowedPaths:    splitStorageList(sec, "disallowed_paths"),
+			Versions:           sec.Key("versions").MustInt(0),
+		}
+		if path := sec.Key("path").String(); path != "" {
+			root.Path = makeAbsolute(path, cfg.HomePath)
+		}
+		if root.Versions < 0 {
+			return fmt.Errorf("versions of storage root %s can not be negative", root.Name)
+		}
+		if retention := sec.Key("trash_retention").String(); retention != "" {
+			var err error
+			if root.TrashRetention, err = gtime.ParseDuration(retention); err != nil {
+				return fmt.Errorf("invalid trash_retention of storage root %s: %w", root.Name, err)
+			}
+		}
+		cfg.StorageRoots = append(cfg.StorageRoots, root)
+	}
+
+	sort.Slice(cfg.StorageRoots, func(i, j int) bool {
+		return cfg.StorageRoots[i].Name < cfg.StorageRoots[j].Name
+	})
+	return nil
+}
@@ -0,0 +1,74 @@
+package setting
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestStorageSettings(t *testing.T) {
+	iniFile, err := ini.Load([]byte(`
+[storage]
+unrelated = true
+
+[storage.public]
+type = local
+path = data/public
+read_only = true
+
+[storage.images]
+type = s3
+bucket_url = s3://images?region=us-east-1
+prefix = grafana/images
+allowed_prefixes = /uploads/, /avatars/
+disallowed_paths =
+
+[storage.internal]
+versions = 5
+trash_retention = 30d
+`))
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	cfg.HomePath = "/grafana"
+	require.NoError(t, cfg.readStorageSettings(iniFile))
+
+	require.Equal(t, []StorageRootSettings{
+		{
+			Name:               "images",
+			Type:               "s3",
+			BucketURL:          "s3://images?region=us-east-1",
+			Prefix:             "grafana/images",
+			AllowedPrefixes:    []string{"/uploads/", "/avatars/"},
+			DisallowedPaths:    []string{},
+			AllowedPaths:       nil,
+			DisallowedPrefixes: nil,
+		},
+		{
+			Name:           "internal",
+			Type:           "db",
+			Versions:       5,
+			TrashRetention: 30 * 24 * time.Hour,
+		},
+		{
+			Name:     "public",
+			Type:     "local",
+			Path:     filepath.Join("/grafana", "data/public"),
+			ReadOnly: true,
+		},
+	}, cfg.StorageRoots)
+}
+
+func TestStorageSettings_InvalidTrashRetention(t *testing.T) {
+	iniFile, err := ini.Load([]byte(`
+[storage.internal]
+trash_retention = forever
+`))
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.Error(t, cfg.readStorageSettings(iniFile))
+}
@@ -48,6 +48,11 @@ const (
 	schedulereDefaultExecuteAlerts          = true
 	schedulerDefaultMaxAttempts             = 3
 	schedulerDefaultLegacyMinInterval       = 1
+	stateHistoryDefaultRetention            = 30 * 24 * time.Hour
+	notificationLogDefaultRetention         = 30 * 24 * time.Hour
+	notificationRetryDefaultMaxAttempts     = 5
+	notificationRetryDefaultBackoff         = time.Minute
+	recordingRulesDefaultTimeout            = 10 * time.Second
 	// SchedulerBaseInterval base interval of the scheduler. Controls how often the scheduler fetches database for new changes as well as schedules evaluation of a rule
 	// changing this value is discouraged because this could cause existing alert definition
 	// with intervals that are not exactly divided by this number not to be evaluated
@@ -65,6 +70,7 @@ type UnifiedAlertingSettings struct {
 	HAPeerTimeout                  time.Duration
 	HAGossipInterval               time.Duration
 	HAPushPullInterval             time.Duration
+	HARuleSharding                 bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -77,6 +83,24 @@ type UnifiedAlertingSettings struct {
 	BaseInterval time.Duration
 	// DefaultRuleEvaluationInterval default interval between evaluations of a rule.
 	DefaultRuleEvaluationInterval time.Duration
+	// StateHistoryRetention is for how long the state transitions of alert instances are kept. Zero keeps them forever.
+	StateHistoryRetention time.Duration
+	// NotificationLogRetention is for how long the notification attempts of contact points are kept. Zero keeps them forever.
+	NotificationLogRetention time.Duration
+	// NotificationRetryMaxAttempts is the maximum number of attempts to send a failed notification. Failed notifications are not retried if it is 1 or less.
+	NotificationRetryMaxAttempts int
+	// NotificationRetryBackoff is the time to wait before the first retry of a failed notification, it is doubled after each attempt.
+	NotificationRetryBackoff time.Duration
+	RecordingRules           RecordingRuleSettings
+}
+
+// RecordingRuleSettings configures where the series produced by recording rules are written to.
+type RecordingRuleSettings struct {
+	// URL of the Prometheus remote write endpoint. Recording rules are not evaluated if it is empty.
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -169,6 +193,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	if err != nil {
 		return err
 	}
+	uaCfg.HARuleSharding = ua.Key("ha_rule_sharding").MustBool(false)
 	uaCfg.HAListenAddr = ua.Key("ha_listen_address").MustString(alertmanagerDefaultClusterAddr)
 	uaCfg.HAAdvertiseAddr = ua.Key("ha_advertise_address").MustString("")
 	peers := ua.Key("ha_peers").MustString("")
@@ -244,6 +269,42 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfg.DefaultRuleEvaluationInterval = uaMinInterval
 	}
 
+	uaCfg.StateHistoryRetention, err = gtime.ParseDuration(valueAsString(ua, "state_history_retention", stateHistoryDefaultRetention.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.StateHistoryRetention < 0 {
+		return fmt.Errorf("value of setting 'state_history_retention' should not be negative")
+	}
+
+	uaCfg.NotificationLogRetention, err = gtime.ParseDuration(valueAsString(ua, "notification_log_retention", notificationLogDefaultRetention.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.NotificationLogRetention < 0 {
+		return fmt.Errorf("value of setting 'notification_log_retention' should not be negative")
+	}
+
+	uaCfg.NotificationRetryMaxAttempts = ua.Key("notification_retry_max_attempts").MustInt(notificationRetryDefaultMaxAttempts)
+	uaCfg.NotificationRetryBackoff, err = gtime.ParseDuration(valueAsString(ua, "notification_retry_backoff", notificationRetryDefaultBackoff.String()))
+	if err != nil {
+		return err
+	}
+	if uaCfg.NotificationRetryBackoff <= 0 {
+		return fmt.Errorf("value of setting 'notification_retry_backoff' should be positive")
+	}
+
+	rr := iniFile.Section("unified_alerting.recording_rules")
+	uaCfg.RecordingRules = RecordingRuleSettings{
+		URL:               valueAsString(rr, "url", ""),
+		BasicAuthUsername: valueAsString(rr, "basic_auth_username", ""),
+		BasicAuthPassword: valueAsString(rr, "basic_auth_password", ""),
+	}
+	uaCfg.RecordingRules.Timeout, err = gtime.ParseDuration(valueAsString(rr, "timeout", recordingRulesDefaultTimeout.String()))
+	if err != nil {
+		return err
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
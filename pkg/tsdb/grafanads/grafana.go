@@ -14,7 +14,9 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/searchV2"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
 )
@@ -40,11 +42,11 @@ var (
 	logger                            = log.New("tsdb.grafana")
 )
 
-func ProvideService(cfg *setting.Cfg, search searchV2.SearchService) *Service {
-	return newService(cfg, search)
+func ProvideService(cfg *setting.Cfg, search searchV2.SearchService, stateHistory store.StateHistoryReader, sqlStore *sqlstore.SQLStore) *Service {
+	return newService(cfg, search, stateHistory, sqlStore)
 }
 
-func newService(cfg *setting.Cfg, search searchV2.SearchService) *Service {
+func newService(cfg *setting.Cfg, search searchV2.SearchService, alertingStore store.StateHistoryReader, users signedInUserStore) *Service {
 	s := &Service{
 		staticRootPath: cfg.StaticRootPath,
 		roots: []string{
@@ -55,7 +57,9 @@ func newService(cfg *setting.Cfg, search searchV2.SearchService) *Service {
 			"maps",
 			"upload", // does not exist yet
 		},
-		search: search,
+		search:        search,
+		alertingStore: alertingStore,
+		users:         users,
 	}
 
 	return s
@@ -67,6 +71,8 @@ type Service struct {
 	staticRootPath string
 	roots          []string
 	search         searchV2.SearchService
+	alertingStore  store.StateHistoryReader
+	users          signedInUserStore
 }
 
 func DataSourceModel(orgId int64) *models.DataSource {
@@ -94,6 +100,8 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			response.Responses[q.RefID] = s.doReadQuery(q)
 		case queryTypeSearch:
 			response.Responses[q.RefID] = s.doSearchQuery(ctx, req, q)
+		case queryTypeStateHistory:
+			response.Responses[q.RefID] = s.doStateHistoryQuery(ctx, req, q)
 		default:
 			response.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("unknown query type"),
@@ -1,10 +1,14 @@
 package grafanads
 
 import (
+	"context"
 	"encoding/json"
 	"path"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/setting"
 
@@ -19,7 +23,7 @@ func asJSON(v interface{}) json.RawMessage {
 }
 
 func TestReadFolderListing(t *testing.T) {
-	ds := newService(&setting.Cfg{StaticRootPath: "../../../public"}, searchV2.NewStubSearchService())
+	ds := newService(&setting.Cfg{StaticRootPath: "../../../public"}, searchV2.NewStubSearchService(), nil, nil)
 	dr := ds.doListQuery(backend.DataQuery{
 		QueryType: "x",
 		JSON: asJSON(listQueryModel{
@@ -31,7 +35,7 @@ func TestReadFolderListing(t *testing.T) {
 }
 
 func TestReadCSVFile(t *testing.T) {
-	ds := newService(&setting.Cfg{StaticRootPath: "../../../public"}, searchV2.NewStubSearchService())
+	ds := newService(&setting.Cfg{StaticRootPath: "../../../public"}, searchV2.NewStubSearchService(), nil, nil)
 	dr := ds.doReadQuery(backend.DataQuery{
 		QueryType: "x",
 		JSON: asJSON(readQueryModel{
@@ -41,3 +45,98 @@ func TestReadCSVFile(t *testing.T) {
 	err := experimental.CheckGoldenDataResponse(path.Join("testdata", "jslib.golden.txt"), &dr, true)
 	require.NoError(t, err)
 }
+
+func TestStateHistoryQuery(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(instance string, offset time.Duration, prev, state ngmodels.InstanceStateType) *ngmodels.AlertStateHistoryEntry {
+		return &ngmodels.AlertStateHistoryEntry{
+			OrgID:         1,
+			RuleUID:       "rule",
+			Labels:        ngmodels.InstanceLabels{"instance": instance},
+			PreviousState: prev,
+			State:         state,
+			EvaluatedAt:   start.Add(offset),
+		}
+	}
+	store := &fakeAlertingStore{
+		namespaces: []string{"folder"},
+		entries: []*ngmodels.AlertStateHistoryEntry{
+			entry("a", 0, ngmodels.InstanceStateNormal, ngmodels.InstanceStatePending),
+			entry("b", 0, ngmodels.InstanceStateNormal, ngmodels.InstanceStateFiring),
+			entry("a", time.Minute, ngmodels.InstanceStatePending, ngmodels.InstanceStateFiring),
+			entry("a", 2*time.Minute, ngmodels.InstanceStateFiring, ngmodels.InstanceStateNormal),
+		},
+	}
+	users := &fakeSignedInUserStore{}
+	ds := newService(&setting.Cfg{StaticRootPath: "../../../public"}, searchV2.NewStubSearchService(), store, users)
+
+	req := &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID: 1,
+			User:  &backend.User{Login: "viewer"},
+		},
+	}
+	query := backend.DataQuery{
+		RefID:     "A",
+		QueryType: queryTypeStateHistory,
+		TimeRange: backend.TimeRange{From: start, To: start.Add(time.Hour)},
+		JSON: asJSON(stateHistoryQueryModel{
+			RuleUID:  "rule",
+			Matchers: `{instance=~"a|b"}`,
+		}),
+	}
+
+	t.Run("returns a frame per alert instance", func(t *testing.T) {
+		dr := ds.doStateHistoryQuery(context.Background(), req, query)
+		err := experimental.CheckGoldenDataResponse(path.Join("testdata", "state_history.golden.txt"), &dr, true)
+		require.NoError(t, err)
+
+		require.Equal(t, "viewer", users.lastQuery.Login)
+		require.Equal(t, int64(1), users.lastQuery.OrgId)
+
+		q := store.lastQuery
+		require.Equal(t, "rule", q.RuleUID)
+		require.Equal(t, []string{"folder"}, q.NamespaceUIDs)
+		require.Len(t, q.Matchers, 1)
+		require.Equal(t, start, q.From)
+		require.Equal(t, start.Add(time.Hour), q.To)
+		require.Equal(t, defaultStateHistoryLimit, q.Limit)
+	})
+
+	t.Run("returns an error for invalid matchers", func(t *testing.T) {
+		invalid := query
+		invalid.JSON = asJSON(stateHistoryQueryModel{Matchers: "{instance}"})
+		dr := ds.doStateHistoryQuery(context.Background(), req, invalid)
+		require.Error(t, dr.Error)
+	})
+}
+
+type fakeAlertingStore struct {
+	namespaces []string
+	entries    []*ngmodels.AlertStateHistoryEntry
+	lastQuery  ngmodels.GetStateHistoryQuery
+}
+
+func (f *fakeAlertingStore) GetStateHistory(_ context.Context, query *ngmodels.GetStateHistoryQuery) error {
+	f.lastQuery = *query
+	query.Result = f.entries
+	return nil
+}
+
+func (f *fakeAlertingStore) GetNamespaces(_ context.Context, _ int64, _ *models.SignedInUser) (map[string]*models.Folder, error) {
+	result := make(map[string]*models.Folder, len(f.namespaces))
+	for _, uid := range f.namespaces {
+		result[uid] = &models.Folder{Uid: uid}
+	}
+	return result, nil
+}
+
+type fakeSignedInUserStore struct {
+	lastQuery models.GetSignedInUserQuery
+}
+
+func (f *fakeSignedInUserStore) GetSignedInUser(_ context.Context, query *models.GetSignedInUserQuery) error {
+	f.lastQuery = *query
+	query.Result = &models.SignedInUser{Login: query.Login, OrgId: query.OrgId, OrgRole: models.ROLE_VIEWER}
+	return nil
+}
@@ -14,6 +14,10 @@ const (
 	// currently only .csv files are supported,
 	// other file types will eventually be supported (parquet, etc)
 	queryTypeRead = "read"
+
+	// QueryTypeStateHistory returns the state transitions of alert instances,
+	// one frame per alert instance
+	queryTypeStateHistory = "stateHistory"
 )
 
 type listQueryModel struct {
@@ -22,3 +26,10 @@ type listQueryModel struct {
 type readQueryModel struct {
 	Path string `json:"path"`
 }
+
+type stateHistoryQueryModel struct {
+	RuleUID string `json:"ruleUID"`
+	// Matchers filter the alert instances by their labels, e.g. {team="ops", severity=~"crit.*"}
+	Matchers string `json:"matchers"`
+	Limit    int    `json:"limit"`
+}
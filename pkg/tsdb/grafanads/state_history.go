@@ -0,0 +1,120 @@
+package grafanads
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// defaultStateHistoryLimit is the number of transitions returned when the query does not set a limit.
+const defaultStateHistoryLimit = 1000
+
+type signedInUserStore interface {
+	GetSignedInUser(ctx context.Context, query *models.GetSignedInUserQuery) error
+}
+
+func (s *Service) doStateHistoryQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery) backend.DataResponse {
+	response := backend.DataResponse{}
+	q := stateHistoryQueryModel{}
+	if err := json.Unmarshal(query.JSON, &q); err != nil {
+		response.Error = err
+		return response
+	}
+
+	matchers, err := labels.ParseMatchers(q.Matchers)
+	if err != nil {
+		response.Error = fmt.Errorf("invalid matchers: %w", err)
+		return response
+	}
+
+	user, err := s.getSignedInUser(ctx, req.PluginContext)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	namespaces, err := s.alertingStore.GetNamespaces(ctx, user.OrgId, user)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	namespaceUIDs := make([]string, 0, len(namespaces))
+	for uid := range namespaces {
+		namespaceUIDs = append(namespaceUIDs, uid)
+	}
+
+	historyQuery := ngmodels.GetStateHistoryQuery{
+		OrgID:         user.OrgId,
+		RuleUID:       q.RuleUID,
+		NamespaceUIDs: namespaceUIDs,
+		Matchers:      matchers,
+		From:          query.TimeRange.From,
+		To:            query.TimeRange.To,
+		Limit:         q.Limit,
+	}
+	if historyQuery.Limit <= 0 {
+		historyQuery.Limit = defaultStateHistoryLimit
+	}
+	if err := s.alertingStore.GetStateHistory(ctx, &historyQuery); err != nil {
+		response.Error = err
+		return response
+	}
+
+	response.Frames, response.Error = stateHistoryFrames(historyQuery.Result)
+	return response
+}
+
+func (s *Service) getSignedInUser(ctx context.Context, pluginCtx backend.PluginContext) (*models.SignedInUser, error) {
+	if pluginCtx.User == nil {
+		return nil, fmt.Errorf("auth error")
+	}
+
+	query := &models.GetSignedInUserQuery{
+		Login: pluginCtx.User.Login,
+		Email: pluginCtx.User.Email,
+		OrgId: pluginCtx.OrgID,
+	}
+	if err := s.users.GetSignedInUser(ctx, query); err != nil || query.Result == nil {
+		logger.Warn("Error while retrieving user", "error", err)
+		return nil, fmt.Errorf("auth error")
+	}
+	return query.Result, nil
+}
+
+// stateHistoryFrames returns a frame per alert instance, with the time and the new state of each
+// transition. The labels of the alert instance are set on the state field so it can be graphed,
+// for example, in a state timeline.
+func stateHistoryFrames(entries []*ngmodels.AlertStateHistoryEntry) (data.Frames, error) {
+	frames := make(data.Frames, 0)
+	byInstance := make(map[string]*data.Frame)
+	for _, entry := range entries {
+		_, hash, err := entry.Labels.StringAndHash()
+		if err != nil {
+			return nil, err
+		}
+		key := entry.RuleUID + "/" + hash
+
+		frame, ok := byInstance[key]
+		if !ok {
+			stateField := data.NewField("state", data.Labels(entry.Labels).Copy(), []string{})
+			stateField.Labels["rule_uid"] = entry.RuleUID
+			frame = data.NewFrame(entry.RuleUID,
+				data.NewField("time", nil, []time.Time{}),
+				stateField,
+				data.NewField("previousState", nil, []string{}),
+			)
+			byInstance[key] = frame
+			frames = append(frames, frame)
+		}
+		frame.AppendRow(entry.EvaluatedAt, string(entry.State), string(entry.PreviousState))
+	}
+	return frames, nil
+}
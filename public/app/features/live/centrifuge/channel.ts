@@ -5,6 +5,7 @@ import {
   LiveChannelConnectionState,
   LiveChannelPresenceStatus,
   LiveChannelAddress,
+  LiveChannelScope,
   DataFrameJSON,
   isValidLiveChannelAddress,
 } from '@grafana/data';
@@ -34,6 +35,9 @@ export class CentrifugeLiveChannel<T = any> {
   // Hold on to the last header with schema
   lastMessageWithSchema?: DataFrameJSON;
 
+  // Publications received while the channel history is loaded
+  pendingPublications?: PublicationContext[];
+
   subscription?: Centrifuge.Subscription;
   shutdownCallback?: () => void;
   initalized?: boolean;
@@ -63,30 +67,11 @@ export class CentrifugeLiveChannel<T = any> {
     const events: SubscriptionEvents = {
       // Called when a message is received from the socket
       publish: (ctx: PublicationContext) => {
-        try {
-          if (ctx.data) {
-            if (ctx.data.schema) {
-              this.lastMessageWithSchema = ctx.data as DataFrameJSON;
-            }
-
-            this.stream.next({
-              type: LiveChannelEventType.Message,
-              message: ctx.data,
-            });
-          }
-
-          // Clear any error messages
-          if (this.currentStatus.error) {
-            this.currentStatus.timestamp = Date.now();
-            delete this.currentStatus.error;
-            this.sendStatus();
-          }
-        } catch (err) {
-          console.log('publish error', this.addr, err);
-          this.currentStatus.error = err;
-          this.currentStatus.timestamp = Date.now();
-          this.sendStatus();
+        if (this.pendingPublications) {
+          this.pendingPublications.push(ctx);
+          return;
         }
+        this.processPublication(ctx);
       },
       error: (ctx: SubscribeErrorContext) => {
         this.currentStatus.timestamp = Date.now();
@@ -100,6 +85,14 @@ export class CentrifugeLiveChannel<T = any> {
 
         if (ctx.data?.schema) {
           this.lastMessageWithSchema = ctx.data as DataFrameJSON;
+
+          // Managed streams keep their recent publications in the channel history, send the schema
+          // first and then the recent points instead of only the last frame.
+          if (this.addr.scope === LiveChannelScope.Stream && !ctx.isResubscribe) {
+            this.sendStatus({ schema: ctx.data.schema });
+            this.loadHistory(ctx.data as DataFrameJSON);
+            return;
+          }
         }
 
         this.sendStatus(ctx.data);
@@ -120,6 +113,74 @@ export class CentrifugeLiveChannel<T = any> {
     return events;
   }
 
+  private processPublication(ctx: PublicationContext) {
+    try {
+      if (ctx.data) {
+        if (ctx.data.schema) {
+          this.lastMessageWithSchema = ctx.data as DataFrameJSON;
+        }
+
+        this.stream.next({
+          type: LiveChannelEventType.Message,
+          message: ctx.data,
+        });
+      }
+
+      // Clear any error messages
+      if (this.currentStatus.error) {
+        this.currentStatus.timestamp = Date.now();
+        delete this.currentStatus.error;
+        this.sendStatus();
+      }
+    } catch (err) {
+      console.log('publish error', this.addr, err);
+      this.currentStatus.error = err;
+      this.currentStatus.timestamp = Date.now();
+      this.sendStatus();
+    }
+  }
+
+  /**
+   * Sends the publications of the channel history, or the last frame when the history is empty or
+   * not available. Publications received in the meantime are sent after the history.
+   */
+  private loadHistory(lastMessage: DataFrameJSON) {
+    if (!this.subscription) {
+      this.stream.next({ type: LiveChannelEventType.Message, message: lastMessage });
+      return;
+    }
+
+    this.pendingPublications = [];
+    const flush = (publications: PublicationContext[]) => {
+      let lastOffset = 0;
+      if (publications.length) {
+        for (const pub of publications) {
+          this.processPublication(pub);
+          lastOffset = pub.offset ?? lastOffset;
+        }
+      } else {
+        this.stream.next({ type: LiveChannelEventType.Message, message: lastMessage });
+      }
+
+      const pending = this.pendingPublications ?? [];
+      this.pendingPublications = undefined;
+      for (const pub of pending) {
+        // Skip the publications that were already part of the history
+        if (!pub.offset || pub.offset > lastOffset) {
+          this.processPublication(pub);
+        }
+      }
+    };
+
+    this.subscription
+      .history()
+      .then((result: any) => flush(result?.publications ?? []))
+      .catch((err: any) => {
+        console.log('history error', this.addr, err);
+        flush([]);
+      });
+  }
+
   private sendStatus(message?: any) {
     const copy = { ...this.currentStatus };
     if (message) {
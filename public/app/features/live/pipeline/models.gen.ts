@@ -46,6 +46,12 @@ export interface MultipleOutputterConfig {
   outputs: FrameOutputterConfig[];
 }
 export interface ManagedStreamOutputConfig {}
+export interface NATSOutputConfig {
+  subject: string;
+}
+export interface KafkaOutputConfig {
+  topic: string;
+}
 export interface FrameOutputterConfig {
   type: Omit<keyof FrameOutputterConfig, 'type'>;
   managedStream?: ManagedStreamOutputConfig;
@@ -56,6 +62,40 @@ export interface FrameOutputterConfig {
   remoteWrite?: RemoteWriteOutputConfig;
   loki?: LokiOutputConfig;
   changeLog?: ChangeLogOutputConfig;
+  kafka?: KafkaOutputConfig;
+  nats?: NATSOutputConfig;
+}
+export interface SampleFrameProcessorConfig {
+  every: number;
+}
+export interface RateLimitFrameProcessorConfig {
+  intervalMilliseconds: number;
+}
+export interface AggregateField {
+  fieldName: string;
+  reducer: string;
+}
+export interface AggregateFrameProcessorConfig {
+  intervalSeconds: number;
+  timeField?: string;
+  fields: AggregateField[];
+}
+export interface ExpressionFieldFrameProcessorConfig {
+  name: string;
+  type: number;
+  expression: string;
+}
+export interface Label {
+  name: string;
+  value: string;
+}
+export interface RelabelFieldsFrameProcessorConfig {
+  fieldNames?: string[];
+  labels?: Label[];
+  dropLabels?: string[];
+}
+export interface RenameFieldsFrameProcessorConfig {
+  names: { [key: string]: string };
 }
 export interface MultipleFrameProcessorConfig {
   processors: FrameProcessorConfig[];
@@ -71,6 +111,12 @@ export interface FrameProcessorConfig {
   dropFields?: DropFieldsFrameProcessorConfig;
   keepFields?: KeepFieldsFrameProcessorConfig;
   multiple?: MultipleFrameProcessorConfig;
+  renameFields?: RenameFieldsFrameProcessorConfig;
+  relabelFields?: RelabelFieldsFrameProcessorConfig;
+  expressionField?: ExpressionFieldFrameProcessorConfig;
+  aggregate?: AggregateFrameProcessorConfig;
+  rateLimit?: RateLimitFrameProcessorConfig;
+  sample?: SampleFrameProcessorConfig;
 }
 export interface JsonFrameConverterConfig {}
 export interface AutoInfluxConverterConfig {
@@ -79,10 +125,6 @@ export interface AutoInfluxConverterConfig {
 export interface ExactJsonConverterConfig {
   fields: Field[];
 }
-export interface Label {
-  name: string;
-  value: string;
-}
 export interface Field {
   name: string;
   type: number;
@@ -110,6 +152,8 @@ export interface DataOutputterConfig {
   type: Omit<keyof DataOutputterConfig, 'type'>;
   redirect?: RedirectDataOutputConfig;
   loki?: LokiOutputConfig;
+  kafka?: KafkaOutputConfig;
+  nats?: NATSOutputConfig;
 }
 export interface MultipleSubscriberConfig {
   subscribers: SubscriberConfig[];
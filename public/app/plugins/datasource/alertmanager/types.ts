@@ -71,6 +71,7 @@ export type GrafanaManagedReceiverConfig = {
   type: string;
   name: string;
   updated?: string;
+  provenance?: string;
   created?: string;
 };
 